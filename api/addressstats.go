@@ -0,0 +1,24 @@
+package api
+
+import (
+	"github.com/cyyber/go-qrl/core"
+	"golang.org/x/net/context"
+)
+
+type GetAddressStatsReq struct {
+	Address []byte
+}
+
+type GetAddressStatsResp struct {
+	Stats *core.AddressStats
+}
+
+// GetAddressStats returns the activity summary of an address, enough to
+// render an explorer address page in one call
+func (p *PublicAPIServer) GetAddressStats(ctx context.Context, in *GetAddressStatsReq) (*GetAddressStatsResp, error) {
+	stats, err := p.chain.GetAddressStats(in.Address)
+	if err != nil {
+		return nil, err
+	}
+	return &GetAddressStatsResp{Stats: stats}, nil
+}
@@ -0,0 +1,256 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/cyyber/go-qrl/core"
+	"github.com/cyyber/go-qrl/generated"
+	"github.com/cyyber/go-qrl/log"
+	"github.com/cyyber/go-qrl/misc"
+	"github.com/cyyber/go-qrl/p2p"
+	"golang.org/x/net/context"
+	"net"
+	"sync"
+	"time"
+)
+
+// AdminAPIServer exposes operator only calls. It must never be bound to a
+// public interface.
+type AdminAPIServer struct {
+	chain   *core.Chain
+	manager *core.ChainManager
+	server  *p2p.Server
+	config  *core.Config
+	log     log.Logger
+
+	reindexer *core.Reindexer
+
+	devAccountsOnce sync.Once
+	devAccounts     []*DevAccount
+}
+
+func NewAdminAPIServer(chain *core.Chain, server *p2p.Server, config *core.Config, log log.Logger) *AdminAPIServer {
+	return &AdminAPIServer{
+		chain:     chain,
+		server:    server,
+		config:    config,
+		log:       log,
+		reindexer: core.NewReindexer(chain, log),
+	}
+}
+
+// SetChainManager enables the calls adding blocks, such as GenerateBlocks
+func (a *AdminAPIServer) SetChainManager(manager *core.ChainManager) {
+	a.manager = manager
+}
+
+type GetPendingReorgResp struct {
+	Pending    bool
+	HeaderHash []byte
+}
+
+func (a *AdminAPIServer) GetPendingReorg(ctx context.Context) (*GetPendingReorgResp, error) {
+	headerHash := a.chain.PendingReorg()
+	return &GetPendingReorgResp{
+		Pending:    headerHash != nil,
+		HeaderHash: headerHash,
+	}, nil
+}
+
+// ConfirmReorg switches to a branch that exceeded MaxAutoReorgDepth
+func (a *AdminAPIServer) ConfirmReorg(ctx context.Context, headerHash []byte) error {
+	a.log.Warn("Operator confirmed deep reorg", "headerhash", misc.Bin2HStr(headerHash))
+	return a.chain.ConfirmReorg(headerHash)
+}
+
+// ReloadConfig applies the operational settings changed in the
+// configuration file, the report lists the changes needing a restart
+func (a *AdminAPIServer) ReloadConfig(ctx context.Context) (*core.ReloadReport, error) {
+	report, err := a.config.Reload()
+	if err != nil {
+		return nil, err
+	}
+	a.log.Info("Operator reloaded configuration", "applied", report.Applied, "restart", report.RequireRestart)
+	return report, nil
+}
+
+var ErrInvalidIP = errors.New("invalid IP address")
+
+// BanPeer bans ip for the given number of minutes, 0 uses Node.BanMinutes
+func (a *AdminAPIServer) BanPeer(ctx context.Context, ip string, minutes uint32) error {
+	parsedIP := net.ParseIP(ip)
+	if parsedIP == nil {
+		return ErrInvalidIP
+	}
+	if minutes == 0 {
+		minutes = uint32(a.config.Settings().Node.BanMinutes)
+	}
+	a.log.Info("Operator banned peer", "ip", ip, "minutes", minutes)
+	return a.server.BanPeer(parsedIP, time.Duration(minutes)*time.Minute)
+}
+
+func (a *AdminAPIServer) UnbanPeer(ctx context.Context, ip string) error {
+	parsedIP := net.ParseIP(ip)
+	if parsedIP == nil {
+		return ErrInvalidIP
+	}
+	a.log.Info("Operator unbanned peer", "ip", ip)
+	return a.server.UnbanPeer(parsedIP)
+}
+
+// GetBannedPeers returns the active bans, BannedTimestamp being the expiry
+func (a *AdminAPIServer) GetBannedPeers(ctx context.Context) (*generated.Peers, error) {
+	return &generated.Peers{PeerInfoList: a.server.BannedPeers()}, nil
+}
+
+type GetPeersResp struct {
+	Peers     []*p2p.PeerSummary
+	Diversity *p2p.PeerDiversity
+}
+
+// GetPeers lists the connected peers with their spread over network groups
+func (a *AdminAPIServer) GetPeers(ctx context.Context) (*GetPeersResp, error) {
+	return &GetPeersResp{
+		Peers:     a.server.Peers(),
+		Diversity: a.server.PeerDiversity(),
+	}, nil
+}
+
+func (a *AdminAPIServer) GetDiskUsage(ctx context.Context) (*core.DiskUsage, error) {
+	return a.chain.DiskUsage()
+}
+
+// CompactDatabase triggers a full compaction, which can take minutes on a
+// large database
+func (a *AdminAPIServer) CompactDatabase(ctx context.Context) error {
+	a.log.Info("Operator triggered database compaction")
+	return a.chain.CompactDatabase()
+}
+
+// RebuildIndexes starts rebuilding indexes from the blocks in the
+// background, every index when none is given. Progress is reported by
+// GetRebuildIndexesProgress.
+func (a *AdminAPIServer) RebuildIndexes(ctx context.Context, indexes []string) error {
+	a.log.Info("Operator triggered index rebuild", "indexes", indexes)
+	return a.reindexer.Start(indexes)
+}
+
+func (a *AdminAPIServer) GetRebuildIndexesProgress(ctx context.Context) (*core.ReindexProgress, error) {
+	return a.reindexer.Progress(), nil
+}
+
+func (a *AdminAPIServer) StopRebuildIndexes(ctx context.Context) error {
+	a.reindexer.Stop()
+	return nil
+}
+
+const maxIndexCheckSamples = 10000
+
+// CheckIndexes compares the indexes with samples random blocks
+func (a *AdminAPIServer) CheckIndexes(ctx context.Context, samples uint32) (*core.IndexCheckReport, error) {
+	if samples == 0 || samples > maxIndexCheckSamples {
+		return nil, fmt.Errorf("samples must be between 1 and %d", maxIndexCheckSamples)
+	}
+	report, err := a.chain.CheckIndexes(int(samples))
+	if err != nil {
+		return nil, err
+	}
+	if len(report.Mismatches) > 0 {
+		a.log.Warn("Index drift detected", "mismatches", len(report.Mismatches), "blocks", report.BlocksChecked)
+	}
+	return report, nil
+}
+
+const maxRecentRejections = 1000
+
+type RejectionInfo struct {
+	TxHash    string
+	AddrFrom  string
+	Reason    string
+	Error     string
+	Timestamp uint64
+}
+
+// GetRecentRejections returns up to limit transactions recently refused by
+// the pool with the reason, the most recent first
+func (a *AdminAPIServer) GetRecentRejections(ctx context.Context, limit uint32) ([]*RejectionInfo, error) {
+	if limit == 0 || limit > maxRecentRejections {
+		limit = maxRecentRejections
+	}
+	var result []*RejectionInfo
+	for _, rejection := range a.chain.RecentRejections(int(limit)) {
+		result = append(result, &RejectionInfo{
+			TxHash:    misc.Bin2HStr(rejection.TxHash),
+			AddrFrom:  misc.Qaddress(rejection.AddrFrom),
+			Reason:    rejection.Reason.String(),
+			Error:     rejection.Error,
+			Timestamp: rejection.Timestamp,
+		})
+	}
+	return result, nil
+}
+
+// GetTxTypePolicyStats returns, per transaction type, the counters of the
+// transactions refused or left out of templates by the fee policy
+func (a *AdminAPIServer) GetTxTypePolicyStats(ctx context.Context) ([]*core.TxTypePolicyStats, error) {
+	return a.chain.TxTypePolicyStats(), nil
+}
+
+type GetStateMismatchResp struct {
+	// Last mismatch detected, nil if none was
+	Mismatch *core.StateMismatch
+	// State digests compared with peers since the node started
+	Checked uint64
+}
+
+// GetStateMismatch returns the last peer which reported another state
+// digest for one of our mainchain blocks
+func (a *AdminAPIServer) GetStateMismatch(ctx context.Context) (*GetStateMismatchResp, error) {
+	mismatch, checked := a.chain.StateMismatch()
+	return &GetStateMismatchResp{Mismatch: mismatch, Checked: checked}, nil
+}
+
+type GetSlowBlocksResp struct {
+	Reports []*core.BlockProfile
+}
+
+// GetSlowBlocks returns the reports of the last blocks exceeding the
+// SlowBlock thresholds, oldest first
+func (a *AdminAPIServer) GetSlowBlocks(ctx context.Context) (*GetSlowBlocksResp, error) {
+	return &GetSlowBlocksResp{Reports: a.chain.SlowBlocks()}, nil
+}
+
+// GetReceipt returns when and from where the node received the block or
+// transaction hash, and how long validating it took
+func (a *AdminAPIServer) GetReceipt(ctx context.Context, hash []byte) (*generated.ReceiptMetadata, error) {
+	return a.chain.GetReceipt(hash)
+}
+
+type GenerateBlocksResp struct {
+	HeaderHashes [][]byte
+	Height       uint64
+}
+
+// GenerateBlocks instantly mines count blocks paying minerAddress, given as
+// a Q address. It is only available on regtest.
+func (a *AdminAPIServer) GenerateBlocks(ctx context.Context, count uint32, minerAddress string) (*GenerateBlocksResp, error) {
+	if a.manager == nil {
+		return nil, errors.New("block generation not enabled")
+	}
+	address, err := misc.ParseQaddress(minerAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks, err := a.manager.GenerateBlocks(int(count), address)
+	resp := &GenerateBlocksResp{}
+	for _, block := range blocks {
+		resp.HeaderHashes = append(resp.HeaderHashes, block.HeaderHash())
+	}
+	resp.Height = a.manager.Height()
+	if err != nil {
+		return nil, fmt.Errorf("%s, %d blocks generated", err, len(blocks))
+	}
+	a.log.Info("Generated blocks", "count", len(blocks), "height", resp.Height)
+	return resp, nil
+}
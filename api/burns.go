@@ -0,0 +1,56 @@
+package api
+
+import (
+	"github.com/cyyber/go-qrl/core"
+	"golang.org/x/net/context"
+)
+
+type GetCirculatingSupplyReq struct {
+}
+
+// Amounts are in Shor
+type GetCirculatingSupplyResp struct {
+	Emitted     uint64
+	Burned      uint64
+	Circulating uint64
+}
+
+type GetBurnsReq struct {
+	// NextCursor of the previous page
+	Cursor []byte
+	Limit  uint32
+}
+
+type GetBurnsResp struct {
+	Burns []*core.Burn
+	// Cursor for the next page, nil when there are no more burns
+	NextCursor []byte
+}
+
+// GetCirculatingSupply returns the emitted coins excluding the ones sent to
+// burn addresses
+func (p *PublicAPIServer) GetCirculatingSupply(ctx context.Context, in *GetCirculatingSupplyReq) (*GetCirculatingSupplyResp, error) {
+	emitted, err := p.chain.GetTotalCoinSupply()
+	if err != nil {
+		return nil, err
+	}
+	circulating, err := p.chain.GetCirculatingSupply()
+	if err != nil {
+		return nil, err
+	}
+	return &GetCirculatingSupplyResp{
+		Emitted:     emitted,
+		Burned:      p.chain.GetBurnedTotal(),
+		Circulating: circulating,
+	}, nil
+}
+
+// GetBurns lists the transactions sending coins to burn addresses, oldest
+// first
+func (p *PublicAPIServer) GetBurns(ctx context.Context, in *GetBurnsReq) (*GetBurnsResp, error) {
+	burns, next, err := p.chain.GetBurns(in.Cursor, tokenPageSize(in.Limit))
+	if err != nil {
+		return nil, err
+	}
+	return &GetBurnsResp{Burns: burns, NextCursor: next}, nil
+}
@@ -0,0 +1,21 @@
+package api
+
+import (
+	"github.com/cyyber/go-qrl/core"
+	"golang.org/x/net/context"
+)
+
+type GetChainTipsResp struct {
+	Tips []*core.ChainTip
+}
+
+// GetChainTips lists the canonical tip and the stale branches seen
+// recently, so operators can tell whether the network is forked or their
+// node follows a minority branch
+func (p *PublicAPIServer) GetChainTips(ctx context.Context) (*GetChainTipsResp, error) {
+	tips, err := p.chain.GetChainTips()
+	if err != nil {
+		return nil, err
+	}
+	return &GetChainTipsResp{Tips: tips}, nil
+}
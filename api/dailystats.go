@@ -0,0 +1,29 @@
+package api
+
+import (
+	"github.com/cyyber/go-qrl/core"
+	"golang.org/x/net/context"
+)
+
+type GetDailyStatsReq struct {
+	// Unix times, To is now when 0
+	From uint64
+	To   uint64
+}
+
+type GetDailyStatsResp struct {
+	Days []*core.DailyStats
+}
+
+// GetDailyStats returns the chain activity per UTC day, for dashboards
+func (p *PublicAPIServer) GetDailyStats(ctx context.Context, in *GetDailyStatsReq) (*GetDailyStatsResp, error) {
+	to := in.To
+	if to == 0 {
+		to = p.chain.Clock().Time()
+	}
+	days, err := p.chain.GetDailyStats(in.From, to)
+	if err != nil {
+		return nil, err
+	}
+	return &GetDailyStatsResp{Days: days}, nil
+}
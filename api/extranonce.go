@@ -0,0 +1,95 @@
+package api
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+// The 8 bytes extra nonce at ExtraNonceOffset of the mining blob is split
+// in ranges assigned to the workers polling the mining API, so several rigs
+// mining the templates of one node never search the same nonces. A worker
+// keeps its range while it polls, ranges of workers which stopped polling
+// are reassigned after the lease timeout. Requests without a worker id get
+// the whole space, as before.
+
+const maxExtraNoncePartitionBits = 16
+
+var ErrExtraNonceExhausted = errors.New("no extra nonce range left for the worker")
+
+type extraNonceLease struct {
+	partition uint64
+	lastSeen  time.Time
+}
+
+type extraNonceAllocator struct {
+	lock sync.Mutex
+
+	bits    uint8
+	timeout time.Duration
+
+	leases     map[string]*extraNonceLease
+	partitions map[uint64]string
+	next       uint64
+}
+
+func newExtraNonceAllocator(bits uint8, timeout time.Duration) *extraNonceAllocator {
+	if bits > maxExtraNoncePartitionBits {
+		bits = maxExtraNoncePartitionBits
+	}
+	return &extraNonceAllocator{
+		bits:       bits,
+		timeout:    timeout,
+		leases:     make(map[string]*extraNonceLease),
+		partitions: make(map[uint64]string),
+	}
+}
+
+// assign returns the first and last extra nonce of the range of workerID
+func (a *extraNonceAllocator) assign(workerID string, now time.Time) (uint64, uint64, error) {
+	if workerID == "" || a.bits == 0 {
+		return 0, math.MaxUint64, nil
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	lease, ok := a.leases[workerID]
+	if !ok {
+		partition, err := a.freePartition(now)
+		if err != nil {
+			return 0, 0, err
+		}
+		lease = &extraNonceLease{partition: partition}
+		a.leases[workerID] = lease
+		a.partitions[partition] = workerID
+	}
+	lease.lastSeen = now
+
+	size := uint64(1) << (64 - a.bits)
+	start := lease.partition * size
+	return start, start + size - 1, nil
+}
+
+// freePartition returns an unassigned partition, expiring the leases of
+// workers which stopped polling when all are assigned
+func (a *extraNonceAllocator) freePartition(now time.Time) (uint64, error) {
+	count := uint64(1) << a.bits
+	if uint64(len(a.partitions)) == count {
+		for workerID, lease := range a.leases {
+			if now.Sub(lease.lastSeen) > a.timeout {
+				delete(a.leases, workerID)
+				delete(a.partitions, lease.partition)
+			}
+		}
+	}
+	for i := uint64(0); i < count; i++ {
+		partition := (a.next + i) % count
+		if _, used := a.partitions[partition]; !used {
+			a.next = partition + 1
+			return partition, nil
+		}
+	}
+	return 0, ErrExtraNonceExhausted
+}
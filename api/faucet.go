@@ -0,0 +1,114 @@
+package api
+
+import (
+	"errors"
+	"github.com/cyyber/go-qrl/core"
+	"github.com/cyyber/go-qrl/core/transactions"
+	"github.com/cyyber/go-qrl/crypto"
+	"github.com/cyyber/go-qrl/misc"
+	"golang.org/x/net/context"
+	"sync"
+)
+
+var ErrFaucetDisabled = errors.New("faucet is only available on developer networks")
+
+// faucet pays test Quanta from the first development account. Requests are
+// serialized so each transfer gets its own nonce and OTS index.
+type faucet struct {
+	lock sync.Mutex
+
+	xmss    *crypto.XMSS
+	address []byte
+	pk      []byte
+}
+
+type FaucetReq struct {
+	Qaddress string
+}
+
+type FaucetResp struct {
+	TxHash []byte
+	Amount uint64
+}
+
+// Faucet transfers FaucetAmount Shor to the requested address
+func (p *PublicAPIServer) Faucet(ctx context.Context, in *FaucetReq) (*FaucetResp, error) {
+	if p.config.Dev.Network == core.NetworkMainnet || p.config.Dev.DevAccounts == 0 {
+		return nil, ErrFaucetDisabled
+	}
+	address, err := misc.ParseQaddress(in.Qaddress)
+	if err != nil {
+		return nil, err
+	}
+
+	p.faucetOnce.Do(func() {
+		xmss := crypto.DevAccount(0)
+		p.faucet = &faucet{
+			xmss:    xmss,
+			address: misc.UCharVectorToBytes(xmss.Address()),
+			pk:      misc.UCharVectorToBytes(xmss.PK()),
+		}
+	})
+	f := p.faucet
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	otsIndex, err := p.chain.NextUnusedOTSIndex(f.address)
+	if err != nil {
+		return nil, err
+	}
+	if otsIndex >= uint64(1)<<crypto.DevAccountTreeHeight {
+		return nil, errors.New("faucet OTS keys exhausted")
+	}
+
+	amount := p.config.Dev.FaucetAmount
+	tx := transactions.Create([][]byte{address}, []uint64{amount}, 0, f.pk, nil)
+	tx.PBData().Nonce = p.chain.NextNonce(f.address)
+	f.xmss.SetOTSIndex(uint(otsIndex))
+	tx.PBData().Signature = f.xmss.Sign(tx.GetHashableBytes())
+	tx.UpdateTxhash(tx.GetHashableBytes())
+
+	if err := p.chain.SubmitTransaction(tx); err != nil {
+		return nil, err
+	}
+	p.log.Info("Faucet paid", "to", in.Qaddress, "amount", misc.FormatQuanta(amount), "txhash", misc.Bin2HStr(tx.Txhash()))
+	return &FaucetResp{TxHash: tx.Txhash(), Amount: amount}, nil
+}
+
+type DevAccount struct {
+	Qaddress string
+	Mnemonic string
+	Balance  uint64
+}
+
+// GetDevAccounts lists the development accounts with their mnemonic, so
+// they can be imported in a wallet. The first one is used by the faucet.
+func (a *AdminAPIServer) GetDevAccounts(ctx context.Context) ([]*DevAccount, error) {
+	if a.config.Dev.Network == core.NetworkMainnet {
+		return nil, ErrFaucetDisabled
+	}
+	// Generating the XMSS trees is slow, they are only derived once
+	a.devAccountsOnce.Do(func() {
+		for i := 0; i < int(a.config.Dev.DevAccounts); i++ {
+			xmss := crypto.DevAccount(i)
+			a.devAccounts = append(a.devAccounts, &DevAccount{
+				Qaddress: xmss.QAddress(),
+				Mnemonic: xmss.Mnemonic(),
+			})
+			xmss.Close()
+		}
+	})
+
+	var accounts []*DevAccount
+	for _, devAccount := range a.devAccounts {
+		account := *devAccount
+		address, _ := misc.ParseQaddress(account.Qaddress)
+		addrState, err := a.chain.GetAddressState(address)
+		if err != nil {
+			return nil, err
+		}
+		account.Balance = addrState.Balance()
+		accounts = append(accounts, &account)
+	}
+	return accounts, nil
+}
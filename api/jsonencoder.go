@@ -0,0 +1,207 @@
+package api
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/cyyber/go-qrl/core"
+	"github.com/cyyber/go-qrl/misc"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// JSONEncoder turns API responses into JSON. Each API server picks its
+// encoder from its APIConfig.
+type JSONEncoder interface {
+	Marshal(pb proto.Message) ([]byte, error)
+}
+
+func NewJSONEncoder(config *core.APIConfig) JSONEncoder {
+	if config.PythonCompatibleJSON {
+		return &PythonJSONEncoder{HexBytes: config.HexBytesJSON}
+	}
+	return &JSONPBEncoder{}
+}
+
+type JSONPBEncoder struct{}
+
+func (e *JSONPBEncoder) Marshal(pb proto.Message) ([]byte, error) {
+	var buf bytes.Buffer
+	ma := jsonpb.Marshaler{}
+	if err := ma.Marshal(&buf, pb); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// PythonJSONEncoder reproduces the output of the Python node, which uses
+// json_format.MessageToJson(message, sort_keys=True): lowerCamelCase field
+// names, keys sorted, default values omitted, 64 bits integers as strings
+// and a 2 spaces indent. Bytes are base64 encoded like json_format does,
+// or hex encoded if HexBytes is set, as done by the Python wallet and
+// explorer APIs.
+type PythonJSONEncoder struct {
+	HexBytes bool
+}
+
+func (e *PythonJSONEncoder) Marshal(pb proto.Message) ([]byte, error) {
+	value, err := e.message(reflect.ValueOf(pb))
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		value = map[string]interface{}{}
+	}
+	// encoding/json writes map keys sorted
+	return json.MarshalIndent(value, "", "  ")
+}
+
+func jsonFieldName(tag string) string {
+	var name string
+	for _, part := range strings.Split(tag, ",") {
+		if strings.HasPrefix(part, "json=") {
+			return part[len("json="):]
+		}
+		if strings.HasPrefix(part, "name=") {
+			name = part[len("name="):]
+		}
+	}
+	return name
+}
+
+func (e *PythonJSONEncoder) message(v reflect.Value) (map[string]interface{}, error) {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("unexpected kind %s for message", v.Kind())
+	}
+
+	result := make(map[string]interface{})
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if strings.HasPrefix(field.Name, "XXX_") {
+			continue
+		}
+
+		if _, ok := field.Tag.Lookup("protobuf_oneof"); ok {
+			oneof := v.Field(i)
+			if oneof.IsNil() {
+				continue
+			}
+			// The oneof wrapper struct holds a single tagged field
+			wrapper := oneof.Elem().Elem()
+			wrapperField := wrapper.Type().Field(0)
+			value, err := e.value(wrapper.Field(0), wrapperField.Tag.Get("protobuf"))
+			if err != nil {
+				return nil, err
+			}
+			if value != nil {
+				result[jsonFieldName(wrapperField.Tag.Get("protobuf"))] = value
+			}
+			continue
+		}
+
+		tag, ok := field.Tag.Lookup("protobuf")
+		if !ok {
+			continue
+		}
+		value, err := e.value(v.Field(i), tag)
+		if err != nil {
+			return nil, err
+		}
+		if value != nil {
+			result[jsonFieldName(tag)] = value
+		}
+	}
+	return result, nil
+}
+
+// value returns nil for default values, which json_format omits
+func (e *PythonJSONEncoder) value(v reflect.Value, tag string) (interface{}, error) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return e.message(v)
+	case reflect.Slice:
+		if v.Len() == 0 {
+			return nil, nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return e.bytes(v.Bytes()), nil
+		}
+		var list []interface{}
+		for i := 0; i < v.Len(); i++ {
+			var item interface{}
+			var err error
+			if v.Index(i).Kind() == reflect.Slice {
+				item = e.bytes(v.Index(i).Bytes())
+			} else {
+				item, err = e.scalar(v.Index(i), true)
+			}
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+		}
+		return list, nil
+	case reflect.Map:
+		if v.Len() == 0 {
+			return nil, nil
+		}
+		m := make(map[string]interface{})
+		for _, key := range v.MapKeys() {
+			item, err := e.scalar(v.MapIndex(key), true)
+			if err != nil {
+				return nil, err
+			}
+			m[fmt.Sprint(key.Interface())] = item
+		}
+		return m, nil
+	}
+	return e.scalar(v, false)
+}
+
+func (e *PythonJSONEncoder) bytes(value []byte) string {
+	if e.HexBytes {
+		return misc.Bin2HStr(value)
+	}
+	return base64.StdEncoding.EncodeToString(value)
+}
+
+// scalar encodes a singular value. Inside lists and maps default values
+// are kept, as the position or key carries meaning.
+func (e *PythonJSONEncoder) scalar(v reflect.Value, keepDefault bool) (interface{}, error) {
+	if v.Kind() == reflect.Ptr {
+		return e.message(v)
+	}
+	if !keepDefault && v.Interface() == reflect.Zero(v.Type()).Interface() {
+		return nil, nil
+	}
+
+	if stringer, ok := v.Interface().(fmt.Stringer); ok && v.Kind() == reflect.Int32 {
+		// Enum values are written by name
+		return stringer.String(), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int32, reflect.Uint32:
+		return v.Interface(), nil
+	case reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	}
+	return nil, fmt.Errorf("unsupported kind %s", v.Kind())
+}
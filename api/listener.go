@@ -0,0 +1,24 @@
+package api
+
+import (
+	"fmt"
+	"github.com/cyyber/go-qrl/core"
+	"github.com/cyyber/go-qrl/misc"
+	"net"
+)
+
+// Listen binds the listener of an API, dropping connections rejected by
+// the CIDR filters of its config
+func Listen(config *core.APIConfig) (net.Listener, error) {
+	filter, err := misc.NewCIDRFilter(config.AllowedCIDRs, config.DeniedCIDRs)
+	if err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", config.Host, config.Port))
+	if err != nil {
+		return nil, err
+	}
+
+	return misc.NewFilteredListener(listener, filter.Allowed), nil
+}
@@ -0,0 +1,150 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"github.com/cyyber/go-qrl/core"
+	"github.com/cyyber/go-qrl/core/pool"
+	"github.com/cyyber/go-qrl/generated"
+	"github.com/cyyber/go-qrl/misc"
+	"golang.org/x/net/context"
+)
+
+const (
+	defaultMempoolPageSize = 100
+	maxMempoolPageSize     = 1000
+)
+
+type MempoolTxSummary struct {
+	TxHash   []byte
+	Type     string
+	AddrFrom []byte
+	Fee      uint64
+	Size     int
+	// Seconds since the transaction entered the pool
+	Age uint64
+}
+
+type GetMempoolReq struct {
+	// Only return transactions sent from or to this address
+	Address []byte
+	// Only return transactions of this type, as returned by TransactionType
+	Type string
+	// TxHash of the last transaction of the previous page
+	Cursor []byte
+	Limit  uint32
+}
+
+type GetMempoolResp struct {
+	Transactions []*MempoolTxSummary
+	// Cursor for the next page, nil when there are no more transactions
+	NextCursor []byte
+	Total      uint64
+}
+
+// TransactionType returns the name of the transaction type set in pbdata
+func TransactionType(pbdata *generated.Transaction) string {
+	return core.TransactionTypeName(pbdata)
+}
+
+func involvesAddress(ti *pool.TransactionInfo, address []byte) bool {
+	tx := ti.Transaction()
+	if bytes.Equal(tx.AddrFrom(), address) {
+		return true
+	}
+	pbdata := tx.PBData()
+	if transfer := pbdata.GetTransfer(); transfer != nil {
+		for _, addrTo := range transfer.AddrsTo {
+			if bytes.Equal(addrTo, address) {
+				return true
+			}
+		}
+	}
+	if transferToken := pbdata.GetTransferToken(); transferToken != nil {
+		for _, addrTo := range transferToken.AddrsTo {
+			if bytes.Equal(addrTo, address) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// GetMempool lists pending transactions in the order they entered the pool
+func (p *PublicAPIServer) GetMempool(ctx context.Context, in *GetMempoolReq) (*GetMempoolResp, error) {
+	if in.Address != nil {
+		if err := misc.ValidateAddress(in.Address); err != nil {
+			return nil, err
+		}
+	}
+
+	limit := int(in.Limit)
+	if limit == 0 {
+		limit = defaultMempoolPageSize
+	}
+	if limit > maxMempoolPageSize {
+		limit = maxMempoolPageSize
+	}
+
+	now := p.ntp.Time()
+	resp := &GetMempoolResp{}
+	started := in.Cursor == nil
+
+	for _, ti := range p.chain.PendingTransactions() {
+		tx := ti.Transaction()
+		if !started {
+			started = bytes.Equal(tx.Txhash(), in.Cursor)
+			continue
+		}
+		if in.Address != nil && !involvesAddress(ti, in.Address) {
+			continue
+		}
+		txType := TransactionType(tx.PBData())
+		if in.Type != "" && in.Type != txType {
+			continue
+		}
+
+		resp.Total++
+		if len(resp.Transactions) == limit {
+			continue
+		}
+
+		summary := &MempoolTxSummary{
+			TxHash:   tx.Txhash(),
+			Type:     txType,
+			AddrFrom: tx.AddrFrom(),
+			Fee:      tx.Fee(),
+			Size:     tx.Size(),
+		}
+		if now > ti.Timestamp() {
+			summary.Age = now - ti.Timestamp()
+		}
+		resp.Transactions = append(resp.Transactions, summary)
+	}
+
+	if !started {
+		return nil, errors.New("cursor not found in mempool")
+	}
+
+	if uint64(len(resp.Transactions)) < resp.Total {
+		resp.NextCursor = resp.Transactions[len(resp.Transactions)-1].TxHash
+	}
+
+	return resp, nil
+}
+
+// GetMempoolStats returns the pool counters and the fee per byte
+// percentiles of the pending transactions, for fee estimation in wallets
+func (p *PublicAPIServer) GetMempoolStats(ctx context.Context) (*pool.Stats, error) {
+	return p.chain.MempoolStats(), nil
+}
+
+// GetMempoolTransaction returns the full protobuf of a pending transaction
+func (p *PublicAPIServer) GetMempoolTransaction(ctx context.Context, txHash []byte) (*generated.Transaction, error) {
+	for _, ti := range p.chain.PendingTransactions() {
+		if bytes.Equal(ti.Transaction().Txhash(), txHash) {
+			return ti.Transaction().PBData(), nil
+		}
+	}
+	return nil, errors.New("transaction not found in mempool")
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"errors"
+	"github.com/cyyber/go-qrl/generated"
+	"golang.org/x/net/context"
+)
+
+const maxMiniBlocks = 100
+
+// GetMiniBlocks returns compact summaries for the mainchain blocks of the
+// requested range, stopping at the current tip. They are enough for wallets
+// to render the history without downloading full blocks.
+func (p *PublicAPIServer) GetMiniBlocks(ctx context.Context, in *generated.GetMiniBlocksReq) (*generated.GetMiniBlocksResp, error) {
+	count := uint64(in.Count)
+	if count == 0 {
+		return nil, errors.New("count must be greater than 0")
+	}
+	if count > maxMiniBlocks {
+		count = maxMiniBlocks
+	}
+
+	height := p.chain.GetLastBlock().BlockNumber()
+	resp := &generated.GetMiniBlocksResp{}
+	for blockNumber := in.FromBlockNumber; blockNumber < in.FromBlockNumber+count && blockNumber <= height; blockNumber++ {
+		block, err := p.chain.GetBlockByNumber(blockNumber)
+		if err != nil {
+			return nil, err
+		}
+
+		miniBlock := &generated.MiniBlock{
+			BlockNumber:      block.BlockNumber(),
+			HeaderHash:       block.HeaderHash(),
+			TimestampSeconds: uint64(block.Timestamp()),
+			TransactionCount: uint32(len(block.Transactions())),
+		}
+		for _, tx := range block.Transactions() {
+			if coinbase := tx.GetCoinbase(); coinbase != nil {
+				miniBlock.MinerAddress = coinbase.AddrTo
+			}
+			if transfer := tx.GetTransfer(); transfer != nil {
+				for _, amount := range transfer.Amounts {
+					miniBlock.TotalTransferred += amount
+				}
+			}
+		}
+		resp.MiniBlocks = append(resp.MiniBlocks, miniBlock)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"github.com/cyyber/go-qrl/core"
+	"github.com/cyyber/go-qrl/generated"
+	"github.com/cyyber/go-qrl/log"
+	"github.com/cyyber/go-qrl/misc"
+	"github.com/theQRL/qryptonight/goqryptonight"
+	"golang.org/x/net/context"
+	"math/big"
+	"time"
+)
+
+type MiningAPIServer struct {
+	chain  *core.Chain
+	config *core.Config
+	log    log.Logger
+
+	extraNonces *extraNonceAllocator
+}
+
+func NewMiningAPIServer(chain *core.Chain, config *core.Config, log log.Logger) *MiningAPIServer {
+	return &MiningAPIServer{
+		chain:  chain,
+		config: config,
+		log:    log,
+		extraNonces: newExtraNonceAllocator(config.User.Miner.ExtraNoncePartitionBits,
+			time.Duration(config.User.Miner.ExtraNonceLeaseMinutes)*time.Minute),
+	}
+}
+
+func (m *MiningAPIServer) GetBlockToMine(ctx context.Context, in *generated.GetBlockToMineReq) (*generated.GetBlockToMineResp, error) {
+	minerAddress, err := misc.ParseQaddress(in.WalletAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	template, err := m.chain.CreateBlockTemplate(minerAddress, m.chain.Clock().Time())
+	if err != nil {
+		return nil, err
+	}
+	return m.blockToMineResp(template, in.WorkerId)
+}
+
+type GetBlockTemplateLongPollReq struct {
+	WalletAddress string
+	WorkerID      string
+	// LongPollID of the template being mined, empty for the first request
+	LongPollID string
+	// Longest time the request is held, capped by the LongPollTimeout
+	// setting which is also used when it's 0
+	TimeoutSeconds uint32
+}
+
+type GetBlockTemplateLongPollResp struct {
+	Template   *generated.GetBlockToMineResp
+	LongPollID string
+}
+
+// GetBlockTemplateLongPoll holds the request until a better template than
+// the one identified by LongPollID is available, a new tip or higher fees,
+// then returns it. The current template is returned on timeout.
+func (m *MiningAPIServer) GetBlockTemplateLongPoll(ctx context.Context, in *GetBlockTemplateLongPollReq) (*GetBlockTemplateLongPollResp, error) {
+	minerAddress, err := misc.ParseQaddress(in.WalletAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := m.config.Settings().Miner.LongPollTimeout
+	if in.TimeoutSeconds != 0 && in.TimeoutSeconds < timeout {
+		timeout = in.TimeoutSeconds
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	defer cancel()
+
+	template, err := m.chain.WaitBlockTemplate(waitCtx, minerAddress, in.LongPollID)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := m.blockToMineResp(template, in.WorkerID)
+	if err != nil {
+		return nil, err
+	}
+	return &GetBlockTemplateLongPollResp{
+		Template:   resp,
+		LongPollID: template.LongPollID(),
+	}, nil
+}
+
+type GetMinerStatsReq struct {
+	// Unix times, To is now when 0
+	From uint64
+	To   uint64
+}
+
+type GetMinerStatsResp struct {
+	IntervalSeconds uint64
+	Intervals       []*core.MinerInterval
+}
+
+// GetMinerStats returns the blocks accepted and orphaned and the work of the
+// miners of the node, per interval, to chart their performance
+func (m *MiningAPIServer) GetMinerStats(ctx context.Context, in *GetMinerStatsReq) (*GetMinerStatsResp, error) {
+	to := in.To
+	if to == 0 {
+		to = m.chain.Clock().Time()
+	}
+	intervals, err := m.chain.GetMinerStats(in.From, to)
+	if err != nil {
+		return nil, err
+	}
+	return &GetMinerStatsResp{
+		IntervalSeconds: m.config.User.MinerStats.Interval,
+		Intervals:       intervals,
+	}, nil
+}
+
+// blockToMineResp returns the work for workerID, the blob holding the first
+// extra nonce of the range assigned to the worker
+func (m *MiningAPIServer) blockToMineResp(template *core.BlockTemplate, workerID string) (*generated.GetBlockToMineResp, error) {
+	start, end, err := m.extraNonces.assign(workerID, time.Unix(int64(m.chain.Clock().Time()), 0))
+	if err != nil {
+		return nil, err
+	}
+	template.Block.SetNonces(0, start)
+
+	difficulty := big.NewInt(0)
+	difficulty.SetString(goqryptonight.UInt256ToString(misc.BytesToUCharVector(template.Difficulty)), 10)
+
+	return &generated.GetBlockToMineResp{
+		BlocktemplateBlob: misc.Bin2HStr(template.Block.MiningBlob()),
+		Difficulty:        difficulty.Uint64(),
+		Height:            template.Block.BlockNumber(),
+		ReservedOffset:    uint32(m.config.Dev.ExtraNonceOffset),
+		BlockWeight:       template.Weight,
+		BlockWeightLimit:  template.WeightLimit,
+		ExtraNonceStart:   start,
+		ExtraNonceEnd:     end,
+	}, nil
+}
@@ -0,0 +1,289 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/cyyber/go-qrl/core"
+	"github.com/cyyber/go-qrl/core/transactions"
+	"github.com/cyyber/go-qrl/crypto"
+	"github.com/cyyber/go-qrl/generated"
+	"github.com/cyyber/go-qrl/log"
+	"github.com/cyyber/go-qrl/misc"
+	"github.com/cyyber/go-qrl/p2p"
+	"github.com/theQRL/qryptonight/goqryptonight"
+	"golang.org/x/net/context"
+	"sync"
+	"time"
+)
+
+type PublicAPIServer struct {
+	chain  *core.Chain
+	server *p2p.Server
+	config *core.Config
+	ntp    *misc.NTP
+	log    log.Logger
+
+	startedAt time.Time
+
+	faucetOnce sync.Once
+	faucet     *faucet
+}
+
+func NewPublicAPIServer(chain *core.Chain, server *p2p.Server, config *core.Config, log log.Logger) *PublicAPIServer {
+	return &PublicAPIServer{
+		chain:     chain,
+		server:    server,
+		config:    config,
+		ntp:       misc.GetNTP(),
+		log:       log,
+		startedAt: time.Now(),
+	}
+}
+
+func (p *PublicAPIServer) getNodeInfo() *generated.NodeInfo {
+	lastBlock := p.chain.GetLastBlock()
+	info := &generated.NodeInfo{
+		Version:        p.config.Dev.Genesis.Version,
+		State:          generated.NodeInfo_UNKNOWN,
+		NumConnections: uint32(p.server.PeerCount()),
+		Uptime:         uint64(time.Since(p.startedAt).Seconds()),
+		BlockHeight:    lastBlock.BlockNumber(),
+		BlockLastHash:  lastBlock.HeaderHash(),
+		NetworkId:      p.config.Dev.Network,
+	}
+	if mismatch, _ := p.chain.StateMismatch(); mismatch != nil {
+		info.StateMismatch = true
+		info.StateMismatchBlockNumber = mismatch.BlockNumber
+	}
+	return info
+}
+
+func (p *PublicAPIServer) GetNodeState(ctx context.Context, in *generated.GetNodeStateReq) (*generated.GetNodeStateResp, error) {
+	return &generated.GetNodeStateResp{Info: p.getNodeInfo()}, nil
+}
+
+// GetStats answers from the rolling statistics maintained by the Chain,
+// so the cost of the call doesn't grow with the chain height
+func (p *PublicAPIServer) GetStats(ctx context.Context, in *generated.GetStatsReq) (*generated.GetStatsResp, error) {
+	lastBlock := p.chain.GetLastBlock()
+	stats := p.chain.Stats()
+
+	coinsEmitted, err := p.chain.GetTotalCoinSupply()
+	if err != nil {
+		return nil, err
+	}
+
+	blocksPerEpoch := p.config.Dev.BlocksPerEpoch
+	resp := &generated.GetStatsResp{
+		NodeInfo:         p.getNodeInfo(),
+		Epoch:            lastBlock.BlockNumber() / blocksPerEpoch,
+		EpochProgress:    float32(lastBlock.BlockNumber()%blocksPerEpoch) / float32(blocksPerEpoch),
+		UptimeNetwork:    p.ntp.Time() - uint64(p.config.Dev.Genesis.GenesisTimestamp),
+		BlockLastReward:  lastBlock.BlockReward(),
+		BlockTimeMean:    stats.BlockTimeMean(),
+		BlockTimeSd:      stats.BlockTimeSD(),
+		CoinsTotalSupply: p.config.Dev.Genesis.MaxCoinSupply,
+		CoinsEmitted:     coinsEmitted,
+		NetworkHashrate:  stats.HashRate(),
+	}
+
+	if difficulty := stats.LastDifficulty(); difficulty != nil {
+		resp.BlockDifficulty = goqryptonight.UInt256ToString(misc.BytesToUCharVector(difficulty))
+	}
+
+	if in.IncludeTimeseries {
+		resp.BlockTimeseries = stats.DataPoints()
+	}
+
+	return resp, nil
+}
+
+// GetLatticeKeysByAddress returns the lattice public keys published by address
+func (p *PublicAPIServer) GetLatticeKeysByAddress(ctx context.Context, in *generated.GetLatticeKeysByAddressReq) (*generated.GetLatticeKeysByAddressResp, error) {
+	if err := misc.ValidateAddress(in.Address); err != nil {
+		return nil, err
+	}
+	addrState, err := p.chain.GetAddressState(in.Address)
+	if err != nil {
+		return nil, err
+	}
+	return &generated.GetLatticeKeysByAddressResp{LatticePks: addrState.LatticePKList()}, nil
+}
+
+// VerifyMessageSignature checks a message signed with the XMSS key of an
+// address. OtsIndexUsedOnChain is set when the OTS index has also been used
+// on chain by the address, a valid signature is still a proof of ownership
+// but reusing an OTS key weakens the security of the XMSS tree.
+func (p *PublicAPIServer) VerifyMessageSignature(ctx context.Context, in *generated.VerifyMessageSignatureReq) (*generated.VerifyMessageSignatureResp, error) {
+	if err := misc.ValidateAddress(in.Address); err != nil {
+		return nil, err
+	}
+
+	resp := &generated.VerifyMessageSignatureResp{}
+	if err := crypto.VerifyMessageSignature(in.Address, in.Message, in.Signature, in.Pk); err != nil {
+		resp.Error = err.Error()
+		return resp, nil
+	}
+	resp.Valid = true
+
+	otsIndex, _ := crypto.SignatureOTSIndex(in.Signature)
+	resp.OtsIndex = otsIndex
+
+	addrState, err := p.chain.GetAddressState(in.Address)
+	if err != nil {
+		return nil, err
+	}
+	if otsIndex <= 0xFFFF {
+		resp.OtsIndexUsedOnChain = addrState.OTSKeyReuse(uint16(otsIndex))
+	}
+
+	return resp, nil
+}
+
+func (p *PublicAPIServer) GetAddressState(ctx context.Context, in *generated.GetAddressStateReq) (*generated.GetAddressStateResp, error) {
+	if err := misc.ValidateAddress(in.Address); err != nil {
+		return nil, err
+	}
+	addrState, err := p.chain.GetAddressState(in.Address)
+	if err != nil {
+		return nil, err
+	}
+	return &generated.GetAddressStateResp{State: addrState.ExpandedPBData()}, nil
+}
+
+// PushTransaction submits a signed transaction to the pool
+func (p *PublicAPIServer) PushTransaction(ctx context.Context, in *generated.PushTransactionReq) (*generated.PushTransactionResp, error) {
+	if in.TransactionSigned == nil {
+		return &generated.PushTransactionResp{
+			ErrorCode:        generated.PushTransactionResp_VALIDATION_FAILED,
+			ErrorDescription: "missing transaction",
+		}, nil
+	}
+
+	tx := transactions.ProtoToTransaction(in.TransactionSigned)
+	if tx == nil {
+		return &generated.PushTransactionResp{
+			ErrorCode:        generated.PushTransactionResp_VALIDATION_FAILED,
+			ErrorDescription: "unsupported transaction type",
+		}, nil
+	}
+
+	if err := p.chain.SubmitTransaction(tx); err != nil {
+		return &generated.PushTransactionResp{
+			ErrorCode:        generated.PushTransactionResp_VALIDATION_FAILED,
+			ErrorDescription: err.Error(),
+		}, nil
+	}
+
+	return &generated.PushTransactionResp{
+		ErrorCode: generated.PushTransactionResp_SUBMITTED,
+		TxHash:    tx.Txhash(),
+	}, nil
+}
+
+const maxPushBatchSize = 100
+
+type PushTransactionsReq struct {
+	// Signed by the same key, in nonce order
+	Transactions []*generated.Transaction
+}
+
+type PushTransactionsResp struct {
+	// Result of each transaction, in the order of the request
+	Results []*generated.PushTransactionResp
+}
+
+// PushTransactions admits a batch of transactions signed by the same key
+// with consecutive nonces as a unit, so payout systems don't depend on the
+// order their single pushes are processed. If one transaction is rejected
+// none is admitted.
+func (p *PublicAPIServer) PushTransactions(ctx context.Context, in *PushTransactionsReq) (*PushTransactionsResp, error) {
+	if len(in.Transactions) > maxPushBatchSize {
+		return nil, fmt.Errorf("batch of %d transactions, maximum %d", len(in.Transactions), maxPushBatchSize)
+	}
+
+	resp := &PushTransactionsResp{}
+	var txs []transactions.TransactionInterface
+	for _, protoTx := range in.Transactions {
+		var tx transactions.TransactionInterface
+		if protoTx != nil {
+			tx = transactions.ProtoToTransaction(protoTx)
+		}
+		if tx == nil {
+			return nil, errors.New("missing or unsupported transaction in batch")
+		}
+		txs = append(txs, tx)
+	}
+
+	for i, err := range p.chain.SubmitTransactions(txs) {
+		if err != nil {
+			resp.Results = append(resp.Results, &generated.PushTransactionResp{
+				ErrorCode:        generated.PushTransactionResp_VALIDATION_FAILED,
+				ErrorDescription: err.Error(),
+				TxHash:           txs[i].Txhash(),
+			})
+			continue
+		}
+		resp.Results = append(resp.Results, &generated.PushTransactionResp{
+			ErrorCode: generated.PushTransactionResp_SUBMITTED,
+			TxHash:    txs[i].Txhash(),
+		})
+	}
+	return resp, nil
+}
+
+// GetObject looks query up as an address, then as a transaction hash
+func (p *PublicAPIServer) GetObject(ctx context.Context, in *generated.GetObjectReq) (*generated.GetObjectResp, error) {
+	if misc.ValidateAddress(in.Query) == nil {
+		addrState, err := p.chain.GetAddressState(in.Query)
+		if err != nil {
+			return nil, err
+		}
+		return &generated.GetObjectResp{
+			Found:  true,
+			Result: &generated.GetObjectResp_AddressState{AddressState: addrState.ExpandedPBData()},
+		}, nil
+	}
+
+	tm, err := p.chain.GetTxMetadata(in.Query)
+	if err != nil {
+		return &generated.GetObjectResp{Found: false}, nil
+	}
+	block, err := p.chain.GetBlockByNumber(tm.BlockNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := transactions.ProtoToTransaction(tm.Transaction)
+	txExtended := &generated.TransactionExtended{
+		Header:           block.PBData().Header,
+		Tx:               tm.Transaction,
+		AddrFrom:         tx.AddrFrom(),
+		Size:             uint64(tx.Size()),
+		TimestampSeconds: tm.Timestamp,
+	}
+	if confirmations, err := p.chain.GetTransactionConfirmations(in.Query); err == nil {
+		txExtended.Confirmations = confirmations.Confirmations
+		txExtended.ConfirmedAtDepth = confirmations.ConfirmedAtDepth
+	}
+	return &generated.GetObjectResp{
+		Found:  true,
+		Result: &generated.GetObjectResp_Transaction{Transaction: txExtended},
+	}, nil
+}
+
+// GetTransactionConfirmations returns the confirmations of a transaction
+// from the canonical tip, reorgs taken into account
+func (p *PublicAPIServer) GetTransactionConfirmations(ctx context.Context, txHash []byte) (*core.TxConfirmations, error) {
+	return p.chain.GetTransactionConfirmations(txHash)
+}
+
+// GetBalance returns the confirmed, unconfirmed and locked balances of
+// address, blocks with at least minConfirmations confirmations counting as
+// confirmed
+func (p *PublicAPIServer) GetBalance(ctx context.Context, address []byte, minConfirmations uint64) (*core.Balance, error) {
+	if err := misc.ValidateAddress(address); err != nil {
+		return nil, err
+	}
+	return p.chain.GetBalance(address, minConfirmations)
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"github.com/cyyber/go-qrl/core"
+	"github.com/cyyber/go-qrl/generated"
+	"github.com/cyyber/go-qrl/log"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server serves the gRPC services of one API on the listener of its config
+type Server struct {
+	config   *core.APIConfig
+	register func(*grpc.Server)
+	server   *grpc.Server
+	log      log.Logger
+}
+
+// NewPublicServer returns the server of the public API
+func NewPublicServer(publicAPI *PublicAPIServer, config *core.APIConfig, log log.Logger) *Server {
+	return &Server{
+		config: config,
+		register: func(s *grpc.Server) {
+			generated.RegisterPublicAPIServer(s, publicAPI)
+		},
+		log: log,
+	}
+}
+
+// Start serves the API in the background. A grpc.Server can't serve again
+// once stopped, so a new one is created on every start.
+func (s *Server) Start() error {
+	listener, err := Listen(s.config)
+	if err != nil {
+		return err
+	}
+	s.log.Info("Serving API", "address", listener.Addr().String())
+
+	server := grpc.NewServer(grpc.MaxConcurrentStreams(uint32(s.config.MaxConcurrentRPC)))
+	s.register(server)
+	s.server = server
+
+	go func() {
+		if err := server.Serve(listener); err != nil {
+			s.log.Warn("API server stopped", "error", err)
+		}
+	}()
+	return nil
+}
+
+// Stop waits for the pending calls to return
+func (s *Server) Stop() {
+	if s.server != nil {
+		s.server.GracefulStop()
+		s.server = nil
+	}
+}
+
+// The methods of the PublicAPI service not implemented by this node yet
+
+func (p *PublicAPIServer) GetKnownPeers(ctx context.Context, in *generated.GetKnownPeersReq) (*generated.GetKnownPeersResp, error) {
+	return nil, status.Error(codes.Unimplemented, "GetKnownPeers is not implemented")
+}
+
+func (p *PublicAPIServer) GetPeersStat(ctx context.Context, in *generated.GetPeersStatReq) (*generated.GetPeersStatResp, error) {
+	return nil, status.Error(codes.Unimplemented, "GetPeersStat is not implemented")
+}
+
+func (p *PublicAPIServer) GetLatestData(ctx context.Context, in *generated.GetLatestDataReq) (*generated.GetLatestDataResp, error) {
+	return nil, status.Error(codes.Unimplemented, "GetLatestData is not implemented")
+}
+
+func (p *PublicAPIServer) TransferCoins(ctx context.Context, in *generated.TransferCoinsReq) (*generated.TransferCoinsResp, error) {
+	return nil, status.Error(codes.Unimplemented, "TransferCoins is not implemented")
+}
+
+func (p *PublicAPIServer) GetAddressFromPK(ctx context.Context, in *generated.GetAddressFromPKReq) (*generated.GetAddressFromPKResp, error) {
+	return nil, status.Error(codes.Unimplemented, "GetAddressFromPK is not implemented")
+}
+
+func (p *PublicAPIServer) GetMessageTxn(ctx context.Context, in *generated.MessageTxnReq) (*generated.TransferCoinsResp, error) {
+	return nil, status.Error(codes.Unimplemented, "GetMessageTxn is not implemented")
+}
+
+func (p *PublicAPIServer) GetTokenTxn(ctx context.Context, in *generated.TokenTxnReq) (*generated.TransferCoinsResp, error) {
+	return nil, status.Error(codes.Unimplemented, "GetTokenTxn is not implemented")
+}
+
+func (p *PublicAPIServer) GetTransferTokenTxn(ctx context.Context, in *generated.TransferTokenTxnReq) (*generated.TransferCoinsResp, error) {
+	return nil, status.Error(codes.Unimplemented, "GetTransferTokenTxn is not implemented")
+}
+
+func (p *PublicAPIServer) GetSlaveTxn(ctx context.Context, in *generated.SlaveTxnReq) (*generated.TransferCoinsResp, error) {
+	return nil, status.Error(codes.Unimplemented, "GetSlaveTxn is not implemented")
+}
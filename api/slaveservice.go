@@ -0,0 +1,182 @@
+package api
+
+import (
+	"errors"
+	"github.com/cyyber/go-qrl/core"
+	"github.com/cyyber/go-qrl/generated"
+	"github.com/cyyber/go-qrl/log"
+	"github.com/cyyber/go-qrl/misc"
+	"github.com/cyyber/go-qrl/wallet"
+	"golang.org/x/net/context"
+	"path"
+	"sync"
+)
+
+var ErrSlaveNotRegistered = errors.New("slave key is not registered with full access to the master address")
+
+// SlaveSigningService is the walletd mode for automated payouts. Its wallet
+// only holds slave keys registered to a master address kept offline, and
+// every transfer must pass the local spending policy before being signed.
+type SlaveSigningService struct {
+	lock sync.Mutex
+
+	walletAPI *WalletAPIServer
+	master    []byte
+	policy    *wallet.Policy
+	log       log.Logger
+
+	// Round robin over the slaves, spreading the OTS keys usage
+	next int
+}
+
+func NewSlaveSigningService(chain *core.Chain, w *wallet.Wallet, config *core.Config, log log.Logger) (*SlaveSigningService, error) {
+	slaveConfig := config.User.Wallet.SlaveService
+	master, err := misc.ParseQaddress(slaveConfig.MasterAddress)
+	if err != nil {
+		return nil, err
+	}
+	for _, qaddress := range slaveConfig.AllowedDestinations {
+		if _, err := misc.ParseQaddress(qaddress); err != nil {
+			return nil, err
+		}
+	}
+
+	policy, err := wallet.LoadPolicy(path.Join(config.User.QrlDir, slaveConfig.SpendingFilename),
+		slaveConfig.MaxAmountPerTx,
+		slaveConfig.MaxAmountPerDay,
+		slaveConfig.AllowedDestinations)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &SlaveSigningService{
+		walletAPI: NewWalletAPIServer(chain, w, nil, config, log),
+		master:    master,
+		policy:    policy,
+		log:       log,
+	}
+	if err := s.checkSlaves(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// checkSlaves makes sure every key of the wallet is allowed to spend the
+// funds of the master address
+func (s *SlaveSigningService) checkSlaves() error {
+	qaddresses, err := s.walletAPI.wallet.Addresses()
+	if err != nil {
+		return err
+	}
+	if len(qaddresses) == 0 {
+		return errors.New("wallet holds no slave key")
+	}
+
+	for _, qaddress := range qaddresses {
+		address, err := misc.ParseQaddress(qaddress)
+		if err != nil {
+			return err
+		}
+		pk, err := s.walletAPI.wallet.PK(qaddress)
+		if err != nil {
+			return err
+		}
+		addrState, err := s.walletAPI.chain.GetAddressState(address)
+		if err != nil {
+			return err
+		}
+		if accessType, ok := addrState.GetSlavePermission(pk); !ok || accessType != 0 {
+			s.log.Warn("Slave key not registered", "address", qaddress)
+			return ErrSlaveNotRegistered
+		}
+	}
+	return nil
+}
+
+func (s *SlaveSigningService) nextSlave() (string, error) {
+	qaddresses, err := s.walletAPI.wallet.Addresses()
+	if err != nil {
+		return "", err
+	}
+	qaddress := qaddresses[s.next%len(qaddresses)]
+	s.next++
+	return qaddress, nil
+}
+
+// SendMany pays payouts from the master address. Each transaction is
+// checked against the policy, transactions relayed before a failure are
+// returned along with the error.
+func (s *SlaveSigningService) SendMany(ctx context.Context, payouts []*wallet.Payout, fee uint64) (*SendManyResp, error) {
+	limit := int(s.walletAPI.config.Dev.Transaction.MultiOutputLimit)
+	totalAmount, totalCost, err := wallet.ValidatePayouts(payouts, fee, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	masterState, err := s.walletAPI.chain.GetAddressState(s.master)
+	if err != nil {
+		return nil, err
+	}
+	if masterState.Balance() < totalCost {
+		return nil, wallet.ErrInsufficientBalance
+	}
+
+	resp := &SendManyResp{
+		TotalAmount: totalAmount,
+		TotalCost:   totalCost,
+	}
+	for _, batch := range wallet.SplitPayouts(payouts, limit) {
+		if err := s.policy.Reserve(batch, fee); err != nil {
+			s.log.Warn("Transfer rejected by policy", "error", err)
+			return resp, err
+		}
+		qaddress, err := s.nextSlave()
+		if err != nil {
+			return resp, err
+		}
+		tx, err := s.walletAPI.relayTransfer(qaddress, s.master, batch, fee)
+		if err != nil {
+			return resp, err
+		}
+		resp.Transactions = append(resp.Transactions, tx)
+	}
+	return resp, nil
+}
+
+func (s *SlaveSigningService) RelayTransferTxn(ctx context.Context, payouts []*wallet.Payout, fee uint64) (*generated.Transaction, error) {
+	if len(payouts) > int(s.walletAPI.config.Dev.Transaction.MultiOutputLimit) {
+		return nil, wallet.ErrTooManyOutputs
+	}
+	resp, err := s.SendMany(ctx, payouts, fee)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Transactions[0], nil
+}
+
+type SlavePolicyStatus struct {
+	MasterAddress   string
+	MaxAmountPerTx  uint64
+	MaxAmountPerDay uint64
+	SpentToday      uint64
+}
+
+func (s *SlaveSigningService) GetPolicyStatus(ctx context.Context) (*SlavePolicyStatus, error) {
+	return &SlavePolicyStatus{
+		MasterAddress:   misc.Qaddress(s.master),
+		MaxAmountPerTx:  s.policy.MaxAmountPerTx,
+		MaxAmountPerDay: s.policy.MaxAmountPerDay,
+		SpentToday:      s.policy.SpentToday(),
+	}, nil
+}
+
+func (s *SlaveSigningService) UnlockWallet(ctx context.Context, passphrase []byte) error {
+	return s.walletAPI.UnlockWallet(ctx, passphrase)
+}
+
+func (s *SlaveSigningService) LockWallet(ctx context.Context) error {
+	return s.walletAPI.LockWallet(ctx)
+}
@@ -0,0 +1,26 @@
+package api
+
+import (
+	"github.com/cyyber/go-qrl/core"
+	"golang.org/x/net/context"
+)
+
+type GetStateDiffReq struct {
+	FromHeight uint64
+	ToHeight   uint64
+}
+
+type GetStateDiffResp struct {
+	StateDiff *core.StateDiff
+}
+
+// GetStateDiff returns the addresses changed between two heights with their
+// balance and nonce deltas, for consumers following the chain incrementally.
+// Heights older than the reorg limit can't be diffed.
+func (p *PublicAPIServer) GetStateDiff(ctx context.Context, in *GetStateDiffReq) (*GetStateDiffResp, error) {
+	diff, err := p.chain.GetStateDiff(in.FromHeight, in.ToHeight)
+	if err != nil {
+		return nil, err
+	}
+	return &GetStateDiffResp{StateDiff: diff}, nil
+}
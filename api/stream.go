@@ -0,0 +1,27 @@
+package api
+
+import (
+	"github.com/cyyber/go-qrl/events"
+	"golang.org/x/net/context"
+)
+
+// StreamEvents forwards the events published on the given topics to send,
+// until the context is cancelled or send returns an error
+func StreamEvents(ctx context.Context, bus *events.Bus, topics []events.Topic, send func(*events.Event) error) error {
+	subscription := bus.Subscribe(topics...)
+	defer subscription.Unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event, ok := <-subscription.Events():
+			if !ok {
+				return nil
+			}
+			if err := send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package api
+
+import (
+	"errors"
+	"github.com/cyyber/go-qrl/core"
+	"github.com/cyyber/go-qrl/core/transactions"
+	"github.com/cyyber/go-qrl/generated"
+	"golang.org/x/net/context"
+)
+
+type TestTransactionReq struct {
+	TransactionSigned *generated.Transaction
+}
+
+type TestTransactionResp struct {
+	Verdict *core.TxVerdict
+}
+
+// TestTransaction reports whether a signed transaction would be accepted by
+// PushTransaction without submitting it, so withdrawal pipelines can check
+// a transaction before broadcasting it
+func (p *PublicAPIServer) TestTransaction(ctx context.Context, in *TestTransactionReq) (*TestTransactionResp, error) {
+	if in.TransactionSigned == nil {
+		return nil, errors.New("missing transaction")
+	}
+	tx := transactions.ProtoToTransaction(in.TransactionSigned)
+	if tx == nil {
+		return nil, errors.New("unsupported transaction type")
+	}
+	return &TestTransactionResp{Verdict: p.chain.TestTransaction(tx)}, nil
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"github.com/cyyber/go-qrl/core"
+	"golang.org/x/net/context"
+)
+
+const (
+	defaultTokenPageSize = 100
+	maxTokenPageSize     = 1000
+)
+
+type GetTokenHoldersReq struct {
+	TokenTxHash []byte
+	// Address of the last holder of the previous page
+	Cursor []byte
+	Limit  uint32
+}
+
+type GetTokenHoldersResp struct {
+	Holders []*core.TokenHolder
+	// Cursor for the next page, nil when there are no more holders
+	NextCursor []byte
+}
+
+type GetTokenTransfersReq struct {
+	TokenTxHash []byte
+	// NextCursor of the previous page
+	Cursor []byte
+	Limit  uint32
+}
+
+type GetTokenTransfersResp struct {
+	Transfers []*core.TokenTransfer
+	// Cursor for the next page, nil when there are no more transfers
+	NextCursor []byte
+}
+
+func tokenPageSize(limit uint32) int {
+	if limit == 0 {
+		return defaultTokenPageSize
+	}
+	if limit > maxTokenPageSize {
+		return maxTokenPageSize
+	}
+	return int(limit)
+}
+
+// GetTokenHolders lists the addresses holding a token with their balances,
+// in address order
+func (p *PublicAPIServer) GetTokenHolders(ctx context.Context, in *GetTokenHoldersReq) (*GetTokenHoldersResp, error) {
+	holders, next, err := p.chain.GetTokenHolders(in.TokenTxHash, in.Cursor, tokenPageSize(in.Limit))
+	if err != nil {
+		return nil, err
+	}
+	return &GetTokenHoldersResp{Holders: holders, NextCursor: next}, nil
+}
+
+// GetTokenTransfers lists the transfers of a token oldest first, starting
+// with the initial balances of the token transaction
+func (p *PublicAPIServer) GetTokenTransfers(ctx context.Context, in *GetTokenTransfersReq) (*GetTokenTransfersResp, error) {
+	transfers, next, err := p.chain.GetTokenTransfers(in.TokenTxHash, in.Cursor, tokenPageSize(in.Limit))
+	if err != nil {
+		return nil, err
+	}
+	return &GetTokenTransfersResp{Transfers: transfers, NextCursor: next}, nil
+}
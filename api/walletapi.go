@@ -0,0 +1,400 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"github.com/cyyber/go-qrl/client"
+	"github.com/cyyber/go-qrl/core"
+	"github.com/cyyber/go-qrl/core/transactions"
+	"github.com/cyyber/go-qrl/generated"
+	"github.com/cyyber/go-qrl/log"
+	"github.com/cyyber/go-qrl/misc"
+	"github.com/cyyber/go-qrl/wallet"
+	"golang.org/x/net/context"
+	"time"
+)
+
+// WalletAPIServer serves the walletd calls. It holds local wallet data and
+// must not be bound to a public interface.
+type WalletAPIServer struct {
+	chain  *core.Chain
+	wallet *wallet.Wallet
+	// Hardware signers, in addition to the local wallet
+	signers  []wallet.Signer
+	contacts *wallet.ContactBook
+	config   *core.Config
+	log      log.Logger
+}
+
+func NewWalletAPIServer(chain *core.Chain, w *wallet.Wallet, contacts *wallet.ContactBook, config *core.Config, log log.Logger) *WalletAPIServer {
+	return &WalletAPIServer{
+		chain:    chain,
+		wallet:   w,
+		contacts: contacts,
+		config:   config,
+		log:      log,
+	}
+}
+
+func (w *WalletAPIServer) AddSigner(signer wallet.Signer) {
+	w.signers = append(w.signers, signer)
+}
+
+// signerFor returns the signer holding the key of qaddress
+func (w *WalletAPIServer) signerFor(qaddress string) (wallet.Signer, error) {
+	for _, signer := range append([]wallet.Signer{w.wallet}, w.signers...) {
+		qaddresses, err := signer.Addresses()
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range qaddresses {
+			if a == qaddress {
+				return signer, nil
+			}
+		}
+	}
+	return nil, wallet.ErrAccountNotFound
+}
+
+// RelayTransferTxn builds a transfer from qaddress, signs it with the
+// signer holding the address and submits it to the pool
+func (w *WalletAPIServer) RelayTransferTxn(ctx context.Context, qaddress string, payouts []*wallet.Payout, fee uint64) (*generated.Transaction, error) {
+	limit := int(w.config.Dev.Transaction.MultiOutputLimit)
+	if _, _, err := wallet.ValidatePayouts(payouts, fee, limit); err != nil {
+		return nil, err
+	}
+	if len(payouts) > limit {
+		return nil, wallet.ErrTooManyOutputs
+	}
+	return w.relayTransfer(qaddress, nil, payouts, fee)
+}
+
+type SendManyResp struct {
+	Transactions []*generated.Transaction
+	TotalAmount  uint64
+	// Amounts plus the fee of every transaction
+	TotalCost uint64
+}
+
+// SendMany pays every payout from qaddress, splitting them in as many
+// transactions as MultiOutputLimit requires. Transactions relayed before a
+// failure are returned along with the error.
+func (w *WalletAPIServer) SendMany(ctx context.Context, qaddress string, payouts []*wallet.Payout, fee uint64) (*SendManyResp, error) {
+	limit := int(w.config.Dev.Transaction.MultiOutputLimit)
+	totalAmount, totalCost, err := wallet.ValidatePayouts(payouts, fee, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	address, err := misc.ParseQaddress(qaddress)
+	if err != nil {
+		return nil, err
+	}
+	addrState, err := w.chain.GetAddressState(address)
+	if err != nil {
+		return nil, err
+	}
+	if addrState.Balance() < totalCost {
+		return nil, wallet.ErrInsufficientBalance
+	}
+
+	resp := &SendManyResp{
+		TotalAmount: totalAmount,
+		TotalCost:   totalCost,
+	}
+	for _, batch := range wallet.SplitPayouts(payouts, limit) {
+		tx, err := w.relayTransfer(qaddress, nil, batch, fee)
+		if err != nil {
+			return resp, err
+		}
+		resp.Transactions = append(resp.Transactions, tx)
+	}
+	return resp, nil
+}
+
+// relayTransfer signs with the key of qaddress, masterAddr is set when the
+// key is a slave spending the funds of masterAddr
+func (w *WalletAPIServer) relayTransfer(qaddress string, masterAddr []byte, payouts []*wallet.Payout, fee uint64) (*generated.Transaction, error) {
+	address, err := misc.ParseQaddress(qaddress)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := w.signerFor(qaddress)
+	if err != nil {
+		return nil, err
+	}
+	pk, err := signer.PK(qaddress)
+	if err != nil {
+		return nil, err
+	}
+
+	chainOTSIndex, err := w.chain.NextUnusedOTSIndex(address)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := wallet.CreateTransfer(payouts, fee, pk, masterAddr)
+	if err != nil {
+		return nil, err
+	}
+	tx.PBData().Nonce = w.chain.NextNonce(address)
+
+	signature, _, err := signer.SignNext(qaddress, chainOTSIndex, tx)
+	if err != nil {
+		return nil, err
+	}
+	tx.PBData().Signature = signature
+	tx.UpdateTxhash(tx.GetHashableBytes())
+
+	if err := w.chain.SubmitTransaction(tx); err != nil {
+		return nil, err
+	}
+	w.log.Info("Relayed transfer", "from", qaddress, "txhash", misc.Bin2HStr(tx.Txhash()))
+
+	return tx.PBData(), nil
+}
+
+func (w *WalletAPIServer) UnlockWallet(ctx context.Context, passphrase []byte) error {
+	return w.wallet.Unlock(passphrase)
+}
+
+func (w *WalletAPIServer) LockWallet(ctx context.Context) error {
+	w.wallet.Lock()
+	return nil
+}
+
+func (w *WalletAPIServer) IsLocked(ctx context.Context) (bool, error) {
+	return w.wallet.IsLocked(), nil
+}
+
+func (w *WalletAPIServer) ListAddresses(ctx context.Context) ([]string, error) {
+	return w.wallet.Addresses()
+}
+
+func (w *WalletAPIServer) ListAccounts(ctx context.Context) ([]*wallet.AccountInfo, error) {
+	return w.wallet.Accounts(), nil
+}
+
+// AddNewAddress requires the wallet to be unlocked
+func (w *WalletAPIServer) AddNewAddress(ctx context.Context, height uint, hashFunction string) (string, error) {
+	return w.wallet.AddNewAddress(height, hashFunction)
+}
+
+func (w *WalletAPIServer) AddContact(ctx context.Context, label string, qaddress string) error {
+	return w.contacts.Add(label, qaddress)
+}
+
+func (w *WalletAPIServer) UpdateContact(ctx context.Context, label string, qaddress string) error {
+	return w.contacts.Update(label, qaddress)
+}
+
+func (w *WalletAPIServer) RemoveContact(ctx context.Context, qaddress string) error {
+	return w.contacts.Remove(qaddress)
+}
+
+func (w *WalletAPIServer) GetContact(ctx context.Context, qaddress string) (*wallet.Contact, error) {
+	return w.contacts.Get(qaddress)
+}
+
+func (w *WalletAPIServer) GetContacts(ctx context.Context) ([]*wallet.Contact, error) {
+	return w.contacts.List(), nil
+}
+
+type TransactionHistoryEntry struct {
+	TxHash      []byte
+	Type        string
+	BlockNumber uint64
+	Timestamp   uint64
+	AddrFrom    []byte
+	FromLabel   string
+	AddrsTo     [][]byte
+	// Labels of AddrsTo, empty for addresses missing from the contact book
+	ToLabels []string
+	Amounts  []uint64
+	Fee      uint64
+}
+
+func (w *WalletAPIServer) historyEntry(tm *generated.TransactionMetadata) *TransactionHistoryEntry {
+	tx := tm.Transaction
+	addrFrom := transactions.ProtoToTransaction(tx).AddrFrom()
+	entry := &TransactionHistoryEntry{
+		TxHash:      tx.TransactionHash,
+		Type:        TransactionType(tx),
+		BlockNumber: tm.BlockNumber,
+		Timestamp:   tm.Timestamp,
+		AddrFrom:    addrFrom,
+		FromLabel:   w.contacts.Label(addrFrom),
+		Fee:         tx.Fee,
+	}
+
+	if transfer := tx.GetTransfer(); transfer != nil {
+		entry.AddrsTo = transfer.AddrsTo
+		entry.Amounts = transfer.Amounts
+	} else if transferToken := tx.GetTransferToken(); transferToken != nil {
+		entry.AddrsTo = transferToken.AddrsTo
+		entry.Amounts = transferToken.Amounts
+	} else if coinbase := tx.GetCoinbase(); coinbase != nil {
+		entry.AddrsTo = [][]byte{coinbase.AddrTo}
+		entry.Amounts = []uint64{coinbase.Amount}
+	}
+	for _, addrTo := range entry.AddrsTo {
+		entry.ToLabels = append(entry.ToLabels, w.contacts.Label(addrTo))
+	}
+
+	return entry
+}
+
+// GetTransactionHistory returns the transactions of address, newest first,
+// with the contact labels of the addresses involved
+func (w *WalletAPIServer) GetTransactionHistory(ctx context.Context, qaddress string) ([]*TransactionHistoryEntry, error) {
+	address, err := misc.ParseQaddress(qaddress)
+	if err != nil {
+		return nil, err
+	}
+
+	addrState, err := w.chain.GetAddressState(address)
+	if err != nil {
+		return nil, err
+	}
+
+	var history []*TransactionHistoryEntry
+	hashes := addrState.TransactionHashes()
+	for i := len(hashes) - 1; i >= 0; i-- {
+		tm, err := w.chain.GetTxMetadata(hashes[i])
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(tm.Transaction.TransactionHash, hashes[i]) {
+			continue
+		}
+		history = append(history, w.historyEntry(tm))
+	}
+
+	return history, nil
+}
+
+func (w *WalletAPIServer) ExportMnemonic(ctx context.Context, qaddress string) (string, error) {
+	return w.wallet.ExportMnemonic(qaddress)
+}
+
+func (w *WalletAPIServer) ExportHexSeed(ctx context.Context, qaddress string) (string, error) {
+	return w.wallet.ExportHexSeed(qaddress)
+}
+
+type RecoverAddressResp struct {
+	Qaddress string
+	Balance  uint64
+	// Number of transactions found on chain signed by the address
+	SignedTransactions int
+	// OTS index the wallet will use for the next signature
+	NextOTSIndex uint64
+}
+
+// RecoverAddress imports a wallet from its mnemonic or hexseed, then scans
+// the chain for the OTS keys the address already used so signing resumes
+// without reusing any of them
+func (w *WalletAPIServer) RecoverAddress(ctx context.Context, mnemonic string, hexSeed string) (*RecoverAddressResp, error) {
+	var qaddress string
+	var err error
+	if mnemonic != "" {
+		qaddress, err = w.wallet.ImportMnemonic(mnemonic)
+	} else {
+		qaddress, err = w.wallet.ImportHexSeed(hexSeed)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	address, err := misc.ParseQaddress(qaddress)
+	if err != nil {
+		return nil, err
+	}
+	addrState, err := w.chain.GetAddressState(address)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &RecoverAddressResp{
+		Qaddress: qaddress,
+		Balance:  addrState.Balance(),
+	}
+
+	// The bitfield only tracks the lowest indexes, the transactions signed
+	// by the address give the highest index used
+	nextOTSIndex, err := w.chain.NextUnusedOTSIndex(address)
+	if err != nil {
+		return nil, err
+	}
+	for _, txHash := range addrState.TransactionHashes() {
+		tm, err := w.chain.GetTxMetadata(txHash)
+		if err != nil {
+			return nil, err
+		}
+		tx := transactions.ProtoToTransaction(tm.Transaction)
+		if !bytes.Equal(tx.AddrFromPK(), address) {
+			continue
+		}
+		resp.SignedTransactions++
+		if otsIndex := uint64(tx.OtsKey()) + 1; otsIndex > nextOTSIndex {
+			nextOTSIndex = otsIndex
+		}
+	}
+
+	if err := w.wallet.SetOTSIndex(qaddress, nextOTSIndex); err != nil {
+		return nil, err
+	}
+	resp.NextOTSIndex = nextOTSIndex
+	w.log.Info("Recovered address", "address", qaddress, "next OTS index", nextOTSIndex)
+
+	return resp, nil
+}
+
+type GetQRCodeResp struct {
+	URI string
+	// Set for the png format
+	PNG []byte
+	// Set for the ascii format
+	ASCII string
+}
+
+// ParsePaymentURI decodes a qrl: payment request, expired requests are
+// rejected
+func (w *WalletAPIServer) ParsePaymentURI(ctx context.Context, uri string) (*client.PaymentRequest, error) {
+	request, err := client.ParseURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	if request.IsExpired(time.Now()) {
+		return nil, errors.New("payment request expired")
+	}
+	return request, nil
+}
+
+// GetQRCode renders a payment request for qaddress, amount and message
+// being optional, as a png or ascii QR code
+func (w *WalletAPIServer) GetQRCode(ctx context.Context, qaddress string, amount uint64, message string, format string, size int) (*GetQRCodeResp, error) {
+	if _, err := misc.ParseQaddress(qaddress); err != nil {
+		return nil, err
+	}
+
+	request := &client.PaymentRequest{
+		Qaddress: qaddress,
+		Amount:   amount,
+		Message:  message,
+	}
+	resp := &GetQRCodeResp{URI: request.URI()}
+
+	var err error
+	switch format {
+	case "png":
+		resp.PNG, err = client.QRCodePNG(resp.URI, size)
+	case "ascii":
+		resp.ASCII, err = client.QRCodeASCII(resp.URI)
+	default:
+		err = errors.New("unknown QR code format")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
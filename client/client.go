@@ -0,0 +1,112 @@
+// Package client is the Go SDK for applications talking to a node over the
+// public gRPC API. Keys never leave the application, transactions are
+// signed locally through a wallet.Signer.
+package client
+
+import (
+	"errors"
+	"github.com/cyyber/go-qrl/core"
+	"github.com/cyyber/go-qrl/generated"
+	"github.com/cyyber/go-qrl/misc"
+	"github.com/cyyber/go-qrl/wallet"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+type Client struct {
+	conn   *grpc.ClientConn
+	api    generated.PublicAPIClient
+	config *core.Config
+}
+
+// Dial connects to the public API of the node at address, as host:port
+func Dial(address string, config *core.Config) (*Client, error) {
+	conn, err := grpc.Dial(address, grpc.WithInsecure())
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		conn:   conn,
+		api:    generated.NewPublicAPIClient(conn),
+		config: config,
+	}, nil
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+func (c *Client) GetAddressState(ctx context.Context, address []byte) (*core.AddressState, error) {
+	resp, err := c.api.GetAddressState(ctx, &generated.GetAddressStateReq{Address: address})
+	if err != nil {
+		return nil, err
+	}
+	return core.NewAddressState(resp.State, c.config), nil
+}
+
+// PushTransaction relays a signed transaction and returns its hash
+func (c *Client) PushTransaction(ctx context.Context, tx *generated.Transaction) ([]byte, error) {
+	resp, err := c.api.PushTransaction(ctx, &generated.PushTransactionReq{TransactionSigned: tx})
+	if err != nil {
+		return nil, err
+	}
+	if resp.ErrorCode != generated.PushTransactionResp_SUBMITTED {
+		return nil, errors.New(resp.ErrorDescription)
+	}
+	return resp.TxHash, nil
+}
+
+// SendMany pays payouts from qaddress, with one transfer per batch of
+// MultiOutputLimit outputs. Nonces and OTS indexes are derived from the
+// state of the address, so no other transaction of the address must be
+// pending. Transactions relayed before a failure are returned with the
+// error.
+func (c *Client) SendMany(ctx context.Context, signer wallet.Signer, qaddress string, payouts []*wallet.Payout, fee uint64) ([]*generated.Transaction, error) {
+	limit := int(c.config.Dev.Transaction.MultiOutputLimit)
+	_, totalCost, err := wallet.ValidatePayouts(payouts, fee, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	address, err := misc.ParseQaddress(qaddress)
+	if err != nil {
+		return nil, err
+	}
+	pk, err := signer.PK(qaddress)
+	if err != nil {
+		return nil, err
+	}
+	addrState, err := c.GetAddressState(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	if addrState.Balance() < totalCost {
+		return nil, wallet.ErrInsufficientBalance
+	}
+
+	var relayed []*generated.Transaction
+	nonce := addrState.Nonce() + 1
+	chainOTSIndex := addrState.NextUnusedOTSIndex(0)
+	for _, batch := range wallet.SplitPayouts(payouts, limit) {
+		tx, err := wallet.CreateTransfer(batch, fee, pk, nil)
+		if err != nil {
+			return relayed, err
+		}
+		tx.PBData().Nonce = nonce
+		signature, otsIndex, err := signer.SignNext(qaddress, chainOTSIndex, tx)
+		if err != nil {
+			return relayed, err
+		}
+		tx.PBData().Signature = signature
+		tx.UpdateTxhash(tx.GetHashableBytes())
+
+		if _, err := c.PushTransaction(ctx, tx.PBData()); err != nil {
+			return relayed, err
+		}
+		relayed = append(relayed, tx.PBData())
+
+		nonce++
+		chainOTSIndex = addrState.NextUnusedOTSIndex(otsIndex + 1)
+	}
+	return relayed, nil
+}
@@ -0,0 +1,206 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"github.com/cyyber/go-qrl/generated"
+	"golang.org/x/net/context"
+	"sync"
+)
+
+type Direction string
+
+// historyBatchSize is the number of transactions History fetches
+// concurrently
+const historyBatchSize = 32
+
+const (
+	DirectionIn  Direction = "in"
+	DirectionOut Direction = "out"
+	// Transactions sent by the address to itself
+	DirectionSelf Direction = "self"
+)
+
+// HistoryEntry is a transaction seen from one address. Amounts are in
+// Shor, token amounts are not included.
+type HistoryEntry struct {
+	TxHash      []byte
+	Type        string
+	BlockNumber uint64
+	Timestamp   uint64
+	Direction   Direction
+	AddrFrom    []byte
+	AddrsTo     [][]byte
+	// Quanta received by the address minus the Quanta it sent, fee included
+	Delta int64
+	Fee   uint64
+	// Balance of the address once the transaction was applied
+	Balance uint64
+}
+
+// HistoryFilter selects entries, zero values don't filter
+type HistoryFilter struct {
+	// Unix timestamps, inclusive
+	From      uint64
+	To        uint64
+	Direction Direction
+}
+
+func (f *HistoryFilter) match(entry *HistoryEntry) bool {
+	if f.From != 0 && entry.Timestamp < f.From {
+		return false
+	}
+	if f.To != 0 && entry.Timestamp > f.To {
+		return false
+	}
+	if f.Direction != "" && f.Direction != entry.Direction {
+		return false
+	}
+	return true
+}
+
+func (c *Client) GetTransaction(ctx context.Context, txHash []byte) (*generated.TransactionExtended, error) {
+	resp, err := c.api.GetObject(ctx, &generated.GetObjectReq{Query: txHash})
+	if err != nil {
+		return nil, err
+	}
+	result, ok := resp.Result.(*generated.GetObjectResp_Transaction)
+	if !resp.Found || !ok {
+		return nil, errors.New("transaction not found")
+	}
+	return result.Transaction, nil
+}
+
+func transactionType(tx *generated.Transaction) string {
+	switch tx.TransactionType.(type) {
+	case *generated.Transaction_Transfer_:
+		return "transfer"
+	case *generated.Transaction_Coinbase:
+		return "coinbase"
+	case *generated.Transaction_LatticePK:
+		return "latticePK"
+	case *generated.Transaction_Message_:
+		return "message"
+	case *generated.Transaction_Token_:
+		return "token"
+	case *generated.Transaction_TransferToken_:
+		return "transfer_token"
+	case *generated.Transaction_Slave_:
+		return "slave"
+	}
+	return "unknown"
+}
+
+func historyEntry(address []byte, txExtended *generated.TransactionExtended) *HistoryEntry {
+	tx := txExtended.Tx
+	entry := &HistoryEntry{
+		TxHash:    tx.TransactionHash,
+		Type:      transactionType(tx),
+		Timestamp: txExtended.TimestampSeconds,
+		AddrFrom:  txExtended.AddrFrom,
+	}
+	if txExtended.Header != nil {
+		entry.BlockNumber = txExtended.Header.BlockNumber
+	}
+
+	var received, sent uint64
+	if transfer := tx.GetTransfer(); transfer != nil {
+		entry.AddrsTo = transfer.AddrsTo
+		for i, addrTo := range transfer.AddrsTo {
+			if bytes.Equal(addrTo, address) {
+				received += transfer.Amounts[i]
+			}
+			sent += transfer.Amounts[i]
+		}
+	} else if transferToken := tx.GetTransferToken(); transferToken != nil {
+		entry.AddrsTo = transferToken.AddrsTo
+	} else if coinbase := tx.GetCoinbase(); coinbase != nil {
+		entry.AddrsTo = [][]byte{coinbase.AddrTo}
+		if bytes.Equal(coinbase.AddrTo, address) {
+			received += coinbase.Amount
+		}
+	}
+
+	entry.Direction = DirectionIn
+	if bytes.Equal(txExtended.AddrFrom, address) && tx.GetCoinbase() == nil {
+		entry.Fee = tx.Fee
+		entry.Delta = int64(received) - int64(sent) - int64(tx.Fee)
+		entry.Direction = DirectionOut
+		if received > 0 && received == sent {
+			entry.Direction = DirectionSelf
+		}
+	} else {
+		entry.Delta = int64(received)
+	}
+
+	return entry
+}
+
+// getTransactions fetches the transactions hashes concurrently
+func (c *Client) getTransactions(ctx context.Context, hashes [][]byte) ([]*generated.TransactionExtended, error) {
+	txs := make([]*generated.TransactionExtended, len(hashes))
+	errs := make([]error, len(hashes))
+	var wg sync.WaitGroup
+	for i, hash := range hashes {
+		wg.Add(1)
+		go func(i int, hash []byte) {
+			defer wg.Done()
+			txs[i], errs[i] = c.GetTransaction(ctx, hash)
+		}(i, hash)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return txs, nil
+}
+
+// History returns the mined transactions of address, oldest first, with
+// the balance of the address after each of them. The running balance is
+// computed back from the current balance, so it is exact whatever the
+// filter. Transactions are fetched by batches from the most recent, and
+// fetching stops at the first one older than filter.From.
+func (c *Client) History(ctx context.Context, address []byte, filter *HistoryFilter) ([]*HistoryEntry, error) {
+	addrState, err := c.GetAddressState(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := addrState.TransactionHashes()
+	balance := int64(addrState.Balance())
+	// Most recent first
+	var entries []*HistoryEntry
+	for end := len(hashes); end > 0; end -= historyBatchSize {
+		start := end - historyBatchSize
+		if start < 0 {
+			start = 0
+		}
+		txs, err := c.getTransactions(ctx, hashes[start:end])
+		if err != nil {
+			return nil, err
+		}
+
+		for i := len(txs) - 1; i >= 0; i-- {
+			entry := historyEntry(address, txs[i])
+			entry.Balance = uint64(balance)
+			balance -= entry.Delta
+			if filter != nil && filter.From != 0 && entry.Timestamp < filter.From {
+				return reverseEntries(entries), nil
+			}
+			if filter == nil || filter.match(entry) {
+				entries = append(entries, entry)
+			}
+		}
+	}
+	return reverseEntries(entries), nil
+}
+
+func reverseEntries(entries []*HistoryEntry) []*HistoryEntry {
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries
+}
@@ -0,0 +1,37 @@
+package client
+
+import (
+	"github.com/skip2/go-qrcode"
+	"strings"
+)
+
+// Medium error correction keeps codes of full payment requests small
+// enough to be scanned from a phone screen
+const qrRecoveryLevel = qrcode.Medium
+
+// QRCodePNG renders content as a size x size pixels PNG
+func QRCodePNG(content string, size int) ([]byte, error) {
+	return qrcode.Encode(content, qrRecoveryLevel, size)
+}
+
+// QRCodeASCII renders content with two characters per module, for display
+// in a terminal
+func QRCodeASCII(content string) (string, error) {
+	code, err := qrcode.New(content, qrRecoveryLevel)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	for _, row := range code.Bitmap() {
+		for _, dark := range row {
+			if dark {
+				sb.WriteString("██")
+			} else {
+				sb.WriteString("  ")
+			}
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,107 @@
+package client
+
+import (
+	"errors"
+	"github.com/cyyber/go-qrl/misc"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Payment requests are encoded as
+//
+//	qrl:<Q address>?amount=<Quanta>&message=<text>&expiry=<unix time>
+//
+// every parameter being optional. Amounts are decimal Quanta with up to 9
+// decimals, the smallest unit being the Shor. Unknown parameters are
+// ignored, unless prefixed with req- in which case the request must be
+// rejected.
+const URIScheme = "qrl"
+
+var (
+	ErrInvalidURI       = errors.New("invalid qrl URI")
+	ErrInvalidURIAmount = errors.New("invalid amount in qrl URI")
+	ErrUnsupportedURI   = errors.New("qrl URI has a required parameter which isn't supported")
+)
+
+// PaymentRequest asks for a payment to Qaddress. Amount is in Shor, Amount,
+// Message and Expiry are optional.
+type PaymentRequest struct {
+	Qaddress string
+	Amount   uint64
+	Message  string
+	// Unix time after which the request must not be paid
+	Expiry uint64
+}
+
+func (r *PaymentRequest) URI() string {
+	query := url.Values{}
+	if r.Amount > 0 {
+		query.Set("amount", misc.FormatQuanta(r.Amount))
+	}
+	if r.Message != "" {
+		query.Set("message", r.Message)
+	}
+	if r.Expiry > 0 {
+		query.Set("expiry", strconv.FormatUint(r.Expiry, 10))
+	}
+
+	uri := URIScheme + ":" + r.Qaddress
+	if len(query) > 0 {
+		uri += "?" + query.Encode()
+	}
+	return uri
+}
+
+func (r *PaymentRequest) IsExpired(now time.Time) bool {
+	return r.Expiry != 0 && uint64(now.Unix()) > r.Expiry
+}
+
+// ParseURI decodes and validates a payment request
+func ParseURI(uri string) (*PaymentRequest, error) {
+	if !strings.HasPrefix(strings.ToLower(uri), URIScheme+":") {
+		return nil, ErrInvalidURI
+	}
+	rest := uri[len(URIScheme)+1:]
+	// Tolerate the qrl://Q... form some apps produce
+	rest = strings.TrimPrefix(rest, "//")
+
+	qaddress, rawQuery := rest, ""
+	if i := strings.IndexByte(rest, '?'); i >= 0 {
+		qaddress, rawQuery = rest[:i], rest[i+1:]
+	}
+	if _, err := misc.ParseQaddress(qaddress); err != nil {
+		return nil, err
+	}
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return nil, ErrInvalidURI
+	}
+
+	r := &PaymentRequest{Qaddress: qaddress}
+	for key, values := range query {
+		if len(values) != 1 {
+			return nil, ErrInvalidURI
+		}
+		value := values[0]
+		switch key {
+		case "amount":
+			if r.Amount, err = misc.ParseQuanta(value); err != nil {
+				return nil, ErrInvalidURIAmount
+			}
+		case "message":
+			r.Message = value
+		case "expiry":
+			if r.Expiry, err = strconv.ParseUint(value, 10, 64); err != nil {
+				return nil, ErrInvalidURI
+			}
+		default:
+			if strings.HasPrefix(key, "req-") {
+				return nil, ErrUnsupportedURI
+			}
+		}
+	}
+	return r, nil
+}
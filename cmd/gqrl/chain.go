@@ -0,0 +1,179 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/cyyber/go-qrl/core"
+	"github.com/cyyber/go-qrl/misc"
+	"github.com/cyyber/go-qrl/node"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+const progressInterval = 1000
+
+// openNode applies the configuration file and opens the local node
+func openNode() (*node.Node, error) {
+	if err := config.LoadFile(); err != nil {
+		return nil, err
+	}
+	return node.New(config)
+}
+
+// exportChain and importChain open the data directory directly, the node
+// must be stopped while they run
+func exportChain(args []string) error {
+	fs := flag.NewFlagSet("export-chain", flag.ExitOnError)
+	from := fs.Uint64("from", 1, "first block number")
+	to := fs.Uint64("to", 0, "last block number, the current height by default")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return errors.New("usage: gqrl export-chain [-from n] [-to n] <file>, - for stdout")
+	}
+
+	n, err := openNode()
+	if err != nil {
+		return err
+	}
+	defer n.Close()
+
+	if *to == 0 {
+		*to = n.Height()
+	}
+	if *to > n.Height() {
+		return fmt.Errorf("block #%d is beyond the height %d", *to, n.Height())
+	}
+
+	out := io.Writer(os.Stdout)
+	filename := fs.Arg(0)
+	if filename != "-" {
+		f, err := os.Create(filename + ".tmp")
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	archive, err := core.NewArchiveWriter(out, *from, *to)
+	if err != nil {
+		return err
+	}
+	for blockNumber := *from; blockNumber <= *to; blockNumber++ {
+		block, err := n.GetBlockByNumber(blockNumber)
+		if err != nil {
+			return fmt.Errorf("failed to read block #%d: %s", blockNumber, err)
+		}
+		if err := archive.WriteBlock(block); err != nil {
+			return err
+		}
+		if blockNumber%progressInterval == 0 {
+			fmt.Fprintf(os.Stderr, "Exported block #%d\n", blockNumber)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return err
+	}
+
+	if filename == "-" {
+		return nil
+	}
+	if err := out.(*os.File).Sync(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Exported blocks #%d to #%d\n", *from, *to)
+	return os.Rename(filename+".tmp", filename)
+}
+
+func importChain(args []string) error {
+	fs := flag.NewFlagSet("import-chain", flag.ExitOnError)
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return errors.New("usage: gqrl import-chain <file>, - for stdin")
+	}
+
+	in, err := openArchive(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	// The checksum is only known once the archive was read to its end, so
+	// the archive is verified in a first pass and nothing is applied from a
+	// truncated or corrupted file
+	summary, err := core.VerifyArchive(in, config)
+	if err != nil {
+		return err
+	}
+	if _, err := in.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	n, err := openNode()
+	if err != nil {
+		return err
+	}
+	defer n.Close()
+
+	if summary.From > n.Height()+1 {
+		return fmt.Errorf("archive starts at block #%d, the chain height is %d", summary.From, n.Height())
+	}
+	archive, err := core.NewArchiveReader(in, config)
+	if err != nil {
+		return err
+	}
+
+	var imported, skipped uint64
+	for {
+		block, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if _, err := n.GetBlock(block.HeaderHash()); err == nil {
+			skipped++
+			continue
+		}
+		if !n.AddBlock(block) {
+			return fmt.Errorf("block #%d %s rejected", block.BlockNumber(), misc.Bin2HStr(block.HeaderHash()))
+		}
+		imported++
+		if block.BlockNumber()%progressInterval == 0 {
+			fmt.Fprintf(os.Stderr, "Imported block #%d\n", block.BlockNumber())
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Imported %d blocks, %d already known, height %d\n", imported, skipped, n.Height())
+	return nil
+}
+
+// openArchive opens the archive file, stdin is spooled to a temporary file
+// as the archive has to be read twice
+func openArchive(filename string) (*os.File, error) {
+	if filename != "-" {
+		return os.Open(filename)
+	}
+
+	f, err := ioutil.TempFile("", "gqrl-import-")
+	if err != nil {
+		return nil, err
+	}
+	// The file is removed once closed
+	os.Remove(f.Name())
+	if _, err := io.Copy(f, os.Stdin); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
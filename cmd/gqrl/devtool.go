@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/cyyber/go-qrl/core"
+	"github.com/cyyber/go-qrl/simulator"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var devtoolCommands = []*command{
+	{"fork-sim", "check the tip picked among competing branches", forkSim},
+}
+
+func devtoolCommand(args []string) error {
+	if len(args) > 0 {
+		for _, c := range devtoolCommands {
+			if c.name == args[0] {
+				return c.run(args[1:])
+			}
+		}
+	}
+
+	fmt.Fprintln(os.Stderr, "usage: gqrl devtool <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	for _, c := range devtoolCommands {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", c.name, c.description)
+	}
+	return errors.New("unknown devtool command")
+}
+
+// branchFlags collects the -branch flags, given as
+// name:length[:interval[:invalid]]
+type branchFlags []*simulator.Branch
+
+func (b *branchFlags) String() string {
+	return fmt.Sprint(len(*b), " branches")
+}
+
+func (b *branchFlags) Set(value string) error {
+	fields := strings.Split(value, ":")
+	if len(fields) < 2 || len(fields) > 4 || fields[0] == "" {
+		return fmt.Errorf("invalid branch %s, expected name:length[:interval[:invalid]]", value)
+	}
+	branch := &simulator.Branch{Name: fields[0], BlockInterval: 60}
+	length, err := strconv.Atoi(fields[1])
+	if err != nil || length <= 0 {
+		return fmt.Errorf("invalid branch length %s", fields[1])
+	}
+	branch.Length = length
+	if len(fields) > 2 {
+		if branch.BlockInterval, err = strconv.ParseUint(fields[2], 10, 64); err != nil || branch.BlockInterval == 0 {
+			return fmt.Errorf("invalid block interval %s", fields[2])
+		}
+	}
+	if len(fields) > 3 {
+		if branch.InvalidAt, err = strconv.Atoi(fields[3]); err != nil || branch.InvalidAt < 0 || branch.InvalidAt > length {
+			return fmt.Errorf("invalid block position %s", fields[3])
+		}
+	}
+	*b = append(*b, branch)
+	return nil
+}
+
+// forkSim builds the branches on an in-memory regtest network and checks
+// the tip picked by a node receiving all of them, e.g.
+//
+//	gqrl devtool fork-sim -branch a:5 -branch b:6:60:3 -expect a
+func forkSim(args []string) error {
+	fs := flag.NewFlagSet("fork-sim", flag.ExitOnError)
+	var branches branchFlags
+	fs.Var(&branches, "branch", "branch as name:length[:interval seconds[:position of an invalid block]], repeatable")
+	common := fs.Int("common", 10, "blocks before the fork")
+	commonInterval := fs.Uint64("common-interval", 60, "seconds between the blocks before the fork")
+	expect := fs.String("expect", "", "branch expected to hold the tip, the common chain when empty")
+	adjustDifficulty := fs.Bool("adjust-difficulty", false, "let the difficulty follow the block intervals instead of the fixed regtest difficulty")
+	seed := fs.Int64("seed", 1, "seed of the virtual network")
+	fs.Parse(args)
+
+	if len(branches) == 0 {
+		return errors.New("at least one -branch is required")
+	}
+
+	nodeConfig := core.RegtestConfig()
+	nodeConfig.Dev.FixedDifficulty = !*adjustDifficulty
+	start := nodeConfig.Dev.Genesis.GenesisTimestamp + 1
+	result, err := simulator.RunForkScenario(&simulator.ForkScenario{
+		CommonLength:   *common,
+		CommonInterval: *commonInterval,
+		Branches:       branches,
+		ExpectedTip:    *expect,
+		MinerAddress:   core.DevAccountAddresses(nodeConfig)[0],
+	}, &simulator.Config{
+		Seed:       *seed,
+		StartTime:  uint64(start),
+		NodeConfig: nodeConfig,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, branch := range branches {
+		fmt.Printf("branch %s: tip %x\n", branch.Name, result.BranchTips[branch.Name])
+	}
+	fmt.Println(result)
+	if !result.OK {
+		return errors.New("the tip isn't on the expected branch")
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/cyyber/go-qrl/client"
+	"github.com/cyyber/go-qrl/misc"
+	"golang.org/x/net/context"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const dateFormat = "2006-01-02"
+
+var walletCommands = []*command{
+	{"history", "list the transactions of an address", walletHistory},
+	{"receive", "create a payment request, optionally as a QR code", walletReceive},
+	{"pay", "pay a qrl: payment request", walletPay},
+}
+
+func walletCommand(args []string) error {
+	if len(args) > 0 {
+		for _, c := range walletCommands {
+			if c.name == args[0] {
+				return c.run(args[1:])
+			}
+		}
+	}
+
+	fmt.Fprintln(os.Stderr, "usage: gqrl wallet <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	for _, c := range walletCommands {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", c.name, c.description)
+	}
+	return errors.New("unknown wallet command")
+}
+
+// parseDate returns the unix time of the start of date, or of the end of
+// the day when endOfDay is set
+func parseDate(date string, endOfDay bool) (uint64, error) {
+	if date == "" {
+		return 0, nil
+	}
+	t, err := time.Parse(dateFormat, date)
+	if err != nil {
+		return 0, err
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Second)
+	}
+	return uint64(t.Unix()), nil
+}
+
+// historyRecord is the exported form of a history entry, amounts being kept
+// in Shor so exports stay exact
+type historyRecord struct {
+	Date        string `json:"date"`
+	TxHash      string `json:"txhash"`
+	Type        string `json:"type"`
+	BlockNumber uint64 `json:"block_number"`
+	Direction   string `json:"direction"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Amount      int64  `json:"amount"`
+	Fee         uint64 `json:"fee"`
+	Balance     uint64 `json:"balance"`
+}
+
+// formatDelta renders a signed Shor amount as Quanta
+func formatDelta(delta int64) string {
+	if delta < 0 {
+		return "-" + misc.FormatQuantaFixed(uint64(-delta))
+	}
+	return "+" + misc.FormatQuantaFixed(uint64(delta))
+}
+
+func newHistoryRecord(entry *client.HistoryEntry) *historyRecord {
+	var to []string
+	for _, addrTo := range entry.AddrsTo {
+		to = append(to, misc.Qaddress(addrTo))
+	}
+	return &historyRecord{
+		Date:        time.Unix(int64(entry.Timestamp), 0).UTC().Format(time.RFC3339),
+		TxHash:      misc.Bin2HStr(entry.TxHash),
+		Type:        entry.Type,
+		BlockNumber: entry.BlockNumber,
+		Direction:   string(entry.Direction),
+		From:        misc.Qaddress(entry.AddrFrom),
+		To:          strings.Join(to, " "),
+		Amount:      entry.Delta,
+		Fee:         entry.Fee,
+		Balance:     entry.Balance,
+	}
+}
+
+func writeHistory(w io.Writer, format string, records []*historyRecord) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(records)
+	case "csv":
+		writer := csv.NewWriter(w)
+		writer.Write([]string{"date", "txhash", "type", "block_number", "direction", "from", "to", "amount", "fee", "balance"})
+		for _, r := range records {
+			writer.Write([]string{r.Date, r.TxHash, r.Type, strconv.FormatUint(r.BlockNumber, 10), r.Direction,
+				r.From, r.To, strconv.FormatInt(r.Amount, 10), strconv.FormatUint(r.Fee, 10), strconv.FormatUint(r.Balance, 10)})
+		}
+		writer.Flush()
+		return writer.Error()
+	case "table":
+		for _, r := range records {
+			fmt.Fprintf(w, "%s  %-8d %-4s %-14s %21s %20s  %s\n", r.Date, r.BlockNumber, r.Direction, r.Type,
+				formatDelta(r.Amount), misc.FormatQuantaFixed(r.Balance), r.TxHash)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+func walletHistory(args []string) error {
+	fs := flag.NewFlagSet("wallet history", flag.ExitOnError)
+	common := newCommonFlags(fs)
+	from := fs.String("from", "", "first day, as YYYY-MM-DD")
+	to := fs.String("to", "", "last day, as YYYY-MM-DD")
+	direction := fs.String("direction", "", "in, out or self, all when empty")
+	format := fs.String("format", "table", "table, csv or json")
+	output := fs.String("o", "", "write to this file instead of stdout")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return errors.New("usage: gqrl wallet history [flags] <address>")
+	}
+	address, err := misc.ParseQaddress(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	filter := &client.HistoryFilter{Direction: client.Direction(*direction)}
+	switch filter.Direction {
+	case "", client.DirectionIn, client.DirectionOut, client.DirectionSelf:
+	default:
+		return fmt.Errorf("unknown direction %q, expected in, out or self", *direction)
+	}
+	if filter.From, err = parseDate(*from, false); err != nil {
+		return err
+	}
+	if filter.To, err = parseDate(*to, true); err != nil {
+		return err
+	}
+
+	c, err := common.dial()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	entries, err := c.History(context.Background(), address, filter)
+	if err != nil {
+		return err
+	}
+	var records []*historyRecord
+	for _, entry := range entries {
+		records = append(records, newHistoryRecord(entry))
+	}
+
+	w := io.Writer(os.Stdout)
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return writeHistory(w, *format, records)
+}
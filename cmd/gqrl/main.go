@@ -0,0 +1,132 @@
+// gqrl is the command line client of the node. Wallet commands work on a
+// local wallet file, keys never leave the machine running gqrl.
+//
+//	gqrl send-many -from Q... -csv payouts.csv -fee 0.001
+//	gqrl wallet history -format csv -o history.csv Q...
+//	gqrl export-chain -from 1 -to 100000 chain.qrlchain
+//	gqrl devtool fork-sim -branch a:5 -branch b:6:60:3 -expect a
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/cyyber/go-qrl/client"
+	"github.com/cyyber/go-qrl/core"
+	"github.com/cyyber/go-qrl/misc"
+	"github.com/cyyber/go-qrl/wallet"
+	"golang.org/x/crypto/ssh/terminal"
+	"net"
+	"os"
+	"path"
+	"strconv"
+	"time"
+)
+
+type command struct {
+	name        string
+	description string
+	run         func(args []string) error
+}
+
+var commands = []*command{
+	{"send-many", "pay the address,amount lines of a CSV file", sendMany},
+	{"wallet", "wallet commands, see gqrl wallet", walletCommand},
+	{"export-chain", "write blocks of the local chain to an archive", exportChain},
+	{"import-chain", "validate and add the blocks of an archive", importChain},
+	{"devtool", "developer tools, see gqrl devtool", devtoolCommand},
+}
+
+var config = core.GetConfig()
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: gqrl <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-12s %s\n", c.name, c.description)
+	}
+}
+
+// commonFlags registers the flags shared by the commands talking to a node
+// through a local wallet
+type commonFlags struct {
+	walletFile *string
+	node       *string
+}
+
+func newCommonFlags(fs *flag.FlagSet) *commonFlags {
+	publicAPI := config.User.API.PublicAPI
+	return &commonFlags{
+		walletFile: fs.String("wallet", path.Join(config.User.QrlDir, config.User.Wallet.WalletFilename), "wallet file"),
+		node:       fs.String("node", net.JoinHostPort(publicAPI.Host, strconv.Itoa(int(publicAPI.Port))), "public API of the node, as host:port"),
+	}
+}
+
+// quantaFlag is an amount given in decimal Quanta on the command line and
+// held in Shor
+type quantaFlag uint64
+
+func (q *quantaFlag) String() string {
+	return misc.FormatQuanta(uint64(*q))
+}
+
+func (q *quantaFlag) Set(value string) error {
+	shor, err := misc.ParseQuanta(value)
+	if err != nil {
+		return err
+	}
+	*q = quantaFlag(shor)
+	return nil
+}
+
+func quantaVar(fs *flag.FlagSet, name string, usage string) *quantaFlag {
+	q := new(quantaFlag)
+	fs.Var(q, name, usage)
+	return q
+}
+
+func (f *commonFlags) dial() (*client.Client, error) {
+	return client.Dial(*f.node, config)
+}
+
+// unlockWallet opens the wallet and unlocks it with a passphrase read from
+// the terminal
+func (f *commonFlags) unlockWallet() (*wallet.Wallet, error) {
+	w, err := wallet.OpenWallet(*f.walletFile, time.Duration(config.User.Wallet.AutoLockTimeout)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Fprint(os.Stderr, "Wallet passphrase: ")
+	passphrase, err := terminal.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, err
+	}
+	defer wallet.Wipe(passphrase)
+
+	if err := w.Unlock(passphrase); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	for _, c := range commands {
+		if c.name != os.Args[1] {
+			continue
+		}
+		if err := c.run(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	usage()
+	os.Exit(2)
+}
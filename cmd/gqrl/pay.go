@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/cyyber/go-qrl/client"
+	"github.com/cyyber/go-qrl/misc"
+	"github.com/cyyber/go-qrl/wallet"
+	"golang.org/x/net/context"
+	"os"
+	"strings"
+	"time"
+)
+
+func walletPay(args []string) error {
+	fs := flag.NewFlagSet("wallet pay", flag.ExitOnError)
+	common := newCommonFlags(fs)
+	from := fs.String("from", "", "Q address paying the request")
+	fee := quantaVar(fs, "fee", "fee in Quanta")
+	yes := fs.Bool("yes", false, "don't ask for confirmation")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 || *from == "" {
+		return errors.New("usage: gqrl wallet pay -from <address> [flags] <qrl: URI>")
+	}
+	request, err := client.ParseURI(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if request.IsExpired(time.Now()) {
+		return errors.New("payment request expired")
+	}
+	if request.Amount == 0 {
+		return errors.New("payment request has no amount")
+	}
+
+	fmt.Printf("Pay %s Quanta to %s\n", misc.FormatQuanta(request.Amount), request.Qaddress)
+	if request.Message != "" {
+		fmt.Printf("Message: %s\n", request.Message)
+	}
+	if !*yes {
+		fmt.Print("Send? [y/N] ")
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if strings.ToLower(strings.TrimSpace(answer)) != "y" {
+			return errors.New("aborted")
+		}
+	}
+
+	w, err := common.unlockWallet()
+	if err != nil {
+		return err
+	}
+	defer w.Lock()
+
+	c, err := common.dial()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	payouts := []*wallet.Payout{{Qaddress: request.Qaddress, Amount: request.Amount}}
+	relayed, err := c.SendMany(context.Background(), w, *from, payouts, uint64(*fee))
+	for _, tx := range relayed {
+		fmt.Println("Relayed", misc.Bin2HStr(tx.TransactionHash))
+	}
+	return err
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/cyyber/go-qrl/client"
+	"github.com/cyyber/go-qrl/misc"
+	"io/ioutil"
+	"time"
+)
+
+func walletReceive(args []string) error {
+	fs := flag.NewFlagSet("wallet receive", flag.ExitOnError)
+	amount := quantaVar(fs, "amount", "requested amount in Quanta")
+	message := fs.String("message", "", "message shown to the payer")
+	expiry := fs.Duration("expiry", 0, "time after which the request expires, e.g. 30m")
+	qr := fs.String("qr", "", "render the request as a QR code: ascii or png")
+	size := fs.Int("size", 256, "size in pixels of png QR codes")
+	output := fs.String("o", "qrcode.png", "file the png QR code is written to")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return errors.New("usage: gqrl wallet receive [flags] <address>")
+	}
+	if _, err := misc.ParseQaddress(fs.Arg(0)); err != nil {
+		return err
+	}
+
+	request := &client.PaymentRequest{
+		Qaddress: fs.Arg(0),
+		Amount:   uint64(*amount),
+		Message:  *message,
+	}
+	if *expiry > 0 {
+		request.Expiry = uint64(time.Now().Add(*expiry).Unix())
+	}
+	uri := request.URI()
+	fmt.Println(uri)
+
+	switch *qr {
+	case "":
+	case "ascii":
+		code, err := client.QRCodeASCII(uri)
+		if err != nil {
+			return err
+		}
+		fmt.Print(code)
+	case "png":
+		png, err := client.QRCodePNG(uri, *size)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(*output, png, 0644)
+	default:
+		return fmt.Errorf("unknown QR code format %q", *qr)
+	}
+	return nil
+}
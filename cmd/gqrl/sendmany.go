@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/cyyber/go-qrl/misc"
+	"github.com/cyyber/go-qrl/wallet"
+	"golang.org/x/net/context"
+	"os"
+	"strings"
+)
+
+func sendMany(args []string) error {
+	fs := flag.NewFlagSet("send-many", flag.ExitOnError)
+	common := newCommonFlags(fs)
+	from := fs.String("from", "", "Q address paying the outputs")
+	csvFile := fs.String("csv", "", "CSV file of address,amount lines, amounts in Quanta")
+	feeFlag := quantaVar(fs, "fee", "fee in Quanta, paid by every transaction")
+	yes := fs.Bool("yes", false, "don't ask for confirmation")
+	fs.Parse(args)
+
+	if *from == "" || *csvFile == "" {
+		fs.Usage()
+		return errors.New("-from and -csv are required")
+	}
+	fee := uint64(*feeFlag)
+
+	f, err := os.Open(*csvFile)
+	if err != nil {
+		return err
+	}
+	payouts, err := wallet.ParsePayoutsCSV(f)
+	f.Close()
+	if err != nil {
+		return err
+	}
+
+	limit := int(config.Dev.Transaction.MultiOutputLimit)
+	totalAmount, totalCost, err := wallet.ValidatePayouts(payouts, fee, limit)
+	if err != nil {
+		return err
+	}
+	batches := len(wallet.SplitPayouts(payouts, limit))
+
+	fmt.Printf("%d outputs in %d transactions\n", len(payouts), batches)
+	fmt.Printf("Total amount: %s Quanta\n", misc.FormatQuanta(totalAmount))
+	fmt.Printf("Total fees:   %s Quanta\n", misc.FormatQuanta(totalCost-totalAmount))
+	if !*yes {
+		fmt.Print("Send? [y/N] ")
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if strings.ToLower(strings.TrimSpace(answer)) != "y" {
+			return errors.New("aborted")
+		}
+	}
+
+	w, err := common.unlockWallet()
+	if err != nil {
+		return err
+	}
+	defer w.Lock()
+
+	c, err := common.dial()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	relayed, err := c.SendMany(context.Background(), w, *from, payouts, fee)
+	for _, tx := range relayed {
+		fmt.Println("Relayed", misc.Bin2HStr(tx.TransactionHash))
+	}
+	return err
+}
@@ -0,0 +1,115 @@
+// qrlbench reads the output of the consensus hot path benchmarks and
+// compares it with a baseline recorded by a previous run.
+//
+//	go test -run ^$ -bench . -benchmem ./crypto ./db ./misc | qrlbench -save baseline.json
+//	go test -run ^$ -bench . -benchmem ./crypto ./db ./misc | qrlbench -baseline baseline.json
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strconv"
+)
+
+// benchmarkLine matches a result line of go test -bench -benchmem
+var benchmarkLine = regexp.MustCompile(`^Benchmark(\S+?)(?:-\d+)?\s+\d+\s+(\d+(?:\.\d+)?) ns/op(?:\s+(\d+) B/op\s+(\d+) allocs/op)?`)
+
+type Result struct {
+	Name        string
+	NsPerOp     int64
+	AllocsPerOp int64
+	BytesPerOp  int64
+}
+
+func loadBaseline(filename string) (map[string]*Result, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var results []*Result
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, err
+	}
+	baseline := make(map[string]*Result)
+	for _, r := range results {
+		baseline[r.Name] = r
+	}
+	return baseline, nil
+}
+
+func parseResult(line string) *Result {
+	match := benchmarkLine.FindStringSubmatch(line)
+	if match == nil {
+		return nil
+	}
+	nsPerOp, _ := strconv.ParseFloat(match[2], 64)
+	bytesPerOp, _ := strconv.ParseInt(match[3], 10, 64)
+	allocsPerOp, _ := strconv.ParseInt(match[4], 10, 64)
+	return &Result{
+		Name:        match[1],
+		NsPerOp:     int64(nsPerOp),
+		AllocsPerOp: allocsPerOp,
+		BytesPerOp:  bytesPerOp,
+	}
+}
+
+func main() {
+	filter := flag.String("run", ".", "regexp selecting the benchmarks to record")
+	baselineFile := flag.String("baseline", "", "compare with the results saved in this file")
+	saveFile := flag.String("save", "", "save the results to this file")
+	flag.Parse()
+
+	re, err := regexp.Compile(*filter)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	var baseline map[string]*Result
+	if *baselineFile != "" {
+		baseline, err = loadBaseline(*baselineFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "failed to load baseline:", err)
+			os.Exit(2)
+		}
+	}
+
+	var results []*Result
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		result := parseResult(scanner.Text())
+		if result == nil || !re.MatchString(result.Name) {
+			continue
+		}
+		results = append(results, result)
+
+		line := fmt.Sprintf("%-16s %12d ns/op %10d B/op %8d allocs/op",
+			result.Name, result.NsPerOp, result.BytesPerOp, result.AllocsPerOp)
+		if base, ok := baseline[result.Name]; ok && base.NsPerOp > 0 {
+			delta := float64(result.NsPerOp-base.NsPerOp) / float64(base.NsPerOp) * 100
+			line += fmt.Sprintf(" %+7.1f%%", delta)
+		}
+		fmt.Println(line)
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if *saveFile != "" {
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if err := ioutil.WriteFile(*saveFile, data, 0644); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+}
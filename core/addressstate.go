@@ -1,13 +1,12 @@
 package core
 
 import (
-	"github.com/cyyber/go-qrl/generated"
+	"bytes"
+	"errors"
 	"github.com/cyyber/go-qrl/core/transactions"
-	"github.com/theQRL/qrllib/goqrllib"
+	"github.com/cyyber/go-qrl/generated"
 	"github.com/cyyber/go-qrl/misc"
 	"github.com/golang/protobuf/proto"
-	"reflect"
-	"errors"
 )
 
 type AddressStateInterface interface {
@@ -22,12 +21,30 @@ type AddressStateInterface interface {
 
 	SetBalance(balance uint64)
 
-	OTSBitfield() [][]byte
+	AddBalance(balance uint64)
+
+	LockedBalances() []*generated.LockedBalance
+
+	LockedBalance(blockNumber uint64) uint64
+
+	SpendableBalance(blockNumber uint64) uint64
 
-	OTSCounter() uint64
+	AddLockedBalance(txHash []byte, amount uint64, unlockHeight uint64)
+
+	RemoveLockedBalance(txHash []byte)
+
+	PruneLockedBalances(blockNumber uint64, reorgLimit uint64)
+
+	OtsBitfield() [][]byte
+
+	OtsCounter() uint64
 
 	TransactionHashes() [][]byte
 
+	AppendTransactionHash(hash []byte)
+
+	RemoveTransactionHash(hash []byte)
+
 	LatticePKList() []*generated.LatticePK
 
 	SlavePKSAccessType() map[string]uint32
@@ -50,24 +67,24 @@ type AddressStateInterface interface {
 
 	DecreaseNonce()
 
-	GetSlavePermission(slavePK []byte) (uint16, bool)
+	GetSlavePermission(slavePK []byte) (uint32, bool)
 
 	GetDefault(address []byte) *AddressState
 
 	OTSKeyReuse(otsKeyIndex uint16) bool
 
-	SetOTSKey(otsKeyIndex uint16)
-
-	UnsetOTSKey(otsKeyIndex uint16, state *State)
+	SetOTSKey(otsKeyIndex uint64)
 
-	IsValidAddress(address []byte) bool
+	UnsetOTSKey(otsKeyIndex uint64, state *State) error
 
-	Serialize() string
+	Serialize() ([]byte, error)
 }
 
 type AddressState struct {
 	data *generated.AddressState
 	config *Config
+	// Pages of the OTS bitfield, nil when the bitfield is inline in data
+	ots *otsPages
 }
 
 func (a *AddressState) PBData() *generated.AddressState {
@@ -94,8 +111,67 @@ func (a *AddressState) AddBalance(balance uint64) {
 	a.data.Balance += balance
 }
 
+// LockedBalances returns the amounts of the balance which can't be spent
+// before their unlock height. Entries are kept ReorgLimit blocks past their
+// unlock height, so a rollback below it locks the amount again, and pruned
+// after.
+func (a *AddressState) LockedBalances() []*generated.LockedBalance {
+	return a.data.LockedBalances
+}
+
+// LockedBalance returns the part of the balance which can't be spent by a
+// transaction included at blockNumber
+func (a *AddressState) LockedBalance(blockNumber uint64) uint64 {
+	var locked uint64
+	for _, lockedBalance := range a.data.LockedBalances {
+		if blockNumber < lockedBalance.UnlockHeight {
+			locked += lockedBalance.Amount
+		}
+	}
+	return locked
+}
+
+func (a *AddressState) SpendableBalance(blockNumber uint64) uint64 {
+	locked := a.LockedBalance(blockNumber)
+	if locked > a.data.Balance {
+		return 0
+	}
+	return a.data.Balance - locked
+}
+
+func (a *AddressState) AddLockedBalance(txHash []byte, amount uint64, unlockHeight uint64) {
+	a.data.LockedBalances = append(a.data.LockedBalances, &generated.LockedBalance{
+		Txhash:       txHash,
+		Amount:       amount,
+		UnlockHeight: unlockHeight,
+	})
+}
+
+// PruneLockedBalances drops the entries unlocked for more than reorgLimit
+// blocks at blockNumber, no rollback can lock them again
+func (a *AddressState) PruneLockedBalances(blockNumber uint64, reorgLimit uint64) {
+	var kept []*generated.LockedBalance
+	for _, lockedBalance := range a.data.LockedBalances {
+		if lockedBalance.UnlockHeight+reorgLimit > blockNumber {
+			kept = append(kept, lockedBalance)
+		}
+	}
+	a.data.LockedBalances = kept
+}
+
+// RemoveLockedBalance removes the amounts locked by txHash
+func (a *AddressState) RemoveLockedBalance(txHash []byte) {
+	var kept []*generated.LockedBalance
+	for _, lockedBalance := range a.data.LockedBalances {
+		if !bytes.Equal(lockedBalance.Txhash, txHash) {
+			kept = append(kept, lockedBalance)
+		}
+	}
+	a.data.LockedBalances = kept
+}
+
 func (a *AddressState) OtsBitfield() [][]byte {
-	return a.data.OtsBitfield
+	return a.expandOTSBitfield()
 }
 
 func (a *AddressState) OtsCounter() uint64 {
@@ -112,9 +188,8 @@ func (a *AddressState) AppendTransactionHash(hash []byte) {
 
 func (a *AddressState) RemoveTransactionHash(hash []byte) {
 	for index, hash1 := range a.data.TransactionHashes {
-		if reflect.DeepEqual(index, hash1) {
+		if bytes.Equal(hash1, hash) {
 			a.data.TransactionHashes = append(a.data.TransactionHashes[:index], a.data.TransactionHashes[index+1:]...)
-			//TODO: Fix remove code
 			return
 		}
 	}
@@ -129,7 +204,7 @@ func (a *AddressState) SlavePKSAccessType() map[string]uint32 {
 }
 
 func (a *AddressState) UpdateTokenBalance(tokenTxHash []byte, balance uint64) {
-	strTokenTxHash := goqrllib.Bin2hstr(tokenTxHash)
+	strTokenTxHash := misc.Bin2HStr(tokenTxHash)
 	a.data.Tokens[strTokenTxHash] += balance
 	if a.data.Tokens[strTokenTxHash] == 0 {
 		delete(a.data.Tokens, strTokenTxHash)
@@ -137,7 +212,7 @@ func (a *AddressState) UpdateTokenBalance(tokenTxHash []byte, balance uint64) {
 }
 
 func (a *AddressState) GetTokenBalance(tokenTxHash []byte) uint64 {
-	strTokenTxHash := goqrllib.Bin2hstr(tokenTxHash)
+	strTokenTxHash := misc.Bin2HStr(tokenTxHash)
 	if balance, ok := a.data.Tokens[strTokenTxHash]; ok {
 		return balance
 	}
@@ -145,7 +220,7 @@ func (a *AddressState) GetTokenBalance(tokenTxHash []byte) uint64 {
 }
 
 func (a *AddressState) IsTokenExists(tokenTxHash []byte) bool {
-	strTokenTxHash := goqrllib.Bin2hstr(tokenTxHash)
+	strTokenTxHash := misc.Bin2HStr(tokenTxHash)
 	_, ok := a.data.Tokens[strTokenTxHash]
 	return ok
 }
@@ -170,7 +245,7 @@ func (a *AddressState) AddLatticePK(latticeTx *transactions.LatticePublicKey) {
 
 func (a *AddressState) RemoveLatticePK(latticeTx *transactions.LatticePublicKey) {
 	for i, latticePK := range a.data.LatticePKList {
-		if reflect.DeepEqual(latticePK.Txhash, latticeTx.Txhash()) {
+		if bytes.Equal(latticePK.Txhash, latticeTx.Txhash()) {
 			a.data.LatticePKList = append(a.data.LatticePKList[0:i], a.data.LatticePKList[i+1:]...)
 		}
 	}
@@ -195,9 +270,7 @@ func (a *AddressState) GetDefault(address []byte) *AddressState {
 
 func (a *AddressState) OTSKeyReuse(otsKeyIndex uint16) bool {
 	if otsKeyIndex < a.config.Dev.MaxOTSTracking {
-		offset := otsKeyIndex >> 3
-		relative := otsKeyIndex % 8
-		if (a.data.OtsBitfield[offset][0] >> relative) & 1 == 1 {
+		if a.otsBit(uint64(otsKeyIndex)) {
 			return true
 		}
 	} else {
@@ -209,12 +282,23 @@ func (a *AddressState) OTSKeyReuse(otsKeyIndex uint16) bool {
 	return false
 }
 
+// NextUnusedOTSIndex returns the lowest OTS index, at or above from, which
+// hasn't been used on chain
+func (a *AddressState) NextUnusedOTSIndex(from uint64) uint64 {
+	for i := from; i < uint64(a.config.Dev.MaxOTSTracking); i++ {
+		if !a.OTSKeyReuse(uint16(i)) {
+			return i
+		}
+	}
+	if from > a.data.OtsCounter {
+		return from
+	}
+	return a.data.OtsCounter + 1
+}
+
 func (a *AddressState) SetOTSKey(otsKeyIndex uint64) {
 	if otsKeyIndex < uint64(a.config.Dev.MaxOTSTracking) {
-		offset := otsKeyIndex >> 3
-		relative := otsKeyIndex % 8
-		bitfield := a.data.OtsBitfield[offset]
-		a.data.OtsBitfield[offset][0] = bitfield[0] | (1 << relative)
+		a.setOTSBit(otsKeyIndex, true)
 	} else {
 		a.data.OtsCounter = otsKeyIndex
 	}
@@ -222,10 +306,7 @@ func (a *AddressState) SetOTSKey(otsKeyIndex uint64) {
 
 func (a *AddressState) UnsetOTSKey(otsKeyIndex uint64, state *State) error {
 	if otsKeyIndex < uint64(a.config.Dev.MaxOTSTracking) {
-		offset := otsKeyIndex >> 3
-		relative := otsKeyIndex % 8
-		bitfield := a.data.OtsBitfield[offset]
-		a.data.OtsBitfield[offset][0] = bitfield[0] & ^(1 << relative)
+		a.setOTSBit(otsKeyIndex, false)
 		return nil
 	} else {
 		a.data.OtsCounter = 0
@@ -247,10 +328,7 @@ func (a *AddressState) UnsetOTSKey(otsKeyIndex uint64, state *State) error {
 
 func IsValidAddress(address []byte) bool {
 	// Warning: Never pass this validation True for Coinbase Address
-	if !goqrllib.QRLHelperAddressIsValid(misc.BytesToUCharVector(address)) {
-		return true
-	}
-	return false
+	return misc.ValidateAddress(address) == nil
 }
 
 func CreateAddressState(address []byte, nonce uint64, balance uint64, otsBitfield [Config{}.Dev.OtsBitFieldSize][8]byte, tokens map[string]uint64, slavePksAccessType map[string]uint32, otsCounter uint64) *AddressState {
@@ -286,16 +364,49 @@ func GetDefaultAddressState(address []byte) *AddressState {
 	return CreateAddressState(address, uint64(c.Dev.DefaultNonce), c.Dev.DefaultAccountBalance, otsBitfield, tokens, slavePksAccessType, 0)
 }
 
+// Serialize encodes the address state in the current serialization version
 func (a *AddressState) Serialize() ([]byte, error) {
-	return proto.Marshal(a.data)
+	return EncodeAddressState(a.recordData()), nil
 }
 
-func DeSerializeAddressState(data []byte) (*AddressState, error) {
-	a := &AddressState{}
+// Clone returns a deep copy of the address state
+func (a *AddressState) Clone() *AddressState {
+	c := &AddressState{
+		data:   proto.Clone(a.data).(*generated.AddressState),
+		config: a.config,
+	}
+	if a.ots != nil {
+		c.ots = a.ots.clone()
+	}
+	return c
+}
 
-	if err := proto.Unmarshal(data, a.data); err != nil {
-		return a, err
+func (a *AddressState) Equals(other *AddressState) bool {
+	if other == nil || !proto.Equal(a.recordData(), other.recordData()) {
+		return false
+	}
+	bitfield, otherBitfield := a.expandOTSBitfield(), other.expandOTSBitfield()
+	if len(bitfield) != len(otherBitfield) {
+		return false
+	}
+	for i := range bitfield {
+		if !bytes.Equal(bitfield[i], otherBitfield[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// NewAddressState wraps an address state received from a node
+func NewAddressState(data *generated.AddressState, config *Config) *AddressState {
+	return &AddressState{data: data, config: config}
+}
+
+func DeSerializeAddressState(data []byte) (*AddressState, error) {
+	pbData, err := DecodeAddressState(data)
+	if err != nil {
+		return nil, err
 	}
 
-	return a, nil
+	return &AddressState{data: pbData}, nil
 }
@@ -0,0 +1,251 @@
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"github.com/cyyber/go-qrl/generated"
+	"github.com/golang/protobuf/proto"
+	"sort"
+)
+
+// Address states are persisted with a leading serialization version byte.
+// States written before versioning are plain protobuf, which can never
+// start with a byte below 0x08 as that would be field number 0, so any
+// value whose first byte is a known version is unambiguous.
+// Legacy values are decoded as protobuf and rewritten in the current
+// version the next time the address state is stored.
+// Version 2 appends the balances locked by time-locked transfers.
+const (
+	AddressStateVersion1 byte = 1
+	AddressStateVersion2 byte = 2
+
+	CurrentAddressStateVersion = AddressStateVersion2
+)
+
+// Upper bounds applied while decoding, so a corrupted value cannot make the
+// decoder allocate unbounded memory
+const (
+	maxEncodedFieldLength = 1 << 20
+	maxEncodedListLength  = 1 << 24
+)
+
+var (
+	errAddressStateTruncated = errors.New("address state truncated")
+	errAddressStateTooLarge  = errors.New("address state field too large")
+	errAddressStateTrailing  = errors.New("trailing bytes after address state")
+)
+
+type addressStateEncoder struct {
+	buf bytes.Buffer
+	tmp [binary.MaxVarintLen64]byte
+}
+
+func (e *addressStateEncoder) uvarint(value uint64) {
+	n := binary.PutUvarint(e.tmp[:], value)
+	e.buf.Write(e.tmp[:n])
+}
+
+func (e *addressStateEncoder) bytes(value []byte) {
+	e.uvarint(uint64(len(value)))
+	e.buf.Write(value)
+}
+
+type addressStateDecoder struct {
+	data []byte
+	err  error
+}
+
+func (d *addressStateDecoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	value, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		d.err = errAddressStateTruncated
+		return 0
+	}
+	d.data = d.data[n:]
+	return value
+}
+
+func (d *addressStateDecoder) length(max uint64) int {
+	n := d.uvarint()
+	if d.err == nil && n > max {
+		d.err = errAddressStateTooLarge
+		return 0
+	}
+	return int(n)
+}
+
+func (d *addressStateDecoder) bytes() []byte {
+	n := d.length(maxEncodedFieldLength)
+	if d.err != nil {
+		return nil
+	}
+	if n > len(d.data) {
+		d.err = errAddressStateTruncated
+		return nil
+	}
+	value := make([]byte, n)
+	copy(value, d.data[:n])
+	d.data = d.data[n:]
+	return value
+}
+
+func sortedKeys(keys []string) []string {
+	sort.Strings(keys)
+	return keys
+}
+
+// encodeAddressStateV2 serializes the fields in a fixed order. Maps are
+// written sorted by key so the encoding is deterministic.
+func encodeAddressStateV2(data *generated.AddressState) []byte {
+	e := &addressStateEncoder{}
+	e.buf.WriteByte(AddressStateVersion2)
+
+	e.bytes(data.Address)
+	e.uvarint(data.Balance)
+	e.uvarint(data.Nonce)
+	e.uvarint(data.OtsCounter)
+
+	e.uvarint(uint64(len(data.OtsBitfield)))
+	for _, bitfield := range data.OtsBitfield {
+		e.bytes(bitfield)
+	}
+
+	e.uvarint(uint64(len(data.TransactionHashes)))
+	for _, txHash := range data.TransactionHashes {
+		e.bytes(txHash)
+	}
+
+	var tokenKeys []string
+	for key := range data.Tokens {
+		tokenKeys = append(tokenKeys, key)
+	}
+	e.uvarint(uint64(len(tokenKeys)))
+	for _, key := range sortedKeys(tokenKeys) {
+		e.bytes([]byte(key))
+		e.uvarint(data.Tokens[key])
+	}
+
+	var slaveKeys []string
+	for key := range data.SlavePksAccessType {
+		slaveKeys = append(slaveKeys, key)
+	}
+	e.uvarint(uint64(len(slaveKeys)))
+	for _, key := range sortedKeys(slaveKeys) {
+		e.bytes([]byte(key))
+		e.uvarint(uint64(data.SlavePksAccessType[key]))
+	}
+
+	e.uvarint(uint64(len(data.LatticePKList)))
+	for _, latticePK := range data.LatticePKList {
+		e.bytes(latticePK.Txhash)
+		e.bytes(latticePK.DilithiumPk)
+		e.bytes(latticePK.KyberPk)
+	}
+
+	e.uvarint(uint64(len(data.LockedBalances)))
+	for _, locked := range data.LockedBalances {
+		e.bytes(locked.Txhash)
+		e.uvarint(locked.Amount)
+		e.uvarint(locked.UnlockHeight)
+	}
+
+	return e.buf.Bytes()
+}
+
+// decodeAddressStateVersion decodes the fields written by version, versions
+// only append fields
+func decodeAddressStateVersion(value []byte, version byte) (*generated.AddressState, error) {
+	d := &addressStateDecoder{data: value}
+	data := &generated.AddressState{
+		Tokens:             make(map[string]uint64),
+		SlavePksAccessType: make(map[string]uint32),
+	}
+
+	data.Address = d.bytes()
+	data.Balance = d.uvarint()
+	data.Nonce = d.uvarint()
+	data.OtsCounter = d.uvarint()
+
+	n := d.length(maxEncodedListLength)
+	for i := 0; i < n && d.err == nil; i++ {
+		data.OtsBitfield = append(data.OtsBitfield, d.bytes())
+	}
+
+	n = d.length(maxEncodedListLength)
+	for i := 0; i < n && d.err == nil; i++ {
+		data.TransactionHashes = append(data.TransactionHashes, d.bytes())
+	}
+
+	n = d.length(maxEncodedListLength)
+	for i := 0; i < n && d.err == nil; i++ {
+		key := string(d.bytes())
+		data.Tokens[key] = d.uvarint()
+	}
+
+	n = d.length(maxEncodedListLength)
+	for i := 0; i < n && d.err == nil; i++ {
+		key := string(d.bytes())
+		data.SlavePksAccessType[key] = uint32(d.uvarint())
+	}
+
+	n = d.length(maxEncodedListLength)
+	for i := 0; i < n && d.err == nil; i++ {
+		data.LatticePKList = append(data.LatticePKList, &generated.LatticePK{
+			Txhash:      d.bytes(),
+			DilithiumPk: d.bytes(),
+			KyberPk:     d.bytes(),
+		})
+	}
+
+	if version >= AddressStateVersion2 {
+		n = d.length(maxEncodedListLength)
+		for i := 0; i < n && d.err == nil; i++ {
+			data.LockedBalances = append(data.LockedBalances, &generated.LockedBalance{
+				Txhash:       d.bytes(),
+				Amount:       d.uvarint(),
+				UnlockHeight: d.uvarint(),
+			})
+		}
+	}
+
+	if d.err != nil {
+		return nil, d.err
+	}
+	if len(d.data) != 0 {
+		return nil, errAddressStateTrailing
+	}
+
+	return data, nil
+}
+
+// EncodeAddressState serializes data in the current serialization version
+func EncodeAddressState(data *generated.AddressState) []byte {
+	return encodeAddressStateV2(data)
+}
+
+// DecodeAddressState decodes any supported serialization version, including
+// legacy protobuf values written before versioning was introduced
+func DecodeAddressState(value []byte) (*generated.AddressState, error) {
+	if len(value) > 0 {
+		switch value[0] {
+		case AddressStateVersion1, AddressStateVersion2:
+			return decodeAddressStateVersion(value[1:], value[0])
+		}
+	}
+
+	data := &generated.AddressState{}
+	if err := proto.Unmarshal(value, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// IsLegacyAddressState returns true if value needs to be migrated to the
+// current serialization version
+func IsLegacyAddressState(value []byte) bool {
+	return len(value) == 0 || value[0] != CurrentAddressStateVersion
+}
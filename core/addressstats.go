@@ -0,0 +1,319 @@
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"github.com/cyyber/go-qrl/core/transactions"
+	"github.com/cyyber/go-qrl/generated"
+	"github.com/cyyber/go-qrl/misc"
+	"github.com/syndtr/goleveldb/leveldb"
+	"sort"
+)
+
+// The activity of every address is summed up as blocks are applied and
+// rolled back, so an address page doesn't need to load every transaction
+// of the address. The first and last activity come from the transaction
+// hashes of the address state.
+//
+// Key: addrstats_ | address
+// Layout: uint64 received | uint64 sent | uint64 fees | uint32 count |
+// (sized transaction type | uint64 transactions)*
+
+var addressStatsPrefix = []byte("addrstats_")
+
+var ErrInvalidAddressActivity = errors.New("invalid address activity record")
+
+// AddressActivity holds the totals of an address, amounts are in Shor and
+// only count Quanta, token amounts are indexed per token
+type AddressActivity struct {
+	Received uint64
+	Sent     uint64
+	Fees     uint64
+	TxCounts map[string]uint64
+}
+
+type AddressStats struct {
+	Address []byte
+
+	FirstSeenHeight       uint64
+	LastActivityHeight    uint64
+	LastActivityTimestamp uint64
+
+	TotalReceived uint64
+	TotalSent     uint64
+	TotalFees     uint64
+	TxCount       uint64
+	TxCountByType map[string]uint64
+
+	OTSKeysTotal     uint64
+	OTSKeysRemaining uint64
+}
+
+// TransactionTypeName returns the name of the transaction type set in pbdata
+func TransactionTypeName(pbdata *generated.Transaction) string {
+	switch pbdata.TransactionType.(type) {
+	case *generated.Transaction_Transfer_:
+		return "transfer"
+	case *generated.Transaction_Coinbase:
+		return "coinbase"
+	case *generated.Transaction_LatticePK:
+		return "latticePK"
+	case *generated.Transaction_Message_:
+		return "message"
+	case *generated.Transaction_Token_:
+		return "token"
+	case *generated.Transaction_TransferToken_:
+		return "transfer_token"
+	case *generated.Transaction_Slave_:
+		return "slave"
+	}
+	return "unknown"
+}
+
+func addressStatsKey(address []byte) []byte {
+	return append(append([]byte{}, addressStatsPrefix...), address...)
+}
+
+func (a *AddressActivity) txCount() uint64 {
+	var count uint64
+	for _, n := range a.TxCounts {
+		count += n
+	}
+	return count
+}
+
+func (a *AddressActivity) encode() []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, a.Received)
+	binary.Write(&buf, binary.BigEndian, a.Sent)
+	binary.Write(&buf, binary.BigEndian, a.Fees)
+
+	txTypes := make([]string, 0, len(a.TxCounts))
+	for txType, n := range a.TxCounts {
+		if n > 0 {
+			txTypes = append(txTypes, txType)
+		}
+	}
+	sort.Strings(txTypes)
+	binary.Write(&buf, binary.BigEndian, uint32(len(txTypes)))
+	for _, txType := range txTypes {
+		writeSized(&buf, []byte(txType))
+		binary.Write(&buf, binary.BigEndian, a.TxCounts[txType])
+	}
+	return buf.Bytes()
+}
+
+func decodeAddressActivity(data []byte) (*AddressActivity, error) {
+	r := bytes.NewReader(data)
+	a := &AddressActivity{TxCounts: make(map[string]uint64)}
+
+	var count uint32
+	for _, field := range []*uint64{&a.Received, &a.Sent, &a.Fees} {
+		if err := binary.Read(r, binary.BigEndian, field); err != nil {
+			return nil, ErrInvalidAddressActivity
+		}
+	}
+	if err := binary.Read(r, binary.BigEndian, &count); err != nil {
+		return nil, ErrInvalidAddressActivity
+	}
+	for i := uint32(0); i < count; i++ {
+		txType, err := readUndoField(r)
+		if err != nil {
+			return nil, ErrInvalidAddressActivity
+		}
+		var n uint64
+		if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+			return nil, ErrInvalidAddressActivity
+		}
+		a.TxCounts[string(txType)] = n
+	}
+	if r.Len() != 0 {
+		return nil, ErrInvalidAddressActivity
+	}
+	return a, nil
+}
+
+// addressStatsUpdate accumulates the activity changed by the transactions
+// of a block before it's written with the block batch
+type addressStatsUpdate struct {
+	s *State
+	// Activities start empty instead of the stored ones, for rebuilds
+	fresh bool
+
+	activities map[string]*AddressActivity
+	order      []string
+	// Transactions of the addresses before the update
+	initial map[string]uint64
+}
+
+func newAddressStatsUpdate(s *State, fresh bool) *addressStatsUpdate {
+	return &addressStatsUpdate{
+		s:          s,
+		fresh:      fresh,
+		activities: make(map[string]*AddressActivity),
+		initial:    make(map[string]uint64),
+	}
+}
+
+func (u *addressStatsUpdate) activity(address []byte) *AddressActivity {
+	if a, ok := u.activities[string(address)]; ok {
+		return a
+	}
+	u.order = append(u.order, string(address))
+
+	a := &AddressActivity{TxCounts: make(map[string]uint64)}
+	if !u.fresh {
+		if value, err := u.s.db.Get(addressStatsKey(address)); err == nil {
+			if stored, err := decodeAddressActivity(value); err == nil {
+				a = stored
+			}
+		}
+	}
+	u.activities[string(address)] = a
+	u.initial[string(address)] = a.txCount()
+	return a
+}
+
+// created returns the number of addresses which got their first
+// transaction, or lost their last one when reverting
+func (u *addressStatsUpdate) created() uint64 {
+	var n uint64
+	for _, address := range u.order {
+		if (u.initial[address] == 0) != (u.activities[address].txCount() == 0) {
+			n++
+		}
+	}
+	return n
+}
+
+// addTx adds the activity of tx, or removes it when revert is set
+func (u *addressStatsUpdate) addTx(tx transactions.TransactionInterface, revert bool) {
+	change := func(value *uint64, amount uint64) {
+		if !revert {
+			*value += amount
+		} else if amount > *value {
+			*value = 0
+		} else {
+			*value -= amount
+		}
+	}
+
+	txType := TransactionTypeName(tx.PBData())
+	seen := make(map[string]bool)
+	for _, address := range historyAddresses(tx) {
+		if seen[string(address)] {
+			continue
+		}
+		seen[string(address)] = true
+		a := u.activity(address)
+		n := a.TxCounts[txType]
+		change(&n, 1)
+		a.TxCounts[txType] = n
+	}
+
+	switch t := tx.(type) {
+	case *transactions.CoinBase:
+		change(&u.activity(t.AddrTo()).Received, t.Amount())
+		return
+	case *transactions.TransferTransaction:
+		change(&u.activity(t.AddrFrom()).Sent, t.TotalAmounts())
+		for i, addrTo := range t.AddrsTo() {
+			change(&u.activity(addrTo).Received, t.Amounts()[i])
+		}
+	}
+	change(&u.activity(tx.AddrFrom()).Fees, tx.Fee())
+}
+
+// write stores the changed activities, addresses left without transactions
+// are removed
+func (u *addressStatsUpdate) write(batch *leveldb.Batch) error {
+	for _, address := range u.order {
+		a := u.activities[address]
+		if a.txCount() == 0 {
+			u.s.deleteKey(addressStatsKey([]byte(address)), batch)
+			continue
+		}
+		if err := u.s.db.Put(addressStatsKey([]byte(address)), a.encode(), batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// clearAddressStats removes the address activities, before they are rebuilt
+func (s *State) clearAddressStats() error {
+	batch := s.GetBatch()
+	err := s.db.IteratePrefix(addressStatsPrefix, nil, func(key []byte, value []byte) bool {
+		batch.Delete(append([]byte{}, key...))
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	return s.WriteBatch(batch)
+}
+
+func (s *State) GetAddressActivity(address []byte) (*AddressActivity, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	value, err := s.db.Get(addressStatsKey(address))
+	if err != nil {
+		return &AddressActivity{TxCounts: make(map[string]uint64)}, nil
+	}
+	return decodeAddressActivity(value)
+}
+
+// otsKeysUsed counts the OTS keys used by the address, following
+// OTSKeyReuse: keys below maxOTSTracking are tracked in the bitfield, keys
+// up to the counter are considered used above it
+func (a *AddressState) otsKeysUsed(maxOTSTracking uint16) uint64 {
+	var used uint64
+	for i := uint64(0); i < uint64(maxOTSTracking); i++ {
+		if a.otsBit(i) {
+			used++
+		}
+	}
+	if a.data.OtsCounter >= uint64(maxOTSTracking) {
+		used += a.data.OtsCounter - uint64(maxOTSTracking) + 1
+	}
+	return used
+}
+
+// GetAddressStats returns the activity summary of address from the indexes
+func (c *Chain) GetAddressStats(address []byte) (*AddressStats, error) {
+	addrState, err := c.GetAddressState(address)
+	if err != nil {
+		return nil, err
+	}
+	activity, err := c.state.GetAddressActivity(address)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &AddressStats{
+		Address:       address,
+		TotalReceived: activity.Received,
+		TotalSent:     activity.Sent,
+		TotalFees:     activity.Fees,
+		TxCount:       activity.txCount(),
+		TxCountByType: activity.TxCounts,
+	}
+
+	if hashes := addrState.TransactionHashes(); len(hashes) > 0 {
+		if first, err := c.state.GetTxMetadata(hashes[0]); err == nil {
+			stats.FirstSeenHeight = first.BlockNumber
+		}
+		if last, err := c.state.GetTxMetadata(hashes[len(hashes)-1]); err == nil {
+			stats.LastActivityHeight = last.BlockNumber
+			stats.LastActivityTimestamp = last.Timestamp
+		}
+	}
+
+	stats.OTSKeysTotal = uint64(1) << misc.AddressTreeHeight(address)
+	if used := addrState.otsKeysUsed(c.config.Dev.MaxOTSTracking); used < stats.OTSKeysTotal {
+		stats.OTSKeysRemaining = stats.OTSKeysTotal - used
+	}
+	return stats, nil
+}
@@ -0,0 +1,216 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+)
+
+// A chain archive holds consecutive mainchain blocks, so a node can be
+// seeded from a local copy instead of syncing from peers. Archives are
+// written and read as a stream, neither side holds more than one block in
+// memory.
+//
+// Layout:
+//   magic | uint64 first block number | uint64 last block number |
+//   (uint32 size | Block)* | uint32 0 | uint64 block count | sha256
+// The checksum covers everything before it. Blocks are not trusted because
+// of it, importing runs them through the regular block validation; it only
+// tells a truncated or corrupted file apart from a complete one.
+
+var archiveMagic = []byte("QRLCHN01")
+
+var (
+	ErrInvalidArchive       = errors.New("invalid chain archive")
+	ErrArchiveChecksum      = errors.New("chain archive checksum mismatch")
+	ErrArchiveBlockSequence = errors.New("chain archive blocks are not consecutive")
+)
+
+type ArchiveWriter struct {
+	out      io.Writer
+	w        *bufio.Writer
+	checksum hash.Hash
+
+	from  uint64
+	to    uint64
+	count uint64
+}
+
+// NewArchiveWriter writes the archive header for blocks from to to
+// (inclusive) to w
+func NewArchiveWriter(w io.Writer, from uint64, to uint64) (*ArchiveWriter, error) {
+	if from > to {
+		return nil, fmt.Errorf("invalid block range %d-%d", from, to)
+	}
+	a := &ArchiveWriter{
+		out:      w,
+		checksum: sha256.New(),
+		from:     from,
+		to:       to,
+	}
+	a.w = bufio.NewWriter(io.MultiWriter(w, a.checksum))
+
+	header := make([]byte, 16)
+	binary.BigEndian.PutUint64(header, from)
+	binary.BigEndian.PutUint64(header[8:], to)
+	if _, err := a.w.Write(archiveMagic); err != nil {
+		return nil, err
+	}
+	if _, err := a.w.Write(header); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// WriteBlock appends block, which must be the next block of the range
+func (a *ArchiveWriter) WriteBlock(block *Block) error {
+	if block.BlockNumber() != a.from+a.count || block.BlockNumber() > a.to {
+		return ErrArchiveBlockSequence
+	}
+	data, err := block.Serialize()
+	if err != nil {
+		return err
+	}
+	if err := writeSized(a.w, data); err != nil {
+		return err
+	}
+	a.count++
+	return nil
+}
+
+// Close writes the trailer, it fails if blocks of the range are missing.
+// The underlying writer is left open.
+func (a *ArchiveWriter) Close() error {
+	if a.count != a.to-a.from+1 {
+		return fmt.Errorf("%s: %d blocks written, %d expected", ErrArchiveBlockSequence, a.count, a.to-a.from+1)
+	}
+
+	trailer := make([]byte, 12)
+	binary.BigEndian.PutUint64(trailer[4:], a.count)
+	if _, err := a.w.Write(trailer); err != nil {
+		return err
+	}
+	if err := a.w.Flush(); err != nil {
+		return err
+	}
+
+	// The checksum itself stays out of the checksum
+	_, err := a.out.Write(a.checksum.Sum(nil))
+	return err
+}
+
+type ArchiveReader struct {
+	r        io.Reader
+	source   io.Reader
+	checksum hash.Hash
+	config   *Config
+
+	From uint64
+	To   uint64
+
+	next uint64
+	done bool
+}
+
+func NewArchiveReader(r io.Reader, config *Config) (*ArchiveReader, error) {
+	a := &ArchiveReader{
+		source:   bufio.NewReader(r),
+		checksum: sha256.New(),
+		config:   config,
+	}
+	a.r = io.TeeReader(a.source, a.checksum)
+
+	header := make([]byte, len(archiveMagic)+16)
+	if _, err := io.ReadFull(a.r, header); err != nil || !bytes.Equal(header[:len(archiveMagic)], archiveMagic) {
+		return nil, ErrInvalidArchive
+	}
+	a.From = binary.BigEndian.Uint64(header[len(archiveMagic):])
+	a.To = binary.BigEndian.Uint64(header[len(archiveMagic)+8:])
+	if a.From > a.To {
+		return nil, ErrInvalidArchive
+	}
+	a.next = a.From
+	return a, nil
+}
+
+// Next returns the next block of the archive. io.EOF is returned once every
+// block was read and the checksum verified.
+func (a *ArchiveReader) Next() (*Block, error) {
+	if a.done {
+		return nil, io.EOF
+	}
+
+	sizeBytes := make([]byte, 4)
+	if _, err := io.ReadFull(a.r, sizeBytes); err != nil {
+		return nil, ErrInvalidArchive
+	}
+	size := binary.BigEndian.Uint32(sizeBytes)
+	if size == 0 {
+		return nil, a.verifyTrailer()
+	}
+	if uint64(size) > a.config.Dev.MaxReceivableBytes {
+		return nil, ErrInvalidArchive
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(a.r, data); err != nil {
+		return nil, ErrInvalidArchive
+	}
+	block, err := DeSerializeBlock(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", ErrInvalidArchive, err)
+	}
+	block.config = a.config
+	block.blockheader.config = a.config
+
+	if block.BlockNumber() != a.next || block.BlockNumber() > a.To {
+		return nil, ErrArchiveBlockSequence
+	}
+	a.next++
+	return block, nil
+}
+
+// VerifyArchive reads the whole archive from r and checks its block sequence
+// and checksum, without keeping the blocks
+func VerifyArchive(r io.Reader, config *Config) (*ArchiveReader, error) {
+	archive, err := NewArchiveReader(r, config)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		_, err := archive.Next()
+		if err == io.EOF {
+			return archive, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (a *ArchiveReader) verifyTrailer() error {
+	count := make([]byte, 8)
+	if _, err := io.ReadFull(a.r, count); err != nil {
+		return ErrInvalidArchive
+	}
+	expected := a.checksum.Sum(nil)
+
+	checksum := make([]byte, sha256.Size)
+	if _, err := io.ReadFull(a.source, checksum); err != nil {
+		return ErrInvalidArchive
+	}
+	if !bytes.Equal(checksum, expected) {
+		return ErrArchiveChecksum
+	}
+	if binary.BigEndian.Uint64(count) != a.next-a.From || a.next != a.To+1 {
+		return ErrArchiveBlockSequence
+	}
+
+	a.done = true
+	return io.EOF
+}
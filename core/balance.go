@@ -0,0 +1,90 @@
+package core
+
+import (
+	"bytes"
+	"github.com/cyyber/go-qrl/core/transactions"
+)
+
+// Balance is the balance of an address split by how settled it is
+type Balance struct {
+	// Balance after the blocks with at least MinConfirmations
+	// confirmations, the tip being confirmed once
+	Confirmed uint64
+	// Balance once the more recent blocks and the pending transactions are
+	// counted, pending transfers to the address included
+	Unconfirmed uint64
+	// Part of the balance at the tip which can't be spent yet by the next
+	// block, time-locked transfers
+	Locked uint64
+
+	MinConfirmations uint64
+	Height           uint64
+}
+
+// GetBalance returns the balance of address. The confirmed balance is read
+// from the undo records, so minConfirmations is limited by ReorgLimit.
+func (c *Chain) GetBalance(address []byte, minConfirmations uint64) (*Balance, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	height := c.lastBlock.BlockNumber()
+	addrState, err := c.state.GetAddressState(address)
+	if err != nil {
+		addrState = GetDefaultAddressState(address)
+	}
+	balance := &Balance{
+		Confirmed:        addrState.Balance(),
+		Locked:           addrState.LockedBalance(height + 1),
+		MinConfirmations: minConfirmations,
+		Height:           height,
+	}
+
+	if minConfirmations > height+1 {
+		balance.Confirmed = 0
+	} else if minConfirmations > 1 {
+		// The undo record of a block holds the state before it, the
+		// earliest record holding the address gives the state after the
+		// last confirmed block
+		target := height - minConfirmations + 1
+		for blockNumber := height; blockNumber > target; blockNumber-- {
+			record, err := c.state.GetUndoRecord(blockNumber)
+			if err != nil {
+				return nil, ErrUndoRecordMissing
+			}
+			preState, ok := record.PreStates[string(address)]
+			if !ok {
+				continue
+			}
+			balance.Confirmed = 0
+			if len(preState) != 0 {
+				confirmedState, err := DeSerializeAddressState(preState)
+				if err != nil {
+					return nil, err
+				}
+				balance.Confirmed = confirmedState.Balance()
+			}
+		}
+	}
+
+	unconfirmed := addrState.Balance()
+	var spent uint64
+	for _, ti := range c.txPool.TransactionInfos() {
+		tx := ti.Transaction()
+		// A transaction signed by a slave spends from its master, AddrFrom,
+		// not from the address of the signing key
+		if bytes.Equal(tx.AddrFrom(), address) {
+			spent += requiredBalance(tx)
+		}
+		if transfer, ok := tx.(*transactions.TransferTransaction); ok {
+			for i, addrTo := range transfer.AddrsTo() {
+				if bytes.Equal(addrTo, address) {
+					unconfirmed += transfer.Amounts()[i]
+				}
+			}
+		}
+	}
+	if spent < unconfirmed {
+		balance.Unconfirmed = unconfirmed - spent
+	}
+	return balance, nil
+}
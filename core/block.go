@@ -1,13 +1,15 @@
 package core
 
 import (
-	"github.com/cyyber/go-qrl/generated"
-	"github.com/golang/protobuf/proto"
-	"github.com/golang/protobuf/jsonpb"
-	"github.com/cyyber/go-qrl/core/transactions"
+	"bytes"
 	"container/list"
-	"github.com/cyyber/go-qrl/misc"
+	"errors"
+	"github.com/cyyber/go-qrl/core/transactions"
+	"github.com/cyyber/go-qrl/generated"
 	"github.com/cyyber/go-qrl/log"
+	"github.com/cyyber/go-qrl/misc"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
 )
 
 type BlockInterface interface {
@@ -119,14 +121,25 @@ func (b *Block) MiningBlob() []byte {
 	return b.blockheader.MiningBlob()
 }
 
+// SetNonces replaces the header of the block by the header sealed with
+// other nonces. The previous header is left untouched, it may be shared.
+func (b *Block) SetNonces(miningNonce uint32, extraNonce uint64) {
+	b.setHeader(b.blockheader.Template().SetNonces(miningNonce, extraNonce).Seal())
+}
+
+func (b *Block) setHeader(blockheader *BlockHeader) {
+	b.blockheader = blockheader
+	b.block.Header = blockheader.blockHeader
+}
+
 func (b *Block) CreateBlock(minerAddress []byte, blockNumber uint64, prevBlockHeaderhash []byte, prevBlockTimestamp uint64, txs list.List, timestamp uint64) *Block {
 	feeReward := uint64(0)
-	for _, tx := range b.Transactions() {
-		feeReward += tx.Fee
+	for e := txs.Front(); e != nil; e = e.Next() {
+		feeReward += e.Value.(transactions.TransactionInterface).Fee()
 	}
 
 	totalRewardAmount := BlockRewardCalc(blockNumber, b.config) + feeReward
-	coinbaseTX := transactions.CreateCoinBase(minerAddress, blockNumber, totalRewardAmount)
+	coinbaseTX := transactions.CreateCoinBase(minerAddress, blockNumber, totalRewardAmount, b.config)
 	var hashes list.List
 	hashes.PushBack(coinbaseTX.Txhash())
 	b.block.Transactions = append(b.block.Transactions, coinbaseTX.PBData())
@@ -139,18 +152,36 @@ func (b *Block) CreateBlock(minerAddress []byte, blockNumber uint64, prevBlockHe
 
 	merkleRoot := misc.MerkleTXHash(hashes)
 
-	b.blockheader = CreateBlockHeader(blockNumber, prevBlockHeaderhash, prevBlockTimestamp, merkleRoot, feeReward, timestamp)
-	b.block.Header = b.blockheader.blockHeader
-	b.blockheader.SetNonces(0 ,0)
+	b.setHeader(NewHeaderTemplate(blockNumber, prevBlockHeaderhash, prevBlockTimestamp, merkleRoot, feeReward, timestamp, b.config).Seal())
 
 	return b
 }
 
+// Clone returns a deep copy of the block, sharing nothing with b
+func (b *Block) Clone() *Block {
+	block := proto.Clone(b.block).(*generated.Block)
+	blockheader := *b.blockheader
+	blockheader.blockHeader = block.Header
+	return &Block{
+		block:       block,
+		blockheader: &blockheader,
+		config:      b.config,
+		log:         b.log,
+	}
+}
+
+// Equals compares the headerhashes first, then the whole blocks
+func (b *Block) Equals(other *Block) bool {
+	if other == nil {
+		return false
+	}
+	return bytes.Equal(b.HeaderHash(), other.HeaderHash()) && proto.Equal(b.block, other.block)
+}
+
 func (b *Block) FromJSON(jsonData string) *Block {
 	b.block = &generated.Block{}
 	jsonpb.UnmarshalString(jsonData, b.block)
-	b.blockheader = new(BlockHeader)
-	b.blockheader.SetPBData(b.block.Header)
+	b.blockheader = &BlockHeader{blockHeader: b.block.Header, config: b.config}
 	return b
 }
 
@@ -163,18 +194,35 @@ func (b *Block) Serialize() ([]byte, error) {
 	return proto.Marshal(b.block)
 }
 
+var ErrMissingBlockHeader = errors.New("block without header")
+
 func DeSerializeBlock(data []byte) (*Block, error) {
-	b := &Block{}
+	b := &Block{block: &generated.Block{}, blockheader: &BlockHeader{}}
 
 	if err := proto.Unmarshal(data, b.block); err != nil {
 		return b, err
 	}
+	if b.block.Header == nil {
+		return nil, ErrMissingBlockHeader
+	}
 
 	b.blockheader.blockHeader = b.block.Header
 
 	return b, nil
 }
 
+// BlockFromPBData wraps a block received from a peer
+func BlockFromPBData(pbBlock *generated.Block, config *Config) (*Block, error) {
+	if pbBlock == nil || pbBlock.Header == nil {
+		return nil, ErrMissingBlockHeader
+	}
+	return &Block{
+		block:       pbBlock,
+		blockheader: &BlockHeader{blockHeader: pbBlock.Header, config: config},
+		config:      config,
+	}, nil
+}
+
 func (b *Block) PrepareAddressesList() map[string]*AddressState {
 	var addressesState map[string]*AddressState
 	for _, protoTX := range b.Transactions() {
@@ -184,26 +232,82 @@ func (b *Block) PrepareAddressesList() map[string]*AddressState {
 	return addressesState
 }
 
-func (b *Block) ApplyStateChanges(addressesState map[string]*AddressState) bool {
-	coinbase := transactions.CoinBase{}
-	coinbase.SetPBData(b.block.Transactions[0])
+// validateCoinbase checks that the block starts with the only coinbase
+// transaction of the block, paying exactly the block reward plus the fees
+// of the other transactions
+func (b *Block) validateCoinbase() (*transactions.CoinBase, bool) {
+	if len(b.Transactions()) == 0 {
+		b.log.Warn("Block without coinbase transaction")
+		return nil, false
+	}
+
+	if b.Transactions()[0].GetCoinbase() == nil {
+		b.log.Warn("First transaction of the block is not a coinbase transaction")
+		return nil, false
+	}
+
+	feeReward := uint64(0)
+	for _, protoTX := range b.Transactions()[1:] {
+		if protoTX.GetCoinbase() != nil {
+			b.log.Warn("Block has more than one coinbase transaction")
+			return nil, false
+		}
+		feeReward += protoTX.Fee
+	}
+
+	coinbase := transactions.ProtoToTransaction(b.Transactions()[0]).(*transactions.CoinBase)
+	coinbase.SetConfig(b.config, b.log)
 
 	if !coinbase.ValidateExtended(b.BlockNumber()) {
 		b.log.Warn("coinbase transaction failed")
+		return nil, false
+	}
+
+	expectedAmount := BlockRewardCalc(b.BlockNumber(), b.config) + feeReward
+	if coinbase.Amount() != expectedAmount {
+		b.log.Warn("Invalid coinbase amount", "amount", coinbase.Amount(), "expected", expectedAmount)
+		return nil, false
+	}
+
+	return coinbase, true
+}
+
+func (b *Block) ApplyStateChanges(addressesState map[string]*AddressState) bool {
+	return b.applyStateChanges(addressesState, true, nil)
+}
+
+// applyStateChanges skips the signature checks of the transactions when
+// verifySignatures is false, for blocks whose signatures were verified
+// before. The signatures verified are counted in profile when it's not nil.
+func (b *Block) applyStateChanges(addressesState map[string]*AddressState, verifySignatures bool, profile *BlockProfile) bool {
+	coinbase, ok := b.validateCoinbase()
+	if !ok {
 		return false
 	}
 
 	coinbase.ApplyStateChanges(addressesState)
 
-	for i := 1; i <= len(b.Transactions()); i++ {
+	for i := 1; i < len(b.Transactions()); i++ {
 		tx := transactions.ProtoToTransaction(b.Transactions()[i])
 
-
-		if !tx.Validate(misc.BytesToUCharVector(tx.GetHashableBytes()), true) {
+		if verifySignatures && profile != nil {
+			profile.SignatureVerifications++
+		}
+		if !tx.Validate(misc.BytesToUCharVector(tx.GetHashableBytes()), verifySignatures) {
 			b.log.Warn("failed transaction validation")
 			return false
 		}
 
+		if tx.IsExpired(b.BlockNumber()) {
+			b.log.Warn("expired transaction included", "txhash", tx.Txhash(), "expiry", tx.ExpiryBlockNumber())
+			return false
+		}
+
+		if !tx.IsUnlockHeightValid(b.BlockNumber()) {
+			b.log.Warn("invalid unlock height", "txhash", tx.Txhash(), "unlockheight", tx.UnlockHeight())
+			return false
+		}
+
 		addrFromPKState := addressesState[string(tx.AddrFrom())]
 		addrFromPK := tx.GetSlave()
 		if addrFromPK != nil {
@@ -215,6 +319,11 @@ func (b *Block) ApplyStateChanges(addressesState map[string]*AddressState) bool
 			return false
 		}
 
+		if addressesState[string(tx.AddrFrom())].SpendableBalance(b.BlockNumber()) < requiredBalance(tx) {
+			b.log.Warn("spending locked balance", "txhash", tx.Txhash())
+			return false
+		}
+
 		expectedNonce := addrFromPKState.Nonce() + 1
 
 		if tx.Nonce() != expectedNonce {
@@ -225,13 +334,17 @@ func (b *Block) ApplyStateChanges(addressesState map[string]*AddressState) bool
 		}
 
 		if addrFromPKState.OTSKeyReuse(tx.OtsKey()) {
-			b.log.Warn("pubkey reuse detected: invalid tx %s", string(tx.Txhash()))
+			b.log.Warn("pubkey reuse detected: invalid tx %s", misc.Bin2HStr(tx.Txhash()))
 			//b.log.Warn("subtype: %s", tx.Type())
 			return false
 		}
 
 		tx.ApplyStateChanges(addressesState)
 	}
+
+	for _, addrState := range addressesState {
+		addrState.PruneLockedBalances(b.BlockNumber(), b.config.Dev.ReorgLimit)
+	}
 	return true
 }
 
@@ -263,7 +376,10 @@ func (b *Block) Validate(c *Chain, futureBlocks map[string]*Block) bool {
 		}
 	}
 
-	if !b.blockheader.ValidateParentChildRelation(parentBlock) {
+	ancestorTimestamps := func(count int) []uint32 {
+		return c.ancestorTimestamps(parentBlock, count, futureBlocks)
+	}
+	if !b.blockheader.ValidateParentChildRelation(parentBlock, ancestorTimestamps) {
 		b.log.Warn("Failed to validate blocks parent child relation")
 		return false
 	}
@@ -278,29 +394,53 @@ func (b *Block) Validate(c *Chain, futureBlocks map[string]*Block) bool {
 		feeReward += b.Transactions()[i].Fee
 	}
 
-	if len(b.Transactions()) == 0 {
+	// Blocks before the weight fork are only limited by size
+	if b.config.Dev.IsBlockWeightActive(b.BlockNumber()) && b.Weight() > b.config.Dev.BlockMaxWeight {
+		b.log.Warn("Block weight above limit", "weight", b.Weight(), "limit", b.config.Dev.BlockMaxWeight)
 		return false
 	}
 
-	coinbaseTX := transactions.CoinBase{}.FromPBData(b.Transactions()[0])
+	coinbaseTX, ok := b.validateCoinbase()
+	if !ok {
+		return false
+	}
 	coinbaseAmount := coinbaseTX.Amount()
 
-	if !coinbaseTX.ValidateExtended(b.BlockNumber()) {
-		return false
+	// The merkle root only commits to the txhashes the block claims, so they
+	// are checked against the content of the transactions even when the
+	// root is cached
+	for _, protoTX := range b.Transactions() {
+		tx := transactions.ProtoToTransaction(protoTX)
+		if !bytes.Equal(tx.Txhash(), transactions.ExpectedTxhash(tx)) {
+			b.log.Warn("Invalid transaction", "txhash", misc.Bin2HStr(tx.Txhash()), "error", transactions.ErrTxhashMismatch)
+			return false
+		}
 	}
 
-	var hashes list.List
-	hashes.PushBack(coinbaseTX.Txhash())
+	// The merkle root is only computed again for blocks not seen before
+	merkleRoot := b.blockheader.TxMerkleRoot()
+	cached := c.validated.get(b.HeaderHash()).merkleRoot
+	if !cached {
+		var hashes list.List
+		hashes.PushBack(coinbaseTX.Txhash())
 
-	for i := 1; i < len(b.Transactions()); i++ {
-		hashes.PushBack(b.Transactions()[i].TransactionHash)
-	}
+		for i := 1; i < len(b.Transactions()); i++ {
+			hashes.PushBack(b.Transactions()[i].TransactionHash)
+		}
 
-	merkleRoot := misc.MerkleTXHash(hashes)
+		merkleRoot = misc.MerkleTXHash(hashes)
+	}
 
+	b.blockheader.clock = c.clock
 	if !b.blockheader.Validate(feeReward, coinbaseAmount, merkleRoot) {
 		return false
 	}
+	c.validated.update(b.HeaderHash(), func(validated *validatedBlock) {
+		validated.merkleRoot = true
+		if !cached {
+			validated.checksMissed++
+		}
+	})
 
 	return true
 }
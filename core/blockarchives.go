@@ -0,0 +1,284 @@
+package core
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/cyyber/go-qrl/diagnostics"
+	"github.com/cyyber/go-qrl/log"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The BlockArchiver writes a chain archive of the mainchain blocks, from
+// genesis to every multiple of BlockArchives.Interval blocks once it's
+// ReorgLimit blocks deep, in the BlockArchives.Directory of the data
+// directory. These are block archives, not state snapshots: each one holds
+// the whole chain up to its height, so its size grows with the chain, and a
+// node restoring one replays every block through the chain import. Only the
+// last BlockArchives.Keep archives are kept.
+//
+// The archives are served over HTTPS on their own listener, to the holders
+// of BlockArchives.Token, to seed new nodes or recover a damaged one. They
+// aren't served without a token or a TLS certificate.
+
+var archiveName = regexp.MustCompile(`^chain-([0-9]+)\.qrl$`)
+
+var ErrArchivesNotServed = errors.New("block archives require a token and a TLS certificate to be served")
+
+type BlockArchiveInfo struct {
+	Name   string `json:"name"`
+	Height uint64 `json:"height"`
+	Size   int64  `json:"size"`
+}
+
+type BlockArchiver struct {
+	chain  *Chain
+	config *Config
+	log    log.Logger
+
+	dir    string
+	server *http.Server
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewBlockArchiver(chain *Chain, config *Config, log log.Logger) *BlockArchiver {
+	return &BlockArchiver{
+		chain:  chain,
+		config: config,
+		log:    log,
+		dir:    path.Join(config.DataDir(), config.User.BlockArchives.Directory),
+	}
+}
+
+func (a *BlockArchiver) Start() error {
+	a.quit = make(chan struct{})
+
+	// Relay-only nodes don't keep the blocks to archive
+	archivesConfig := a.config.User.BlockArchives
+	if !archivesConfig.Enabled || archivesConfig.Interval == 0 || a.config.User.RelayOnly {
+		return nil
+	}
+	if err := a.serve(); err != nil {
+		return err
+	}
+	a.wg.Add(1)
+	diagnostics.Go("archives", a.run)
+	return nil
+}
+
+func (a *BlockArchiver) Stop() {
+	close(a.quit)
+	a.wg.Wait()
+	if a.server != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		a.server.Shutdown(ctx)
+		a.server = nil
+	}
+}
+
+// serve starts the HTTPS server of the archives, they are still written
+// when it isn't configured
+func (a *BlockArchiver) serve() error {
+	archivesConfig := a.config.User.BlockArchives
+	if archivesConfig.TLSCertFile == "" || archivesConfig.TLSKeyFile == "" || a.config.Settings().BlockArchives.Token == "" {
+		a.log.Warn("Block archives not served", "error", ErrArchivesNotServed)
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/archives/", a)
+	a.server = &http.Server{
+		Addr:    net.JoinHostPort(archivesConfig.Host, strconv.Itoa(int(archivesConfig.Port))),
+		Handler: mux,
+	}
+	listener, err := net.Listen("tcp", a.server.Addr)
+	if err != nil {
+		return err
+	}
+
+	a.log.Info("Serving block archives", "address", a.server.Addr)
+	server := a.server
+	go func() {
+		if err := server.ServeTLS(listener, archivesConfig.TLSCertFile, archivesConfig.TLSKeyFile); err != nil && err != http.ErrServerClosed {
+			a.log.Warn("Block archives server stopped", "error", err)
+		}
+	}()
+	return nil
+}
+
+func (a *BlockArchiver) run() {
+	defer a.wg.Done()
+
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.quit:
+			return
+		case <-ticker.C:
+			a.archiveDue()
+		}
+	}
+}
+
+// archiveDue writes the archive of the last interval boundary below the
+// reorg limit if it's missing
+func (a *BlockArchiver) archiveDue() {
+	height := a.chain.Height()
+	if height < a.config.Dev.ReorgLimit {
+		return
+	}
+	interval := a.config.User.BlockArchives.Interval
+	boundary := (height - a.config.Dev.ReorgLimit) / interval * interval
+	if boundary == 0 {
+		return
+	}
+
+	archives, err := a.List()
+	if err != nil {
+		a.log.Warn("Failed to list block archives", "error", err)
+		return
+	}
+	if len(archives) > 0 && archives[len(archives)-1].Height >= boundary {
+		return
+	}
+	if err := a.Archive(boundary); err != nil {
+		a.log.Warn("Block archive failed", "height", boundary, "error", err)
+		return
+	}
+	a.prune()
+}
+
+// Archive writes the archive of the mainchain blocks up to height
+func (a *BlockArchiver) Archive(height uint64) error {
+	if err := os.MkdirAll(a.dir, 0700); err != nil {
+		return err
+	}
+	filename := path.Join(a.dir, fmt.Sprintf("chain-%d.qrl", height))
+
+	start := time.Now()
+	a.log.Info("Writing block archive", "height", height)
+	f, err := os.Create(filename + ".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(filename + ".tmp")
+	defer f.Close()
+
+	archive, err := NewArchiveWriter(f, 0, height)
+	if err != nil {
+		return err
+	}
+	err = a.chain.IterateBlocks(0, height, func(block *Block) error {
+		select {
+		case <-a.quit:
+			return ErrStopIteration
+		default:
+		}
+		return archive.WriteBlock(block)
+	})
+	if err != nil {
+		return err
+	}
+	// Closing the archive with missing blocks fails, as when stopped early
+	if err := archive.Close(); err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		return err
+	}
+	if err := os.Rename(filename+".tmp", filename); err != nil {
+		return err
+	}
+	a.log.Info("Block archive written", "height", height, "duration", time.Since(start))
+	return nil
+}
+
+// List returns the archives available, oldest first
+func (a *BlockArchiver) List() ([]*BlockArchiveInfo, error) {
+	files, err := ioutil.ReadDir(a.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var archives []*BlockArchiveInfo
+	for _, file := range files {
+		match := archiveName.FindStringSubmatch(file.Name())
+		if match == nil {
+			continue
+		}
+		var height uint64
+		fmt.Sscan(match[1], &height)
+		archives = append(archives, &BlockArchiveInfo{
+			Name:   file.Name(),
+			Height: height,
+			Size:   file.Size(),
+		})
+	}
+	sort.Slice(archives, func(i, j int) bool {
+		return archives[i].Height < archives[j].Height
+	})
+	return archives, nil
+}
+
+// prune removes the archives beyond the Keep newest
+func (a *BlockArchiver) prune() {
+	archives, err := a.List()
+	if err != nil {
+		return
+	}
+	keep := int(a.config.Settings().BlockArchives.Keep)
+	for i := 0; i < len(archives)-keep; i++ {
+		if err := os.Remove(path.Join(a.dir, archives[i].Name)); err != nil {
+			a.log.Warn("Failed to remove block archive", "name", archives[i].Name, "error", err)
+		}
+	}
+}
+
+// ServeHTTP lists the archives on /archives/ and serves them on
+// /archives/<name>, to requests holding the token as a bearer token. Every
+// request is refused when no token is set.
+func (a *BlockArchiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token := a.config.Settings().BlockArchives.Token
+	provided := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	name := path.Base(r.URL.Path)
+	if name == "archives" {
+		archives, err := a.List()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(archives)
+		return
+	}
+	if !archiveName.MatchString(name) {
+		http.NotFound(w, r)
+		return
+	}
+	http.ServeFile(w, r, path.Join(a.dir, name))
+}
@@ -0,0 +1,156 @@
+package core
+
+import (
+	"container/list"
+	"sync"
+)
+
+// blockCache keeps the most recently read blocks decoded, validation, the
+// APIs and peers mostly ask for the blocks around the tip. Blocks are
+// immutable once stored so entries never go stale by hash; the height index
+// only tracks the mainchain and is updated whenever the mainchain changes.
+// The cache keeps its own copies and hands out clones, so callers are free
+// to modify the blocks they get.
+type blockCache struct {
+	lock sync.Mutex
+
+	size     int
+	entries  *list.List
+	byHash   map[string]*list.Element
+	byNumber map[uint64]string
+
+	hits   uint64
+	misses uint64
+}
+
+func newBlockCache(size int) *blockCache {
+	return &blockCache{
+		size:     size,
+		entries:  list.New(),
+		byHash:   make(map[string]*list.Element),
+		byNumber: make(map[uint64]string),
+	}
+}
+
+func (b *blockCache) get(headerHash []byte) *Block {
+	if b.size == 0 {
+		return nil
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	e, ok := b.byHash[string(headerHash)]
+	if !ok {
+		b.misses++
+		return nil
+	}
+	b.hits++
+	b.entries.MoveToFront(e)
+	return e.Value.(*Block).Clone()
+}
+
+func (b *blockCache) getByNumber(blockNumber uint64) *Block {
+	if b.size == 0 {
+		return nil
+	}
+	b.lock.Lock()
+	headerHash, ok := b.byNumber[blockNumber]
+	b.lock.Unlock()
+	if !ok {
+		return nil
+	}
+	return b.get([]byte(headerHash))
+}
+
+func (b *blockCache) add(block *Block) {
+	if b.size == 0 {
+		return
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.addLocked(block)
+}
+
+func (b *blockCache) addLocked(block *Block) {
+	key := string(block.HeaderHash())
+	if e, ok := b.byHash[key]; ok {
+		b.entries.MoveToFront(e)
+		return
+	}
+
+	b.byHash[key] = b.entries.PushFront(block.Clone())
+	for b.entries.Len() > b.size {
+		oldest := b.entries.Back()
+		evicted := b.entries.Remove(oldest).(*Block)
+		delete(b.byHash, string(evicted.HeaderHash()))
+		if b.byNumber[evicted.BlockNumber()] == string(evicted.HeaderHash()) {
+			delete(b.byNumber, evicted.BlockNumber())
+		}
+	}
+}
+
+// addMainchain caches block as the mainchain block at its height
+func (b *blockCache) addMainchain(block *Block) {
+	if b.size == 0 {
+		return
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.addLocked(block)
+	b.byNumber[block.BlockNumber()] = string(block.HeaderHash())
+}
+
+// removeMainchain forgets the mainchain block at blockNumber, the block
+// itself stays cached by hash
+func (b *blockCache) removeMainchain(blockNumber uint64) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	delete(b.byNumber, blockNumber)
+}
+
+func (b *blockCache) reset() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.entries.Init()
+	b.byHash = make(map[string]*list.Element)
+	b.byNumber = make(map[uint64]string)
+}
+
+// getBlock reads a block through the cache, the Chain lock must be held
+func (c *Chain) getBlock(headerHash []byte) (*Block, error) {
+	if block := c.blocks.get(headerHash); block != nil {
+		return block, nil
+	}
+	block, err := c.state.GetBlock(headerHash)
+	if err != nil {
+		return nil, err
+	}
+	c.blocks.add(block)
+	return block, nil
+}
+
+// getBlockByNumber reads a mainchain block through the cache, the Chain
+// lock must be held
+func (c *Chain) getBlockByNumber(blockNumber uint64) (*Block, error) {
+	if block := c.blocks.getByNumber(blockNumber); block != nil {
+		return block, nil
+	}
+	block, err := c.state.GetBlockByNumber(blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	c.blocks.addMainchain(block)
+	return block, nil
+}
+
+// BlockCacheStats returns the hits and misses of the block cache
+func (c *Chain) BlockCacheStats() (hits uint64, misses uint64) {
+	c.blocks.lock.Lock()
+	defer c.blocks.lock.Unlock()
+
+	return c.blocks.hits, c.blocks.misses
+}
@@ -0,0 +1,221 @@
+package core
+
+import (
+	"errors"
+	"github.com/cyyber/go-qrl/diagnostics"
+	"github.com/cyyber/go-qrl/log"
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+	"sync"
+	"time"
+)
+
+// Block bodies may be stored compressed. A compressed record starts with
+// blockCompressedMarker, which can't start a serialized block as protobuf
+// field numbers start at 1, followed by the codec byte and the compressed
+// block, so records written before compression was enabled, or with it
+// off, are read as they are. Compression is off by default, binaries older
+// than block compression can't read compressed records.
+
+const (
+	BlockCompressionOff    = "off"
+	BlockCompressionSnappy = "snappy"
+	BlockCompressionZstd   = "zstd"
+)
+
+const (
+	blockCompressedMarker byte = 0
+
+	blockCodecSnappy byte = 1
+	blockCodecZstd   byte = 2
+)
+
+// Blocks recompressed per batch by the BlockRecompressor, which pauses
+// between batches to leave IO to the node
+const (
+	recompressBatchBlocks = 100
+	recompressPause       = 100 * time.Millisecond
+)
+
+var ErrUnknownBlockCodec = errors.New("unknown block compression codec")
+
+var (
+	blockZstdOnce    sync.Once
+	blockZstdEncoder *zstd.Encoder
+	blockZstdDecoder *zstd.Decoder
+)
+
+func initBlockZstd() {
+	blockZstdOnce.Do(func() {
+		blockZstdEncoder, _ = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedDefault))
+		blockZstdDecoder, _ = zstd.NewReader(nil)
+	})
+}
+
+func blockCodec(compression string) (byte, bool) {
+	switch compression {
+	case BlockCompressionSnappy:
+		return blockCodecSnappy, true
+	case BlockCompressionZstd:
+		return blockCodecZstd, true
+	}
+	return 0, false
+}
+
+// compressBlock returns the record stored for the serialized block data
+func compressBlock(data []byte, compression string) []byte {
+	codec, ok := blockCodec(compression)
+	if !ok {
+		return data
+	}
+
+	var compressed []byte
+	switch codec {
+	case blockCodecSnappy:
+		compressed = snappy.Encode(nil, data)
+	case blockCodecZstd:
+		initBlockZstd()
+		compressed = blockZstdEncoder.EncodeAll(data, nil)
+	}
+	// Small blocks may not shrink
+	if len(compressed)+2 >= len(data) {
+		return data
+	}
+	return append([]byte{blockCompressedMarker, codec}, compressed...)
+}
+
+// decompressBlock returns the serialized block held by a stored record
+func decompressBlock(value []byte) ([]byte, error) {
+	if len(value) == 0 || value[0] != blockCompressedMarker {
+		return value, nil
+	}
+	if len(value) < 2 {
+		return nil, ErrUnknownBlockCodec
+	}
+
+	switch value[1] {
+	case blockCodecSnappy:
+		return snappy.Decode(nil, value[2:])
+	case blockCodecZstd:
+		initBlockZstd()
+		return blockZstdDecoder.DecodeAll(value[2:], nil)
+	}
+	return nil, ErrUnknownBlockCodec
+}
+
+// storedBlockCodec returns the codec of a stored record, 0 if it isn't
+// compressed
+func storedBlockCodec(value []byte) byte {
+	if len(value) < 2 || value[0] != blockCompressedMarker {
+		return 0
+	}
+	return value[1]
+}
+
+func (s *State) blockCompression() string {
+	if s.config == nil {
+		return BlockCompressionOff
+	}
+	return s.config.User.BlockCompression
+}
+
+// recompressBlock stores the mainchain block blockNumber again with the
+// configured compression, returning false if it already was. The chain lock
+// is held so a reorg can't remove the block between its read and its write.
+func (c *Chain) recompressBlock(blockNumber uint64) (bool, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	mapping, err := c.state.GetBlockNumberMapping(blockNumber)
+	if err != nil || mapping == nil {
+		return false, nil
+	}
+	return c.state.recompressBlock(mapping.Headerhash)
+}
+
+func (s *State) recompressBlock(headerHash []byte) (bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	value, err := s.db.Get(headerHash)
+	if err != nil {
+		return false, err
+	}
+	codec, _ := blockCodec(s.blockCompression())
+	if storedBlockCodec(value) == codec {
+		return false, nil
+	}
+
+	data, err := decompressBlock(value)
+	if err != nil {
+		return false, err
+	}
+	stored := compressBlock(data, s.blockCompression())
+	if storedBlockCodec(stored) == storedBlockCodec(value) {
+		// Not worth compressing
+		return false, nil
+	}
+	return true, s.db.Put(headerHash, stored, nil)
+}
+
+// BlockRecompressor rewrites the mainchain blocks stored with another
+// compression than the configured one, such as blocks written before
+// compression was enabled, in the background once the node started
+type BlockRecompressor struct {
+	chain *Chain
+	log   log.Logger
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewBlockRecompressor(chain *Chain, log log.Logger) *BlockRecompressor {
+	return &BlockRecompressor{
+		chain: chain,
+		log:   log,
+	}
+}
+
+func (r *BlockRecompressor) Start() {
+	r.quit = make(chan struct{})
+	r.wg.Add(1)
+	diagnostics.Go("recompression", r.run)
+}
+
+func (r *BlockRecompressor) Stop() {
+	close(r.quit)
+	r.wg.Wait()
+}
+
+func (r *BlockRecompressor) run() {
+	defer r.wg.Done()
+
+	height, err := r.chain.state.GetChainHeight()
+	if err != nil {
+		return
+	}
+
+	start := time.Now()
+	var rewritten uint64
+	for blockNumber := uint64(0); blockNumber <= height; blockNumber++ {
+		if blockNumber%recompressBatchBlocks == 0 {
+			select {
+			case <-r.quit:
+				return
+			case <-time.After(recompressPause):
+			}
+		}
+
+		ok, err := r.chain.recompressBlock(blockNumber)
+		if err != nil {
+			r.log.Warn("Failed to recompress block", "block", blockNumber, "error", err)
+			continue
+		}
+		if ok {
+			rewritten++
+		}
+	}
+	if rewritten > 0 {
+		r.log.Info("Blocks recompressed", "count", rewritten, "duration", time.Since(start))
+	}
+}
@@ -1,15 +1,14 @@
 package core
 
 import (
-	"encoding/binary"
 	"bytes"
-	"github.com/golang/protobuf/jsonpb"
-	"github.com/theQRL/qrllib/goqrllib"
-	"github.com/cyyber/go-qrl/misc"
+	"encoding/binary"
 	"github.com/cyyber/go-qrl/generated"
 	"github.com/cyyber/go-qrl/log"
-	"reflect"
+	"github.com/cyyber/go-qrl/misc"
 	"github.com/cyyber/go-qrl/pow"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
 )
 
 type BlockHeaderInterface interface {
@@ -42,30 +41,37 @@ type BlockHeaderInterface interface {
 
 	GenerateHeaderHash() []byte
 
-	UpdateMerkleRoot([]byte)
-
-	SetNonces(uint32, uint64)
-
-	SetMiningNonceFromBlob([]byte)
+	Template() *HeaderTemplate
 
 	Validate(uint64, uint64) bool
 
-	ValidateParentChildRelation(block Block) bool
+	ValidateParentChildRelation(block Block, ancestorTimestamps func(int) []uint32) bool
 
 	VerifyBlob([]byte) bool
 
-	SetPBData(*generated.BlockHeader)
-
 	FromJSON(string) BlockHeader
 
 	JSON() string
 }
 
+// BlockHeader is a sealed header, its fields are never changed once created
+// so it can be shared between goroutines. Headers are built and mined
+// through a HeaderTemplate.
 type BlockHeader struct {
 	blockHeader *generated.BlockHeader
 
 	config *Config
 	log    log.Logger
+	clock  misc.Clock
+}
+
+// now returns the time of the clock injected by the Chain, headers created
+// outside of a Chain use NTP
+func (bh *BlockHeader) now() uint64 {
+	if bh.clock == nil {
+		return misc.GetNTP().Time()
+	}
+	return bh.clock.Time()
 }
 
 func (bh *BlockHeader) BlockNumber() uint64 {
@@ -117,34 +123,35 @@ func (bh *BlockHeader) ExtraNonceOffset() uint16 {
 }
 
 func (bh *BlockHeader) MiningBlob() []byte {
-	tmp := new(bytes.Buffer)
-	binary.Write(tmp, binary.BigEndian, uint64(bh.BlockNumber()))
-	binary.Write(tmp, binary.BigEndian, uint64(bh.Timestamp()))
-	tmp.Write(bh.PrevHeaderHash())
-	binary.Write(tmp, binary.BigEndian, uint64(bh.BlockReward()))
-	binary.Write(tmp, binary.BigEndian, uint64(bh.FeeReward()))
-	tmp.Write(bh.TxMerkleRoot())
+	return miningBlob(bh.blockHeader, bh.config)
+}
 
-	blob := misc.UcharVector{}
-	blob.AddByte(0)
-	blob.AddBytes(tmp.Bytes())
+func miningBlob(blockHeader *generated.BlockHeader, config *Config) []byte {
+	tmp := new(bytes.Buffer)
+	binary.Write(tmp, binary.BigEndian, uint64(blockHeader.BlockNumber))
+	binary.Write(tmp, binary.BigEndian, uint64(uint32(blockHeader.TimestampSeconds)))
+	tmp.Write(blockHeader.HashHeaderPrev)
+	binary.Write(tmp, binary.BigEndian, uint64(blockHeader.RewardBlock))
+	binary.Write(tmp, binary.BigEndian, uint64(blockHeader.RewardFee))
+	tmp.Write(blockHeader.MerkleRoot)
 
-	blob.New(goqrllib.Shake128(int64(bh.config.Dev.MiningBlobSize - 18), blob.GetData()))
+	blob := misc.Shake128(int(config.Dev.MiningBlobSize-18), append([]byte{0}, tmp.Bytes()...))
 
-	if blob.GetData().Size() < int64(bh.config.Dev.MiningNonceOffset) {
+	if len(blob) < int(config.Dev.MiningNonceOffset) {
 		panic("Mining blob size below 56 bytes")
 	}
 
 	miningNonce := make([]byte, 12)
-	binary.BigEndian.PutUint32(miningNonce, bh.MiningNonce())
-	binary.BigEndian.PutUint64(miningNonce[4:], bh.ExtraNonce())
+	binary.BigEndian.PutUint32(miningNonce, blockHeader.MiningNonce)
+	binary.BigEndian.PutUint64(miningNonce[4:], blockHeader.ExtraNonce)
 
-	finalBlob := misc.UcharVector{}
-	finalBlob.AddBytes(blob.GetBytes()[:bh.NonceOffset()])
-	finalBlob.AddBytes(miningNonce)
-	finalBlob.AddBytes(blob.GetBytes()[bh.NonceOffset():])
+	nonceOffset := config.Dev.MiningNonceOffset
+	var finalBlob []byte
+	finalBlob = append(finalBlob, blob[:nonceOffset]...)
+	finalBlob = append(finalBlob, miningNonce...)
+	finalBlob = append(finalBlob, blob[nonceOffset:]...)
 
-	return finalBlob.GetBytes()
+	return finalBlob
 }
 
 func (bh *BlockHeader) GenerateHeaderHash() []byte {
@@ -153,113 +160,79 @@ func (bh *BlockHeader) GenerateHeaderHash() []byte {
 	return qn.Hash(miningBlob)
 }
 
-func (bh *BlockHeader) UpdateMerkleRoot(hashedtransactions []byte) {
-	bh.blockHeader.MerkleRoot = hashedtransactions
-}
-
-func (bh *BlockHeader) SetNonces(miningNonce uint32, extraNonce uint64) {
-	bh.blockHeader.MiningNonce = miningNonce
-	bh.blockHeader.ExtraNonce = extraNonce
-}
-
-func (bh *BlockHeader) SetMiningNonceFromBlob(blob []byte) {
-	miningNonceBytes := blob[bh.NonceOffset():bh.NonceOffset() + 4]
-	miningNonce := binary.BigEndian.Uint32(miningNonceBytes)
-
-	extraNonceBytes := blob[bh.ExtraNonceOffset():bh.ExtraNonceOffset() + 8]
-	extraNonce := binary.BigEndian.Uint64(extraNonceBytes)
-
-	bh.SetNonces(miningNonce, extraNonce)
-}
-
-func (bh *BlockHeader) Validate(feeReward uint64, coinbaseAmount uint64, txMerkleRoot []byte) bool {
-	ntp := misc.GetNTP()
-	currentTime := uint32(ntp.Time())
-	allowedTimestamp := currentTime + bh.config.Dev.BlockLeadTimestamp
-	if bh.Timestamp() > allowedTimestamp {
-		bh.log.Warn("BLOCK timestamp is more than the allowed block lead timestamp")
-		bh.log.Warn("Block timestamp %s", bh.Timestamp())
-		bh.log.Warn("threshold timestamp %s", allowedTimestamp)
-		return false
-	}
-
-	if bh.Timestamp() < bh.config.Dev.Genesis.GenesisTimestamp {
-		bh.log.Warn("Timestamp lower than genesis timestamp")
-		bh.log.Warn("Genesis Timestamp %s", bh.config.Dev.Genesis.GenesisTimestamp)
-		bh.log.Warn("Block Timestamp %s", bh.Timestamp())
-		return false
-	}
-
-	if !reflect.DeepEqual(bh.GenerateHeaderHash(), bh.HeaderHash()) {
-		bh.log.Warn("Headerhash false for block: failed validation")
-		return false
+// Clone returns a deep copy of the header
+func (bh *BlockHeader) Clone() *BlockHeader {
+	return &BlockHeader{
+		blockHeader: proto.Clone(bh.blockHeader).(*generated.BlockHeader),
+		config:      bh.config,
+		log:         bh.log,
+		clock:       bh.clock,
 	}
+}
 
-	if bh.BlockReward() != BlockRewardCalc(bh.BlockNumber(), bh.config) {
-		bh.log.Warn("Block reward incorrect for block: failed validation")
+// Equals compares the headerhashes first, then the fields
+func (bh *BlockHeader) Equals(other *BlockHeader) bool {
+	if other == nil {
 		return false
 	}
+	return bytes.Equal(bh.HeaderHash(), other.HeaderHash()) && proto.Equal(bh.blockHeader, other.blockHeader)
+}
 
-	if bh.FeeReward() != feeReward {
-		bh.log.Warn("Block Fee reward incorrect for block: failed validation")
-		return false
+// Template returns a template holding a copy of the header, to mine it with
+// other nonces
+func (bh *BlockHeader) Template() *HeaderTemplate {
+	return &HeaderTemplate{
+		blockHeader: proto.Clone(bh.blockHeader).(*generated.BlockHeader),
+		config:      bh.config,
 	}
+}
 
-	if bh.BlockReward() + bh.FeeReward() != coinbaseAmount {
-		bh.log.Warn("Block_reward + fee_reward doesnt sums up to coinbase_amount")
-		return false
+func (bh *BlockHeader) Validate(feeReward uint64, coinbaseAmount uint64, txMerkleRoot []byte) bool {
+	ctx := &RuleContext{
+		Config:         bh.config,
+		Now:            bh.now(),
+		FeeReward:      feeReward,
+		CoinbaseAmount: coinbaseAmount,
+		TxMerkleRoot:   txMerkleRoot,
 	}
-
-	if !reflect.DeepEqual(bh.TxMerkleRoot(), txMerkleRoot) {
-		bh.log.Warn("Invalid TX Merkle Root")
+	if err := CheckRules(HeaderRules, bh, nil, ctx); err != nil {
+		bh.log.Warn("Block header failed validation", "block", bh.BlockNumber(), "error", err)
 		return false
 	}
-
 	return true
 }
 
-func (bh *BlockHeader) ValidateParentChildRelation(parentBlock *Block) bool {
+// ValidateParentChildRelation checks the header against parentBlock, the
+// timestamps of older ancestors are read through ancestorTimestamps
+func (bh *BlockHeader) ValidateParentChildRelation(parentBlock *Block, ancestorTimestamps func(count int) []uint32) bool {
 	if parentBlock == nil {
 		bh.log.Warn("Parent Block not found")
 		return false
 	}
 
-	if parentBlock.BlockNumber() != bh.BlockNumber() - 1 {
-		bh.log.Warn("Block numbers out of sequence: failed validation")
-		return false
-	}
-
-	if !reflect.DeepEqual(parentBlock.HeaderHash(), bh.PrevHeaderHash()) {
-		bh.log.Warn("Headerhash not in sequence: failed validation")
-		return false
+	ctx := &RuleContext{
+		Config:             bh.config,
+		Now:                bh.now(),
+		AncestorTimestamps: ancestorTimestamps,
 	}
-
-	if bh.Timestamp() <= parentBlock.Timestamp() {
-		bh.log.Warn("BLOCK timestamp must be greater than parent block timestamp")
-		bh.log.Warn("block timestamp %s", bh.Timestamp())
-		bh.log.Warn("must be greater than %s", parentBlock.Timestamp())
+	if err := CheckRules(ParentRules, bh, parentBlock, ctx); err != nil {
+		bh.log.Warn("Block header failed validation against parent", "block", bh.BlockNumber(), "error", err)
 		return false
 	}
-
 	return true
 }
 
+// VerifyBlob tells if blob is the mining blob of the header, whatever its
+// nonces. blob isn't modified.
 func (bh *BlockHeader) VerifyBlob(blob []byte) bool {
-	miningNonceOffset := bh.config.Dev.MiningNonceOffset
-	blob = append(blob[:miningNonceOffset], blob[miningNonceOffset + 17:]...)
-
+	miningNonceOffset := int(bh.config.Dev.MiningNonceOffset)
 	actualBlob := bh.MiningBlob()
-	actualBlob = append(actualBlob[:miningNonceOffset], actualBlob[miningNonceOffset + 17:]...)
-
-	if reflect.DeepEqual(blob, actualBlob) {
+	if len(blob) != len(actualBlob) {
 		return false
 	}
 
-	return true
-}
-
-func (bh *BlockHeader) SetPBData(blockHeader *generated.BlockHeader) {
-	bh.blockHeader = blockHeader
+	return bytes.Equal(blob[:miningNonceOffset], actualBlob[:miningNonceOffset]) &&
+		bytes.Equal(blob[miningNonceOffset+17:], actualBlob[miningNonceOffset+17:])
 }
 
 func (bh *BlockHeader) FromJSON(jsonData string) *BlockHeader {
@@ -273,38 +246,9 @@ func (bh *BlockHeader) JSON() (string, error)  {
 	return ma.MarshalToString(bh.blockHeader)
 }
 
-func CreateBlockHeader(blockNumber uint64, prevBlockHeaderHash []byte, prevBlockTimestamp uint64, merkleRoot []byte, feeReward uint64, timestamp uint64) *BlockHeader {
-	bh := &BlockHeader{}
-	bh.blockHeader.BlockNumber = blockNumber
-
-	if bh.blockHeader.BlockNumber != 0 {
-		bh.blockHeader.TimestampSeconds = timestamp
-		// If current block timestamp is less than or equals to the previous block timestamp
-		// then set current block timestamp 1 sec higher than prev_block_timestamp
-		if bh.blockHeader.TimestampSeconds <= prevBlockTimestamp {
-			bh.blockHeader.TimestampSeconds = prevBlockTimestamp + 1
-		}
-		if bh.blockHeader.TimestampSeconds == 0 {
-			bh.log.Warn("Failed to get NTP timestamp")
-			return nil
-		}
-	} else {
-		bh.blockHeader.TimestampSeconds = prevBlockTimestamp  // Set timestamp for genesis block
-	}
-
-	bh.blockHeader.HashHeaderPrev = prevBlockHeaderHash
-	bh.blockHeader.MerkleRoot = merkleRoot
-	bh.blockHeader.RewardFee = feeReward
-
-	bh.blockHeader.RewardBlock = BlockRewardCalc(bh.BlockNumber(), bh.config)
-
-	bh.SetNonces(0, 0)
-	return bh
-}
-
 func BlockRewardCalc(blockNumber uint64, config *Config) uint64 {
 	if blockNumber == 0 {
-		return config.Dev.Genesis.SuppliedCoins
+		return config.Dev.Genesis.SuppliedCoins * config.Dev.ShorPerQuanta
 	}
 	return BlockReward(config.Dev.Genesis.MaxCoinSupply - config.Dev.Genesis.SuppliedCoins, config.Dev.ShorPerQuanta, blockNumber)
 }
\ No newline at end of file
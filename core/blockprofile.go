@@ -0,0 +1,99 @@
+package core
+
+import (
+	"github.com/cyyber/go-qrl/misc"
+	"sync"
+	"time"
+)
+
+// Every block applied is profiled: the address states and OTS bitfield
+// pages read from the database, including the pre-images of its undo
+// record, the address states written, the validation checks which missed
+// the cache, the signatures verified and the time spent applying it. A block exceeding one of the
+// SlowBlock thresholds is logged as a slow block and its report kept for
+// GetSlowBlocks, the last SlowBlock.ReportsKept only.
+
+// BlockProfile is the cost of applying a block to the state
+type BlockProfile struct {
+	BlockNumber  uint64
+	HeaderHash   []byte
+	Transactions uint64
+
+	StateReads  uint64
+	StateWrites uint64
+	// Validation checks, PoW, merkle root and signatures, which missed the
+	// cache and were run in full
+	CacheMisses            uint64
+	SignatureVerifications uint64
+	ApplyTime              time.Duration
+
+	// Thresholds exceeded, empty when the block wasn't slow
+	Exceeded  []string
+	Timestamp uint64
+}
+
+type slowBlocks struct {
+	lock sync.Mutex
+
+	reports []*BlockProfile
+}
+
+func (s *slowBlocks) add(profile *BlockProfile, size uint64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.reports = append(s.reports, profile)
+	if uint64(len(s.reports)) > size {
+		s.reports = append([]*BlockProfile{}, s.reports[uint64(len(s.reports))-size:]...)
+	}
+}
+
+func (s *slowBlocks) list() []*BlockProfile {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return append([]*BlockProfile{}, s.reports...)
+}
+
+// exceeded returns the SlowBlock thresholds exceeded by profile, thresholds
+// set to 0 being disabled
+func (p *BlockProfile) exceeded(config *SlowBlockConfig) []string {
+	var exceeded []string
+	check := func(name string, value uint64, threshold uint64) {
+		if threshold != 0 && value >= threshold {
+			exceeded = append(exceeded, name)
+		}
+	}
+	check("apply_time", uint64(p.ApplyTime/time.Millisecond), config.ApplyTimeMillis)
+	check("state_reads", p.StateReads, config.StateReads)
+	check("state_writes", p.StateWrites, config.StateWrites)
+	check("signature_verifications", p.SignatureVerifications, config.SignatureVerifications)
+	return exceeded
+}
+
+// reportBlockProfile logs and keeps profile when the block was slow
+func (c *Chain) reportBlockProfile(profile *BlockProfile) {
+	config := c.config.Settings().SlowBlock
+	profile.Exceeded = profile.exceeded(config)
+	if len(profile.Exceeded) == 0 {
+		return
+	}
+	profile.Timestamp = c.clock.Time()
+
+	c.log.Warn("Slow block",
+		"blocknumber", profile.BlockNumber,
+		"headerhash", misc.Bin2HStr(profile.HeaderHash),
+		"transactions", profile.Transactions,
+		"statereads", profile.StateReads,
+		"statewrites", profile.StateWrites,
+		"cachemisses", profile.CacheMisses,
+		"signatures", profile.SignatureVerifications,
+		"applytime", profile.ApplyTime,
+		"exceeded", profile.Exceeded)
+	c.slowBlocks.add(profile, config.ReportsKept)
+}
+
+// SlowBlocks returns the reports of the last slow blocks, oldest first
+func (c *Chain) SlowBlocks() []*BlockProfile {
+	return c.slowBlocks.list()
+}
@@ -0,0 +1,210 @@
+package core
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"github.com/cyyber/go-qrl/core/transactions"
+	"github.com/cyyber/go-qrl/generated"
+	"github.com/cyyber/go-qrl/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"sort"
+)
+
+// txCandidates holds the pool transactions signed by one XMSS address,
+// sorted by nonce
+type txCandidates struct {
+	txs []transactions.TransactionInterface
+}
+
+func (t *txCandidates) head() transactions.TransactionInterface {
+	if len(t.txs) == 0 {
+		return nil
+	}
+	return t.txs[0]
+}
+
+func (t *txCandidates) pop() {
+	t.txs = t.txs[1:]
+}
+
+// SelectTransactions picks the pool transactions to be included in the next
+// block. Transactions signed by the same XMSS key are taken in nonce order,
+// stopping at the first gap, and every selected transaction is validated
+// against a working copy of the state with the previously selected
+// transactions already applied. This also resolves slave transactions
+// depending on a slave registration included earlier in the same block.
+// The result only depends on the pool content, the state and the fee
+// policy, so two nodes with the same pool and policy build the same
+// template.
+func (c *Chain) SelectTransactions(poolTxs []transactions.TransactionInterface, sizeLimit int, weightLimit uint64) *list.List {
+	blockNumber := c.lastBlock.BlockNumber() + 1
+	groups := make(map[string]*txCandidates)
+	for _, tx := range poolTxs {
+		if tx.IsExpired(blockNumber) || !tx.IsUnlockHeightValid(blockNumber) || !c.includableByPolicy(tx) {
+			continue
+		}
+		signer := string(tx.AddrFromPK())
+		group, ok := groups[signer]
+		if !ok {
+			group = &txCandidates{}
+			groups[signer] = group
+		}
+		group.txs = append(group.txs, tx)
+	}
+
+	for _, group := range groups {
+		sort.SliceStable(group.txs, func(i, j int) bool {
+			if group.txs[i].Nonce() != group.txs[j].Nonce() {
+				return group.txs[i].Nonce() < group.txs[j].Nonce()
+			}
+			return bytes.Compare(group.txs[i].Txhash(), group.txs[j].Txhash()) < 0
+		})
+	}
+
+	// Selected transactions are applied to an overlay, the state itself is
+	// never modified while building a template
+	overlay := NewStateOverlay(c.state)
+	addressesState := make(map[string]*AddressState)
+	selected := list.New()
+	size := 0
+	// Room is left for the coinbase transaction, added once the
+	// selection is done
+	weight := maxCoinbaseSize * c.config.Dev.Transaction.WeightPerByte
+
+	for {
+		var heads []transactions.TransactionInterface
+		for _, group := range groups {
+			if tx := group.head(); tx != nil {
+				heads = append(heads, tx)
+			}
+		}
+		if len(heads) == 0 {
+			break
+		}
+
+		// Highest fee first, txhash as deterministic tie breaker
+		sort.Slice(heads, func(i, j int) bool {
+			if heads[i].Fee() != heads[j].Fee() {
+				return heads[i].Fee() > heads[j].Fee()
+			}
+			return bytes.Compare(heads[i].Txhash(), heads[j].Txhash()) < 0
+		})
+
+		progress := false
+		for _, tx := range heads {
+			signer := string(tx.AddrFromPK())
+			group := groups[signer]
+
+			txWeight := TransactionWeight(tx.PBData(), c.config)
+			if size+tx.Size() > sizeLimit || weight+txWeight > weightLimit {
+				delete(groups, signer)
+				continue
+			}
+
+			if !c.validateAgainstTip(tx, overlay, addressesState) {
+				// A nonce lower than expected means the transaction is
+				// already mined, it can be skipped. Any other failure could
+				// be resolved by another transaction selected later in this
+				// round, so the group is retried in the next round.
+				addrFromPKState := addressesState[signer]
+				if addrFromPKState != nil && tx.Nonce() <= addrFromPKState.Nonce() {
+					group.pop()
+					progress = true
+				}
+				continue
+			}
+
+			tx.ApplyStateChanges(addressesState)
+			selected.PushBack(tx)
+			size += tx.Size()
+			weight += txWeight
+			group.pop()
+			progress = true
+		}
+
+		if !progress {
+			// Remaining heads have nonce gaps or are invalid
+			break
+		}
+	}
+
+	return selected
+}
+
+type BlockTemplate struct {
+	Block       *Block
+	Difficulty  []byte
+	Weight      uint64
+	WeightLimit uint64
+	// Sum of the fees of the transactions, part of the coinbase amount
+	Fees uint64
+}
+
+// nextDifficulty returns the difficulty a block on top of the current tip
+// with the given timestamp has to meet
+func (c *Chain) nextDifficulty(timestamp uint32) ([]byte, error) {
+	parentMetadata, err := c.state.GetBlockMetadata(c.lastBlock.HeaderHash())
+	if err != nil {
+		return nil, err
+	}
+
+	measurement, err := c.state.GetMeasurement(timestamp, c.lastBlock.HeaderHash(), parentMetadata)
+	if err != nil {
+		return nil, err
+	}
+
+	difficulty, _ := c.blockDifficulty(measurement, parentMetadata.BlockDifficulty())
+	return difficulty, nil
+}
+
+// CreateBlockTemplate builds an unsealed block on top of the current tip
+// with the transactions selected from the pool
+func (c *Chain) CreateBlockTemplate(minerAddress []byte, timestamp uint64) (*BlockTemplate, error) {
+	if c.config.User.ReadOnly {
+		return nil, ErrReadOnly
+	}
+	if c.config.User.SeedMode {
+		return nil, ErrSeedNode
+	}
+
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	sizeLimit, err := c.state.GetBlockSizeLimit(c.lastBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	_, span := tracing.Start(context.Background(), "block.template")
+	defer span.End()
+
+	txs := c.SelectTransactions(c.txPool.Snapshot().Transactions(), sizeLimit, c.config.Dev.BlockMaxWeight)
+	span.SetAttributes(attribute.Int("block.transactions", txs.Len()))
+
+	var fees uint64
+	for e := txs.Front(); e != nil; e = e.Next() {
+		fees += e.Value.(transactions.TransactionInterface).Fee()
+	}
+
+	block := &Block{block: &generated.Block{}, config: c.config, log: c.log}
+	block = block.CreateBlock(minerAddress,
+		c.lastBlock.BlockNumber()+1,
+		c.lastBlock.HeaderHash(),
+		uint64(c.lastBlock.Timestamp()),
+		*txs,
+		timestamp)
+
+	difficulty, err := c.nextDifficulty(block.Timestamp())
+	if err != nil {
+		return nil, err
+	}
+
+	return &BlockTemplate{
+		Block:       block,
+		Difficulty:  difficulty,
+		Weight:      block.Weight(),
+		WeightLimit: c.config.Dev.BlockMaxWeight,
+		Fees:        fees,
+	}, nil
+}
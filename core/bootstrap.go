@@ -0,0 +1,254 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/cyyber/go-qrl/crypto"
+	"github.com/cyyber/go-qrl/generated"
+	"github.com/cyyber/go-qrl/misc"
+	"github.com/golang/protobuf/proto"
+	"github.com/syndtr/goleveldb/leveldb"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// A bootstrap headers file holds the mainchain headers from block 1 up to a
+// recent checkpoint, signed by one of the publishers in
+// Dev.BootstrapPublishers. It is imported at first start, so the headerhash
+// of every block up to the checkpoint is known before syncing, and blocks
+// can be downloaded and checked against it without header round trips.
+//
+// Layout:
+//   magic | uint64 header count | (uint32 size | BlockHeader)* |
+//   uint32 size | signature | uint32 size | publisher pk
+// The signature is a message signature over everything before it.
+
+var bootstrapMagic = []byte("QRLBHDR1")
+
+const maxBootstrapFieldSize = 64 * 1024
+
+var (
+	ErrInvalidBootstrapFile    = errors.New("invalid bootstrap headers file")
+	ErrUntrustedBootstrapFile  = errors.New("bootstrap headers file not signed by a trusted publisher")
+	ErrBootstrapHeaderMismatch = errors.New("block does not match the bootstrap headers")
+)
+
+func bootstrapKey(blockNumber uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, blockNumber)
+	return append([]byte("bootstrap_"), key...)
+}
+
+func (s *State) PutBootstrapHeaderHash(blockNumber uint64, headerHash []byte, batch *leveldb.Batch) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.db.Put(bootstrapKey(blockNumber), headerHash, batch)
+}
+
+func (s *State) GetBootstrapHeaderHash(blockNumber uint64) ([]byte, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.db.Get(bootstrapKey(blockNumber))
+}
+
+func (s *State) PutBootstrapHeight(height uint64, batch *leveldb.Batch) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	value := make([]byte, 8)
+	binary.BigEndian.PutUint64(value, height)
+	return s.db.Put([]byte("bootstrap_height"), value, batch)
+}
+
+// GetBootstrapHeight returns the block number of the checkpoint, 0 when no
+// bootstrap headers were imported
+func (s *State) GetBootstrapHeight() uint64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	value, err := s.db.Get([]byte("bootstrap_height"))
+	if err != nil || len(value) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(value)
+}
+
+func writeSized(w io.Writer, data []byte) error {
+	size := make([]byte, 4)
+	binary.BigEndian.PutUint32(size, uint32(len(data)))
+	if _, err := w.Write(size); err != nil {
+		return err
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+func readSized(r io.Reader) ([]byte, error) {
+	size := make([]byte, 4)
+	if _, err := io.ReadFull(r, size); err != nil {
+		return nil, err
+	}
+	n := binary.BigEndian.Uint32(size)
+	if n > maxBootstrapFieldSize {
+		return nil, ErrInvalidBootstrapFile
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// WriteBootstrapHeaders exports the mainchain headers up to checkpoint and
+// signs the file with publisher, consuming one of its OTS keys
+func WriteBootstrapHeaders(state *State, checkpoint uint64, publisher *crypto.XMSS, filename string) error {
+	var buf bytes.Buffer
+	buf.Write(bootstrapMagic)
+
+	count := make([]byte, 8)
+	binary.BigEndian.PutUint64(count, checkpoint)
+	buf.Write(count)
+
+	for blockNumber := uint64(1); blockNumber <= checkpoint; blockNumber++ {
+		block, err := state.GetBlockByNumber(blockNumber)
+		if err != nil {
+			return fmt.Errorf("failed to read block #%d: %s", blockNumber, err)
+		}
+		header, err := proto.Marshal(block.PBData().Header)
+		if err != nil {
+			return err
+		}
+		writeSized(&buf, header)
+	}
+
+	signature := publisher.SignMessage(append([]byte(nil), buf.Bytes()...))
+	writeSized(&buf, signature)
+	writeSized(&buf, misc.UCharVectorToBytes(publisher.PK()))
+
+	return ioutil.WriteFile(filename, buf.Bytes(), 0644)
+}
+
+// ReadBootstrapHeaders reads and verifies a bootstrap headers file. The
+// headers are checked to form a single chain starting at genesisHeaderHash.
+func ReadBootstrapHeaders(filename string, genesisHeaderHash []byte, config *Config) ([]*generated.BlockHeader, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var signed bytes.Buffer
+	r := io.TeeReader(bufio.NewReader(f), &signed)
+
+	magic := make([]byte, len(bootstrapMagic))
+	if _, err := io.ReadFull(r, magic); err != nil || !bytes.Equal(magic, bootstrapMagic) {
+		return nil, ErrInvalidBootstrapFile
+	}
+
+	countBytes := make([]byte, 8)
+	if _, err := io.ReadFull(r, countBytes); err != nil {
+		return nil, ErrInvalidBootstrapFile
+	}
+	count := binary.BigEndian.Uint64(countBytes)
+
+	var headers []*generated.BlockHeader
+	prevHeaderHash := genesisHeaderHash
+	for blockNumber := uint64(1); blockNumber <= count; blockNumber++ {
+		data, err := readSized(r)
+		if err != nil {
+			return nil, ErrInvalidBootstrapFile
+		}
+		header := &generated.BlockHeader{}
+		if err := proto.Unmarshal(data, header); err != nil {
+			return nil, ErrInvalidBootstrapFile
+		}
+
+		bh := &BlockHeader{blockHeader: header, config: config}
+		if header.BlockNumber != blockNumber ||
+			!bytes.Equal(header.HashHeaderPrev, prevHeaderHash) ||
+			!bytes.Equal(bh.GenerateHeaderHash(), header.HashHeader) {
+			return nil, fmt.Errorf("%s: bad header #%d", ErrInvalidBootstrapFile, blockNumber)
+		}
+
+		headers = append(headers, header)
+		prevHeaderHash = header.HashHeader
+	}
+
+	message := append([]byte(nil), signed.Bytes()...)
+
+	signature, err := readSized(r)
+	if err != nil {
+		return nil, ErrInvalidBootstrapFile
+	}
+	pk, err := readSized(r)
+	if err != nil {
+		return nil, ErrInvalidBootstrapFile
+	}
+
+	if err := config.Dev.verifyBootstrapSignature(message, signature, pk); err != nil {
+		return nil, err
+	}
+
+	return headers, nil
+}
+
+func (d *DevConfig) verifyBootstrapSignature(message []byte, signature []byte, pk []byte) error {
+	for _, qaddress := range d.BootstrapPublishers {
+		address, err := misc.ParseQaddress(qaddress)
+		if err != nil {
+			continue
+		}
+		if crypto.VerifyMessageSignature(address, message, signature, pk) == nil {
+			return nil
+		}
+	}
+	return ErrUntrustedBootstrapFile
+}
+
+// importBootstrapHeaders stores the headerhashes of a bootstrap headers file
+func (c *Chain) importBootstrapHeaders(filename string, genesisBlock *Block) error {
+	headers, err := ReadBootstrapHeaders(filename, genesisBlock.HeaderHash(), c.config)
+	if err != nil {
+		return err
+	}
+
+	batch := c.state.GetBatch()
+	for _, header := range headers {
+		if err := c.state.PutBootstrapHeaderHash(header.BlockNumber, header.HashHeader, batch); err != nil {
+			return err
+		}
+	}
+	if err := c.state.PutBootstrapHeight(uint64(len(headers)), batch); err != nil {
+		return err
+	}
+	if err := c.state.WriteBatch(batch); err != nil {
+		return err
+	}
+
+	c.log.Info("Imported bootstrap headers", "checkpoint", len(headers))
+	return nil
+}
+
+// matchesBootstrapHeaders returns false for a block below the bootstrap
+// checkpoint whose headerhash differs from the imported one
+func (c *Chain) matchesBootstrapHeaders(block *Block) bool {
+	if block.BlockNumber() > c.state.GetBootstrapHeight() {
+		return true
+	}
+	headerHash, err := c.state.GetBootstrapHeaderHash(block.BlockNumber())
+	if err != nil {
+		return true
+	}
+	return bytes.Equal(headerHash, block.HeaderHash())
+}
+
+// BootstrapHeight is the height up to which headers are already known
+func (c *Chain) BootstrapHeight() uint64 {
+	return c.state.GetBootstrapHeight()
+}
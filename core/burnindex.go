@@ -0,0 +1,251 @@
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"github.com/cyyber/go-qrl/core/transactions"
+	"github.com/cyyber/go-qrl/misc"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// Quanta sent to the canonical burn addresses (see misc.IsBurnAddress) can
+// never be spent, so they are summed up and subtracted from the circulating
+// supply. The total and the burn transactions are updated with the
+// transaction metadata and rebuilt with the transactions index.
+//
+// Keys:
+//   burn_total -> uint64 amount
+//   burn_tx_ | uint64 block number | uint32 tx index -> burn
+// Burn layout: uint64 timestamp | uint64 amount | sized txhash | sized address from
+
+var (
+	burnTotalKey = []byte("burn_total")
+	burnTxPrefix = []byte("burn_tx_")
+)
+
+var ErrInvalidBurn = errors.New("invalid burn record")
+
+type Burn struct {
+	TxHash   []byte
+	AddrFrom []byte
+	// Sum of the amounts sent to burn addresses by the transaction
+	Amount uint64
+
+	BlockNumber uint64
+	Timestamp   uint64
+
+	position []byte
+}
+
+func burnTxKey(position []byte) []byte {
+	return append(append([]byte{}, burnTxPrefix...), position...)
+}
+
+func (b *Burn) encode() []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, b.Timestamp)
+	binary.Write(&buf, binary.BigEndian, b.Amount)
+	writeSized(&buf, b.TxHash)
+	writeSized(&buf, b.AddrFrom)
+	return buf.Bytes()
+}
+
+func decodeBurn(position []byte, data []byte) (*Burn, error) {
+	if len(position) != 12 {
+		return nil, ErrInvalidBurn
+	}
+	b := &Burn{
+		BlockNumber: binary.BigEndian.Uint64(position),
+		position:    append([]byte{}, position...),
+	}
+
+	r := bytes.NewReader(data)
+	if err := binary.Read(r, binary.BigEndian, &b.Timestamp); err != nil {
+		return nil, ErrInvalidBurn
+	}
+	if err := binary.Read(r, binary.BigEndian, &b.Amount); err != nil {
+		return nil, ErrInvalidBurn
+	}
+	var err error
+	if b.TxHash, err = readUndoField(r); err != nil {
+		return nil, ErrInvalidBurn
+	}
+	if b.AddrFrom, err = readUndoField(r); err != nil {
+		return nil, ErrInvalidBurn
+	}
+	if r.Len() != 0 {
+		return nil, ErrInvalidBurn
+	}
+	return b, nil
+}
+
+// burnedAmount returns the Quanta tx sends to burn addresses
+func burnedAmount(tx transactions.TransactionInterface) uint64 {
+	var amount uint64
+	switch t := tx.(type) {
+	case *transactions.CoinBase:
+		if misc.IsBurnAddress(t.AddrTo()) {
+			amount = t.Amount()
+		}
+	case *transactions.TransferTransaction:
+		for i, addrTo := range t.AddrsTo() {
+			if misc.IsBurnAddress(addrTo) {
+				amount += t.Amounts()[i]
+			}
+		}
+	}
+	return amount
+}
+
+// burnIndexUpdate accumulates the burned total of a block before it's
+// written with the block batch
+type burnIndexUpdate struct {
+	s *State
+	// The total starts from zero instead of the stored one, for rebuilds
+	fresh bool
+
+	total   uint64
+	loaded  bool
+	changed bool
+}
+
+func newBurnIndexUpdate(s *State, fresh bool) *burnIndexUpdate {
+	return &burnIndexUpdate{
+		s:      s,
+		fresh:  fresh,
+		loaded: fresh,
+	}
+}
+
+func (u *burnIndexUpdate) load() {
+	if u.loaded {
+		return
+	}
+	u.loaded = true
+	if value, err := u.s.db.Get(burnTotalKey); err == nil && len(value) == 8 {
+		u.total = binary.BigEndian.Uint64(value)
+	}
+}
+
+// applyTx indexes tx, the txIndex-th transaction of the block blockNumber
+func (u *burnIndexUpdate) applyTx(tx transactions.TransactionInterface, blockNumber uint64, txIndex int, timestamp uint64, batch *leveldb.Batch) error {
+	amount := burnedAmount(tx)
+	if amount == 0 {
+		return nil
+	}
+	u.load()
+	u.total += amount
+	u.changed = true
+
+	burn := &Burn{
+		TxHash:    tx.Txhash(),
+		AddrFrom:  tx.AddrFrom(),
+		Amount:    amount,
+		Timestamp: timestamp,
+	}
+	return u.s.db.Put(burnTxKey(tokenTransferPosition(blockNumber, uint32(txIndex))), burn.encode(), batch)
+}
+
+// revertTx removes tx from the index, undoing applyTx
+func (u *burnIndexUpdate) revertTx(tx transactions.TransactionInterface, blockNumber uint64, txIndex int, batch *leveldb.Batch) {
+	amount := burnedAmount(tx)
+	if amount == 0 {
+		return
+	}
+	u.load()
+	if amount > u.total {
+		amount = u.total
+	}
+	u.total -= amount
+	u.changed = true
+	u.s.deleteKey(burnTxKey(tokenTransferPosition(blockNumber, uint32(txIndex))), batch)
+}
+
+func (u *burnIndexUpdate) write(batch *leveldb.Batch) error {
+	if !u.changed {
+		return nil
+	}
+	value := make([]byte, 8)
+	binary.BigEndian.PutUint64(value, u.total)
+	return u.s.db.Put(burnTotalKey, value, batch)
+}
+
+// clearBurnIndex removes the burned total and burn transactions, before
+// they are rebuilt
+func (s *State) clearBurnIndex() error {
+	batch := s.GetBatch()
+	batch.Delete(burnTotalKey)
+	err := s.db.IteratePrefix(burnTxPrefix, nil, func(key []byte, value []byte) bool {
+		batch.Delete(append([]byte{}, key...))
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	return s.WriteBatch(batch)
+}
+
+func (s *State) GetBurnedTotal() uint64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	value, err := s.db.Get(burnTotalKey)
+	if err != nil || len(value) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(value)
+}
+
+// GetBurns returns up to limit burn transactions after cursor, oldest
+// first, and the cursor of the next page, nil on the last page
+func (s *State) GetBurns(cursor []byte, limit int) ([]*Burn, []byte, error) {
+	var burns []*Burn
+	more := false
+	var decodeErr error
+	err := s.db.IteratePrefix(burnTxPrefix, cursorStart(burnTxPrefix, cursor), func(key []byte, value []byte) bool {
+		if len(burns) == limit {
+			more = true
+			return false
+		}
+		burn, err := decodeBurn(key[len(burnTxPrefix):], value)
+		if err != nil {
+			decodeErr = err
+			return false
+		}
+		burns = append(burns, burn)
+		return true
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if decodeErr != nil {
+		return nil, nil, decodeErr
+	}
+	if more {
+		return burns, burns[len(burns)-1].position, nil
+	}
+	return burns, nil, nil
+}
+
+// GetCirculatingSupply returns the emitted coins minus the burned ones,
+// in Shor
+func (c *Chain) GetCirculatingSupply() (uint64, error) {
+	emitted, err := c.state.GetTotalCoinSupply()
+	if err != nil {
+		return 0, err
+	}
+	burned := c.state.GetBurnedTotal()
+	if burned > emitted {
+		return 0, nil
+	}
+	return emitted - burned, nil
+}
+
+func (c *Chain) GetBurnedTotal() uint64 {
+	return c.state.GetBurnedTotal()
+}
+
+func (c *Chain) GetBurns(cursor []byte, limit int) ([]*Burn, []byte, error) {
+	return c.state.GetBurns(cursor, limit)
+}
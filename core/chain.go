@@ -1,24 +1,46 @@
 package core
 
 import (
-	"github.com/cyyber/go-qrl/core/pool"
-	"github.com/cyyber/go-qrl/generated"
-	"github.com/theQRL/qryptonight/goqryptonight"
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
 	"github.com/cyyber/go-qrl/core/metadata"
-	"github.com/cyyber/go-qrl/misc"
-	"github.com/cyyber/go-qrl/genesis"
+	"github.com/cyyber/go-qrl/core/pool"
 	"github.com/cyyber/go-qrl/core/transactions"
-	"errors"
+	"github.com/cyyber/go-qrl/events"
+	"github.com/cyyber/go-qrl/generated"
 	"github.com/cyyber/go-qrl/log"
-	"reflect"
-	"math/big"
+	"github.com/cyyber/go-qrl/misc"
+	"github.com/cyyber/go-qrl/pow"
+	"github.com/cyyber/go-qrl/tracing"
 	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/theQRL/qryptonight/goqryptonight"
+	"go.opentelemetry.io/otel/attribute"
+	"os"
+	"path"
+	"strconv"
 	"sync"
-	"github.com/cyyber/go-qrl/pow"
+	"time"
 )
 
+// Chain is safe for concurrent use by the P2P handlers, the miner and the
+// API. The locking model is:
+//   - lock guards lastBlock, currentDifficulty, triggerMiner, pendingReorg
+//     and every read-modify-write sequence on the State. Methods changing
+//     the mainchain (processBlock, ConfirmReorg) take the write lock, read only
+//     methods (GetBlock, GetLastBlock, Height, template creation and
+//     transaction submission) take the read lock. AddChain, Rollback and
+//     RemoveBlockFromMainchain are only used during fork recovery, with the
+//     write lock already held.
+//   - The TransactionPool has its own lock. It is always acquired after
+//     the Chain lock and the pool never calls back into the Chain, so the
+//     two locks cannot deadlock.
+//   - Exported methods take the lock, unexported ones expect the caller to
+//     hold it. Exported methods must never be called while holding it, as
+//     the lock is not reentrant.
 type Chain struct {
-	lock sync.Mutex
+	lock sync.RWMutex
 
 	log log.Logger
 	config *Config
@@ -32,13 +54,141 @@ type Chain struct {
 	lastBlock *Block
 	currentDifficulty []byte
 
+	stats *ChainStats
+
+	// Cumulative difficulty index, shared with the ChainManager
+	difficulties *difficultyIndex
+
+	eventBus *events.Bus
+
+	clock misc.Clock
+
+	wal *WAL
+
+	// Recently read blocks, decoded
+	blocks *blockCache
+	// Context-free checks passed by recent blocks
+	validated *validationCache
+
+	// Headerhash of a block whose branch requires a reorg deeper than
+	// MaxAutoReorgDepth, waiting for operator confirmation
+	pendingReorg []byte
+
+	// Leaves of the block tree, for GetChainTips
+	tips *chainTips
+
+	stateProbe stateProbe
+
+	// Reports of the last slow blocks
+	slowBlocks slowBlocks
+}
+
+func CreateChain(log log.Logger, state *State, txPool *pool.TransactionPool, eventBus *events.Bus, config *Config) *Chain {
+	return &Chain{
+		log:          log,
+		state:        state,
+		txPool:       txPool,
+		config:       config,
+		stats:        CreateChainStats(int(config.Dev.BlockTimeSeriesSize)),
+		difficulties: newDifficultyIndex(state),
+		eventBus:     eventBus,
+		clock:        misc.GetNTP(),
+		blocks:       newBlockCache(int(config.User.BlockCacheSize)),
+		validated:    newValidationCache(int(config.User.ValidationCacheSize)),
+		tips:         newChainTips(),
+	}
+}
+
+// SetClock replaces the NTP clock, it must be called before the Chain is used
+func (c *Chain) SetClock(clock misc.Clock) {
+	c.clock = clock
+}
+
+func (c *Chain) Clock() misc.Clock {
+	return c.clock
+}
+
+func (c *Chain) Stats() *ChainStats {
+	return c.stats
+}
+
+func (c *Chain) PendingTransactions() []*pool.TransactionInfo {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.txPool.TransactionInfos()
+}
+
+// RecentRejections returns up to limit transactions recently refused by the
+// pool, the most recent first
+func (c *Chain) RecentRejections(limit int) []*pool.Rejection {
+	return c.txPool.RecentRejections(limit)
+}
+
+func (c *Chain) MempoolStats() *pool.Stats {
+	return c.txPool.Stats()
+}
+
+// GetAddressState returns the state of address at the current tip, or the
+// default state if the address has never been used
+func (c *Chain) GetAddressState(address []byte) (*AddressState, error) {
+	if err := misc.ValidateAddress(address); err != nil {
+		return nil, err
+	}
+
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	addrState, err := c.state.GetAddressState(address)
+	if err != nil {
+		return GetDefaultAddressState(address), nil
+	}
+	return addrState, nil
+}
+
+func (c *Chain) GetTotalCoinSupply() (uint64, error) {
+	return c.state.GetTotalCoinSupply()
+}
+
+// loadStats fills the rolling statistics window with the most recent
+// mainchain blocks on startup
+func (c *Chain) loadStats() {
+	height := c.lastBlock.BlockNumber()
+	start := uint64(0)
+	if height > uint64(c.config.Dev.BlockTimeSeriesSize) {
+		start = height - uint64(c.config.Dev.BlockTimeSeriesSize)
+	}
+
+	for i := start; i <= height; i++ {
+		block, err := c.state.GetBlockByNumber(i)
+		if err != nil {
+			c.log.Warn("Failed to load block for stats", "block number", i, "error", err)
+			return
+		}
+		c.pushStats(block)
+	}
+}
+
+func (c *Chain) pushStats(block *Block) {
+	blockMetadata, err := c.state.GetBlockMetadata(block.HeaderHash())
+	if err != nil {
+		c.log.Warn("Failed to get block metadata for stats", "error", err)
+		return
+	}
+	c.stats.Push(block, blockMetadata.BlockDifficulty())
 }
 
 func (c *Chain) Height() uint64 {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
 	return c.lastBlock.BlockNumber()
 }
 
 func (c *Chain) GetLastBlock() *Block {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
 	return c.lastBlock
 }
 
@@ -52,16 +202,24 @@ func (c *Chain) Load(genesisBlock *Block) error {
 	// Detect if we are forked from genesis block and if so initiate recovery.
 	h, err := c.state.GetChainHeight()
 
+	var pendingWAL []*WALRecord
+	if !c.state.InMemory() && !c.config.User.ReadOnly {
+		var walErr error
+		c.wal, pendingWAL, walErr = OpenWAL(path.Join(c.config.DataDir(), c.config.Dev.ChainWALFilename))
+		if walErr != nil {
+			return walErr
+		}
+	}
+
 	if err != nil {
 		c.state.PutBlock(genesisBlock, nil)
 		blockNumberMapping := &generated.BlockNumberMapping{Headerhash:genesisBlock.HeaderHash(),
 		PrevHeaderhash:genesisBlock.PrevHeaderHash()}
 
 		c.state.PutBlockNumberMapping(genesisBlock.BlockNumber(), blockNumberMapping, nil)
-		parentDifficulty := goqryptonight.StringToUInt256(string(c.config.Dev.Genesis.GenesisDifficulty))
+		parentDifficulty := goqryptonight.StringToUInt256(strconv.FormatUint(c.config.Dev.Genesis.GenesisDifficulty, 10))
 
-		dt := pow.DifficultyTracker{}
-		currentDifficulty, _ := dt.Get(uint64(c.config.Dev.MiningSetpointBlocktime),
+		currentDifficulty, _ := c.blockDifficulty(uint64(c.config.Dev.MiningSetpointBlocktime),
 			misc.UCharVectorToBytes(parentDifficulty))
 
 		blockMetaData := metadata.CreateBlockMetadata(currentDifficulty, currentDifficulty, nil)
@@ -69,14 +227,15 @@ func (c *Chain) Load(genesisBlock *Block) error {
 		c.state.PutBlockMetaData(genesisBlock.HeaderHash(), blockMetaData, nil)
 
 		addressesState := make(map[string]*AddressState)
-		gen := &genesis.Genesis{}
-		for _, genesisBalance := range gen.GenesisBalance() {
+		// The development accounts are part of the genesis balances, see
+		// AddDevAccounts
+		for _, genesisBalance := range genesisBlock.PBData().GenesisBalance {
 			addrState := GetDefaultAddressState(genesisBalance.Address)
 			addressesState[string(addrState.Address())] = addrState
 			addrState.SetBalance(genesisBalance.Balance)
 		}
 
-		txs := gen.Transactions()
+		txs := genesisBlock.Transactions()
 		for i := 1; i < len(txs); i++ {
 			for _, addr := range txs[i].GetTransfer().AddrsTo {
 				addressesState[string(addr)] = GetDefaultAddressState(addr)
@@ -84,10 +243,11 @@ func (c *Chain) Load(genesisBlock *Block) error {
 		}
 
 		coinBase := &transactions.CoinBase{}
-		coinBase.SetPBData(txs[0])
+		coinBase.FromPBdata(txs[0])
+		coinBase.SetConfig(c.config, c.log)
 		addressesState[string(coinBase.AddrTo())] = GetDefaultAddressState(coinBase.AddrTo())
 
-		if !coinBase.ValidateExtended(gen.BlockNumber()) {
+		if !coinBase.ValidateExtended(genesisBlock.BlockNumber()) {
 			return errors.New("coinbase validation failed")
 		}
 
@@ -102,7 +262,32 @@ func (c *Chain) Load(genesisBlock *Block) error {
 		c.state.PutAddressesState(addressesState, nil)
 		c.state.UpdateTxMetadata(genesisBlock, nil)
 		c.state.PutChainHeight(0, nil)
+		c.state.PutUndoHeight(1)
+		c.lastBlock = genesisBlock
+		c.currentDifficulty = currentDifficulty
+
+		bootstrapFile := path.Join(c.config.User.QrlDir, c.config.User.BootstrapHeadersFilename)
+		if _, err := os.Stat(bootstrapFile); err == nil {
+			if err := c.importBootstrapHeaders(bootstrapFile, genesisBlock); err != nil {
+				c.log.Warn("Failed to import bootstrap headers", "file", bootstrapFile, "error", err)
+			}
+		}
 	} else {
+		if _, ok := c.state.GetUndoHeight(); !ok && !c.config.User.ReadOnly {
+			c.state.PutUndoHeight(h + 1)
+		}
+		if err := c.verifyTip(h); err != nil {
+			// A pending WAL record accounts for the inconsistency, the
+			// interrupted mutation is rolled back without AutoRepair
+			if (!c.config.User.AutoRepair && len(pendingWAL) == 0) || c.config.User.ReadOnly {
+				return err
+			}
+			c.log.Error("Chain database inconsistent, rolling back", "height", h)
+			if h, err = c.repair(h); err != nil {
+				return err
+			}
+		}
+
 		c.lastBlock, err = c.state.GetBlockByNumber(h)
 		var blockMetadata *metadata.BlockMetaData
 		blockMetadata, err := c.state.GetBlockMetadata(c.lastBlock.HeaderHash())
@@ -112,87 +297,192 @@ func (c *Chain) Load(genesisBlock *Block) error {
 		}
 
 		c.currentDifficulty = blockMetadata.BlockDifficulty()
+		c.loadStats()
 		forkState, err := c.state.GetForkState()
-		if err == nil {
+		resumedFork := err == nil
+		if resumedFork {
 			block, err := c.state.GetBlock(forkState.InitiatorHeaderhash)
 			if err != nil {
 				return err
 			}
 			c.forkRecovery(block, forkState)
 		}
+		if err := c.recoverWAL(pendingWAL, resumedFork); err != nil {
+			return err
+		}
 	}
-	return nil
+	return c.wal.Reset()
 }
 
-func (c *Chain) addBlock(block *Block, batch *leveldb.Batch) (bool, bool) {
+// addBlock returns whether block was added and whether it triggered fork
+// recovery, err is only set when writing the block to the database failed
+func (c *Chain) addBlock(ctx context.Context, block *Block, receipt *blockReceipt, batch *leveldb.Batch) (bool, bool, error) {
 	blockSizeLimit, err := c.state.GetBlockSizeLimit(block)
 
 	if err == nil && block.Size() > blockSizeLimit {
 		c.log.Warn("Block Size greater than threshold limit %s > %s", block.Size(), blockSizeLimit)
-		return false, false
+		return false, false, nil
 	}
 
-	if reflect.DeepEqual(c.lastBlock.HeaderHash(), block.PrevHeaderHash()) {
-		if !c.applyBlock(block, batch) {
-			return false, false
+	if bytes.Equal(c.lastBlock.HeaderHash(), block.PrevHeaderHash()) {
+		if !c.applyBlock(ctx, block, batch) {
+			return false, false, nil
 		}
 	}
 
 	err = c.state.PutBlock(block, batch)
 
 	if err != nil {
-		return false, false
+		return false, false, nil
+	}
+	c.putBlockReceipt(block, receipt, batch)
+	if err := c.putBlockMetadata(block, batch); err != nil {
+		c.log.Warn("Failed to compute block difficulty", "error", err)
+		return false, false, nil
 	}
+	c.trackTip(block)
 
-	lastBlockMetadata, err := c.state.GetBlockMetadata(c.lastBlock.HeaderHash())
-	newBlockMetadata, err := c.state.GetBlockMetadata(block.HeaderHash())
+	isBetterTip, err := c.difficulties.isBetterTip(block.HeaderHash(), c.lastBlock.HeaderHash())
+	if err != nil {
+		c.log.Warn("Failed to compare cumulative difficulty", "error", err)
+		return false, false, nil
+	}
 
-	lastBlockDifficulty := big.NewInt(0)
-	lastBlockDifficulty.SetString(goqryptonight.UInt256ToString(misc.BytesToUCharVector(lastBlockMetadata.TotalDifficulty())), 10)
-	newBlockDifficulty := big.NewInt(0)
-	newBlockDifficulty.SetString(goqryptonight.UInt256ToString(misc.BytesToUCharVector(newBlockMetadata.TotalDifficulty())), 10)
+	// A block of the local miners is counted orphaned until it becomes the
+	// tip, a reorg to its branch moves it to accepted
+	mined := receipt != nil && receipt.source != BlockFromSync
+	if mined && !(isBetterTip && bytes.Equal(c.lastBlock.HeaderHash(), block.PrevHeaderHash())) {
+		c.recordMinedBlock(block, false, batch)
+	}
 
-	if newBlockDifficulty.Cmp(lastBlockDifficulty) == 1 {
-		if !reflect.DeepEqual(c.lastBlock.HeaderHash(), block.PrevHeaderHash()) {
+	if isBetterTip {
+		if !bytes.Equal(c.lastBlock.HeaderHash(), block.PrevHeaderHash()) {
+			if !c.isReorgAllowed(block) {
+				c.pendingReorg = block.HeaderHash()
+				return true, false, nil
+			}
 			forkState := &generated.ForkState{InitiatorHeaderhash:block.HeaderHash()}
 			err = c.state.PutForkState(forkState, batch)
 			if err != nil {
 				c.log.Info("PutForkState Error %s", err.Error())
-				return false, true
+				return false, true, nil
+			}
+			if err := c.state.WriteChainBatch(batch); err != nil {
+				return false, true, err
 			}
-			c.state.WriteBatch(batch)
-			return c.forkRecovery(block, forkState), true
+			_, reorgSpan := tracing.Start(ctx, "block.reorg")
+			defer reorgSpan.End()
+			return c.forkRecovery(block, forkState), true, nil
 		}
+		// Removes the included transactions from the pool and publishes the
+		// new tip, which triggers the broadcast to peers
+		_, updateSpan := tracing.Start(ctx, "block.update_tip")
 		c.updateChainState(block, batch)
+		updateSpan.End()
+		if mined {
+			c.recordMinedBlock(block, true, batch)
+		}
 		c.txPool.CheckStale(block.BlockNumber())
 		c.triggerMiner = true
 	}
-	return true, false
+	return true, false, nil
+}
+
+// putBlockMetadata computes the difficulty of block from its parent and the
+// cumulative difficulty of the chain ending at it, and adds its metadata and
+// the child link of its parent to batch. The cumulative difficulty is
+// recorded in the difficulty index to compare the block with the tip before
+// the batch is written.
+func (c *Chain) putBlockMetadata(block *Block, batch *leveldb.Batch) error {
+	parentMetadata, err := c.state.GetBlockMetadata(block.PrevHeaderHash())
+	if err != nil {
+		return err
+	}
+	measurement, err := c.state.GetMeasurement(block.Timestamp(), block.PrevHeaderHash(), parentMetadata)
+	if err != nil {
+		return err
+	}
+	blockDifficulty, _ := c.blockDifficulty(measurement, parentMetadata.BlockDifficulty())
+
+	parentTotal, err := c.difficulties.get(block.PrevHeaderHash())
+	if err != nil {
+		return err
+	}
+	difficulty, ok := new(big.Int).SetString(goqryptonight.UInt256ToString(misc.BytesToUCharVector(blockDifficulty)), 10)
+	if !ok {
+		return errors.New("invalid block difficulty")
+	}
+	total := new(big.Int).Add(parentTotal, difficulty)
+	totalDifficulty := misc.UCharVectorToBytes(goqryptonight.StringToUInt256(total.String()))
+
+	blockMetadata := metadata.CreateBlockMetadata(blockDifficulty, totalDifficulty, nil)
+	blockMetadata.UpdateLastHeaderHashes(parentMetadata.LastNHeaderHashes(), block.PrevHeaderHash(), c.config.Dev.NMeasurement)
+	if err := c.state.PutBlockMetaData(block.HeaderHash(), blockMetadata, batch); err != nil {
+		return err
+	}
+	parentMetadata.AddChildHeaderHash(block.HeaderHash())
+	if err := c.state.PutBlockMetaData(block.PrevHeaderHash(), parentMetadata, batch); err != nil {
+		return err
+	}
+
+	c.difficulties.add(block.HeaderHash(), total)
+	return nil
 }
 
-func (c *Chain) AddBlock(block *Block) bool {
+// processBlock is only called by ChainManager.AddBlock, receipt is stored
+// with the block
+func (c *Chain) processBlock(ctx context.Context, block *Block, receipt *blockReceipt) bool {
+	_, lockSpan := tracing.Start(ctx, "block.wait_lock")
 	c.lock.Lock()
+	lockSpan.End()
 	defer c.lock.Unlock()
 
-	if block.BlockNumber() < c.Height() - c.config.Dev.ReorgLimit {
+	if c.config.User.ReadOnly {
+		return false
+	}
+
+	if block.BlockNumber() < c.lastBlock.BlockNumber()-c.config.Dev.ReorgLimit {
 		c.log.Debug("Skipping block #%s as beyond re-org limit", block.BlockNumber())
 		return false
 	}
 
-	_, err := c.state.GetBlock(block.HeaderHash())
+	_, err := c.getBlock(block.HeaderHash())
 
 	if err == nil {
 		c.log.Debug("Skipping block #%s is duplicate block", block.BlockNumber())
 		return false
 	}
 
+	if !c.matchesBootstrapHeaders(block) {
+		c.log.Warn("Rejecting block", "number", block.BlockNumber(), "error", ErrBootstrapHeaderMismatch)
+		return false
+	}
+
+	walSeq, err := c.wal.Begin(WALAddBlock, block.BlockNumber(), block.HeaderHash(), c.lastBlock.HeaderHash())
+	if err != nil {
+		c.log.Error("Failed to write chain WAL", "error", err)
+		return false
+	}
+
 	batch := c.state.GetBatch()
-	blockFlag, forkFlag := c.addBlock(block, batch)
+	blockFlag, forkFlag, err := c.addBlock(ctx, block, receipt, batch)
+	if err == nil && blockFlag && !forkFlag {
+		_, commitSpan := tracing.Start(ctx, "block.commit")
+		err = c.state.WriteChainBatch(batch)
+		commitSpan.End()
+	}
+	if err != nil {
+		// The WAL record stays pending, the chain is repaired from it at
+		// the next start
+		c.log.Error("Failed to write block", "number", block.BlockNumber(), "error", err)
+		return false
+	}
+	if err := c.wal.Commit(walSeq); err != nil {
+		c.log.Error("Failed to commit chain WAL", "error", err)
+	}
+
 	if blockFlag {
-		if !forkFlag {
-			c.state.WriteBatch(batch)
-		}
-		c.log.Info("Added Block #%s %s", block.BlockNumber(), string(block.HeaderHash()))
+		c.log.Info("Added Block #%s %s", block.BlockNumber(), misc.Bin2HStr(block.HeaderHash()))
 		return true
 	}
 
@@ -200,28 +490,196 @@ func (c *Chain) AddBlock(block *Block) bool {
 
 }
 
-func (c *Chain) applyBlock(block *Block, batch *leveldb.Batch) bool {
+func (c *Chain) applyBlock(ctx context.Context, block *Block, batch *leveldb.Batch) bool {
+	_, span := tracing.Start(ctx, "block.apply")
+	defer span.End()
+
+	start := time.Now()
+	profile := &BlockProfile{
+		BlockNumber:  block.BlockNumber(),
+		HeaderHash:   block.HeaderHash(),
+		Transactions: uint64(len(block.Transactions())),
+	}
+
+	overlay := NewStateOverlay(c.state)
 	addressesState := block.PrepareAddressesList()
-	c.state.GetAddressesState(addressesState)
-	if !block.ApplyStateChanges(addressesState) {
+	overlay.Prepare(addressesState)
+	undo, undoReads := c.newUndoRecord(block, addressesState)
+	verified := c.validated.get(block.HeaderHash()).signatures
+	if !block.applyStateChanges(addressesState, !verified, profile) {
 		return false
 	}
+	c.validated.update(block.HeaderHash(), func(validated *validatedBlock) {
+		validated.signatures = true
+		if !verified {
+			validated.checksMissed++
+		}
+	})
+	profile.CacheMisses = c.validated.checksMissed(block.HeaderHash())
 
-	err := c.state.PutAddressesState(addressesState, batch)
+	err := overlay.Flush(batch)
 	if err != nil {
 		c.log.Warn("Failed to apply Block %s", err.Error())
 		return false
 	}
 
+	undo.PostDigest = addressesDigest(addressesState)
+	undo.StateDigest = c.canonicalStateDigest(addressesState)
+	if err := c.state.PutUndoRecord(undo, batch); err != nil {
+		c.log.Warn("Failed to write undo record", "error", err)
+		return false
+	}
+	c.pruneUndoRecord(block.BlockNumber(), batch)
+
+	profile.StateReads, profile.StateWrites = overlay.Stats()
+	profile.StateReads += undoReads
+	profile.ApplyTime = time.Since(start)
+	c.reportBlockProfile(profile)
+
 	return true
 }
 
+// SubmitTransaction adds tx to the pool, or to the nonce-gap queue when its
+// nonce is ahead of the next nonce expected for its signing address
+func (c *Chain) SubmitTransaction(tx transactions.TransactionInterface) error {
+	return c.SubmitTransactionFromPeer(tx, "")
+}
+
+// SubmitTransactionFromPeer is SubmitTransaction for a transaction received
+// from the peer at address peer, recorded in the transaction receipt
+func (c *Chain) SubmitTransactionFromPeer(tx transactions.TransactionInterface, peer string) error {
+	if c.config.User.ReadOnly {
+		return ErrReadOnly
+	}
+	if c.config.User.SeedMode {
+		return ErrSeedNode
+	}
+	receivedAt := time.Now()
+	_, span := tracing.Start(context.Background(), "tx.admit",
+		attribute.String("tx.hash", misc.Bin2HStr(tx.Txhash())))
+
+	if err := misc.ValidateAddress(tx.AddrFrom()); err != nil {
+		c.txPool.RecordRejection(tx, err)
+		tracing.End(span, err)
+		return err
+	}
+
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	err := c.txPool.AddWithNonce(tx, c.stateNonce(tx.AddrFromPK()), c.lastBlock.BlockNumber(), 0)
+	if err != nil {
+		c.txPool.RecordRejection(tx, err)
+	} else {
+		c.txPool.SetReceipt(tx.Txhash(), newReceipt(receivedAt, receiptSource(peer), peer))
+	}
+	tracing.End(span, err)
+	return err
+}
+
+// SubmitTransactions admits a batch of transactions signed by the same key
+// with consecutive nonces as a unit, see TransactionPool.AddBatch. The error
+// of each transaction is returned, nil when admitted.
+func (c *Chain) SubmitTransactions(txs []transactions.TransactionInterface) []error {
+	errs := make([]error, len(txs))
+	if c.config.User.ReadOnly {
+		for i := range errs {
+			errs[i] = ErrReadOnly
+		}
+		return errs
+	}
+	if c.config.User.SeedMode {
+		for i := range errs {
+			errs[i] = ErrSeedNode
+		}
+		return errs
+	}
+
+	receivedAt := time.Now()
+	for i, tx := range txs {
+		if err := misc.ValidateAddress(tx.AddrFrom()); err != nil {
+			for j := range errs {
+				errs[j] = pool.ErrBatchAborted
+			}
+			errs[i] = err
+			c.txPool.RecordRejection(tx, err)
+			return errs
+		}
+	}
+	if len(txs) == 0 {
+		return errs
+	}
+
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	errs = c.txPool.AddBatch(txs, c.stateNonce(txs[0].AddrFromPK()), c.lastBlock.BlockNumber())
+	for i, err := range errs {
+		if err == nil {
+			c.txPool.SetReceipt(txs[i].Txhash(), newReceipt(receivedAt, "api", ""))
+		} else if err != pool.ErrBatchAborted {
+			c.txPool.RecordRejection(txs[i], err)
+		}
+	}
+	return errs
+}
+
+// NextNonce returns the nonce of the next transaction signed by address,
+// pending transactions included
+func (c *Chain) NextNonce(address []byte) uint64 {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.txPool.NextNonce(address, c.stateNonce(address))
+}
+
+// NextUnusedOTSIndex returns the lowest OTS index of address used neither
+// on chain nor by a pending transaction
+func (c *Chain) NextUnusedOTSIndex(address []byte) (uint64, error) {
+	addrState, err := c.GetAddressState(address)
+	if err != nil {
+		return 0, err
+	}
+
+	pending := make(map[uint64]bool)
+	for _, ti := range c.txPool.TransactionInfos() {
+		if bytes.Equal(ti.Transaction().AddrFromPK(), address) {
+			pending[uint64(ti.Transaction().OtsKey())] = true
+		}
+	}
+
+	index := addrState.NextUnusedOTSIndex(0)
+	for pending[index] {
+		index = addrState.NextUnusedOTSIndex(index + 1)
+	}
+	return index, nil
+}
+
+func (c *Chain) stateNonce(address []byte) uint64 {
+	addrState, err := c.state.GetAddressState(address)
+	if err != nil {
+		return uint64(c.config.Dev.DefaultNonce)
+	}
+	return addrState.Nonce()
+}
+
 func (c *Chain) updateChainState(block *Block, batch *leveldb.Batch) {
 	c.lastBlock = block
+	c.blocks.addMainchain(block)
 	c.updateBlockNumberMapping(block, batch)
+	c.putTxReceipts(block, batch)
+	c.recordMinedBlockMoved(block, true, batch)
 	c.txPool.RemoveTxInBlock(block)
+	c.txPool.RemoveExpired(block.BlockNumber())
+	c.txPool.PromoteQueued(c.stateNonce, block.BlockNumber())
 	c.state.PutChainHeight(block.BlockNumber(), batch)
 	c.state.UpdateTxMetadata(block, batch)
+	c.pushStats(block)
+	c.eventBus.Publish(events.TopicNewBlock, &events.NewBlockEvent{
+		HeaderHash:  block.HeaderHash(),
+		BlockNumber: block.BlockNumber(),
+		Timestamp:   uint64(block.Timestamp()),
+	})
 }
 
 func (c *Chain) updateBlockNumberMapping(block *Block, batch *leveldb.Batch) {
@@ -232,22 +690,27 @@ func (c *Chain) updateBlockNumberMapping(block *Block, batch *leveldb.Batch) {
 func (c *Chain) RemoveBlockFromMainchain(block *Block, blockNumber uint64, batch *leveldb.Batch) {
 	addressesState := block.PrepareAddressesList()
 	c.state.GetAddressesState(addressesState)
-	for i := len(block.Transactions()) - 1; i <= 0; i-- {
+	for i := len(block.Transactions()) - 1; i >= 0; i-- {
 		tx := transactions.ProtoToTransaction(block.Transactions()[i])
 		tx.RevertStateChanges(addressesState, c.state)
 	}
 
-	c.txPool.AddTxFromBlock(block, blockNumber)
 	c.state.PutChainHeight(block.BlockNumber() - 1, batch)
 	c.state.RollbackTxMetadata(block, batch)
-	c.state.RemoveBlockNumberMapping(block.BlockNumber())
+	c.state.RemoveBlockNumberMapping(block.BlockNumber(), batch)
+	c.blocks.removeMainchain(block.BlockNumber())
+	c.state.RemoveUndoRecord(block.BlockNumber(), batch)
 	c.state.PutAddressesState(addressesState, batch)
+	c.stats.Pop()
+	c.recordMinedBlockMoved(block, false, batch)
 }
 
-func (c *Chain) Rollback(forkedHeaderHash []byte, forkState *generated.ForkState) [][]byte {
+// Rollback removes the mainchain blocks down to forkedHeaderHash, each
+// removal is recorded in the WAL
+func (c *Chain) Rollback(forkedHeaderHash []byte, forkState *generated.ForkState) ([][]byte, error) {
 	var hashPath [][]byte
 
-	for  ;!reflect.DeepEqual(c.lastBlock.HeaderHash(), forkedHeaderHash); {
+	for !bytes.Equal(c.lastBlock.HeaderHash(), forkedHeaderHash) {
 		block, err := c.state.GetBlock(c.lastBlock.HeaderHash())
 
 		if err != nil {
@@ -260,11 +723,16 @@ func (c *Chain) Rollback(forkedHeaderHash []byte, forkState *generated.ForkState
 			c.log.Info("self.get_block_by_number(block.block_number) returned None")
 		}
 
-		if reflect.DeepEqual(block.HeaderHash(), mainchainBlock.HeaderHash()) {
+		if bytes.Equal(block.HeaderHash(), mainchainBlock.HeaderHash()) {
 			break
 		}
 		hashPath = append(hashPath, c.lastBlock.HeaderHash())
 
+		walSeq, err := c.wal.Begin(WALRemoveBlock, block.BlockNumber()-1, block.PrevHeaderHash(), block.HeaderHash())
+		if err != nil {
+			return hashPath, err
+		}
+
 		batch := c.state.GetBatch()
 		c.RemoveBlockFromMainchain(c.lastBlock, block.BlockNumber(), batch)
 
@@ -273,7 +741,12 @@ func (c *Chain) Rollback(forkedHeaderHash []byte, forkState *generated.ForkState
 			c.state.PutForkState(forkState, batch)
 		}
 
-		c.state.WriteBatch(batch)
+		if err := c.state.WriteChainBatch(batch); err != nil {
+			return hashPath, err
+		}
+		if err := c.wal.Commit(walSeq); err != nil {
+			c.log.Error("Failed to commit chain WAL", "error", err)
+		}
 
 		c.lastBlock, err = c.state.GetBlock(c.lastBlock.PrevHeaderHash())
 
@@ -282,7 +755,7 @@ func (c *Chain) Rollback(forkedHeaderHash []byte, forkState *generated.ForkState
 		}
 	}
 
-	return hashPath
+	return hashPath, nil
 }
 
 func (c *Chain) GetForkPoint(block *Block) ([]byte, [][]byte, error) {
@@ -291,16 +764,16 @@ func (c *Chain) GetForkPoint(block *Block) ([]byte, [][]byte, error) {
 	var hashPath [][]byte
 	for ;; {
 		if block == nil {
-			return nil, nil, errors.New("No Block Found " + string(block.HeaderHash()) +", Initiator " +
-				string(tmpBlock.HeaderHash()))
+			return nil, nil, errors.New("No Block Found " + misc.Bin2HStr(block.HeaderHash()) + ", Initiator " +
+				misc.Bin2HStr(tmpBlock.HeaderHash()))
 		}
 		mainchainBlock, err := c.state.GetBlockByNumber(block.BlockNumber())
-		if err == nil && reflect.DeepEqual(mainchainBlock.HeaderHash(), block.HeaderHash()) {
+		if err == nil && bytes.Equal(mainchainBlock.HeaderHash(), block.HeaderHash()) {
 			break
 		}
 		if block.BlockNumber() == 0 {
 			return nil, nil, errors.New("Alternate chain genesis is different, Initiator " +
-				string(tmpBlock.HeaderHash()))
+				misc.Bin2HStr(tmpBlock.HeaderHash()))
 		}
 		hashPath = append(hashPath, block.HeaderHash())
 		block, err = c.state.GetBlock(block.PrevHeaderHash())
@@ -316,7 +789,7 @@ func (c *Chain) AddChain(hashPath [][]byte, forkState *generated.ForkState) bool
 	var start int
 
 	for i := 0; i < len(hashPath); i++ {
-		if reflect.DeepEqual(hashPath[i], c.lastBlock.HeaderHash()) {
+		if bytes.Equal(hashPath[i], c.lastBlock.HeaderHash()) {
 			start = i + 1
 			break
 		}
@@ -330,16 +803,29 @@ func (c *Chain) AddChain(hashPath [][]byte, forkState *generated.ForkState) bool
 
 		}
 
+		walSeq, err := c.wal.Begin(WALAddBlock, block.BlockNumber(), block.HeaderHash(), c.lastBlock.HeaderHash())
+		if err != nil {
+			c.log.Error("Failed to write chain WAL", "error", err)
+			return false
+		}
+
 		batch := c.state.GetBatch()
 
-		if !c.applyBlock(block, batch) {
+		if !c.applyBlock(context.Background(), block, batch) {
+			c.wal.Commit(walSeq)
 			return false
 		}
 
 		c.updateChainState(block, batch)
 
 		c.log.Debug("Apply block #%d - [batch %d | %s]", block.BlockNumber(), i, hashPath[i])
-		c.state.WriteBatch(batch)
+		if err := c.state.WriteChainBatch(batch); err != nil {
+			c.log.Error("Failed to write block", "number", block.BlockNumber(), "error", err)
+			return false
+		}
+		if err := c.wal.Commit(walSeq); err != nil {
+			c.log.Error("Failed to commit chain WAL", "error", err)
+		}
 	}
 
 	c.state.DeleteForkState()
@@ -347,9 +833,195 @@ func (c *Chain) AddChain(hashPath [][]byte, forkState *generated.ForkState) bool
 	return true
 }
 
+// isReorgAllowed checks the depth of the reorg required to switch to the
+// branch ending at block against the configured MaxAutoReorgDepth
+func (c *Chain) isReorgAllowed(block *Block) bool {
+	maxDepth := c.config.User.MaxAutoReorgDepth
+	if maxDepth == 0 {
+		return true
+	}
+
+	forkHeaderHash, _, err := c.GetForkPoint(block)
+	if err != nil {
+		c.log.Warn("Failed to find fork point", "error", err)
+		return false
+	}
+
+	forkBlock, err := c.state.GetBlock(forkHeaderHash)
+	if err != nil {
+		c.log.Warn("Failed to get fork point block", "error", err)
+		return false
+	}
+
+	depth := c.lastBlock.BlockNumber() - forkBlock.BlockNumber()
+	if depth > maxDepth {
+		c.log.Crit("Reorg depth beyond MaxAutoReorgDepth, waiting for operator confirmation",
+			"depth", depth,
+			"max depth", maxDepth,
+			"new tip", misc.Bin2HStr(block.HeaderHash()))
+		return false
+	}
+
+	return true
+}
+
+// PendingReorg returns the headerhash of the tip of a branch which was not
+// switched to automatically due to its reorg depth
+func (c *Chain) PendingReorg() []byte {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.pendingReorg
+}
+
+// ConfirmReorg switches to the pending branch after operator confirmation
+func (c *Chain) ConfirmReorg(headerHash []byte) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.pendingReorg == nil || !bytes.Equal(c.pendingReorg, headerHash) {
+		return errors.New("no pending reorg for the given headerhash")
+	}
+
+	block, err := c.state.GetBlock(headerHash)
+	if err != nil {
+		return err
+	}
+
+	c.pendingReorg = nil
+
+	forkState := &generated.ForkState{InitiatorHeaderhash: block.HeaderHash()}
+	err = c.state.PutForkState(forkState, nil)
+	if err != nil {
+		return err
+	}
+
+	if !c.forkRecovery(block, forkState) {
+		return errors.New("fork recovery failed")
+	}
+
+	return nil
+}
+
+func (c *Chain) publishReorg(oldTip *Block, forkHeaderHash []byte, oldHashPath [][]byte, newHashPath [][]byte) {
+	newTxHashes := make(map[string]bool)
+	for _, headerHash := range newHashPath {
+		block, err := c.state.GetBlock(headerHash)
+		if err != nil {
+			continue
+		}
+		for _, protoTX := range block.Transactions() {
+			newTxHashes[string(protoTX.TransactionHash)] = true
+		}
+	}
+
+	// Transactions of the old branch which are not part of the new branch
+	var droppedTxHashes [][]byte
+	for _, headerHash := range oldHashPath {
+		block, err := c.state.GetBlock(headerHash)
+		if err != nil {
+			continue
+		}
+		for _, protoTX := range block.Transactions()[1:] {
+			if !newTxHashes[string(protoTX.TransactionHash)] {
+				droppedTxHashes = append(droppedTxHashes, protoTX.TransactionHash)
+			}
+		}
+	}
+
+	c.eventBus.Publish(events.TopicReorg, &events.ReorgEvent{
+		OldTip:          oldTip.HeaderHash(),
+		OldHeight:       oldTip.BlockNumber(),
+		NewTip:          c.lastBlock.HeaderHash(),
+		NewHeight:       c.lastBlock.BlockNumber(),
+		ForkPoint:       forkHeaderHash,
+		Depth:           uint64(len(oldHashPath)),
+		DroppedTxHashes: droppedTxHashes,
+	})
+}
+
+// resurrectTransactions returns the transactions of the blocks removed from
+// the mainchain to the pool, unless they are confirmed on the new branch or
+// no longer valid against the new tip. Transactions are checked in their
+// original order against a working copy of the state, so dependent
+// transactions from the same address keep passing the nonce checks.
+func (c *Chain) resurrectTransactions(oldHashPath [][]byte, newHashPath [][]byte) {
+	confirmed := make(map[string]bool)
+	for _, headerHash := range newHashPath {
+		block, err := c.state.GetBlock(headerHash)
+		if err != nil {
+			continue
+		}
+		for _, protoTX := range block.Transactions() {
+			confirmed[string(protoTX.TransactionHash)] = true
+		}
+	}
+
+	overlay := NewStateOverlay(c.state)
+	addressesState := make(map[string]*AddressState)
+	resurrected := 0
+
+	// oldHashPath is ordered from the old tip down to the fork point
+	for i := len(oldHashPath) - 1; i >= 0; i-- {
+		block, err := c.state.GetBlock(oldHashPath[i])
+		if err != nil {
+			c.log.Warn("Failed to load rolled back block", "error", err)
+			continue
+		}
+
+		// Skip the coinbase transaction, it is only valid in its own block
+		for _, protoTX := range block.Transactions()[1:] {
+			if confirmed[string(protoTX.TransactionHash)] {
+				continue
+			}
+
+			tx := transactions.ProtoToTransaction(protoTX)
+			if !c.validateAgainstTip(tx, overlay, addressesState) {
+				c.log.Debug("Dropping rolled back transaction", "txhash", misc.Bin2HStr(tx.Txhash()))
+				continue
+			}
+
+			tx.ApplyStateChanges(addressesState)
+			if err := c.txPool.Add(tx, c.lastBlock.BlockNumber(), 0); err != nil {
+				c.log.Debug("Failed to return transaction to pool", "error", err)
+				continue
+			}
+			resurrected++
+		}
+	}
+
+	c.log.Info("Returned rolled back transactions to pool", "count", resurrected)
+}
+
+// validateAgainstTip loads the states affected by tx from overlay into
+// addressesState and validates tx against them
+func (c *Chain) validateAgainstTip(tx transactions.TransactionInterface, overlay *StateOverlay, addressesState map[string]*AddressState) bool {
+	tx.SetAffectedAddress(addressesState)
+	overlay.Prepare(addressesState)
+
+	addrFromState := addressesState[string(tx.AddrFrom())]
+	addrFromPKState := addrFromState
+	if addrFromPK := tx.GetSlave(); addrFromPK != nil {
+		addrFromPKState = addressesState[string(addrFromPK)]
+	}
+
+	if !tx.ValidateExtended(addrFromState, addrFromPKState) {
+		return false
+	}
+
+	blockNumber := c.lastBlock.BlockNumber() + 1
+	if !tx.IsUnlockHeightValid(blockNumber) || addrFromState.SpendableBalance(blockNumber) < requiredBalance(tx) {
+		return false
+	}
+
+	return tx.Nonce() == addrFromPKState.Nonce()+1
+}
+
 func (c *Chain) forkRecovery(block *Block, forkState *generated.ForkState) bool {
 	c.log.Info("Triggered Fork Recovery")
 
+	oldTip := c.lastBlock
+
 	var forkHeaderHash []byte
 	var hashPath, oldHashPath [][]byte
 
@@ -358,9 +1030,11 @@ func (c *Chain) forkRecovery(block *Block, forkState *generated.ForkState) bool
 		forkHeaderHash = forkState.ForkPointHeaderhash
 		hashPath = forkState.NewMainchainHashPath
 	} else {
-		forkHeaderHash, hashPath, err := c.GetForkPoint(block)
+		var err error
+		forkHeaderHash, hashPath, err = c.GetForkPoint(block)
 		if err != nil {
-
+			c.log.Warn("Failed to find fork point", "error", err)
+			return false
 		}
 		forkState.ForkPointHeaderhash = forkHeaderHash
 		forkState.NewMainchainHashPath = hashPath
@@ -370,14 +1044,19 @@ func (c *Chain) forkRecovery(block *Block, forkState *generated.ForkState) bool
 	rollbackDone := false
 	if len(forkState.OldMainchainHashPath) > 0 {
 		b, err := c.state.GetBlock(forkState.OldMainchainHashPath[len(forkState.OldMainchainHashPath) - 1])
-		if err == nil && reflect.DeepEqual(b.PrevHeaderHash(), forkState.ForkPointHeaderhash) {
+		if err == nil && bytes.Equal(b.PrevHeaderHash(), forkState.ForkPointHeaderhash) {
 			rollbackDone = true
 		}
 	}
 
 	if !rollbackDone {
 		c.log.Info("Rolling back")
-		oldHashPath = c.Rollback(forkHeaderHash, forkState)
+		var err error
+		oldHashPath, err = c.Rollback(forkHeaderHash, forkState)
+		if err != nil {
+			c.log.Error("Failed to roll back", "error", err)
+			return false
+		}
 	} else {
 		oldHashPath = forkState.OldMainchainHashPath
 	}
@@ -386,33 +1065,49 @@ func (c *Chain) forkRecovery(block *Block, forkState *generated.ForkState) bool
 		c.log.Warn("Fork Recovery Failed... Recovering back to old mainchain")
 		// Above condition is true, when the node failed to add_chain
 		// Thus old chain state, must be retrieved
-		c.Rollback(forkHeaderHash, nil)
+		if _, err := c.Rollback(forkHeaderHash, nil); err != nil {
+			c.log.Error("Failed to roll back", "error", err)
+			return false
+		}
 		c.AddChain(misc.Reverse(oldHashPath), forkState)
 		return false
 	}
 
 	c.log.Info("Fork Recovery Finished")
 
+	c.resurrectTransactions(oldHashPath, hashPath)
+	c.publishReorg(oldTip, forkHeaderHash, oldHashPath, hashPath)
+
 	c.triggerMiner = true
 	return true
 }
 
+// blockDifficulty returns the difficulty and target of a block from the
+// difficulty of its parent. With FixedDifficulty it never changes.
+func (c *Chain) blockDifficulty(measurement uint64, parentDifficulty []byte) ([]byte, []byte) {
+	dt := pow.DifficultyTracker{}
+	if c.config.Dev.FixedDifficulty {
+		return parentDifficulty, dt.GetTarget(misc.BytesToUCharVector(parentDifficulty))
+	}
+	return dt.Get(measurement, parentDifficulty)
+}
+
 func (c *Chain) ValidateMiningNonce(bh *BlockHeader, enableLogging bool) bool {
-	c.lock.Lock()
-	defer c.lock.Unlock()
+	c.lock.RLock()
+	defer c.lock.RUnlock()
 
-	parentMetadata, err := c.state.GetBlockMetadata(bh.HeaderHash())
+	parentMetadata, err := c.state.GetBlockMetadata(bh.PrevHeaderHash())
 
 	if err != nil {
-		panic(err.Error())
+		c.log.Warn("Parent block metadata not found", "error", err)
+		return false
 	}
 
 	measurement, err := c.state.GetMeasurement(bh.Timestamp(), bh.PrevHeaderHash(), parentMetadata)
-	dt := pow.DifficultyTracker{}
-	diff, target := dt.Get(measurement, parentMetadata.BlockDifficulty())
+	diff, target := c.blockDifficulty(measurement, parentMetadata.BlockDifficulty())
 
 	if enableLogging {
-		parentBlock, err := c.state.GetBlock(bh.PrevHeaderHash())
+		parentBlock, err := c.getBlock(bh.PrevHeaderHash())
 		if err != nil {
 
 		}
@@ -426,20 +1121,63 @@ func (c *Chain) ValidateMiningNonce(bh *BlockHeader, enableLogging bool) bool {
 		c.log.Debug("-------------------END--------------------")
 	}
 
+	if c.validated.powVerified(bh.HeaderHash(), target) {
+		return true
+	}
 	if !pow.GetPowValidator().VerifyInput(bh.MiningBlob(), target) {
 		if enableLogging {
 			c.log.Warn("PoW verification failed")
 		}
 		return false
 	}
+	c.validated.setPowVerified(bh.HeaderHash(), target)
 
 	return true
 
 }
 
 func (c *Chain) GetBlock(headerhash []byte) (*Block, error) {
-	c.lock.Lock()
-	defer c.lock.Unlock()
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.getBlock(headerhash)
+}
+
+func (c *Chain) GetTxMetadata(txHash []byte) (*generated.TransactionMetadata, error) {
+	return c.state.GetTxMetadata(txHash)
+}
+
+func (c *Chain) GetBlockByNumber(blockNumber uint64) (*Block, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.getBlockByNumber(blockNumber)
+}
+
+// DiskUsage reports the database size per keyspace, it doesn't take the
+// Chain lock as the scan runs on a consistent LevelDB iterator
+func (c *Chain) DiskUsage() (*DiskUsage, error) {
+	return c.state.DiskUsage()
+}
 
-	return c.state.GetBlock(headerhash)
-}
\ No newline at end of file
+func (c *Chain) CompactDatabase() error {
+	return c.state.Compact()
+}
+
+// ancestorTimestamps returns the timestamps of block and up to count - 1 of
+// its ancestors, block first
+func (c *Chain) ancestorTimestamps(block *Block, count int, futureBlocks map[string]*Block) []uint32 {
+	var timestamps []uint32
+	for block != nil && len(timestamps) < count {
+		timestamps = append(timestamps, block.Timestamp())
+		if block.BlockNumber() == 0 {
+			break
+		}
+		parent, err := c.getBlock(block.PrevHeaderHash())
+		if err != nil {
+			parent = futureBlocks[string(block.PrevHeaderHash())]
+		}
+		block = parent
+	}
+	return timestamps
+}
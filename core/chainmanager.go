@@ -0,0 +1,217 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"github.com/cyyber/go-qrl/core/pool"
+	"github.com/cyyber/go-qrl/events"
+	"github.com/cyyber/go-qrl/log"
+	"github.com/cyyber/go-qrl/misc"
+	"github.com/cyyber/go-qrl/tracing"
+	"github.com/theQRL/qryptonight/goqryptonight"
+	"go.opentelemetry.io/otel/attribute"
+	"math/big"
+	"sync"
+	"time"
+)
+
+type BlockSource int
+
+const (
+	BlockFromSync BlockSource = iota
+	BlockFromMiner
+	BlockFromAPI
+)
+
+var blockSourceToString = map[BlockSource]string{
+	BlockFromSync:  "sync",
+	BlockFromMiner: "miner",
+	BlockFromAPI:   "api",
+}
+
+func (s BlockSource) String() string {
+	return blockSourceToString[s]
+}
+
+// maxDifficultyEntries bounds the difficulty index, an evicted entry is
+// loaded again from the block metadata when needed
+const maxDifficultyEntries = 4096
+
+// difficultyIndex maps header hashes to the cumulative difficulty of the
+// chain ending at that block. The entry of a new block is added as its
+// metadata is computed, before the block batch is written, others are
+// loaded from the block metadata on first access. The oldest entries are
+// evicted beyond maxDifficultyEntries.
+type difficultyIndex struct {
+	lock sync.Mutex
+
+	state        *State
+	difficulties map[string]*big.Int
+	// Header hashes of the entries, oldest first
+	order []string
+}
+
+func newDifficultyIndex(state *State) *difficultyIndex {
+	return &difficultyIndex{
+		state:        state,
+		difficulties: make(map[string]*big.Int),
+	}
+}
+
+func (d *difficultyIndex) get(headerHash []byte) (*big.Int, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if difficulty, ok := d.difficulties[string(headerHash)]; ok {
+		return difficulty, nil
+	}
+
+	blockMetadata, err := d.state.GetBlockMetadata(headerHash)
+	if err != nil {
+		return nil, err
+	}
+
+	difficulty, ok := new(big.Int).SetString(goqryptonight.UInt256ToString(misc.BytesToUCharVector(blockMetadata.TotalDifficulty())), 10)
+	if !ok {
+		return nil, errors.New("invalid total difficulty in block metadata")
+	}
+	d.put(string(headerHash), difficulty)
+
+	return difficulty, nil
+}
+
+// add records the cumulative difficulty of the chain ending at headerHash
+func (d *difficultyIndex) add(headerHash []byte, difficulty *big.Int) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.put(string(headerHash), difficulty)
+}
+
+func (d *difficultyIndex) put(headerHash string, difficulty *big.Int) {
+	if _, ok := d.difficulties[headerHash]; !ok {
+		d.order = append(d.order, headerHash)
+	}
+	d.difficulties[headerHash] = difficulty
+	for len(d.order) > maxDifficultyEntries {
+		delete(d.difficulties, d.order[0])
+		d.order = d.order[1:]
+	}
+}
+
+// isBetterTip returns true if the chain ending at candidate has a higher
+// cumulative difficulty than the one ending at tip
+func (d *difficultyIndex) isBetterTip(candidate []byte, tip []byte) (bool, error) {
+	candidateDifficulty, err := d.get(candidate)
+	if err != nil {
+		return false, err
+	}
+	tipDifficulty, err := d.get(tip)
+	if err != nil {
+		return false, err
+	}
+	return candidateDifficulty.Cmp(tipDifficulty) == 1, nil
+}
+
+// ChainManager is the single entry point for blocks, whether they come from
+// sync, the miner or the API. It owns the cumulative difficulty index used
+// to select the canonical tip and answers block queries from the canonical
+// index, so no caller mutates the Chain directly.
+type ChainManager struct {
+	log log.Logger
+
+	chain *Chain
+	state *State
+
+	difficulties *difficultyIndex
+
+	relay func(block *Block)
+}
+
+func CreateChainManager(log log.Logger, state *State, txPool *pool.TransactionPool, eventBus *events.Bus, config *Config) *ChainManager {
+	chain := CreateChain(log, state, txPool, eventBus, config)
+	return &ChainManager{
+		log:          log,
+		chain:        chain,
+		state:        state,
+		difficulties: chain.difficulties,
+	}
+}
+
+// SetBlockRelay sets the function broadcasting the blocks mined locally to
+// the peers, it must be called before blocks are added
+func (m *ChainManager) SetBlockRelay(relay func(block *Block)) {
+	m.relay = relay
+}
+
+func (m *ChainManager) Chain() *Chain {
+	return m.chain
+}
+
+func (m *ChainManager) Load(genesisBlock *Block) error {
+	return m.chain.Load(genesisBlock)
+}
+
+// AddBlock validates and stores block, switching the canonical tip if the
+// chain ending at block has a higher cumulative difficulty
+func (m *ChainManager) AddBlock(block *Block, source BlockSource) bool {
+	return m.AddBlockFromPeer(block, source, "")
+}
+
+// AddBlockFromPeer is AddBlock for a block received from the peer at
+// address peer, recorded in the block receipt
+func (m *ChainManager) AddBlockFromPeer(block *Block, source BlockSource, peer string) bool {
+	receivedAt := time.Now()
+	ctx, span := tracing.Start(context.Background(), "block.receive",
+		attribute.Int64("block.number", int64(block.BlockNumber())),
+		attribute.String("block.headerhash", misc.Bin2HStr(block.HeaderHash())),
+		attribute.String("block.source", source.String()),
+		attribute.Int("block.transactions", len(block.Transactions())))
+	defer span.End()
+
+	// Own blocks first: with cut-through relay a mined block leaves as soon
+	// as its header and PoW are checked, the peers validating it in
+	// parallel with our own processing
+	relayed := false
+	if source == BlockFromMiner && m.relay != nil && m.chain.config.User.Node.CutThroughRelay {
+		if block.Validate(m.chain, nil) {
+			m.relay(block)
+			relayed = true
+			span.SetAttributes(attribute.Bool("block.cut_through", true))
+		}
+	}
+
+	added := m.chain.processBlock(ctx, block, &blockReceipt{receivedAt: receivedAt, source: source, peer: peer})
+	span.SetAttributes(attribute.Bool("block.added", added))
+	if added && source == BlockFromMiner && m.relay != nil && !relayed {
+		m.relay(block)
+	}
+	if !added {
+		m.log.Debug("Block not added", "number", block.BlockNumber(), "headerhash", misc.Bin2HStr(block.HeaderHash()), "source", source)
+	}
+	return added
+}
+
+func (m *ChainManager) CumulativeDifficulty(headerHash []byte) (*big.Int, error) {
+	return m.difficulties.get(headerHash)
+}
+
+func (m *ChainManager) Tip() *Block {
+	return m.chain.GetLastBlock()
+}
+
+func (m *ChainManager) Height() uint64 {
+	return m.chain.Height()
+}
+
+func (m *ChainManager) GetBlock(headerHash []byte) (*Block, error) {
+	return m.chain.GetBlock(headerHash)
+}
+
+// GetBlockByNumber returns the block at blockNumber on the canonical chain
+func (m *ChainManager) GetBlockByNumber(blockNumber uint64) (*Block, error) {
+	if blockNumber > m.Height() {
+		return nil, errors.New("block number above chain height")
+	}
+	return m.chain.GetBlockByNumber(blockNumber)
+}
@@ -0,0 +1,200 @@
+package core
+
+import (
+	"github.com/cyyber/go-qrl/generated"
+	"github.com/cyyber/go-qrl/misc"
+	"github.com/theQRL/qryptonight/goqryptonight"
+	"math"
+	"math/big"
+	"sync"
+)
+
+type blockStatsEntry struct {
+	blockNumber uint64
+	headerHash  []byte
+	timestamp   uint64
+	blockTime   uint64
+	difficulty  []byte
+}
+
+// ChainStats keeps rolling statistics over the last N mainchain blocks.
+// It is updated as blocks are added to or removed from the mainchain, so
+// querying it never requires a scan of the chain.
+type ChainStats struct {
+	lock sync.RWMutex
+
+	windowSize int
+	entries    []*blockStatsEntry
+
+	sumBlockTime        uint64
+	sumSquaredBlockTime float64
+}
+
+func CreateChainStats(windowSize int) *ChainStats {
+	return &ChainStats{
+		windowSize: windowSize,
+	}
+}
+
+func (s *ChainStats) Push(block *Block, difficulty []byte) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	entry := &blockStatsEntry{
+		blockNumber: block.BlockNumber(),
+		headerHash:  block.HeaderHash(),
+		timestamp:   uint64(block.Timestamp()),
+		difficulty:  difficulty,
+	}
+
+	if len(s.entries) > 0 {
+		last := s.entries[len(s.entries)-1]
+		if entry.timestamp > last.timestamp {
+			entry.blockTime = entry.timestamp - last.timestamp
+		}
+	}
+
+	s.entries = append(s.entries, entry)
+	s.sumBlockTime += entry.blockTime
+	s.sumSquaredBlockTime += float64(entry.blockTime) * float64(entry.blockTime)
+
+	if len(s.entries) > s.windowSize {
+		oldest := s.entries[0]
+		s.sumBlockTime -= oldest.blockTime
+		s.sumSquaredBlockTime -= float64(oldest.blockTime) * float64(oldest.blockTime)
+		s.entries = s.entries[1:]
+
+		// The parent of the new first entry left the window, its block
+		// time isn't counted anymore
+		first := s.entries[0]
+		s.sumBlockTime -= first.blockTime
+		s.sumSquaredBlockTime -= float64(first.blockTime) * float64(first.blockTime)
+		first.blockTime = 0
+	}
+}
+
+func (s *ChainStats) Reset() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.entries = nil
+	s.sumBlockTime = 0
+	s.sumSquaredBlockTime = 0
+}
+
+// Pop removes the most recent block, used when a block is removed from the
+// mainchain during fork recovery
+func (s *ChainStats) Pop() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if len(s.entries) == 0 {
+		return
+	}
+
+	last := s.entries[len(s.entries)-1]
+	s.sumBlockTime -= last.blockTime
+	s.sumSquaredBlockTime -= float64(last.blockTime) * float64(last.blockTime)
+	s.entries = s.entries[:len(s.entries)-1]
+}
+
+func (s *ChainStats) count() int {
+	// The first entry in the window has no parent inside the window,
+	// so it doesn't contribute a block time
+	if len(s.entries) < 2 {
+		return 0
+	}
+	return len(s.entries) - 1
+}
+
+func (s *ChainStats) BlockTimeMean() uint64 {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	n := s.count()
+	if n == 0 {
+		return 0
+	}
+	return s.sumBlockTime / uint64(n)
+}
+
+func (s *ChainStats) BlockTimeSD() uint64 {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	n := s.count()
+	if n == 0 {
+		return 0
+	}
+	mean := float64(s.sumBlockTime) / float64(n)
+	variance := s.sumSquaredBlockTime/float64(n) - mean*mean
+	if variance < 0 {
+		return 0
+	}
+	return uint64(math.Sqrt(variance))
+}
+
+func (s *ChainStats) LastDifficulty() []byte {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if len(s.entries) == 0 {
+		return nil
+	}
+	return s.entries[len(s.entries)-1].difficulty
+}
+
+// HashRate estimates the network hashrate as difficulty / mean block time
+func (s *ChainStats) HashRate() float64 {
+	difficulty := s.LastDifficulty()
+	mean := s.BlockTimeMean()
+	if difficulty == nil || mean == 0 {
+		return 0
+	}
+
+	d := big.NewFloat(0)
+	d.SetString(goqryptonight.UInt256ToString(misc.BytesToUCharVector(difficulty)))
+	d.Quo(d, big.NewFloat(float64(mean)))
+	hashRate, _ := d.Float64()
+	return hashRate
+}
+
+// DataPoints returns the blocks in the current window as BlockDataPoints,
+// oldest first
+func (s *ChainStats) DataPoints() []*generated.BlockDataPoint {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	var dataPoints []*generated.BlockDataPoint
+	var sumBlockTime uint64
+	var prevHeaderHash []byte
+
+	for i, entry := range s.entries {
+		difficulty := big.NewInt(0)
+		difficulty.SetString(goqryptonight.UInt256ToString(misc.BytesToUCharVector(entry.difficulty)), 10)
+
+		dataPoint := &generated.BlockDataPoint{
+			Number:         entry.blockNumber,
+			Difficulty:     difficulty.String(),
+			Timestamp:      entry.timestamp,
+			TimeLast:       entry.blockTime,
+			HeaderHash:     entry.headerHash,
+			HeaderHashPrev: prevHeaderHash,
+		}
+
+		if i > 0 {
+			sumBlockTime += entry.blockTime
+			dataPoint.TimeMovavg = sumBlockTime / uint64(i)
+		}
+		if dataPoint.TimeMovavg > 0 {
+			hashPower, _ := new(big.Float).Quo(new(big.Float).SetInt(difficulty),
+				big.NewFloat(float64(dataPoint.TimeMovavg))).Float32()
+			dataPoint.HashPower = hashPower
+		}
+
+		dataPoints = append(dataPoints, dataPoint)
+		prevHeaderHash = entry.headerHash
+	}
+
+	return dataPoints
+}
@@ -0,0 +1,171 @@
+package core
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+	"sync"
+)
+
+const maxTrackedTips = 100
+
+const (
+	TipActive       = "active"
+	TipValidFork    = "valid-fork"
+	TipPendingReorg = "pending-reorg"
+)
+
+// ChainTip is the last block of a branch of the block tree
+type ChainTip struct {
+	HeaderHash  []byte
+	BlockNumber uint64
+	Timestamp   uint32
+	// Cumulative difficulty of the branch, the chain with the highest one is
+	// the canonical chain
+	CumulativeDifficulty *big.Int
+	// Blocks of the branch not in the mainchain, 0 for the active tip
+	BranchLength uint64
+	Status       string
+	// Local time the tip was received
+	SeenAt uint64
+}
+
+// chainTips tracks the leaves of the block tree received since startup. A
+// block replaces its parent as a leaf, so the tips left are the canonical
+// tip and the last blocks of the stale branches.
+type chainTips struct {
+	lock sync.Mutex
+
+	tips map[string]*ChainTip
+}
+
+func newChainTips() *chainTips {
+	return &chainTips{tips: make(map[string]*ChainTip)}
+}
+
+func (t *chainTips) add(block *Block, seenAt uint64) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	delete(t.tips, string(block.PrevHeaderHash()))
+	t.tips[string(block.HeaderHash())] = &ChainTip{
+		HeaderHash:  block.HeaderHash(),
+		BlockNumber: block.BlockNumber(),
+		Timestamp:   block.Timestamp(),
+		SeenAt:      seenAt,
+	}
+
+	for len(t.tips) > maxTrackedTips {
+		var oldest *ChainTip
+		for _, tip := range t.tips {
+			if oldest == nil || tip.SeenAt < oldest.SeenAt {
+				oldest = tip
+			}
+		}
+		delete(t.tips, string(oldest.HeaderHash))
+	}
+}
+
+// prune forgets the tips beyond the reorg limit of height, they can't
+// become canonical anymore
+func (t *chainTips) prune(height uint64, reorgLimit uint64) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for key, tip := range t.tips {
+		if tip.BlockNumber+reorgLimit < height {
+			delete(t.tips, key)
+		}
+	}
+}
+
+func (t *chainTips) list() []ChainTip {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	tips := make([]ChainTip, 0, len(t.tips))
+	for _, tip := range t.tips {
+		tips = append(tips, *tip)
+	}
+	return tips
+}
+
+// trackTip records block as a leaf once it's stored, the Chain lock must be
+// held
+func (c *Chain) trackTip(block *Block) {
+	c.tips.add(block, c.clock.Time())
+	c.tips.prune(c.lastBlock.BlockNumber(), c.config.Dev.ReorgLimit)
+}
+
+// isMainchain returns true if headerHash is the mainchain block at
+// blockNumber
+func (c *Chain) isMainchain(headerHash []byte, blockNumber uint64) bool {
+	mapping, err := c.state.GetBlockNumberMapping(blockNumber)
+	return err == nil && mapping != nil && bytes.Equal(mapping.Headerhash, headerHash)
+}
+
+// branchLength walks back from tip to the mainchain, within the reorg limit
+func (c *Chain) branchLength(tip *ChainTip) uint64 {
+	headerHash := tip.HeaderHash
+	blockNumber := tip.BlockNumber
+	var length uint64
+	for length <= c.config.Dev.ReorgLimit && !c.isMainchain(headerHash, blockNumber) {
+		block, err := c.getBlock(headerHash)
+		if err != nil || blockNumber == 0 {
+			break
+		}
+		length++
+		headerHash = block.PrevHeaderHash()
+		blockNumber--
+	}
+	return length
+}
+
+// GetChainTips returns the canonical tip and the tips of the stale branches
+// received recently, the highest cumulative difficulty first. Several tips
+// with a comparable difficulty mean the network is split.
+func (c *Chain) GetChainTips() ([]*ChainTip, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	active := ChainTip{
+		HeaderHash:  c.lastBlock.HeaderHash(),
+		BlockNumber: c.lastBlock.BlockNumber(),
+		Timestamp:   c.lastBlock.Timestamp(),
+	}
+	tips := []*ChainTip{&active}
+	for _, tip := range c.tips.list() {
+		tip := tip
+		if bytes.Equal(tip.HeaderHash, active.HeaderHash) {
+			active.SeenAt = tip.SeenAt
+			continue
+		}
+		if c.isMainchain(tip.HeaderHash, tip.BlockNumber) {
+			continue
+		}
+		tips = append(tips, &tip)
+	}
+
+	for _, tip := range tips {
+		difficulty, err := c.difficulties.get(tip.HeaderHash)
+		if err != nil {
+			return nil, err
+		}
+		tip.CumulativeDifficulty = difficulty
+		switch {
+		case tip == &active:
+			tip.Status = TipActive
+		case bytes.Equal(tip.HeaderHash, c.pendingReorg):
+			tip.Status = TipPendingReorg
+			tip.BranchLength = c.branchLength(tip)
+		default:
+			tip.Status = TipValidFork
+			tip.BranchLength = c.branchLength(tip)
+		}
+	}
+
+	sort.SliceStable(tips, func(i, j int) bool {
+		return tips[i].CumulativeDifficulty.Cmp(tips[j].CumulativeDifficulty) > 0
+	})
+	return tips, nil
+}
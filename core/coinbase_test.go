@@ -0,0 +1,89 @@
+package core
+
+import (
+	"github.com/cyyber/go-qrl/generated"
+	"github.com/cyyber/go-qrl/log"
+	"github.com/cyyber/go-qrl/misc"
+	"testing"
+)
+
+func testCoinbaseBlock(config *Config, blockNumber uint64, txs []*generated.Transaction) *Block {
+	pbBlock := &generated.Block{
+		Header:       &generated.BlockHeader{BlockNumber: blockNumber},
+		Transactions: txs,
+	}
+	return &Block{
+		block:       pbBlock,
+		blockheader: &BlockHeader{blockHeader: pbBlock.Header, config: config},
+		config:      config,
+		log:         log.New(),
+	}
+}
+
+func testCoinbase(config *Config, minerAddress []byte, blockNumber uint64, amount uint64) *generated.Transaction {
+	return &generated.Transaction{
+		MasterAddr: config.Dev.Genesis.CoinbaseAddress,
+		Nonce:      blockNumber + 1,
+		TransactionType: &generated.Transaction_Coinbase{
+			Coinbase: &generated.Transaction_CoinBase{
+				AddrTo: minerAddress,
+				Amount: amount,
+			},
+		},
+	}
+}
+
+func testFeeTransfer(fee uint64) *generated.Transaction {
+	return &generated.Transaction{
+		Fee: fee,
+		TransactionType: &generated.Transaction_Transfer_{
+			Transfer: &generated.Transaction_Transfer{},
+		},
+	}
+}
+
+func TestValidateCoinbase(t *testing.T) {
+	config := GetConfig()
+	blockNumber := uint64(10)
+	miner := misc.BurnAddress([]byte{0x00, 0x04, 0x00})
+	reward := BlockRewardCalc(blockNumber, config)
+	coinbase := func(amount uint64, change func(tx *generated.Transaction)) *generated.Transaction {
+		tx := testCoinbase(config, miner, blockNumber, amount)
+		if change != nil {
+			change(tx)
+		}
+		return tx
+	}
+
+	tests := []struct {
+		name  string
+		txs   []*generated.Transaction
+		valid bool
+	}{
+		{"valid", []*generated.Transaction{coinbase(reward+3, nil), testFeeTransfer(3)}, true},
+		{"empty block", nil, false},
+		{"coinbase not first", []*generated.Transaction{testFeeTransfer(0), coinbase(reward, nil)}, false},
+		{"second coinbase", []*generated.Transaction{coinbase(reward, nil), coinbase(0, nil)}, false},
+		{"amount above reward", []*generated.Transaction{coinbase(reward+1, nil)}, false},
+		{"amount without fees", []*generated.Transaction{coinbase(reward, nil), testFeeTransfer(3)}, false},
+		{"invalid miner address", []*generated.Transaction{coinbase(reward, func(tx *generated.Transaction) {
+			tx.GetCoinbase().AddrTo = make([]byte, misc.AddressSize)
+		})}, false},
+		{"nonce not block number + 1", []*generated.Transaction{coinbase(reward, func(tx *generated.Transaction) {
+			tx.Nonce = blockNumber
+		})}, false},
+		{"public key", []*generated.Transaction{coinbase(reward, func(tx *generated.Transaction) {
+			tx.PublicKey = make([]byte, 67)
+		})}, false},
+		{"signature", []*generated.Transaction{coinbase(reward, func(tx *generated.Transaction) {
+			tx.Signature = make([]byte, 8)
+		})}, false},
+	}
+
+	for _, test := range tests {
+		_, valid := testCoinbaseBlock(config, blockNumber, test.txs).validateCoinbase()
+		if valid != test.valid {
+			t.Errorf("%s: validateCoinbase() = %v, expected %v", test.name, valid, test.valid)
+		}
+	}
+}
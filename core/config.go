@@ -1,16 +1,41 @@
 package core
 
-import "sync"
+import (
+	"bytes"
+	"fmt"
+	"github.com/cyyber/go-qrl/db"
+	"github.com/cyyber/go-qrl/misc"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
 
 type Config struct {
 	Dev  *DevConfig
 	User *UserConfig
+
+	// The user settings published by Reload, see Settings
+	settings atomic.Value
 }
 
 type MinerConfig struct {
 	MiningEnabled     bool
 	MiningAddress     string
 	MiningThreadCount uint16
+
+	// Longest time a long-poll block template request is held, in seconds
+	LongPollTimeout uint32
+	// Fee increase, in percent of the fees of the template held by the
+	// miner, answering a long-poll request on the same tip
+	LongPollFeeChangePercent uint64
+
+	// The extra nonce space is split in 2^ExtraNoncePartitionBits ranges
+	// assigned to the workers of the mining API
+	ExtraNoncePartitionBits uint8
+	// Minutes after which the range of a worker which stopped polling is
+	// reassigned
+	ExtraNonceLeaseMinutes uint16
 }
 
 type NodeConfig struct {
@@ -23,6 +48,38 @@ type NodeConfig struct {
 	BanMinutes              uint8
 	MaxPeersLimit           uint16
 	MaxRedundantConnections int
+	// Peers kept connected in seed mode, instead of MaxPeersLimit
+	SeedMaxPeersLimit uint16
+
+	// Unauthenticated encryption of the P2P connections, it keeps passive
+	// observers out but doesn't stop a man-in-the-middle
+	EnableEncryptedTransport bool
+	// Message compression offered to peers: zstd, snappy or off
+	Compression string
+
+	PeerIdleTimeout       uint16
+	PeerReadTimeout       uint16
+	PeerWriteTimeout      uint16
+	PeerMaxBytesPerSecond uint64
+
+	MaxOutboundPeers uint16
+	MaxAnchorPeers   uint16
+	MinAnchorUptime  uint32
+
+	// Outbound peers allowed per network group, 0 for unlimited. Groups are
+	// autonomous systems when ASNDatabase, a MaxMind ASN database, is set,
+	// IP prefixes otherwise.
+	MaxOutboundPerNetGroup uint16
+	ASNDatabase            string
+
+	// Connection filters, as CIDR ranges or plain IPs
+	AllowedCIDRs []string
+	DeniedCIDRs  []string
+
+	// Relay blocks mined locally as soon as their header and PoW are
+	// checked, before they are applied to the state, cutting orphan rates.
+	// When disabled they are relayed once fully processed.
+	CutThroughRelay bool
 }
 
 type EphemeralConfig struct {
@@ -30,6 +87,8 @@ type EphemeralConfig struct {
 }
 
 type NTPConfig struct {
+	// When disabled the system clock is trusted
+	Enabled bool
 	Retries int
 	Servers []string
 	Refresh uint64
@@ -40,6 +99,24 @@ type TransactionPoolConfig struct {
 	PendingTransactionPoolSize   uint64
 	PendingTranactionPoolReserve uint64
 	StaleTransactionThreshold    uint64
+	MaxQueuedPerAddress          uint64
+	MaxTxPerAddress              uint64
+	MaxTxPerPK                   uint64
+	// Relay policy only, blocks paying less are still valid
+	MinRelayFeePerByte uint64
+	// Deprecated: former name of MinRelayFeePerByte, still read from the
+	// configuration file and copied to it
+	MinFeePerByte uint64
+	// Q addresses whose transactions are admitted and relayed whatever fee
+	// they pay
+	FeeExemptAddresses []string
+	// Minimum fee per byte by transaction type name, e.g. message or
+	// token, overriding MinRelayFeePerByte. Also applied to templates.
+	MinFeePerByteByType map[string]uint64
+	// Transaction types neither admitted, relayed nor mined
+	DisabledTxTypes []string
+	// Recently rejected transactions kept for inspection
+	RejectLogSize uint64
 }
 
 type API struct {
@@ -55,14 +132,293 @@ type UserConfig struct {
 
 	NTP *NTPConfig
 
-	ChainStateTimeout         uint16
+	ChainStateTimeout uint16
+	// Seconds between the chain state announcements, 0 to only announce it
+	// on connection
 	ChainStateBroadcastPeriod uint16
 
+	MaxAutoReorgDepth uint64
+
 	TransactionPool *TransactionPoolConfig
 
 	QrlDir string
 
+	// crit, error, warn, info, debug or trace
+	LogLevel string
+
 	API *API
+
+	Deposits *DepositsConfig
+	Webhooks *WebhooksConfig
+
+	// Bootstrap headers file imported at first start, relative to QrlDir
+	BootstrapHeadersFilename string
+
+	// Minutes between background database compactions, 0 disables them
+	DBCompactionInterval uint64
+
+	Database *DatabaseConfig
+
+	// Compression of the blocks stored, off, snappy or zstd. Blocks stored
+	// with another compression are rewritten in the background. Enabling it
+	// is a one-way migration: releases without block compression can't read
+	// the compressed blocks, set it back to off and let the blocks be
+	// rewritten before downgrading.
+	BlockCompression string
+
+	// Number of decoded blocks kept in memory, 0 disables the cache
+	BlockCacheSize uint64
+
+	// Number of blocks whose context-free validation results are kept, 0
+	// disables the cache
+	ValidationCacheSize uint64
+
+	// Read-only replica: the node serves the API from a snapshot of the
+	// primary's data directory, reopened every ReplicaRefreshInterval
+	// seconds, and never writes, syncs or mines
+	ReadOnly               bool
+	ReplicaRefreshInterval uint64
+
+	// Seed node: peers are served peer lists, headers and blocks only, the
+	// transaction pool, mining and the public and mining APIs are disabled
+	SeedMode bool
+
+	// Relay-only node: transactions and blocks are validated and relayed,
+	// mining and the public and mining APIs are disabled and only the
+	// bodies of the last RelayWindow blocks are kept
+	RelayOnly   bool
+	RelayWindow uint64
+
+	// Roll back to the last block verifying against its undo record when
+	// the database is found inconsistent at startup, instead of failing
+	AutoRepair bool
+
+	// Blocks on top of a transaction, its own included, for the API to
+	// report it confirmed
+	ConfirmationDepth uint64
+
+	Debug *DebugConfig
+
+	SlowBlock *SlowBlockConfig
+
+	Health *HealthConfig
+
+	BlockArchives *BlockArchivesConfig
+
+	MinerStats *MinerStatsConfig
+
+	Tracing *TracingConfig
+
+	Wallet *WalletConfig
+}
+
+const (
+	// Few readers, every block synced to disk as it's applied
+	DatabaseProfileMining = "mining"
+	// API heavy node, larger caches and periodic syncs for faster syncing
+	// and indexing
+	DatabaseProfileExplorer = "explorer"
+)
+
+// DatabaseConfig tunes LevelDB. Settings left at 0 take the value of the
+// profile, sizes are in MiB.
+type DatabaseConfig struct {
+	// mining or explorer
+	Profile string
+
+	Cache           int
+	Handles         int
+	WriteBuffer     int
+	BloomFilterBits int
+
+	// Sync every write to disk, otherwise the writes of the indexes and
+	// statistics are synced every SyncInterval seconds and the last ones
+	// may be lost on power loss. Chain updates are always synced, the WAL
+	// treats them as durable once written.
+	SyncWrites   *bool
+	SyncInterval uint64
+
+	// Number of level 0 files triggering a compaction, slowing down then
+	// pausing writes
+	CompactionL0Trigger    int
+	WriteL0SlowdownTrigger int
+	WriteL0PauseTrigger    int
+	CompactionTableSize    int
+}
+
+var databaseProfiles = map[string]*db.Options{
+	DatabaseProfileMining: {
+		Cache:                  128,
+		Handles:                64,
+		WriteBuffer:            32,
+		BloomFilterBits:        10,
+		SyncWrites:             true,
+		CompactionL0Trigger:    4,
+		WriteL0SlowdownTrigger: 8,
+		WriteL0PauseTrigger:    12,
+		CompactionTableSize:    2,
+	},
+	DatabaseProfileExplorer: {
+		Cache:                  512,
+		Handles:                256,
+		WriteBuffer:            64,
+		BloomFilterBits:        10,
+		SyncWrites:             false,
+		SyncInterval:           5 * time.Second,
+		CompactionL0Trigger:    8,
+		WriteL0SlowdownTrigger: 16,
+		WriteL0PauseTrigger:    24,
+		CompactionTableSize:    8,
+	},
+}
+
+// Options returns the LevelDB options, the settings of the profile
+// overridden by the ones set
+func (d *DatabaseConfig) Options() (*db.Options, error) {
+	profile, ok := databaseProfiles[d.Profile]
+	if !ok {
+		return nil, fmt.Errorf("unknown database profile %s", d.Profile)
+	}
+	options := *profile
+
+	override := func(value int, option *int) {
+		if value != 0 {
+			*option = value
+		}
+	}
+	override(d.Cache, &options.Cache)
+	override(d.Handles, &options.Handles)
+	override(d.WriteBuffer, &options.WriteBuffer)
+	override(d.BloomFilterBits, &options.BloomFilterBits)
+	override(d.CompactionL0Trigger, &options.CompactionL0Trigger)
+	override(d.WriteL0SlowdownTrigger, &options.WriteL0SlowdownTrigger)
+	override(d.WriteL0PauseTrigger, &options.WriteL0PauseTrigger)
+	override(d.CompactionTableSize, &options.CompactionTableSize)
+	if d.SyncWrites != nil {
+		options.SyncWrites = *d.SyncWrites
+	}
+	if d.SyncInterval != 0 {
+		options.SyncInterval = time.Duration(d.SyncInterval) * time.Second
+	}
+	if !options.SyncWrites && options.SyncInterval == 0 {
+		options.SyncInterval = time.Second
+	}
+	return &options, nil
+}
+
+// WalletConfig holds the walletd settings, filenames are relative to QrlDir
+type WalletConfig struct {
+	WalletFilename   string
+	ContactsFilename string
+	// Seconds without use after which an unlocked wallet locks itself,
+	// 0 disables auto-lock
+	AutoLockTimeout uint64
+
+	SlaveService *SlaveServiceConfig
+}
+
+// SlaveServiceConfig sets up walletd to sign with slave keys only, spending
+// the funds of a master address kept offline within local limits
+type SlaveServiceConfig struct {
+	Enabled       bool
+	MasterAddress string
+	// Amounts in Shor, 0 means no limit
+	MaxAmountPerTx  uint64
+	MaxAmountPerDay uint64
+	// Empty allows any destination
+	AllowedDestinations []string
+	// Spendings of the last 24 hours, relative to QrlDir
+	SpendingFilename string
+}
+
+type TracingConfig struct {
+	Enabled bool
+	// stdout or otlp
+	Exporter string
+	// OTLP collector address, as host:port
+	Endpoint    string
+	SampleRatio float64
+}
+
+// DebugConfig controls the pprof and runtime stats server, which only binds
+// to loopback addresses
+type DebugConfig struct {
+	Enabled bool
+	Host    string
+	Port    uint16
+}
+
+// SlowBlockConfig sets the thresholds above which an applied block is
+// reported as slow, 0 disabling a threshold
+type SlowBlockConfig struct {
+	ApplyTimeMillis        uint64
+	StateReads             uint64
+	StateWrites            uint64
+	SignatureVerifications uint64
+	// Slow block reports kept for the admin API
+	ReportsKept uint64
+}
+
+// BlockArchivesConfig controls the chain archives written every Interval
+// blocks, served over HTTPS on Host:Port under /archives/ to the requests
+// holding Token as bearer token
+type BlockArchivesConfig struct {
+	Enabled  bool
+	Interval uint64
+	Keep     uint64
+	// Relative to the data directory of the network
+	Directory string
+
+	Host string
+	Port uint16
+	// The archives aren't served without a certificate
+	TLSCertFile string
+	TLSKeyFile  string
+	// Downloads are refused while it's empty
+	Token string
+}
+
+// MinerStatsConfig sets the length in seconds of the intervals the blocks
+// of the local miners are counted in, 0 disabling the stats, and how many
+// intervals are kept
+type MinerStatsConfig struct {
+	Interval uint64
+	Keep     uint64
+}
+
+// HealthConfig controls the /healthz and /readyz probes server. The node is
+// ready once it has MinPeers peers and is at most MaxSyncLag blocks behind
+// the height announced by its peers.
+type HealthConfig struct {
+	Enabled    bool
+	Host       string
+	Port       uint16
+	MinPeers   uint16
+	MaxSyncLag uint64
+}
+
+type WebhookEndpoint struct {
+	URL    string
+	Secret string
+	// Topics delivered to this endpoint: new_block, reorg, deposit and
+	// address_transaction
+	Topics           []string
+	WatchedAddresses []string
+}
+
+type WebhooksConfig struct {
+	Endpoints          []*WebhookEndpoint
+	DeadLetterFilename string
+	Timeout            uint64 // seconds
+	MaxRetries         uint64
+	InitialBackoff     uint64 // milliseconds
+	MaxBackoff         uint64 // milliseconds
+}
+
+type DepositsConfig struct {
+	Enabled          bool
+	WatchedAddresses []string
+	Confirmations    uint64
 }
 
 type APIConfig struct {
@@ -71,13 +427,40 @@ type APIConfig struct {
 	Port             uint32
 	Threads          uint32
 	MaxConcurrentRPC uint16
+
+	// Encode JSON responses the same way the Python node does
+	PythonCompatibleJSON bool
+	// Encode bytes as hex instead of base64, requires PythonCompatibleJSON
+	HexBytesJSON bool
+
+	AllowedCIDRs []string
+	DeniedCIDRs  []string
 }
 
 type DevConfig struct {
+	Network string
+	// Recorded in the data directory with the genesis headerhash, a
+	// directory of another chain is never opened
+	ChainID uint64
 	Genesis              *GenesisConfig
 
+	// Every block keeps the genesis difficulty, used by regtest
+	FixedDifficulty bool
+
+	// Deterministic accounts funded at genesis with DevAccountBalance Shor
+	// each, on developer networks only. The first one funds the faucet,
+	// which pays FaucetAmount Shor per request.
+	DevAccounts       uint16
+	DevAccountBalance uint64
+	FaucetAmount      uint64
+
 	BlocksPerEpoch		 uint64
 	BlockLeadTimestamp   uint32
+
+	// Blocks must have a timestamp above the median of the previous
+	// MedianTimestampWindow blocks from this block number on
+	MedianTimestampForkBlockNumber uint64
+	MedianTimestampWindow          uint16
 	BlockMaxDrift        uint16
 	MaxFutureBlockLength uint16
 	MaxMarginBlocKNumber uint16
@@ -104,9 +487,19 @@ type DevConfig struct {
 	ChainFileDirectory  string
 	WalletDatFilename   string
 	BannedPeersFilename string
+	AnchorsFilename     string
+	ChainWALFilename    string
+	ConfigFilename      string
+	// Chain ID and genesis of the data directory
+	ChainInfoFilename string
+
+	// Q addresses trusted to sign bootstrap headers files
+	BootstrapPublishers []string
 
 	Transaction *TransactionConfig
 
+	AddressRules []*AddressRule
+
 	Token *TokenConfig
 
 	NMeasurement uint8
@@ -115,17 +508,102 @@ type DevConfig struct {
 	NumberOfBlockAnalyze uint8
 	SizeMultiplier       float64
 	BlockMinSizeLimit    int
+	BlockMaxWeight       uint64
+	// Block number from which BlockMaxWeight is enforced, blocks are only
+	// limited by size before it
+	BlockWeightForkBlockNumber uint64
 
 	ShorPerQuanta uint64
 
 	MaxReceivableBytes uint64
-	SyncDelayMining    uint8
+	// Bytes sent to a peer and not yet acknowledged by a P2P_ACK, as
+	// enforced by the Python node
+	MaxBytesOut     uint64
+	SyncDelayMining uint8
 
 	BlockTimeSeriesSize uint32
 }
 
+// AddressRule is a consensus rule restricting the addresses blocks can pay
+// to, between StartBlockNumber and EndBlockNumber inclusive. An
+// EndBlockNumber of 0 means the rule never ends.
+type AddressRule struct {
+	StartBlockNumber uint64
+	EndBlockNumber   uint64
+
+	// Addresses which cannot receive the block reward
+	BannedAddresses [][]byte
+	// If not empty, the block reward must be paid to one of these addresses
+	RequiredCoinbaseAddresses [][]byte
+}
+
+func (r *AddressRule) IsActive(blockNumber uint64) bool {
+	if blockNumber < r.StartBlockNumber {
+		return false
+	}
+	return r.EndBlockNumber == 0 || blockNumber <= r.EndBlockNumber
+}
+
+// ValidateCoinbaseAddress returns false if the rules active at blockNumber
+// don't allow the block reward to be paid to address
+func (d *DevConfig) ValidateCoinbaseAddress(address []byte, blockNumber uint64) bool {
+	for _, rule := range d.AddressRules {
+		if !rule.IsActive(blockNumber) {
+			continue
+		}
+
+		for _, banned := range rule.BannedAddresses {
+			if bytes.Equal(banned, address) {
+				return false
+			}
+		}
+
+		if len(rule.RequiredCoinbaseAddresses) == 0 {
+			continue
+		}
+		required := false
+		for _, requiredAddress := range rule.RequiredCoinbaseAddresses {
+			if bytes.Equal(requiredAddress, address) {
+				required = true
+				break
+			}
+		}
+		if !required {
+			return false
+		}
+	}
+	return true
+}
+
 type TransactionConfig struct {
 	MultiOutputLimit uint8
+
+	// Block number from which the expiry_block_number of transactions
+	// is enforced
+	ExpiryForkBlockNumber uint64
+
+	// Block number from which transfers may carry an unlock_height
+	TimeLockForkBlockNumber uint64
+
+	WeightPerByte   uint64
+	SignatureWeight uint64
+
+	MaxLatticePKSize uint16
+
+	// Largest serialized transaction admitted to the pool
+	MaxTxSize uint32
+}
+
+func (t *TransactionConfig) IsExpiryActive(blockNumber uint64) bool {
+	return blockNumber >= t.ExpiryForkBlockNumber
+}
+
+func (t *TransactionConfig) IsTimeLockActive(blockNumber uint64) bool {
+	return blockNumber >= t.TimeLockForkBlockNumber
+}
+
+func (d *DevConfig) IsBlockWeightActive(blockNumber uint64) bool {
+	return blockNumber >= d.BlockWeightForkBlockNumber
 }
 
 type TokenConfig struct {
@@ -136,6 +614,7 @@ type TokenConfig struct {
 type GenesisConfig struct {
 	Version              string
 	GenesisPrevHeadehash []byte
+	// In Quanta
 	MaxCoinSupply        uint64
 	SuppliedCoins        uint64
 	GenesisDifficulty    uint64
@@ -143,8 +622,33 @@ type GenesisConfig struct {
 	GenesisTimestamp     uint32
 }
 
+const (
+	NetworkMainnet = "mainnet"
+	NetworkDevnet  = "devnet"
+	NetworkRegtest = "regtest"
+)
+
+// Added to the mainnet ports of the services, so a node of each network can
+// run beside the others
+const (
+	regtestPortOffset = 10000
+	devnetPortOffset  = 30000
+)
+
 var once sync.Once
 var config *Config
+var network = NetworkMainnet
+
+// SelectNetwork sets the network returned by GetConfig, it must be called
+// before the first GetConfig
+func SelectNetwork(name string) error {
+	switch name {
+	case NetworkMainnet, NetworkDevnet, NetworkRegtest:
+		network = name
+		return nil
+	}
+	return fmt.Errorf("unknown network %s", name)
+}
 
 func GetConfig() (*Config) {
 	once.Do(func() {
@@ -154,11 +658,65 @@ func GetConfig() (*Config) {
 			User: userConfig,
 			Dev: devConfig,
 		}
+		switch network {
+		case NetworkDevnet:
+			applyDevnet(config)
+		case NetworkRegtest:
+			applyRegtest(config)
+		}
 	})
 
 	return config
 }
 
+// applyDevnet turns config into a private developer network mined like
+// mainnet, with funded development accounts and a faucet
+func applyDevnet(config *Config) {
+	config.Dev.Network = NetworkDevnet
+	config.Dev.ChainID = 3
+	config.Dev.Genesis.GenesisPrevHeadehash = []byte("Devnet")
+	config.Dev.DevAccounts = 10
+	config.Dev.DevAccountBalance = 1000000 * misc.ShorPerQuanta
+	config.Dev.FaucetAmount = 100 * misc.ShorPerQuanta
+
+	config.User.Node.PeerList = nil
+	offsetPorts(config.User, devnetPortOffset)
+}
+
+// applyRegtest turns config into a local developer network: no peers, no
+// NTP, a trivial fixed difficulty so blocks are mined instantly, and a
+// genesis of its own so it never mixes with mainnet
+func applyRegtest(config *Config) {
+	config.Dev.Network = NetworkRegtest
+	config.Dev.ChainID = 4
+	config.Dev.FixedDifficulty = true
+	config.Dev.Genesis.GenesisDifficulty = 1
+	config.Dev.Genesis.GenesisPrevHeadehash = []byte("Regtest")
+	// Generated blocks are one second apart, a burst of them quickly gets
+	// ahead of the clock
+	config.Dev.BlockLeadTimestamp = 365 * 24 * 60 * 60
+	config.Dev.DevAccounts = 10
+	config.Dev.DevAccountBalance = 1000000 * misc.ShorPerQuanta
+	config.Dev.FaucetAmount = 100 * misc.ShorPerQuanta
+
+	config.User.NTP.Enabled = false
+	config.User.Node.EnablePeerDiscovery = false
+	config.User.Node.PeerList = nil
+	offsetPorts(config.User, regtestPortOffset)
+}
+
+// offsetPorts adds offset to the ports of the P2P network and the services
+func offsetPorts(user *UserConfig, offset uint16) {
+	user.Node.LocalPort += offset
+	user.Node.PublicPort += offset
+	user.API.AdminAPI.Port += uint32(offset)
+	user.API.PublicAPI.Port += uint32(offset)
+	user.API.MiningAPI.Port += uint32(offset)
+	user.Debug.Port += offset
+	user.Health.Port += offset
+	user.BlockArchives.Port += offset
+}
+
 func GetUserConfig() (user *UserConfig) {
 	node := &NodeConfig {
 		EnablePeerDiscovery: true,
@@ -176,12 +734,32 @@ func GetUserConfig() (user *UserConfig) {
 		BanMinutes: 20,
 		MaxPeersLimit: 100,
 		MaxRedundantConnections: 5,
+		SeedMaxPeersLimit:       1000,
+
+		EnableEncryptedTransport: true,
+		Compression:              "snappy",
+
+		PeerIdleTimeout:       180,
+		PeerReadTimeout:       30,
+		PeerWriteTimeout:      30,
+		PeerMaxBytesPerSecond: 4 * 1024 * 1024,
+
+		MaxOutboundPeers:       8,
+		MaxAnchorPeers:         2,
+		MinAnchorUptime:        60 * 60,
+		MaxOutboundPerNetGroup: 2,
+
+		CutThroughRelay: true,
 	}
 
-	miner := &MinerConfig {
-		MiningEnabled: false,
-		MiningAddress: "",
-		MiningThreadCount: 0,
+	miner := &MinerConfig{
+		MiningEnabled:            false,
+		MiningAddress:            "",
+		MiningThreadCount:        0,
+		LongPollTimeout:          60,
+		LongPollFeeChangePercent: 10,
+		ExtraNoncePartitionBits:  8,
+		ExtraNonceLeaseMinutes:   10,
 	}
 
 	ephemeral := &EphemeralConfig {
@@ -189,6 +767,7 @@ func GetUserConfig() (user *UserConfig) {
 	}
 
 	ntp := &NTPConfig {
+		Enabled: true,
 		Retries: 6,
 		Servers: []string{"pool.ntp.org", "ntp.ubuntu.com"},
 		Refresh: 12 * 60 * 60,
@@ -198,7 +777,13 @@ func GetUserConfig() (user *UserConfig) {
 		TransactionPoolSize: 25000,
 		PendingTransactionPoolSize: 75000,
 		PendingTranactionPoolReserve: 750,
-		StaleTransactionThreshold: 15,
+		StaleTransactionThreshold:    15,
+		MaxQueuedPerAddress:          16,
+		MaxTxPerAddress:              64,
+		MaxTxPerPK:                   32,
+		MinRelayFeePerByte:           0,
+		MinFeePerByteByType:          make(map[string]uint64),
+		RejectLogSize:                1000,
 	}
 
 	adminAPI := &APIConfig {
@@ -231,6 +816,52 @@ func GetUserConfig() (user *UserConfig) {
 		MiningAPI: miningAPI,
 	}
 
+	deposits := &DepositsConfig{
+		Enabled:       false,
+		Confirmations: 10,
+	}
+
+	debug := &DebugConfig{
+		Enabled: false,
+		Host:    "127.0.0.1",
+		Port:    9010,
+	}
+
+	health := &HealthConfig{
+		Enabled:    false,
+		Host:       "0.0.0.0",
+		Port:       9011,
+		MinPeers:   1,
+		MaxSyncLag: 5,
+	}
+
+	tracingConfig := &TracingConfig{
+		Enabled:     false,
+		Exporter:    "otlp",
+		Endpoint:    "127.0.0.1:4317",
+		SampleRatio: 1,
+	}
+
+	webhooks := &WebhooksConfig{
+		DeadLetterFilename: "webhooks_deadletter.json",
+		Timeout:            10,
+		MaxRetries:         8,
+		InitialBackoff:     500,
+		MaxBackoff:         5 * 60 * 1000,
+	}
+
+	slaveService := &SlaveServiceConfig{
+		Enabled:          false,
+		SpendingFilename: "slave_spending.json",
+	}
+
+	walletConfig := &WalletConfig{
+		WalletFilename:   "wallet.json",
+		ContactsFilename: "contacts.enc",
+		AutoLockTimeout:  300,
+		SlaveService:     slaveService,
+	}
+
 	user = &UserConfig{
 		Node: node,
 		Miner: miner,
@@ -241,11 +872,69 @@ func GetUserConfig() (user *UserConfig) {
 		ChainStateTimeout: 180,
 		ChainStateBroadcastPeriod: 30,
 
+		MaxAutoReorgDepth: 100,
+
 		TransactionPool: transactionPool,
 
 		QrlDir: "~/.qrl",
 
+		LogLevel: "trace",
+
 		API: api,
+
+		Deposits: deposits,
+		Webhooks: webhooks,
+
+		BootstrapHeadersFilename: "bootstrap_headers.qrl",
+
+		DBCompactionInterval: 24 * 60,
+
+		Database:         &DatabaseConfig{Profile: DatabaseProfileMining},
+		BlockCompression: BlockCompressionOff,
+
+		BlockCacheSize:      256,
+		ValidationCacheSize: 1024,
+
+		ReadOnly:               false,
+		ReplicaRefreshInterval: 60,
+
+		SeedMode: false,
+
+		RelayOnly:   false,
+		RelayWindow: 10000,
+
+		AutoRepair:        true,
+		ConfirmationDepth: 10,
+
+		Debug: debug,
+
+		SlowBlock: &SlowBlockConfig{
+			ApplyTimeMillis:        2000,
+			StateReads:             5000,
+			StateWrites:            5000,
+			SignatureVerifications: 2000,
+			ReportsKept:            50,
+		},
+
+		Health: health,
+
+		BlockArchives: &BlockArchivesConfig{
+			Enabled:   false,
+			Interval:  10000,
+			Keep:      3,
+			Directory: "archives",
+			Host:      "0.0.0.0",
+			Port:      9012,
+		},
+
+		MinerStats: &MinerStatsConfig{
+			Interval: 3600,
+			Keep:     24 * 90,
+		},
+
+		Tracing: tracingConfig,
+
+		Wallet: walletConfig,
 	}
 
 	return user
@@ -255,14 +944,20 @@ func GetDevConfig() (dev *DevConfig) {
 	genesis := &GenesisConfig{
 		Version: "v0.63",
 		GenesisPrevHeadehash: []byte("Outside Context Problem"),
-		MaxCoinSupply: 105000000 * (10 ^ 9),
-		SuppliedCoins: 65000000 * (10 ^ 9),
-		GenesisDifficulty: 5000,
-		CoinbaseAddress: []byte("000000000000000000000000000000000000000000000000000000000000000000000000000000"),
-		GenesisTimestamp: 1524928900,
+		MaxCoinSupply:        105000000,
+		SuppliedCoins:        65000000,
+		GenesisDifficulty:    5000,
+		CoinbaseAddress:      []byte("000000000000000000000000000000000000000000000000000000000000000000000000000000"),
+		GenesisTimestamp:     1524928900,
 	}
 	transaction := &TransactionConfig{
-		MultiOutputLimit: 100,
+		MultiOutputLimit:        100,
+		ExpiryForkBlockNumber:   math.MaxUint64,
+		TimeLockForkBlockNumber: math.MaxUint64,
+		WeightPerByte:           1,
+		SignatureWeight:         4000,
+		MaxLatticePKSize:        4096,
+		MaxTxSize:               64 * 1024,
 	}
 
 	token := &TokenConfig{
@@ -271,10 +966,15 @@ func GetDevConfig() (dev *DevConfig) {
 	}
 
 	dev = &DevConfig{
+		Network: NetworkMainnet,
+		ChainID: 1,
 		Genesis: genesis,
 
 		BlocksPerEpoch:		 100,
 		BlockLeadTimestamp:   30,
+
+		MedianTimestampForkBlockNumber: math.MaxUint64,
+		MedianTimestampWindow:          11,
 		BlockMaxDrift:        15,
 		MaxFutureBlockLength: 256,
 		MaxMarginBlocKNumber: 32,
@@ -301,6 +1001,10 @@ func GetDevConfig() (dev *DevConfig) {
 		ChainFileDirectory:  "data",
 		WalletDatFilename:   "wallet.json",
 		BannedPeersFilename: "banned_peers.qrl",
+		AnchorsFilename:     "anchors.qrl",
+		ChainWALFilename:    "chain.wal",
+		ConfigFilename:      "config.yml",
+		ChainInfoFilename:   "chain.json",
 
 		Transaction: transaction,
 
@@ -309,13 +1013,16 @@ func GetDevConfig() (dev *DevConfig) {
 		NMeasurement: 30,
 		KP:           5,
 
-		NumberOfBlockAnalyze: 10,
-		SizeMultiplier:       1.1,
-		BlockMinSizeLimit:    1024 * 1024,
+		NumberOfBlockAnalyze:       10,
+		SizeMultiplier:             1.1,
+		BlockMinSizeLimit:          1024 * 1024,
+		BlockMaxWeight:             4 * 1024 * 1024,
+		BlockWeightForkBlockNumber: math.MaxUint64,
 
-		ShorPerQuanta: 10 ^ 9,
+		ShorPerQuanta: misc.ShorPerQuanta,
 
 		MaxReceivableBytes: 10 * 1024 * 1024,
+		MaxBytesOut:        10*1024*1024 - 1024,
 		SyncDelayMining:    60,
 
 		BlockTimeSeriesSize: 1440,
@@ -0,0 +1,203 @@
+package core
+
+import (
+	"errors"
+	"github.com/cyyber/go-qrl/log"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// The user settings can be overridden by ConfigFilename in QrlDir, a YAML
+// file whose keys are the lowercased field names of UserConfig, e.g.
+//
+//	loglevel: info
+//	node:
+//	  peerratelimit: 50
+//
+// It is read at startup and again on Reload, where only the operational
+// settings listed in reloadableSettings are applied, the other changed
+// settings being reported as requiring a restart. Reload never writes the
+// UserConfig the node was started with, it publishes a new snapshot returned
+// by Settings, which is what goroutines reading reloadable settings use.
+
+// reloadableSettings are read every time they are used, so they can be
+// changed while the node runs
+var reloadableSettings = map[string]bool{
+	"LogLevel":                            true,
+	"Node.PeerRateLimit":                  true,
+	"Node.PeerMaxBytesPerSecond":          true,
+	"Node.BanMinutes":                     true,
+	"Node.MaxOutboundPeers":               true,
+	"Node.MaxOutboundPerNetGroup":         true,
+	"TransactionPool.MinRelayFeePerByte":  true,
+	"TransactionPool.FeeExemptAddresses":  true,
+	"TransactionPool.MinFeePerByteByType": true,
+	"TransactionPool.DisabledTxTypes":     true,
+	"TransactionPool.MaxTxPerAddress":     true,
+	"TransactionPool.MaxTxPerPK":          true,
+	"TransactionPool.MaxQueuedPerAddress": true,
+	"Miner.LongPollTimeout":               true,
+	"Miner.LongPollFeeChangePercent":      true,
+	"ConfirmationDepth":                   true,
+	"SlowBlock.ApplyTimeMillis":           true,
+	"SlowBlock.StateReads":                true,
+	"SlowBlock.StateWrites":               true,
+	"SlowBlock.SignatureVerifications":    true,
+	"SlowBlock.ReportsKept":               true,
+	"BlockArchives.Keep":                  true,
+	"BlockArchives.Token":                 true,
+	"MinerStats.Keep":                     true,
+}
+
+var ErrNoConfigFile = errors.New("no configuration file")
+
+// reloadLock serializes the reloads, each one starting from the snapshot
+// published by the previous one
+var reloadLock sync.Mutex
+
+type ReloadReport struct {
+	// Settings changed by the file, as dotted UserConfig field paths
+	Applied        []string
+	RequireRestart []string
+}
+
+func (c *Config) configFile() string {
+	return path.Join(c.User.QrlDir, c.Dev.ConfigFilename)
+}
+
+// Settings returns the current user settings, including the changes applied
+// by Reload. The returned UserConfig must not be modified.
+func (c *Config) Settings() *UserConfig {
+	if user, ok := c.settings.Load().(*UserConfig); ok {
+		return user
+	}
+	return c.User
+}
+
+func copyUserConfig(user *UserConfig) (*UserConfig, error) {
+	data, err := yaml.Marshal(user)
+	if err != nil {
+		return nil, err
+	}
+	copied := &UserConfig{}
+	if err := yaml.Unmarshal(data, copied); err != nil {
+		return nil, err
+	}
+	return copied, nil
+}
+
+// readConfigFile returns a copy of the current user settings with the
+// values of the configuration file applied
+func (c *Config) readConfigFile() (*UserConfig, error) {
+	data, err := ioutil.ReadFile(c.configFile())
+	if os.IsNotExist(err) {
+		return nil, ErrNoConfigFile
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := copyUserConfig(c.Settings())
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, user); err != nil {
+		return nil, err
+	}
+	if pool := user.TransactionPool; pool != nil && pool.MinFeePerByte != 0 {
+		pool.MinRelayFeePerByte = pool.MinFeePerByte
+		pool.MinFeePerByte = 0
+	}
+	if _, err := log.ParseLevel(user.LogLevel); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// LoadFile applies the configuration file, if any, then the seed and
+// relay-only modes. It must be called once, by the program starting the
+// node, before any goroutine reads the config.
+func (c *Config) LoadFile() error {
+	user, err := c.readConfigFile()
+	if err != nil && err != ErrNoConfigFile {
+		return err
+	}
+	if err == nil {
+		c.User = user
+	}
+	c.applyNodeModes()
+	return c.applyLogLevel()
+}
+
+// Reload reads the configuration file again and publishes the changed
+// operational settings as a new snapshot
+func (c *Config) Reload() (*ReloadReport, error) {
+	reloadLock.Lock()
+	defer reloadLock.Unlock()
+
+	user, err := c.readConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	// Nothing else holds the snapshot until it's published, so its fields
+	// can be set freely
+	snapshot, err := copyUserConfig(c.Settings())
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ReloadReport{}
+	var diff func(prefix string, current reflect.Value, updated reflect.Value)
+	diff = func(prefix string, current reflect.Value, updated reflect.Value) {
+		if current.Kind() == reflect.Ptr {
+			if current.IsNil() || updated.IsNil() {
+				if current.IsNil() != updated.IsNil() {
+					report.RequireRestart = append(report.RequireRestart, prefix)
+				}
+				return
+			}
+			current = current.Elem()
+			updated = updated.Elem()
+		}
+		for i := 0; i < current.NumField(); i++ {
+			name := current.Type().Field(i).Name
+			if prefix != "" {
+				name = prefix + "." + name
+			}
+			field := current.Field(i)
+			if field.Kind() == reflect.Ptr && field.Type().Elem().Kind() == reflect.Struct {
+				diff(name, field, updated.Field(i))
+				continue
+			}
+			if reflect.DeepEqual(field.Interface(), updated.Field(i).Interface()) {
+				continue
+			}
+			if reloadableSettings[name] {
+				field.Set(updated.Field(i))
+				report.Applied = append(report.Applied, name)
+			} else {
+				report.RequireRestart = append(report.RequireRestart, name)
+			}
+		}
+	}
+	diff("", reflect.ValueOf(snapshot), reflect.ValueOf(user))
+	c.settings.Store(snapshot)
+
+	sort.Strings(report.Applied)
+	sort.Strings(report.RequireRestart)
+	return report, c.applyLogLevel()
+}
+
+func (c *Config) applyLogLevel() error {
+	lvl, err := log.ParseLevel(c.Settings().LogLevel)
+	if err != nil {
+		return err
+	}
+	log.SetLevel(lvl)
+	return nil
+}
@@ -0,0 +1,65 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+)
+
+var ErrTxNotFound = errors.New("transaction not found")
+
+// TxConfirmations is the position of a transaction relative to the
+// canonical tip
+type TxConfirmations struct {
+	// Blocks on top of the transaction, its own block included. 0 while
+	// the transaction is pending.
+	Confirmations uint64
+	BlockNumber   uint64
+	Pending       bool
+	// Confirmations reached ConfirmationDepth
+	ConfirmedAtDepth bool
+}
+
+// GetTransactionConfirmations computes the confirmations of txHash from the
+// canonical tip. The transaction metadata is only trusted once the
+// mainchain block at its height is found to contain the transaction, so a
+// transaction whose block was reorged out is reported pending if it went
+// back to the pool, and not found otherwise.
+func (c *Chain) GetTransactionConfirmations(txHash []byte) (*TxConfirmations, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if blockNumber, ok := c.canonicalTxBlockNumber(txHash); ok {
+		confirmations := c.lastBlock.BlockNumber() - blockNumber + 1
+		return &TxConfirmations{
+			Confirmations:    confirmations,
+			BlockNumber:      blockNumber,
+			ConfirmedAtDepth: confirmations >= c.config.Settings().ConfirmationDepth,
+		}, nil
+	}
+
+	for _, ti := range c.txPool.TransactionInfos() {
+		if bytes.Equal(ti.Transaction().Txhash(), txHash) {
+			return &TxConfirmations{Pending: true}, nil
+		}
+	}
+	return nil, ErrTxNotFound
+}
+
+// canonicalTxBlockNumber returns the number of the mainchain block holding
+// txHash, the Chain lock must be held
+func (c *Chain) canonicalTxBlockNumber(txHash []byte) (uint64, bool) {
+	tm, err := c.state.GetTxMetadata(txHash)
+	if err != nil || tm.BlockNumber > c.lastBlock.BlockNumber() {
+		return 0, false
+	}
+	block, err := c.getBlockByNumber(tm.BlockNumber)
+	if err != nil {
+		return 0, false
+	}
+	for _, tx := range block.Transactions() {
+		if bytes.Equal(tx.TransactionHash, txHash) {
+			return tm.BlockNumber, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,253 @@
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"github.com/cyyber/go-qrl/core/transactions"
+	"github.com/syndtr/goleveldb/leveldb"
+	"sort"
+)
+
+// Chain activity is summed up per UTC day of block timestamp as blocks are
+// applied and rolled back, so dashboards can chart it without scanning the
+// chain. Amounts are in Shor and only count Quanta: fees paid, and coins
+// moved by transfers. An address is active on a day when one of the
+// transactions of the day involves it, a marker per day and address
+// counting them; it's new when it gets its first transaction.
+//
+// Key: dailystats_ | uint64 day
+// Layout: uint64 blocks | uint64 fees | uint64 moved | uint64 active |
+// uint64 new | uint32 count | (sized transaction type | uint64 transactions)*
+//
+// Key: dailyactive_ | uint64 day | address
+// Layout: uint64 transactions
+
+var (
+	dailyStatsPrefix  = []byte("dailystats_")
+	dailyActivePrefix = []byte("dailyactive_")
+)
+
+const secondsPerDay = 24 * 60 * 60
+
+var ErrInvalidDailyStats = errors.New("invalid daily stats record")
+
+type DailyStats struct {
+	// Unix time of the start of the day
+	Day    uint64
+	Blocks uint64
+
+	TxCount       uint64
+	TxCountByType map[string]uint64
+
+	Fees            uint64
+	CoinsMoved      uint64
+	ActiveAddresses uint64
+	NewAddresses    uint64
+}
+
+func dailyStatsKey(day uint64) []byte {
+	key := make([]byte, len(dailyStatsPrefix)+8)
+	copy(key, dailyStatsPrefix)
+	binary.BigEndian.PutUint64(key[len(dailyStatsPrefix):], day)
+	return key
+}
+
+func dailyActiveKey(day uint64, address []byte) []byte {
+	key := make([]byte, len(dailyActivePrefix)+8, len(dailyActivePrefix)+8+len(address))
+	copy(key, dailyActivePrefix)
+	binary.BigEndian.PutUint64(key[len(dailyActivePrefix):], day)
+	return append(key, address...)
+}
+
+func (d *DailyStats) encode() []byte {
+	var buf bytes.Buffer
+	for _, field := range []uint64{d.Blocks, d.Fees, d.CoinsMoved, d.ActiveAddresses, d.NewAddresses} {
+		binary.Write(&buf, binary.BigEndian, field)
+	}
+
+	txTypes := make([]string, 0, len(d.TxCountByType))
+	for txType, n := range d.TxCountByType {
+		if n > 0 {
+			txTypes = append(txTypes, txType)
+		}
+	}
+	sort.Strings(txTypes)
+	binary.Write(&buf, binary.BigEndian, uint32(len(txTypes)))
+	for _, txType := range txTypes {
+		writeSized(&buf, []byte(txType))
+		binary.Write(&buf, binary.BigEndian, d.TxCountByType[txType])
+	}
+	return buf.Bytes()
+}
+
+func decodeDailyStats(day uint64, data []byte) (*DailyStats, error) {
+	r := bytes.NewReader(data)
+	d := &DailyStats{Day: day * secondsPerDay, TxCountByType: make(map[string]uint64)}
+
+	for _, field := range []*uint64{&d.Blocks, &d.Fees, &d.CoinsMoved, &d.ActiveAddresses, &d.NewAddresses} {
+		if err := binary.Read(r, binary.BigEndian, field); err != nil {
+			return nil, ErrInvalidDailyStats
+		}
+	}
+	var count uint32
+	if err := binary.Read(r, binary.BigEndian, &count); err != nil {
+		return nil, ErrInvalidDailyStats
+	}
+	for i := uint32(0); i < count; i++ {
+		txType, err := readUndoField(r)
+		if err != nil {
+			return nil, ErrInvalidDailyStats
+		}
+		var n uint64
+		if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+			return nil, ErrInvalidDailyStats
+		}
+		d.TxCountByType[string(txType)] = n
+		d.TxCount += n
+	}
+	if r.Len() != 0 {
+		return nil, ErrInvalidDailyStats
+	}
+	return d, nil
+}
+
+// dailyStatsUpdate accumulates the changes a block makes to the stats of
+// its day before they're written with the block batch
+type dailyStatsUpdate struct {
+	s      *State
+	day    uint64
+	revert bool
+
+	stats  *DailyStats
+	active map[string]uint64
+	order  []string
+}
+
+func newDailyStatsUpdate(s *State, block *Block, revert bool) *dailyStatsUpdate {
+	day := uint64(block.Timestamp()) / secondsPerDay
+	u := &dailyStatsUpdate{
+		s:      s,
+		day:    day,
+		revert: revert,
+		stats:  &DailyStats{Day: day * secondsPerDay, TxCountByType: make(map[string]uint64)},
+		active: make(map[string]uint64),
+	}
+	if value, err := s.db.Get(dailyStatsKey(day)); err == nil {
+		if stored, err := decodeDailyStats(day, value); err == nil {
+			u.stats = stored
+		}
+	}
+	u.change(&u.stats.Blocks, 1)
+	return u
+}
+
+func (u *dailyStatsUpdate) change(value *uint64, amount uint64) {
+	if !u.revert {
+		*value += amount
+	} else if amount > *value {
+		*value = 0
+	} else {
+		*value -= amount
+	}
+}
+
+// markActive counts a transaction of address, the address becoming active
+// with its first transaction of the day
+func (u *dailyStatsUpdate) markActive(address []byte) {
+	n, ok := u.active[string(address)]
+	if !ok {
+		u.order = append(u.order, string(address))
+		if value, err := u.s.db.Get(dailyActiveKey(u.day, address)); err == nil && len(value) == 8 {
+			n = binary.BigEndian.Uint64(value)
+		}
+	}
+	before := n
+	u.change(&n, 1)
+	u.active[string(address)] = n
+	if before == 0 && n > 0 {
+		u.stats.ActiveAddresses++
+	}
+	if before > 0 && n == 0 && u.stats.ActiveAddresses > 0 {
+		u.stats.ActiveAddresses--
+	}
+}
+
+// addTx adds tx to the stats of the day, or removes it when reverting
+func (u *dailyStatsUpdate) addTx(tx transactions.TransactionInterface) {
+	txType := TransactionTypeName(tx.PBData())
+	n := u.stats.TxCountByType[txType]
+	u.change(&n, 1)
+	u.stats.TxCountByType[txType] = n
+
+	seen := make(map[string]bool)
+	for _, address := range historyAddresses(tx) {
+		if !seen[string(address)] {
+			seen[string(address)] = true
+			u.markActive(address)
+		}
+	}
+
+	if t, ok := tx.(*transactions.TransferTransaction); ok {
+		u.change(&u.stats.CoinsMoved, t.TotalAmounts())
+	}
+	if _, ok := tx.(*transactions.CoinBase); !ok {
+		u.change(&u.stats.Fees, tx.Fee())
+	}
+}
+
+// write stores the stats of the day and the activity markers, created is
+// the number of addresses which got their first transaction with the block
+func (u *dailyStatsUpdate) write(created uint64, batch *leveldb.Batch) error {
+	u.change(&u.stats.NewAddresses, created)
+	for _, address := range u.order {
+		key := dailyActiveKey(u.day, []byte(address))
+		if u.active[address] == 0 {
+			u.s.deleteKey(key, batch)
+			continue
+		}
+		value := make([]byte, 8)
+		binary.BigEndian.PutUint64(value, u.active[address])
+		if err := u.s.db.Put(key, value, batch); err != nil {
+			return err
+		}
+	}
+	if u.stats.Blocks == 0 {
+		u.s.deleteKey(dailyStatsKey(u.day), batch)
+		return nil
+	}
+	return u.s.db.Put(dailyStatsKey(u.day), u.stats.encode(), batch)
+}
+
+// GetDailyStats returns the stats of the days holding the unix times from
+// to to, oldest first. Days without blocks are left out.
+func (s *State) GetDailyStats(from uint64, to uint64) ([]*DailyStats, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var days []*DailyStats
+	var decodeErr error
+	err := s.db.IteratePrefix(dailyStatsPrefix, dailyStatsKey(from/secondsPerDay), func(key []byte, value []byte) bool {
+		day := binary.BigEndian.Uint64(key[len(dailyStatsPrefix):])
+		if day > to/secondsPerDay {
+			return false
+		}
+		d, err := decodeDailyStats(day, value)
+		if err != nil {
+			decodeErr = err
+			return false
+		}
+		days = append(days, d)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return days, decodeErr
+}
+
+// GetDailyStats returns the chain activity of the days between the unix
+// times from and to
+func (c *Chain) GetDailyStats(from uint64, to uint64) ([]*DailyStats, error) {
+	return c.state.GetDailyStats(from, to)
+}
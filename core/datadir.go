@@ -0,0 +1,224 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/cyyber/go-qrl/log"
+	"github.com/cyyber/go-qrl/misc"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"syscall"
+)
+
+// Every network keeps its chain data, the database, the WAL, the block
+// archives, the anchors and the ban list, in a directory of its own:
+// ChainFileDirectory/<network> in QrlDir. The chain ID and genesis
+// headerhash are recorded in it when it's first used and a directory
+// recording another chain is refused, so a misconfigured node never writes
+// the blocks of a network into the database of another one. The files of
+// the former layout are moved to the mainnet directory.
+
+var (
+	ErrChainIDMismatch = errors.New("data directory belongs to another chain")
+	ErrGenesisMismatch = errors.New("data directory holds another genesis block")
+)
+
+// legacyDBName is the database of the former layout, opened relative to the
+// working directory the node was started from
+const legacyDBName = "qrl"
+
+type chainInfo struct {
+	Network     string `json:"network"`
+	ChainID     uint64 `json:"chain_id"`
+	GenesisHash string `json:"genesis_hash"`
+}
+
+// DataDir returns the chain data directory of the network
+func (c *Config) DataDir() string {
+	return path.Join(c.User.QrlDir, c.Dev.ChainFileDirectory, c.Dev.Network)
+}
+
+func (c *Config) chainDBPath() string {
+	return path.Join(c.DataDir(), c.Dev.DBName)
+}
+
+// OpenDataDir checks that the data directory belongs to the configured
+// chain, recording the chain on first use. Read-only replicas only check
+// it, their directory being copied from the primary.
+func (c *Config) OpenDataDir(genesisHeaderHash []byte, log log.Logger) error {
+	filename := path.Join(c.DataDir(), c.Dev.ChainInfoFilename)
+	expected := &chainInfo{
+		Network:     c.Dev.Network,
+		ChainID:     c.Dev.ChainID,
+		GenesisHash: misc.Bin2HStr(genesisHeaderHash),
+	}
+
+	data, err := ioutil.ReadFile(filename)
+	if err == nil {
+		recorded := &chainInfo{}
+		if err := json.Unmarshal(data, recorded); err != nil {
+			return fmt.Errorf("%s: %s", filename, err)
+		}
+		if recorded.ChainID != expected.ChainID {
+			return fmt.Errorf("%s: %s is %s chain %d, configured %s chain %d", ErrChainIDMismatch, c.DataDir(),
+				recorded.Network, recorded.ChainID, expected.Network, expected.ChainID)
+		}
+		if recorded.GenesisHash != expected.GenesisHash {
+			return fmt.Errorf("%s: %s has genesis %s, configured %s", ErrGenesisMismatch, c.DataDir(),
+				recorded.GenesisHash, expected.GenesisHash)
+		}
+		return nil
+	}
+	if !os.IsNotExist(err) {
+		return err
+	}
+	if c.User.ReadOnly {
+		return nil
+	}
+
+	if err := os.MkdirAll(c.DataDir(), 0700); err != nil {
+		return err
+	}
+	if err := c.moveLegacyData(log); err != nil {
+		return err
+	}
+	data, err = json.MarshalIndent(expected, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, data, 0600)
+}
+
+// legacyDBPaths returns the places the database of the former layout may
+// be in: the working directory, QrlDir and the directory of the executable
+func (c *Config) legacyDBPaths() []string {
+	var paths []string
+	if wd, err := os.Getwd(); err == nil {
+		paths = append(paths, path.Join(wd, legacyDBName))
+	}
+	paths = append(paths, path.Join(c.User.QrlDir, legacyDBName))
+	if executable, err := os.Executable(); err == nil {
+		paths = append(paths, path.Join(filepath.Dir(executable), legacyDBName))
+	}
+	return paths
+}
+
+// moveLegacyData moves the database, WAL, anchors and ban list of the
+// former layout to the mainnet data directory. The other networks shared
+// the database with mainnet, they start over.
+func (c *Config) moveLegacyData(log log.Logger) error {
+	if c.Dev.Network != NetworkMainnet {
+		return nil
+	}
+	moves := [][2]string{
+		{path.Join(c.User.QrlDir, c.Dev.ChainWALFilename), path.Join(c.DataDir(), c.Dev.ChainWALFilename)},
+		{path.Join(c.User.QrlDir, c.Dev.AnchorsFilename), path.Join(c.DataDir(), c.Dev.AnchorsFilename)},
+		{path.Join(c.User.QrlDir, c.Dev.BannedPeersFilename), path.Join(c.DataDir(), c.Dev.BannedPeersFilename)},
+	}
+	legacyDB := ""
+	for _, candidate := range c.legacyDBPaths() {
+		if _, err := os.Stat(path.Join(candidate, "CURRENT")); err == nil {
+			legacyDB = candidate
+			break
+		}
+	}
+	if legacyDB != "" {
+		moves = append(moves, [2]string{legacyDB, c.chainDBPath()})
+	} else {
+		log.Info("No database of an earlier version found, starting a new chain database",
+			"searched", c.legacyDBPaths(), "database", c.chainDBPath())
+	}
+
+	for _, move := range moves {
+		if _, err := os.Stat(move[0]); err != nil {
+			continue
+		}
+		if _, err := os.Stat(move[1]); err == nil {
+			continue
+		}
+		log.Info("Moving to the data directory", "from", move[0], "to", move[1])
+		if err := moveFile(move[0], move[1]); err != nil {
+			return fmt.Errorf("failed to move %s to %s: %s", move[0], move[1], err)
+		}
+	}
+	return nil
+}
+
+// moveFile renames from to to, copying and removing it when they're on
+// different filesystems
+func moveFile(from string, to string) error {
+	err := os.Rename(from, to)
+	if linkErr, ok := err.(*os.LinkError); !ok || linkErr.Err != syscall.EXDEV {
+		return err
+	}
+
+	tmp := to + ".tmp"
+	os.RemoveAll(tmp)
+	if err := copyTree(from, tmp); err != nil {
+		os.RemoveAll(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, to); err != nil {
+		return err
+	}
+	return os.RemoveAll(from)
+}
+
+// copyTree copies the file or directory from to to
+func copyTree(from string, to string) error {
+	return filepath.Walk(from, func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relative, err := filepath.Rel(from, name)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(to, relative)
+		if info.IsDir() {
+			return os.MkdirAll(target, info.Mode().Perm())
+		}
+		return copyFile(name, target, info.Mode().Perm())
+	})
+}
+
+func copyFile(from string, to string, mode os.FileMode) error {
+	in, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(to, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// CheckGenesis returns ErrGenesisMismatch when the database holds another
+// genesis block than genesisHeaderHash
+func (s *State) CheckGenesis(genesisHeaderHash []byte) error {
+	mapping, err := s.GetBlockNumberMapping(0)
+	if err != nil || mapping == nil {
+		return nil
+	}
+	if !bytes.Equal(mapping.Headerhash, genesisHeaderHash) {
+		return fmt.Errorf("%s: database genesis %s, configured %s", ErrGenesisMismatch,
+			misc.Bin2HStr(mapping.Headerhash), misc.Bin2HStr(genesisHeaderHash))
+	}
+	return nil
+}
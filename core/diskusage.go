@@ -0,0 +1,175 @@
+package core
+
+import (
+	"bytes"
+	"github.com/cyyber/go-qrl/diagnostics"
+	"github.com/cyyber/go-qrl/log"
+	"github.com/cyyber/go-qrl/misc"
+	"sync"
+	"time"
+)
+
+const (
+	KeyspaceBlocks  = "blocks"
+	KeyspaceState   = "state"
+	KeyspaceIndexes = "indexes"
+	KeyspaceUndo    = "undo"
+	KeyspaceOther   = "other"
+)
+
+var (
+	stateKeys = [][]byte{
+		[]byte("blockheight"),
+		[]byte("TotalCoinSupply"),
+		[]byte("LastTransactions"),
+		[]byte(prunedHeightKey),
+	}
+	indexPrefixes = [][]byte{
+		[]byte("metadata_"),
+		tokenMetadataPrefix,
+		[]byte("tokenholder_"),
+		[]byte("tokentransfer_"),
+		[]byte("addrstats_"),
+		[]byte("burn_"),
+		[]byte("bootstrap_"),
+		reindexHistoryPrefix,
+		receiptPrefix,
+		minerStatsPrefix,
+		dailyStatsPrefix,
+		dailyActivePrefix,
+	}
+	statePrefixes = [][]byte{
+		otsPagePrefix,
+	}
+	undoKeys = [][]byte{
+		[]byte("fork_state"),
+	}
+	undoPrefix = []byte("undo_")
+)
+
+// classifyKey maps a database key to its keyspace. Blocks and transaction
+// metadata are both keyed by a bare 32 bytes hash, so they are reported
+// together as blocks.
+func classifyKey(key []byte) string {
+	for _, k := range stateKeys {
+		if bytes.Equal(key, k) {
+			return KeyspaceState
+		}
+	}
+	for _, prefix := range statePrefixes {
+		if bytes.HasPrefix(key, prefix) {
+			return KeyspaceState
+		}
+	}
+	for _, k := range undoKeys {
+		if bytes.Equal(key, k) {
+			return KeyspaceUndo
+		}
+	}
+	if bytes.HasPrefix(key, undoPrefix) {
+		return KeyspaceUndo
+	}
+	for _, prefix := range indexPrefixes {
+		if bytes.HasPrefix(key, prefix) {
+			return KeyspaceIndexes
+		}
+	}
+
+	switch len(key) {
+	case 8:
+		// Block number mapping
+		return KeyspaceIndexes
+	case 32:
+		return KeyspaceBlocks
+	case misc.AddressSize:
+		return KeyspaceState
+	}
+	return KeyspaceOther
+}
+
+type DiskUsage struct {
+	// Size of the database files on disk
+	TotalSize uint64
+	// Uncompressed size of keys and values per keyspace
+	Keyspaces map[string]uint64
+}
+
+// DiskUsage scans the whole database, so it is only meant for operators
+func (s *State) DiskUsage() (*DiskUsage, error) {
+	totalSize, err := s.db.DiskSize()
+	if err != nil {
+		return nil, err
+	}
+	keyspaces, err := s.db.KeyspaceSizes(classifyKey)
+	if err != nil {
+		return nil, err
+	}
+	return &DiskUsage{
+		TotalSize: totalSize,
+		Keyspaces: keyspaces,
+	}, nil
+}
+
+func (s *State) Compact() error {
+	return s.db.Compact()
+}
+
+// Compactor compacts the database every DBCompactionInterval minutes
+type Compactor struct {
+	state *State
+	log   log.Logger
+
+	interval time.Duration
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewCompactor(state *State, config *Config, log log.Logger) *Compactor {
+	return &Compactor{
+		state:    state,
+		log:      log,
+		interval: time.Duration(config.User.DBCompactionInterval) * time.Minute,
+	}
+}
+
+func (c *Compactor) Start() {
+	c.quit = make(chan struct{})
+	if c.interval == 0 {
+		return
+	}
+	c.wg.Add(1)
+	diagnostics.Go("compaction", c.run)
+}
+
+func (c *Compactor) Stop() {
+	close(c.quit)
+	c.wg.Wait()
+}
+
+func (c *Compactor) run() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.quit:
+			return
+		case <-ticker.C:
+			c.Compact()
+		}
+	}
+}
+
+func (c *Compactor) Compact() error {
+	start := time.Now()
+	c.log.Info("Compacting database")
+	if err := c.state.Compact(); err != nil {
+		c.log.Warn("Database compaction failed", "error", err)
+		return err
+	}
+	c.log.Info("Database compacted", "duration", time.Since(start))
+	return nil
+}
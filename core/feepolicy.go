@@ -0,0 +1,127 @@
+package core
+
+import (
+	"github.com/cyyber/go-qrl/core/pool"
+	"github.com/cyyber/go-qrl/core/transactions"
+	"github.com/cyyber/go-qrl/misc"
+	"sort"
+	"sync"
+)
+
+// The fee policy of a transaction type is applied when transactions enter
+// the pool and when templates are built, never by block validation, so
+// blocks mined by nodes with another policy are still accepted.
+
+// TxTypeMinFeePerByte returns the minimum fee per byte of txType, the
+// MinFeePerByteByType entry if any, MinRelayFeePerByte otherwise
+func (p *TransactionPoolConfig) TxTypeMinFeePerByte(txType string) uint64 {
+	if minFee, ok := p.MinFeePerByteByType[txType]; ok {
+		return minFee
+	}
+	return p.MinRelayFeePerByte
+}
+
+func (p *TransactionPoolConfig) IsTxTypeDisabled(txType string) bool {
+	for _, disabled := range p.DisabledTxTypes {
+		if disabled == txType {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *TransactionPoolConfig) IsFeeExempt(addrFrom []byte) bool {
+	if len(p.FeeExemptAddresses) == 0 {
+		return false
+	}
+	qaddress := misc.Qaddress(addrFrom)
+	for _, exempt := range p.FeeExemptAddresses {
+		if exempt == qaddress {
+			return true
+		}
+	}
+	return false
+}
+
+type TxTypePolicyStats struct {
+	Type string
+	// Refused by the pool as the type is disabled
+	RejectedDisabled uint64
+	// Refused by the pool as the fee is below the minimum of the type
+	RejectedFeeTooLow uint64
+	// Left out of block templates by the policy, transactions admitted
+	// before the policy changed
+	SkippedInTemplate uint64
+}
+
+type TxTypePolicyMetrics struct {
+	lock sync.Mutex
+
+	stats map[string]*TxTypePolicyStats
+}
+
+func NewTxTypePolicyMetrics() *TxTypePolicyMetrics {
+	return &TxTypePolicyMetrics{stats: make(map[string]*TxTypePolicyStats)}
+}
+
+// Update changes the counters of txType through fn
+func (m *TxTypePolicyMetrics) Update(txType string, fn func(stats *TxTypePolicyStats)) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	stats, ok := m.stats[txType]
+	if !ok {
+		stats = &TxTypePolicyStats{Type: txType}
+		m.stats[txType] = stats
+	}
+	fn(stats)
+}
+
+// Stats returns a copy of the counters, sorted by type
+func (m *TxTypePolicyMetrics) Stats() []*TxTypePolicyStats {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	result := make([]*TxTypePolicyStats, 0, len(m.stats))
+	for _, stats := range m.stats {
+		copied := *stats
+		result = append(result, &copied)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Type < result[j].Type
+	})
+	return result
+}
+
+// includableByPolicy tells if the fee policy lets tx into a block template
+func (c *Chain) includableByPolicy(tx transactions.TransactionInterface) bool {
+	poolConfig := c.config.Settings().TransactionPool
+	txType := TransactionTypeName(tx.PBData())
+	if poolConfig.IsTxTypeDisabled(txType) {
+		pool.TxTypePolicyMetrics().Update(txType, func(stats *TxTypePolicyStats) {
+			stats.SkippedInTemplate++
+		})
+		return false
+	}
+
+	// The global MinRelayFeePerByte is only a relay policy, templates only
+	// apply the minimum configured for the type
+	minFee, ok := poolConfig.MinFeePerByteByType[txType]
+	size := uint64(tx.Size())
+	if !ok || minFee == 0 || size == 0 || poolConfig.IsFeeExempt(tx.AddrFrom()) {
+		return true
+	}
+	if tx.Fee()/size < minFee {
+		pool.TxTypePolicyMetrics().Update(txType, func(stats *TxTypePolicyStats) {
+			stats.SkippedInTemplate++
+		})
+		return false
+	}
+	return true
+}
+
+// TxTypePolicyStats returns the counters of the transactions refused by the
+// pool or left out of templates by the fee policy, per transaction type
+func (c *Chain) TxTypePolicyStats() []*TxTypePolicyStats {
+	return pool.TxTypePolicyMetrics().Stats()
+}
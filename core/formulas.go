@@ -8,19 +8,19 @@ import (
 
 func CalcCoeff(coinRemainingAtGenesis uint64) float64 {
 	//START_DATE = datetime.datetime(2018, 4, 1, 0, 0, 0)
-	START_DATE, err := time.Parse("YYYY-MM-DD", "2018-04-01")
+	START_DATE, err := time.Parse("2006-01-02", "2018-04-01")
 	if err != nil {
-
+		panic(err)
 	}
 
-	END_DATE, err := time.Parse("YYYY-MM-DD", "2218-04-01")
+	END_DATE, err := time.Parse("2006-01-02", "2218-04-01")
 	if err != nil {
-
+		panic(err)
 	}
 
 	c := END_DATE.Sub(START_DATE)
 	c.Nanoseconds()
-	TOTAL_MINUTES := c.Nanoseconds()/(60 * 10^9)
+	TOTAL_MINUTES := c.Nanoseconds() / int64(time.Minute)
 
 	// At 1 block per minute
 	TOTAL_BLOCKS := TOTAL_MINUTES
@@ -0,0 +1,82 @@
+package core
+
+import (
+	"encoding/binary"
+	"github.com/cyyber/go-qrl/generated"
+	"github.com/cyyber/go-qrl/pow"
+	"github.com/golang/protobuf/proto"
+)
+
+// HeaderTemplate is the mutable form of a block header, owned by a single
+// goroutine while the header is built and mined. Seal returns the
+// immutable BlockHeader.
+type HeaderTemplate struct {
+	blockHeader *generated.BlockHeader
+
+	config *Config
+}
+
+func NewHeaderTemplate(blockNumber uint64, prevBlockHeaderHash []byte, prevBlockTimestamp uint64, merkleRoot []byte, feeReward uint64, timestamp uint64, config *Config) *HeaderTemplate {
+	blockHeader := &generated.BlockHeader{
+		BlockNumber:    blockNumber,
+		HashHeaderPrev: prevBlockHeaderHash,
+		MerkleRoot:     merkleRoot,
+		RewardFee:      feeReward,
+		RewardBlock:    BlockRewardCalc(blockNumber, config),
+	}
+
+	if blockNumber != 0 {
+		blockHeader.TimestampSeconds = timestamp
+		// If current block timestamp is less than or equals to the previous block timestamp
+		// then set current block timestamp 1 sec higher than prev_block_timestamp
+		if blockHeader.TimestampSeconds <= prevBlockTimestamp {
+			blockHeader.TimestampSeconds = prevBlockTimestamp + 1
+		}
+	} else {
+		blockHeader.TimestampSeconds = prevBlockTimestamp // Set timestamp for genesis block
+	}
+
+	return &HeaderTemplate{blockHeader: blockHeader, config: config}
+}
+
+func (t *HeaderTemplate) BlockNumber() uint64 {
+	return t.blockHeader.BlockNumber
+}
+
+func (t *HeaderTemplate) SetNonces(miningNonce uint32, extraNonce uint64) *HeaderTemplate {
+	t.blockHeader.MiningNonce = miningNonce
+	t.blockHeader.ExtraNonce = extraNonce
+	return t
+}
+
+// SetMiningNonceFromBlob reads the nonces of a blob mined by an external
+// miner
+func (t *HeaderTemplate) SetMiningNonceFromBlob(blob []byte) *HeaderTemplate {
+	nonceOffset := t.config.Dev.MiningNonceOffset
+	extraNonceOffset := t.config.Dev.ExtraNonceOffset
+	miningNonce := binary.BigEndian.Uint32(blob[nonceOffset : nonceOffset+4])
+	extraNonce := binary.BigEndian.Uint64(blob[extraNonceOffset : extraNonceOffset+8])
+	return t.SetNonces(miningNonce, extraNonce)
+}
+
+func (t *HeaderTemplate) UpdateMerkleRoot(merkleRoot []byte) *HeaderTemplate {
+	t.blockHeader.MerkleRoot = merkleRoot
+	return t
+}
+
+func (t *HeaderTemplate) MiningBlob() []byte {
+	return miningBlob(t.blockHeader, t.config)
+}
+
+// HeaderHash computes the headerhash of the header with the current nonces
+func (t *HeaderTemplate) HeaderHash() []byte {
+	return pow.GetQryptonight().Hash(t.MiningBlob())
+}
+
+// Seal returns the header with its headerhash computed. The template can
+// still be changed, the sealed header holds a copy.
+func (t *HeaderTemplate) Seal() *BlockHeader {
+	blockHeader := proto.Clone(t.blockHeader).(*generated.BlockHeader)
+	blockHeader.HashHeader = t.HeaderHash()
+	return &BlockHeader{blockHeader: blockHeader, config: t.config}
+}
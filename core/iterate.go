@@ -0,0 +1,64 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/cyyber/go-qrl/core/transactions"
+)
+
+var (
+	// ErrStopIteration is returned by an iteration callback to stop early,
+	// the iteration then returns nil
+	ErrStopIteration  = errors.New("stop iteration")
+	ErrIterationReorg = errors.New("mainchain changed during iteration")
+)
+
+// IterateBlocks calls fn with the mainchain blocks from, to included, in
+// order. Blocks are read one at a time without going through the block
+// cache, so memory stays bounded whatever the range and recent blocks
+// aren't evicted from the cache. The Chain lock isn't held while fn runs,
+// ErrIterationReorg is returned if the mainchain is reorganized under the
+// iteration.
+func (c *Chain) IterateBlocks(from uint64, to uint64, fn func(block *Block) error) error {
+	if height := c.Height(); to > height {
+		to = height
+	}
+
+	var lastHeaderHash []byte
+	for blockNumber := from; blockNumber <= to; blockNumber++ {
+		block, err := c.state.GetBlockByNumber(blockNumber)
+		if err != nil {
+			return fmt.Errorf("failed to read block #%d: %s", blockNumber, err)
+		}
+		if lastHeaderHash != nil && !bytes.Equal(block.PrevHeaderHash(), lastHeaderHash) {
+			return ErrIterationReorg
+		}
+		lastHeaderHash = block.HeaderHash()
+
+		if err := fn(block); err != nil {
+			if err == ErrStopIteration {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// IterateTransactions calls fn with the transactions of the mainchain
+// blocks from, to included, in order, coinbase transactions included
+func (c *Chain) IterateTransactions(from uint64, to uint64, fn func(block *Block, tx transactions.TransactionInterface) error) error {
+	return c.IterateBlocks(from, to, func(block *Block) error {
+		for _, protoTX := range block.Transactions() {
+			tx := transactions.ProtoToTransaction(protoTX)
+			if tx == nil {
+				continue
+			}
+			if err := fn(block, tx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,99 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/cyyber/go-qrl/core/pool"
+	"github.com/cyyber/go-qrl/events"
+	"github.com/cyyber/go-qrl/misc"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A long-poll request carries the id of the template the miner works on and
+// is answered once a better template is available: the tip changed, or the
+// pool allows fees higher by LongPollFeeChangePercent. The pool is checked
+// once per longPollInterval as it publishes no events.
+
+const longPollInterval = time.Second
+
+var ErrInvalidLongPollID = errors.New("invalid long-poll id")
+
+// LongPollID identifies the work of a template by its parent and its fees
+func (t *BlockTemplate) LongPollID() string {
+	return fmt.Sprintf("%s-%d", misc.Bin2HStr(t.Block.PrevHeaderHash()), t.Fees)
+}
+
+func parseLongPollID(id string) ([]byte, uint64, error) {
+	fields := strings.Split(id, "-")
+	if len(fields) != 2 {
+		return nil, 0, ErrInvalidLongPollID
+	}
+	prevHeaderHash, err := misc.HStr2Bin(fields[0])
+	if err != nil {
+		return nil, 0, ErrInvalidLongPollID
+	}
+	fees, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return nil, 0, ErrInvalidLongPollID
+	}
+	return prevHeaderHash, fees, nil
+}
+
+func (c *Chain) tipHeaderHash() []byte {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.lastBlock.HeaderHash()
+}
+
+// WaitBlockTemplate returns a template for minerAddress once it improves on
+// the template identified by longPollID, or the current one when ctx is
+// done. An empty longPollID returns immediately.
+func (c *Chain) WaitBlockTemplate(ctx context.Context, minerAddress []byte, longPollID string) (*BlockTemplate, error) {
+	if longPollID == "" {
+		return c.CreateBlockTemplate(minerAddress, c.clock.Time())
+	}
+	prevHeaderHash, fees, err := parseLongPollID(longPollID)
+	if err != nil {
+		return nil, err
+	}
+	minFees := fees + fees*c.config.Settings().Miner.LongPollFeeChangePercent/100
+
+	var tipChanged <-chan *events.Event
+	if c.eventBus != nil {
+		subscription := c.eventBus.Subscribe(events.TopicNewBlock, events.TopicReorg)
+		defer subscription.Unsubscribe()
+		tipChanged = subscription.Events()
+	}
+	ticker := time.NewTicker(longPollInterval)
+	defer ticker.Stop()
+
+	var snapshot *pool.Snapshot
+	for {
+		if !bytes.Equal(c.tipHeaderHash(), prevHeaderHash) {
+			return c.CreateBlockTemplate(minerAddress, c.clock.Time())
+		}
+		// Templates are only rebuilt when the pool changed since the last one
+		if current := c.txPool.Snapshot(); current != snapshot {
+			snapshot = current
+			template, err := c.CreateBlockTemplate(minerAddress, c.clock.Time())
+			if err != nil {
+				return nil, err
+			}
+			if template.Fees > fees && template.Fees >= minFees {
+				return template, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return c.CreateBlockTemplate(minerAddress, c.clock.Time())
+		case <-tipChanged:
+		case <-ticker.C:
+		}
+	}
+}
@@ -1,17 +1,15 @@
 package metadata
 
 import (
+	"bytes"
 	"github.com/cyyber/go-qrl/generated"
 	"github.com/cyyber/go-qrl/log"
-	"reflect"
 	"github.com/golang/protobuf/proto"
-	"github.com/cyyber/go-qrl/core"
 )
 
 type BlockMetaData struct {
 	data *generated.BlockMetaData
 	log log.Logger
-	config *core.Config
 }
 
 func (b *BlockMetaData) PBData() *generated.BlockMetaData {
@@ -50,27 +48,29 @@ func (b *BlockMetaData) SetTotalDifficulty(value []byte) {
 
 func (b *BlockMetaData) AddChildHeaderHash(ChildHeaderHash []byte) {
 	for _, headerHash := range b.data.ChildHeaderhashes {
-		if reflect.DeepEqual(headerHash, ChildHeaderHash) {
+		if bytes.Equal(headerHash, ChildHeaderHash) {
 			return
 		}
 	}
 	b.data.ChildHeaderhashes = append(b.data.ChildHeaderhashes, ChildHeaderHash)
 }
 
-func (b *BlockMetaData) UpdateLastHeaderHashes(parentLastNHeaderHashes [][]byte, lastHeaderHash []byte) {
-	b.data.Last_NHeaderhashes = append(parentLastNHeaderHashes, lastHeaderHash)
+// UpdateLastHeaderHashes sets the last nMeasurement header hashes of the
+// chain ending at the block, from those of its parent
+func (b *BlockMetaData) UpdateLastHeaderHashes(parentLastNHeaderHashes [][]byte, lastHeaderHash []byte, nMeasurement uint8) {
+	b.data.Last_NHeaderhashes = append(append([][]byte{}, parentLastNHeaderHashes...), lastHeaderHash)
 
-	if len(b.data.Last_NHeaderhashes) > int(b.config.Dev.NMeasurement) {
+	if len(b.data.Last_NHeaderhashes) > int(nMeasurement) {
 		b.data.Last_NHeaderhashes = b.data.Last_NHeaderhashes[1:]
 	}
 
-	if len(b.data.Last_NHeaderhashes) > int(b.config.Dev.NMeasurement) {
+	if len(b.data.Last_NHeaderhashes) > int(nMeasurement) {
 		panic("Length of Last N Headerhashes is more than the allowed NMeasurement in config")
 	}
 }
 
 func CreateBlockMetadata(blockDifficulty []byte, totalDifficulty []byte, childHeaderHashes [][]byte) *BlockMetaData {
-	b := &BlockMetaData{}
+	b := &BlockMetaData{data: &generated.BlockMetaData{}}
 
 	b.data.BlockDifficulty = blockDifficulty
 	b.data.CumulativeDifficulty = totalDifficulty
@@ -84,7 +84,7 @@ func (b *BlockMetaData) Serialize() ([]byte, error) {
 }
 
 func DeSerializeBlockMetaData(data []byte) (*BlockMetaData, error) {
-	b := &BlockMetaData{}
+	b := &BlockMetaData{data: &generated.BlockMetaData{}}
 
 	if err := proto.Unmarshal(data, b.data); err != nil {
 		return b, err
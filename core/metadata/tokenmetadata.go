@@ -1,8 +1,8 @@
 package metadata
 
 import (
+	"bytes"
 	"github.com/cyyber/go-qrl/generated"
-	"reflect"
 	"github.com/golang/protobuf/proto"
 )
 
@@ -24,7 +24,7 @@ func (t *TokenMetadata) Append(transferTokenTxHash []byte) {
 
 func (t *TokenMetadata) Remove(transferTokenTxHash []byte) {
 	for index, hash := range t.data.TransferTokenTxHashes {
-		if reflect.DeepEqual(hash, transferTokenTxHash) {
+		if bytes.Equal(hash, transferTokenTxHash) {
 			t.data.TransferTokenTxHashes = append(t.data.TransferTokenTxHashes[:index], t.data.TransferTokenTxHashes[index + 1:]...)
 		}
 	}
@@ -0,0 +1,247 @@
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"github.com/cyyber/go-qrl/misc"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/theQRL/qryptonight/goqryptonight"
+	"math/big"
+)
+
+// The blocks mined by the miners of the node, received from the miner or
+// the mining API, are counted per interval of MinerStats.Interval seconds
+// of block timestamp so solo miners can chart their performance from the
+// node. A block is accepted when it becomes the tip, orphaned when it
+// doesn't or is later rolled back by a reorg, and accepted again if a
+// reorg brings it back to the mainchain. The work of an interval is the sum
+// of the difficulties of its blocks, the hashes expected to find them.
+//
+// The stats are written in the batch of the block they count. The state of
+// each mined block is kept so a block moves between accepted and orphaned
+// at most once per change of the mainchain.
+//
+// Key: minerstats_ | uint64 interval start
+// Layout: uint64 accepted | uint64 orphaned | uint64 work
+//
+// Key: minedblock_ | headerhash
+// Layout: byte 1 if accepted, 0 if orphaned
+
+var minerStatsPrefix = []byte("minerstats_")
+
+var minedBlockPrefix = []byte("minedblock_")
+
+var ErrInvalidMinerStats = errors.New("invalid miner stats record")
+
+type MinerInterval struct {
+	// Unix time of the start of the interval
+	Start    uint64
+	Accepted uint64
+	Orphaned uint64
+	Work     uint64
+	// Work per second over the interval. It's the hashrate the blocks found
+	// would take on average, not a measure of the miners' hashrate: over
+	// short intervals it's mostly luck.
+	WorkRate float64
+}
+
+func minedBlockKey(headerHash []byte) []byte {
+	return append(append([]byte{}, minedBlockPrefix...), headerHash...)
+}
+
+func minerStatsKey(start uint64) []byte {
+	key := make([]byte, len(minerStatsPrefix)+8)
+	copy(key, minerStatsPrefix)
+	binary.BigEndian.PutUint64(key[len(minerStatsPrefix):], start)
+	return key
+}
+
+func (i *MinerInterval) encode() []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, i.Accepted)
+	binary.Write(&buf, binary.BigEndian, i.Orphaned)
+	binary.Write(&buf, binary.BigEndian, i.Work)
+	return buf.Bytes()
+}
+
+func decodeMinerInterval(start uint64, data []byte) (*MinerInterval, error) {
+	if len(data) != 24 {
+		return nil, ErrInvalidMinerStats
+	}
+	return &MinerInterval{
+		Start:    start,
+		Accepted: binary.BigEndian.Uint64(data[0:8]),
+		Orphaned: binary.BigEndian.Uint64(data[8:16]),
+		Work:     binary.BigEndian.Uint64(data[16:24]),
+	}, nil
+}
+
+// difficultyWork returns the difficulty of the block headerHash
+func (s *State) difficultyWork(headerHash []byte) uint64 {
+	blockMetadata, err := s.GetBlockMetadata(headerHash)
+	if err != nil {
+		return 0
+	}
+	difficulty := big.NewInt(0)
+	difficulty.SetString(goqryptonight.UInt256ToString(misc.BytesToUCharVector(blockMetadata.BlockDifficulty())), 10)
+	if !difficulty.IsUint64() {
+		return 0
+	}
+	return difficulty.Uint64()
+}
+
+// updateMinerInterval applies update to the interval holding timestamp and
+// drops the intervals older than MinerStats.Keep. An interval is read from
+// the database, it must be updated once per batch.
+func (s *State) updateMinerInterval(timestamp uint64, interval uint64, keep uint64, update func(i *MinerInterval), batch *leveldb.Batch) error {
+	if interval == 0 {
+		return nil
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	start := timestamp / interval * interval
+	i := &MinerInterval{Start: start}
+	if value, err := s.db.Get(minerStatsKey(start)); err == nil {
+		if stored, err := decodeMinerInterval(start, value); err == nil {
+			i = stored
+		}
+	}
+	update(i)
+	if err := s.db.Put(minerStatsKey(start), i.encode(), batch); err != nil {
+		return err
+	}
+
+	if start < keep*interval {
+		return nil
+	}
+	cutoff := minerStatsKey(start - keep*interval)
+	var expired [][]byte
+	err := s.db.IteratePrefix(minerStatsPrefix, nil, func(key []byte, value []byte) bool {
+		if bytes.Compare(key, cutoff) >= 0 {
+			return false
+		}
+		expired = append(expired, append([]byte{}, key...))
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	for _, key := range expired {
+		s.deleteKey(key, batch)
+	}
+	return nil
+}
+
+// minedBlockState returns whether the local miners mined the block
+// headerHash and if it's accepted
+func (s *State) minedBlockState(headerHash []byte) (mined bool, accepted bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	value, err := s.db.Get(minedBlockKey(headerHash))
+	if err != nil || len(value) != 1 {
+		return false, false
+	}
+	return true, value[0] == 1
+}
+
+func (s *State) putMinedBlockState(headerHash []byte, accepted bool, batch *leveldb.Batch) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	value := []byte{0}
+	if accepted {
+		value[0] = 1
+	}
+	return s.db.Put(minedBlockKey(headerHash), value, batch)
+}
+
+// recordMinedBlock counts a block of the local miners as it's stored,
+// accepted when it becomes the tip, in the batch storing it
+func (c *Chain) recordMinedBlock(block *Block, accepted bool, batch *leveldb.Batch) {
+	work := c.state.difficultyWork(block.HeaderHash())
+	config := c.config.Settings().MinerStats
+	err := c.state.updateMinerInterval(uint64(block.Timestamp()), config.Interval, config.Keep, func(i *MinerInterval) {
+		if accepted {
+			i.Accepted++
+		} else {
+			i.Orphaned++
+		}
+		i.Work += work
+	}, batch)
+	if err == nil {
+		err = c.state.putMinedBlockState(block.HeaderHash(), accepted, batch)
+	}
+	if err != nil {
+		c.log.Warn("Failed to update miner stats", "error", err)
+	}
+}
+
+// recordMinedBlockMoved moves a block of the local miners between accepted
+// and orphaned as it joins or leaves the mainchain. Blocks the local miners
+// didn't mine, or already counted as such, are left alone.
+func (c *Chain) recordMinedBlockMoved(block *Block, accepted bool, batch *leveldb.Batch) {
+	mined, wasAccepted := c.state.minedBlockState(block.HeaderHash())
+	if !mined || wasAccepted == accepted {
+		return
+	}
+	config := c.config.Settings().MinerStats
+	err := c.state.updateMinerInterval(uint64(block.Timestamp()), config.Interval, config.Keep, func(i *MinerInterval) {
+		if accepted {
+			i.Accepted++
+			if i.Orphaned > 0 {
+				i.Orphaned--
+			}
+		} else {
+			i.Orphaned++
+			if i.Accepted > 0 {
+				i.Accepted--
+			}
+		}
+	}, batch)
+	if err == nil {
+		err = c.state.putMinedBlockState(block.HeaderHash(), accepted, batch)
+	}
+	if err != nil {
+		c.log.Warn("Failed to update miner stats", "error", err)
+	}
+}
+
+// GetMinerIntervals returns the intervals holding the unix times from to
+// to, oldest first. Intervals without blocks are left out.
+func (s *State) GetMinerIntervals(from uint64, to uint64, interval uint64) ([]*MinerInterval, error) {
+	if interval == 0 {
+		return nil, nil
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var intervals []*MinerInterval
+	var decodeErr error
+	err := s.db.IteratePrefix(minerStatsPrefix, minerStatsKey(from/interval*interval), func(key []byte, value []byte) bool {
+		start := binary.BigEndian.Uint64(key[len(minerStatsPrefix):])
+		if start > to {
+			return false
+		}
+		i, err := decodeMinerInterval(start, value)
+		if err != nil {
+			decodeErr = err
+			return false
+		}
+		i.WorkRate = float64(i.Work) / float64(interval)
+		intervals = append(intervals, i)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return intervals, decodeErr
+}
+
+// GetMinerStats returns the stats of the local miners between the unix
+// times from and to
+func (c *Chain) GetMinerStats(from uint64, to uint64) ([]*MinerInterval, error) {
+	return c.state.GetMinerIntervals(from, to, c.config.User.MinerStats.Interval)
+}
@@ -0,0 +1,207 @@
+package core
+
+import (
+	"bytes"
+	"github.com/cyyber/go-qrl/generated"
+	"github.com/golang/protobuf/proto"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// The bitfield of the OTS keys used by an address is stored apart from its
+// address state, in pages of otsPageBits keys under
+// otspage_|address|page number, so reading the state of an active address
+// doesn't load its whole bitfield. OTSKeyReuse loads the page holding the
+// index checked, SetOTSKey and UnsetOTSKey mark the page dirty and
+// PutAddressesState writes the dirty pages along with the record.
+//
+// Serialize leaves the bitfield out, a stored record without bitfield is
+// paged. Records written before paging keep their bitfield inline until the
+// address is written again, the bitfield is then moved to pages.
+
+const otsPageBits = 1024
+
+var otsPagePrefix = []byte("otspage_")
+
+func otsPageKey(address []byte, page uint16) []byte {
+	key := make([]byte, 0, len(otsPagePrefix)+len(address)+2)
+	key = append(key, otsPagePrefix...)
+	key = append(key, address...)
+	return append(key, byte(page>>8), byte(page))
+}
+
+func isOTSPageKey(key []byte) bool {
+	return bytes.HasPrefix(key, otsPagePrefix)
+}
+
+func otsPageCount(config *Config) uint16 {
+	return uint16((uint32(config.Dev.MaxOTSTracking) + otsPageBits - 1) / otsPageBits)
+}
+
+type otsPages struct {
+	// load reads a page from the database, nil if it was never written
+	load  func(page uint16) []byte
+	pages map[uint16][]byte
+	dirty map[uint16]bool
+}
+
+func newOTSPages(load func(page uint16) []byte) *otsPages {
+	return &otsPages{
+		load:  load,
+		pages: make(map[uint16][]byte),
+		dirty: make(map[uint16]bool),
+	}
+}
+
+func (p *otsPages) page(n uint16) []byte {
+	if page, ok := p.pages[n]; ok {
+		return page
+	}
+	page := p.load(n)
+	if len(page) != otsPageBits/8 {
+		page = make([]byte, otsPageBits/8)
+	}
+	p.pages[n] = page
+	return page
+}
+
+func (p *otsPages) clone() *otsPages {
+	c := newOTSPages(p.load)
+	for n, page := range p.pages {
+		c.pages[n] = append([]byte{}, page...)
+	}
+	for n := range p.dirty {
+		c.dirty[n] = true
+	}
+	return c
+}
+
+// otsBit returns whether the OTS key at index, below MaxOTSTracking, was used
+func (a *AddressState) otsBit(index uint64) bool {
+	if a.ots == nil {
+		offset := index >> 3
+		return offset < uint64(len(a.data.OtsBitfield)) && len(a.data.OtsBitfield[offset]) > 0 && (a.data.OtsBitfield[offset][0]>>(index%8))&1 == 1
+	}
+	page := a.ots.page(uint16(index / otsPageBits))
+	bit := index % otsPageBits
+	return (page[bit>>3]>>(bit%8))&1 == 1
+}
+
+func (a *AddressState) setOTSBit(index uint64, used bool) {
+	var b *byte
+	if a.ots == nil {
+		b = &a.data.OtsBitfield[index>>3][0]
+	} else {
+		n := uint16(index / otsPageBits)
+		bit := index % otsPageBits
+		b = &a.ots.page(n)[bit>>3]
+		a.ots.dirty[n] = true
+	}
+	if used {
+		*b |= 1 << (index % 8)
+	} else {
+		*b &^= 1 << (index % 8)
+	}
+}
+
+// expandOTSBitfield returns the bitfield in the inline layout, one 8 bytes
+// entry per 8 keys with the bits in the first byte, loading all the pages
+func (a *AddressState) expandOTSBitfield() [][]byte {
+	if a.ots == nil {
+		return a.data.OtsBitfield
+	}
+	bitfield := make([][]byte, a.config.Dev.OtsBitFieldSize)
+	for i := range bitfield {
+		bitfield[i] = make([]byte, 8)
+		for j := uint64(0); j < 8; j++ {
+			if a.otsBit(uint64(i)*8 + j) {
+				bitfield[i][0] |= 1 << j
+			}
+		}
+	}
+	return bitfield
+}
+
+// ExpandedPBData returns a copy of the protobuf data holding the whole
+// bitfield, as served to the clients
+func (a *AddressState) ExpandedPBData() *generated.AddressState {
+	if a.ots == nil {
+		return a.data
+	}
+	data := proto.Clone(a.data).(*generated.AddressState)
+	data.OtsBitfield = a.expandOTSBitfield()
+	return data
+}
+
+// recordData returns the protobuf data as stored, without the bitfield
+func (a *AddressState) recordData() *generated.AddressState {
+	if len(a.data.OtsBitfield) == 0 {
+		return a.data
+	}
+	data := *a.data
+	data.OtsBitfield = nil
+	return &data
+}
+
+func (s *State) otsPageLoader(address []byte) func(page uint16) []byte {
+	address = append([]byte{}, address...)
+	return func(page uint16) []byte {
+		value, err := s.db.Get(otsPageKey(address, page))
+		if err != nil {
+			return nil
+		}
+		return value
+	}
+}
+
+func (s *State) putOTSPage(address []byte, n uint16, page []byte, batch *leveldb.Batch) {
+	key := otsPageKey(address, n)
+	if bytes.Count(page, []byte{0}) == len(page) {
+		if batch != nil {
+			batch.Delete(key)
+		} else {
+			s.db.Delete(key)
+		}
+		return
+	}
+	s.db.Put(key, append([]byte{}, page...), batch)
+}
+
+// putOTSPages writes the dirty pages of addrState, moving an inline
+// bitfield to pages first
+func (s *State) putOTSPages(addrState *AddressState, batch *leveldb.Batch) {
+	if addrState.ots == nil {
+		pages := newOTSPages(s.otsPageLoader(addrState.Address()))
+		for n := uint16(0); n < otsPageCount(s.config); n++ {
+			page := make([]byte, otsPageBits/8)
+			for bit := uint64(0); bit < otsPageBits; bit++ {
+				if addrState.otsBit(uint64(n)*otsPageBits + bit) {
+					page[bit>>3] |= 1 << (bit % 8)
+				}
+			}
+			pages.pages[n] = page
+			pages.dirty[n] = true
+		}
+		addrState.ots = pages
+		addrState.data.OtsBitfield = nil
+	}
+
+	for n := range addrState.ots.dirty {
+		s.putOTSPage(addrState.Address(), n, addrState.ots.pages[n], batch)
+	}
+	addrState.ots.dirty = make(map[uint16]bool)
+}
+
+// getOTSPageRecords returns the stored pages of address keyed by their
+// database key, nil for the pages never written
+func (s *State) getOTSPageRecords(address []byte) map[string][]byte {
+	records := make(map[string][]byte)
+	for n := uint16(0); n < otsPageCount(s.config); n++ {
+		key := otsPageKey(address, n)
+		value, err := s.db.Get(key)
+		if err != nil {
+			value = nil
+		}
+		records[string(key)] = value
+	}
+	return records
+}
@@ -0,0 +1,94 @@
+package pool
+
+import (
+	"errors"
+	"github.com/cyyber/go-qrl/core/transactions"
+)
+
+var (
+	ErrBatchSigner  = errors.New("batch transactions must be signed by the same key")
+	ErrBatchNonces  = errors.New("batch nonces must be consecutive")
+	ErrBatchAborted = errors.New("not admitted, another transaction of the batch was rejected")
+)
+
+// AddBatch admits txs, signed by the same key with consecutive nonces, as a
+// unit: either all of them enter the pool or the queue, or none does. The
+// pool lock is held for the whole batch so no other submission can take a
+// nonce in between. The error of each transaction is returned, the
+// transactions other than the rejected one failing with ErrBatchAborted.
+func (t *TransactionPool) AddBatch(txs []transactions.TransactionInterface, stateNonce uint64, blockNumber uint64) []error {
+	errs := make([]error, len(txs))
+	abort := func(index int, err error) []error {
+		for i := range errs {
+			errs[i] = ErrBatchAborted
+		}
+		errs[index] = err
+		return errs
+	}
+
+	if len(txs) == 0 {
+		return errs
+	}
+	signer := string(txs[0].AddrFromPK())
+	for i, tx := range txs {
+		if string(tx.AddrFromPK()) != signer {
+			return abort(i, ErrBatchSigner)
+		}
+		if i > 0 && tx.Nonce() != txs[i-1].Nonce()+1 {
+			return abort(i, ErrBatchNonces)
+		}
+		// Signatures are verified before taking the lock
+		if err := CheckRelayFee(tx, t.config); err != nil {
+			return abort(i, err)
+		}
+		if err := t.validateStateless(tx); err != nil {
+			return abort(i, err)
+		}
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	timestamp := t.clock.Time()
+	for i, tx := range txs {
+		expected := t.nextNonce(signer, stateNonce)
+		var err error
+		if tx.Nonce() < expected {
+			err = ErrNonceTooLow
+		} else if tx.Nonce() == expected {
+			err = t.add(tx, blockNumber, timestamp)
+		} else {
+			err = t.enqueue(signer, tx, blockNumber, timestamp)
+		}
+		if err != nil {
+			for _, added := range txs[:i] {
+				if t.remove(added) == nil {
+					t.unqueue(signer, added)
+				}
+			}
+			return abort(i, err)
+		}
+	}
+
+	t.metrics.update(func(m *metrics) {
+		m.admitted += uint64(len(txs))
+	})
+	t.promote(signer, stateNonce, blockNumber)
+	return errs
+}
+
+// unqueue removes tx from the queue of signer
+func (t *TransactionPool) unqueue(signer string, tx transactions.TransactionInterface) {
+	queue := t.queued[signer]
+	for i, ti := range queue {
+		if ti.tx.Nonce() == tx.Nonce() {
+			queue = append(queue[:i], queue[i+1:]...)
+			break
+		}
+	}
+	if len(queue) == 0 {
+		delete(t.queued, signer)
+	} else {
+		t.queued[signer] = queue
+	}
+}
@@ -0,0 +1,95 @@
+package pool
+
+import (
+	"fmt"
+	"github.com/cyyber/go-qrl/core/transactions"
+)
+
+type RejectReason int
+
+const (
+	RejectTooManyFromAddress RejectReason = iota
+	RejectTooManyFromPK
+	RejectFeeTooLow
+	RejectPoolFull
+	RejectExpired
+	RejectDuplicate
+	RejectOTSKeyReused
+	RejectNonceTooLow
+	RejectQueueFull
+	RejectAlreadyQueued
+	RejectInvalid
+	RejectInvalidSignature
+	RejectOTSKeyUsed
+	RejectInsufficientBalance
+	RejectTxTypeDisabled
+)
+
+var rejectReasonToString = map[RejectReason]string{
+	RejectTooManyFromAddress:  "too many transactions from address",
+	RejectTooManyFromPK:       "too many transactions signed by public key",
+	RejectFeeTooLow:           "fee per byte below minimum",
+	RejectPoolFull:            "transaction pool is full",
+	RejectExpired:             "transaction expired",
+	RejectDuplicate:           "transaction already in pool",
+	RejectOTSKeyReused:        "ots key already used by a pending transaction",
+	RejectNonceTooLow:         "nonce too low",
+	RejectQueueFull:           "queued transactions limit reached",
+	RejectAlreadyQueued:       "nonce already queued",
+	RejectInvalid:             "invalid transaction",
+	RejectInvalidSignature:    "invalid signature",
+	RejectOTSKeyUsed:          "ots key already used on chain",
+	RejectInsufficientBalance: "insufficient balance",
+	RejectTxTypeDisabled:      "transaction type disabled",
+}
+
+func (r RejectReason) String() string {
+	return rejectReasonToString[r]
+}
+
+// RejectedError is returned when a transaction is refused by the pool
+// admission policy, as opposed to being invalid
+type RejectedError struct {
+	Reason RejectReason
+	Detail string
+}
+
+func (e *RejectedError) Error() string {
+	if e.Detail == "" {
+		return e.Reason.String()
+	}
+	return fmt.Sprintf("%s: %s", e.Reason, e.Detail)
+}
+
+func IsRejected(err error) bool {
+	_, ok := err.(*RejectedError)
+	return ok
+}
+
+// checkLimits enforces the per address and per public key caps, so a single
+// wallet cannot monopolize the pool
+func (t *TransactionPool) checkLimits(tx transactions.TransactionInterface) error {
+	poolConfig := t.config.Settings().TransactionPool
+
+	addrFrom := string(tx.AddrFrom())
+	pk := string(tx.PK())
+	var fromAddress, fromPK uint64
+	for e := t.txPool.Front(); e != nil; e = e.Next() {
+		ti := e.Value.(*TransactionInfo)
+		if string(ti.tx.AddrFrom()) == addrFrom {
+			fromAddress++
+		}
+		if string(ti.tx.PK()) == pk {
+			fromPK++
+		}
+	}
+
+	if poolConfig.MaxTxPerAddress > 0 && fromAddress >= poolConfig.MaxTxPerAddress {
+		return &RejectedError{Reason: RejectTooManyFromAddress}
+	}
+	if poolConfig.MaxTxPerPK > 0 && fromPK >= poolConfig.MaxTxPerPK {
+		return &RejectedError{Reason: RejectTooManyFromPK}
+	}
+
+	return nil
+}
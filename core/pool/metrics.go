@@ -0,0 +1,124 @@
+package pool
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// Fee per byte percentiles reported by Stats
+var feePercentiles = []int{10, 25, 50, 75, 90}
+
+// metrics counts the pool events since the node started
+type metrics struct {
+	lock sync.Mutex
+
+	admitted  uint64
+	rejected  map[RejectReason]uint64
+	evicted   uint64
+	confirmed uint64
+	// Sum of the seconds spent in the pool by the confirmed transactions
+	confirmedWait uint64
+}
+
+func newMetrics() *metrics {
+	return &metrics{rejected: make(map[RejectReason]uint64)}
+}
+
+func (m *metrics) update(fn func(m *metrics)) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	fn(m)
+}
+
+type FeePercentile struct {
+	Percentile int
+	FeePerByte uint64
+}
+
+type Stats struct {
+	Pending uint64
+	Queued  uint64
+	// Counters since the node started
+	Admitted  uint64
+	Rejected  map[string]uint64
+	Evicted   uint64
+	Confirmed uint64
+	// Average seconds between admission and inclusion in a block
+	AverageWaitSeconds uint64
+	// Fee per byte of the pending transactions
+	FeePercentiles []*FeePercentile
+}
+
+// Stats returns the pool counters and the fee distribution of the pending
+// transactions, for fee estimation in wallets
+func (t *TransactionPool) Stats() *Stats {
+	t.lock.Lock()
+	stats := &Stats{
+		Pending: uint64(t.txPool.Len()),
+		Queued:  uint64(t.queuedCount()),
+	}
+	feesPerByte := make([]uint64, 0, t.txPool.Len())
+	for e := t.txPool.Front(); e != nil; e = e.Next() {
+		tx := e.Value.(*TransactionInfo).tx
+		if tx.Size() > 0 {
+			feesPerByte = append(feesPerByte, tx.Fee()/uint64(tx.Size()))
+		}
+	}
+	t.lock.Unlock()
+
+	sort.Slice(feesPerByte, func(i, j int) bool {
+		return feesPerByte[i] < feesPerByte[j]
+	})
+	if len(feesPerByte) > 0 {
+		for _, percentile := range feePercentiles {
+			stats.FeePercentiles = append(stats.FeePercentiles, &FeePercentile{
+				Percentile: percentile,
+				FeePerByte: feesPerByte[(len(feesPerByte)-1)*percentile/100],
+			})
+		}
+	}
+
+	t.metrics.update(func(m *metrics) {
+		stats.Admitted = m.admitted
+		stats.Evicted = m.evicted
+		stats.Confirmed = m.confirmed
+		if m.confirmed > 0 {
+			stats.AverageWaitSeconds = m.confirmedWait / m.confirmed
+		}
+		stats.Rejected = make(map[string]uint64, len(m.rejected))
+		for reason, count := range m.rejected {
+			stats.Rejected[reason.String()] = count
+		}
+	})
+	return stats
+}
+
+// WritePrometheus writes the pool metrics in the Prometheus text format
+func (t *TransactionPool) WritePrometheus(w io.Writer) {
+	stats := t.Stats()
+
+	fmt.Fprintf(w, "# TYPE qrl_mempool_pending gauge\nqrl_mempool_pending %d\n", stats.Pending)
+	fmt.Fprintf(w, "# TYPE qrl_mempool_queued gauge\nqrl_mempool_queued %d\n", stats.Queued)
+	fmt.Fprintf(w, "# TYPE qrl_mempool_admitted_total counter\nqrl_mempool_admitted_total %d\n", stats.Admitted)
+	fmt.Fprintf(w, "# TYPE qrl_mempool_evicted_total counter\nqrl_mempool_evicted_total %d\n", stats.Evicted)
+	fmt.Fprintf(w, "# TYPE qrl_mempool_confirmed_total counter\nqrl_mempool_confirmed_total %d\n", stats.Confirmed)
+	fmt.Fprintf(w, "# TYPE qrl_mempool_wait_seconds_average gauge\nqrl_mempool_wait_seconds_average %d\n", stats.AverageWaitSeconds)
+
+	reasons := make([]string, 0, len(stats.Rejected))
+	for reason := range stats.Rejected {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	fmt.Fprintf(w, "# TYPE qrl_mempool_rejected_total counter\n")
+	for _, reason := range reasons {
+		fmt.Fprintf(w, "qrl_mempool_rejected_total{reason=%q} %d\n", reason, stats.Rejected[reason])
+	}
+
+	fmt.Fprintf(w, "# TYPE qrl_mempool_fee_per_byte gauge\n")
+	for _, fee := range stats.FeePercentiles {
+		fmt.Fprintf(w, "qrl_mempool_fee_per_byte{quantile=\"%.2f\"} %d\n", float64(fee.Percentile)/100, fee.FeePerByte)
+	}
+}
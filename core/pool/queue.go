@@ -0,0 +1,196 @@
+package pool
+
+import (
+	"errors"
+	"github.com/cyyber/go-qrl/core/transactions"
+	"sort"
+)
+
+var (
+	ErrNonceTooLow   = errors.New("nonce lower than expected nonce")
+	ErrQueueFull     = errors.New("queued transactions limit reached for address")
+	ErrAlreadyQueued = errors.New("a transaction with same nonce is already queued")
+)
+
+// The queue holds transactions whose nonce is ahead of the next nonce
+// expected for their signing address. They are neither relayed nor mined
+// until the gap is filled, at which point they are promoted to the pool.
+
+// nextNonce returns the nonce expected for the next transaction of signer,
+// taking into account the transactions already in the pool
+func (t *TransactionPool) nextNonce(signer string, stateNonce uint64) uint64 {
+	expected := stateNonce + 1
+	for e := t.txPool.Front(); e != nil; e = e.Next() {
+		ti := e.Value.(*TransactionInfo)
+		if string(ti.tx.AddrFromPK()) != signer {
+			continue
+		}
+		if ti.tx.Nonce() >= expected {
+			expected = ti.tx.Nonce() + 1
+		}
+	}
+	return expected
+}
+
+// NextNonce returns the nonce expected for the next transaction signed by
+// address
+func (t *TransactionPool) NextNonce(address []byte, stateNonce uint64) uint64 {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.nextNonce(string(address), stateNonce)
+}
+
+// AddWithNonce adds tx to the pool if its nonce is the next expected nonce
+// of its signing address, or to the queue if the nonce is ahead.
+// stateNonce is the nonce of the signing address at the current tip.
+func (t *TransactionPool) AddWithNonce(tx transactions.TransactionInterface, stateNonce uint64, blockNumber uint64, timestamp uint64) error {
+	if err := CheckRelayFee(tx, t.config); err != nil {
+		return err
+	}
+	if err := t.validateStateless(tx); err != nil {
+		return err
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	signer := string(tx.AddrFromPK())
+	expected := t.nextNonce(signer, stateNonce)
+
+	if tx.Nonce() < expected {
+		return ErrNonceTooLow
+	}
+
+	if tx.Nonce() == expected {
+		if err := t.add(tx, blockNumber, timestamp); err != nil {
+			return err
+		}
+		t.metrics.update(func(m *metrics) {
+			m.admitted++
+		})
+		t.promote(signer, stateNonce, blockNumber)
+		return nil
+	}
+
+	if err := t.enqueue(signer, tx, blockNumber, timestamp); err != nil {
+		return err
+	}
+	t.metrics.update(func(m *metrics) {
+		m.admitted++
+	})
+	return nil
+}
+
+// Test runs the checks of AddWithNonce without changing the pool, queued is
+// set when tx would wait in the queue for a nonce gap to be filled
+func (t *TransactionPool) Test(tx transactions.TransactionInterface, stateNonce uint64, blockNumber uint64) (queued bool, err error) {
+	if err := CheckRelayFee(tx, t.config); err != nil {
+		return false, err
+	}
+	if err := t.validateStateless(tx); err != nil {
+		return false, err
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	signer := string(tx.AddrFromPK())
+	expected := t.nextNonce(signer, stateNonce)
+
+	if tx.Nonce() < expected {
+		return false, ErrNonceTooLow
+	}
+	if tx.Nonce() == expected {
+		return false, t.admissible(tx, blockNumber)
+	}
+	return true, t.queueable(signer, tx)
+}
+
+func (t *TransactionPool) queueable(signer string, tx transactions.TransactionInterface) error {
+	queue := t.queued[signer]
+	for _, ti := range queue {
+		if ti.tx.Nonce() == tx.Nonce() {
+			return ErrAlreadyQueued
+		}
+	}
+
+	if uint64(len(queue)) >= t.config.Settings().TransactionPool.MaxQueuedPerAddress {
+		return ErrQueueFull
+	}
+	return nil
+}
+
+func (t *TransactionPool) enqueue(signer string, tx transactions.TransactionInterface, blockNumber uint64, timestamp uint64) error {
+	if err := t.queueable(signer, tx); err != nil {
+		return err
+	}
+	queue := t.queued[signer]
+
+	if timestamp == 0 {
+		timestamp = t.clock.Time()
+	}
+
+	queue = append(queue, CreateTransactionInfo(tx, blockNumber, timestamp))
+	sort.Slice(queue, func(i, j int) bool {
+		return queue[i].tx.Nonce() < queue[j].tx.Nonce()
+	})
+	t.queued[signer] = queue
+
+	return nil
+}
+
+// promote moves queued transactions of signer into the pool as long as
+// their nonces are consecutive to the pool's last nonce
+func (t *TransactionPool) promote(signer string, stateNonce uint64, blockNumber uint64) {
+	queue := t.queued[signer]
+	for len(queue) > 0 {
+		expected := t.nextNonce(signer, stateNonce)
+		ti := queue[0]
+		if ti.tx.Nonce() < expected {
+			// Nonce already used, either mined or replaced in pool
+			queue = queue[1:]
+			continue
+		}
+		if ti.tx.Nonce() > expected {
+			break
+		}
+		if err := t.add(ti.tx, blockNumber, ti.timestamp); err != nil {
+			// Pool is full or tx conflicts, keep it queued
+			break
+		}
+		queue = queue[1:]
+	}
+
+	if len(queue) == 0 {
+		delete(t.queued, signer)
+	} else {
+		t.queued[signer] = queue
+	}
+}
+
+// PromoteQueued is called once a block is applied, with a lookup returning
+// the nonce at the new tip for a signing address
+func (t *TransactionPool) PromoteQueued(stateNonce func(address []byte) uint64, blockNumber uint64) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for signer := range t.queued {
+		t.promote(signer, stateNonce([]byte(signer)), blockNumber)
+	}
+}
+
+func (t *TransactionPool) QueuedCount() int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.queuedCount()
+}
+
+func (t *TransactionPool) queuedCount() int {
+	count := 0
+	for _, queue := range t.queued {
+		count += len(queue)
+	}
+	return count
+}
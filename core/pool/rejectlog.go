@@ -0,0 +1,112 @@
+package pool
+
+import (
+	"github.com/cyyber/go-qrl/core/transactions"
+	"sync"
+)
+
+// Rejection is a transaction refused by the pool, kept so integrators can
+// find out why a push never confirmed
+type Rejection struct {
+	TxHash    []byte
+	AddrFrom  []byte
+	Reason    RejectReason
+	Error     string
+	Timestamp uint64
+}
+
+// RejectLog keeps the last rejections in a ring buffer
+type RejectLog struct {
+	lock sync.Mutex
+
+	entries []*Rejection
+	next    int
+	full    bool
+}
+
+func NewRejectLog(size int) *RejectLog {
+	return &RejectLog{entries: make([]*Rejection, size)}
+}
+
+func (r *RejectLog) add(rejection *Rejection) {
+	if len(r.entries) == 0 {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.entries[r.next] = rejection
+	r.next = (r.next + 1) % len(r.entries)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// Recent returns up to limit rejections, the most recent first. A limit of
+// 0 returns all of them.
+func (r *RejectLog) Recent(limit int) []*Rejection {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	count := r.next
+	if r.full {
+		count = len(r.entries)
+	}
+	if limit > 0 && limit < count {
+		count = limit
+	}
+
+	result := make([]*Rejection, 0, count)
+	for i := 1; i <= count; i++ {
+		index := (r.next - i + len(r.entries)) % len(r.entries)
+		result = append(result, r.entries[index])
+	}
+	return result
+}
+
+// RejectReasonForError classifies the errors returned while admitting a
+// transaction, errors of the transaction validation being RejectInvalid
+func RejectReasonForError(err error) RejectReason {
+	if rejected, ok := err.(*RejectedError); ok {
+		return rejected.Reason
+	}
+	switch err {
+	case ErrPoolFull:
+		return RejectPoolFull
+	case ErrTxExpired:
+		return RejectExpired
+	case ErrUnlockHeight:
+		return RejectInvalid
+	case ErrTxExists:
+		return RejectDuplicate
+	case ErrOTSKeyReused:
+		return RejectOTSKeyReused
+	case ErrNonceTooLow:
+		return RejectNonceTooLow
+	case ErrQueueFull:
+		return RejectQueueFull
+	case ErrAlreadyQueued:
+		return RejectAlreadyQueued
+	}
+	return RejectInvalid
+}
+
+// RecordRejection logs tx as rejected with err
+func (t *TransactionPool) RecordRejection(tx transactions.TransactionInterface, err error) {
+	reason := RejectReasonForError(err)
+	t.metrics.update(func(m *metrics) {
+		m.rejected[reason]++
+	})
+	t.rejects.add(&Rejection{
+		TxHash:    tx.Txhash(),
+		AddrFrom:  tx.AddrFrom(),
+		Reason:    reason,
+		Error:     err.Error(),
+		Timestamp: t.clock.Time(),
+	})
+}
+
+// RecentRejections returns up to limit rejections, the most recent first
+func (t *TransactionPool) RecentRejections(limit int) []*Rejection {
+	return t.rejects.Recent(limit)
+}
@@ -0,0 +1,51 @@
+package pool
+
+import (
+	"fmt"
+	"github.com/cyyber/go-qrl/core"
+	"github.com/cyyber/go-qrl/core/transactions"
+)
+
+var txTypePolicy = core.NewTxTypePolicyMetrics()
+
+// CheckRelayFee applies the fee policy, the minimum fee per byte of the
+// transaction type and the disabled types, to a transaction
+// submitted to the pool or received from a peer. It is a local anti spam
+// policy, block validation never consults it, and transactions returned to
+// the pool by a rollback are not checked again.
+func CheckRelayFee(tx transactions.TransactionInterface, config *core.Config) error {
+	poolConfig := config.Settings().TransactionPool
+	txType := core.TransactionTypeName(tx.PBData())
+	if poolConfig.IsTxTypeDisabled(txType) {
+		txTypePolicy.Update(txType, func(stats *core.TxTypePolicyStats) {
+			stats.RejectedDisabled++
+		})
+		return &RejectedError{
+			Reason: RejectTxTypeDisabled,
+			Detail: txType,
+		}
+	}
+
+	minFee := poolConfig.TxTypeMinFeePerByte(txType)
+	if minFee == 0 || poolConfig.IsFeeExempt(tx.AddrFrom()) {
+		return nil
+	}
+
+	size := uint64(tx.Size())
+	if size > 0 && tx.Fee()/size < minFee {
+		txTypePolicy.Update(txType, func(stats *core.TxTypePolicyStats) {
+			stats.RejectedFeeTooLow++
+		})
+		return &RejectedError{
+			Reason: RejectFeeTooLow,
+			Detail: fmt.Sprintf("%s fee %d for %d bytes, minimum %d per byte", txType, tx.Fee(), size, minFee),
+		}
+	}
+	return nil
+}
+
+// TxTypePolicyMetrics returns the counters of the fee policy, shared by the
+// pool and the template builder
+func TxTypePolicyMetrics() *core.TxTypePolicyMetrics {
+	return txTypePolicy
+}
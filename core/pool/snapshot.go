@@ -0,0 +1,66 @@
+package pool
+
+import (
+	"bytes"
+	"github.com/cyyber/go-qrl/core/transactions"
+	"sort"
+)
+
+// Snapshot is an immutable, fee ordered view of the pool. It can be iterated
+// without holding the pool lock, which keeps block template construction
+// from stalling admission when the pool is large.
+type Snapshot struct {
+	txs []transactions.TransactionInterface
+}
+
+func (s *Snapshot) Len() int {
+	return len(s.txs)
+}
+
+func (s *Snapshot) At(i int) transactions.TransactionInterface {
+	return s.txs[i]
+}
+
+// Transactions returns a copy of the snapshot, highest fee first
+func (s *Snapshot) Transactions() []transactions.TransactionInterface {
+	return append([]transactions.TransactionInterface(nil), s.txs...)
+}
+
+// Snapshot returns the transactions currently in the pool ordered by fee,
+// txhash being used as tie breaker. The snapshot is cached until the pool
+// is modified.
+func (t *TransactionPool) Snapshot() *Snapshot {
+	t.lock.Lock()
+	if t.snapshot != nil {
+		defer t.lock.Unlock()
+		return t.snapshot
+	}
+	txs := make([]transactions.TransactionInterface, 0, t.txPool.Len())
+	for e := t.txPool.Front(); e != nil; e = e.Next() {
+		txs = append(txs, e.Value.(*TransactionInfo).tx)
+	}
+	version := t.version
+	t.lock.Unlock()
+
+	// Sorting is done outside the lock
+	sort.Slice(txs, func(i, j int) bool {
+		if txs[i].Fee() != txs[j].Fee() {
+			return txs[i].Fee() > txs[j].Fee()
+		}
+		return bytes.Compare(txs[i].Txhash(), txs[j].Txhash()) < 0
+	})
+	snapshot := &Snapshot{txs: txs}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.version == version {
+		t.snapshot = snapshot
+	}
+	return snapshot
+}
+
+// modified invalidates the cached snapshot
+func (t *TransactionPool) modified() {
+	t.version++
+	t.snapshot = nil
+}
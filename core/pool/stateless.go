@@ -0,0 +1,89 @@
+package pool
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/cyyber/go-qrl/core/transactions"
+	"github.com/cyyber/go-qrl/misc"
+)
+
+// Extended XMSS public key, descriptor followed by root and public seed
+const xmssPKSize = 67
+
+// The signature starts with the OTS index, read from the first 8 bytes
+const minSignatureSize = 8
+
+// validateStateless runs the checks which don't depend on the chain state,
+// so transactions which can never be mined neither take a pool slot nor get
+// relayed. Transactions returned to the pool by a rollback were validated
+// when their block was applied and skip them.
+func (t *TransactionPool) validateStateless(tx transactions.TransactionInterface) error {
+	if _, ok := tx.(*transactions.CoinBase); ok {
+		return &RejectedError{Reason: RejectInvalid, Detail: "coinbase transaction"}
+	}
+
+	maxSize := int(t.config.Dev.Transaction.MaxTxSize)
+	if tx.Size() == 0 || tx.Size() > maxSize {
+		return &RejectedError{
+			Reason: RejectInvalid,
+			Detail: fmt.Sprintf("size %d, maximum %d", tx.Size(), maxSize),
+		}
+	}
+	if len(tx.PK()) != xmssPKSize {
+		return &RejectedError{
+			Reason: RejectInvalid,
+			Detail: fmt.Sprintf("public key size %d", len(tx.PK())),
+		}
+	}
+	if len(tx.Signature()) < minSignatureSize {
+		return &RejectedError{
+			Reason: RejectInvalidSignature,
+			Detail: fmt.Sprintf("signature size %d", len(tx.Signature())),
+		}
+	}
+
+	// The address of the public key and the master address of a slave
+	// transaction must be well formed, and a slave can't name its own
+	// address as master
+	if err := misc.ValidateAddress(tx.AddrFromPK()); err != nil {
+		return &RejectedError{Reason: RejectInvalid, Detail: "public key: " + err.Error()}
+	}
+	if tx.MasterAddr() != nil {
+		if err := misc.ValidateAddress(tx.MasterAddr()); err != nil {
+			return &RejectedError{Reason: RejectInvalid, Detail: "master address: " + err.Error()}
+		}
+		if bytes.Equal(tx.MasterAddr(), tx.AddrFromPK()) {
+			return &RejectedError{Reason: RejectInvalid, Detail: "master address is the address of the public key"}
+		}
+	}
+
+	// Fees are unsigned, but the fee and amounts spent must not overflow
+	// once added
+	if transfer, ok := tx.(*transactions.TransferTransaction); ok {
+		var total uint64
+		for _, amount := range transfer.Amounts() {
+			if total+amount < total {
+				return &RejectedError{Reason: RejectInvalid, Detail: "amounts overflow"}
+			}
+			total += amount
+		}
+		if total+tx.Fee() < total {
+			return &RejectedError{Reason: RejectInvalid, Detail: "fee overflow"}
+		}
+	}
+
+	// Cheaper than the signature check, and a tx whose hash doesn't match
+	// its content would be rejected in a block
+	if !bytes.Equal(tx.Txhash(), transactions.ExpectedTxhash(tx)) {
+		return &RejectedError{Reason: RejectInvalid, Detail: transactions.ErrTxhashMismatch.Error()}
+	}
+
+	hashableBytes := misc.BytesToUCharVector(tx.GetHashableBytes())
+	if !tx.Validate(hashableBytes, false) {
+		return &RejectedError{Reason: RejectInvalid}
+	}
+	if !tx.ValidateXMSS(hashableBytes) {
+		return &RejectedError{Reason: RejectInvalidSignature}
+	}
+	return nil
+}
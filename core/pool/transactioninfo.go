@@ -1,15 +1,17 @@
 package pool
 
 import (
-	"github.com/cyyber/go-qrl/core/transactions"
 	"github.com/cyyber/go-qrl/core"
+	"github.com/cyyber/go-qrl/core/transactions"
+	"github.com/cyyber/go-qrl/generated"
 )
 
 type TransactionInfo struct {
 	tx transactions.TransactionInterface
 	blockNumber uint64
-	timestamp uint64
-	config *core.Config
+	timestamp   uint64
+	config      *core.Config
+	receipt     *generated.ReceiptMetadata
 }
 
 func (t *TransactionInfo) Transaction() transactions.TransactionInterface {
@@ -24,6 +26,11 @@ func (t *TransactionInfo) Timestamp() uint64 {
 	return t.timestamp
 }
 
+// Receipt returns how the transaction was received, nil if unknown
+func (t *TransactionInfo) Receipt() *generated.ReceiptMetadata {
+	return t.receipt
+}
+
 func (t *TransactionInfo) IsStale(currentBlockHeight uint64) bool {
 	if currentBlockHeight > t.blockNumber + t.config.User.TransactionPool.StaleTransactionThreshold {
 		return true
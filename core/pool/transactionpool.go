@@ -1,21 +1,68 @@
 package pool
 
 import (
+	"bytes"
 	"container/list"
+	"errors"
 	"github.com/cyyber/go-qrl/core"
 	"github.com/cyyber/go-qrl/core/transactions"
+	"github.com/cyyber/go-qrl/generated"
 	"github.com/cyyber/go-qrl/misc"
-	"errors"
-	"reflect"
+	"sync"
+)
+
+var (
+	ErrPoolFull     = errors.New("transaction pool is full")
+	ErrTxExpired    = errors.New("transaction expired")
+	ErrUnlockHeight = errors.New("unlock height not reached")
+	ErrTxExists     = errors.New("transaction already exists in pool")
+	ErrOTSKeyReused = errors.New("a transaction already exists signed with same ots key")
 )
 
+// TransactionPool is safe for concurrent use. All exported methods take
+// lock, unexported ones expect the caller to hold it.
 type TransactionPool struct {
+	lock sync.Mutex
+
 	txPool list.List
+	queued map[string][]*TransactionInfo
 	config *core.Config
-	ntp *misc.NTP
+	clock  misc.Clock
+
+	// Incremented on every change, used to invalidate snapshot
+	version  uint64
+	snapshot *Snapshot
+
+	rejects *RejectLog
+	metrics *metrics
+}
+
+func CreateTransactionPool(config *core.Config) *TransactionPool {
+	return &TransactionPool{
+		queued:  make(map[string][]*TransactionInfo),
+		config:  config,
+		clock:   misc.GetNTP(),
+		rejects: NewRejectLog(int(config.User.TransactionPool.RejectLogSize)),
+		metrics: newMetrics(),
+	}
+}
+
+// SetClock replaces the NTP clock used to timestamp transactions
+func (t *TransactionPool) SetClock(clock misc.Clock) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.clock = clock
 }
 
 func (t *TransactionPool) IsFull() bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.isFull()
+}
+
+func (t *TransactionPool) isFull() bool {
 	if t.txPool.Len() >= int(t.config.User.TransactionPool.TransactionPoolSize) {
 		return true
 	}
@@ -24,76 +71,242 @@ func (t *TransactionPool) IsFull() bool {
 }
 
 func (t *TransactionPool) Add(tx transactions.TransactionInterface, blockNumber uint64, timestamp uint64) error {
-	if t.IsFull() {
-		return errors.New("transaction pool is full")
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.add(tx, blockNumber, timestamp)
+}
+
+func (t *TransactionPool) add(tx transactions.TransactionInterface, blockNumber uint64, timestamp uint64) error {
+	if err := t.admissible(tx, blockNumber); err != nil {
+		return err
+	}
+
+	if timestamp == 0 {
+		timestamp = t.clock.Time()
+	}
+
+	ti := CreateTransactionInfo(tx, blockNumber, timestamp)
+
+	t.txPool.PushBack(ti)
+	t.modified()
+
+	return nil
+}
+
+// admissible checks tx against the pool without adding it
+func (t *TransactionPool) admissible(tx transactions.TransactionInterface, blockNumber uint64) error {
+	if t.isFull() {
+		return ErrPoolFull
+	}
+
+	// The transaction could be included at the earliest in the next block
+	if tx.IsExpired(blockNumber + 1) {
+		return ErrTxExpired
+	}
+	if !tx.IsUnlockHeightValid(blockNumber + 1) {
+		return ErrUnlockHeight
 	}
 
 	for e := t.txPool.Front(); e != nil; e = e.Next() {
-		ti := e.Value.(TransactionInfo)
-		if reflect.DeepEqual(ti.tx.Txhash(), tx.Txhash()) {
-			return errors.New("transaction already exists in pool")
+		ti := e.Value.(*TransactionInfo)
+		if bytes.Equal(ti.tx.Txhash(), tx.Txhash()) {
+			return ErrTxExists
 		}
-		if reflect.DeepEqual(ti.tx.PK(), tx.PK()) {
+		if bytes.Equal(ti.tx.PK(), tx.PK()) {
 			if ti.tx.OtsKey() == tx.OtsKey() {
-				return errors.New("a transaction already exists signed with same ots key")
+				return ErrOTSKeyReused
 			}
 		}
 	}
 
-	if timestamp == 0 {
-		timestamp = t.ntp.Time()
-	}
+	return t.checkLimits(tx)
+}
 
-	ti := CreateTransactionInfo(tx, blockNumber, timestamp)
+// Transactions returns the transactions currently in the pool,
+// in the order they were added
+func (t *TransactionPool) Transactions() []transactions.TransactionInterface {
+	t.lock.Lock()
+	defer t.lock.Unlock()
 
-	t.txPool.PushBack(ti)
+	var txs []transactions.TransactionInterface
+	for e := t.txPool.Front(); e != nil; e = e.Next() {
+		txs = append(txs, e.Value.(*TransactionInfo).tx)
+	}
+	return txs
+}
 
-	return nil
+// TransactionInfos returns the pool entries in the order they were added
+func (t *TransactionPool) TransactionInfos() []*TransactionInfo {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	var infos []*TransactionInfo
+	for e := t.txPool.Front(); e != nil; e = e.Next() {
+		infos = append(infos, e.Value.(*TransactionInfo))
+	}
+	return infos
 }
 
 func (t *TransactionPool) Remove(tx transactions.TransactionInterface) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.remove(tx)
+}
+
+func (t *TransactionPool) remove(tx transactions.TransactionInterface) *TransactionInfo {
 	for e := t.txPool.Front(); e != nil; e = e.Next() {
-		ti := e.Value.(TransactionInfo)
-		if reflect.DeepEqual(ti.tx.Txhash(), tx.Txhash()) {
+		ti := e.Value.(*TransactionInfo)
+		if bytes.Equal(ti.tx.Txhash(), tx.Txhash()) {
 			t.txPool.Remove(e)
-			break
+			t.modified()
+			return ti
 		}
 	}
+	return nil
+}
+
+// confirmed records the time ti waited in the pool before being mined
+func (t *TransactionPool) confirmed(ti *TransactionInfo) {
+	now := t.clock.Time()
+	t.metrics.update(func(m *metrics) {
+		m.confirmed++
+		if now > ti.timestamp {
+			m.confirmedWait += now - ti.timestamp
+		}
+	})
+}
+
+// find returns the pool or queue entry of txHash, nil if there's none
+func (t *TransactionPool) find(txHash []byte) *TransactionInfo {
+	for e := t.txPool.Front(); e != nil; e = e.Next() {
+		ti := e.Value.(*TransactionInfo)
+		if bytes.Equal(ti.tx.Txhash(), txHash) {
+			return ti
+		}
+	}
+	for _, queue := range t.queued {
+		for _, ti := range queue {
+			if bytes.Equal(ti.tx.Txhash(), txHash) {
+				return ti
+			}
+		}
+	}
+	return nil
+}
+
+// SetReceipt attaches receipt to the pending transaction txHash
+func (t *TransactionPool) SetReceipt(txHash []byte, receipt *generated.ReceiptMetadata) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if ti := t.find(txHash); ti != nil {
+		ti.receipt = receipt
+	}
+}
+
+// Receipt returns the receipt of the pending transaction txHash, nil if
+// it isn't pending or has none
+func (t *TransactionPool) Receipt(txHash []byte) *generated.ReceiptMetadata {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if ti := t.find(txHash); ti != nil {
+		return ti.receipt
+	}
+	return nil
 }
 
 func (t *TransactionPool) RemoveTxInBlock(block *core.Block) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
 	for _, protoTX := range block.Transactions() {
 		tx := transactions.ProtoToTransaction(protoTX)
 		if tx.OtsKey() < t.config.Dev.MaxOTSTracking {
-			t.Remove(tx)
+			if ti := t.remove(tx); ti != nil {
+				t.confirmed(ti)
+			}
 		} else {
 			for e := t.txPool.Front(); e != nil; {
-				tmp := e
-				e := e.Next()
+				next := e.Next()
 
-				ti := e.Value.(TransactionInfo)
-				if reflect.DeepEqual(tx.PK(), ti.tx.PK()) {
+				ti := e.Value.(*TransactionInfo)
+				if bytes.Equal(tx.PK(), ti.tx.PK()) {
 					if ti.tx.OtsKey() <= tx.OtsKey() {
-						t.txPool.Remove(tmp)
+						t.txPool.Remove(e)
+						t.modified()
 					}
 				}
+				e = next
+			}
+		}
+	}
+}
+
+// RemoveExpired drops the transactions which can't be included in the block
+// following blockNumber, because they expired or, after a rollback, their
+// unlock height is no longer reached
+func (t *TransactionPool) RemoveExpired(blockNumber uint64) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for e := t.txPool.Front(); e != nil; {
+		next := e.Next()
+		if !includable(e.Value.(*TransactionInfo).tx, blockNumber+1) {
+			t.txPool.Remove(e)
+			t.modified()
+			t.metrics.update(func(m *metrics) {
+				m.evicted++
+			})
+		}
+		e = next
+	}
+
+	for signer, queue := range t.queued {
+		var kept []*TransactionInfo
+		for _, ti := range queue {
+			if includable(ti.tx, blockNumber+1) {
+				kept = append(kept, ti)
 			}
 		}
+		evicted := uint64(len(queue) - len(kept))
+		t.metrics.update(func(m *metrics) {
+			m.evicted += evicted
+		})
+		if len(kept) == 0 {
+			delete(t.queued, signer)
+		} else {
+			t.queued[signer] = kept
+		}
 	}
 }
 
+func includable(tx transactions.TransactionInterface, blockNumber uint64) bool {
+	return !tx.IsExpired(blockNumber) && tx.IsUnlockHeightValid(blockNumber)
+}
+
 func (t *TransactionPool) AddTxFromBlock(block *core.Block, currentBlockHeight uint64) error {
-	for _, protoTX := range block.Transactions() {
-		err := t.Add(transactions.ProtoToTransaction(protoTX), currentBlockHeight, t.ntp.Time())
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	// Coinbase transaction is skipped as it cannot be included in any other block
+	for _, protoTX := range block.Transactions()[1:] {
+		err := t.add(transactions.ProtoToTransaction(protoTX), currentBlockHeight, t.clock.Time())
 		if err != nil {
 			return err
 		}
 	}
+	return nil
 }
 
 func (t *TransactionPool) CheckStale(currentBlockHeight uint64) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
 	for e := t.txPool.Front(); e != nil; e = e.Next() {
-		ti := e.Value.(TransactionInfo)
+		ti := e.Value.(*TransactionInfo)
 		if ti.IsStale(currentBlockHeight) {
 			ti.blockNumber = currentBlockHeight
 			// TODO: Broadcast txn to other peers
@@ -0,0 +1,105 @@
+package core
+
+import (
+	"github.com/cyyber/go-qrl/generated"
+	"github.com/golang/protobuf/proto"
+	"github.com/syndtr/goleveldb/leveldb"
+	"time"
+)
+
+// A receipt records when and from where the node received a block or a
+// transaction and how long validating it took. Receipts are node-local:
+// they're stored under their own keys, never serialized with the block or
+// transaction, so consensus bytes and hashes don't change. Transaction
+// receipts are kept by the pool until the transaction is mined.
+
+var receiptPrefix = []byte("receipt_")
+
+func receiptKey(hash []byte) []byte {
+	return append(append([]byte{}, receiptPrefix...), hash...)
+}
+
+func newReceipt(receivedAt time.Time, source string, peer string) *generated.ReceiptMetadata {
+	return &generated.ReceiptMetadata{
+		ReceivedAt:       uint64(receivedAt.UnixNano() / int64(time.Millisecond)),
+		Source:           source,
+		SourcePeer:       peer,
+		ValidationMicros: uint64(time.Since(receivedAt) / time.Microsecond),
+	}
+}
+
+// blockReceipt is what is known of a block as it's received, its receipt
+// is written with the block once validated
+type blockReceipt struct {
+	receivedAt time.Time
+	source     BlockSource
+	peer       string
+}
+
+// putBlockReceipt adds the receipt of block to the batch storing it
+func (c *Chain) putBlockReceipt(block *Block, receipt *blockReceipt, batch *leveldb.Batch) {
+	if receipt == nil {
+		return
+	}
+	if err := c.state.PutReceipt(block.HeaderHash(), newReceipt(receipt.receivedAt, receipt.source.String(), receipt.peer), batch); err != nil {
+		c.log.Warn("Failed to store block receipt", "error", err)
+	}
+}
+
+// receiptSource returns the source of a transaction received from peer,
+// submitted to the API when empty
+func receiptSource(peer string) string {
+	if peer == "" {
+		return "api"
+	}
+	return BlockFromSync.String()
+}
+
+func (s *State) PutReceipt(hash []byte, receipt *generated.ReceiptMetadata, batch *leveldb.Batch) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	value, err := proto.Marshal(receipt)
+	if err != nil {
+		return err
+	}
+	return s.db.Put(receiptKey(hash), value, batch)
+}
+
+func (s *State) GetReceipt(hash []byte) (*generated.ReceiptMetadata, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	value, err := s.db.Get(receiptKey(hash))
+	if err != nil {
+		return nil, err
+	}
+	receipt := &generated.ReceiptMetadata{}
+	if err := proto.Unmarshal(value, receipt); err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// putTxReceipts persists the receipts the pool holds for the transactions
+// of block, before they leave the pool
+func (c *Chain) putTxReceipts(block *Block, batch *leveldb.Batch) {
+	for _, protoTX := range block.Transactions() {
+		receipt := c.txPool.Receipt(protoTX.TransactionHash)
+		if receipt == nil {
+			continue
+		}
+		if err := c.state.PutReceipt(protoTX.TransactionHash, receipt, batch); err != nil {
+			c.log.Warn("Failed to store transaction receipt", "error", err)
+		}
+	}
+}
+
+// GetReceipt returns the receipt of the block or transaction hash, pending
+// transactions included
+func (c *Chain) GetReceipt(hash []byte) (*generated.ReceiptMetadata, error) {
+	if receipt := c.txPool.Receipt(hash); receipt != nil {
+		return receipt, nil
+	}
+	return c.state.GetReceipt(hash)
+}
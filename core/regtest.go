@@ -0,0 +1,100 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"github.com/cyyber/go-qrl/crypto"
+	"github.com/cyyber/go-qrl/generated"
+	"github.com/cyyber/go-qrl/misc"
+	"github.com/cyyber/go-qrl/pow"
+)
+
+// On regtest every block keeps the trivial genesis difficulty, so any
+// nonce meets the target and blocks are sealed without mining.
+
+const MaxGenerateBlocks = 1000
+
+var ErrNotRegtest = errors.New("only available on regtest")
+
+// GenerateBlocks seals count blocks paying minerAddress on top of the tip,
+// with the transactions of the pool, and returns them
+func (m *ChainManager) GenerateBlocks(count int, minerAddress []byte) ([]*Block, error) {
+	if m.chain.config.Dev.Network != NetworkRegtest {
+		return nil, ErrNotRegtest
+	}
+	if count <= 0 || count > MaxGenerateBlocks {
+		return nil, fmt.Errorf("block count must be between 1 and %d", MaxGenerateBlocks)
+	}
+	if err := misc.ValidateAddress(minerAddress); err != nil {
+		return nil, err
+	}
+
+	dt := pow.DifficultyTracker{}
+	validator := pow.GetPowValidator()
+
+	var blocks []*Block
+	for i := 0; i < count; i++ {
+		template, err := m.chain.CreateBlockTemplate(minerAddress, m.chain.Clock().Time())
+		if err != nil {
+			return blocks, err
+		}
+		target := dt.GetTarget(misc.BytesToUCharVector(template.Difficulty))
+
+		block := template.Block
+		found := false
+		for nonce := uint32(0); nonce < 1<<16; nonce++ {
+			block.SetNonces(nonce, 0)
+			if validator.VerifyInput(block.MiningBlob(), target) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return blocks, errors.New("no nonce found, the difficulty isn't trivial")
+		}
+
+		if !m.AddBlock(block, BlockFromMiner) {
+			return blocks, fmt.Errorf("generated block #%d rejected", block.BlockNumber())
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, nil
+}
+
+// RegtestConfig returns a new regtest configuration, whatever network the
+// process selected, for in-process tools such as the fork simulation
+func RegtestConfig() *Config {
+	config := &Config{
+		User: GetUserConfig(),
+		Dev:  GetDevConfig(),
+	}
+	applyRegtest(config)
+	return config
+}
+
+// DevAccountAddresses returns the addresses of the development accounts
+// funded at genesis, none outside of developer networks
+func DevAccountAddresses(config *Config) [][]byte {
+	if config.Dev.Network == NetworkMainnet {
+		return nil
+	}
+	var addresses [][]byte
+	for i := 0; i < int(config.Dev.DevAccounts); i++ {
+		xmss := crypto.DevAccount(i)
+		addresses = append(addresses, misc.UCharVectorToBytes(xmss.Address()))
+		xmss.Close()
+	}
+	return addresses
+}
+
+// AddDevAccounts adds the development accounts to the balances of the
+// genesis block, before it is loaded, so the genesis state follows from the
+// block alone
+func AddDevAccounts(genesisBlock *Block, config *Config) {
+	for _, address := range DevAccountAddresses(config) {
+		genesisBlock.block.GenesisBalance = append(genesisBlock.block.GenesisBalance, &generated.GenesisBalance{
+			Address: address,
+			Balance: config.Dev.DevAccountBalance,
+		})
+	}
+}
@@ -0,0 +1,690 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/cyyber/go-qrl/core/metadata"
+	"github.com/cyyber/go-qrl/core/transactions"
+	"github.com/cyyber/go-qrl/diagnostics"
+	"github.com/cyyber/go-qrl/generated"
+	"github.com/cyyber/go-qrl/log"
+	"github.com/cyyber/go-qrl/misc"
+	"github.com/golang/protobuf/proto"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/willf/bloom"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Secondary indexes are derived from the mainchain blocks and can be
+// rebuilt from them: the transaction metadata keyed by txhash along with
+// the burned total and burn transactions, the
+// transaction hashes of the address states, and the token metadata listing
+// the transfers of every token along with the token holders and transfers
+// indexes.
+const (
+	IndexTransactions   = "transactions"
+	IndexAddressHistory = "address_history"
+	IndexTokens         = "tokens"
+)
+
+var AllIndexes = []string{IndexTransactions, IndexAddressHistory, IndexTokens}
+
+const (
+	reindexBatchBlocks = 1000
+	maxIndexMismatches = 100
+)
+
+var (
+	ErrReindexRunning = errors.New("index rebuild already running")
+	ErrReindexStopped = errors.New("index rebuild stopped")
+	ErrReindexReorg   = errors.New("chain reorganized during the index rebuild, it must be restarted")
+	ErrUnknownIndex   = errors.New("unknown index")
+)
+
+// historyAddresses returns the addresses whose history lists tx, following
+// ApplyStateChanges of the transaction types
+func historyAddresses(tx transactions.TransactionInterface) [][]byte {
+	addresses := [][]byte{tx.AddrFrom()}
+	if addrFromPK := tx.AddrFromPK(); !bytes.Equal(addrFromPK, tx.AddrFrom()) {
+		addresses = append(addresses, addrFromPK)
+	}
+
+	var addrsTo [][]byte
+	switch t := tx.(type) {
+	case *transactions.CoinBase:
+		return [][]byte{t.AddrTo()}
+	case *transactions.TransferTransaction:
+		addrsTo = t.AddrsTo()
+	case *transactions.TransferTokenTransaction:
+		addrsTo = t.AddrsTo()
+	case *transactions.TokenTransaction:
+		for _, balance := range t.InitialBalances() {
+			addrsTo = append(addrsTo, balance.Address)
+		}
+	}
+	for _, addrTo := range addrsTo {
+		if !bytes.Equal(addrTo, tx.AddrFrom()) {
+			addresses = append(addresses, addrTo)
+		}
+	}
+	return addresses
+}
+
+var tokenMetadataPrefix = []byte("token_")
+
+func (s *State) PutTokenMetadata(tokenMetadata *metadata.TokenMetadata, batch *leveldb.Batch) error {
+	value, err := tokenMetadata.Serialize()
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.db.Put(append(append([]byte{}, tokenMetadataPrefix...), tokenMetadata.TokenTxHash()...), value, batch)
+}
+
+// Address history entries collected by a rebuild, keyed by address and
+// position in the chain, before they are moved into the address states
+var reindexHistoryPrefix = []byte("reindex_history_")
+
+// Expected transactions per block, to size the filter of rebuilt txhashes
+const reindexTxsPerBlock = 4
+
+func reindexHistoryKey(address []byte, blockNumber uint64, txIndex int) []byte {
+	key := append(append([]byte{}, reindexHistoryPrefix...), address...)
+	return append(key, tokenTransferPosition(blockNumber, uint32(txIndex))...)
+}
+
+// indexRebuild streams the rebuilt indexes to the database while the blocks
+// are scanned. Only the holder balances, address activities and burns are
+// aggregated in memory, the address histories are written as separate
+// records and moved into the address states once the scan reached the tip.
+type indexRebuild struct {
+	indexes map[string]bool
+
+	// Transactions indexed by the rebuild, metadata of the others is stale
+	txHashes *bloom.BloomFilter
+
+	tokenIndex   *tokenIndexUpdate
+	addressStats *addressStatsUpdate
+	burnIndex    *burnIndexUpdate
+}
+
+func (r *indexRebuild) addBlock(block *Block, state *State, batch *leveldb.Batch) error {
+	for index, protoTX := range block.Transactions() {
+		tx := transactions.ProtoToTransaction(protoTX)
+		if tx == nil {
+			return fmt.Errorf("block #%d: unsupported transaction", block.BlockNumber())
+		}
+
+		if r.indexes[IndexTransactions] {
+			if err := state.PutTxMetadata(tx, block.BlockNumber(), uint64(block.Timestamp()), batch); err != nil {
+				return err
+			}
+			r.txHashes.Add(tx.Txhash())
+			if err := r.burnIndex.applyTx(tx, block.BlockNumber(), index, uint64(block.Timestamp()), batch); err != nil {
+				return err
+			}
+		}
+		if r.indexes[IndexAddressHistory] {
+			for _, address := range historyAddresses(tx) {
+				if err := state.db.Put(reindexHistoryKey(address, block.BlockNumber(), index), tx.Txhash(), batch); err != nil {
+					return err
+				}
+			}
+			r.addressStats.addTx(tx, false)
+		}
+		if r.indexes[IndexTokens] {
+			if err := r.tokenIndex.applyTx(tx, block.BlockNumber(), index, uint64(block.Timestamp()), batch); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// write moves the address histories into the address states, rebuilds the
+// token metadata from the token transfers and stores the aggregates. The
+// Chain lock must be held so no block changes the address states meanwhile.
+func (r *indexRebuild) write(state *State) error {
+	batch := state.GetBatch()
+	if r.indexes[IndexTransactions] {
+		if err := r.burnIndex.write(batch); err != nil {
+			return err
+		}
+	}
+	if r.indexes[IndexAddressHistory] {
+		if err := state.moveRebuiltHistories(); err != nil {
+			return err
+		}
+		if err := r.addressStats.write(batch); err != nil {
+			return err
+		}
+	}
+	if r.indexes[IndexTokens] {
+		if err := state.rebuildTokenMetadata(); err != nil {
+			return err
+		}
+		if err := r.tokenIndex.write(batch); err != nil {
+			return err
+		}
+	}
+	return state.WriteBatch(batch)
+}
+
+// clearPrefix removes every key starting with prefix
+func (s *State) clearPrefix(prefix []byte) error {
+	batch := s.GetBatch()
+	err := s.db.IteratePrefix(prefix, nil, func(key []byte, value []byte) bool {
+		batch.Delete(append([]byte{}, key...))
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	return s.WriteBatch(batch)
+}
+
+// moveRebuiltHistories replaces the transaction hashes of the address
+// states with the rebuilt histories, one address at a time, and removes the
+// history records
+func (s *State) moveRebuiltHistories() error {
+	batch := s.GetBatch()
+	var address []byte
+	var hashes [][]byte
+	var addresses int
+
+	flush := func() error {
+		if address == nil {
+			return nil
+		}
+		addrState, err := s.GetAddressState(address)
+		if err != nil {
+			return fmt.Errorf("address %s: %s", misc.Qaddress(address), err)
+		}
+		addrState.data.TransactionHashes = hashes
+		return s.PutAddressesState(map[string]*AddressState{string(address): addrState}, batch)
+	}
+
+	var flushErr error
+	err := s.db.IteratePrefix(reindexHistoryPrefix, nil, func(key []byte, value []byte) bool {
+		keyAddress := key[len(reindexHistoryPrefix) : len(key)-12]
+		if !bytes.Equal(keyAddress, address) {
+			if flushErr = flush(); flushErr != nil {
+				return false
+			}
+			address = append([]byte{}, keyAddress...)
+			hashes = nil
+
+			addresses++
+			if addresses%reindexBatchBlocks == 0 {
+				if flushErr = s.WriteBatch(batch); flushErr != nil {
+					return false
+				}
+				batch = s.GetBatch()
+			}
+		}
+		hashes = append(hashes, append([]byte{}, value...))
+		batch.Delete(append([]byte{}, key...))
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	if flushErr != nil {
+		return flushErr
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	return s.WriteBatch(batch)
+}
+
+func (s *State) hasRebuiltHistory(address []byte) bool {
+	found := false
+	s.db.IteratePrefix(append(append([]byte{}, reindexHistoryPrefix...), address...), nil, func(key []byte, value []byte) bool {
+		found = true
+		return false
+	})
+	return found
+}
+
+// clearStaleHistories empties the history of the address states left
+// without any rebuilt entry, they only list transactions no longer on the
+// mainchain. Each address is cleared under the Chain lock so a block
+// applied meanwhile isn't lost, the blocks added since the scan started
+// are indexed again when the rebuild is written.
+func (c *Chain) clearStaleHistories() error {
+	var clearErr error
+	err := c.state.db.IteratePrefix(nil, nil, func(key []byte, value []byte) bool {
+		if len(key) != misc.AddressSize {
+			return true
+		}
+		addrState, err := DeSerializeAddressState(value)
+		if err != nil || len(addrState.TransactionHashes()) == 0 || c.state.hasRebuiltHistory(key) {
+			return true
+		}
+
+		c.lock.Lock()
+		defer c.lock.Unlock()
+
+		addrState, clearErr = c.state.GetAddressState(key)
+		if clearErr != nil {
+			return false
+		}
+		addrState.data.TransactionHashes = nil
+		clearErr = c.state.PutAddressesState(map[string]*AddressState{string(key): addrState}, nil)
+		return clearErr == nil
+	})
+	if err != nil {
+		return err
+	}
+	return clearErr
+}
+
+// removeStaleTxMetadata removes the metadata of the transactions that are
+// not in the mainchain block it points to. The metadata shares the bare
+// hash keyspace with the blocks, so the whole database is scanned; metadata
+// is told apart by the hash of its transaction matching its key. Only the
+// transactions missing from the rebuild are looked up in their block.
+func (c *Chain) removeStaleTxMetadata(txHashes *bloom.BloomFilter, height uint64) error {
+	batch := c.state.GetBatch()
+	var blockErr error
+	err := c.state.db.IteratePrefix(nil, nil, func(key []byte, value []byte) bool {
+		if len(key) != 32 || txHashes.Test(key) {
+			return true
+		}
+		m := &generated.TransactionMetadata{}
+		if proto.Unmarshal(value, m) != nil || m.Transaction == nil || !bytes.Equal(m.Transaction.TransactionHash, key) {
+			return true
+		}
+		// Blocks added since the scan started are checked when the rebuild
+		// is written
+		if m.BlockNumber > height {
+			return true
+		}
+
+		block, err := c.state.GetBlockByNumber(m.BlockNumber)
+		if err != nil {
+			blockErr = fmt.Errorf("failed to read block #%d: %s", m.BlockNumber, err)
+			return false
+		}
+		for _, protoTX := range block.Transactions() {
+			if bytes.Equal(protoTX.TransactionHash, key) {
+				return true
+			}
+		}
+		batch.Delete(append([]byte{}, key...))
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	if blockErr != nil {
+		return blockErr
+	}
+	return c.state.WriteBatch(batch)
+}
+
+// rebuildTokenMetadata builds the token metadata again from the token
+// transfers index, one token at a time
+func (s *State) rebuildTokenMetadata() error {
+	var tokenMetadata *metadata.TokenMetadata
+	var putErr error
+	err := s.db.IteratePrefix(tokenTransferPrefix, nil, func(key []byte, value []byte) bool {
+		tokenTxHash := key[len(tokenTransferPrefix) : len(key)-12]
+		transfer, err := decodeTokenTransfer(key[len(key)-12:], value)
+		if err != nil {
+			putErr = err
+			return false
+		}
+
+		if tokenMetadata == nil || !bytes.Equal(tokenMetadata.TokenTxHash(), tokenTxHash) {
+			if tokenMetadata != nil {
+				if putErr = s.PutTokenMetadata(tokenMetadata, nil); putErr != nil {
+					return false
+				}
+			}
+			tokenMetadata = metadata.CreateTokenMetadata(append([]byte{}, tokenTxHash...), append([]byte{}, tokenTxHash...))
+		}
+		if !transfer.Issuance {
+			tokenMetadata.Append(transfer.TxHash)
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	if putErr != nil {
+		return putErr
+	}
+	if tokenMetadata != nil {
+		return s.PutTokenMetadata(tokenMetadata, nil)
+	}
+	return nil
+}
+
+// rebuildIndexes scans the mainchain and rewrites the selected indexes. The
+// indexes are cleared first and written while scanning; address histories
+// and token metadata are completed at the end under the Chain lock, after
+// the blocks added during the scan were scanned too.
+func (c *Chain) rebuildIndexes(indexes map[string]bool, progress func(processed uint64, height uint64), quit <-chan struct{}) error {
+	height := c.Height()
+	rebuild := &indexRebuild{
+		indexes:      indexes,
+		txHashes:     bloom.NewWithEstimates(uint(height+1)*reindexTxsPerBlock, 0.0001),
+		tokenIndex:   newTokenIndexUpdate(c.state, true),
+		addressStats: newAddressStatsUpdate(c.state, true),
+		burnIndex:    newBurnIndexUpdate(c.state, true),
+	}
+
+	// Records of blocks rolled back without being unindexed would be left
+	// over, so the index prefixes are cleared before the scan. Transaction
+	// metadata and address histories share their keyspace with the blocks
+	// and address states, their stale entries are removed after the scan.
+	var prefixes [][]byte
+	if indexes[IndexTransactions] {
+		if err := c.state.clearBurnIndex(); err != nil {
+			return err
+		}
+	}
+	if indexes[IndexAddressHistory] {
+		if err := c.state.clearAddressStats(); err != nil {
+			return err
+		}
+		prefixes = append(prefixes, reindexHistoryPrefix)
+	}
+	if indexes[IndexTokens] {
+		if err := c.state.clearTokenIndexes(); err != nil {
+			return err
+		}
+		prefixes = append(prefixes, tokenMetadataPrefix)
+	}
+	for _, prefix := range prefixes {
+		if err := c.state.clearPrefix(prefix); err != nil {
+			return err
+		}
+	}
+
+	var lastHeaderHash []byte
+	batch := c.state.GetBatch()
+	err := c.IterateBlocks(0, height, func(block *Block) error {
+		select {
+		case <-quit:
+			return ErrReindexStopped
+		default:
+		}
+
+		lastHeaderHash = block.HeaderHash()
+		if err := rebuild.addBlock(block, c.state, batch); err != nil {
+			return err
+		}
+		if block.BlockNumber()%reindexBatchBlocks == 0 {
+			if err := c.state.WriteBatch(batch); err != nil {
+				return err
+			}
+			batch = c.state.GetBatch()
+			progress(block.BlockNumber()+1, height+1)
+		}
+		return nil
+	})
+	if err == ErrIterationReorg {
+		return ErrReindexReorg
+	}
+	if err != nil {
+		return err
+	}
+	if err := c.state.WriteBatch(batch); err != nil {
+		return err
+	}
+
+	if indexes[IndexTransactions] {
+		if err := c.removeStaleTxMetadata(rebuild.txHashes, height); err != nil {
+			return err
+		}
+	}
+	if indexes[IndexAddressHistory] {
+		if err := c.clearStaleHistories(); err != nil {
+			return err
+		}
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	batch = c.state.GetBatch()
+	for blockNumber := height + 1; blockNumber <= c.lastBlock.BlockNumber(); blockNumber++ {
+		block, err := c.getBlockByNumber(blockNumber)
+		if err != nil {
+			return fmt.Errorf("failed to read block #%d: %s", blockNumber, err)
+		}
+		if !bytes.Equal(block.PrevHeaderHash(), lastHeaderHash) {
+			return ErrReindexReorg
+		}
+		lastHeaderHash = block.HeaderHash()
+
+		if err := rebuild.addBlock(block, c.state, batch); err != nil {
+			return err
+		}
+		height = blockNumber
+	}
+	if !bytes.Equal(c.lastBlock.HeaderHash(), lastHeaderHash) {
+		return ErrReindexReorg
+	}
+	if err := c.state.WriteBatch(batch); err != nil {
+		return err
+	}
+
+	if err := rebuild.write(c.state); err != nil {
+		return err
+	}
+	progress(height+1, height+1)
+	return nil
+}
+
+type ReindexProgress struct {
+	Running bool
+	Indexes []string
+
+	// Blocks scanned out of the blocks of the chain
+	Processed uint64
+	Total     uint64
+
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Error      string
+}
+
+// Reindexer runs one index rebuild at a time in the background
+type Reindexer struct {
+	lock sync.Mutex
+
+	chain *Chain
+	log   log.Logger
+
+	progress ReindexProgress
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewReindexer(chain *Chain, log log.Logger) *Reindexer {
+	return &Reindexer{
+		chain: chain,
+		log:   log,
+	}
+}
+
+// Start rebuilds indexes, every index when none is given
+func (r *Reindexer) Start(indexes []string) error {
+	if len(indexes) == 0 {
+		indexes = AllIndexes
+	}
+	selected := make(map[string]bool)
+	for _, index := range indexes {
+		switch index {
+		case IndexTransactions, IndexAddressHistory, IndexTokens:
+			selected[index] = true
+		default:
+			return fmt.Errorf("%s: %s", ErrUnknownIndex, index)
+		}
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.progress.Running {
+		return ErrReindexRunning
+	}
+	if c := r.chain.config; c.User.ReadOnly {
+		return ErrReadOnly
+	} else if c.User.RelayOnly {
+		return ErrRelayOnly
+	}
+	r.progress = ReindexProgress{
+		Running:   true,
+		Indexes:   indexes,
+		StartedAt: time.Now(),
+	}
+	r.quit = make(chan struct{})
+
+	r.wg.Add(1)
+	diagnostics.Go("reindexer", func() {
+		defer r.wg.Done()
+		r.log.Info("Rebuilding indexes", "indexes", indexes)
+
+		err := r.chain.rebuildIndexes(selected, r.setProgress, r.quit)
+
+		r.lock.Lock()
+		defer r.lock.Unlock()
+		r.progress.Running = false
+		r.progress.FinishedAt = time.Now()
+		if err != nil {
+			r.progress.Error = err.Error()
+			r.log.Warn("Index rebuild failed", "error", err)
+			return
+		}
+		r.log.Info("Rebuilt indexes", "indexes", indexes, "blocks", r.progress.Total,
+			"duration", r.progress.FinishedAt.Sub(r.progress.StartedAt))
+	})
+	return nil
+}
+
+func (r *Reindexer) setProgress(processed uint64, total uint64) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.progress.Processed = processed
+	r.progress.Total = total
+}
+
+func (r *Reindexer) Progress() *ReindexProgress {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	progress := r.progress
+	return &progress
+}
+
+// Stop interrupts a running rebuild and waits for it. Indexes left half
+// rebuilt are missing entries until a new rebuild completes them.
+func (r *Reindexer) Stop() {
+	r.lock.Lock()
+	if r.progress.Running {
+		close(r.quit)
+	}
+	r.lock.Unlock()
+
+	r.wg.Wait()
+}
+
+type IndexMismatch struct {
+	Index       string
+	BlockNumber uint64
+	TxHash      []byte
+	Detail      string
+}
+
+type IndexCheckReport struct {
+	BlocksChecked       uint64
+	TransactionsChecked uint64
+	// At most 100 mismatches are reported
+	Mismatches []*IndexMismatch
+}
+
+func (r *IndexCheckReport) add(index string, block *Block, tx transactions.TransactionInterface, detail string) {
+	if len(r.Mismatches) < maxIndexMismatches {
+		r.Mismatches = append(r.Mismatches, &IndexMismatch{
+			Index:       index,
+			BlockNumber: block.BlockNumber(),
+			TxHash:      tx.Txhash(),
+			Detail:      detail,
+		})
+	}
+}
+
+func containsHash(hashes [][]byte, hash []byte) bool {
+	for _, h := range hashes {
+		if bytes.Equal(h, hash) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckIndexes compares the indexes with samples randomly chosen mainchain
+// blocks, detecting drift without a full rebuild
+func (c *Chain) CheckIndexes(samples int) (*IndexCheckReport, error) {
+	report := &IndexCheckReport{}
+	height := c.Height()
+	for i := 0; i < samples; i++ {
+		blockNumber := uint64(rand.Int63n(int64(height) + 1))
+		block, err := c.GetBlockByNumber(blockNumber)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read block #%d: %s", blockNumber, err)
+		}
+		report.BlocksChecked++
+
+		for _, protoTX := range block.Transactions() {
+			tx := transactions.ProtoToTransaction(protoTX)
+			if tx == nil {
+				continue
+			}
+			report.TransactionsChecked++
+			c.checkTxIndexes(block, tx, report)
+		}
+	}
+	return report, nil
+}
+
+func (c *Chain) checkTxIndexes(block *Block, tx transactions.TransactionInterface, report *IndexCheckReport) {
+	txMetadata, err := c.state.GetTxMetadata(tx.Txhash())
+	if err != nil {
+		report.add(IndexTransactions, block, tx, "missing transaction metadata")
+	} else if txMetadata.BlockNumber != block.BlockNumber() {
+		report.add(IndexTransactions, block, tx, fmt.Sprintf("metadata points to block #%d", txMetadata.BlockNumber))
+	}
+
+	for _, address := range historyAddresses(tx) {
+		addrState, err := c.state.GetAddressState(address)
+		if err != nil || !containsHash(addrState.TransactionHashes(), tx.Txhash()) {
+			report.add(IndexAddressHistory, block, tx, "missing from the history of "+misc.Qaddress(address))
+		}
+	}
+
+	switch t := tx.(type) {
+	case *transactions.TokenTransaction:
+		if _, err := c.state.GetTokenMetadata(t.Txhash()); err != nil {
+			report.add(IndexTokens, block, tx, "missing token metadata")
+		}
+	case *transactions.TransferTokenTransaction:
+		tokenMetadata, err := c.state.GetTokenMetadata(t.TokenTxhash())
+		if err != nil || !containsHash(tokenMetadata.PBData().TransferTokenTxHashes, t.Txhash()) {
+			report.add(IndexTokens, block, tx, "missing from the token metadata of "+misc.Bin2HStr(t.TokenTxhash()))
+		}
+	}
+}
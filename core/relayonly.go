@@ -0,0 +1,166 @@
+package core
+
+import (
+	"encoding/binary"
+	"errors"
+	"github.com/cyyber/go-qrl/diagnostics"
+	"github.com/cyyber/go-qrl/generated"
+	"github.com/cyyber/go-qrl/log"
+	"github.com/golang/protobuf/proto"
+	"sync"
+	"time"
+)
+
+// A relay-only node validates and relays transactions and blocks to
+// strengthen the gossip network from small machines. It doesn't mine nor
+// serve the public and mining APIs, and only keeps the bodies of the last
+// RelayWindow blocks: older blocks are rewritten with their header only, so
+// headerhashes and headers can still be served. Pruned blocks can't be
+// reindexed, and a peer requesting one is disconnected, the legacy protocol
+// having no negative answer, so it asks another peer instead of waiting for
+// its request to time out.
+
+var ErrRelayOnly = errors.New("node is a relay-only node")
+
+const prunedHeightKey = "pruned_height"
+
+// IsPruned returns whether the transactions of the block were dropped, every
+// complete block holding at least its coinbase
+func (b *Block) IsPruned() bool {
+	return len(b.block.Transactions) == 0
+}
+
+func (s *State) GetPrunedHeight() (uint64, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	value, err := s.db.Get([]byte(prunedHeightKey))
+	if err != nil || len(value) != 8 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint64(value), true
+}
+
+func (s *State) putPrunedHeight(height uint64) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	value := make([]byte, 8)
+	binary.BigEndian.PutUint64(value, height)
+	return s.db.Put([]byte(prunedHeightKey), value, nil)
+}
+
+// pruneBlock rewrites the block with its header only
+func (s *State) pruneBlock(headerHash []byte) error {
+	block, err := s.GetBlock(headerHash)
+	if err != nil {
+		return err
+	}
+	if block.IsPruned() {
+		return nil
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	value, err := proto.Marshal(&generated.Block{Header: block.block.Header})
+	if err != nil {
+		return err
+	}
+	return s.db.Put(headerHash, compressBlock(value, s.blockCompression()), nil)
+}
+
+// BlockPruner drops the bodies of the mainchain blocks older than the relay
+// window of relay-only nodes
+type BlockPruner struct {
+	state  *State
+	config *Config
+	log    log.Logger
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewBlockPruner(state *State, config *Config, log log.Logger) *BlockPruner {
+	return &BlockPruner{
+		state:  state,
+		config: config,
+		log:    log,
+	}
+}
+
+func (p *BlockPruner) Start() {
+	p.quit = make(chan struct{})
+	if !p.config.User.RelayOnly {
+		return
+	}
+	p.wg.Add(1)
+	diagnostics.Go("pruning", p.run)
+}
+
+func (p *BlockPruner) Stop() {
+	close(p.quit)
+	p.wg.Wait()
+}
+
+func (p *BlockPruner) run() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		if err := p.Prune(); err != nil {
+			p.log.Warn("Block pruning failed", "error", err)
+		}
+		select {
+		case <-p.quit:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// window returns the number of recent blocks kept whole, never less than
+// the blocks a reorg may roll back
+func (p *BlockPruner) window() uint64 {
+	window := p.config.User.RelayWindow
+	if window <= p.config.Dev.ReorgLimit {
+		window = p.config.Dev.ReorgLimit + 1
+	}
+	return window
+}
+
+// Prune drops the bodies of the blocks which left the relay window since
+// the last run. Genesis is kept whole.
+func (p *BlockPruner) Prune() error {
+	height, err := p.state.GetChainHeight()
+	if err != nil || height <= p.window() {
+		return err
+	}
+	to := height - p.window()
+
+	from := uint64(1)
+	if pruned, ok := p.state.GetPrunedHeight(); ok {
+		from = pruned + 1
+	}
+	for blockNumber := from; blockNumber <= to; blockNumber++ {
+		select {
+		case <-p.quit:
+			return nil
+		default:
+		}
+
+		mapping, err := p.state.GetBlockNumberMapping(blockNumber)
+		if err != nil || mapping == nil {
+			continue
+		}
+		if err := p.state.pruneBlock(mapping.Headerhash); err != nil {
+			return err
+		}
+		if err := p.state.putPrunedHeight(blockNumber); err != nil {
+			return err
+		}
+	}
+	return nil
+}
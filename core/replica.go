@@ -0,0 +1,96 @@
+package core
+
+import (
+	"errors"
+	"github.com/cyyber/go-qrl/diagnostics"
+	"github.com/cyyber/go-qrl/log"
+	"sync"
+	"time"
+)
+
+var ErrReadOnly = errors.New("node is a read-only replica")
+
+// Reload reopens the state and reloads the tip, used by read-only replicas
+// to follow the snapshots of the primary
+func (c *Chain) Reload() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if err := c.state.Reopen(); err != nil {
+		return err
+	}
+	c.blocks.reset()
+
+	height, err := c.state.GetChainHeight()
+	if err != nil {
+		return err
+	}
+	lastBlock, err := c.state.GetBlockByNumber(height)
+	if err != nil {
+		return err
+	}
+	blockMetadata, err := c.state.GetBlockMetadata(lastBlock.HeaderHash())
+	if err != nil {
+		return err
+	}
+
+	c.lastBlock = lastBlock
+	c.currentDifficulty = blockMetadata.BlockDifficulty()
+	c.stats.Reset()
+	c.loadStats()
+
+	return nil
+}
+
+// Replica periodically reloads the Chain of a read-only node
+type Replica struct {
+	chain *Chain
+	log   log.Logger
+
+	interval time.Duration
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewReplica(chain *Chain, config *Config, log log.Logger) *Replica {
+	return &Replica{
+		chain:    chain,
+		log:      log,
+		interval: time.Duration(config.User.ReplicaRefreshInterval) * time.Second,
+	}
+}
+
+func (r *Replica) Start() {
+	r.quit = make(chan struct{})
+	if r.interval == 0 {
+		return
+	}
+	r.wg.Add(1)
+	diagnostics.Go("replica", r.run)
+}
+
+func (r *Replica) Stop() {
+	close(r.quit)
+	r.wg.Wait()
+}
+
+func (r *Replica) run() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.quit:
+			return
+		case <-ticker.C:
+			if err := r.chain.Reload(); err != nil {
+				r.log.Warn("Failed to reload replica state", "error", err)
+				continue
+			}
+			r.log.Debug("Reloaded replica state", "height", r.chain.Height())
+		}
+	}
+}
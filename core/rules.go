@@ -0,0 +1,183 @@
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+)
+
+// RuleContext holds what the consensus rules need beyond the header and its
+// parent
+type RuleContext struct {
+	Config *Config
+	// Current time of the validating node
+	Now uint64
+
+	FeeReward      uint64
+	CoinbaseAmount uint64
+	TxMerkleRoot   []byte
+
+	// AncestorTimestamps returns the timestamps of up to count ancestors
+	// of the header, parent first. It may be nil when unavailable.
+	AncestorTimestamps func(count int) []uint32
+}
+
+// ConsensusRule is a named header check. Rules introduced by a fork only
+// apply from the block number returned by ActiveFrom, nil meaning always.
+type ConsensusRule struct {
+	Name       string
+	ActiveFrom func(config *Config) uint64
+	Check      func(header *BlockHeader, parent *Block, ctx *RuleContext) error
+}
+
+func (r *ConsensusRule) IsActive(blockNumber uint64, config *Config) bool {
+	return r.ActiveFrom == nil || blockNumber >= r.ActiveFrom(config)
+}
+
+type RuleError struct {
+	Rule string
+	Err  error
+}
+
+func (e *RuleError) Error() string {
+	return fmt.Sprintf("consensus rule %s: %s", e.Rule, e.Err)
+}
+
+// CheckRules runs the active rules in order and returns the first failure
+func CheckRules(rules []*ConsensusRule, header *BlockHeader, parent *Block, ctx *RuleContext) error {
+	for _, rule := range rules {
+		if !rule.IsActive(header.BlockNumber(), ctx.Config) {
+			continue
+		}
+		if err := rule.Check(header, parent, ctx); err != nil {
+			return &RuleError{Rule: rule.Name, Err: err}
+		}
+	}
+	return nil
+}
+
+// HeaderRules only depend on the header and the block content
+var HeaderRules = []*ConsensusRule{
+	{
+		Name: "timestamp_lead",
+		Check: func(header *BlockHeader, parent *Block, ctx *RuleContext) error {
+			allowedTimestamp := uint32(ctx.Now) + ctx.Config.Dev.BlockLeadTimestamp
+			if header.Timestamp() > allowedTimestamp {
+				return fmt.Errorf("timestamp %d is beyond the allowed lead timestamp %d", header.Timestamp(), allowedTimestamp)
+			}
+			return nil
+		},
+	},
+	{
+		Name: "timestamp_after_genesis",
+		Check: func(header *BlockHeader, parent *Block, ctx *RuleContext) error {
+			if header.Timestamp() < ctx.Config.Dev.Genesis.GenesisTimestamp {
+				return fmt.Errorf("timestamp %d is lower than genesis timestamp %d", header.Timestamp(), ctx.Config.Dev.Genesis.GenesisTimestamp)
+			}
+			return nil
+		},
+	},
+	{
+		Name: "headerhash",
+		Check: func(header *BlockHeader, parent *Block, ctx *RuleContext) error {
+			if !bytes.Equal(header.GenerateHeaderHash(), header.HeaderHash()) {
+				return fmt.Errorf("headerhash doesn't match the header")
+			}
+			return nil
+		},
+	},
+	{
+		Name: "block_reward",
+		Check: func(header *BlockHeader, parent *Block, ctx *RuleContext) error {
+			if expected := BlockRewardCalc(header.BlockNumber(), ctx.Config); header.BlockReward() != expected {
+				return fmt.Errorf("block reward %d, expected %d", header.BlockReward(), expected)
+			}
+			return nil
+		},
+	},
+	{
+		Name: "fee_reward",
+		Check: func(header *BlockHeader, parent *Block, ctx *RuleContext) error {
+			if header.FeeReward() != ctx.FeeReward {
+				return fmt.Errorf("fee reward %d, expected %d", header.FeeReward(), ctx.FeeReward)
+			}
+			return nil
+		},
+	},
+	{
+		Name: "coinbase_amount",
+		Check: func(header *BlockHeader, parent *Block, ctx *RuleContext) error {
+			if header.BlockReward()+header.FeeReward() != ctx.CoinbaseAmount {
+				return fmt.Errorf("block reward and fee reward don't sum up to the coinbase amount %d", ctx.CoinbaseAmount)
+			}
+			return nil
+		},
+	},
+	{
+		Name: "tx_merkle_root",
+		Check: func(header *BlockHeader, parent *Block, ctx *RuleContext) error {
+			if !bytes.Equal(header.TxMerkleRoot(), ctx.TxMerkleRoot) {
+				return fmt.Errorf("invalid tx merkle root")
+			}
+			return nil
+		},
+	},
+}
+
+// ParentRules check the header against its parent and ancestors
+var ParentRules = []*ConsensusRule{
+	{
+		Name: "block_number_sequence",
+		Check: func(header *BlockHeader, parent *Block, ctx *RuleContext) error {
+			if parent.BlockNumber()+1 != header.BlockNumber() {
+				return fmt.Errorf("block number %d doesn't follow parent %d", header.BlockNumber(), parent.BlockNumber())
+			}
+			return nil
+		},
+	},
+	{
+		Name: "prev_headerhash",
+		Check: func(header *BlockHeader, parent *Block, ctx *RuleContext) error {
+			if !bytes.Equal(parent.HeaderHash(), header.PrevHeaderHash()) {
+				return fmt.Errorf("prev headerhash doesn't match parent")
+			}
+			return nil
+		},
+	},
+	{
+		Name: "timestamp_monotonic",
+		Check: func(header *BlockHeader, parent *Block, ctx *RuleContext) error {
+			if header.Timestamp() <= parent.Timestamp() {
+				return fmt.Errorf("timestamp %d must be greater than parent timestamp %d", header.Timestamp(), parent.Timestamp())
+			}
+			return nil
+		},
+	},
+	{
+		Name: "timestamp_median",
+		ActiveFrom: func(config *Config) uint64 {
+			return config.Dev.MedianTimestampForkBlockNumber
+		},
+		Check: func(header *BlockHeader, parent *Block, ctx *RuleContext) error {
+			if ctx.AncestorTimestamps == nil {
+				return fmt.Errorf("ancestor timestamps unavailable")
+			}
+			median := medianTimestamp(ctx.AncestorTimestamps(int(ctx.Config.Dev.MedianTimestampWindow)))
+			if header.Timestamp() <= median {
+				return fmt.Errorf("timestamp %d must be greater than the median %d of the previous blocks", header.Timestamp(), median)
+			}
+			return nil
+		},
+	},
+}
+
+func medianTimestamp(timestamps []uint32) uint32 {
+	if len(timestamps) == 0 {
+		return 0
+	}
+	sorted := append([]uint32(nil), timestamps...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+	return sorted[len(sorted)/2]
+}
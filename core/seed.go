@@ -0,0 +1,36 @@
+package core
+
+import (
+	"errors"
+)
+
+// A seed node only helps other nodes join the network: it keeps many
+// lightweight peer connections and serves peer lists, headers and blocks,
+// but has no transaction pool, never mines and doesn't serve the public and
+// mining APIs. It's meant for community-run seed infrastructure. It still
+// syncs and stores the whole chain and state, as the blocks it serves are
+// validated first. Running it RelayOnly as well keeps only the bodies of
+// the recent blocks.
+
+var ErrSeedNode = errors.New("node is a seed node")
+
+// applyNodeModes turns off the services seed and relay-only nodes don't
+// run, see relayonly.go. LoadFile calls it once the configuration file is
+// applied.
+func (c *Config) applyNodeModes() {
+	if !c.User.SeedMode && !c.User.RelayOnly {
+		return
+	}
+	c.User.Miner.MiningEnabled = false
+	c.User.API.PublicAPI.Enabled = false
+	c.User.API.MiningAPI.Enabled = false
+}
+
+// MaxPeers returns the number of peers a seed node keeps connected, inbound
+// peers beyond it are refused
+func (c *Config) MaxPeers() int {
+	if c.User.SeedMode {
+		return int(c.User.Node.SeedMaxPeersLimit)
+	}
+	return int(c.User.Node.MaxPeersLimit)
+}
@@ -1,17 +1,17 @@
 package core
 
 import (
+	"bytes"
+	"encoding/binary"
+	"github.com/cyyber/go-qrl/core/metadata"
+	"github.com/cyyber/go-qrl/core/transactions"
 	"github.com/cyyber/go-qrl/db"
-	"github.com/cyyber/go-qrl/log"
-	"sync"
 	"github.com/cyyber/go-qrl/generated"
+	"github.com/cyyber/go-qrl/log"
 	"github.com/golang/protobuf/proto"
-	"encoding/binary"
-	"reflect"
-	"github.com/cyyber/go-qrl/core/transactions"
-	"github.com/cyyber/go-qrl/core/metadata"
-	"math"
 	"github.com/syndtr/goleveldb/leveldb"
+	"math"
+	"sync"
 )
 
 type State struct {
@@ -28,8 +28,12 @@ type RollbackStateInfo struct {
 	hashPath           [][]byte
 }
 
-func CreateState(log *log.Logger) (*State, error) {
-	newDB, err := db.NewDB("qrl", 16, 16, log)
+func CreateState(log *log.Logger, config *Config) (*State, error) {
+	options, err := config.User.Database.Options()
+	if err != nil {
+		return nil, err
+	}
+	newDB, err := db.NewDB(config.chainDBPath(), options, log)
 
 	if err != nil {
 		return nil, err
@@ -38,17 +42,88 @@ func CreateState(log *log.Logger) (*State, error) {
 	state := State {
 		db: newDB,
 		log: *log,
+		config: config,
+	}
+
+	return &state, err
+}
+
+// CreateMemState creates a State kept in memory
+func CreateMemState(log *log.Logger, config *Config) (*State, error) {
+	newDB, err := db.NewMemDB(log)
+
+	if err != nil {
+		return nil, err
+	}
+
+	state := State{
+		db:     newDB,
+		log:    *log,
+		config: config,
+	}
+
+	return &state, err
+}
+
+// CreateReadOnlyState opens the state of a read-only replica
+func CreateReadOnlyState(log *log.Logger, config *Config) (*State, error) {
+	options, err := config.User.Database.Options()
+	if err != nil {
+		return nil, err
+	}
+	newDB, err := db.NewReadOnlyDB(config.chainDBPath(), options, log)
+
+	if err != nil {
+		return nil, err
+	}
+
+	state := State{
+		db:     newDB,
+		log:    *log,
+		config: config,
 	}
 
 	return &state, err
 }
 
+func (s *State) InMemory() bool {
+	return s.db.InMemory()
+}
+
+func (s *State) Reopen() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.db.Reopen()
+}
+
+// Open opens the database again after Close
+func (s *State) Open() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.db.Open()
+}
+
+func (s *State) Close() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.db.Close()
+}
+
 func (s *State) GetBatch() *leveldb.Batch {
 	return s.db.GetBatch()
 }
 
-func (s *State) WriteBatch(batch *leveldb.Batch) {
-	s.db.WriteBatch(batch, true)
+func (s *State) WriteBatch(batch *leveldb.Batch) error {
+	return s.db.WriteBatch(batch, true)
+}
+
+// WriteChainBatch writes a batch covered by the chain WAL, it is synced
+// whatever the database profile since the WAL record is committed after it
+func (s *State) WriteChainBatch(batch *leveldb.Batch) error {
+	return s.db.WriteBatchSynced(batch)
 }
 
 func (s *State) GetBlockSizeLimit(b *Block) (int, error) {
@@ -76,7 +151,7 @@ func (s *State) PutBlock(b *Block, batch *leveldb.Batch) error {
 		return err
 	}
 
-	if err := s.db.Put(b.HeaderHash(), value, batch); err != nil {
+	if err := s.db.Put(b.HeaderHash(), compressBlock(value, s.blockCompression()), batch); err != nil {
 		return err
 	}
 	return nil
@@ -92,7 +167,11 @@ func (s *State) GetBlock(headerHash []byte) (*Block, error) {
 		return nil, err
 	}
 
-	return DeSerializeBlock(value)
+	data, err := decompressBlock(value)
+	if err != nil {
+		return nil, err
+	}
+	return DeSerializeBlock(data)
 }
 
 func (s *State) RemoveBlock(headerHash []byte) error {
@@ -125,7 +204,7 @@ func (s *State) GetBlockMetadata(headerHash []byte) (*metadata.BlockMetaData, er
 	value, err := s.db.Get(append([]byte("metadata_"), headerHash...))
 
 	if err != nil {
-
+		return nil, err
 	}
 
 	return metadata.DeSerializeBlockMetaData(value)
@@ -166,7 +245,7 @@ func (s *State) GetBlockNumberMapping(blockNumber uint64) (*generated.BlockNumbe
 	value, err := s.db.Get(key)
 
 	if err != nil {
-
+		return nil, err
 	}
 
 	b := &generated.BlockNumberMapping{}
@@ -175,13 +254,17 @@ func (s *State) GetBlockNumberMapping(blockNumber uint64) (*generated.BlockNumbe
 	return b, err
 }
 
-func (s *State) RemoveBlockNumberMapping(blockNumber uint64) error {
+func (s *State) RemoveBlockNumberMapping(blockNumber uint64, batch *leveldb.Batch) error {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
 	key := make([]byte, 8)
 	binary.BigEndian.PutUint64(key[0:], blockNumber)
 
+	if batch != nil {
+		batch.Delete(key)
+		return nil
+	}
 	return s.db.Delete(key)
 }
 
@@ -332,7 +415,7 @@ func (s *State) RemoveLastTransactions(block *Block, batch *leveldb.Batch) error
 
 	for _, protoTX := range block.Transactions()[1:] {
 		for index, txMetadata := range lastTransactions.TxMetadata {
-			if reflect.DeepEqual(txMetadata.Transaction.TransactionHash, protoTX.TransactionHash) {
+			if bytes.Equal(txMetadata.Transaction.TransactionHash, protoTX.TransactionHash) {
 				lastTransactions.TxMetadata = append(lastTransactions.TxMetadata[:index], lastTransactions.TxMetadata[index+1:]...)
 				break
 			}
@@ -537,10 +620,15 @@ func (s *State) UpdateTxMetadata(block *Block, batch *leveldb.Batch) error {
 
 	var feeReward uint64
 	var err error
+	tokenIndex := newTokenIndexUpdate(s, false)
+	addressStats := newAddressStatsUpdate(s, false)
+	burnIndex := newBurnIndexUpdate(s, false)
+	dailyStats := newDailyStatsUpdate(s, block, false)
 
-	for _, protoTX := range block.Transactions() {
+	for index, protoTX := range block.Transactions() {
 		tx := transactions.ProtoToTransaction(protoTX)
 		feeReward += tx.Fee()
+		dailyStats.addTx(tx)
 
 		s.PutTxMetadata(tx, block.BlockNumber(), uint64(block.Timestamp()), batch)
 
@@ -556,6 +644,27 @@ func (s *State) UpdateTxMetadata(block *Block, batch *leveldb.Batch) error {
 		if err != nil {
 			return err
 		}
+
+		if err := tokenIndex.applyTx(tx, block.BlockNumber(), index, uint64(block.Timestamp()), batch); err != nil {
+			return err
+		}
+		addressStats.addTx(tx, false)
+		if err := burnIndex.applyTx(tx, block.BlockNumber(), index, uint64(block.Timestamp()), batch); err != nil {
+			return err
+		}
+	}
+
+	if err := tokenIndex.write(batch); err != nil {
+		return err
+	}
+	if err := addressStats.write(batch); err != nil {
+		return err
+	}
+	if err := burnIndex.write(batch); err != nil {
+		return err
+	}
+	if err := dailyStats.write(addressStats.created(), batch); err != nil {
+		return err
 	}
 
 	tx := block.Transactions()[0]
@@ -578,10 +687,15 @@ func (s *State) RollbackTxMetadata(block *Block, batch *leveldb.Batch) error {
 
 	var feeReward uint64
 	var err error
+	tokenIndex := newTokenIndexUpdate(s, false)
+	addressStats := newAddressStatsUpdate(s, false)
+	burnIndex := newBurnIndexUpdate(s, false)
+	dailyStats := newDailyStatsUpdate(s, block, true)
 
-	for _, protoTX := range block.Transactions() {
+	for index, protoTX := range block.Transactions() {
 		tx := transactions.ProtoToTransaction(protoTX)
 		feeReward += tx.Fee()
+		dailyStats.addTx(tx)
 
 		s.PutTxMetadata(tx, block.BlockNumber(), uint64(block.Timestamp()), batch)
 
@@ -597,6 +711,23 @@ func (s *State) RollbackTxMetadata(block *Block, batch *leveldb.Batch) error {
 		if err != nil {
 			return err
 		}
+
+		tokenIndex.revertTx(tx, block.BlockNumber(), index, batch)
+		addressStats.addTx(tx, true)
+		burnIndex.revertTx(tx, block.BlockNumber(), index, batch)
+	}
+
+	if err := tokenIndex.write(batch); err != nil {
+		return err
+	}
+	if err := addressStats.write(batch); err != nil {
+		return err
+	}
+	if err := burnIndex.write(batch); err != nil {
+		return err
+	}
+	if err := dailyStats.write(addressStats.created(), batch); err != nil {
+		return err
 	}
 
 	tx := block.Transactions()[0]
@@ -677,6 +808,7 @@ func (s *State) PutAddressesState(addressesState map[string]*AddressState, batch
 			return err
 		}
 		s.db.Put(addrState.Address(), value, batch)
+		s.putOTSPages(addrState, batch)
 	}
 
 	return nil
@@ -692,7 +824,24 @@ func (s *State) GetAddressState(address []byte) (*AddressState, error) {
 		return nil, err
 	}
 
-	return DeSerializeAddressState(value)
+	addrState, err := DeSerializeAddressState(value)
+	if err != nil {
+		return nil, err
+	}
+	addrState.config = s.config
+	if len(addrState.data.OtsBitfield) == 0 {
+		addrState.ots = newOTSPages(s.otsPageLoader(address))
+	}
+
+	return addrState, nil
+}
+
+// getAddressStateRecord returns the address state as stored
+func (s *State) getAddressStateRecord(address []byte) ([]byte, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.db.Get(address)
 }
 
 func (s *State) GetAddressesState(addressesState map[string]*AddressState) error {
@@ -726,7 +875,7 @@ func (s *State) GetState(headerHash []byte, addressesState map[string]*AddressSt
 		mainchainBlock, err := s.GetBlockByNumber(block.BlockNumber())
 
 		if err == nil {
-			if reflect.DeepEqual(mainchainBlock.HeaderHash(), block.HeaderHash()) {
+			if bytes.Equal(mainchainBlock.HeaderHash(), block.HeaderHash()) {
 				break
 			}
 		}
@@ -752,7 +901,7 @@ func (s *State) GetState(headerHash []byte, addressesState map[string]*AddressSt
 		return nil, err
 	}
 
-	for reflect.DeepEqual(block.HeaderHash(), rollbackHeaderHash) {
+	for bytes.Equal(block.HeaderHash(), rollbackHeaderHash) {
 		txs := block.Transactions()
 		for i := len(txs); i >= 0; i-- {
 			tx := transactions.ProtoToTransaction(txs[i])
@@ -837,10 +986,8 @@ func (s *State) ReduceTotalCoinSupply(value uint64, batch *leveldb.Batch) error
 	return err
 }
 
+// GetMeasurement doesn't take the lock, GetBlock does
 func (s *State) GetMeasurement(blockTimestamp uint32, parentHeaderHash []byte, parentMetaData *metadata.BlockMetaData) (uint64, error) {
-	s.lock.Lock()
-	defer s.lock.Unlock()
-
 	var nthBlock *Block
 	var err error
 
@@ -0,0 +1,120 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var ErrStateDiffRange = errors.New("invalid state diff range")
+
+// AddressDiff is the change of an address between two heights. Before
+// values are zero for addresses created within the range.
+type AddressDiff struct {
+	Address []byte
+
+	BalanceBefore uint64
+	BalanceAfter  uint64
+	BalanceDelta  int64
+
+	NonceBefore uint64
+	NonceAfter  uint64
+	NonceDelta  int64
+
+	Created bool
+}
+
+type StateDiff struct {
+	FromHeight uint64
+	ToHeight   uint64
+	Addresses  []*AddressDiff
+}
+
+// GetStateDiff returns the addresses changed by the mainchain blocks
+// fromHeight+1 to toHeight. It is built from the undo records, so only the
+// heights within ReorgLimit of the tip are available.
+func (c *Chain) GetStateDiff(fromHeight uint64, toHeight uint64) (*StateDiff, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	height := c.lastBlock.BlockNumber()
+	if fromHeight >= toHeight || toHeight > height {
+		return nil, fmt.Errorf("%s: %d-%d, height %d", ErrStateDiffRange, fromHeight, toHeight, height)
+	}
+	undoHeight, ok := c.state.GetUndoHeight()
+	if !ok || fromHeight+1 < undoHeight {
+		return nil, ErrUndoRecordMissing
+	}
+
+	before := make(map[string][]byte)
+	after := make(map[string][]byte)
+	for blockNumber := fromHeight + 1; blockNumber <= height; blockNumber++ {
+		record, err := c.state.GetUndoRecord(blockNumber)
+		if err != nil {
+			return nil, fmt.Errorf("block #%d: %s", blockNumber, err)
+		}
+		for address, preState := range record.PreStates {
+			if isOTSPageKey([]byte(address)) {
+				continue
+			}
+			if blockNumber <= toHeight {
+				if _, ok := before[address]; !ok {
+					before[address] = preState
+				}
+				continue
+			}
+			// The state before a later block is the state at toHeight
+			if _, ok := before[address]; ok {
+				if _, ok := after[address]; !ok {
+					after[address] = preState
+				}
+			}
+		}
+	}
+
+	diff := &StateDiff{
+		FromHeight: fromHeight,
+		ToHeight:   toHeight,
+	}
+	for address, preState := range before {
+		addressDiff := &AddressDiff{
+			Address: []byte(address),
+			Created: len(preState) == 0,
+		}
+		if len(preState) != 0 {
+			addrState, err := DeSerializeAddressState(preState)
+			if err != nil {
+				return nil, err
+			}
+			addressDiff.BalanceBefore = addrState.Balance()
+			addressDiff.NonceBefore = addrState.Nonce()
+		}
+
+		postState, ok := after[address]
+		if !ok {
+			addrState, err := c.state.GetAddressState([]byte(address))
+			if err == nil {
+				addressDiff.BalanceAfter = addrState.Balance()
+				addressDiff.NonceAfter = addrState.Nonce()
+			}
+		} else if len(postState) != 0 {
+			addrState, err := DeSerializeAddressState(postState)
+			if err != nil {
+				return nil, err
+			}
+			addressDiff.BalanceAfter = addrState.Balance()
+			addressDiff.NonceAfter = addrState.Nonce()
+		}
+
+		// Balances are bounded by the coin supply, far below the int64 range
+		addressDiff.BalanceDelta = int64(addressDiff.BalanceAfter) - int64(addressDiff.BalanceBefore)
+		addressDiff.NonceDelta = int64(addressDiff.NonceAfter) - int64(addressDiff.NonceBefore)
+		diff.Addresses = append(diff.Addresses, addressDiff)
+	}
+
+	sort.Slice(diff.Addresses, func(i, j int) bool {
+		return bytes.Compare(diff.Addresses[i].Address, diff.Addresses[j].Address) < 0
+	})
+	return diff, nil
+}
@@ -0,0 +1,132 @@
+package core
+
+import (
+	"github.com/syndtr/goleveldb/leveldb"
+	"sync"
+	"sync/atomic"
+)
+
+// StateOverlay is a copy-on-write view of the address states. Address
+// states are copied from the parent layer, or from the State for the base
+// layer, the first time they are accessed, so changes applied through the
+// overlay never touch the underlying layer until Commit or Flush is called.
+// Dropping an overlay discards all its changes.
+type StateOverlay struct {
+	// Address states and OTS bitfield pages read from the State. Pages are
+	// loaded lazily, possibly outside the lock, so it's updated atomically
+	// and comes first to be 64-bit aligned.
+	reads uint64
+
+	lock sync.Mutex
+
+	state  *State
+	parent *StateOverlay
+
+	addressesState map[string]*AddressState
+
+	// Address states written to the State
+	writes uint64
+}
+
+func NewStateOverlay(state *State) *StateOverlay {
+	return &StateOverlay{
+		state:          state,
+		addressesState: make(map[string]*AddressState),
+	}
+}
+
+// Child returns a new layer on top of o, useful to evaluate a change which
+// may have to be discarded while keeping the changes already in o
+func (o *StateOverlay) Child() *StateOverlay {
+	return &StateOverlay{
+		state:          o.state,
+		parent:         o,
+		addressesState: make(map[string]*AddressState),
+	}
+}
+
+func (o *StateOverlay) lookup(address string) *AddressState {
+	if addrState, ok := o.addressesState[address]; ok {
+		return addrState
+	}
+
+	var addrState *AddressState
+	if o.parent != nil {
+		o.parent.lock.Lock()
+		addrState = o.parent.lookup(address).Clone()
+		o.parent.lock.Unlock()
+	} else {
+		var err error
+		atomic.AddUint64(&o.reads, 1)
+		addrState, err = o.state.GetAddressState([]byte(address))
+		if err != nil {
+			addrState = GetDefaultAddressState([]byte(address))
+		}
+		if addrState.ots != nil {
+			load := addrState.ots.load
+			addrState.ots.load = func(page uint16) []byte {
+				atomic.AddUint64(&o.reads, 1)
+				return load(page)
+			}
+		}
+	}
+
+	o.addressesState[address] = addrState
+	return addrState
+}
+
+// GetAddressState returns the overlay copy of the address state, changes
+// made to it are visible to later reads through the same overlay
+func (o *StateOverlay) GetAddressState(address []byte) *AddressState {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	return o.lookup(string(address))
+}
+
+// Prepare fills every key of addressesState with the overlay copy of the
+// address state, so the maps expected by ApplyStateChanges can be used
+// unchanged on top of the overlay
+func (o *StateOverlay) Prepare(addressesState map[string]*AddressState) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	for address := range addressesState {
+		addressesState[address] = o.lookup(address)
+	}
+}
+
+// Commit merges the changes of o into its parent layer
+func (o *StateOverlay) Commit() {
+	if o.parent == nil {
+		return
+	}
+
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.parent.lock.Lock()
+	defer o.parent.lock.Unlock()
+
+	for address, addrState := range o.addressesState {
+		o.parent.addressesState[address] = addrState
+	}
+	o.addressesState = make(map[string]*AddressState)
+}
+
+// Flush writes the changes of the base layer into batch
+func (o *StateOverlay) Flush(batch *leveldb.Batch) error {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	o.writes += uint64(len(o.addressesState))
+	return o.state.PutAddressesState(o.addressesState, batch)
+}
+
+// Stats returns the number of records read from and address states written
+// to the State by the base layer
+func (o *StateOverlay) Stats() (reads uint64, writes uint64) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	return atomic.LoadUint64(&o.reads), o.writes
+}
@@ -0,0 +1,235 @@
+package core
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"github.com/cyyber/go-qrl/misc"
+	"hash"
+	"sort"
+	"sync"
+)
+
+// Peers announce the state digest of their tip along with its headerhash,
+// the digest of the address states changed by the block as recorded in its
+// undo record. Two nodes applying the same block must compute the same
+// states, so peers reporting another digest for a mainchain block of ours
+// means one of the implementations has a consensus bug, even if both still
+// agree on the blocks. It's only detected within ReorgLimit of our tip, as
+// older undo records are pruned, and only against go-qrl peers, the Python
+// node doesn't send state_digest.
+//
+// The digest hashes a canonical encoding of the consensus fields of the
+// address states, independent of how a release stores them: sorted by
+// address, the sized address, uint64 balance, nonce and OTS counter, the
+// OTS bitfield expanded to MaxOTSTracking bits, then the tokens and slave
+// public keys sorted by key and the lattice keys in order, each list
+// prefixed with its uint32 length.
+
+const (
+	// Distinct peers which must announce the same mismatching digest for a
+	// block before it's alerted, so a single peer can't raise alerts
+	stateAlertPeers = 2
+	// Seconds between two alerts
+	stateAlertInterval = 10 * 60
+	// Mismatching digests tracked before the oldest reports are dropped
+	maxStateReports = 64
+)
+
+// StateMismatch is the last state digest announced by a peer which differs
+// from ours
+type StateMismatch struct {
+	Peer         string
+	BlockNumber  uint64
+	HeaderHash   []byte
+	LocalDigest  []byte
+	RemoteDigest []byte
+	Timestamp    uint64
+}
+
+type stateProbe struct {
+	lock sync.Mutex
+
+	mismatch *StateMismatch
+	checked  uint64
+
+	// Peers which announced each mismatching digest, keyed by headerhash and
+	// digest
+	reports   map[string]*stateReport
+	lastAlert uint64
+}
+
+type stateReport struct {
+	blockNumber uint64
+	peers       map[string]bool
+}
+
+// canonicalStateDigest hashes the address states in the canonical encoding
+func (c *Chain) canonicalStateDigest(addressesState map[string]*AddressState) []byte {
+	addresses := make([]string, 0, len(addressesState))
+	for address := range addressesState {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+
+	h := sha256.New()
+	for _, address := range addresses {
+		c.writeCanonicalAddressState(h, addressesState[address])
+	}
+	return h.Sum(nil)
+}
+
+func (c *Chain) writeCanonicalAddressState(h hash.Hash, a *AddressState) {
+	writeUint64 := func(value uint64) {
+		binary.Write(h, binary.BigEndian, value)
+	}
+	writeUint32 := func(value uint32) {
+		binary.Write(h, binary.BigEndian, value)
+	}
+	data := a.PBData()
+
+	writeSized(h, a.Address())
+	writeUint64(data.Balance)
+	writeUint64(data.Nonce)
+	writeUint64(data.OtsCounter)
+
+	bitfield := make([]byte, (uint64(c.config.Dev.MaxOTSTracking)+7)/8)
+	for i := uint64(0); i < uint64(c.config.Dev.MaxOTSTracking); i++ {
+		if a.otsBit(i) {
+			bitfield[i>>3] |= 1 << (i % 8)
+		}
+	}
+	writeSized(h, bitfield)
+
+	tokens := make([]string, 0, len(data.Tokens))
+	for token := range data.Tokens {
+		tokens = append(tokens, token)
+	}
+	writeUint32(uint32(len(tokens)))
+	for _, token := range sortedKeys(tokens) {
+		writeSized(h, []byte(token))
+		writeUint64(data.Tokens[token])
+	}
+
+	slaves := make([]string, 0, len(data.SlavePksAccessType))
+	for slave := range data.SlavePksAccessType {
+		slaves = append(slaves, slave)
+	}
+	writeUint32(uint32(len(slaves)))
+	for _, slave := range sortedKeys(slaves) {
+		writeSized(h, []byte(slave))
+		writeUint32(data.SlavePksAccessType[slave])
+	}
+
+	writeUint32(uint32(len(data.LatticePKList)))
+	for _, latticePK := range data.LatticePKList {
+		writeSized(h, latticePK.Txhash)
+		writeSized(h, latticePK.DilithiumPk)
+		writeSized(h, latticePK.KyberPk)
+	}
+}
+
+// StateDigest returns the digest of the address states after the mainchain
+// block headerHash, nil if the block isn't in the mainchain or its undo
+// record was pruned
+func (c *Chain) StateDigest(blockNumber uint64, headerHash []byte) []byte {
+	if !c.isMainchain(headerHash, blockNumber) {
+		return nil
+	}
+	record, err := c.state.GetUndoRecord(blockNumber)
+	if err != nil || !bytes.Equal(record.HeaderHash, headerHash) {
+		return nil
+	}
+	return record.StateDigest
+}
+
+// CheckStateDigest compares the state digest announced by peer, its host,
+// for a block with ours. The mismatch is recorded, and raises a critical
+// alert once enough peers announced the same digest.
+func (c *Chain) CheckStateDigest(peer string, blockNumber uint64, headerHash []byte, digest []byte) bool {
+	if len(digest) == 0 {
+		return true
+	}
+	local := c.StateDigest(blockNumber, headerHash)
+	if local == nil {
+		return true
+	}
+
+	c.stateProbe.lock.Lock()
+	defer c.stateProbe.lock.Unlock()
+
+	c.stateProbe.checked++
+	if bytes.Equal(local, digest) {
+		return true
+	}
+
+	now := c.clock.Time()
+	c.stateProbe.mismatch = &StateMismatch{
+		Peer:         peer,
+		BlockNumber:  blockNumber,
+		HeaderHash:   headerHash,
+		LocalDigest:  local,
+		RemoteDigest: digest,
+		Timestamp:    now,
+	}
+
+	if c.stateProbe.reports == nil {
+		c.stateProbe.reports = make(map[string]*stateReport)
+	}
+	key := string(headerHash) + string(digest)
+	report, ok := c.stateProbe.reports[key]
+	if !ok {
+		c.stateProbe.pruneReports()
+		report = &stateReport{blockNumber: blockNumber, peers: make(map[string]bool)}
+		c.stateProbe.reports[key] = report
+	}
+	report.peers[peer] = true
+
+	if len(report.peers) < stateAlertPeers || (c.stateProbe.lastAlert != 0 && now < c.stateProbe.lastAlert+stateAlertInterval) {
+		c.log.Warn("State digest mismatch with peer",
+			"peer", peer,
+			"blockNumber", blockNumber,
+			"headerhash", misc.Bin2HStr(headerHash),
+			"peers", len(report.peers))
+		return false
+	}
+	c.stateProbe.lastAlert = now
+	c.log.Crit("State digest mismatch with peer, possible consensus bug",
+		"peer", peer,
+		"blockNumber", blockNumber,
+		"headerhash", misc.Bin2HStr(headerHash),
+		"local", misc.Bin2HStr(local),
+		"remote", misc.Bin2HStr(digest),
+		"peers", len(report.peers))
+	return false
+}
+
+// pruneReports drops the reports of the oldest block once maxStateReports
+// digests are tracked
+func (p *stateProbe) pruneReports() {
+	if len(p.reports) < maxStateReports {
+		return
+	}
+	var oldest uint64
+	first := true
+	for _, report := range p.reports {
+		if first || report.blockNumber < oldest {
+			oldest = report.blockNumber
+			first = false
+		}
+	}
+	for key, report := range p.reports {
+		if report.blockNumber == oldest {
+			delete(p.reports, key)
+		}
+	}
+}
+
+// StateMismatch returns the last state digest mismatch detected, nil if
+// none was, and the number of digests compared
+func (c *Chain) StateMismatch() (*StateMismatch, uint64) {
+	c.stateProbe.lock.Lock()
+	defer c.stateProbe.lock.Unlock()
+
+	return c.stateProbe.mismatch, c.stateProbe.checked
+}
@@ -0,0 +1,97 @@
+package core
+
+import (
+	"bytes"
+	"github.com/cyyber/go-qrl/core/pool"
+	"github.com/cyyber/go-qrl/core/transactions"
+	"github.com/cyyber/go-qrl/misc"
+)
+
+// TxVerdict is the outcome of TestTransaction
+type TxVerdict struct {
+	TxHash   []byte
+	Accepted bool
+	// Set when the nonce is ahead of the expected one, the transaction would
+	// wait in the queue until the gap is filled
+	Queued bool
+	// Why the transaction would be refused, only set when not accepted
+	Reason pool.RejectReason
+	Error  string
+}
+
+func rejectVerdict(verdict *TxVerdict, reason pool.RejectReason, err error) *TxVerdict {
+	verdict.Reason = reason
+	verdict.Error = reason.String()
+	if err != nil {
+		verdict.Error = err.Error()
+	}
+	return verdict
+}
+
+// requiredBalance returns the Quanta tx spends from its address
+func requiredBalance(tx transactions.TransactionInterface) uint64 {
+	if t, ok := tx.(*transactions.TransferTransaction); ok {
+		return t.TotalAmounts() + tx.Fee()
+	}
+	return tx.Fee()
+}
+
+// TestTransaction runs the admission checks of SubmitTransaction, along with
+// the signature and state validation done when the transaction is mined,
+// without adding it to the pool. Nothing is relayed nor recorded as
+// rejected.
+func (c *Chain) TestTransaction(tx transactions.TransactionInterface) *TxVerdict {
+	verdict := &TxVerdict{TxHash: tx.Txhash()}
+
+	if _, ok := tx.(*transactions.CoinBase); ok {
+		return rejectVerdict(verdict, pool.RejectInvalid, nil)
+	}
+	if err := misc.ValidateAddress(tx.AddrFrom()); err != nil {
+		return rejectVerdict(verdict, pool.RejectInvalid, err)
+	}
+	// The node refuses a transaction whose hash doesn't commit to its content
+	if !bytes.Equal(tx.Txhash(), transactions.ExpectedTxhash(tx)) {
+		return rejectVerdict(verdict, pool.RejectInvalid, transactions.ErrTxhashMismatch)
+	}
+	hashableBytes := misc.BytesToUCharVector(tx.GetHashableBytes())
+	if !tx.Validate(hashableBytes, false) {
+		return rejectVerdict(verdict, pool.RejectInvalid, nil)
+	}
+	if !tx.ValidateXMSS(hashableBytes) {
+		return rejectVerdict(verdict, pool.RejectInvalidSignature, nil)
+	}
+
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	addressesState := make(map[string]*AddressState)
+	tx.SetAffectedAddress(addressesState)
+	NewStateOverlay(c.state).Prepare(addressesState)
+
+	addrFromState := addressesState[string(tx.AddrFrom())]
+	addrFromPKState := addrFromState
+	if addrFromPK := tx.GetSlave(); addrFromPK != nil {
+		addrFromPKState = addressesState[string(addrFromPK)]
+	}
+
+	if addrFromPKState.OTSKeyReuse(tx.OtsKey()) {
+		return rejectVerdict(verdict, pool.RejectOTSKeyUsed, nil)
+	}
+	if !tx.IsUnlockHeightValid(c.lastBlock.BlockNumber() + 1) {
+		return rejectVerdict(verdict, pool.RejectInvalid, nil)
+	}
+	if addrFromState.SpendableBalance(c.lastBlock.BlockNumber()+1) < requiredBalance(tx) {
+		return rejectVerdict(verdict, pool.RejectInsufficientBalance, nil)
+	}
+	if !tx.ValidateExtended(addrFromState, addrFromPKState) {
+		return rejectVerdict(verdict, pool.RejectInvalid, nil)
+	}
+
+	queued, err := c.txPool.Test(tx, c.stateNonce(tx.AddrFromPK()), c.lastBlock.BlockNumber())
+	if err != nil {
+		return rejectVerdict(verdict, pool.RejectReasonForError(err), err)
+	}
+	verdict.Accepted = true
+	verdict.Queued = queued
+	return verdict
+}
@@ -0,0 +1,366 @@
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"github.com/cyyber/go-qrl/core/transactions"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// The holders and the transfers of every token are indexed for explorers.
+// Both are updated with the transaction metadata when blocks are applied
+// and rolled back, and rebuilt with the tokens index.
+//
+// Keys:
+//   tokenholder_ | token txhash | address -> uint64 balance
+//   tokentransfer_ | token txhash | uint64 block number | uint32 tx index -> transfer
+// Transfer layout: uint64 timestamp | uint8 issuance | sized txhash |
+// sized address from | uint32 count | (sized address to | uint64 amount)*
+
+var (
+	tokenHolderPrefix   = []byte("tokenholder_")
+	tokenTransferPrefix = []byte("tokentransfer_")
+)
+
+var (
+	ErrUnknownToken         = errors.New("unknown token")
+	ErrInvalidTokenTransfer = errors.New("invalid token transfer record")
+)
+
+type TokenHolder struct {
+	Address []byte
+	Balance uint64
+}
+
+type TokenTransfer struct {
+	TxHash   []byte
+	AddrFrom []byte
+	AddrsTo  [][]byte
+	Amounts  []uint64
+	// Set for the initial balances of the token transaction
+	Issuance bool
+
+	BlockNumber uint64
+	Timestamp   uint64
+
+	// Block number and transaction index, the key suffix used as cursor
+	position []byte
+}
+
+func tokenHolderKey(tokenTxHash []byte, address []byte) []byte {
+	key := append(append([]byte{}, tokenHolderPrefix...), tokenTxHash...)
+	return append(key, address...)
+}
+
+func tokenTransferPosition(blockNumber uint64, txIndex uint32) []byte {
+	position := make([]byte, 12)
+	binary.BigEndian.PutUint64(position, blockNumber)
+	binary.BigEndian.PutUint32(position[8:], txIndex)
+	return position
+}
+
+func tokenTransferKey(tokenTxHash []byte, position []byte) []byte {
+	key := append(append([]byte{}, tokenTransferPrefix...), tokenTxHash...)
+	return append(key, position...)
+}
+
+func (t *TokenTransfer) encode() []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, t.Timestamp)
+	if t.Issuance {
+		buf.WriteByte(1)
+	} else {
+		buf.WriteByte(0)
+	}
+	writeSized(&buf, t.TxHash)
+	writeSized(&buf, t.AddrFrom)
+	binary.Write(&buf, binary.BigEndian, uint32(len(t.AddrsTo)))
+	for i, addrTo := range t.AddrsTo {
+		writeSized(&buf, addrTo)
+		binary.Write(&buf, binary.BigEndian, t.Amounts[i])
+	}
+	return buf.Bytes()
+}
+
+func decodeTokenTransfer(position []byte, data []byte) (*TokenTransfer, error) {
+	if len(position) != 12 {
+		return nil, ErrInvalidTokenTransfer
+	}
+	t := &TokenTransfer{
+		BlockNumber: binary.BigEndian.Uint64(position),
+		position:    append([]byte{}, position...),
+	}
+
+	r := bytes.NewReader(data)
+	var issuance uint8
+	var count uint32
+	if err := binary.Read(r, binary.BigEndian, &t.Timestamp); err != nil {
+		return nil, ErrInvalidTokenTransfer
+	}
+	if err := binary.Read(r, binary.BigEndian, &issuance); err != nil {
+		return nil, ErrInvalidTokenTransfer
+	}
+	t.Issuance = issuance == 1
+
+	var err error
+	if t.TxHash, err = readUndoField(r); err != nil {
+		return nil, ErrInvalidTokenTransfer
+	}
+	if t.AddrFrom, err = readUndoField(r); err != nil {
+		return nil, ErrInvalidTokenTransfer
+	}
+	if err := binary.Read(r, binary.BigEndian, &count); err != nil {
+		return nil, ErrInvalidTokenTransfer
+	}
+	for i := uint32(0); i < count; i++ {
+		addrTo, err := readUndoField(r)
+		if err != nil {
+			return nil, ErrInvalidTokenTransfer
+		}
+		var amount uint64
+		if err := binary.Read(r, binary.BigEndian, &amount); err != nil {
+			return nil, ErrInvalidTokenTransfer
+		}
+		t.AddrsTo = append(t.AddrsTo, addrTo)
+		t.Amounts = append(t.Amounts, amount)
+	}
+	if r.Len() != 0 {
+		return nil, ErrInvalidTokenTransfer
+	}
+	return t, nil
+}
+
+// tokenIndexUpdate accumulates the holder balances changed by the token
+// transactions of a block, several transactions of the same block may
+// move the same balance before the batch is written
+type tokenIndexUpdate struct {
+	s *State
+	// Balances start from zero instead of the stored ones, for rebuilds
+	fresh bool
+
+	balances map[string]uint64
+	order    []string
+}
+
+func newTokenIndexUpdate(s *State, fresh bool) *tokenIndexUpdate {
+	return &tokenIndexUpdate{
+		s:        s,
+		fresh:    fresh,
+		balances: make(map[string]uint64),
+	}
+}
+
+func (u *tokenIndexUpdate) balance(key string) uint64 {
+	if balance, ok := u.balances[key]; ok {
+		return balance
+	}
+	u.order = append(u.order, key)
+	if u.fresh {
+		return 0
+	}
+	value, err := u.s.db.Get([]byte(key))
+	if err != nil || len(value) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(value)
+}
+
+func (u *tokenIndexUpdate) add(tokenTxHash []byte, address []byte, amount uint64) {
+	key := string(tokenHolderKey(tokenTxHash, address))
+	u.balances[key] = u.balance(key) + amount
+}
+
+func (u *tokenIndexUpdate) sub(tokenTxHash []byte, address []byte, amount uint64) {
+	key := string(tokenHolderKey(tokenTxHash, address))
+	balance := u.balance(key)
+	if amount > balance {
+		amount = balance
+	}
+	u.balances[key] = balance - amount
+}
+
+// tokenTransfer returns the transfer recorded for tx and the token it moves,
+// nil for the other transaction types
+func tokenTransfer(tx transactions.TransactionInterface, timestamp uint64) (*TokenTransfer, []byte) {
+	switch t := tx.(type) {
+	case *transactions.TokenTransaction:
+		transfer := &TokenTransfer{
+			TxHash:    t.Txhash(),
+			AddrFrom:  t.AddrFrom(),
+			Issuance:  true,
+			Timestamp: timestamp,
+		}
+		for _, balance := range t.InitialBalances() {
+			transfer.AddrsTo = append(transfer.AddrsTo, balance.Address)
+			transfer.Amounts = append(transfer.Amounts, balance.Amount)
+		}
+		return transfer, t.Txhash()
+	case *transactions.TransferTokenTransaction:
+		return &TokenTransfer{
+			TxHash:    t.Txhash(),
+			AddrFrom:  t.AddrFrom(),
+			AddrsTo:   t.AddrsTo(),
+			Amounts:   t.Amounts(),
+			Timestamp: timestamp,
+		}, t.TokenTxhash()
+	}
+	return nil, nil
+}
+
+// applyTx indexes tx, the txIndex-th transaction of the block blockNumber
+func (u *tokenIndexUpdate) applyTx(tx transactions.TransactionInterface, blockNumber uint64, txIndex int, timestamp uint64, batch *leveldb.Batch) error {
+	transfer, tokenTxHash := tokenTransfer(tx, timestamp)
+	if transfer == nil {
+		return nil
+	}
+	for i, addrTo := range transfer.AddrsTo {
+		if !transfer.Issuance {
+			u.sub(tokenTxHash, transfer.AddrFrom, transfer.Amounts[i])
+		}
+		u.add(tokenTxHash, addrTo, transfer.Amounts[i])
+	}
+	position := tokenTransferPosition(blockNumber, uint32(txIndex))
+	return u.s.db.Put(tokenTransferKey(tokenTxHash, position), transfer.encode(), batch)
+}
+
+// revertTx removes tx from the index, undoing applyTx
+func (u *tokenIndexUpdate) revertTx(tx transactions.TransactionInterface, blockNumber uint64, txIndex int, batch *leveldb.Batch) {
+	transfer, tokenTxHash := tokenTransfer(tx, 0)
+	if transfer == nil {
+		return
+	}
+	for i, addrTo := range transfer.AddrsTo {
+		u.sub(tokenTxHash, addrTo, transfer.Amounts[i])
+		if !transfer.Issuance {
+			u.add(tokenTxHash, transfer.AddrFrom, transfer.Amounts[i])
+		}
+	}
+	u.s.deleteKey(tokenTransferKey(tokenTxHash, tokenTransferPosition(blockNumber, uint32(txIndex))), batch)
+}
+
+// write stores the changed balances, holders left with nothing are removed
+func (u *tokenIndexUpdate) write(batch *leveldb.Batch) error {
+	for _, key := range u.order {
+		balance := u.balances[key]
+		if balance == 0 {
+			u.s.deleteKey([]byte(key), batch)
+			continue
+		}
+		value := make([]byte, 8)
+		binary.BigEndian.PutUint64(value, balance)
+		if err := u.s.db.Put([]byte(key), value, batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *State) deleteKey(key []byte, batch *leveldb.Batch) {
+	if batch != nil {
+		batch.Delete(key)
+		return
+	}
+	s.db.Delete(key)
+}
+
+// clearTokenIndexes removes the token holders and transfers, before they
+// are rebuilt
+func (s *State) clearTokenIndexes() error {
+	batch := s.GetBatch()
+	for _, prefix := range [][]byte{tokenHolderPrefix, tokenTransferPrefix} {
+		err := s.db.IteratePrefix(prefix, nil, func(key []byte, value []byte) bool {
+			batch.Delete(append([]byte{}, key...))
+			return true
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return s.WriteBatch(batch)
+}
+
+// cursorStart returns the first key of prefix after cursor, keys of the
+// same index all having the same length
+func cursorStart(prefix []byte, cursor []byte) []byte {
+	if cursor == nil {
+		return nil
+	}
+	return append(append(append([]byte{}, prefix...), cursor...), 0)
+}
+
+// GetTokenHolders returns up to limit holders of the token after cursor in
+// address order, and the cursor of the next page, nil on the last page
+func (s *State) GetTokenHolders(tokenTxHash []byte, cursor []byte, limit int) ([]*TokenHolder, []byte, error) {
+	prefix := append(append([]byte{}, tokenHolderPrefix...), tokenTxHash...)
+
+	var holders []*TokenHolder
+	more := false
+	err := s.db.IteratePrefix(prefix, cursorStart(prefix, cursor), func(key []byte, value []byte) bool {
+		if len(holders) == limit {
+			more = true
+			return false
+		}
+		if len(value) == 8 {
+			holders = append(holders, &TokenHolder{
+				Address: append([]byte{}, key[len(prefix):]...),
+				Balance: binary.BigEndian.Uint64(value),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if more {
+		return holders, holders[len(holders)-1].Address, nil
+	}
+	return holders, nil, nil
+}
+
+// GetTokenTransfers returns up to limit transfers of the token after cursor,
+// oldest first, and the cursor of the next page, nil on the last page
+func (s *State) GetTokenTransfers(tokenTxHash []byte, cursor []byte, limit int) ([]*TokenTransfer, []byte, error) {
+	prefix := append(append([]byte{}, tokenTransferPrefix...), tokenTxHash...)
+
+	var transfers []*TokenTransfer
+	more := false
+	var decodeErr error
+	err := s.db.IteratePrefix(prefix, cursorStart(prefix, cursor), func(key []byte, value []byte) bool {
+		if len(transfers) == limit {
+			more = true
+			return false
+		}
+		transfer, err := decodeTokenTransfer(key[len(prefix):], value)
+		if err != nil {
+			decodeErr = err
+			return false
+		}
+		transfers = append(transfers, transfer)
+		return true
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if decodeErr != nil {
+		return nil, nil, decodeErr
+	}
+	if more {
+		return transfers, transfers[len(transfers)-1].position, nil
+	}
+	return transfers, nil, nil
+}
+
+func (c *Chain) GetTokenHolders(tokenTxHash []byte, cursor []byte, limit int) ([]*TokenHolder, []byte, error) {
+	if _, err := c.state.GetTokenMetadata(tokenTxHash); err != nil {
+		return nil, nil, ErrUnknownToken
+	}
+	return c.state.GetTokenHolders(tokenTxHash, cursor, limit)
+}
+
+func (c *Chain) GetTokenTransfers(tokenTxHash []byte, cursor []byte, limit int) ([]*TokenTransfer, []byte, error) {
+	if _, err := c.state.GetTokenMetadata(tokenTxHash); err != nil {
+		return nil, nil, ErrUnknownToken
+	}
+	return c.state.GetTokenTransfers(tokenTxHash, cursor, limit)
+}
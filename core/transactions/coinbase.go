@@ -1,12 +1,12 @@
 package transactions
 
 import (
-	"github.com/theQRL/qrllib/goqrllib"
 	"bytes"
 	"encoding/binary"
-	"github.com/cyyber/go-qrl/misc"
-	"reflect"
 	"github.com/cyyber/go-qrl/core"
+	"github.com/cyyber/go-qrl/generated"
+	"github.com/cyyber/go-qrl/misc"
+	"github.com/theQRL/qrllib/goqrllib"
 )
 
 type CoinBase struct {
@@ -21,23 +21,24 @@ func (tx *CoinBase) Amount() uint64 {
 	return tx.data.GetCoinbase().GetAmount()
 }
 
-func (tx *CoinBase) GetHashableBytes() []byte {
+func (tx *CoinBase) GetHashableBytes() goqrllib.UcharVector {
 	tmp := new(bytes.Buffer)
 	tmp.Write(tx.MasterAddr())
 	tmp.Write(tx.AddrTo())
 	binary.Write(tmp, binary.BigEndian, uint64(tx.Nonce()))
 	binary.Write(tmp, binary.BigEndian, uint64(tx.Amount()))
+	tx.hashExpiry(tmp)
 
 	tmptxhash := misc.UcharVector{}
 	tmptxhash.AddBytes(tmp.Bytes())
 	tmptxhash.New(goqrllib.Sha2_256(tmptxhash.GetData()))
 
-	return tmptxhash.GetBytes()
+	return tmptxhash.GetData()
 }
 
 func (tx *CoinBase) UpdateMiningAddress(miningAddress []byte) {
 	tx.data.GetCoinbase().AddrTo = miningAddress
-	tx.data.TransactionHash = tx.GetHashableBytes()
+	tx.data.TransactionHash = misc.UCharVectorToBytes(tx.GetHashableBytes())
 }
 
 func (tx *CoinBase) validateCustom() bool {
@@ -49,15 +50,34 @@ func (tx *CoinBase) validateCustom() bool {
 	return true
 }
 
-func (tx *CoinBase) ValidateExtended() bool {
-	if reflect.DeepEqual(tx.MasterAddr(), tx.config.Dev.Genesis.CoinbaseAddress) {
+func (tx *CoinBase) ValidateExtended(blockNumber uint64) bool {
+	if !bytes.Equal(tx.MasterAddr(), tx.config.Dev.Genesis.CoinbaseAddress) {
 		tx.log.Warn("Master address doesnt match with coinbase_address")
 		tx.log.Warn(string(tx.MasterAddr()), tx.config.Dev.Genesis.CoinbaseAddress)
 		return false
 	}
 
-	if !(core.IsValidAddress(tx.MasterAddr()) && core.IsValidAddress(tx.AddrTo())) {
-		tx.log.Warn("Invalid address addr_from: %s addr_to: %s", tx.MasterAddr(), tx.AddrTo())
+	// The coinbase address is not a valid XMSS address, only the miner
+	// address is validated
+	if !core.IsValidAddress(tx.AddrTo()) {
+		tx.log.Warn("Invalid miner address", "addr_to", misc.Bin2HStr(tx.AddrTo()))
+		return false
+	}
+
+	// The coinbase nonce is the block number + 1 so that every coinbase
+	// transaction has a different hash
+	if tx.Nonce() != blockNumber+1 {
+		tx.log.Warn("Invalid coinbase nonce", "nonce", tx.Nonce(), "expected", blockNumber+1)
+		return false
+	}
+
+	if len(tx.PK()) != 0 || len(tx.Signature()) != 0 {
+		tx.log.Warn("Coinbase transaction must not be signed")
+		return false
+	}
+
+	if !tx.config.Dev.ValidateCoinbaseAddress(tx.AddrTo(), blockNumber) {
+		tx.log.Warn("Coinbase address not allowed by consensus rules", "addr_to", misc.Bin2HStr(tx.AddrTo()), "block", blockNumber)
 		return false
 	}
 
@@ -97,20 +117,24 @@ func (tx *CoinBase) RevertStateChanges(addressesState map[string]core.AddressSta
 }
 
 func (tx *CoinBase) SetAffectedAddress(addressesState map[string]core.AddressState) {
-	addressesState[string(tx.AddrFrom())] = core.AddressState{}
-	addressesState[string(tx.PK())] = core.AddressState{}
-
 	addressesState[string(tx.MasterAddr())] = core.AddressState{}
 	addressesState[string(tx.AddrTo())] = core.AddressState{}
 }
 
-func CreateCoinBase(minerAddress []byte, blockNumber uint64, amount uint64) *CoinBase {
+func CreateCoinBase(minerAddress []byte, blockNumber uint64, amount uint64, config *core.Config) *CoinBase {
 	tx := &CoinBase{}
-	tx.data.MasterAddr = tx.config.Dev.Genesis.CoinbaseAddress
-	tx.data.GetCoinbase().AddrTo = minerAddress
-	tx.data.GetCoinbase().Amount = amount
-	tx.data.Nonce = blockNumber + 1
-	tx.data.TransactionHash = tx.GetHashableBytes()
+	tx.config = *config
+	tx.data = &generated.Transaction{
+		MasterAddr: config.Dev.Genesis.CoinbaseAddress,
+		Nonce:      blockNumber + 1,
+		TransactionType: &generated.Transaction_Coinbase{
+			Coinbase: &generated.Transaction_CoinBase{
+				AddrTo: minerAddress,
+				Amount: amount,
+			},
+		},
+	}
+	tx.data.TransactionHash = misc.UCharVectorToBytes(tx.GetHashableBytes())
 
 	return tx
 }
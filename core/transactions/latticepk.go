@@ -0,0 +1,123 @@
+package transactions
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/cyyber/go-qrl/core"
+	"github.com/cyyber/go-qrl/generated"
+	"github.com/cyyber/go-qrl/misc"
+	"github.com/theQRL/qrllib/goqrllib"
+)
+
+// LatticePublicKey publishes the kyber and dilithium public keys of an
+// address, used to establish encrypted ephemeral channels
+type LatticePublicKey struct {
+	Transaction
+}
+
+func (tx *LatticePublicKey) KyberPk() []byte {
+	return tx.data.GetLatticePK().KyberPk
+}
+
+func (tx *LatticePublicKey) DilithiumPk() []byte {
+	return tx.data.GetLatticePK().DilithiumPk
+}
+
+func (tx *LatticePublicKey) GetHashableBytes() goqrllib.UcharVector {
+	tmp := new(bytes.Buffer)
+	tmp.Write(tx.MasterAddr())
+	binary.Write(tmp, binary.BigEndian, uint64(tx.Fee()))
+	tmp.Write(tx.KyberPk())
+	tmp.Write(tx.DilithiumPk())
+	tx.hashExpiry(tmp)
+
+	tmptxhash := misc.UcharVector{}
+	tmptxhash.AddBytes(tmp.Bytes())
+	tmptxhash.New(goqrllib.Sha2_256(tmptxhash.GetData()))
+
+	return tmptxhash.GetData()
+}
+
+func (tx *LatticePublicKey) validateCustom() bool {
+	if len(tx.KyberPk()) == 0 || len(tx.KyberPk()) > int(tx.config.Dev.Transaction.MaxLatticePKSize) {
+		tx.log.Warn("Invalid kyber pk length", "length", len(tx.KyberPk()))
+		return false
+	}
+
+	if len(tx.DilithiumPk()) == 0 || len(tx.DilithiumPk()) > int(tx.config.Dev.Transaction.MaxLatticePKSize) {
+		tx.log.Warn("Invalid dilithium pk length", "length", len(tx.DilithiumPk()))
+		return false
+	}
+
+	return true
+}
+
+func (tx *LatticePublicKey) ValidateExtended(addrFromState *core.AddressState, addrFromPKState *core.AddressState) bool {
+	if !tx.ValidateSlave(addrFromState, addrFromPKState) {
+		return false
+	}
+
+	balance := addrFromState.Balance()
+
+	if balance < tx.Fee() {
+		tx.log.Warn("[LatticePublicKey] State validation failed for %s because: Insufficient funds", misc.Bin2HStr(tx.Txhash()))
+		tx.log.Warn("Balance: %s, Fee: %s", balance, tx.Fee())
+		return false
+	}
+
+	for _, latticePK := range addrFromState.LatticePKList() {
+		if bytes.Equal(latticePK.KyberPk, tx.KyberPk()) || bytes.Equal(latticePK.DilithiumPk, tx.DilithiumPk()) {
+			tx.log.Warn("[LatticePublicKey] State validation failed for %s because: Lattice public key already published", misc.Bin2HStr(tx.Txhash()))
+			return false
+		}
+	}
+
+	if addrFromPKState.OTSKeyReuse(tx.OtsKey()) {
+		tx.log.Warn("[LatticePublicKey] State validation failed for %s because: OTS Public key re-use detected", misc.Bin2HStr(tx.Txhash()))
+		return false
+	}
+
+	return true
+}
+
+func (tx *LatticePublicKey) ApplyStateChanges(addressesState map[string]core.AddressState) {
+	if addrState, ok := addressesState[string(tx.AddrFrom())]; ok {
+		addrState.AddBalance(tx.Fee() * -1)
+		addrState.AddLatticePK(tx)
+		addrState.AppendTransactionHash(tx.Txhash())
+	}
+
+	tx.applyStateChangesForPK(addressesState)
+}
+
+func (tx *LatticePublicKey) RevertStateChanges(addressesState map[string]core.AddressState, state *core.State) {
+	if addrState, ok := addressesState[string(tx.AddrFrom())]; ok {
+		addrState.AddBalance(tx.Fee())
+		addrState.RemoveLatticePK(tx)
+		addrState.RemoveTransactionHash(tx.Txhash())
+	}
+
+	tx.revertStateChangesForPK(addressesState, state)
+}
+
+func (tx *LatticePublicKey) SetAffectedAddress(addressesState map[string]core.AddressState) {
+	addressesState[string(tx.AddrFrom())] = core.AddressState{}
+	addressesState[string(tx.PK())] = core.AddressState{}
+}
+
+func CreateLatticePublicKey(fee uint64, kyberPK []byte, dilithiumPK []byte, xmssPK []byte, masterAddr []byte) *LatticePublicKey {
+	tx := &LatticePublicKey{}
+	tx.data = &generated.Transaction{
+		MasterAddr: masterAddr,
+		Fee:        fee,
+		PublicKey:  xmssPK,
+		TransactionType: &generated.Transaction_LatticePK{
+			LatticePK: &generated.Transaction_LatticePublicKey{
+				KyberPk:     kyberPK,
+				DilithiumPk: dilithiumPK,
+			},
+		},
+	}
+
+	return tx
+}
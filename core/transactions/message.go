@@ -21,6 +21,7 @@ func (tx *MessageTransaction) GetHashableBytes() goqrllib.UcharVector {
 	tmp.Write(tx.MasterAddr())
 	binary.Write(tmp, binary.BigEndian, uint64(tx.Fee()))
 	tmp.Write(tx.MessageHash())
+	tx.hashExpiry(tmp)
 
 	tmptxhash := misc.UcharVector{}
 	tmptxhash.AddBytes(tmp.Bytes())
@@ -48,17 +49,17 @@ func (tx *MessageTransaction) ValidateExtended(addrFromState *core.AddressState,
 	balance := addrFromState.Balance()
 
 	if tx.Fee() < 0 {
-		tx.log.Warn("State validation failed for %s because: Negative txn fee", string(tx.Txhash()))
+		tx.log.Warn("State validation failed for %s because: Negative txn fee", misc.Bin2HStr(tx.Txhash()))
 	}
 
 	if balance < tx.Fee() {
-		tx.log.Warn("State validation failed for %s because: Insufficient funds", string(tx.Txhash()))
+		tx.log.Warn("State validation failed for %s because: Insufficient funds", misc.Bin2HStr(tx.Txhash()))
 		tx.log.Warn("Balance: %s, Fee: %s", balance, tx.Fee())
 		return false
 	}
 
 	if addrFromPKState.OTSKeyReuse(tx.OtsKey()) {
-		tx.log.Warn("State validation failed for %s because: OTS Public key re-use detected", string(tx.Txhash()))
+		tx.log.Warn("State validation failed for %s because: OTS Public key re-use detected", misc.Bin2HStr(tx.Txhash()))
 		return false
 	}
 
@@ -27,8 +27,10 @@ func (tx *SlaveTransaction) GetHashableBytes() goqrllib.UcharVector {
 
 	for i := 0; i < len(tx.SlavePKs()); i++ {
 		tmp.Write(tx.SlavePKs()[i])
-		binary.Write(tmp, binary.BigEndian, tx.AccessTypes()[i])
+		// Access types are hashed as 8 bytes, as done by the Python node
+		binary.Write(tmp, binary.BigEndian, uint64(tx.AccessTypes()[i]))
 	}
+	tx.hashExpiry(tmp)
 
 	tmptxhash := misc.UcharVector{}
 	tmptxhash.AddBytes(tmp.Bytes())
@@ -70,18 +72,18 @@ func (tx *SlaveTransaction) ValidateExtended(addrFromState *core.AddressState, a
 	balance := addrFromState.Balance()
 
 	if tx.Fee() < 0 {
-		tx.log.Warn("[SlaveTransaction] State validation failed for %s because: Negative Send", string(tx.Txhash()))
+		tx.log.Warn("[SlaveTransaction] State validation failed for %s because: Negative Send", misc.Bin2HStr(tx.Txhash()))
 		return false
 	}
 
 	if balance < tx.Fee() {
-		tx.log.Warn("[SlaveTransaction] State validation failed for %s because: Insufficient funds", string(tx.Txhash()))
+		tx.log.Warn("[SlaveTransaction] State validation failed for %s because: Insufficient funds", misc.Bin2HStr(tx.Txhash()))
 		tx.log.Warn("Balance: %s, Amount: %s", balance, tx.Fee())
 		return false
 	}
 
 	if addrFromPkState.OTSKeyReuse(tx.OtsKey()) {
-		tx.log.Warn("[SlaveTransaction] State validation failed for %s because: OTS Public key re-use detected", string(tx.Txhash()))
+		tx.log.Warn("[SlaveTransaction] State validation failed for %s because: OTS Public key re-use detected", misc.Bin2HStr(tx.Txhash()))
 		return false
 	}
 
@@ -7,7 +7,6 @@ import (
 	"github.com/cyyber/go-qrl/misc"
 	"github.com/theQRL/qrllib/goqrllib"
 	"github.com/cyyber/go-qrl/core"
-	"reflect"
 	"errors"
 	"math"
 )
@@ -36,7 +35,7 @@ func (tx *TokenTransaction) InitialBalances() []*generated.AddressAmount {
 	return tx.data.GetToken().InitialBalances
 }
 
-func (tx *TokenTransaction) GetHashable() goqrllib.UcharVector {
+func (tx *TokenTransaction) GetHashableBytes() goqrllib.UcharVector {
 	tmp := new(bytes.Buffer)
 	tmp.Write(tx.MasterAddr())
 	binary.Write(tmp, binary.BigEndian, tx.Fee())
@@ -49,6 +48,7 @@ func (tx *TokenTransaction) GetHashable() goqrllib.UcharVector {
 		tmp.Write(addrAmount.Address)
 		binary.Write(tmp, binary.BigEndian, addrAmount.Amount)
 	}
+	tx.hashExpiry(tmp)
 
 	tmptxhash := misc.UcharVector{}
 	tmptxhash.AddBytes(tmp.Bytes())
@@ -111,7 +111,7 @@ func (tx *TokenTransaction) validateCustom() bool {
 	}
 
 	if tx.Fee() < 0 {
-		tx.log.Warn("TokenTransaction [%s] Invalid Fee = %d", string(tx.Txhash()), tx.Fee())
+		tx.log.Warn("TokenTransaction [%s] Invalid Fee = %d", misc.Bin2HStr(tx.Txhash()), tx.Fee())
 		return false
 	}
 
@@ -143,14 +143,14 @@ func (tx *TokenTransaction) validateExtended(addrFromState *core.AddressState, a
 	}
 
 	if txBalance < tx.Fee() {
-		tx.log.Warn("TokenTxn State validation failed for %s because: Insufficient funds", string(tx.Txhash()))
+		tx.log.Warn("TokenTxn State validation failed for %s because: Insufficient funds", misc.Bin2HStr(tx.Txhash()))
 		tx.log.Warn("balance: %s, Fee: %s", txBalance, tx.Fee())
 		return false
 	}
 
 	if addrFromState.OTSKeyReuse(tx.OtsKey()) {
 		tx.log.Warn("TokenTxn State validation failed for %s because: OTS Public key re-use detected",
-			string(tx.Txhash()))
+			misc.Bin2HStr(tx.Txhash()))
 		return false
 	}
 
@@ -164,13 +164,13 @@ func (tx *TokenTransaction) ApplyStateChanges(addressesState map[string]core.Add
 	addrFromPKProcessed := false
 
 	for _, addrAmount := range tx.InitialBalances() {
-		if reflect.DeepEqual(addrAmount.Address, tx.Owner()) {
+		if bytes.Equal(addrAmount.Address, tx.Owner()) {
 			ownerProcessed = true
 		}
-		if reflect.DeepEqual(addrAmount.Address, tx.AddrFrom()) {
+		if bytes.Equal(addrAmount.Address, tx.AddrFrom()) {
 			addrFromProcessed = true
 		}
-		if reflect.DeepEqual(addrAmount.Address, addrFromPK) {
+		if bytes.Equal(addrAmount.Address, addrFromPK) {
 			addrFromPKProcessed = true
 		}
 		if addrState, ok := addressesState[string(addrAmount.Address)]; ok {
@@ -193,7 +193,7 @@ func (tx *TokenTransaction) ApplyStateChanges(addressesState map[string]core.Add
 	}
 
 	if addrState, ok := addressesState[string(addrFromPK)]; ok {
-		if reflect.DeepEqual(tx.AddrFrom(), addrFromPK) {
+		if bytes.Equal(tx.AddrFrom(), addrFromPK) {
 			if !addrFromPKProcessed {
 				addrState.AppendTransactionHash(tx.Txhash())
 			}
@@ -210,13 +210,13 @@ func (tx *TokenTransaction) RevertStateChanges(addressesState map[string]core.Ad
 	addrFromPKProcessed := false
 
 	for _, addrAmount := range tx.InitialBalances() {
-		if reflect.DeepEqual(addrAmount.Address, tx.Owner()) {
+		if bytes.Equal(addrAmount.Address, tx.Owner()) {
 			ownerProcessed = true
 		}
-		if reflect.DeepEqual(addrAmount.Address, tx.AddrFrom()) {
+		if bytes.Equal(addrAmount.Address, tx.AddrFrom()) {
 			addrFromProcessed = true
 		}
-		if reflect.DeepEqual(addrAmount.Address, addrFromPK) {
+		if bytes.Equal(addrAmount.Address, addrFromPK) {
 			addrFromPKProcessed = true
 		}
 		if addrState, ok := addressesState[string(addrAmount.Address)]; ok {
@@ -239,7 +239,7 @@ func (tx *TokenTransaction) RevertStateChanges(addressesState map[string]core.Ad
 	}
 
 	if addrState, ok := addressesState[string(addrFromPK)]; ok {
-		if reflect.DeepEqual(tx.AddrFrom(), addrFromPK) {
+		if bytes.Equal(tx.AddrFrom(), addrFromPK) {
 			if !addrFromPKProcessed {
 				addrState.RemoveTransactionHash(tx.Txhash())
 			}
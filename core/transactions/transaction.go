@@ -1,17 +1,18 @@
 package transactions
 
 import (
+	"bytes"
 	"encoding/binary"
-	"reflect"
-	"github.com/golang/protobuf/proto"
-	"github.com/theQRL/qrllib/goqrllib"
+	"errors"
+	"github.com/cyyber/go-qrl/core"
+	"github.com/cyyber/go-qrl/core/pool"
+	"github.com/cyyber/go-qrl/crypto"
 	"github.com/cyyber/go-qrl/generated"
+	"github.com/cyyber/go-qrl/log"
 	"github.com/cyyber/go-qrl/misc"
-	"github.com/cyyber/go-qrl/crypto"
 	"github.com/golang/protobuf/jsonpb"
-	"github.com/cyyber/go-qrl/log"
-	"github.com/cyyber/go-qrl/core"
-	"github.com/cyyber/go-qrl/core/pool"
+	"github.com/golang/protobuf/proto"
+	"github.com/theQRL/qrllib/goqrllib"
 )
 
 type TransactionInterface interface {
@@ -26,6 +27,14 @@ type TransactionInterface interface {
 
 	Nonce() uint64
 
+	ExpiryBlockNumber() uint64
+
+	IsExpired(blockNumber uint64) bool
+
+	UnlockHeight() uint64
+
+	IsUnlockHeightValid(blockNumber uint64) bool
+
 	MasterAddr() []byte
 
 	AddrFrom() []byte
@@ -42,6 +51,8 @@ type TransactionInterface interface {
 
 	GetSlave() []byte
 
+	AddrFromPK() []byte
+
 	Txhash() []byte
 
 	UpdateTxhash(hashableBytes goqrllib.UcharVector)
@@ -99,6 +110,42 @@ func (tx *Transaction) Nonce() uint64 {
 	return tx.data.Nonce
 }
 
+func (tx *Transaction) ExpiryBlockNumber() uint64 {
+	return tx.data.ExpiryBlockNumber
+}
+
+// IsExpired returns true if the transaction cannot be included in a block
+// at blockNumber. An expiry is only allowed from the expiry fork, before it
+// nodes without the fork don't know the field.
+func (tx *Transaction) IsExpired(blockNumber uint64) bool {
+	if tx.ExpiryBlockNumber() == 0 {
+		return false
+	}
+	if !tx.config.Dev.Transaction.IsExpiryActive(blockNumber) {
+		return true
+	}
+	return blockNumber > tx.ExpiryBlockNumber()
+}
+
+// UnlockHeight returns the height before which a time-locked transfer can't
+// be mined, 0 for other transactions
+func (tx *Transaction) UnlockHeight() uint64 {
+	return tx.data.GetTransfer().GetUnlockHeight()
+}
+
+// IsUnlockHeightValid returns false if the transaction can't be included in
+// a block at blockNumber because of its unlock height, which is only
+// allowed from the time lock fork and must be reached by the block number
+func (tx *Transaction) IsUnlockHeightValid(blockNumber uint64) bool {
+	if tx.UnlockHeight() == 0 {
+		return true
+	}
+	if !tx.config.Dev.Transaction.IsTimeLockActive(blockNumber) {
+		return false
+	}
+	return blockNumber >= tx.UnlockHeight()
+}
+
 func (tx *Transaction) MasterAddr() []byte {
 	return tx.data.MasterAddr
 }
@@ -137,33 +184,74 @@ func (tx *Transaction) FromPBdata(pbdata *generated.Transaction) {
 	tx.data = pbdata
 }
 
+// SetConfig sets the config and the logger a transaction decoded with
+// ProtoToTransaction is validated with
+func (tx *Transaction) SetConfig(config *core.Config, log log.Logger) {
+	tx.config = *config
+	tx.log = log
+}
+
 func (tx *Transaction) GetSlave() []byte {
 	pk := tx.PK()
 	upk := misc.UcharVector{}
 	upk.AddBytes(pk)
 	upk.New(goqrllib.QRLHelperGetAddress(upk.GetData()))
 
-	if reflect.DeepEqual(upk.GetBytes(), tx.AddrFrom()) {
+	if bytes.Equal(upk.GetBytes(), tx.AddrFrom()) {
 		return upk.GetBytes()
 	}
 
 	return nil
 }
 
+// AddrFromPK returns the address derived from the signing XMSS public key.
+// Nonce and OTS keys are always tracked on this address, which differs from
+// AddrFrom when the transaction is signed by a slave.
+func (tx *Transaction) AddrFromPK() []byte {
+	return misc.UCharVectorToBytes(goqrllib.QRLHelperGetAddress(misc.BytesToUCharVector(tx.PK())))
+}
+
 func (tx *Transaction) Txhash() []byte {
 	return tx.data.TransactionHash
 }
 
+// UpdateTxhash sets the transaction hash to
+// SHA2-256(hashable bytes || signature || public key)
 func (tx *Transaction) UpdateTxhash(hashableBytes goqrllib.UcharVector) {
-	tmp := misc.UcharVector{}
-	tmp.New(hashableBytes)
-	tmp.AddBytes(tx.Signature())
-	tmp.AddBytes(tx.PK())
-	tx.data.TransactionHash = tmp.GetBytes()
+	tx.data.TransactionHash = ComputeTxhash(misc.UCharVectorToBytes(hashableBytes), tx.Signature(), tx.PK())
+}
+
+// hashExpiry appends the expiry block number to the hashable bytes, only
+// when set so the hashes of transactions without it are unchanged
+func (tx *Transaction) hashExpiry(tmp *bytes.Buffer) {
+	if tx.ExpiryBlockNumber() != 0 {
+		binary.Write(tmp, binary.BigEndian, tx.ExpiryBlockNumber())
+	}
 }
 
+// GetHashableBytes is implemented by each transaction type
 func (tx *Transaction) GetHashableBytes() goqrllib.UcharVector {
-	//TODO When State is ready
+	panic("GetHashableBytes not implemented for transaction type")
+}
+
+func ComputeTxhash(hashableBytes []byte, signature []byte, pk []byte) []byte {
+	var tmp []byte
+	tmp = append(tmp, hashableBytes...)
+	tmp = append(tmp, signature...)
+	tmp = append(tmp, pk...)
+	return misc.Sha256(tmp)
+}
+
+var ErrTxhashMismatch = errors.New("transaction hash doesn't match its content")
+
+// ExpectedTxhash recomputes the hash of tx from its content. Coinbase
+// transactions are not signed, their hash is their hashable bytes.
+func ExpectedTxhash(tx TransactionInterface) []byte {
+	hashableBytes := misc.UCharVectorToBytes(tx.GetHashableBytes())
+	if tx.PBData().GetCoinbase() != nil {
+		return hashableBytes
+	}
+	return ComputeTxhash(hashableBytes, tx.Signature(), tx.PK())
 }
 
 func (tx *Transaction) Sign(xmss crypto.XMSS, message goqrllib.UcharVector) {
@@ -257,7 +345,7 @@ func (tx *Transaction) JSON() (string, error) {
 
 func ProtoToTransaction(protoTX *generated.Transaction) TransactionInterface {
 	var tx TransactionInterface
-	switch _ := protoTX.TransactionType.(type) {
+	switch protoTX.TransactionType.(type) {
 	case *generated.Transaction_Transfer_:
 		tx = &TransferTransaction{}
 	case *generated.Transaction_Coinbase:
@@ -268,10 +356,14 @@ func ProtoToTransaction(protoTX *generated.Transaction) TransactionInterface {
 		tx = &TransferTokenTransaction{}
 	case *generated.Transaction_Message_:
 		tx = &MessageTransaction{}
+	case *generated.Transaction_LatticePK:
+		tx = &LatticePublicKey{}
+	case *generated.Transaction_Slave_:
+		tx = &SlaveTransaction{}
 	}
 
 	if tx != nil {
-		tx.data = protoTX
+		tx.FromPBdata(protoTX)
 	}
 
 	return tx
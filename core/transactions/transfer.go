@@ -1,12 +1,12 @@
 package transactions
 
 import (
-	"github.com/theQRL/qrllib/goqrllib"
-	"github.com/cyyber/go-qrl/misc"
-	"encoding/binary"
 	"bytes"
+	"encoding/binary"
 	"github.com/cyyber/go-qrl/core"
-	"reflect"
+	"github.com/cyyber/go-qrl/generated"
+	"github.com/cyyber/go-qrl/misc"
+	"github.com/theQRL/qrllib/goqrllib"
 )
 
 type TransferTransaction struct {
@@ -21,10 +21,16 @@ func (tx *TransferTransaction) Amounts() []uint64 {
 	return tx.data.GetTransfer().Amounts
 }
 
+// SetUnlockHeight time-locks the transfer, it can't be mined before
+// unlockHeight. It must be set before signing.
+func (tx *TransferTransaction) SetUnlockHeight(unlockHeight uint64) {
+	tx.data.GetTransfer().UnlockHeight = unlockHeight
+}
+
 func (tx *TransferTransaction) TotalAmounts() uint64 {
 	totalAmount := uint64(0)
-	for amount := range tx.Amounts() {
-		totalAmount += uint64(amount)
+	for _, amount := range tx.Amounts() {
+		totalAmount += amount
 	}
 	return totalAmount
 }
@@ -37,6 +43,13 @@ func (tx *TransferTransaction) GetHashableBytes() goqrllib.UcharVector {
 		tmp.Write(tx.AddrsTo()[i])
 		binary.Write(tmp, binary.BigEndian, tx.Amounts()[i])
 	}
+	// Only hashed when set, so hashes of transfers without it are unchanged.
+	// It's also hashed before an expiry, an expiry alone then never hashes
+	// like an unlock height alone.
+	if tx.UnlockHeight() != 0 || tx.ExpiryBlockNumber() != 0 {
+		binary.Write(tmp, binary.BigEndian, tx.UnlockHeight())
+	}
+	tx.hashExpiry(tmp)
 
 	tmptxhash := misc.UcharVector{}
 	tmptxhash.AddBytes(tmp.Bytes())
@@ -55,7 +68,7 @@ func (tx *TransferTransaction) validateCustom() bool {
 	}
 
 	if tx.Fee() < 0 {
-		tx.log.Warn("TransferTransaction [%s] Invalid Fee = %d", goqrllib.Bin2hstr(tx.Txhash()), tx.Fee)
+		tx.log.Warn("TransferTransaction [%s] Invalid Fee = %d", misc.Bin2HStr(tx.Txhash()), tx.Fee)
 		return false
 	}
 
@@ -99,14 +112,14 @@ func (tx *TransferTransaction) validateExtended(
 	totalAmount := tx.TotalAmounts()
 
 	if balance < totalAmount + tx.Fee() {
-		tx.log.Warn("State validation failed for %s because: Insufficient funds", goqrllib.Bin2hstr(tx.Txhash()))
+		tx.log.Warn("State validation failed for %s because: Insufficient funds", misc.Bin2HStr(tx.Txhash()))
 		tx.log.Warn("balance: %s, fee: %s, amount: %s", balance, tx.Fee(), totalAmount)
 		return false
 	}
 
 	if addrFromPkState.OTSKeyReuse(tx.OtsKey()) {
 		tx.log.Warn("State validation failed for %s because: OTS Public key re-use detected",
-			goqrllib.Bin2hstr(tx.Txhash()))
+			misc.Bin2HStr(tx.Txhash()))
 		return false
 	}
 
@@ -130,7 +143,7 @@ func (tx *TransferTransaction) ApplyStateChanges(addressesState map[string]core.
 
 		if addrState, ok := addressesState[string(addrTo)]; ok {
 			addrState.AddBalance(amount)
-			if !reflect.DeepEqual(addrTo, tx.AddrFrom()) {
+			if !bytes.Equal(addrTo, tx.AddrFrom()) {
 				addrState.AppendTransactionHash(tx.Txhash())
 			}
 		}
@@ -155,7 +168,7 @@ func (tx *TransferTransaction) RevertStateChanges(addressesState map[string]core
 
 		if addrState, ok := addressesState[string(addrTo)]; ok {
 			addrState.AddBalance(amount * -1)
-			if !reflect.DeepEqual(addrTo, tx.AddrFrom()) {
+			if !bytes.Equal(addrTo, tx.AddrFrom()) {
 				addrState.RemoveTransactionHash(tx.Txhash())
 			}
 		}
@@ -173,6 +186,9 @@ func (tx *TransferTransaction) SetAffectedAddress(addressesState map[string]core
 
 func Create(addrsTo [][]byte, amounts []uint64, fee uint64, xmssPK []byte, masterAddr []byte) *TransferTransaction {
 	tx := &TransferTransaction{}
+	tx.data = &generated.Transaction{
+		TransactionType: &generated.Transaction_Transfer_{Transfer: &generated.Transaction_Transfer{}},
+	}
 
 	if masterAddr != nil {
 		tx.data.MasterAddr = masterAddr
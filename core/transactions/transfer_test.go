@@ -0,0 +1,13 @@
+package transactions
+
+import (
+	"testing"
+)
+
+func TestTotalAmounts(t *testing.T) {
+	addrsTo := [][]byte{make([]byte, 39), make([]byte, 39), make([]byte, 39)}
+	tx := Create(addrsTo, []uint64{5, 7, 11}, 1, make([]byte, 67), nil)
+	if total := tx.TotalAmounts(); total != 23 {
+		t.Fatalf("TotalAmounts() = %d, expected 23", total)
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"encoding/binary"
 	"github.com/cyyber/go-qrl/misc"
 	"github.com/cyyber/go-qrl/core"
-	"reflect"
 )
 
 type TransferTokenTransaction struct {
@@ -45,6 +44,7 @@ func (tx *TransferTokenTransaction) GetHashableBytes() goqrllib.UcharVector {
 		tmp.Write(tx.AddrsTo()[i])
 		binary.Write(tmp, binary.BigEndian, tx.Amounts()[i])
 	}
+	tx.hashExpiry(tmp)
 
 	tmptxhash := misc.UcharVector{}
 	tmptxhash.AddBytes(tmp.Bytes())
@@ -61,7 +61,7 @@ func (tx *TransferTokenTransaction) validateCustom() bool {
 		}
 
 		if tx.Fee() < 0 {
-			tx.log.Warn("[TransferTokenTransaction] Invalid Fee = %d", string(tx.Txhash()), tx.Fee())
+			tx.log.Warn("[TransferTokenTransaction] Invalid Fee = %d", misc.Bin2HStr(tx.Txhash()), tx.Fee())
 			return false
 		}
 
@@ -97,7 +97,7 @@ func (tx *TransferTokenTransaction) ValidateExtended(addrFromState *core.Address
 	totalAmount := tx.TotalAmount()
 
 	if balance < tx.Fee() {
-		tx.log.Warn("[TransferTokenTransaction] State validation failed for %s because: Insufficient funds", goqrllib.Bin2hstr(tx.Txhash()))
+		tx.log.Warn("[TransferTokenTransaction] State validation failed for %s because: Insufficient funds", misc.Bin2HStr(tx.Txhash()))
 		tx.log.Warn("balance: %s, fee: %s", balance, tx.Fee())
 		return false
 	}
@@ -115,7 +115,7 @@ func (tx *TransferTokenTransaction) ValidateExtended(addrFromState *core.Address
 	}
 
 	if addrFromPkState.OTSKeyReuse(tx.OtsKey()) {
-		tx.log.Warn("[TransferTokenTransaction] State validation failed for %s because: OTS Public key re-use detected", string(tx.Txhash()))
+		tx.log.Warn("[TransferTokenTransaction] State validation failed for %s because: OTS Public key re-use detected", misc.Bin2HStr(tx.Txhash()))
 		return false
 	}
 
@@ -138,7 +138,7 @@ func (tx *TransferTokenTransaction) ApplyStateChanges(addressesState map[string]
 
 		if addrState, ok := addressesState[string(addrTo)]; ok {
 			addrState.AddBalance(amount)
-			if !reflect.DeepEqual(addrTo, tx.AddrFrom()) {
+			if !bytes.Equal(addrTo, tx.AddrFrom()) {
 				addrState.AppendTransactionHash(tx.Txhash())
 			}
 		}
@@ -161,7 +161,7 @@ func (tx *TransferTokenTransaction) RevertStateChanges(addressesState map[string
 
 		if addrState, ok := addressesState[string(addrTo)]; ok {
 			addrState.AddBalance(amount * -1)
-			if !reflect.DeepEqual(addrTo, tx.AddrFrom()) {
+			if !bytes.Equal(addrTo, tx.AddrFrom()) {
 				addrState.RemoveTransactionHash(tx.Txhash())
 			}
 		}
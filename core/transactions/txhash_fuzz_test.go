@@ -0,0 +1,47 @@
+package transactions
+
+import (
+	"bytes"
+	"github.com/cyyber/go-qrl/generated"
+	"github.com/golang/protobuf/proto"
+	"testing"
+)
+
+// FuzzTxhash checks that the transaction hash survives a protobuf round
+// trip, so the hash never depends on anything else than the encoded
+// fields. The corpus is seeded with the mainnet genesis transactions.
+func FuzzTxhash(f *testing.F) {
+	for _, pbdata := range mainnetTransactions(f) {
+		data, err := proto.Marshal(pbdata)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		pbdata := &generated.Transaction{}
+		if err := proto.Unmarshal(data, pbdata); err != nil {
+			return
+		}
+
+		tx := ProtoToTransaction(pbdata)
+		if tx == nil {
+			return
+		}
+		txhash := ExpectedTxhash(tx)
+
+		encoded, err := proto.Marshal(pbdata)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded := &generated.Transaction{}
+		if err := proto.Unmarshal(encoded, decoded); err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(txhash, ExpectedTxhash(ProtoToTransaction(decoded))) {
+			t.Fatal("transaction hash changed after protobuf round trip")
+		}
+	})
+}
@@ -0,0 +1,86 @@
+package transactions
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/cyyber/go-qrl/generated"
+	"github.com/cyyber/go-qrl/misc"
+	"github.com/golang/protobuf/jsonpb"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"testing"
+)
+
+// mainnetTransactions returns the transactions of the mainnet genesis
+// block. Their hashes were computed by the Python node, so they pin the
+// hashable bytes of the coinbase and transfer transactions byte for byte.
+func mainnetTransactions(tb testing.TB) []*generated.Transaction {
+	yamlData, err := ioutil.ReadFile("../../genesis/genesis.yml")
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	var m interface{}
+	if err := yaml.Unmarshal(yamlData, &m); err != nil {
+		tb.Fatal(err)
+	}
+	jsonData, err := json.Marshal(yamlToJSON(m))
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	block := &generated.Block{}
+	if err := jsonpb.UnmarshalString(string(jsonData), block); err != nil {
+		tb.Fatal(err)
+	}
+	if len(block.Transactions) == 0 {
+		tb.Fatal("no transaction in the genesis block")
+	}
+	return block.Transactions
+}
+
+// yamlToJSON converts the map[interface{}]interface{} decoded by yaml.v2
+// to maps encoding/json can marshal
+func yamlToJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{})
+		for key, value := range v {
+			m[fmt.Sprint(key)] = yamlToJSON(value)
+		}
+		return m
+	case []interface{}:
+		for i, value := range v {
+			v[i] = yamlToJSON(value)
+		}
+	}
+	return v
+}
+
+// TODO: the genesis block only has coinbase and transfer transactions. The
+// message, token, transfer token, slave and lattice public key types still
+// need vectors taken from mainnet blocks, with the hashes of the Python
+// node, which this tree has no copy of.
+func TestExpectedTxhashMainnet(t *testing.T) {
+	for i, pbdata := range mainnetTransactions(t) {
+		tx := ProtoToTransaction(pbdata)
+		if tx == nil {
+			t.Fatalf("transaction %d: unknown transaction type", i)
+		}
+		if txhash := ExpectedTxhash(tx); !bytes.Equal(txhash, pbdata.TransactionHash) {
+			t.Errorf("transaction %d: hash %s, expected %s", i, misc.Bin2HStr(txhash), misc.Bin2HStr(pbdata.TransactionHash))
+		}
+	}
+}
+
+// The expiry block number added by this node is only hashed when set,
+// which the mainnet vectors above check for the unset case
+func TestExpectedTxhashExpiry(t *testing.T) {
+	for i, pbdata := range mainnetTransactions(t) {
+		pbdata.ExpiryBlockNumber = 1000
+		if bytes.Equal(ExpectedTxhash(ProtoToTransaction(pbdata)), pbdata.TransactionHash) {
+			t.Errorf("transaction %d: expiry block number is not hashed", i)
+		}
+	}
+}
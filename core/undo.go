@@ -0,0 +1,324 @@
+package core
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"github.com/syndtr/goleveldb/leveldb"
+	"sort"
+)
+
+// An undo record is written in the same batch as every block applied on
+// top of the mainchain, keyed by block number. It holds the address states
+// changed by the block as they were before it, and a digest of the same
+// address states after it. Records older than ReorgLimit are pruned.
+//
+// At startup the digest is compared with the stored address states. A
+// mismatch, or a missing tip block, mapping or metadata, means the database
+// was damaged, typically by a crash on a filesystem ignoring fsync, and the
+// chain is rolled back by restoring the pre-images until the tip verifies.
+//
+// Layout: uint64 block number | sized headerhash | sized post digest |
+// uint32 count | (sized address | sized pre-image)* | sized state digest,
+// an empty pre-image meaning the address didn't exist. The OTS bitfield
+// pages of the addresses are recorded the same way, under their page key.
+// The post digest covers the stored records, the state digest is the
+// canonical one compared with the peers, see stateprobe.go. Records written
+// before the state digest end after the pre-images.
+
+var (
+	ErrUndoRecordMissing = errors.New("undo record missing")
+	ErrInvalidUndoRecord = errors.New("invalid undo record")
+	ErrChainCorrupted    = errors.New("chain database is inconsistent at its tip")
+	ErrChainUnrepairable = errors.New("chain database is inconsistent and can't be rolled back further")
+)
+
+type UndoRecord struct {
+	BlockNumber uint64
+	HeaderHash  []byte
+	PostDigest  []byte
+	PreStates   map[string][]byte
+	StateDigest []byte
+}
+
+func undoKey(blockNumber uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, blockNumber)
+	return append([]byte("undo_"), key...)
+}
+
+func (r *UndoRecord) encode() []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, r.BlockNumber)
+	writeSized(&buf, r.HeaderHash)
+	writeSized(&buf, r.PostDigest)
+	binary.Write(&buf, binary.BigEndian, uint32(len(r.PreStates)))
+	for _, address := range sortedKeys(addressKeys(r.PreStates)) {
+		writeSized(&buf, []byte(address))
+		writeSized(&buf, r.PreStates[address])
+	}
+	writeSized(&buf, r.StateDigest)
+	return buf.Bytes()
+}
+
+func decodeUndoRecord(data []byte) (*UndoRecord, error) {
+	r := bytes.NewReader(data)
+	record := &UndoRecord{PreStates: make(map[string][]byte)}
+
+	var count uint32
+	if err := binary.Read(r, binary.BigEndian, &record.BlockNumber); err != nil {
+		return nil, ErrInvalidUndoRecord
+	}
+	var err error
+	if record.HeaderHash, err = readUndoField(r); err != nil {
+		return nil, err
+	}
+	if record.PostDigest, err = readUndoField(r); err != nil {
+		return nil, err
+	}
+	if err := binary.Read(r, binary.BigEndian, &count); err != nil {
+		return nil, ErrInvalidUndoRecord
+	}
+	for i := uint32(0); i < count; i++ {
+		address, err := readUndoField(r)
+		if err != nil {
+			return nil, err
+		}
+		preState, err := readUndoField(r)
+		if err != nil {
+			return nil, err
+		}
+		record.PreStates[string(address)] = preState
+	}
+	if r.Len() != 0 {
+		if record.StateDigest, err = readUndoField(r); err != nil {
+			return nil, err
+		}
+	}
+	if r.Len() != 0 {
+		return nil, ErrInvalidUndoRecord
+	}
+	return record, nil
+}
+
+// readUndoField reads a sized field, address states being unbounded the
+// size is only checked against the record length
+func readUndoField(r *bytes.Reader) ([]byte, error) {
+	var size uint32
+	if err := binary.Read(r, binary.BigEndian, &size); err != nil || uint64(size) > uint64(r.Len()) {
+		return nil, ErrInvalidUndoRecord
+	}
+	field := make([]byte, size)
+	r.Read(field)
+	return field, nil
+}
+
+func addressKeys(m map[string][]byte) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// addressesDigest hashes the serialized address states in address order
+func addressesDigest(addressesState map[string]*AddressState) []byte {
+	addresses := make([]string, 0, len(addressesState))
+	for address := range addressesState {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+
+	h := sha256.New()
+	for _, address := range addresses {
+		value, _ := addressesState[address].Serialize()
+		writeSized(h, []byte(address))
+		writeSized(h, value)
+	}
+	return h.Sum(nil)
+}
+
+func (s *State) PutUndoRecord(record *UndoRecord, batch *leveldb.Batch) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.db.Put(undoKey(record.BlockNumber), record.encode(), batch)
+}
+
+func (s *State) GetUndoRecord(blockNumber uint64) (*UndoRecord, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	value, err := s.db.Get(undoKey(blockNumber))
+	if err != nil {
+		return nil, ErrUndoRecordMissing
+	}
+	return decodeUndoRecord(value)
+}
+
+func (s *State) RemoveUndoRecord(blockNumber uint64, batch *leveldb.Batch) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if batch != nil {
+		batch.Delete(undoKey(blockNumber))
+		return
+	}
+	s.db.Delete(undoKey(blockNumber))
+}
+
+// PutUndoHeight records the first block number having an undo record, so
+// databases written before undo records existed aren't reported corrupted
+func (s *State) PutUndoHeight(height uint64) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	value := make([]byte, 8)
+	binary.BigEndian.PutUint64(value, height)
+	return s.db.Put([]byte("undo_height"), value, nil)
+}
+
+func (s *State) GetUndoHeight() (uint64, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	value, err := s.db.Get([]byte("undo_height"))
+	if err != nil || len(value) != 8 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint64(value), true
+}
+
+// restoreUndoRecord writes the pre-images of record into batch
+func (s *State) restoreUndoRecord(record *UndoRecord, batch *leveldb.Batch) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for address, preState := range record.PreStates {
+		if len(preState) == 0 {
+			batch.Delete([]byte(address))
+			continue
+		}
+		batch.Put([]byte(address), preState)
+	}
+}
+
+// newUndoRecord captures the pre-images of addressesState, it must be
+// called before the block is applied to them. It returns the record and the
+// number of records read from the database.
+func (c *Chain) newUndoRecord(block *Block, addressesState map[string]*AddressState) (*UndoRecord, uint64) {
+	record := &UndoRecord{
+		BlockNumber: block.BlockNumber(),
+		HeaderHash:  block.HeaderHash(),
+		PreStates:   make(map[string][]byte),
+	}
+	var reads uint64
+	for address := range addressesState {
+		preState, err := c.state.getAddressStateRecord([]byte(address))
+		if err != nil {
+			preState = nil
+		}
+		record.PreStates[address] = preState
+		for key, page := range c.state.getOTSPageRecords([]byte(address)) {
+			record.PreStates[key] = page
+		}
+		reads += 1 + uint64(otsPageCount(c.state.config))
+	}
+	return record, reads
+}
+
+// pruneUndoRecord removes the undo record which just fell beyond the reorg
+// limit once blockNumber is applied
+func (c *Chain) pruneUndoRecord(blockNumber uint64, batch *leveldb.Batch) {
+	if blockNumber > c.config.Dev.ReorgLimit {
+		c.state.RemoveUndoRecord(blockNumber-c.config.Dev.ReorgLimit-1, batch)
+	}
+}
+
+// verifyTip checks that the mainchain block at height is readable and
+// indexed, and that the address states match its undo record
+func (c *Chain) verifyTip(height uint64) error {
+	mapping, err := c.state.GetBlockNumberMapping(height)
+	if err != nil || mapping == nil {
+		return ErrChainCorrupted
+	}
+	block, err := c.state.GetBlock(mapping.Headerhash)
+	if err != nil || block.BlockNumber() != height || !bytes.Equal(block.HeaderHash(), mapping.Headerhash) {
+		return ErrChainCorrupted
+	}
+	if _, err := c.state.GetBlockMetadata(mapping.Headerhash); err != nil {
+		return ErrChainCorrupted
+	}
+
+	undoHeight, ok := c.state.GetUndoHeight()
+	if height == 0 || !ok || height < undoHeight {
+		return nil
+	}
+	record, err := c.state.GetUndoRecord(height)
+	if err != nil || !bytes.Equal(record.HeaderHash, mapping.Headerhash) {
+		return ErrChainCorrupted
+	}
+
+	addressesState := make(map[string]*AddressState)
+	for address := range record.PreStates {
+		if isOTSPageKey([]byte(address)) {
+			continue
+		}
+		addrState, err := c.state.GetAddressState([]byte(address))
+		if err != nil {
+			return ErrChainCorrupted
+		}
+		addressesState[address] = addrState
+	}
+	if !bytes.Equal(addressesDigest(addressesState), record.PostDigest) {
+		return ErrChainCorrupted
+	}
+	return nil
+}
+
+// undoTip rolls back the mainchain block at height with its undo record
+func (c *Chain) undoTip(height uint64) error {
+	record, err := c.state.GetUndoRecord(height)
+	if err != nil {
+		c.log.Error("Can't roll back block", "number", height, "error", err)
+		return ErrChainUnrepairable
+	}
+
+	batch := c.state.GetBatch()
+	c.state.restoreUndoRecord(record, batch)
+	if block, err := c.state.GetBlock(record.HeaderHash); err == nil {
+		c.state.RollbackTxMetadata(block, batch)
+	} else {
+		c.log.Warn("Rolled back block unreadable, keeping its transaction metadata", "number", height)
+	}
+	c.state.RemoveUndoRecord(height, batch)
+	c.state.PutChainHeight(height-1, batch)
+	c.state.RemoveBlockNumberMapping(height, batch)
+	if err := c.state.WriteChainBatch(batch); err != nil {
+		c.log.Error("Can't roll back block", "number", height, "error", err)
+		return ErrChainUnrepairable
+	}
+	return nil
+}
+
+// repair rolls the chain back from height using the undo records until the
+// tip verifies, and returns the new height. Sync resumes from there.
+func (c *Chain) repair(height uint64) (uint64, error) {
+	undoHeight, _ := c.state.GetUndoHeight()
+	for rolledBack := uint64(0); ; rolledBack++ {
+		if height == 0 || height < undoHeight || rolledBack >= c.config.Dev.ReorgLimit {
+			return 0, ErrChainUnrepairable
+		}
+
+		if err := c.undoTip(height); err != nil {
+			return 0, err
+		}
+		height--
+
+		if c.verifyTip(height) == nil {
+			c.log.Warn("Repaired chain database", "rolledback", rolledBack+1, "height", height)
+			return height, nil
+		}
+	}
+}
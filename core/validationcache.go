@@ -0,0 +1,129 @@
+package core
+
+import (
+	"bytes"
+	"container/list"
+	"sync"
+)
+
+// validationCache remembers the context-free checks passed by recent
+// blocks, keyed by headerhash, so a block validated again, such as a block
+// relayed cut-through then processed, or a block re-applied when fork
+// recovery switches back to its branch, only repeats the checks depending
+// on its parent and the state. The headerhash commits to the mining blob
+// and the merkle root, and the merkle root to the txhashes, which
+// Block.Validate checks against the content of the transactions on every
+// call, so a cached result can't be reused for different content.
+type validatedBlock struct {
+	// Target the PoW hash was verified to meet, targets only depend on the
+	// parent so it's checked again when it differs
+	powTarget []byte
+	// The merkle root matches the transactions
+	merkleRoot bool
+	// The signatures of the transactions are valid
+	signatures bool
+	// Checks which were run in full as they weren't cached yet
+	checksMissed uint64
+}
+
+// Checks a validatedBlock caches, all run in full when the cache is disabled
+const validatedChecks = 3
+
+type validationCache struct {
+	lock sync.Mutex
+
+	size    int
+	entries *list.List
+	byHash  map[string]*list.Element
+
+	hits   uint64
+	misses uint64
+}
+
+type validationCacheEntry struct {
+	headerHash string
+	validated  validatedBlock
+}
+
+func newValidationCache(size int) *validationCache {
+	return &validationCache{
+		size:    size,
+		entries: list.New(),
+		byHash:  make(map[string]*list.Element),
+	}
+}
+
+// get returns a copy of the checks passed by the block
+func (v *validationCache) get(headerHash []byte) validatedBlock {
+	if v.size == 0 {
+		return validatedBlock{}
+	}
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	e, ok := v.byHash[string(headerHash)]
+	if !ok {
+		v.misses++
+		return validatedBlock{}
+	}
+	v.hits++
+	v.entries.MoveToFront(e)
+	return e.Value.(*validationCacheEntry).validated
+}
+
+// update records the checks passed by the block through fn
+func (v *validationCache) update(headerHash []byte, fn func(validated *validatedBlock)) {
+	if v.size == 0 {
+		return
+	}
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	e, ok := v.byHash[string(headerHash)]
+	if !ok {
+		e = v.entries.PushFront(&validationCacheEntry{headerHash: string(headerHash)})
+		v.byHash[string(headerHash)] = e
+		for v.entries.Len() > v.size {
+			oldest := v.entries.Back()
+			delete(v.byHash, v.entries.Remove(oldest).(*validationCacheEntry).headerHash)
+		}
+	}
+	v.entries.MoveToFront(e)
+	fn(&e.Value.(*validationCacheEntry).validated)
+}
+
+func (v *validationCache) powVerified(headerHash []byte, target []byte) bool {
+	powTarget := v.get(headerHash).powTarget
+	return powTarget != nil && bytes.Equal(powTarget, target)
+}
+
+func (v *validationCache) setPowVerified(headerHash []byte, target []byte) {
+	v.update(headerHash, func(validated *validatedBlock) {
+		validated.powTarget = append([]byte{}, target...)
+		validated.checksMissed++
+	})
+}
+
+// checksMissed returns the checks of the block which were run in full,
+// without counting as a cache hit or miss
+func (v *validationCache) checksMissed(headerHash []byte) uint64 {
+	if v.size == 0 {
+		return validatedChecks
+	}
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	e, ok := v.byHash[string(headerHash)]
+	if !ok {
+		return 0
+	}
+	return e.Value.(*validationCacheEntry).validated.checksMissed
+}
+
+// ValidationCacheStats returns the hits and misses of the validation cache
+func (c *Chain) ValidationCacheStats() (hits uint64, misses uint64) {
+	c.validated.lock.Lock()
+	defer c.validated.lock.Unlock()
+
+	return c.validated.hits, c.validated.misses
+}
@@ -0,0 +1,7 @@
+package core
+
+// Version is the version of the build, reported to peers. Release builds
+// set it with the linker:
+//
+//	go build -ldflags "-X github.com/cyyber/go-qrl/core.Version=v1.1.0"
+var Version = "dev"
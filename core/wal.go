@@ -0,0 +1,363 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"github.com/cyyber/go-qrl/misc"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+// The chain WAL records every mutation of the mainchain before it reaches
+// the database, and marks it committed once the database writes succeeded.
+// A block added or removed by fork recovery is a mutation of its own.
+// A record still pending at startup means the node stopped in the middle of
+// a mutation. If the database holds its target as a consistent tip the
+// mutation completed. Otherwise the partial writes are rolled back with the
+// undo records down to the tip before the mutation, and the block is
+// replayed if it was stored. The node refuses to start if neither is
+// possible, instead of running on a diverged chain index and state.
+//
+// Record layout: uint32 size | uint32 crc32 | payload, the payload being
+// op | uint64 seq | uint64 block number | sized headerhash | sized prev tip.
+// A torn record at the end of the file is ignored.
+
+type WALOp byte
+
+const (
+	WALAddBlock WALOp = iota + 1
+	WALCommit
+	WALRemoveBlock
+)
+
+var ErrWALInconsistent = errors.New("chain state doesn't match the pending WAL record")
+
+type WALRecord struct {
+	Seq         uint64
+	Op          WALOp
+	BlockNumber uint64
+	HeaderHash  []byte
+	PrevTip     []byte
+}
+
+// rollbackTarget is the tip an inconsistent chain is rolled back to. A
+// removal is completed, any other mutation is undone.
+func (r *WALRecord) rollbackTarget() []byte {
+	if r.Op == WALRemoveBlock {
+		return r.HeaderHash
+	}
+	return r.PrevTip
+}
+
+func (r *WALRecord) encode() []byte {
+	var payload bytes.Buffer
+	payload.WriteByte(byte(r.Op))
+	binary.Write(&payload, binary.BigEndian, r.Seq)
+	binary.Write(&payload, binary.BigEndian, r.BlockNumber)
+	payload.WriteByte(byte(len(r.HeaderHash)))
+	payload.Write(r.HeaderHash)
+	payload.WriteByte(byte(len(r.PrevTip)))
+	payload.Write(r.PrevTip)
+
+	out := make([]byte, 8, 8+payload.Len())
+	binary.BigEndian.PutUint32(out[0:4], uint32(payload.Len()))
+	binary.BigEndian.PutUint32(out[4:8], crc32.ChecksumIEEE(payload.Bytes()))
+	return append(out, payload.Bytes()...)
+}
+
+func decodeWALRecord(payload []byte) (*WALRecord, bool) {
+	if len(payload) < 18 {
+		return nil, false
+	}
+	r := &WALRecord{
+		Op:          WALOp(payload[0]),
+		Seq:         binary.BigEndian.Uint64(payload[1:9]),
+		BlockNumber: binary.BigEndian.Uint64(payload[9:17]),
+	}
+	rest := payload[17:]
+
+	size := int(rest[0])
+	if len(rest) < 1+size+1 {
+		return nil, false
+	}
+	r.HeaderHash = rest[1 : 1+size]
+	rest = rest[1+size:]
+
+	size = int(rest[0])
+	if len(rest) != 1+size {
+		return nil, false
+	}
+	r.PrevTip = rest[1:]
+
+	return r, true
+}
+
+func readWALRecords(data []byte) []*WALRecord {
+	var records []*WALRecord
+	for len(data) >= 8 {
+		size := binary.BigEndian.Uint32(data[0:4])
+		checksum := binary.BigEndian.Uint32(data[4:8])
+		if uint64(len(data)-8) < uint64(size) {
+			break
+		}
+		payload := data[8 : 8+size]
+		if crc32.ChecksumIEEE(payload) != checksum {
+			break
+		}
+		record, ok := decodeWALRecord(payload)
+		if !ok {
+			break
+		}
+		records = append(records, record)
+		data = data[8+size:]
+	}
+	return records
+}
+
+type WAL struct {
+	lock sync.Mutex
+
+	file *os.File
+	seq  uint64
+
+	pending int
+}
+
+// OpenWAL opens the WAL and returns the records which were never committed
+func OpenWAL(filename string) (*WAL, []*WALRecord, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, err
+	}
+
+	w := &WAL{}
+	open := make(map[uint64]*WALRecord)
+	var order []uint64
+	for _, record := range readWALRecords(data) {
+		if record.Seq > w.seq {
+			w.seq = record.Seq
+		}
+		if record.Op == WALCommit {
+			delete(open, record.Seq)
+			continue
+		}
+		open[record.Seq] = record
+		order = append(order, record.Seq)
+	}
+
+	var pending []*WALRecord
+	for _, seq := range order {
+		if record, ok := open[seq]; ok {
+			pending = append(pending, record)
+		}
+	}
+
+	// Pending records stay in the file until the caller has dealt with
+	// them and calls Reset
+	w.file, err = os.OpenFile(filename, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := w.file.Seek(0, io.SeekEnd); err != nil {
+		return nil, nil, err
+	}
+
+	return w, pending, nil
+}
+
+func (w *WAL) append(record *WALRecord) error {
+	if _, err := w.file.Write(record.encode()); err != nil {
+		return err
+	}
+	return w.file.Sync()
+}
+
+// Begin records an intended mutation, it is durable once Begin returns
+func (w *WAL) Begin(op WALOp, blockNumber uint64, headerHash []byte, prevTip []byte) (uint64, error) {
+	if w == nil {
+		return 0, nil
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.seq++
+	w.pending++
+	return w.seq, w.append(&WALRecord{
+		Seq:         w.seq,
+		Op:          op,
+		BlockNumber: blockNumber,
+		HeaderHash:  headerHash,
+		PrevTip:     prevTip,
+	})
+}
+
+// Commit marks the mutation seq as written to the database. Once nothing is
+// pending the file is truncated, so it never grows beyond a few records.
+func (w *WAL) Commit(seq uint64) error {
+	if w == nil {
+		return nil
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.pending--
+	if w.pending > 0 {
+		return w.append(&WALRecord{Seq: seq, Op: WALCommit})
+	}
+	return w.reset()
+}
+
+func (w *WAL) reset() error {
+	if err := w.file.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := w.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	return w.file.Sync()
+}
+
+// Reset drops the records left by the previous run, once recovered
+func (w *WAL) Reset() error {
+	if w == nil {
+		return nil
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	return w.reset()
+}
+
+func (w *WAL) Close() error {
+	if w == nil {
+		return nil
+	}
+	return w.file.Close()
+}
+
+// recoverWAL completes the mutations left pending by the previous run. When
+// an interrupted branch switch was resumed from its ForkState, the tip is
+// the one chosen by fork recovery and only the index is checked.
+func (c *Chain) recoverWAL(pending []*WALRecord, resumedFork bool) error {
+	if len(pending) == 0 {
+		return nil
+	}
+
+	for _, record := range pending {
+		if resumedFork {
+			break
+		}
+		if err := c.recoverWALRecord(record); err != nil {
+			c.log.Error("Can't recover pending WAL record",
+				"block", record.BlockNumber,
+				"headerhash", misc.Bin2HStr(record.HeaderHash),
+				"tip", misc.Bin2HStr(c.lastBlock.HeaderHash()),
+				"error", err)
+			return ErrWALInconsistent
+		}
+	}
+
+	tip := c.lastBlock.HeaderHash()
+	mapping, err := c.state.GetBlockNumberMapping(c.lastBlock.BlockNumber())
+	if err != nil || !bytes.Equal(mapping.Headerhash, tip) {
+		return ErrWALInconsistent
+	}
+	if _, err := c.state.GetBlockMetadata(tip); err != nil {
+		return ErrWALInconsistent
+	}
+
+	c.log.Info("Recovered from interrupted chain update", "pending", len(pending), "tip", c.lastBlock.BlockNumber())
+	return nil
+}
+
+func (c *Chain) recoverWALRecord(record *WALRecord) error {
+	consistent := c.verifyTip(c.lastBlock.BlockNumber()) == nil
+	if consistent && bytes.Equal(c.lastBlock.HeaderHash(), record.HeaderHash) {
+		return nil
+	}
+	if consistent {
+		// Already repaired below the mutation, it is synced again
+		prevTip, err := c.state.GetBlock(record.PrevTip)
+		if err == nil && prevTip.BlockNumber() > c.lastBlock.BlockNumber() {
+			return nil
+		}
+	}
+	if !consistent || !bytes.Equal(c.lastBlock.HeaderHash(), record.rollbackTarget()) {
+		if err := c.rollbackWAL(record); err != nil {
+			return err
+		}
+	}
+	c.replayWAL(record)
+	return nil
+}
+
+// rollbackWAL undoes the mainchain blocks down to the rollback target of
+// the interrupted mutation
+func (c *Chain) rollbackWAL(record *WALRecord) error {
+	target := record.rollbackTarget()
+	height := c.lastBlock.BlockNumber()
+	for rolledBack := uint64(0); ; rolledBack++ {
+		mapping, err := c.state.GetBlockNumberMapping(height)
+		if err == nil && bytes.Equal(mapping.Headerhash, target) && c.verifyTip(height) == nil {
+			break
+		}
+		if height == 0 || rolledBack >= c.config.Dev.ReorgLimit {
+			return ErrChainUnrepairable
+		}
+		if err := c.undoTip(height); err != nil {
+			return err
+		}
+		height--
+	}
+
+	block, err := c.state.GetBlockByNumber(height)
+	if err != nil {
+		return err
+	}
+	blockMetadata, err := c.state.GetBlockMetadata(block.HeaderHash())
+	if err != nil {
+		return err
+	}
+	c.lastBlock = block
+	c.currentDifficulty = blockMetadata.BlockDifficulty()
+
+	c.log.Warn("Rolled back interrupted chain update", "height", height)
+	return nil
+}
+
+// replayWAL applies again the block of an interrupted mutation on top of
+// the tip before it. A block which never reached the database is synced
+// again from the peers.
+func (c *Chain) replayWAL(record *WALRecord) {
+	if record.Op != WALAddBlock {
+		return
+	}
+	block, err := c.state.GetBlock(record.HeaderHash)
+	if err != nil {
+		c.log.Info("Interrupted block not stored, it will be synced again", "block", record.BlockNumber)
+		return
+	}
+
+	batch := c.state.GetBatch()
+	blockFlag, forkFlag, err := c.addBlock(context.Background(), block, nil, batch)
+	if err != nil {
+		c.log.Error("Failed to write replayed block", "block", record.BlockNumber, "error", err)
+		return
+	}
+	if !blockFlag {
+		c.log.Warn("Failed to replay interrupted block", "block", record.BlockNumber, "headerhash", misc.Bin2HStr(record.HeaderHash))
+		return
+	}
+	if !forkFlag {
+		if err := c.state.WriteChainBatch(batch); err != nil {
+			c.log.Error("Failed to write replayed block", "block", record.BlockNumber, "error", err)
+			return
+		}
+	}
+	c.log.Info("Replayed interrupted block", "block", record.BlockNumber, "headerhash", misc.Bin2HStr(record.HeaderHash))
+}
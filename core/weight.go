@@ -0,0 +1,31 @@
+package core
+
+import (
+	"github.com/cyyber/go-qrl/generated"
+	"github.com/golang/protobuf/proto"
+)
+
+// The weight of a transaction accounts for its size and for the cost of
+// verifying its XMSS signature, which dominates validation time. Blocks are
+// bounded by the sum of the weights of their transactions in addition to
+// their size.
+
+// Upper bound of the encoded size of a coinbase transaction
+const maxCoinbaseSize = 256
+
+func TransactionWeight(pbdata *generated.Transaction, config *Config) uint64 {
+	weight := uint64(proto.Size(pbdata)) * config.Dev.Transaction.WeightPerByte
+	// Coinbase transactions are not signed
+	if pbdata.GetCoinbase() == nil {
+		weight += config.Dev.Transaction.SignatureWeight
+	}
+	return weight
+}
+
+func (b *Block) Weight() uint64 {
+	weight := uint64(0)
+	for _, pbdata := range b.Transactions() {
+		weight += TransactionWeight(pbdata, b.config)
+	}
+	return weight
+}
@@ -0,0 +1,28 @@
+package crypto
+
+import (
+	"crypto/sha512"
+	"fmt"
+	"github.com/cyyber/go-qrl/misc"
+	"github.com/theQRL/qrllib/goqrllib"
+)
+
+// Development accounts are XMSS trees derived from a public seed, so every
+// developer network funds the same addresses and their keys can be
+// imported anywhere. They must never hold value.
+
+const (
+	DevAccountTreeHeight   = 10
+	DevAccountHashFunction = "shake128"
+)
+
+// DevAccount returns the XMSS tree of the index-th development account, the
+// caller must Close it
+func DevAccount(index int) *XMSS {
+	digest := sha512.Sum512([]byte(fmt.Sprintf("go-qrl development account %d", index)))
+	seed := misc.BytesToUCharVector(digest[:48])
+
+	x := &XMSS{}
+	x.xmss = goqrllib.NewXmssFast__SWIG_1(seed, byte(DevAccountTreeHeight), hashFunctions[DevAccountHashFunction])
+	return x
+}
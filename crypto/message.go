@@ -0,0 +1,61 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"github.com/cyyber/go-qrl/misc"
+	"github.com/theQRL/qrllib/goqrllib"
+)
+
+// Signed messages are prefixed before hashing, so a message signature can
+// never be replayed as a transaction signature
+const signedMessagePrefix = "QRL Signed Message:\n"
+
+var (
+	ErrMessageSignatureTooShort = errors.New("message signature too short")
+	ErrMessagePKMismatch        = errors.New("public key does not match address")
+	ErrInvalidMessageSignature  = errors.New("invalid message signature")
+)
+
+func MessageDigest(message []byte) []byte {
+	var tmp []byte
+	tmp = append(tmp, []byte(signedMessagePrefix)...)
+	tmp = append(tmp, message...)
+	return misc.Sha256(tmp)
+}
+
+// SignMessage signs message with the next OTS key of x. Message signatures
+// consume OTS keys exactly like transactions, so the caller must persist
+// the new OTS index before releasing the signature.
+func (x *XMSS) SignMessage(message []byte) []byte {
+	return x.Sign(misc.BytesToUCharVector(MessageDigest(message)))
+}
+
+// SignatureOTSIndex returns the OTS index used to produce an XMSS signature
+func SignatureOTSIndex(signature []byte) (uint32, error) {
+	if len(signature) < 4 {
+		return 0, ErrMessageSignatureTooShort
+	}
+	return binary.BigEndian.Uint32(signature[0:4]), nil
+}
+
+// VerifyMessageSignature checks that signature was produced over message by
+// the XMSS tree with public key pk, and that pk belongs to address
+func VerifyMessageSignature(address []byte, message []byte, signature []byte, pk []byte) error {
+	if _, err := SignatureOTSIndex(signature); err != nil {
+		return err
+	}
+
+	if !bytes.Equal(misc.UCharVectorToBytes(goqrllib.QRLHelperGetAddress(misc.BytesToUCharVector(pk))), address) {
+		return ErrMessagePKMismatch
+	}
+
+	if !goqrllib.XmssFastVerify(misc.BytesToUCharVector(MessageDigest(message)),
+		misc.BytesToUCharVector(signature),
+		misc.BytesToUCharVector(pk)) {
+		return ErrInvalidMessageSignature
+	}
+
+	return nil
+}
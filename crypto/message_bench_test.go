@@ -0,0 +1,20 @@
+package crypto
+
+import (
+	"github.com/cyyber/go-qrl/misc"
+	"testing"
+)
+
+func BenchmarkXMSSVerify(b *testing.B) {
+	xmss := (&XMSS{}).FromHeight(4, "shake128")
+	message := []byte("benchmark")
+	signature := xmss.SignMessage(message)
+	address := misc.UCharVectorToBytes(xmss.Address())
+	pk := misc.UCharVectorToBytes(xmss.PK())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := VerifyMessageSignature(address, message, signature, pk); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
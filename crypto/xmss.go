@@ -80,7 +80,7 @@ func (x *XMSS) FromExtendedSeed(extendedSeed goqrllib.UcharVector) *XMSS {
 	hashFunction := descr.GetHashFunction()
 	tmp = misc.UcharVector{}
 	tmp.AddBytes(moddedExtendedSeed.GetBytes()[3:])
-	goqrllib.NewXmssFast__SWIG_1(tmp.GetData(), height, hashFunction)
+	x.xmss = goqrllib.NewXmssFast__SWIG_1(tmp.GetData(), height, hashFunction)
 
 	return x
 }
@@ -126,6 +126,10 @@ func (x *XMSS) pk() goqrllib.UcharVector {
 	return x.xmss.GetPK()
 }
 
+func (x *XMSS) PK() goqrllib.UcharVector {
+	return x.pk()
+}
+
 func (x *XMSS) NumberSignatures() uint {
 	return x.xmss.GetNumberSignatures()
 }
@@ -143,7 +147,7 @@ func (x *XMSS) Address() goqrllib.UcharVector {
 }
 
 func (x *XMSS) QAddress() string {
-	return "Q" + goqrllib.Bin2hstr(x.Address())
+	return misc.Qaddress(misc.UCharVectorToBytes(x.Address()))
 }
 
 func (x *XMSS) OTSIndex() uint {
@@ -155,7 +159,7 @@ func (x *XMSS) SetOTSIndex(newIndex uint) {
 }
 
 func (x *XMSS) HexSeed() string {
-	return goqrllib.Bin2hstr(x.xmss.GetExtendedSeed())
+	return misc.Bin2HStr(misc.UCharVectorToBytes(x.xmss.GetExtendedSeed()))
 }
 
 func (x *XMSS) ExtendedSeed() goqrllib.UcharVector {
@@ -166,6 +170,14 @@ func (x *XMSS) Seed() goqrllib.UcharVector {
 	return x.xmss.GetSeed()
 }
 
+// Close releases the tree, it must not be used afterwards
+func (x *XMSS) Close() {
+	if x.xmss != nil {
+		goqrllib.DeleteXmssFast(x.xmss)
+		x.xmss = nil
+	}
+}
+
 func (x *XMSS) Sign(message goqrllib.UcharVector) []byte {
 	msg := misc.UcharVector{}
 	msg.New(x.xmss.Sign(message))
@@ -1,54 +1,192 @@
 package db
 
 import (
+	"github.com/cyyber/go-qrl/log"
 	"github.com/syndtr/goleveldb/leveldb"
-	"github.com/syndtr/goleveldb/leveldb/opt"
 	"github.com/syndtr/goleveldb/leveldb/filter"
-	"github.com/cyyber/go-qrl/log"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/storage"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	"os"
+	"path/filepath"
 	"sync"
+	"time"
 )
 
 type LDB struct {
 	db *leveldb.DB
 
 	filename string
+	readOnly bool
+	options  *Options
 
 	exitLock sync.Mutex
+	stopSync chan struct{}
 
 	log log.Logger
 }
 
+// Options tunes LevelDB, sizes are in MiB
+type Options struct {
+	Cache           int
+	Handles         int
+	WriteBuffer     int
+	BloomFilterBits int
+
+	// Sync every batch written with sync requested, otherwise batches are
+	// only synced every SyncInterval, trading the last writes on power loss
+	// for throughput. WriteBatchSynced always syncs.
+	SyncWrites   bool
+	SyncInterval time.Duration
+
+	// Number of level 0 files triggering a compaction, slowing down then
+	// pausing writes, 0 keeps the LevelDB default
+	CompactionL0Trigger    int
+	WriteL0SlowdownTrigger int
+	WriteL0PauseTrigger    int
+	CompactionTableSize    int
+}
+
+// Written with sync to flush the journal on periodic syncs, LevelDB
+// ignores empty batches
+var syncMarkerKey = []byte("db_sync_marker")
+
 type ldbBatch struct {
 	db   *leveldb.DB
 	b    *leveldb.Batch
 	size int
 }
 
-func NewDB(file string, cache int, handles int, log *log.Logger) (*LDB, error) {
-	if cache < 16 {
-		cache = 16
+func openFile(file string, options *Options, readOnly bool) (*leveldb.DB, error) {
+	writeBuffer := options.WriteBuffer
+	if writeBuffer == 0 {
+		writeBuffer = options.Cache / 4
 	}
-	if handles < 16 {
-		handles = 16
-	}
-	db, err := leveldb.OpenFile(file, &opt.Options{
-		OpenFilesCacheCapacity: handles,
-		BlockCacheCapacity:     cache / 2 * opt.MiB,
-		WriteBuffer:            cache / 4 * opt.MiB,
-		Filter:                 filter.NewBloomFilter(10),
+	return leveldb.OpenFile(file, &opt.Options{
+		OpenFilesCacheCapacity: options.Handles,
+		BlockCacheCapacity:     options.Cache / 2 * opt.MiB,
+		WriteBuffer:            writeBuffer * opt.MiB,
+		Filter:                 filter.NewBloomFilter(options.BloomFilterBits),
+		CompactionL0Trigger:    options.CompactionL0Trigger,
+		WriteL0SlowdownTrigger: options.WriteL0SlowdownTrigger,
+		WriteL0PauseTrigger:    options.WriteL0PauseTrigger,
+		CompactionTableSize:    options.CompactionTableSize * opt.MiB,
+		ReadOnly:               readOnly,
 	})
+}
+
+func newDB(file string, options *Options, readOnly bool, log *log.Logger) (*LDB, error) {
+	if options.Cache < 16 {
+		options.Cache = 16
+	}
+	if options.Handles < 16 {
+		options.Handles = 16
+	}
+	if options.BloomFilterBits == 0 {
+		options.BloomFilterBits = 10
+	}
+	db, err := openFile(file, options, readOnly)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return &LDB{
+	ldb := &LDB{
 		filename: file,
+		readOnly: readOnly,
+		options:  options,
+		db:       db,
+		log:      *log,
+	}
+	ldb.startSync()
+	return ldb, nil
+}
+
+func (db *LDB) startSync() {
+	if !db.readOnly && !db.options.SyncWrites && db.options.SyncInterval > 0 {
+		db.stopSync = make(chan struct{})
+		go db.syncLoop(db.stopSync)
+	}
+}
+
+func NewDB(file string, options *Options, log *log.Logger) (*LDB, error) {
+	return newDB(file, options, false, log)
+}
+
+// NewReadOnlyDB opens a database which rejects every write. LevelDB doesn't
+// allow another process to open a database while it is open for writing,
+// so file is expected to be a snapshot copied from the primary node.
+func NewReadOnlyDB(file string, options *Options, log *log.Logger) (*LDB, error) {
+	return newDB(file, options, true, log)
+}
+
+// syncLoop flushes the journal every SyncInterval when batches aren't
+// synced as they are written
+func (db *LDB) syncLoop(stopSync chan struct{}) {
+	ticker := time.NewTicker(db.options.SyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := db.Sync(); err != nil {
+				db.log.Warn("Failed to sync LevelDB", "err", err)
+			}
+		case <-stopSync:
+			return
+		}
+	}
+}
+
+// Sync flushes the writes not synced yet to disk
+func (db *LDB) Sync() error {
+	db.exitLock.Lock()
+	defer db.exitLock.Unlock()
+
+	return db.db.Put(syncMarkerKey, nil, &opt.WriteOptions{Sync: true})
+}
+
+// NewMemDB opens a database kept in memory, used by the simulator
+func NewMemDB(log *log.Logger) (*LDB, error) {
+	db, err := leveldb.Open(storage.NewMemStorage(), &opt.Options{
+		Filter: filter.NewBloomFilter(10),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &LDB{
+		filename: "",
 		db:       db,
 		log:      *log,
 	}, nil
 }
 
+func (db *LDB) InMemory() bool {
+	return db.filename == ""
+}
+
+func (db *LDB) ReadOnly() bool {
+	return db.readOnly
+}
+
+// Reopen closes and opens the database again, so a read-only database picks
+// up a newer snapshot written in place by the primary
+func (db *LDB) Reopen() error {
+	db.exitLock.Lock()
+	defer db.exitLock.Unlock()
+
+	if err := db.db.Close(); err != nil {
+		return err
+	}
+	newDB, err := openFile(db.filename, db.options, db.readOnly)
+	if err != nil {
+		return err
+	}
+	db.db = newDB
+	return nil
+}
+
 func (db *LDB) Put(key []byte, value[] byte, batch *leveldb.Batch) error {
 	if batch != nil {
 		batch.Put(key, value)
@@ -69,7 +207,44 @@ func (db *LDB) Delete(key []byte) error {
 	return db.db.Delete(key, nil)
 }
 
+// IteratePrefix calls fn in key order for the keys starting with prefix,
+// from start when set, until fn returns false. Key and value are only valid
+// during the call.
+func (db *LDB) IteratePrefix(prefix []byte, start []byte, fn func(key []byte, value []byte) bool) error {
+	r := util.BytesPrefix(prefix)
+	if start != nil {
+		r.Start = start
+	}
+	iter := db.db.NewIterator(r, nil)
+	defer iter.Release()
+
+	for iter.Next() {
+		if !fn(iter.Key(), iter.Value()) {
+			break
+		}
+	}
+	return iter.Error()
+}
+
+// Open opens the database again after Close
+func (db *LDB) Open() error {
+	db.exitLock.Lock()
+	defer db.exitLock.Unlock()
+
+	newDB, err := openFile(db.filename, db.options, db.readOnly)
+	if err != nil {
+		return err
+	}
+	db.db = newDB
+	db.startSync()
+	return nil
+}
+
 func (db *LDB) Close() {
+	if db.stopSync != nil {
+		close(db.stopSync)
+		db.stopSync = nil
+	}
 	db.exitLock.Lock()
 	defer db.exitLock.Unlock()
 
@@ -90,12 +265,20 @@ func (db *LDB) GetBatch() *leveldb.Batch {
 	return &leveldb.Batch{}
 }
 
-func (db *LDB) WriteBatch(batch *leveldb.Batch, sync bool) {
+// WriteBatch writes batch, synced when sync is set unless the database is
+// synced periodically
+func (db *LDB) WriteBatch(batch *leveldb.Batch, sync bool) error {
 	var wo *opt.WriteOptions
-	if sync {
+	if sync && (db.options == nil || db.options.SyncWrites) {
 		wo = &opt.WriteOptions{Sync: sync}
 	}
-	db.db.Write(batch, wo)
+	return db.db.Write(batch, wo)
+}
+
+// WriteBatchSynced writes batch synced whatever SyncWrites, for the writes
+// which must be on disk before they are acknowledged
+func (db *LDB) WriteBatchSynced(batch *leveldb.Batch) error {
+	return db.db.Write(batch, &opt.WriteOptions{Sync: true})
 }
 
 func (db *LDB) NewBatch() *ldbBatch {
@@ -119,4 +302,41 @@ func (b *ldbBatch) ValueSize() int {
 func (b *ldbBatch) Reset() {
 	b.b.Reset()
 	b.size = 0
-}
\ No newline at end of file
+}
+
+// Compact compacts the whole key range, reclaiming the space of deleted and
+// overwritten entries
+func (db *LDB) Compact() error {
+	return db.db.CompactRange(util.Range{})
+}
+
+// DiskSize returns the size of the database files on disk
+func (db *LDB) DiskSize() (uint64, error) {
+	var size uint64
+	err := filepath.Walk(db.filename, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			size += uint64(info.Size())
+		}
+		return nil
+	})
+	return size, err
+}
+
+// KeyspaceSizes iterates the whole database and sums the size of keys and
+// values per keyspace, as returned by classify. The sizes are uncompressed,
+// so they only give the relative weight of each keyspace on disk.
+func (db *LDB) KeyspaceSizes(classify func(key []byte) string) (map[string]uint64, error) {
+	sizes := make(map[string]uint64)
+
+	iter := db.db.NewIterator(nil, nil)
+	defer iter.Release()
+
+	for iter.Next() {
+		sizes[classify(iter.Key())] += uint64(len(iter.Key()) + len(iter.Value()))
+	}
+
+	return sizes, iter.Error()
+}
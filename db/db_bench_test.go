@@ -0,0 +1,27 @@
+package db
+
+import (
+	"encoding/binary"
+	"github.com/cyyber/go-qrl/log"
+	"testing"
+)
+
+func BenchmarkDBBatchWrite(b *testing.B) {
+	logger := log.New()
+	ldb, err := NewMemDB(&logger)
+	if err != nil {
+		b.Fatal(err)
+	}
+	value := make([]byte, 256)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		batch := ldb.GetBatch()
+		for j := 0; j < 100; j++ {
+			key := make([]byte, 16)
+			binary.BigEndian.PutUint64(key, uint64(i))
+			binary.BigEndian.PutUint64(key[8:], uint64(j))
+			ldb.Put(key, value, batch)
+		}
+		ldb.WriteBatch(batch, false)
+	}
+}
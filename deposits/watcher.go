@@ -0,0 +1,304 @@
+package deposits
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/cyyber/go-qrl/core"
+	"github.com/cyyber/go-qrl/diagnostics"
+	"github.com/cyyber/go-qrl/events"
+	"github.com/cyyber/go-qrl/generated"
+	"github.com/cyyber/go-qrl/log"
+	"github.com/cyyber/go-qrl/misc"
+	"sync"
+)
+
+type Status string
+
+const (
+	StatusConfirmed Status = "confirmed"
+	// A previously confirmed deposit whose block left the mainchain
+	StatusReverted Status = "reverted"
+)
+
+// Deposit is one output of a transaction paying a watched address.
+// ID is stable across restarts and reorgs, so consumers can use it to
+// process each deposit exactly once.
+type Deposit struct {
+	ID          string
+	Address     []byte
+	TxHash      []byte
+	OutputIndex int
+	Amount      uint64
+	// Set for token transfers
+	TokenTxHash []byte
+
+	BlockNumber uint64
+	HeaderHash  []byte
+}
+
+type DepositEvent struct {
+	Deposit       *Deposit
+	Status        Status
+	Confirmations uint64
+}
+
+// Sink receives deposit events in addition to the event bus
+type Sink interface {
+	Deliver(event *DepositEvent) error
+}
+
+// Watcher reports transfers to a set of watched addresses once they reach
+// the configured confirmation depth, and reports them as reverted if their
+// block is later removed from the mainchain by a reorg.
+//
+// The mainchain is scanned by height from the last scanned block on every
+// new block or reorg event, rather than block by block from the events, so
+// the events dropped by a full subscription buffer are never missed.
+type Watcher struct {
+	lock sync.Mutex
+
+	log      log.Logger
+	manager  *core.ChainManager
+	eventBus *events.Bus
+	sinks    []Sink
+
+	addresses     map[string]bool
+	confirmations uint64
+	reorgLimit    uint64
+
+	// Last mainchain block scanned for deposits
+	scannedHeight uint64
+	scannedHash   []byte
+
+	// Deposits waiting for confirmations, and deposits already reported.
+	// Reported deposits deeper than the reorg limit can't be reverted
+	// anymore and are forgotten.
+	pending  map[string]*Deposit
+	reported map[string]*Deposit
+
+	subscription *events.Subscription
+	quit         chan struct{}
+	wg           sync.WaitGroup
+}
+
+func NewWatcher(manager *core.ChainManager, eventBus *events.Bus, config *core.Config, log log.Logger) (*Watcher, error) {
+	depositsConfig := config.User.Deposits
+	w := &Watcher{
+		log:           log,
+		manager:       manager,
+		eventBus:      eventBus,
+		addresses:     make(map[string]bool),
+		confirmations: depositsConfig.Confirmations,
+		reorgLimit:    config.Dev.ReorgLimit,
+		pending:       make(map[string]*Deposit),
+		reported:      make(map[string]*Deposit),
+	}
+
+	for _, qaddress := range depositsConfig.WatchedAddresses {
+		address, err := misc.ParseQaddress(qaddress)
+		if err != nil {
+			return nil, fmt.Errorf("invalid watched address %s: %s", qaddress, err)
+		}
+		w.addresses[string(address)] = true
+	}
+
+	return w, nil
+}
+
+func (w *Watcher) AddSink(sink Sink) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.sinks = append(w.sinks, sink)
+}
+
+// Start scans the blocks which may still reach the confirmation depth, then
+// watches the new blocks
+func (w *Watcher) Start() {
+	w.lock.Lock()
+	if w.scannedHash == nil {
+		tip := w.manager.Tip()
+		if tip.BlockNumber() > w.confirmations {
+			w.rewindTo(tip.BlockNumber() - w.confirmations)
+		}
+	}
+	w.lock.Unlock()
+
+	w.quit = make(chan struct{})
+	w.subscription = w.eventBus.Subscribe(events.TopicNewBlock, events.TopicReorg)
+	w.wg.Add(1)
+	diagnostics.Go("deposits", w.run)
+}
+
+func (w *Watcher) Stop() {
+	close(w.quit)
+	w.subscription.Unsubscribe()
+	w.wg.Wait()
+}
+
+func (w *Watcher) run() {
+	defer w.wg.Done()
+
+	w.sync()
+	for {
+		select {
+		case <-w.quit:
+			return
+		case _, ok := <-w.subscription.Events():
+			if !ok {
+				return
+			}
+			w.sync()
+		}
+	}
+}
+
+func depositID(txHash []byte, outputIndex int) string {
+	return fmt.Sprintf("%s:%d", misc.Bin2HStr(txHash), outputIndex)
+}
+
+func (w *Watcher) scanBlock(block *core.Block) {
+	for _, protoTX := range block.Transactions() {
+		var addrsTo [][]byte
+		var amounts []uint64
+		var tokenTxHash []byte
+
+		switch txType := protoTX.TransactionType.(type) {
+		case *generated.Transaction_Transfer_:
+			addrsTo = txType.Transfer.AddrsTo
+			amounts = txType.Transfer.Amounts
+		case *generated.Transaction_TransferToken_:
+			addrsTo = txType.TransferToken.AddrsTo
+			amounts = txType.TransferToken.Amounts
+			tokenTxHash = txType.TransferToken.TokenTxhash
+		default:
+			continue
+		}
+
+		for i, addrTo := range addrsTo {
+			if !w.addresses[string(addrTo)] || i >= len(amounts) {
+				continue
+			}
+			id := depositID(protoTX.TransactionHash, i)
+			if _, ok := w.reported[id]; ok {
+				continue
+			}
+			w.pending[id] = &Deposit{
+				ID:          id,
+				Address:     addrTo,
+				TxHash:      protoTX.TransactionHash,
+				OutputIndex: i,
+				Amount:      amounts[i],
+				TokenTxHash: tokenTxHash,
+				BlockNumber: block.BlockNumber(),
+				HeaderHash:  block.HeaderHash(),
+			}
+		}
+	}
+}
+
+// isCanonical returns true if the block of deposit is still on the mainchain
+func (w *Watcher) isCanonical(deposit *Deposit) bool {
+	block, err := w.manager.GetBlockByNumber(deposit.BlockNumber)
+	if err != nil {
+		return false
+	}
+	return misc.Bin2HStr(block.HeaderHash()) == misc.Bin2HStr(deposit.HeaderHash)
+}
+
+// rewindTo makes blockNumber the last scanned block
+func (w *Watcher) rewindTo(blockNumber uint64) {
+	w.scannedHeight = blockNumber
+	w.scannedHash = nil
+	if block, err := w.manager.GetBlockByNumber(blockNumber); err == nil {
+		w.scannedHash = block.HeaderHash()
+	}
+}
+
+// sync scans the mainchain blocks added since the last scan, after moving
+// back to the fork point if the last scanned block left the mainchain, and
+// reports the deposits which were confirmed or reverted meanwhile
+func (w *Watcher) sync() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	for w.scannedHeight > 0 {
+		block, err := w.manager.GetBlockByNumber(w.scannedHeight)
+		if err == nil && bytes.Equal(block.HeaderHash(), w.scannedHash) {
+			break
+		}
+		scanned, err := w.manager.GetBlock(w.scannedHash)
+		if err != nil {
+			w.rewindTo(w.scannedHeight - 1)
+			continue
+		}
+		w.scannedHeight--
+		w.scannedHash = scanned.PrevHeaderHash()
+	}
+
+	w.revertDropped()
+
+	height := w.manager.Height()
+	for w.scannedHeight < height {
+		block, err := w.manager.GetBlockByNumber(w.scannedHeight + 1)
+		if err != nil {
+			w.log.Warn("Deposit watcher failed to load block", "number", w.scannedHeight+1, "error", err)
+			break
+		}
+		w.scanBlock(block)
+		w.scannedHeight = block.BlockNumber()
+		w.scannedHash = block.HeaderHash()
+	}
+
+	for id, deposit := range w.pending {
+		confirmations := height - deposit.BlockNumber + 1
+		if confirmations < w.confirmations {
+			continue
+		}
+		delete(w.pending, id)
+		w.reported[id] = deposit
+		w.deliver(&DepositEvent{
+			Deposit:       deposit,
+			Status:        StatusConfirmed,
+			Confirmations: confirmations,
+		})
+	}
+
+	for id, deposit := range w.reported {
+		if deposit.BlockNumber+w.reorgLimit < height {
+			delete(w.reported, id)
+		}
+	}
+}
+
+// revertDropped drops pending deposits and reverts reported deposits whose
+// block left the mainchain. Deposits included again in the new branch are
+// found when its blocks are scanned.
+func (w *Watcher) revertDropped() {
+	for id, deposit := range w.pending {
+		if !w.isCanonical(deposit) {
+			delete(w.pending, id)
+		}
+	}
+
+	for id, deposit := range w.reported {
+		if w.isCanonical(deposit) {
+			continue
+		}
+		delete(w.reported, id)
+		w.log.Warn("Reported deposit reverted by reorg", "id", id)
+		w.deliver(&DepositEvent{
+			Deposit: deposit,
+			Status:  StatusReverted,
+		})
+	}
+}
+
+func (w *Watcher) deliver(event *DepositEvent) {
+	w.eventBus.Publish(events.TopicDeposit, event)
+	for _, sink := range w.sinks {
+		if err := sink.Deliver(event); err != nil {
+			w.log.Warn("Failed to deliver deposit event", "id", event.Deposit.ID, "error", err)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+// Package diagnostics serves net/http/pprof and runtime statistics on a
+// localhost only debug port, to diagnose stalls during sync and mining.
+package diagnostics
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/cyyber/go-qrl/log"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	rpprof "runtime/pprof"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const subsystemLabel = "subsystem"
+
+var ErrNotLoopback = errors.New("debug server must be bound to a loopback address")
+
+// Go starts f in a goroutine labelled with subsystem, so that it is counted
+// per subsystem in the runtime stats
+func Go(subsystem string, f func()) {
+	go rpprof.Do(context.Background(), rpprof.Labels(subsystemLabel, subsystem), func(context.Context) {
+		f()
+	})
+}
+
+var (
+	gaugesLock sync.RWMutex
+	gauges     = make(map[string]func() int64)
+)
+
+// RegisterGauge exposes the value returned by f, typically the depth of a
+// channel or queue, in the runtime stats
+func RegisterGauge(name string, f func() int64) {
+	gaugesLock.Lock()
+	defer gaugesLock.Unlock()
+
+	gauges[name] = f
+}
+
+func UnregisterGauge(name string) {
+	gaugesLock.Lock()
+	defer gaugesLock.Unlock()
+
+	delete(gauges, name)
+}
+
+type GCStats struct {
+	NumGC         int64
+	LastGC        time.Time
+	PauseTotal    time.Duration
+	LastPause     time.Duration
+	HeapAlloc     uint64
+	HeapObjects   uint64
+	HeapSys       uint64
+	NextGC        uint64
+	GCCPUFraction float64
+}
+
+type RuntimeStats struct {
+	Goroutines            int
+	GoroutinesBySubsystem map[string]int
+	Gauges                map[string]int64
+	GC                    *GCStats
+}
+
+var labelsRegexp = regexp.MustCompile(`"` + subsystemLabel + `":"([^"]*)"`)
+
+// goroutinesBySubsystem parses the debug=1 goroutine profile, in which each
+// group of identical goroutines starts with "<count> @ <pcs>" and is
+// followed by a "# labels: {...}" line when labelled
+func goroutinesBySubsystem() map[string]int {
+	var buf bytes.Buffer
+	rpprof.Lookup("goroutine").WriteTo(&buf, 1)
+
+	counts := make(map[string]int)
+	count := 0
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if at := strings.Index(line, " @ "); at > 0 {
+			if n, err := strconv.Atoi(line[:at]); err == nil {
+				count = n
+				counts["unlabelled"] += n
+				continue
+			}
+		}
+		if match := labelsRegexp.FindStringSubmatch(line); match != nil && count > 0 {
+			counts[match[1]] += count
+			counts["unlabelled"] -= count
+			count = 0
+		}
+	}
+	return counts
+}
+
+func gcStats() *GCStats {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	var stats debug.GCStats
+	debug.ReadGCStats(&stats)
+
+	result := &GCStats{
+		NumGC:         stats.NumGC,
+		LastGC:        stats.LastGC,
+		PauseTotal:    stats.PauseTotal,
+		HeapAlloc:     memStats.HeapAlloc,
+		HeapObjects:   memStats.HeapObjects,
+		HeapSys:       memStats.HeapSys,
+		NextGC:        memStats.NextGC,
+		GCCPUFraction: memStats.GCCPUFraction,
+	}
+	if len(stats.Pause) > 0 {
+		result.LastPause = stats.Pause[0]
+	}
+	return result
+}
+
+func Stats() *RuntimeStats {
+	stats := &RuntimeStats{
+		Goroutines:            runtime.NumGoroutine(),
+		GoroutinesBySubsystem: goroutinesBySubsystem(),
+		Gauges:                make(map[string]int64),
+		GC:                    gcStats(),
+	}
+
+	gaugesLock.RLock()
+	defer gaugesLock.RUnlock()
+
+	names := make([]string, 0, len(gauges))
+	for name := range gauges {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		stats.Gauges[name] = gauges[name]()
+	}
+
+	return stats
+}
+
+// Server serves the pprof handlers under /debug/pprof/ and the runtime
+// stats as JSON under /debug/stats
+type Server struct {
+	host   string
+	log    log.Logger
+	server *http.Server
+}
+
+func NewServer(host string, port uint16, log log.Logger) *Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc("/debug/stats", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(Stats())
+	})
+
+	return &Server{
+		host: host,
+		log:  log,
+		server: &http.Server{
+			Addr:    net.JoinHostPort(host, strconv.Itoa(int(port))),
+			Handler: mux,
+		},
+	}
+}
+
+func (s *Server) Start() error {
+	ip := net.ParseIP(s.host)
+	if s.host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return ErrNotLoopback
+	}
+
+	// An http.Server can't serve again once shut down
+	s.server = &http.Server{Addr: s.server.Addr, Handler: s.server.Handler}
+
+	listener, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return err
+	}
+
+	s.log.Info("Debug server listening", "address", s.server.Addr)
+	Go("diagnostics", func() {
+		if err := s.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			s.log.Warn("Debug server stopped", "error", err)
+		}
+	})
+	return nil
+}
+
+func (s *Server) Stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return s.server.Shutdown(ctx)
+}
@@ -0,0 +1,133 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+type Topic string
+
+const (
+	TopicNewBlock Topic = "new_block"
+	TopicReorg    Topic = "reorg"
+	TopicDeposit  Topic = "deposit"
+)
+
+type Event struct {
+	Topic     Topic
+	Timestamp time.Time
+	Data      interface{}
+}
+
+// ReorgEvent is published whenever the mainchain switches to another branch
+type ReorgEvent struct {
+	OldTip          []byte
+	OldHeight       uint64
+	NewTip          []byte
+	NewHeight       uint64
+	ForkPoint       []byte
+	Depth           uint64
+	DroppedTxHashes [][]byte
+}
+
+type NewBlockEvent struct {
+	HeaderHash  []byte
+	BlockNumber uint64
+	Timestamp   uint64
+}
+
+// Subscription receives the events published on the topics it was created
+// for. Slow subscribers never block publishers, events are dropped instead
+// once the subscription buffer is full.
+type Subscription struct {
+	bus    *Bus
+	topics map[Topic]bool
+	ch     chan *Event
+	once   sync.Once
+}
+
+func (s *Subscription) Events() <-chan *Event {
+	return s.ch
+}
+
+func (s *Subscription) Unsubscribe() {
+	s.once.Do(func() {
+		s.bus.remove(s)
+		close(s.ch)
+	})
+}
+
+type Bus struct {
+	lock sync.RWMutex
+
+	subscriptions map[*Subscription]bool
+	bufferSize    int
+}
+
+func NewBus(bufferSize int) *Bus {
+	return &Bus{
+		subscriptions: make(map[*Subscription]bool),
+		bufferSize:    bufferSize,
+	}
+}
+
+func (b *Bus) Subscribe(topics ...Topic) *Subscription {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	s := &Subscription{
+		bus:    b,
+		topics: make(map[Topic]bool),
+		ch:     make(chan *Event, b.bufferSize),
+	}
+	for _, topic := range topics {
+		s.topics[topic] = true
+	}
+	b.subscriptions[s] = true
+
+	return s
+}
+
+func (b *Bus) remove(s *Subscription) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	delete(b.subscriptions, s)
+}
+
+func (b *Bus) Publish(topic Topic, data interface{}) {
+	if b == nil {
+		return
+	}
+
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	event := &Event{
+		Topic:     topic,
+		Timestamp: time.Now(),
+		Data:      data,
+	}
+
+	for s := range b.subscriptions {
+		if !s.topics[topic] {
+			continue
+		}
+		select {
+		case s.ch <- event:
+		default:
+		}
+	}
+}
+
+// Pending returns the number of events waiting in subscription buffers
+func (b *Bus) Pending() int {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	pending := 0
+	for s := range b.subscriptions {
+		pending += len(s.ch)
+	}
+	return pending
+}
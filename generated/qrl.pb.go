@@ -37,6 +37,13 @@ It has these top-level messages:
 	TokenTxnReq
 	TransferTokenTxnReq
 	SlaveTxnReq
+	GetLatticeKeysByAddressReq
+	GetLatticeKeysByAddressResp
+	VerifyMessageSignatureReq
+	VerifyMessageSignatureResp
+	GetMiniBlocksReq
+	MiniBlock
+	GetMiniBlocksResp
 	GetLocalAddressesReq
 	GetLocalAddressesResp
 	NodeInfo
@@ -70,6 +77,7 @@ It has these top-level messages:
 	P2PAcknowledgement
 	PeerInfo
 	Peers
+	LockedBalance
 	TransactionMetadata
 	LastTransactions
 	ForkState
@@ -454,6 +462,9 @@ type GetStatsResp struct {
 	CoinsTotalSupply uint64            `protobuf:"varint,7,opt,name=coins_total_supply,json=coinsTotalSupply" json:"coins_total_supply,omitempty"`
 	CoinsEmitted     uint64            `protobuf:"varint,8,opt,name=coins_emitted,json=coinsEmitted" json:"coins_emitted,omitempty"`
 	BlockTimeseries  []*BlockDataPoint `protobuf:"bytes,9,rep,name=block_timeseries,json=blockTimeseries" json:"block_timeseries,omitempty"`
+	BlockDifficulty  string            `protobuf:"bytes,10,opt,name=block_difficulty,json=blockDifficulty" json:"block_difficulty,omitempty"`
+	NetworkHashrate  float64           `protobuf:"fixed64,11,opt,name=network_hashrate,json=networkHashrate" json:"network_hashrate,omitempty"`
+	EpochProgress    float32           `protobuf:"fixed32,12,opt,name=epoch_progress,json=epochProgress" json:"epoch_progress,omitempty"`
 }
 
 func (m *GetStatsResp) Reset()                    { *m = GetStatsResp{} }
@@ -524,6 +535,27 @@ func (m *GetStatsResp) GetBlockTimeseries() []*BlockDataPoint {
 	return nil
 }
 
+func (m *GetStatsResp) GetBlockDifficulty() string {
+	if m != nil {
+		return m.BlockDifficulty
+	}
+	return ""
+}
+
+func (m *GetStatsResp) GetNetworkHashrate() float64 {
+	if m != nil {
+		return m.NetworkHashrate
+	}
+	return 0
+}
+
+func (m *GetStatsResp) GetEpochProgress() float32 {
+	if m != nil {
+		return m.EpochProgress
+	}
+	return 0
+}
+
 type GetAddressFromPKReq struct {
 	Pk []byte `protobuf:"bytes,1,opt,name=pk,proto3" json:"pk,omitempty"`
 }
@@ -1230,6 +1262,214 @@ func (m *SlaveTxnReq) GetXmssPk() []byte {
 	return nil
 }
 
+type GetLatticeKeysByAddressReq struct {
+	Address []byte `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+}
+
+func (m *GetLatticeKeysByAddressReq) Reset()                    { *m = GetLatticeKeysByAddressReq{} }
+func (m *GetLatticeKeysByAddressReq) String() string            { return proto.CompactTextString(m) }
+func (*GetLatticeKeysByAddressReq) ProtoMessage()               {}
+func (*GetLatticeKeysByAddressReq) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{61} }
+
+func (m *GetLatticeKeysByAddressReq) GetAddress() []byte {
+	if m != nil {
+		return m.Address
+	}
+	return nil
+}
+
+type GetLatticeKeysByAddressResp struct {
+	LatticePks []*LatticePK `protobuf:"bytes,1,rep,name=lattice_pks,json=latticePks" json:"lattice_pks,omitempty"`
+}
+
+func (m *GetLatticeKeysByAddressResp) Reset()                    { *m = GetLatticeKeysByAddressResp{} }
+func (m *GetLatticeKeysByAddressResp) String() string            { return proto.CompactTextString(m) }
+func (*GetLatticeKeysByAddressResp) ProtoMessage()               {}
+func (*GetLatticeKeysByAddressResp) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{62} }
+
+func (m *GetLatticeKeysByAddressResp) GetLatticePks() []*LatticePK {
+	if m != nil {
+		return m.LatticePks
+	}
+	return nil
+}
+
+type VerifyMessageSignatureReq struct {
+	Address   []byte `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Message   []byte `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Signature []byte `protobuf:"bytes,3,opt,name=signature,proto3" json:"signature,omitempty"`
+	Pk        []byte `protobuf:"bytes,4,opt,name=pk,proto3" json:"pk,omitempty"`
+}
+
+func (m *VerifyMessageSignatureReq) Reset()                    { *m = VerifyMessageSignatureReq{} }
+func (m *VerifyMessageSignatureReq) String() string            { return proto.CompactTextString(m) }
+func (*VerifyMessageSignatureReq) ProtoMessage()               {}
+func (*VerifyMessageSignatureReq) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{63} }
+
+func (m *VerifyMessageSignatureReq) GetAddress() []byte {
+	if m != nil {
+		return m.Address
+	}
+	return nil
+}
+
+func (m *VerifyMessageSignatureReq) GetMessage() []byte {
+	if m != nil {
+		return m.Message
+	}
+	return nil
+}
+
+func (m *VerifyMessageSignatureReq) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+func (m *VerifyMessageSignatureReq) GetPk() []byte {
+	if m != nil {
+		return m.Pk
+	}
+	return nil
+}
+
+type VerifyMessageSignatureResp struct {
+	Valid               bool   `protobuf:"varint,1,opt,name=valid" json:"valid,omitempty"`
+	Error               string `protobuf:"bytes,2,opt,name=error" json:"error,omitempty"`
+	OtsIndex            uint32 `protobuf:"varint,3,opt,name=ots_index,json=otsIndex" json:"ots_index,omitempty"`
+	OtsIndexUsedOnChain bool   `protobuf:"varint,4,opt,name=ots_index_used_on_chain,json=otsIndexUsedOnChain" json:"ots_index_used_on_chain,omitempty"`
+}
+
+func (m *VerifyMessageSignatureResp) Reset()                    { *m = VerifyMessageSignatureResp{} }
+func (m *VerifyMessageSignatureResp) String() string            { return proto.CompactTextString(m) }
+func (*VerifyMessageSignatureResp) ProtoMessage()               {}
+func (*VerifyMessageSignatureResp) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{64} }
+
+func (m *VerifyMessageSignatureResp) GetValid() bool {
+	if m != nil {
+		return m.Valid
+	}
+	return false
+}
+
+func (m *VerifyMessageSignatureResp) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *VerifyMessageSignatureResp) GetOtsIndex() uint32 {
+	if m != nil {
+		return m.OtsIndex
+	}
+	return 0
+}
+
+func (m *VerifyMessageSignatureResp) GetOtsIndexUsedOnChain() bool {
+	if m != nil {
+		return m.OtsIndexUsedOnChain
+	}
+	return false
+}
+
+type GetMiniBlocksReq struct {
+	FromBlockNumber uint64 `protobuf:"varint,1,opt,name=from_block_number,json=fromBlockNumber" json:"from_block_number,omitempty"`
+	Count           uint32 `protobuf:"varint,2,opt,name=count" json:"count,omitempty"`
+}
+
+func (m *GetMiniBlocksReq) Reset()                    { *m = GetMiniBlocksReq{} }
+func (m *GetMiniBlocksReq) String() string            { return proto.CompactTextString(m) }
+func (*GetMiniBlocksReq) ProtoMessage()               {}
+func (*GetMiniBlocksReq) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{65} }
+
+func (m *GetMiniBlocksReq) GetFromBlockNumber() uint64 {
+	if m != nil {
+		return m.FromBlockNumber
+	}
+	return 0
+}
+
+func (m *GetMiniBlocksReq) GetCount() uint32 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+type MiniBlock struct {
+	BlockNumber      uint64 `protobuf:"varint,1,opt,name=block_number,json=blockNumber" json:"block_number,omitempty"`
+	HeaderHash       []byte `protobuf:"bytes,2,opt,name=header_hash,json=headerHash,proto3" json:"header_hash,omitempty"`
+	TimestampSeconds uint64 `protobuf:"varint,3,opt,name=timestamp_seconds,json=timestampSeconds" json:"timestamp_seconds,omitempty"`
+	TransactionCount uint32 `protobuf:"varint,4,opt,name=transaction_count,json=transactionCount" json:"transaction_count,omitempty"`
+	TotalTransferred uint64 `protobuf:"varint,5,opt,name=total_transferred,json=totalTransferred" json:"total_transferred,omitempty"`
+	MinerAddress     []byte `protobuf:"bytes,6,opt,name=miner_address,json=minerAddress,proto3" json:"miner_address,omitempty"`
+}
+
+func (m *MiniBlock) Reset()                    { *m = MiniBlock{} }
+func (m *MiniBlock) String() string            { return proto.CompactTextString(m) }
+func (*MiniBlock) ProtoMessage()               {}
+func (*MiniBlock) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{66} }
+
+func (m *MiniBlock) GetBlockNumber() uint64 {
+	if m != nil {
+		return m.BlockNumber
+	}
+	return 0
+}
+
+func (m *MiniBlock) GetHeaderHash() []byte {
+	if m != nil {
+		return m.HeaderHash
+	}
+	return nil
+}
+
+func (m *MiniBlock) GetTimestampSeconds() uint64 {
+	if m != nil {
+		return m.TimestampSeconds
+	}
+	return 0
+}
+
+func (m *MiniBlock) GetTransactionCount() uint32 {
+	if m != nil {
+		return m.TransactionCount
+	}
+	return 0
+}
+
+func (m *MiniBlock) GetTotalTransferred() uint64 {
+	if m != nil {
+		return m.TotalTransferred
+	}
+	return 0
+}
+
+func (m *MiniBlock) GetMinerAddress() []byte {
+	if m != nil {
+		return m.MinerAddress
+	}
+	return nil
+}
+
+type GetMiniBlocksResp struct {
+	MiniBlocks []*MiniBlock `protobuf:"bytes,1,rep,name=mini_blocks,json=miniBlocks" json:"mini_blocks,omitempty"`
+}
+
+func (m *GetMiniBlocksResp) Reset()                    { *m = GetMiniBlocksResp{} }
+func (m *GetMiniBlocksResp) String() string            { return proto.CompactTextString(m) }
+func (*GetMiniBlocksResp) ProtoMessage()               {}
+func (*GetMiniBlocksResp) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{67} }
+
+func (m *GetMiniBlocksResp) GetMiniBlocks() []*MiniBlock {
+	if m != nil {
+		return m.MiniBlocks
+	}
+	return nil
+}
+
 type GetLocalAddressesReq struct {
 }
 
@@ -1255,14 +1495,16 @@ func (m *GetLocalAddressesResp) GetAddresses() [][]byte {
 }
 
 type NodeInfo struct {
-	Version        string         `protobuf:"bytes,1,opt,name=version" json:"version,omitempty"`
-	State          NodeInfo_State `protobuf:"varint,2,opt,name=state,enum=qrl.NodeInfo_State" json:"state,omitempty"`
-	NumConnections uint32         `protobuf:"varint,3,opt,name=num_connections,json=numConnections" json:"num_connections,omitempty"`
-	NumKnownPeers  uint32         `protobuf:"varint,4,opt,name=num_known_peers,json=numKnownPeers" json:"num_known_peers,omitempty"`
-	Uptime         uint64         `protobuf:"varint,5,opt,name=uptime" json:"uptime,omitempty"`
-	BlockHeight    uint64         `protobuf:"varint,6,opt,name=block_height,json=blockHeight" json:"block_height,omitempty"`
-	BlockLastHash  []byte         `protobuf:"bytes,7,opt,name=block_last_hash,json=blockLastHash,proto3" json:"block_last_hash,omitempty"`
-	NetworkId      string         `protobuf:"bytes,8,opt,name=network_id,json=networkId" json:"network_id,omitempty"`
+	Version                  string         `protobuf:"bytes,1,opt,name=version" json:"version,omitempty"`
+	State                    NodeInfo_State `protobuf:"varint,2,opt,name=state,enum=qrl.NodeInfo_State" json:"state,omitempty"`
+	NumConnections           uint32         `protobuf:"varint,3,opt,name=num_connections,json=numConnections" json:"num_connections,omitempty"`
+	NumKnownPeers            uint32         `protobuf:"varint,4,opt,name=num_known_peers,json=numKnownPeers" json:"num_known_peers,omitempty"`
+	Uptime                   uint64         `protobuf:"varint,5,opt,name=uptime" json:"uptime,omitempty"`
+	BlockHeight              uint64         `protobuf:"varint,6,opt,name=block_height,json=blockHeight" json:"block_height,omitempty"`
+	BlockLastHash            []byte         `protobuf:"bytes,7,opt,name=block_last_hash,json=blockLastHash,proto3" json:"block_last_hash,omitempty"`
+	NetworkId                string         `protobuf:"bytes,8,opt,name=network_id,json=networkId" json:"network_id,omitempty"`
+	StateMismatch            bool           `protobuf:"varint,9,opt,name=state_mismatch,json=stateMismatch" json:"state_mismatch,omitempty"`
+	StateMismatchBlockNumber uint64         `protobuf:"varint,10,opt,name=state_mismatch_block_number,json=stateMismatchBlockNumber" json:"state_mismatch_block_number,omitempty"`
 }
 
 func (m *NodeInfo) Reset()                    { *m = NodeInfo{} }
@@ -1326,6 +1568,20 @@ func (m *NodeInfo) GetNetworkId() string {
 	return ""
 }
 
+func (m *NodeInfo) GetStateMismatch() bool {
+	if m != nil {
+		return m.StateMismatch
+	}
+	return false
+}
+
+func (m *NodeInfo) GetStateMismatchBlockNumber() uint64 {
+	if m != nil {
+		return m.StateMismatchBlockNumber
+	}
+	return 0
+}
+
 type StoredPeers struct {
 	Peers []*Peer `protobuf:"bytes,1,rep,name=peers" json:"peers,omitempty"`
 }
@@ -1368,6 +1624,7 @@ type AddressState struct {
 	LatticePKList      []*LatticePK      `protobuf:"bytes,7,rep,name=latticePK_list,json=latticePKList" json:"latticePK_list,omitempty"`
 	SlavePksAccessType map[string]uint32 `protobuf:"bytes,8,rep,name=slave_pks_access_type,json=slavePksAccessType" json:"slave_pks_access_type,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
 	OtsCounter         uint64            `protobuf:"varint,9,opt,name=ots_counter,json=otsCounter" json:"ots_counter,omitempty"`
+	LockedBalances     []*LockedBalance  `protobuf:"bytes,10,rep,name=locked_balances,json=lockedBalances" json:"locked_balances,omitempty"`
 }
 
 func (m *AddressState) Reset()                    { *m = AddressState{} }
@@ -1438,6 +1695,13 @@ func (m *AddressState) GetOtsCounter() uint64 {
 	return 0
 }
 
+func (m *AddressState) GetLockedBalances() []*LockedBalance {
+	if m != nil {
+		return m.LockedBalances
+	}
+	return nil
+}
+
 type LatticePK struct {
 	Txhash      []byte `protobuf:"bytes,1,opt,name=txhash,proto3" json:"txhash,omitempty"`
 	DilithiumPk []byte `protobuf:"bytes,2,opt,name=dilithium_pk,json=dilithiumPk,proto3" json:"dilithium_pk,omitempty"`
@@ -1621,6 +1885,8 @@ type TransactionExtended struct {
 	AddrFrom         []byte       `protobuf:"bytes,3,opt,name=addr_from,json=addrFrom,proto3" json:"addr_from,omitempty"`
 	Size             uint64       `protobuf:"varint,4,opt,name=size" json:"size,omitempty"`
 	TimestampSeconds uint64       `protobuf:"varint,5,opt,name=timestamp_seconds,json=timestampSeconds" json:"timestamp_seconds,omitempty"`
+	Confirmations    uint64       `protobuf:"varint,6,opt,name=confirmations" json:"confirmations,omitempty"`
+	ConfirmedAtDepth bool         `protobuf:"varint,7,opt,name=confirmed_at_depth,json=confirmedAtDepth" json:"confirmed_at_depth,omitempty"`
 }
 
 func (m *TransactionExtended) Reset()                    { *m = TransactionExtended{} }
@@ -1663,6 +1929,20 @@ func (m *TransactionExtended) GetTimestampSeconds() uint64 {
 	return 0
 }
 
+func (m *TransactionExtended) GetConfirmations() uint64 {
+	if m != nil {
+		return m.Confirmations
+	}
+	return 0
+}
+
+func (m *TransactionExtended) GetConfirmedAtDepth() bool {
+	if m != nil {
+		return m.ConfirmedAtDepth
+	}
+	return false
+}
+
 type BlockExtended struct {
 	Header               *BlockHeader           `protobuf:"bytes,1,opt,name=header" json:"header,omitempty"`
 	ExtendedTransactions []*TransactionExtended `protobuf:"bytes,2,rep,name=extended_transactions,json=extendedTransactions" json:"extended_transactions,omitempty"`
@@ -1778,12 +2058,13 @@ func (m *BlockMetaDataList) GetBlockNumberHashes() []*BlockMetaData {
 }
 
 type Transaction struct {
-	MasterAddr      []byte `protobuf:"bytes,1,opt,name=master_addr,json=masterAddr,proto3" json:"master_addr,omitempty"`
-	Fee             uint64 `protobuf:"varint,2,opt,name=fee" json:"fee,omitempty"`
-	PublicKey       []byte `protobuf:"bytes,3,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
-	Signature       []byte `protobuf:"bytes,4,opt,name=signature,proto3" json:"signature,omitempty"`
-	Nonce           uint64 `protobuf:"varint,5,opt,name=nonce" json:"nonce,omitempty"`
-	TransactionHash []byte `protobuf:"bytes,6,opt,name=transaction_hash,json=transactionHash,proto3" json:"transaction_hash,omitempty"`
+	MasterAddr        []byte `protobuf:"bytes,1,opt,name=master_addr,json=masterAddr,proto3" json:"master_addr,omitempty"`
+	Fee               uint64 `protobuf:"varint,2,opt,name=fee" json:"fee,omitempty"`
+	PublicKey         []byte `protobuf:"bytes,3,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	Signature         []byte `protobuf:"bytes,4,opt,name=signature,proto3" json:"signature,omitempty"`
+	Nonce             uint64 `protobuf:"varint,5,opt,name=nonce" json:"nonce,omitempty"`
+	TransactionHash   []byte `protobuf:"bytes,6,opt,name=transaction_hash,json=transactionHash,proto3" json:"transaction_hash,omitempty"`
+	ExpiryBlockNumber uint64 `protobuf:"varint,14,opt,name=expiry_block_number,json=expiryBlockNumber" json:"expiry_block_number,omitempty"`
 	// Types that are valid to be assigned to TransactionType:
 	//	*Transaction_Transfer_
 	//	*Transaction_Coinbase
@@ -1932,6 +2213,13 @@ func (m *Transaction) GetSlave() *Transaction_Slave {
 	return nil
 }
 
+func (m *Transaction) GetExpiryBlockNumber() uint64 {
+	if m != nil {
+		return m.ExpiryBlockNumber
+	}
+	return 0
+}
+
 // XXX_OneofFuncs is for the internal use of the proto package.
 func (*Transaction) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
 	return _Transaction_OneofMarshaler, _Transaction_OneofUnmarshaler, _Transaction_OneofSizer, []interface{}{
@@ -2103,8 +2391,9 @@ func _Transaction_OneofSizer(msg proto.Message) (n int) {
 
 // ////////
 type Transaction_Transfer struct {
-	AddrsTo [][]byte `protobuf:"bytes,1,rep,name=addrs_to,json=addrsTo,proto3" json:"addrs_to,omitempty"`
-	Amounts []uint64 `protobuf:"varint,2,rep,packed,name=amounts" json:"amounts,omitempty"`
+	AddrsTo      [][]byte `protobuf:"bytes,1,rep,name=addrs_to,json=addrsTo,proto3" json:"addrs_to,omitempty"`
+	Amounts      []uint64 `protobuf:"varint,2,rep,packed,name=amounts" json:"amounts,omitempty"`
+	UnlockHeight uint64   `protobuf:"varint,3,opt,name=unlock_height,json=unlockHeight" json:"unlock_height,omitempty"`
 }
 
 func (m *Transaction_Transfer) Reset()                    { *m = Transaction_Transfer{} }
@@ -2126,6 +2415,13 @@ func (m *Transaction_Transfer) GetAmounts() []uint64 {
 	return nil
 }
 
+func (m *Transaction_Transfer) GetUnlockHeight() uint64 {
+	if m != nil {
+		return m.UnlockHeight
+	}
+	return 0
+}
+
 type Transaction_CoinBase struct {
 	AddrTo []byte `protobuf:"bytes,1,opt,name=addr_to,json=addrTo,proto3" json:"addr_to,omitempty"`
 	Amount uint64 `protobuf:"varint,2,opt,name=amount" json:"amount,omitempty"`
@@ -2624,6 +2920,7 @@ type NodeChainState struct {
 	CumulativeDifficulty []byte `protobuf:"bytes,3,opt,name=cumulative_difficulty,json=cumulativeDifficulty,proto3" json:"cumulative_difficulty,omitempty"`
 	Version              string `protobuf:"bytes,4,opt,name=version" json:"version,omitempty"`
 	Timestamp            uint64 `protobuf:"varint,5,opt,name=timestamp" json:"timestamp,omitempty"`
+	StateDigest          []byte `protobuf:"bytes,6,opt,name=state_digest,json=stateDigest,proto3" json:"state_digest,omitempty"`
 }
 
 func (m *NodeChainState) Reset()                    { *m = NodeChainState{} }
@@ -2666,6 +2963,13 @@ func (m *NodeChainState) GetTimestamp() uint64 {
 	return 0
 }
 
+func (m *NodeChainState) GetStateDigest() []byte {
+	if m != nil {
+		return m.StateDigest
+	}
+	return nil
+}
+
 type NodeHeaderHash struct {
 	BlockNumber  uint64   `protobuf:"varint,1,opt,name=block_number,json=blockNumber" json:"block_number,omitempty"`
 	Headerhashes [][]byte `protobuf:"bytes,2,rep,name=headerhashes,proto3" json:"headerhashes,omitempty"`
@@ -2770,6 +3074,38 @@ func (m *Peers) GetPeerInfoList() []*PeerInfo {
 	return nil
 }
 
+type LockedBalance struct {
+	Txhash       []byte `protobuf:"bytes,1,opt,name=txhash,proto3" json:"txhash,omitempty"`
+	Amount       uint64 `protobuf:"varint,2,opt,name=amount" json:"amount,omitempty"`
+	UnlockHeight uint64 `protobuf:"varint,3,opt,name=unlock_height,json=unlockHeight" json:"unlock_height,omitempty"`
+}
+
+func (m *LockedBalance) Reset()                    { *m = LockedBalance{} }
+func (m *LockedBalance) String() string            { return proto.CompactTextString(m) }
+func (*LockedBalance) ProtoMessage()               {}
+func (*LockedBalance) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{68} }
+
+func (m *LockedBalance) GetTxhash() []byte {
+	if m != nil {
+		return m.Txhash
+	}
+	return nil
+}
+
+func (m *LockedBalance) GetAmount() uint64 {
+	if m != nil {
+		return m.Amount
+	}
+	return 0
+}
+
+func (m *LockedBalance) GetUnlockHeight() uint64 {
+	if m != nil {
+		return m.UnlockHeight
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*Empty)(nil), "qrl.Empty")
 	proto.RegisterType((*GetNodeStateReq)(nil), "qrl.GetNodeStateReq")
@@ -2799,6 +3135,13 @@ func init() {
 	proto.RegisterType((*TokenTxnReq)(nil), "qrl.TokenTxnReq")
 	proto.RegisterType((*TransferTokenTxnReq)(nil), "qrl.TransferTokenTxnReq")
 	proto.RegisterType((*SlaveTxnReq)(nil), "qrl.SlaveTxnReq")
+	proto.RegisterType((*GetLatticeKeysByAddressReq)(nil), "qrl.GetLatticeKeysByAddressReq")
+	proto.RegisterType((*GetLatticeKeysByAddressResp)(nil), "qrl.GetLatticeKeysByAddressResp")
+	proto.RegisterType((*VerifyMessageSignatureReq)(nil), "qrl.VerifyMessageSignatureReq")
+	proto.RegisterType((*VerifyMessageSignatureResp)(nil), "qrl.VerifyMessageSignatureResp")
+	proto.RegisterType((*GetMiniBlocksReq)(nil), "qrl.GetMiniBlocksReq")
+	proto.RegisterType((*MiniBlock)(nil), "qrl.MiniBlock")
+	proto.RegisterType((*GetMiniBlocksResp)(nil), "qrl.GetMiniBlocksResp")
 	proto.RegisterType((*GetLocalAddressesReq)(nil), "qrl.GetLocalAddressesReq")
 	proto.RegisterType((*GetLocalAddressesResp)(nil), "qrl.GetLocalAddressesResp")
 	proto.RegisterType((*NodeInfo)(nil), "qrl.NodeInfo")
@@ -2840,6 +3183,7 @@ func init() {
 	proto.RegisterType((*P2PAcknowledgement)(nil), "qrl.P2PAcknowledgement")
 	proto.RegisterType((*PeerInfo)(nil), "qrl.PeerInfo")
 	proto.RegisterType((*Peers)(nil), "qrl.Peers")
+	proto.RegisterType((*LockedBalance)(nil), "qrl.LockedBalance")
 	proto.RegisterEnum("qrl.GetLatestDataReq_Filter", GetLatestDataReq_Filter_name, GetLatestDataReq_Filter_value)
 	proto.RegisterEnum("qrl.PushTransactionResp_ResponseCode", PushTransactionResp_ResponseCode_name, PushTransactionResp_ResponseCode_value)
 	proto.RegisterEnum("qrl.NodeInfo_State", NodeInfo_State_name, NodeInfo_State_value)
@@ -2870,6 +3214,9 @@ type PublicAPIClient interface {
 	GetTokenTxn(ctx context.Context, in *TokenTxnReq, opts ...grpc.CallOption) (*TransferCoinsResp, error)
 	GetTransferTokenTxn(ctx context.Context, in *TransferTokenTxnReq, opts ...grpc.CallOption) (*TransferCoinsResp, error)
 	GetSlaveTxn(ctx context.Context, in *SlaveTxnReq, opts ...grpc.CallOption) (*TransferCoinsResp, error)
+	GetLatticeKeysByAddress(ctx context.Context, in *GetLatticeKeysByAddressReq, opts ...grpc.CallOption) (*GetLatticeKeysByAddressResp, error)
+	VerifyMessageSignature(ctx context.Context, in *VerifyMessageSignatureReq, opts ...grpc.CallOption) (*VerifyMessageSignatureResp, error)
+	GetMiniBlocks(ctx context.Context, in *GetMiniBlocksReq, opts ...grpc.CallOption) (*GetMiniBlocksResp, error)
 }
 
 type publicAPIClient struct {
@@ -3006,6 +3353,33 @@ func (c *publicAPIClient) GetSlaveTxn(ctx context.Context, in *SlaveTxnReq, opts
 	return out, nil
 }
 
+func (c *publicAPIClient) GetLatticeKeysByAddress(ctx context.Context, in *GetLatticeKeysByAddressReq, opts ...grpc.CallOption) (*GetLatticeKeysByAddressResp, error) {
+	out := new(GetLatticeKeysByAddressResp)
+	err := grpc.Invoke(ctx, "/qrl.PublicAPI/GetLatticeKeysByAddress", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *publicAPIClient) VerifyMessageSignature(ctx context.Context, in *VerifyMessageSignatureReq, opts ...grpc.CallOption) (*VerifyMessageSignatureResp, error) {
+	out := new(VerifyMessageSignatureResp)
+	err := grpc.Invoke(ctx, "/qrl.PublicAPI/VerifyMessageSignature", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *publicAPIClient) GetMiniBlocks(ctx context.Context, in *GetMiniBlocksReq, opts ...grpc.CallOption) (*GetMiniBlocksResp, error) {
+	out := new(GetMiniBlocksResp)
+	err := grpc.Invoke(ctx, "/qrl.PublicAPI/GetMiniBlocks", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for PublicAPI service
 
 type PublicAPIServer interface {
@@ -3023,6 +3397,9 @@ type PublicAPIServer interface {
 	GetTokenTxn(context.Context, *TokenTxnReq) (*TransferCoinsResp, error)
 	GetTransferTokenTxn(context.Context, *TransferTokenTxnReq) (*TransferCoinsResp, error)
 	GetSlaveTxn(context.Context, *SlaveTxnReq) (*TransferCoinsResp, error)
+	GetLatticeKeysByAddress(context.Context, *GetLatticeKeysByAddressReq) (*GetLatticeKeysByAddressResp, error)
+	VerifyMessageSignature(context.Context, *VerifyMessageSignatureReq) (*VerifyMessageSignatureResp, error)
+	GetMiniBlocks(context.Context, *GetMiniBlocksReq) (*GetMiniBlocksResp, error)
 }
 
 func RegisterPublicAPIServer(s *grpc.Server, srv PublicAPIServer) {
@@ -3281,6 +3658,60 @@ func _PublicAPI_GetSlaveTxn_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PublicAPI_GetLatticeKeysByAddress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLatticeKeysByAddressReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PublicAPIServer).GetLatticeKeysByAddress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/qrl.PublicAPI/GetLatticeKeysByAddress",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PublicAPIServer).GetLatticeKeysByAddress(ctx, req.(*GetLatticeKeysByAddressReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PublicAPI_VerifyMessageSignature_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyMessageSignatureReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PublicAPIServer).VerifyMessageSignature(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/qrl.PublicAPI/VerifyMessageSignature",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PublicAPIServer).VerifyMessageSignature(ctx, req.(*VerifyMessageSignatureReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PublicAPI_GetMiniBlocks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMiniBlocksReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PublicAPIServer).GetMiniBlocks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/qrl.PublicAPI/GetMiniBlocks",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PublicAPIServer).GetMiniBlocks(ctx, req.(*GetMiniBlocksReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _PublicAPI_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "qrl.PublicAPI",
 	HandlerType: (*PublicAPIServer)(nil),
@@ -3341,6 +3772,18 @@ var _PublicAPI_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetSlaveTxn",
 			Handler:    _PublicAPI_GetSlaveTxn_Handler,
 		},
+		{
+			MethodName: "GetLatticeKeysByAddress",
+			Handler:    _PublicAPI_GetLatticeKeysByAddress_Handler,
+		},
+		{
+			MethodName: "VerifyMessageSignature",
+			Handler:    _PublicAPI_VerifyMessageSignature_Handler,
+		},
+		{
+			MethodName: "GetMiniBlocks",
+			Handler:    _PublicAPI_GetMiniBlocks_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "qrl.proto",
@@ -3379,230 +3822,261 @@ var _AdminAPI_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("qrl.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 3598 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x5a, 0xcd, 0x73, 0x1b, 0x47,
-	0x76, 0xe7, 0x80, 0x04, 0x08, 0x3c, 0x7c, 0x10, 0x68, 0x8a, 0x34, 0x0c, 0x5b, 0x96, 0x34, 0x1b,
-	0xaf, 0x65, 0xd9, 0x61, 0x52, 0xb4, 0xb5, 0x76, 0x62, 0xad, 0x77, 0xc1, 0x0f, 0x89, 0x2c, 0x51,
-	0x14, 0x6a, 0x48, 0xc5, 0x95, 0x2a, 0xa7, 0xa6, 0x86, 0x98, 0x26, 0x39, 0x01, 0x30, 0x33, 0x9a,
-	0x6e, 0x50, 0x64, 0x8e, 0x49, 0xce, 0x39, 0xa4, 0x72, 0xd9, 0xca, 0x35, 0xf9, 0x2f, 0x52, 0x95,
-	0xda, 0xfc, 0x01, 0x49, 0xe5, 0x9a, 0x73, 0x6e, 0x39, 0xe4, 0x96, 0x1c, 0x93, 0x7a, 0xaf, 0x7b,
-	0x30, 0x3d, 0xf8, 0x90, 0x28, 0xef, 0x05, 0x85, 0xfe, 0xf5, 0xeb, 0xaf, 0xf7, 0xd5, 0xef, 0xf5,
-	0x1b, 0xa8, 0xbc, 0x4e, 0x86, 0x5b, 0x71, 0x12, 0xc9, 0x88, 0x2d, 0xbf, 0x4e, 0x86, 0xf6, 0x2a,
-	0x14, 0xf7, 0x47, 0xb1, 0xbc, 0xb1, 0x5b, 0xb0, 0xf6, 0x8c, 0xcb, 0xe3, 0xc8, 0xe7, 0x27, 0xd2,
-	0x93, 0xdc, 0xe1, 0xaf, 0xed, 0xc7, 0xd0, 0xcc, 0x43, 0x22, 0x66, 0x0f, 0x60, 0x25, 0x08, 0xcf,
-	0xa3, 0xb6, 0x75, 0xdf, 0x7a, 0x58, 0xdd, 0xae, 0x6f, 0xe1, 0x74, 0x48, 0x71, 0x18, 0x9e, 0x47,
-	0x0e, 0x75, 0xd9, 0x8c, 0x86, 0x3d, 0x0f, 0xa3, 0x37, 0x61, 0x8f, 0xf3, 0x44, 0xe0, 0x54, 0x03,
-	0x68, 0x4d, 0x61, 0x22, 0x66, 0x8f, 0xa0, 0x12, 0x46, 0x3e, 0x77, 0x17, 0x4f, 0x58, 0x0e, 0xf5,
-	0x3f, 0xf6, 0x08, 0xaa, 0x03, 0x1c, 0xed, 0xc6, 0x38, 0xbc, 0x5d, 0xb8, 0xbf, 0xfc, 0xb0, 0xba,
-	0x5d, 0x21, 0x6a, 0x9c, 0xd0, 0x81, 0xc1, 0x64, 0x6e, 0x7d, 0x14, 0xfa, 0x8f, 0x1b, 0xc7, 0xf5,
-	0x7f, 0x4d, 0x7b, 0x32, 0x20, 0x11, 0xb3, 0x2f, 0x01, 0x68, 0x32, 0x57, 0x48, 0x4f, 0xb6, 0x2d,
-	0x9a, 0xb1, 0x3e, 0x99, 0x91, 0xc8, 0x2a, 0x71, 0x3a, 0xc2, 0x7e, 0x09, 0xd5, 0x67, 0x5c, 0xee,
-	0x0c, 0xa3, 0xfe, 0xc0, 0xe1, 0xaf, 0xd9, 0x26, 0x14, 0x83, 0xd0, 0xe7, 0xd7, 0xb4, 0xef, 0x95,
-	0x83, 0x25, 0x47, 0x35, 0xd9, 0x3d, 0x00, 0xef, 0x5c, 0xf2, 0xc4, 0xbd, 0xf4, 0xc4, 0x65, 0xbb,
-	0x70, 0xdf, 0x7a, 0x58, 0x3b, 0x58, 0x72, 0x2a, 0x84, 0x1d, 0x78, 0xe2, 0x72, 0x67, 0x15, 0x8a,
-	0xaf, 0xc7, 0x3c, 0xb9, 0xb1, 0x7f, 0x84, 0x5a, 0x36, 0xe1, 0x7b, 0x72, 0xe3, 0x3e, 0x14, 0xcf,
-	0x70, 0x20, 0x2d, 0x50, 0xdd, 0x06, 0xa2, 0x53, 0x53, 0xa9, 0x0e, 0xfb, 0x09, 0x6d, 0x17, 0x77,
-	0x8e, 0xfc, 0x67, 0xbf, 0x0f, 0x2c, 0x08, 0xfb, 0xc3, 0xb1, 0xcf, 0x5d, 0x19, 0x8c, 0xb8, 0xe0,
-	0x49, 0xc0, 0x05, 0xad, 0x52, 0x76, 0x5a, 0xba, 0xe7, 0x74, 0xd2, 0x61, 0xff, 0xe5, 0x32, 0x6d,
-	0x4e, 0x0f, 0x7f, 0xcf, 0xcd, 0xdd, 0x81, 0x22, 0x8f, 0xa3, 0xbe, 0x3a, 0xfd, 0x8a, 0xa3, 0x1a,
-	0xec, 0x53, 0x68, 0x8c, 0x63, 0x5c, 0xdb, 0x0d, 0xb9, 0x7c, 0x13, 0x25, 0x83, 0xf6, 0x32, 0x75,
-	0xd7, 0x15, 0x7a, 0xac, 0x40, 0xf6, 0x08, 0x5a, 0x74, 0x00, 0x77, 0xe8, 0x09, 0xe9, 0x26, 0xfc,
-	0x8d, 0x97, 0xf8, 0xed, 0x15, 0xa2, 0x5c, 0xa3, 0x8e, 0x23, 0x4f, 0x48, 0x87, 0x60, 0xf6, 0x73,
-	0x50, 0x10, 0x1d, 0xc9, 0x1d, 0x71, 0x2f, 0x6c, 0x17, 0xd5, 0x9c, 0x04, 0xe3, 0x79, 0x5e, 0x70,
-	0x2f, 0x64, 0x36, 0xd4, 0x0d, 0x3a, 0xe1, 0xb7, 0x4b, 0x44, 0x55, 0x9d, 0x50, 0x9d, 0xf8, 0xec,
-	0x4b, 0x60, 0xfd, 0x28, 0x08, 0x85, 0x2b, 0x23, 0xe9, 0x0d, 0x5d, 0x31, 0x8e, 0xe3, 0xe1, 0x4d,
-	0x7b, 0x95, 0x08, 0x9b, 0xd4, 0x73, 0x8a, 0x1d, 0x27, 0x84, 0xb3, 0x9f, 0x41, 0x5d, 0x51, 0xf3,
-	0x51, 0x20, 0x25, 0xf7, 0xdb, 0x65, 0x22, 0xac, 0x11, 0xb8, 0xaf, 0x30, 0xf6, 0x3d, 0x34, 0xb3,
-	0x65, 0x35, 0xc7, 0x2b, 0xa4, 0x65, 0xeb, 0x99, 0xbc, 0xf6, 0x3c, 0xe9, 0xf5, 0xa2, 0x20, 0x94,
-	0xfa, 0x78, 0x86, 0x10, 0x3e, 0x85, 0xf5, 0x67, 0x5c, 0x76, 0x7d, 0x3f, 0xe1, 0x42, 0x3c, 0x4d,
-	0xa2, 0x51, 0xef, 0x39, 0x8a, 0xb2, 0x01, 0x85, 0x78, 0x40, 0x32, 0xa8, 0x39, 0x85, 0x78, 0x60,
-	0xff, 0x21, 0xdc, 0x99, 0x25, 0x13, 0x31, 0x6b, 0xc3, 0xaa, 0xa7, 0x40, 0x4d, 0x9c, 0x36, 0xed,
-	0xbf, 0x29, 0x40, 0x23, 0xbf, 0x38, 0xdb, 0x84, 0x52, 0x38, 0x1e, 0x9d, 0xf1, 0x44, 0xe9, 0xb3,
-	0xa3, 0x5b, 0xec, 0x13, 0x00, 0x3f, 0x38, 0x3f, 0x0f, 0xfa, 0xe3, 0xa1, 0xbc, 0x21, 0x81, 0x56,
-	0x1c, 0x03, 0x61, 0x1f, 0x43, 0x85, 0x4e, 0x27, 0xbd, 0x51, 0xac, 0x05, 0x9a, 0x01, 0xec, 0x23,
-	0xd5, 0x4b, 0xb2, 0xd4, 0x42, 0x2c, 0x23, 0x80, 0x32, 0x64, 0xf7, 0xa0, 0xaa, 0xe4, 0x16, 0x5d,
-	0x79, 0x57, 0x17, 0x5a, 0x72, 0x80, 0xd0, 0x0b, 0x42, 0xd8, 0x5d, 0x00, 0x34, 0x22, 0x37, 0x8e,
-	0xde, 0xf0, 0x84, 0x64, 0x56, 0x70, 0x2a, 0x88, 0xf4, 0x10, 0xc0, 0xf1, 0x97, 0xdc, 0xf3, 0x53,
-	0x53, 0x5b, 0xa5, 0x33, 0x82, 0x82, 0xd0, 0xd2, 0xd8, 0x43, 0x68, 0x1a, 0x04, 0x6e, 0x9c, 0xf0,
-	0x2b, 0x92, 0x53, 0xcd, 0x69, 0x64, 0x54, 0xbd, 0x84, 0x5f, 0xd9, 0x5b, 0xc0, 0x32, 0x16, 0xa6,
-	0xee, 0xef, 0x2d, 0x0c, 0xfc, 0xde, 0x94, 0x4c, 0xe6, 0x1b, 0x3f, 0x83, 0x22, 0xba, 0x12, 0xae,
-	0x0d, 0xa4, 0x45, 0x52, 0xce, 0x51, 0xa9, 0x7e, 0xfb, 0xf7, 0xc8, 0xba, 0x5e, 0x9e, 0xfd, 0x39,
-	0xef, 0xa3, 0x77, 0x42, 0x8b, 0x21, 0x9f, 0xa0, 0xd7, 0xd1, 0x0e, 0xe2, 0x3f, 0x2d, 0xa8, 0x1b,
-	0x64, 0x22, 0x46, 0xba, 0xf3, 0x68, 0x1c, 0xfa, 0xda, 0x70, 0x55, 0x83, 0x7d, 0x0b, 0x75, 0xbd,
-	0x31, 0x57, 0x2d, 0x5f, 0x58, 0xb0, 0xfc, 0xc1, 0x92, 0x53, 0xf3, 0x8c, 0x36, 0x7b, 0x02, 0x55,
-	0x99, 0x78, 0xa1, 0xf0, 0xfa, 0x32, 0x88, 0x42, 0x92, 0x5f, 0x75, 0xbb, 0x4d, 0xe3, 0x4e, 0x33,
-	0x7c, 0xff, 0x5a, 0xf2, 0xd0, 0xe7, 0xfe, 0xc1, 0x92, 0x63, 0x92, 0xb3, 0xef, 0xa0, 0xa1, 0xf4,
-	0x9b, 0x6b, 0x02, 0x12, 0x71, 0x75, 0x9b, 0x65, 0xda, 0x6d, 0x0c, 0x55, 0x26, 0x98, 0x02, 0x3b,
-	0x65, 0x28, 0x25, 0x5c, 0x8c, 0x87, 0xd2, 0xfe, 0x77, 0x8b, 0x7c, 0xf3, 0x91, 0x27, 0xb9, 0x90,
-	0xa8, 0x91, 0xc8, 0x91, 0xaf, 0xa1, 0x74, 0x1e, 0x0c, 0xa5, 0xd6, 0xc7, 0xc6, 0xf6, 0xc7, 0x34,
-	0xe7, 0x34, 0xd9, 0xd6, 0x53, 0xa2, 0x71, 0x34, 0x2d, 0x6a, 0x71, 0x74, 0x7e, 0x2e, 0xb8, 0x24,
-	0x16, 0xd4, 0x1d, 0xdd, 0x62, 0x1d, 0x28, 0xbf, 0x1e, 0x7b, 0xa1, 0x0c, 0xe4, 0x0d, 0x1d, 0xb2,
-	0xee, 0x4c, 0xda, 0xf6, 0x09, 0x94, 0xd4, 0x2c, 0x6c, 0x15, 0x96, 0xbb, 0x47, 0x47, 0xcd, 0x25,
-	0xd6, 0x84, 0xda, 0xce, 0xd1, 0xcb, 0xdd, 0xe7, 0x07, 0xfb, 0xdd, 0xbd, 0x7d, 0xe7, 0xa4, 0x69,
-	0x21, 0x72, 0xea, 0x74, 0x8f, 0x4f, 0xba, 0xbb, 0xa7, 0x87, 0x2f, 0x8f, 0x4f, 0x9a, 0x05, 0xf6,
-	0x31, 0xb4, 0x4d, 0xc4, 0x7d, 0x75, 0xbc, 0xfb, 0xf2, 0xf8, 0xe9, 0xa1, 0xf3, 0x62, 0x7f, 0xaf,
-	0xb9, 0x8c, 0xa2, 0x6b, 0x4d, 0x6d, 0x56, 0xc4, 0xec, 0x09, 0xd4, 0x88, 0x09, 0x4a, 0xfb, 0x84,
-	0xbe, 0x72, 0xda, 0x19, 0xbb, 0x0e, 0xa8, 0x23, 0xe5, 0x91, 0x93, 0xa3, 0xc6, 0xd1, 0x06, 0xf7,
-	0xd3, 0x2b, 0x70, 0xa1, 0xb4, 0x9c, 0x1c, 0x35, 0x3b, 0x81, 0xb6, 0xd9, 0x76, 0xc7, 0x61, 0x3f,
-	0x0a, 0xcf, 0x83, 0x64, 0xc4, 0xfd, 0xf6, 0xf2, 0x3b, 0x66, 0xfa, 0xc0, 0x1c, 0xf9, 0x2a, 0x1b,
-	0x68, 0xff, 0xbd, 0x05, 0x4d, 0x1a, 0x70, 0xce, 0x93, 0x5d, 0x74, 0x7d, 0x28, 0xba, 0x7b, 0x50,
-	0x1d, 0x79, 0x02, 0xaf, 0x40, 0xd4, 0x35, 0xad, 0xd2, 0xa0, 0x20, 0xd4, 0x46, 0xf6, 0x00, 0x52,
-	0x2d, 0xe4, 0xe8, 0x6e, 0xe9, 0x20, 0x35, 0xa7, 0x3a, 0xc1, 0x4e, 0x23, 0x32, 0xbd, 0x51, 0x34,
-	0x0e, 0xa5, 0xa0, 0xcd, 0xad, 0x38, 0x69, 0x93, 0x35, 0x61, 0xf9, 0x9c, 0x73, 0xed, 0x4c, 0xf0,
-	0x2f, 0xfb, 0x00, 0x56, 0xaf, 0x47, 0x42, 0xb8, 0xf1, 0x80, 0x7c, 0x48, 0xcd, 0x29, 0x61, 0xb3,
-	0x37, 0xb0, 0x5f, 0x43, 0x6b, 0x6a, 0x73, 0x22, 0x66, 0x3f, 0xc2, 0xdd, 0x54, 0x5d, 0x5d, 0xe3,
-	0x58, 0xee, 0x38, 0x14, 0xc1, 0x45, 0xc8, 0x7d, 0x6d, 0xbb, 0x8b, 0x99, 0xf1, 0x51, 0x3a, 0xdc,
-	0xe8, 0x7c, 0xa5, 0x07, 0xdb, 0xaf, 0x80, 0xf5, 0xc6, 0xe2, 0xd2, 0xe8, 0x42, 0x8e, 0xfc, 0x0a,
-	0x98, 0xb9, 0x54, 0x6e, 0xa1, 0xe6, 0xf4, 0x42, 0x4e, 0xcb, 0xa0, 0x3d, 0x51, 0xd3, 0xfe, 0xb7,
-	0x05, 0xeb, 0x33, 0xf3, 0x8a, 0x98, 0xed, 0x01, 0xf0, 0x24, 0x89, 0x12, 0xb7, 0x1f, 0xf9, 0x5c,
-	0x5b, 0xca, 0xa7, 0x2a, 0x82, 0x99, 0xa5, 0xde, 0xc2, 0x9f, 0x28, 0x14, 0x7c, 0x37, 0xf2, 0xb9,
-	0x53, 0xa1, 0x81, 0xf8, 0x97, 0x7d, 0x01, 0x2d, 0x35, 0x8b, 0xcf, 0x45, 0x3f, 0x09, 0x62, 0xf2,
-	0x05, 0xca, 0xd5, 0x37, 0xa9, 0x63, 0x2f, 0xc3, 0x91, 0xdb, 0xf2, 0x5a, 0x79, 0xdc, 0x65, 0xc5,
-	0x6d, 0x79, 0x8d, 0x7e, 0xd4, 0x3e, 0x82, 0x9a, 0xb9, 0x00, 0xab, 0xc2, 0xea, 0xab, 0xe3, 0xe7,
-	0xc7, 0x2f, 0x7f, 0x38, 0x6e, 0x2e, 0xb1, 0x0a, 0x14, 0xf7, 0x1d, 0xe7, 0xa5, 0xd3, 0xb4, 0xd8,
-	0x06, 0xb4, 0xfe, 0xa4, 0x7b, 0x74, 0xb8, 0xd7, 0x45, 0xbb, 0x71, 0x9f, 0x76, 0x0f, 0x8f, 0xf6,
-	0xf7, 0x9a, 0x05, 0x56, 0x87, 0xca, 0xc9, 0xab, 0x9d, 0x17, 0x87, 0xa7, 0xa7, 0x64, 0x40, 0x63,
-	0xa8, 0xbf, 0xe0, 0x42, 0x78, 0x17, 0xfc, 0xf4, 0x3a, 0xbc, 0x95, 0x56, 0xb5, 0x61, 0x75, 0xa4,
-	0x46, 0xa8, 0xa8, 0xcb, 0x49, 0x9b, 0xa9, 0xca, 0x2c, 0xcf, 0x55, 0x99, 0x95, 0x9c, 0xca, 0xfc,
-	0x8f, 0x05, 0xd5, 0xd3, 0x68, 0xc0, 0xc3, 0xdb, 0xae, 0xba, 0x09, 0x25, 0x71, 0x33, 0x3a, 0x8b,
-	0x86, 0x7a, 0x51, 0xdd, 0x62, 0x0c, 0x56, 0x42, 0x6f, 0xc4, 0x35, 0x8f, 0xe8, 0x3f, 0x7a, 0xef,
-	0xe8, 0x4d, 0xc8, 0x13, 0xbd, 0xa6, 0x6a, 0xa0, 0x6f, 0xf2, 0x79, 0x3f, 0x18, 0x79, 0x43, 0xa1,
-	0xef, 0xc0, 0x49, 0x9b, 0xfd, 0x12, 0x9a, 0x41, 0x18, 0xc8, 0xc0, 0x1b, 0xba, 0x67, 0xde, 0xd0,
-	0x0b, 0xfb, 0x5c, 0xb4, 0x4b, 0x64, 0xac, 0xcc, 0x74, 0xee, 0x5d, 0xb2, 0x0d, 0x67, 0x4d, 0xd3,
-	0xee, 0x68, 0xd2, 0xf4, 0xe0, 0xab, 0x73, 0x0f, 0x5e, 0xce, 0x1d, 0xfc, 0xb7, 0x16, 0xac, 0xa7,
-	0xc6, 0xf2, 0x5e, 0x0c, 0xb8, 0x85, 0x31, 0x3f, 0x80, 0x9a, 0xc4, 0x29, 0x5d, 0x79, 0x6d, 0xe8,
-	0x4d, 0x55, 0xaa, 0x65, 0x10, 0x32, 0xed, 0x7d, 0x65, 0xae, 0xbd, 0x17, 0xe7, 0x9e, 0xa1, 0x94,
-	0x3b, 0xc3, 0x6f, 0x2c, 0xa8, 0x9e, 0x0c, 0xbd, 0xab, 0x5b, 0xab, 0xcc, 0x47, 0x50, 0x11, 0x48,
-	0xef, 0xc6, 0x03, 0xa1, 0x37, 0x5e, 0x26, 0xa0, 0x37, 0x10, 0x74, 0xb0, 0x7e, 0x1f, 0x2f, 0x55,
-	0x79, 0x13, 0x73, 0xe5, 0x87, 0xea, 0x4e, 0x55, 0x61, 0xa7, 0x08, 0xbd, 0x8f, 0x2f, 0xda, 0xa4,
-	0x20, 0xed, 0x28, 0xea, 0x7b, 0xc3, 0x6e, 0xca, 0x1a, 0x95, 0x62, 0x6d, 0xcc, 0xc1, 0x45, 0x8c,
-	0x81, 0xd5, 0x84, 0x87, 0x74, 0x51, 0xd4, 0x9c, 0x0c, 0xb0, 0xff, 0xab, 0x00, 0xe5, 0x34, 0xf2,
-	0x46, 0xe6, 0x5d, 0xf1, 0x44, 0xa0, 0xd5, 0x5a, 0x64, 0xb5, 0x69, 0x93, 0x7d, 0x9e, 0x06, 0x24,
-	0x05, 0x72, 0x0d, 0xeb, 0xb9, 0x88, 0x7d, 0xcb, 0x0c, 0x49, 0xd8, 0x67, 0xb0, 0x16, 0x8e, 0x47,
-	0x6e, 0x3f, 0x0a, 0x43, 0xae, 0x2f, 0x18, 0x75, 0x53, 0x36, 0xc2, 0xf1, 0x68, 0x37, 0x43, 0x31,
-	0xe8, 0x46, 0x42, 0x33, 0x19, 0x5b, 0x21, 0xc2, 0x7a, 0x38, 0x1e, 0x65, 0x09, 0x1e, 0x5a, 0x86,
-	0x8a, 0xec, 0xb5, 0xec, 0x74, 0x0b, 0xf9, 0xaa, 0xa2, 0x86, 0x4b, 0x1e, 0x5c, 0x5c, 0xca, 0x5c,
-	0x2c, 0x7e, 0x40, 0x50, 0x16, 0xd7, 0x53, 0x0e, 0x60, 0x44, 0x77, 0xf5, 0x49, 0x06, 0x40, 0x01,
-	0xde, 0x5d, 0x00, 0x9d, 0x4b, 0xb8, 0x81, 0x0a, 0xc1, 0x2b, 0x4e, 0x45, 0x23, 0x87, 0xbe, 0xfd,
-	0x0c, 0x8a, 0x2a, 0xcc, 0xc9, 0xb9, 0xa2, 0x1a, 0x94, 0x5f, 0x1d, 0x9f, 0xfc, 0xe9, 0xf1, 0xee,
-	0xfe, 0x5e, 0xd3, 0xc2, 0x2e, 0xfc, 0x7f, 0x78, 0xfc, 0xac, 0x59, 0x60, 0x00, 0x25, 0xdd, 0xb1,
-	0x8c, 0xff, 0x9f, 0xbe, 0x74, 0x9e, 0xef, 0xef, 0x35, 0x57, 0xec, 0x2d, 0xa8, 0x9e, 0xc8, 0x28,
-	0xe1, 0xbe, 0x3a, 0xd9, 0x3d, 0x28, 0xaa, 0x73, 0x5b, 0xd3, 0x49, 0xa8, 0xc2, 0xed, 0x4d, 0x58,
-	0xc1, 0x26, 0x46, 0xea, 0x41, 0xac, 0x65, 0x52, 0x08, 0x62, 0xfb, 0x37, 0x2b, 0x50, 0x33, 0xe3,
-	0xb1, 0xc5, 0x11, 0x26, 0xf6, 0x68, 0x8b, 0xd7, 0x59, 0x54, 0xda, 0x44, 0x2f, 0x12, 0x46, 0x88,
-	0x2b, 0x7f, 0xa6, 0x1a, 0xc8, 0xd5, 0x48, 0x0a, 0xf7, 0x2c, 0x90, 0xe7, 0x01, 0x1f, 0xfa, 0x64,
-	0x45, 0x35, 0xa7, 0x1a, 0x49, 0xb1, 0xa3, 0x21, 0x4c, 0x01, 0xcd, 0x5b, 0x08, 0xd9, 0xca, 0xd1,
-	0xe5, 0x20, 0xa1, 0x79, 0xe7, 0x1c, 0x50, 0x07, 0x7b, 0x0c, 0x25, 0xb2, 0xd0, 0xd4, 0xe3, 0xdc,
-	0x9d, 0x09, 0x27, 0xb7, 0xc8, 0x51, 0x88, 0xfd, 0x50, 0x26, 0x37, 0x8e, 0x26, 0x66, 0x8f, 0xa1,
-	0x31, 0xf4, 0xa4, 0x0c, 0xfa, 0xbc, 0xf7, 0xdc, 0x1d, 0x06, 0x42, 0xb6, 0x57, 0x69, 0x78, 0x83,
-	0x86, 0x1f, 0xa5, 0x5d, 0x4e, 0x7d, 0x42, 0x75, 0x14, 0x08, 0xc9, 0x7e, 0x84, 0x8d, 0x89, 0x29,
-	0xba, 0x86, 0xdd, 0xb5, 0xcb, 0x34, 0xfa, 0xf3, 0xd9, 0xc5, 0x4f, 0xb4, 0xa1, 0x76, 0x27, 0x06,
-	0xa9, 0x36, 0xc2, 0xc4, 0x4c, 0x07, 0x7a, 0x02, 0xe4, 0x4e, 0x1f, 0x5d, 0x0a, 0x4f, 0xda, 0x15,
-	0x95, 0x6a, 0x44, 0x52, 0xec, 0x2a, 0xa4, 0xf3, 0x47, 0xda, 0xed, 0xab, 0xc3, 0xa0, 0x61, 0x0f,
-	0xf8, 0x8d, 0x96, 0x1c, 0xfe, 0x45, 0xae, 0x5f, 0x79, 0xc3, 0x71, 0x2a, 0x0d, 0xd5, 0xf8, 0xe3,
-	0xc2, 0xb7, 0x56, 0x67, 0x1f, 0x3e, 0x58, 0xb0, 0x95, 0x77, 0x4d, 0x53, 0x37, 0xa6, 0xb1, 0x3d,
-	0xa8, 0x4c, 0x98, 0x83, 0xb6, 0xa3, 0x7d, 0xa5, 0x95, 0xde, 0xb1, 0xe4, 0x26, 0x1f, 0x40, 0xcd,
-	0x0f, 0x86, 0x81, 0xbc, 0x0c, 0xc6, 0x23, 0xf4, 0x31, 0xea, 0xce, 0xa9, 0x4e, 0xb0, 0xde, 0x80,
-	0x7d, 0x08, 0xe5, 0xc1, 0xcd, 0x19, 0x4f, 0xb0, 0x5b, 0x39, 0xda, 0x55, 0x6a, 0xf7, 0x06, 0x76,
-	0x17, 0xea, 0xb9, 0x0b, 0xe3, 0x2d, 0xea, 0xb7, 0x09, 0x25, 0xe5, 0x80, 0xf5, 0x79, 0x75, 0xcb,
-	0xfe, 0xb7, 0x02, 0x54, 0x8d, 0x48, 0x95, 0x72, 0x30, 0xcc, 0xad, 0x54, 0x8c, 0x9a, 0xba, 0x58,
-	0x84, 0x34, 0xc1, 0xc4, 0xda, 0x75, 0x76, 0x59, 0x30, 0xac, 0xfd, 0x58, 0xa5, 0x98, 0x5f, 0x40,
-	0x6b, 0x92, 0x31, 0xba, 0x82, 0xf7, 0xa3, 0xd0, 0x17, 0x5a, 0xb9, 0x9b, 0x93, 0x8e, 0x13, 0x85,
-	0x53, 0x4e, 0x97, 0x2d, 0xa8, 0x72, 0xba, 0x15, 0x9d, 0xd3, 0x4d, 0x56, 0xc5, 0x9c, 0x0e, 0x57,
-	0x56, 0xaf, 0x07, 0xae, 0x7a, 0x29, 0x51, 0x5e, 0xa8, 0xaa, 0x30, 0x3a, 0x03, 0xfa, 0x0f, 0x4d,
-	0x82, 0x6e, 0x5c, 0x39, 0xa2, 0x8a, 0x42, 0x9e, 0x72, 0xd2, 0x9a, 0x11, 0x4f, 0x06, 0x43, 0xee,
-	0x26, 0x51, 0x24, 0xd3, 0x04, 0x53, 0x41, 0x4e, 0x14, 0x49, 0x5c, 0x62, 0x14, 0x84, 0x41, 0x78,
-	0xe1, 0x2a, 0x8b, 0x2c, 0x93, 0x50, 0xab, 0x0a, 0x3b, 0x26, 0xbb, 0xbc, 0x07, 0x55, 0x7e, 0x2d,
-	0x13, 0x4f, 0x53, 0x68, 0xcd, 0x23, 0x88, 0x08, 0xec, 0xbf, 0xb2, 0x60, 0x7d, 0x4e, 0xec, 0xcf,
-	0x1e, 0x42, 0xc9, 0x60, 0x6a, 0x1a, 0x27, 0x1a, 0x94, 0x8e, 0xee, 0x67, 0x3b, 0x60, 0x5a, 0xaf,
-	0x52, 0x72, 0x9d, 0x02, 0x6e, 0x4c, 0x07, 0x97, 0xa4, 0xef, 0x4e, 0x53, 0x4e, 0x21, 0xf6, 0x5f,
-	0xa7, 0x81, 0xbc, 0x01, 0xb2, 0x5f, 0x40, 0x51, 0x4d, 0xa6, 0xfc, 0xdc, 0xfd, 0xb9, 0x93, 0x6d,
-	0xd1, 0xaf, 0x32, 0x3d, 0x45, 0xde, 0xf9, 0x16, 0x20, 0x03, 0x4d, 0x23, 0xa8, 0xbf, 0xcb, 0x08,
-	0xfe, 0x39, 0x8d, 0x42, 0xf2, 0x31, 0xf7, 0x7b, 0x30, 0xe3, 0x3e, 0x14, 0xe4, 0xb5, 0x3e, 0xfd,
-	0x6c, 0x68, 0x5d, 0x90, 0xd7, 0x78, 0xe9, 0xa3, 0x96, 0xbb, 0xe7, 0x49, 0x34, 0xd2, 0x16, 0x52,
-	0x46, 0xe0, 0x69, 0x12, 0x8d, 0x30, 0x6c, 0x13, 0xc1, 0x5f, 0xa4, 0x57, 0x3a, 0xfd, 0x9f, 0xaf,
-	0x9f, 0xc5, 0xf9, 0xfa, 0x69, 0xff, 0x87, 0x05, 0xf5, 0x5c, 0xe6, 0xfb, 0x1e, 0x7b, 0x7f, 0x01,
-	0x1b, 0xf3, 0x52, 0x93, 0x77, 0x67, 0x7a, 0x77, 0xe6, 0xa4, 0x24, 0x98, 0x2f, 0xae, 0x5d, 0xf0,
-	0x90, 0x8b, 0x40, 0xa4, 0xc1, 0xa3, 0x4e, 0xf4, 0xd6, 0x75, 0x2e, 0x4d, 0x7d, 0x3a, 0x58, 0x74,
-	0x1a, 0x17, 0xb9, 0xf6, 0x3c, 0x4e, 0xd8, 0xff, 0x68, 0x41, 0x51, 0x59, 0xce, 0xed, 0x0f, 0xf5,
-	0xf5, 0xdc, 0xac, 0x75, 0x56, 0x34, 0xf9, 0x6c, 0xf5, 0x77, 0xda, 0xbb, 0xbd, 0x07, 0x8d, 0x3c,
-	0xc5, 0x4f, 0xb9, 0x68, 0xed, 0x1f, 0xa0, 0x45, 0x07, 0x7a, 0xc1, 0xa5, 0x87, 0x29, 0x3c, 0xdd,
-	0x53, 0x3b, 0xb0, 0x6e, 0xfa, 0xb3, 0xf4, 0x16, 0xb5, 0x8c, 0xa0, 0x3c, 0x37, 0xc8, 0x69, 0x19,
-	0xae, 0x4e, 0xdd, 0xac, 0xf6, 0x3f, 0x55, 0xa0, 0x6a, 0x1c, 0xfd, 0xdd, 0x71, 0xaa, 0x8e, 0x33,
-	0x0b, 0x59, 0x9c, 0x79, 0x17, 0x20, 0x1e, 0x9f, 0x0d, 0x83, 0xbe, 0x8b, 0xb6, 0xa5, 0xb4, 0xb8,
-	0xa2, 0x90, 0xe7, 0x9c, 0x5e, 0xe5, 0x30, 0xc9, 0xf4, 0xe4, 0x38, 0xe1, 0xda, 0x3d, 0x66, 0x40,
-	0x16, 0x41, 0x14, 0xcd, 0x08, 0xe2, 0x73, 0x68, 0x4e, 0x87, 0x07, 0x3a, 0xbe, 0x5e, 0x9b, 0x0a,
-	0x0e, 0xd8, 0x37, 0x50, 0x96, 0x3a, 0x57, 0x20, 0xaf, 0x58, 0xdd, 0xfe, 0x70, 0x5a, 0x9e, 0x5b,
-	0x69, 0x32, 0x71, 0xb0, 0xe4, 0x4c, 0x88, 0x71, 0x60, 0x3f, 0x0a, 0xc2, 0x33, 0x4f, 0x28, 0x67,
-	0x39, 0x6f, 0x20, 0xa6, 0xea, 0x3b, 0x9e, 0xe0, 0x38, 0x30, 0x25, 0x66, 0x5d, 0xa8, 0x4c, 0xe2,
-	0x05, 0x72, 0xa2, 0xd5, 0xed, 0x07, 0x33, 0x23, 0xd3, 0xfb, 0x33, 0x65, 0xc3, 0xc1, 0x92, 0x93,
-	0x8d, 0x62, 0x5f, 0x67, 0xf9, 0x21, 0xcc, 0x4f, 0xf1, 0xb7, 0x74, 0xc6, 0x79, 0xb0, 0x94, 0xe5,
-	0x8e, 0x5b, 0x50, 0xa4, 0xc0, 0xa6, 0x5d, 0xa5, 0x31, 0x9b, 0xb3, 0xe7, 0xc4, 0xde, 0x83, 0x25,
-	0x47, 0x91, 0xb1, 0x67, 0xd0, 0x48, 0x4f, 0xeb, 0xaa, 0x81, 0x35, 0x1a, 0xf8, 0xc9, 0x42, 0x06,
-	0xa5, 0x13, 0xd4, 0xa5, 0x09, 0xe0, 0xc2, 0x14, 0xc8, 0xb4, 0xeb, 0x0b, 0x16, 0xa6, 0xa0, 0x03,
-	0x17, 0x26, 0xb2, 0xce, 0xaf, 0xa0, 0x9c, 0xce, 0x88, 0x31, 0x00, 0x6a, 0x12, 0xe5, 0x63, 0x2a,
-	0x75, 0x20, 0x75, 0x9f, 0x7a, 0x58, 0x29, 0xe4, 0x12, 0xad, 0xce, 0x77, 0x50, 0x4e, 0x59, 0x8f,
-	0x69, 0x0c, 0xf9, 0x48, 0x19, 0xa5, 0x01, 0x08, 0x36, 0x4f, 0xa3, 0x45, 0x71, 0x41, 0xa7, 0x07,
-	0xcd, 0x69, 0xee, 0xe7, 0x22, 0x11, 0x2b, 0x17, 0x89, 0xdc, 0x22, 0x8e, 0xe9, 0x7c, 0x09, 0xab,
-	0x5a, 0x1c, 0x74, 0xcd, 0xaa, 0xbf, 0xae, 0x11, 0x13, 0x55, 0x35, 0x86, 0x1a, 0xd9, 0xf9, 0x07,
-	0x0b, 0x8a, 0x8a, 0x6f, 0x59, 0x42, 0x6e, 0xcd, 0x4d, 0xc8, 0x0b, 0xf3, 0x12, 0xf2, 0xe5, 0x45,
-	0x09, 0xf9, 0xca, 0x2d, 0x12, 0xf2, 0xe2, 0xad, 0x13, 0xf2, 0xce, 0x05, 0xd4, 0x73, 0x62, 0x9f,
-	0x49, 0x8d, 0xad, 0xd9, 0xd4, 0xd8, 0x14, 0x66, 0x61, 0xa1, 0x30, 0xf3, 0xaf, 0x64, 0x1d, 0x4c,
-	0x7d, 0x50, 0x2d, 0xf2, 0x29, 0xae, 0xf5, 0x8e, 0x14, 0xb7, 0x30, 0x93, 0xe2, 0xee, 0xb4, 0xc0,
-	0xb4, 0x7e, 0xc4, 0xec, 0x2d, 0xa8, 0xd0, 0xe6, 0xc9, 0x1f, 0xce, 0x1e, 0x60, 0x79, 0xea, 0x00,
-	0xf6, 0x00, 0xea, 0x44, 0x8f, 0x2e, 0xd1, 0xf7, 0xa4, 0x77, 0x9b, 0x43, 0x7f, 0xa3, 0x5f, 0x2b,
-	0x27, 0x66, 0xe4, 0xea, 0x47, 0x27, 0x9e, 0x26, 0xea, 0x1b, 0x32, 0xff, 0x5a, 0xa1, 0x7d, 0xeb,
-	0xff, 0x59, 0xf0, 0xe1, 0x7e, 0xd8, 0x4f, 0x6e, 0x62, 0xc9, 0xfd, 0xfd, 0xf8, 0x92, 0x8f, 0x78,
-	0xe2, 0x0d, 0x53, 0x4d, 0xda, 0x80, 0xd2, 0x48, 0x5c, 0x60, 0xb2, 0xa8, 0x1f, 0xda, 0x47, 0xe2,
-	0xe2, 0xd0, 0x47, 0xff, 0x2a, 0xe5, 0x30, 0xf5, 0xaf, 0x52, 0x0e, 0x15, 0x92, 0xa4, 0x4f, 0x46,
-	0x52, 0x26, 0xec, 0xd7, 0xb0, 0xda, 0xbf, 0xf4, 0xc2, 0x90, 0x0f, 0xc9, 0x6d, 0x56, 0xb7, 0x7f,
-	0x4e, 0x02, 0x5f, 0xb8, 0xd6, 0xd6, 0xae, 0xa2, 0x76, 0xd2, 0x61, 0x99, 0xdb, 0x2d, 0x99, 0x6e,
-	0xb7, 0x0d, 0xab, 0xb1, 0x77, 0x33, 0x8c, 0x3c, 0x5f, 0x07, 0x98, 0x69, 0xb3, 0xf3, 0x18, 0x56,
-	0xf5, 0x1c, 0xec, 0x11, 0xb4, 0x78, 0xd8, 0x77, 0x3d, 0x2e, 0xb6, 0x1f, 0xff, 0xc2, 0x15, 0x37,
-	0x23, 0xf4, 0xfa, 0xca, 0xaf, 0xaf, 0xf1, 0xb0, 0xdf, 0x25, 0xfc, 0x84, 0x60, 0xfb, 0x0b, 0xa8,
-	0x6a, 0x2d, 0x24, 0x01, 0xbd, 0xfd, 0x1d, 0xe1, 0xef, 0x2c, 0x58, 0xdb, 0xc9, 0x32, 0xef, 0x3d,
-	0x2d, 0x9e, 0x5c, 0xc8, 0x6e, 0xcd, 0x86, 0xec, 0x9f, 0xa7, 0x95, 0x2d, 0x75, 0xc9, 0x67, 0xa5,
-	0x4e, 0x5d, 0xc4, 0x3a, 0x98, 0xc0, 0xec, 0x2b, 0xd8, 0xe8, 0x8f, 0x47, 0xe3, 0xa1, 0x27, 0x83,
-	0x2b, 0xee, 0x1a, 0xb5, 0x24, 0x65, 0x73, 0x77, 0xb2, 0xce, 0xbd, 0x49, 0x9f, 0xfd, 0xaf, 0x69,
-	0x14, 0x95, 0x5e, 0xa3, 0xd9, 0x8a, 0xc6, 0x0c, 0x96, 0xb1, 0x62, 0x36, 0x78, 0xf1, 0x8a, 0x85,
-	0xc5, 0x2b, 0x62, 0x72, 0xdc, 0xbf, 0x0c, 0x86, 0xbe, 0x71, 0x22, 0xfd, 0xe6, 0x53, 0x73, 0x5a,
-	0xd4, 0x73, 0x60, 0x74, 0xb0, 0x2d, 0x58, 0xa7, 0xb7, 0x89, 0xe3, 0x3c, 0xbd, 0xca, 0xba, 0x5b,
-	0xd8, 0x75, 0x6c, 0xd2, 0xdb, 0x7f, 0x06, 0x6c, 0x27, 0xe3, 0xdf, 0x0b, 0x2f, 0x8e, 0x83, 0xf0,
-	0x82, 0x7d, 0x02, 0x60, 0x30, 0xd0, 0x32, 0x0b, 0x58, 0xc4, 0xbb, 0xcf, 0x60, 0x0d, 0x13, 0x9c,
-	0x59, 0x2e, 0x37, 0x10, 0xce, 0x16, 0xb0, 0xff, 0xd6, 0x82, 0x2a, 0x65, 0xc5, 0x47, 0x11, 0x05,
-	0x5d, 0x6f, 0x15, 0xfa, 0x8c, 0xfd, 0x15, 0x66, 0x6c, 0xd6, 0x48, 0x40, 0x15, 0x0b, 0xd2, 0x04,
-	0xf4, 0x11, 0xb4, 0x54, 0x7d, 0x14, 0x6f, 0xe6, 0xb4, 0x48, 0xaa, 0xab, 0xb3, 0xd4, 0x81, 0x57,
-	0x88, 0xaa, 0x91, 0xda, 0x5f, 0x41, 0x8d, 0xf6, 0xa4, 0xea, 0x57, 0x82, 0xfd, 0x0c, 0xea, 0xf4,
-	0xd4, 0xe4, 0x0e, 0xa3, 0xac, 0xfc, 0x51, 0x73, 0x6a, 0x22, 0xdb, 0xb8, 0xb0, 0xd7, 0xa0, 0x7e,
-	0xe4, 0xbc, 0xa2, 0x71, 0xbb, 0x5e, 0xff, 0x92, 0xdb, 0x57, 0x50, 0x4e, 0xab, 0xf1, 0x78, 0x2d,
-	0xc5, 0x9c, 0x27, 0xae, 0x7e, 0x54, 0xa9, 0x39, 0x25, 0x6c, 0x1e, 0xc6, 0xe8, 0xdc, 0xe3, 0x28,
-	0x49, 0xab, 0x3e, 0xf4, 0x1f, 0x5d, 0x35, 0x55, 0xac, 0xfb, 0x97, 0x1e, 0x6e, 0x95, 0x9e, 0xc1,
-	0x54, 0x81, 0x2b, 0x7b, 0x06, 0xdb, 0xc5, 0x3e, 0xf5, 0x0c, 0xd6, 0x08, 0x73, 0x6d, 0xfb, 0xb7,
-	0x16, 0x34, 0xf2, 0x24, 0xb7, 0x31, 0x8c, 0xa9, 0x9a, 0x64, 0x61, 0xa6, 0x26, 0xf9, 0x53, 0xcc,
-	0xc1, 0x7c, 0xe0, 0x5b, 0xc9, 0x3f, 0xf0, 0xe5, 0xca, 0xaf, 0xc5, 0xa9, 0xf2, 0xab, 0xfd, 0x83,
-	0x3a, 0xc2, 0x41, 0xb6, 0xfc, 0x2d, 0x8e, 0x60, 0x43, 0x2d, 0xa7, 0xd3, 0x4a, 0x3b, 0x72, 0x98,
-	0xfd, 0x4b, 0x60, 0xbd, 0xed, 0x5e, 0xb7, 0x3f, 0x08, 0xa3, 0x37, 0x43, 0xee, 0x5f, 0xf0, 0x11,
-	0x0f, 0x25, 0xaa, 0xeb, 0xd9, 0x8d, 0xe4, 0xc2, 0x8d, 0x93, 0x08, 0x6f, 0x10, 0x5d, 0xe3, 0xa8,
-	0x3b, 0x0d, 0x82, 0x7b, 0x29, 0x6a, 0xff, 0x8b, 0xa5, 0x84, 0x4a, 0xaf, 0x97, 0xef, 0x25, 0x54,
-	0x74, 0x03, 0xe8, 0x12, 0x7d, 0x37, 0x5f, 0x75, 0xae, 0x3b, 0x6b, 0x0a, 0x3f, 0x9d, 0xd4, 0x9e,
-	0xef, 0x43, 0xb5, 0x9f, 0x70, 0x3f, 0x38, 0xc3, 0xa0, 0xe2, 0x46, 0xbf, 0x51, 0x9a, 0x10, 0x7b,
-	0x02, 0x1d, 0x32, 0x62, 0xe3, 0xcd, 0xd3, 0x35, 0xb9, 0x89, 0x97, 0x61, 0x1b, 0x29, 0x8c, 0xe7,
-	0xcf, 0xc9, 0xfc, 0xf6, 0x13, 0x28, 0xaa, 0xe7, 0xc0, 0xaf, 0xa0, 0xa1, 0x0e, 0x10, 0x9e, 0x47,
-	0xea, 0xc5, 0x6b, 0xfa, 0x53, 0x12, 0xfa, 0x3e, 0xa2, 0x16, 0xeb, 0x7f, 0xe8, 0x96, 0xb7, 0xff,
-	0xb7, 0x04, 0x15, 0x15, 0x2a, 0x75, 0x7b, 0x87, 0xec, 0x3b, 0xaa, 0x07, 0x4f, 0x3e, 0xb4, 0x61,
-	0x77, 0xd2, 0x6a, 0xa7, 0xf9, 0x39, 0x4e, 0x67, 0x63, 0x0e, 0x2a, 0x62, 0xf6, 0x3d, 0x55, 0x89,
-	0x8d, 0x97, 0xd7, 0x09, 0x5d, 0xee, 0x13, 0x9c, 0xce, 0xe6, 0x3c, 0x58, 0xc4, 0x7a, 0xf1, 0xc9,
-	0xa7, 0x31, 0xd9, 0xe2, 0xe6, 0x07, 0x34, 0xd9, 0xe2, 0xf9, 0x6f, 0x68, 0xfe, 0x00, 0xca, 0xe9,
-	0x77, 0x22, 0xac, 0x99, 0x92, 0xa4, 0x5f, 0x9d, 0x74, 0x5a, 0x53, 0x08, 0x95, 0xac, 0xd6, 0xa6,
-	0x4a, 0xe7, 0xec, 0x83, 0x94, 0x6a, 0xaa, 0x00, 0xdf, 0x69, 0xcf, 0xef, 0x10, 0x31, 0xdb, 0x86,
-	0xca, 0xa4, 0x32, 0xce, 0x26, 0xab, 0x4c, 0x0a, 0xea, 0x1d, 0x36, 0x0d, 0x4d, 0xf8, 0x94, 0x95,
-	0x64, 0x33, 0x3e, 0xe5, 0x6a, 0xca, 0x19, 0x9f, 0xa6, 0xaa, 0xb7, 0x7b, 0xb0, 0x36, 0x55, 0x55,
-	0xd3, 0x3b, 0x9f, 0xad, 0xf8, 0xe9, 0x9d, 0xcf, 0x2b, 0xd9, 0x7d, 0x9f, 0x85, 0x80, 0x54, 0x94,
-	0x64, 0xc6, 0x1b, 0x8d, 0x51, 0x45, 0xed, 0x6c, 0xce, 0x83, 0x45, 0xcc, 0x9e, 0x51, 0xb1, 0x3c,
-	0xf7, 0xb5, 0x07, 0x9b, 0xe6, 0xd3, 0xe4, 0x5b, 0x91, 0xce, 0x87, 0x0b, 0x7a, 0x48, 0xec, 0xc8,
-	0x8e, 0xac, 0xc8, 0xc6, 0x14, 0xcf, 0x72, 0x55, 0xb7, 0x85, 0xbb, 0xf8, 0x86, 0xbe, 0x2e, 0x4a,
-	0x0b, 0x45, 0x5a, 0xf2, 0x46, 0xdd, 0xe8, 0x2d, 0xdb, 0x5f, 0xc7, 0x81, 0x53, 0x95, 0x26, 0xd6,
-	0xce, 0x91, 0xdf, 0x66, 0x22, 0xb5, 0x83, 0xb4, 0xdc, 0xa3, 0x77, 0x60, 0x54, 0x7f, 0x16, 0x0d,
-	0xdc, 0x06, 0x28, 0x77, 0xfd, 0x51, 0x10, 0x76, 0x7b, 0x87, 0x67, 0x25, 0xfa, 0x10, 0xee, 0xab,
-	0xff, 0x0f, 0x00, 0x00, 0xff, 0xff, 0x7b, 0x9c, 0x8a, 0x24, 0x15, 0x27, 0x00, 0x00,
+	// 4087 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x5a, 0xcd, 0x6f, 0x1b, 0x49,
+	0x76, 0x77, 0x53, 0xa2, 0x44, 0x3e, 0x92, 0x12, 0x55, 0xb2, 0x64, 0x9a, 0x1e, 0xaf, 0xe5, 0xde,
+	0x9d, 0x19, 0xcf, 0x47, 0x94, 0x40, 0x33, 0x9e, 0x99, 0x64, 0x3c, 0x93, 0xd5, 0x97, 0x2d, 0xc1,
+	0x92, 0x2c, 0xb4, 0xe4, 0x1d, 0x2c, 0x30, 0x41, 0xa3, 0xc5, 0x2e, 0x49, 0x1d, 0x92, 0xdd, 0xed,
+	0xae, 0xa2, 0x2c, 0x06, 0xc8, 0x29, 0x39, 0x07, 0xc8, 0xc7, 0x25, 0xd8, 0x6b, 0xf2, 0x87, 0x24,
+	0x7f, 0x40, 0x82, 0xbd, 0xe4, 0x90, 0x73, 0xee, 0xc9, 0x29, 0x97, 0x1c, 0x36, 0x78, 0xaf, 0xaa,
+	0xbb, 0xab, 0xf9, 0x21, 0xcb, 0x83, 0xbd, 0x10, 0xac, 0x5f, 0xbd, 0xea, 0xaa, 0x7a, 0xf5, 0xbe,
+	0xab, 0xa0, 0xfa, 0x26, 0xe9, 0xad, 0xc7, 0x49, 0x24, 0x23, 0x36, 0xf3, 0x26, 0xe9, 0xd9, 0xf3,
+	0x50, 0xde, 0xed, 0xc7, 0x72, 0x68, 0x2f, 0xc1, 0xe2, 0x0b, 0x2e, 0x8f, 0x22, 0x9f, 0x9f, 0x48,
+	0x4f, 0x72, 0x87, 0xbf, 0xb1, 0x9f, 0x42, 0xb3, 0x08, 0x89, 0x98, 0x3d, 0x86, 0xd9, 0x20, 0x3c,
+	0x8f, 0x5a, 0xd6, 0x9a, 0xf5, 0xa4, 0xb6, 0xd1, 0x58, 0xc7, 0xcf, 0x21, 0xc5, 0x7e, 0x78, 0x1e,
+	0x39, 0xd4, 0x65, 0x33, 0x1a, 0xf6, 0x32, 0x8c, 0xde, 0x86, 0xc7, 0x9c, 0x27, 0x02, 0x3f, 0xd5,
+	0x85, 0xa5, 0x11, 0x4c, 0xc4, 0xec, 0x53, 0xa8, 0x86, 0x91, 0xcf, 0xdd, 0xe9, 0x1f, 0xac, 0x84,
+	0xfa, 0x1f, 0xfb, 0x14, 0x6a, 0x5d, 0x1c, 0xed, 0xc6, 0x38, 0xbc, 0x55, 0x5a, 0x9b, 0x79, 0x52,
+	0xdb, 0xa8, 0x12, 0x35, 0x7e, 0xd0, 0x81, 0x6e, 0xf6, 0x6d, 0xbd, 0x15, 0xfa, 0x8f, 0x0b, 0xc7,
+	0xf9, 0x7f, 0x09, 0xcd, 0x22, 0x24, 0x62, 0xf6, 0x39, 0x00, 0x7d, 0xcc, 0x15, 0xd2, 0x93, 0x2d,
+	0x6b, 0x6d, 0x26, 0x9b, 0x1f, 0xe9, 0x88, 0xac, 0x1a, 0xa7, 0x23, 0xec, 0x57, 0x50, 0x7b, 0xc1,
+	0xe5, 0x56, 0x2f, 0xea, 0x74, 0x1d, 0xfe, 0x86, 0xad, 0x42, 0x39, 0x08, 0x7d, 0x7e, 0x4d, 0xeb,
+	0x9e, 0xdd, 0xbb, 0xe3, 0xa8, 0x26, 0x7b, 0x04, 0xe0, 0x9d, 0x4b, 0x9e, 0xb8, 0x97, 0x9e, 0xb8,
+	0x6c, 0x95, 0xd6, 0xac, 0x27, 0xf5, 0xbd, 0x3b, 0x4e, 0x95, 0xb0, 0x3d, 0x4f, 0x5c, 0x6e, 0xcd,
+	0x43, 0xf9, 0xcd, 0x80, 0x27, 0x43, 0xfb, 0x47, 0xa8, 0xe7, 0x1f, 0x7c, 0x4f, 0x6e, 0xac, 0x41,
+	0xf9, 0x0c, 0x07, 0xd2, 0x04, 0xb5, 0x0d, 0x20, 0x3a, 0xf5, 0x29, 0xd5, 0x61, 0x3f, 0xa3, 0xe5,
+	0xe2, 0xca, 0x91, 0xff, 0xec, 0x0f, 0x80, 0x05, 0x61, 0xa7, 0x37, 0xf0, 0xb9, 0x2b, 0x83, 0x3e,
+	0x17, 0x3c, 0x09, 0xb8, 0xa0, 0x59, 0x2a, 0xce, 0x92, 0xee, 0x39, 0xcd, 0x3a, 0xec, 0xdf, 0xcd,
+	0x40, 0x3d, 0x1f, 0xfe, 0x9e, 0x8b, 0xbb, 0x0b, 0x65, 0x1e, 0x47, 0x1d, 0xb5, 0xfb, 0x59, 0x47,
+	0x35, 0xd8, 0x87, 0xb0, 0x30, 0x88, 0x71, 0x6e, 0x37, 0xe4, 0xf2, 0x6d, 0x94, 0x74, 0x5b, 0x33,
+	0xd4, 0xdd, 0x50, 0xe8, 0x91, 0x02, 0xd9, 0xa7, 0xb0, 0x44, 0x1b, 0x70, 0x7b, 0x9e, 0x90, 0x6e,
+	0xc2, 0xdf, 0x7a, 0x89, 0xdf, 0x9a, 0x25, 0xca, 0x45, 0xea, 0x38, 0xf0, 0x84, 0x74, 0x08, 0x66,
+	0x1f, 0x81, 0x82, 0x68, 0x4b, 0x6e, 0x9f, 0x7b, 0x61, 0xab, 0xac, 0xbe, 0x49, 0x30, 0xee, 0xe7,
+	0x90, 0x7b, 0x21, 0xb3, 0xa1, 0x61, 0xd0, 0x09, 0xbf, 0x35, 0x47, 0x54, 0xb5, 0x8c, 0xea, 0xc4,
+	0x67, 0x9f, 0x03, 0xeb, 0x44, 0x41, 0x28, 0x5c, 0x19, 0x49, 0xaf, 0xe7, 0x8a, 0x41, 0x1c, 0xf7,
+	0x86, 0xad, 0x79, 0x22, 0x6c, 0x52, 0xcf, 0x29, 0x76, 0x9c, 0x10, 0xce, 0x7e, 0x0e, 0x0d, 0x45,
+	0xcd, 0xfb, 0x81, 0x94, 0xdc, 0x6f, 0x55, 0x88, 0xb0, 0x4e, 0xe0, 0xae, 0xc2, 0xd8, 0xf7, 0xd0,
+	0xcc, 0xa7, 0xd5, 0x1c, 0xaf, 0x92, 0x94, 0x2d, 0xe7, 0xe7, 0xb5, 0xe3, 0x49, 0xef, 0x38, 0x0a,
+	0x42, 0xe9, 0x2c, 0x66, 0xcb, 0x51, 0xb4, 0xec, 0x93, 0x74, 0xbc, 0x1f, 0x9c, 0x9f, 0x07, 0x9d,
+	0x41, 0x4f, 0x0e, 0x5b, 0xb0, 0x66, 0x3d, 0xa9, 0x6a, 0xd2, 0x9d, 0x0c, 0x46, 0x52, 0xcd, 0x55,
+	0x92, 0xbb, 0xc4, 0x93, 0xbc, 0x55, 0x5b, 0xb3, 0x9e, 0x58, 0xce, 0xa2, 0xc6, 0xf7, 0x34, 0x8c,
+	0xe7, 0x40, 0x07, 0xe2, 0xc6, 0x49, 0x74, 0x91, 0x70, 0x21, 0x5a, 0xf5, 0x35, 0xeb, 0x49, 0xc9,
+	0x69, 0x10, 0x7a, 0xac, 0x41, 0xfb, 0x43, 0x58, 0x7e, 0xc1, 0xe5, 0xa6, 0xef, 0x63, 0xeb, 0x79,
+	0x12, 0xf5, 0x8f, 0x5f, 0xa2, 0x1c, 0x2d, 0x40, 0x29, 0xee, 0x92, 0x00, 0xd4, 0x9d, 0x52, 0xdc,
+	0xb5, 0xff, 0x08, 0xee, 0x8e, 0x93, 0x89, 0x98, 0xb5, 0x60, 0xde, 0x53, 0xa0, 0x26, 0x4e, 0x9b,
+	0xf6, 0xdf, 0x94, 0x60, 0xa1, 0xb8, 0x73, 0xb6, 0x0a, 0x73, 0xe1, 0xa0, 0x7f, 0xc6, 0x13, 0xa5,
+	0x4c, 0x8e, 0x6e, 0xb1, 0x9f, 0x01, 0x18, 0x5b, 0x2f, 0xd1, 0xd6, 0x0d, 0x84, 0x7d, 0x00, 0x55,
+	0x62, 0xad, 0xf4, 0xfa, 0xb1, 0x96, 0xa6, 0x1c, 0x60, 0x0f, 0x54, 0x2f, 0x09, 0x92, 0x96, 0xa0,
+	0x0a, 0x02, 0x28, 0x40, 0xec, 0x11, 0xd4, 0x94, 0xd0, 0x44, 0x57, 0xde, 0xd5, 0x85, 0x16, 0x1b,
+	0x40, 0xe8, 0x90, 0x10, 0xf6, 0x10, 0x00, 0x39, 0xe9, 0xc6, 0xd1, 0x5b, 0x9e, 0x90, 0xc0, 0x94,
+	0x9c, 0x2a, 0x22, 0xc7, 0x08, 0xe0, 0xf8, 0x4b, 0xee, 0xf9, 0xa9, 0x9e, 0xcf, 0xd3, 0x1e, 0x41,
+	0x41, 0xc8, 0x6a, 0xf6, 0x04, 0x9a, 0x06, 0x81, 0x1b, 0x27, 0xfc, 0x8a, 0x84, 0xa4, 0xee, 0x2c,
+	0xe4, 0x54, 0xc7, 0x09, 0xbf, 0xb2, 0xd7, 0x81, 0xe5, 0x2c, 0x4c, 0x6d, 0xef, 0x0d, 0x0c, 0xfc,
+	0x1e, 0x96, 0xc7, 0xe8, 0x45, 0xcc, 0x3e, 0x86, 0xb2, 0xc0, 0x86, 0xd6, 0xce, 0x25, 0x12, 0xb1,
+	0x02, 0x95, 0xea, 0xb7, 0x7f, 0x41, 0xaa, 0xfd, 0xea, 0xec, 0xcf, 0x79, 0x07, 0x4d, 0x23, 0xbb,
+	0xab, 0x0d, 0x92, 0x9e, 0x47, 0x35, 0xec, 0xff, 0xb2, 0xa0, 0x61, 0x90, 0x89, 0x18, 0xe9, 0xce,
+	0xa3, 0x41, 0xe8, 0x6b, 0xab, 0xa1, 0x1a, 0xec, 0x1b, 0x68, 0xe8, 0x85, 0xb9, 0x6a, 0xfa, 0xd2,
+	0x94, 0xe9, 0xf7, 0xee, 0x38, 0x75, 0xcf, 0x68, 0xb3, 0x67, 0x50, 0x93, 0x89, 0x17, 0x0a, 0xaf,
+	0x23, 0x83, 0x28, 0xa4, 0xf3, 0xab, 0x6d, 0xb4, 0x68, 0xdc, 0x69, 0x8e, 0xef, 0x5e, 0x4b, 0x1e,
+	0xfa, 0xdc, 0xdf, 0xbb, 0xe3, 0x98, 0xe4, 0xec, 0x5b, 0x58, 0x50, 0xca, 0xc1, 0x35, 0x01, 0x1d,
+	0x71, 0x6d, 0x83, 0xe5, 0xaa, 0x65, 0x0c, 0x6d, 0x9c, 0x99, 0xc0, 0x56, 0x05, 0xe6, 0x12, 0x2e,
+	0x06, 0x3d, 0x69, 0xff, 0xd6, 0x22, 0xc7, 0x70, 0xe0, 0x49, 0x2e, 0x24, 0x4a, 0x24, 0x72, 0xe4,
+	0x4b, 0x98, 0x3b, 0x0f, 0x7a, 0x52, 0xcb, 0xe3, 0xc2, 0xc6, 0x07, 0xf4, 0xcd, 0x51, 0xb2, 0xf5,
+	0xe7, 0x44, 0xe3, 0x68, 0x5a, 0x94, 0xe2, 0xe8, 0xfc, 0x5c, 0x70, 0x49, 0x2c, 0x68, 0x38, 0xba,
+	0xc5, 0xda, 0x50, 0x79, 0x33, 0xf0, 0x42, 0x19, 0xc8, 0x21, 0x6d, 0xb2, 0xe1, 0x64, 0x6d, 0xfb,
+	0x04, 0xe6, 0xd4, 0x57, 0xd8, 0x3c, 0xcc, 0x6c, 0x1e, 0x1c, 0x34, 0xef, 0xb0, 0x26, 0xd4, 0xb7,
+	0x0e, 0x5e, 0x6d, 0xbf, 0xdc, 0xdb, 0xdd, 0xdc, 0xd9, 0x75, 0x4e, 0x9a, 0x16, 0x22, 0xa7, 0xce,
+	0xe6, 0xd1, 0xc9, 0xe6, 0xf6, 0xe9, 0xfe, 0xab, 0xa3, 0x93, 0x66, 0x89, 0x7d, 0x00, 0x2d, 0x13,
+	0x71, 0x5f, 0x1f, 0x6d, 0xbf, 0x3a, 0x7a, 0xbe, 0xef, 0x1c, 0xee, 0xee, 0x34, 0x67, 0xf0, 0xe8,
+	0x96, 0x46, 0x16, 0x2b, 0x62, 0xf6, 0x0c, 0xea, 0xc4, 0x04, 0x25, 0x7d, 0x42, 0xfb, 0xbb, 0x56,
+	0xce, 0xae, 0x3d, 0xea, 0x48, 0x79, 0xe4, 0x14, 0xa8, 0x71, 0xb4, 0xc1, 0xfd, 0xd4, 0xff, 0x4e,
+	0x3d, 0x2d, 0xa7, 0x40, 0xcd, 0x4e, 0xa0, 0x65, 0xb6, 0xdd, 0x41, 0xd8, 0x89, 0xc2, 0xf3, 0x20,
+	0xe9, 0x73, 0xbf, 0x35, 0xf3, 0x8e, 0x2f, 0xdd, 0x33, 0x47, 0xbe, 0xce, 0x07, 0xda, 0xbf, 0xb1,
+	0xa0, 0x49, 0x03, 0xce, 0x79, 0xb2, 0x8d, 0x76, 0x17, 0x8f, 0xee, 0x11, 0xd4, 0xfa, 0x9e, 0x40,
+	0xff, 0x8b, 0xb2, 0xa6, 0x45, 0x1a, 0x14, 0x84, 0xd2, 0xc8, 0x1e, 0x43, 0x2a, 0x85, 0x1c, 0x6d,
+	0x3d, 0x6d, 0xa4, 0xee, 0xd4, 0x32, 0xec, 0x34, 0x22, 0xd5, 0xeb, 0x47, 0x83, 0x50, 0x0a, 0x5a,
+	0xdc, 0xac, 0x93, 0x36, 0x59, 0x13, 0x66, 0xce, 0x39, 0xd7, 0xc6, 0x04, 0xff, 0xb2, 0x7b, 0x30,
+	0x7f, 0xdd, 0x17, 0xc2, 0x8d, 0xbb, 0x64, 0x43, 0xea, 0xce, 0x1c, 0x36, 0x8f, 0xbb, 0xf6, 0x1b,
+	0x58, 0x1a, 0x59, 0x9c, 0x88, 0xd9, 0x8f, 0xf0, 0x30, 0x15, 0x57, 0xd7, 0xd8, 0x96, 0x3b, 0x08,
+	0x45, 0x70, 0x11, 0x72, 0x5f, 0xeb, 0xee, 0x74, 0x66, 0x3c, 0x48, 0x87, 0x1b, 0x9d, 0xaf, 0xf5,
+	0x60, 0xfb, 0x35, 0xb0, 0xe3, 0x81, 0xb8, 0x34, 0xba, 0x90, 0x23, 0x7f, 0x0a, 0xcc, 0x9c, 0xaa,
+	0x30, 0x51, 0x73, 0x74, 0x22, 0x67, 0xc9, 0xa0, 0x3d, 0x51, 0x9f, 0xfd, 0x6f, 0x0b, 0x96, 0xc7,
+	0xbe, 0x2b, 0x62, 0xb6, 0x03, 0xc0, 0x93, 0x24, 0x4a, 0xdc, 0x4e, 0xe4, 0x73, 0xad, 0x29, 0x1f,
+	0xaa, 0xf0, 0x69, 0x9c, 0x7a, 0x1d, 0x7f, 0xa2, 0x50, 0xf0, 0xed, 0xc8, 0xe7, 0x4e, 0x95, 0x06,
+	0xe2, 0x5f, 0xf6, 0x19, 0x2c, 0xa9, 0xaf, 0xf8, 0x5c, 0x74, 0x92, 0x20, 0x26, 0x5b, 0xa0, 0x4c,
+	0x7d, 0x93, 0x3a, 0x76, 0x72, 0x1c, 0xb9, 0x2d, 0xaf, 0x95, 0xc5, 0x9d, 0x51, 0xdc, 0x96, 0xd7,
+	0x68, 0x47, 0xed, 0x03, 0xa8, 0x9b, 0x13, 0xb0, 0x1a, 0xcc, 0xbf, 0x3e, 0x7a, 0x79, 0xf4, 0xea,
+	0x87, 0xa3, 0xe6, 0x1d, 0x56, 0x85, 0xf2, 0xae, 0xe3, 0xbc, 0x72, 0x9a, 0x16, 0x5b, 0x81, 0xa5,
+	0x5f, 0x6d, 0x1e, 0xec, 0xef, 0x6c, 0xa2, 0xde, 0xb8, 0xcf, 0x37, 0xf7, 0x0f, 0x76, 0x77, 0x9a,
+	0x25, 0xd6, 0x80, 0xea, 0xc9, 0xeb, 0xad, 0xc3, 0xfd, 0xd3, 0x53, 0x52, 0xa0, 0x01, 0x34, 0x0e,
+	0xb9, 0x10, 0xde, 0x05, 0x3f, 0xbd, 0x0e, 0x6f, 0x25, 0x55, 0x2d, 0x98, 0xef, 0xab, 0x11, 0x2a,
+	0xe4, 0x73, 0xd2, 0x66, 0x2a, 0x32, 0x33, 0x13, 0x45, 0x66, 0xb6, 0x20, 0x32, 0xff, 0x6b, 0x41,
+	0xed, 0x34, 0xea, 0xf2, 0xf0, 0xb6, 0xb3, 0xae, 0xc2, 0x9c, 0x18, 0xf6, 0xcf, 0xa2, 0x9e, 0x9e,
+	0x54, 0xb7, 0x18, 0x83, 0xd9, 0xd0, 0xeb, 0x73, 0xcd, 0x23, 0xfa, 0x8f, 0xd6, 0x3b, 0x7a, 0x1b,
+	0xf2, 0x44, 0xcf, 0xa9, 0x1a, 0x68, 0x9b, 0x7c, 0xde, 0x09, 0xfa, 0x5e, 0x4f, 0x68, 0x1f, 0x98,
+	0xb5, 0xd9, 0x77, 0xd0, 0x0c, 0xc2, 0x40, 0x06, 0x5e, 0xcf, 0x3d, 0xf3, 0x7a, 0x5e, 0xd8, 0xe1,
+	0xa2, 0x35, 0xb7, 0x36, 0x93, 0xd9, 0x58, 0x6d, 0xdc, 0x37, 0x49, 0x37, 0x9c, 0x45, 0x4d, 0xbb,
+	0xa5, 0x49, 0xd3, 0x8d, 0xcf, 0x4f, 0xdc, 0x78, 0xa5, 0xb0, 0xf1, 0x7f, 0xb1, 0x60, 0x39, 0x55,
+	0x96, 0xf7, 0x62, 0xc0, 0x2d, 0x94, 0xf9, 0x31, 0xd4, 0x25, 0x7e, 0xd2, 0x95, 0xd7, 0x86, 0xdc,
+	0xd4, 0xa4, 0x9a, 0x06, 0x21, 0x53, 0xdf, 0x67, 0x27, 0xea, 0x7b, 0x79, 0xe2, 0x1e, 0xe6, 0x0a,
+	0x7b, 0xf8, 0x47, 0x0b, 0x6a, 0x27, 0x3d, 0xef, 0xea, 0xd6, 0x22, 0xf3, 0x00, 0xaa, 0x02, 0xe9,
+	0xdd, 0xb8, 0x2b, 0xf4, 0xc2, 0x2b, 0x04, 0x1c, 0x77, 0x05, 0x6d, 0xac, 0xd3, 0x41, 0xa7, 0x2a,
+	0x87, 0x31, 0x57, 0x76, 0xa8, 0xe1, 0xd4, 0x14, 0x76, 0x8a, 0xd0, 0xfb, 0xd8, 0xa2, 0x55, 0x0a,
+	0xd2, 0x0e, 0xa2, 0x8e, 0xd7, 0xdb, 0x4c, 0x59, 0xa3, 0xf2, 0xbb, 0x95, 0x09, 0xb8, 0x88, 0x31,
+	0xb0, 0xca, 0x78, 0x48, 0x8e, 0xa2, 0xee, 0xe4, 0x80, 0xfd, 0xdb, 0x19, 0xa8, 0xa4, 0x61, 0x3f,
+	0x32, 0xef, 0x8a, 0x27, 0x02, 0xb5, 0xd6, 0x22, 0xad, 0x4d, 0x9b, 0xec, 0x93, 0x34, 0x20, 0x29,
+	0x91, 0x69, 0x58, 0x2e, 0xa4, 0x0b, 0xeb, 0x66, 0x48, 0xc2, 0x3e, 0x86, 0xc5, 0x70, 0xd0, 0x77,
+	0x3b, 0x51, 0x18, 0x72, 0xed, 0x60, 0x94, 0xa7, 0x5c, 0x08, 0x07, 0xfd, 0xed, 0x1c, 0x65, 0x1f,
+	0x29, 0x42, 0x33, 0x13, 0x9c, 0x25, 0xc2, 0x46, 0x38, 0xe8, 0xe7, 0xd9, 0x25, 0x6a, 0x86, 0x4a,
+	0x2b, 0xf4, 0xd9, 0xe9, 0x16, 0xf2, 0x55, 0x45, 0x0d, 0x97, 0x3c, 0xb8, 0xb8, 0x94, 0x85, 0x44,
+	0x60, 0x8f, 0xa0, 0x3c, 0xa9, 0xa0, 0x04, 0xc4, 0x88, 0xee, 0x1a, 0x59, 0xfa, 0x41, 0x01, 0xde,
+	0x43, 0x80, 0x34, 0xe4, 0x0e, 0x54, 0xfc, 0x5f, 0x75, 0xaa, 0x1a, 0xd9, 0xf7, 0x31, 0xcc, 0xa6,
+	0xbd, 0xb9, 0xfd, 0x40, 0xf4, 0x3d, 0xd9, 0xb9, 0x6c, 0x55, 0x29, 0x6c, 0x6a, 0x10, 0x7a, 0xa8,
+	0x41, 0xf6, 0x1d, 0x3c, 0x28, 0x92, 0xb9, 0x6a, 0x72, 0x1d, 0x0f, 0x03, 0xad, 0xaf, 0x55, 0x18,
+	0x43, 0xce, 0xfa, 0x88, 0xfa, 0xed, 0x17, 0x50, 0x56, 0xc1, 0x54, 0xc1, 0xe0, 0xd5, 0xa1, 0xf2,
+	0xfa, 0xe8, 0xe4, 0xd7, 0x47, 0xdb, 0xbb, 0x3b, 0x4d, 0x0b, 0xbb, 0xf0, 0xff, 0xfe, 0xd1, 0x8b,
+	0x66, 0x89, 0x01, 0xcc, 0xe9, 0x8e, 0x19, 0xfc, 0xff, 0xfc, 0x95, 0xf3, 0x72, 0x77, 0xa7, 0x39,
+	0x6b, 0xaf, 0x43, 0xed, 0x44, 0x46, 0x09, 0xf7, 0x15, 0xff, 0x1e, 0x41, 0x59, 0x71, 0xd7, 0x1a,
+	0xcd, 0xb3, 0x15, 0x6e, 0xaf, 0xc2, 0x2c, 0x36, 0x31, 0x1f, 0x08, 0x62, 0x7d, 0xf2, 0xa5, 0x20,
+	0xb6, 0xff, 0x63, 0x16, 0xea, 0x66, 0xd4, 0x37, 0x3d, 0x8e, 0xc5, 0x1e, 0x6d, 0x57, 0x74, 0xa2,
+	0x98, 0x36, 0xd1, 0x56, 0x85, 0x11, 0xe2, 0xca, 0x6a, 0xaa, 0x06, 0x9e, 0x5d, 0x24, 0x85, 0x7b,
+	0x16, 0xc8, 0xf3, 0x80, 0xf7, 0x7c, 0xd2, 0xd5, 0xba, 0x53, 0x8b, 0xa4, 0xd8, 0xd2, 0x10, 0x66,
+	0xb9, 0xa6, 0xaf, 0xc3, 0xc3, 0xe3, 0x68, 0xd8, 0x90, 0xd0, 0xf4, 0x6c, 0x7b, 0xd4, 0xc1, 0x9e,
+	0xc2, 0x1c, 0xd9, 0x81, 0xd4, 0xae, 0x3d, 0x1c, 0x0b, 0x5a, 0xd7, 0xc9, 0x1c, 0x89, 0xdd, 0x50,
+	0x26, 0x43, 0x47, 0x13, 0xb3, 0xa7, 0xb0, 0xd0, 0xf3, 0xa4, 0x0c, 0x3a, 0xfc, 0xf8, 0xa5, 0xdb,
+	0x0b, 0x84, 0x6c, 0xcd, 0xd3, 0xf0, 0x05, 0x1a, 0x7e, 0x90, 0x76, 0x39, 0x8d, 0x8c, 0xea, 0x20,
+	0x10, 0x92, 0xfd, 0x08, 0x2b, 0x99, 0xc2, 0xbb, 0x86, 0x76, 0xb7, 0x2a, 0x34, 0xfa, 0x93, 0xf1,
+	0xc9, 0x4f, 0xb4, 0x39, 0xd8, 0xcc, 0xd4, 0x5e, 0x2d, 0x84, 0x89, 0xb1, 0x0e, 0xb4, 0x37, 0xc8,
+	0x9d, 0x0e, 0x1a, 0x2e, 0x9e, 0x90, 0xb0, 0xcd, 0x3a, 0x10, 0x49, 0xb1, 0xad, 0x10, 0xf6, 0x2d,
+	0x2c, 0xa2, 0xe0, 0x70, 0x3f, 0xb7, 0xe6, 0x60, 0x58, 0xf3, 0x03, 0xea, 0xd3, 0xd6, 0xdb, 0x59,
+	0xe8, 0x99, 0x4d, 0xd1, 0xfe, 0x63, 0xa8, 0x19, 0x9c, 0x40, 0xdb, 0xd3, 0xe5, 0x43, 0x7d, 0xec,
+	0xf8, 0x17, 0x8f, 0xec, 0xca, 0xeb, 0x0d, 0xd2, 0xa3, 0x54, 0x8d, 0x3f, 0x29, 0x7d, 0x63, 0xb5,
+	0x77, 0xe1, 0xde, 0x94, 0x7d, 0xbc, 0xeb, 0x33, 0x0d, 0xe3, 0x33, 0xb6, 0x07, 0xd5, 0x8c, 0xb3,
+	0xa8, 0xde, 0xda, 0x9c, 0x5b, 0x69, 0x18, 0x80, 0x2d, 0x14, 0x11, 0x3f, 0xe8, 0x05, 0xf2, 0x32,
+	0x18, 0xf4, 0xd1, 0x0c, 0x2a, 0xb7, 0x58, 0xcb, 0xb0, 0xe3, 0x2e, 0xbb, 0x0f, 0x95, 0xee, 0xf0,
+	0x8c, 0x27, 0xd8, 0xad, 0x7c, 0xc1, 0x3c, 0xb5, 0x8f, 0xbb, 0xf6, 0x26, 0x34, 0x0a, 0x3e, 0xed,
+	0x06, 0xd9, 0x5d, 0x85, 0x39, 0xe5, 0x23, 0xf4, 0x7e, 0x75, 0xcb, 0xfe, 0xf7, 0x12, 0xd4, 0x8c,
+	0x60, 0x9a, 0xd2, 0x44, 0x4c, 0xff, 0x54, 0x18, 0x9d, 0x7a, 0x01, 0x84, 0x34, 0x41, 0x66, 0x90,
+	0xb4, 0xc2, 0x97, 0x0c, 0x83, 0xa4, 0x74, 0x1c, 0x23, 0xa4, 0x2c, 0xa9, 0x75, 0x05, 0xef, 0x44,
+	0xa1, 0x2f, 0xb4, 0x66, 0x34, 0xb3, 0x8e, 0x13, 0x85, 0x53, 0xda, 0x99, 0x4f, 0xa8, 0xd2, 0xce,
+	0x59, 0x9d, 0x76, 0x66, 0xb3, 0x62, 0xda, 0x89, 0x33, 0xab, 0xea, 0x8a, 0xb2, 0x38, 0xda, 0x50,
+	0xd6, 0x14, 0x46, 0x7b, 0x40, 0x13, 0xa7, 0x49, 0xd0, 0xd3, 0x28, 0x5b, 0x59, 0x55, 0xc8, 0x73,
+	0x4e, 0x22, 0xd7, 0xe7, 0x49, 0xb7, 0xc7, 0xdd, 0x24, 0x8a, 0x64, 0x9a, 0x03, 0x2b, 0xc8, 0x89,
+	0x22, 0x89, 0x53, 0xf4, 0x83, 0x30, 0x08, 0x2f, 0x5c, 0xa5, 0xce, 0x15, 0x3a, 0xd4, 0x9a, 0xc2,
+	0x8e, 0x10, 0xc2, 0x6f, 0xf0, 0x6b, 0x99, 0x78, 0x9a, 0x42, 0x8b, 0x2d, 0x41, 0x44, 0x60, 0xff,
+	0x95, 0x05, 0xcb, 0x13, 0xd2, 0x13, 0xf6, 0x04, 0xe6, 0x0c, 0xa6, 0xa6, 0xa1, 0xac, 0x41, 0xe9,
+	0xe8, 0x7e, 0xb6, 0x05, 0xa6, 0xea, 0x2b, 0x0d, 0xd1, 0x59, 0xea, 0xca, 0x68, 0xfc, 0x4b, 0xca,
+	0xe2, 0x34, 0xe5, 0x08, 0x62, 0xff, 0x75, 0x9a, 0x6b, 0x18, 0x20, 0xfb, 0x0a, 0xca, 0xea, 0x63,
+	0xca, 0x48, 0xae, 0x4d, 0xfc, 0xd8, 0x3a, 0xfd, 0x2a, 0xbd, 0x55, 0xe4, 0xed, 0x6f, 0x00, 0x72,
+	0xd0, 0x54, 0x82, 0xc6, 0xbb, 0x94, 0xe0, 0xef, 0x4b, 0xb0, 0x6c, 0x4c, 0xf0, 0x13, 0x98, 0xb1,
+	0x06, 0x25, 0x79, 0xdd, 0x2a, 0x19, 0x54, 0xc6, 0xf7, 0x9c, 0x92, 0xbc, 0xc6, 0xb8, 0x04, 0xa5,
+	0xdc, 0x3d, 0x4f, 0xa2, 0xbe, 0xd6, 0x90, 0x0a, 0x02, 0x58, 0xdc, 0xc1, 0xc8, 0x52, 0x04, 0x7f,
+	0x91, 0x46, 0x1d, 0xf4, 0x7f, 0xb2, 0x7c, 0x96, 0xa7, 0xc8, 0xe7, 0x2f, 0xa0, 0xa1, 0x33, 0x38,
+	0x4f, 0xf9, 0x79, 0x25, 0x55, 0x45, 0x50, 0x15, 0xe3, 0x74, 0x9e, 0xe7, 0x7a, 0xd2, 0xf5, 0x79,
+	0x2c, 0x95, 0x1b, 0xae, 0x38, 0xcd, 0xac, 0x67, 0x53, 0xee, 0x20, 0x6e, 0xff, 0xa7, 0x05, 0x8d,
+	0x42, 0xc2, 0xff, 0x1e, 0xfc, 0x38, 0x84, 0x95, 0x49, 0x19, 0xd9, 0xbb, 0x13, 0xdc, 0xbb, 0x13,
+	0x32, 0x31, 0x4c, 0x93, 0x17, 0x2f, 0x78, 0xc8, 0x45, 0x20, 0x52, 0x2b, 0xab, 0xf3, 0xdb, 0x65,
+	0x5d, 0x42, 0xa0, 0xbe, 0xcc, 0xca, 0x5e, 0x14, 0xda, 0x93, 0xb8, 0x6b, 0xff, 0xb3, 0x05, 0x65,
+	0xa5, 0x8d, 0xb7, 0xdf, 0xd4, 0x97, 0x13, 0x93, 0xf5, 0xf1, 0xe3, 0xae, 0xcb, 0xdf, 0xdb, 0xda,
+	0xed, 0x1d, 0x58, 0x28, 0x52, 0xfc, 0x14, 0xcf, 0x6f, 0xff, 0x00, 0x4b, 0xb4, 0xa1, 0x43, 0x2e,
+	0x3d, 0xac, 0x5c, 0x90, 0xe3, 0xdc, 0x82, 0x65, 0xd3, 0x46, 0xa6, 0x6e, 0xdd, 0x32, 0xbc, 0x57,
+	0x61, 0x90, 0xb3, 0x64, 0x98, 0x4f, 0xe5, 0xea, 0xed, 0xbf, 0x05, 0xa8, 0x19, 0x5b, 0x7f, 0x77,
+	0x78, 0xae, 0xc3, 0xeb, 0x52, 0x1e, 0x5e, 0x3f, 0x04, 0x88, 0x07, 0x67, 0xbd, 0xa0, 0xe3, 0xa2,
+	0xbe, 0x2a, 0xcd, 0xa8, 0x2a, 0xe4, 0x25, 0xa7, 0x62, 0x24, 0xe6, 0xd6, 0x9e, 0x1c, 0x24, 0x5c,
+	0x9b, 0xdc, 0x1c, 0xc8, 0x43, 0x9a, 0xb2, 0x19, 0xd2, 0x7c, 0x02, 0xcd, 0xd1, 0x78, 0x45, 0xa7,
+	0x15, 0x8b, 0x23, 0xd1, 0x0a, 0xfb, 0x1a, 0x2a, 0x52, 0xa7, 0x48, 0xa4, 0x08, 0xb5, 0x8d, 0xfb,
+	0xa3, 0xe7, 0xb9, 0x9e, 0xe6, 0x50, 0x7b, 0x77, 0x9c, 0x8c, 0x18, 0x07, 0x62, 0x55, 0xfa, 0xcc,
+	0x13, 0xca, 0x00, 0x4f, 0x1a, 0x88, 0x15, 0x8a, 0x2d, 0x4f, 0x60, 0x8d, 0x2e, 0x23, 0x66, 0x9b,
+	0x50, 0xcd, 0x02, 0x18, 0x32, 0xcc, 0xb5, 0x8d, 0xc7, 0x63, 0x23, 0x53, 0x9f, 0x9c, 0xb2, 0x01,
+	0xef, 0x3a, 0xb2, 0x51, 0xec, 0xcb, 0x3c, 0x2d, 0x86, 0xc9, 0x95, 0x8d, 0x75, 0x9d, 0x68, 0xef,
+	0xdd, 0xc9, 0x53, 0xe6, 0x75, 0x28, 0x53, 0xa4, 0x45, 0x15, 0xec, 0xda, 0xc6, 0xea, 0xf8, 0x3e,
+	0xb1, 0x17, 0xaf, 0x5c, 0x88, 0x8c, 0xbd, 0x80, 0x85, 0x74, 0xb7, 0xae, 0x1a, 0x58, 0xa7, 0x81,
+	0x3f, 0x9b, 0xca, 0xa0, 0xf4, 0x03, 0x0d, 0x69, 0x02, 0x38, 0x31, 0x45, 0x56, 0xad, 0xc6, 0x94,
+	0x89, 0x29, 0x90, 0xc1, 0x89, 0x89, 0x8c, 0xad, 0xc3, 0x32, 0xbf, 0x8e, 0x83, 0x64, 0x58, 0x0c,
+	0xda, 0x17, 0xe8, 0x88, 0x97, 0x54, 0x97, 0x11, 0xad, 0xb7, 0x7d, 0xa8, 0xa4, 0x2b, 0xc0, 0x38,
+	0x04, 0x25, 0x8f, 0xd2, 0x56, 0x95, 0x61, 0x91, 0x7a, 0x8c, 0xd4, 0x9f, 0x4a, 0xc5, 0x7c, 0xf4,
+	0xe7, 0xd0, 0x18, 0x84, 0x66, 0xfe, 0xa2, 0xc2, 0x80, 0xfa, 0x20, 0xcc, 0x13, 0x98, 0xf6, 0xb7,
+	0x50, 0x49, 0xcf, 0x13, 0x53, 0x42, 0x32, 0xe6, 0x32, 0x4a, 0x23, 0x25, 0x6c, 0x9e, 0x46, 0xd3,
+	0x02, 0x98, 0xf6, 0x31, 0x34, 0x47, 0x8f, 0xb4, 0x10, 0x32, 0x59, 0x85, 0x90, 0xe9, 0x16, 0x01,
+	0x57, 0xfb, 0x73, 0x98, 0xd7, 0x67, 0x8c, 0xd4, 0xfa, 0x8c, 0x5d, 0x23, 0x78, 0xab, 0x69, 0x0c,
+	0xc5, 0xbc, 0xfd, 0x4f, 0x16, 0x94, 0xd5, 0x61, 0xe4, 0xc5, 0x0d, 0x6b, 0x62, 0x71, 0xa3, 0x34,
+	0xa9, 0xb8, 0x31, 0x33, 0xad, 0xb8, 0x31, 0x7b, 0x8b, 0xe2, 0x46, 0xf9, 0xd6, 0xc5, 0x8d, 0xf6,
+	0x05, 0x34, 0x0a, 0xb2, 0x34, 0x56, 0x66, 0xb0, 0xc6, 0xcb, 0x0c, 0xe6, 0x89, 0x97, 0xa6, 0x9e,
+	0x78, 0xb1, 0xe2, 0xd8, 0xc6, 0x04, 0x8f, 0x64, 0xad, 0x50, 0x2e, 0xb0, 0xde, 0x51, 0x2e, 0x28,
+	0x8d, 0x95, 0x0b, 0xb6, 0x96, 0xc0, 0x34, 0x29, 0x88, 0xd9, 0xeb, 0x50, 0xa5, 0xc5, 0x93, 0x91,
+	0x1d, 0xdf, 0xc0, 0xcc, 0xc8, 0x06, 0xec, 0x2e, 0x34, 0x88, 0x1e, 0xed, 0xac, 0xef, 0x49, 0xef,
+	0x36, 0x9b, 0xfe, 0x1a, 0x5a, 0x45, 0xdd, 0x74, 0x75, 0x01, 0x8f, 0xa7, 0x45, 0x8f, 0x15, 0x59,
+	0xac, 0xfc, 0x68, 0x83, 0xfd, 0x3b, 0x0b, 0xee, 0xef, 0x86, 0x9d, 0x64, 0x18, 0x4b, 0xee, 0xef,
+	0xc6, 0x97, 0xbc, 0xcf, 0x13, 0xaf, 0x97, 0x4a, 0xd2, 0x0a, 0xcc, 0xf5, 0xc5, 0x05, 0x26, 0xde,
+	0xfa, 0xd2, 0xa2, 0x2f, 0x2e, 0xf6, 0x7d, 0x34, 0xda, 0x52, 0xf6, 0x52, 0xa3, 0x2d, 0x65, 0x4f,
+	0x21, 0x49, 0x5a, 0x7e, 0x93, 0x32, 0x61, 0xbf, 0x84, 0xf9, 0xce, 0xa5, 0x17, 0x86, 0xbc, 0x47,
+	0xb6, 0xb8, 0xb6, 0xf1, 0x11, 0x1d, 0xf8, 0xd4, 0xb9, 0xd6, 0xb7, 0x15, 0xb5, 0x93, 0x0e, 0xcb,
+	0x6d, 0xf9, 0x9c, 0x69, 0xcb, 0x5b, 0x30, 0x1f, 0x7b, 0xc3, 0x5e, 0xe4, 0xf9, 0x3a, 0x12, 0x4e,
+	0x9b, 0xed, 0xa7, 0x30, 0xaf, 0xbf, 0x81, 0xb7, 0x9b, 0x3c, 0xec, 0xb8, 0x1e, 0x17, 0x1b, 0x4f,
+	0xbf, 0x72, 0xc5, 0xb0, 0x8f, 0xae, 0x44, 0x39, 0x8b, 0x45, 0x1e, 0x76, 0x36, 0x09, 0x3f, 0x21,
+	0xd8, 0xfe, 0x0c, 0x6a, 0x5a, 0x0a, 0xe9, 0x80, 0x6e, 0xae, 0xc9, 0xfc, 0x83, 0x05, 0x8b, 0x5b,
+	0xb9, 0x11, 0xd8, 0xd1, 0xc7, 0x53, 0xb0, 0x4b, 0xd6, 0x78, 0x6e, 0x91, 0x5d, 0x31, 0xaa, 0xc8,
+	0x21, 0xbf, 0xb3, 0xd6, 0x57, 0x8c, 0x7b, 0x19, 0xcc, 0xbe, 0x80, 0x95, 0xce, 0xa0, 0x3f, 0xe8,
+	0x79, 0x32, 0xb8, 0xe2, 0xe6, 0x95, 0xa4, 0xd2, 0xb9, 0xbb, 0x79, 0x67, 0x7e, 0x2f, 0x69, 0xff,
+	0x5b, 0x1a, 0x9a, 0xa5, 0xbe, 0x79, 0xe2, 0xa5, 0xa6, 0x65, 0xcc, 0x98, 0x0f, 0x9e, 0x3e, 0x63,
+	0x69, 0xfa, 0x8c, 0x58, 0x02, 0xe8, 0x5c, 0x06, 0x3d, 0xdf, 0xd8, 0x91, 0xae, 0x9f, 0xd5, 0x9d,
+	0x25, 0xea, 0xd9, 0x33, 0x3a, 0xd0, 0x84, 0x53, 0x9d, 0xe7, 0xa8, 0x48, 0xaf, 0x6a, 0x0b, 0x4b,
+	0xd8, 0x75, 0x64, 0xd2, 0xdb, 0x7f, 0x06, 0xcc, 0xb0, 0xe8, 0x87, 0x5e, 0x1c, 0x07, 0xe1, 0x05,
+	0x5e, 0x54, 0x1a, 0x0c, 0xb4, 0xcc, 0xcb, 0x40, 0xe2, 0xdd, 0xc7, 0xb0, 0x88, 0x99, 0xd8, 0x38,
+	0x97, 0x17, 0x10, 0xce, 0x27, 0xb0, 0xff, 0x0e, 0xab, 0x88, 0xd2, 0x93, 0xfc, 0x20, 0x42, 0xec,
+	0xe6, 0x43, 0x1f, 0xd3, 0xbf, 0xd2, 0x98, 0xce, 0x1a, 0x99, 0xb2, 0x62, 0x81, 0x6e, 0xa1, 0x20,
+	0xaa, 0x8b, 0x6e, 0x74, 0xf7, 0xe9, 0x6d, 0xb7, 0xbe, 0x66, 0xa7, 0x0e, 0x74, 0x21, 0xea, 0xb2,
+	0xdb, 0xfe, 0x02, 0xea, 0xb4, 0x26, 0x75, 0x17, 0x48, 0x5e, 0x48, 0xd5, 0xac, 0x7a, 0x51, 0x7e,
+	0x95, 0x54, 0x77, 0xea, 0x22, 0x5f, 0xb8, 0xb0, 0x17, 0xa1, 0x71, 0xe0, 0xbc, 0xa6, 0x71, 0xdb,
+	0x5e, 0xe7, 0x92, 0xdb, 0x57, 0x50, 0x49, 0x9f, 0x55, 0xa0, 0x5b, 0xc2, 0x32, 0x92, 0xab, 0x4b,
+	0x47, 0x75, 0x67, 0x0e, 0x9b, 0xfb, 0x31, 0x1a, 0xf7, 0x38, 0x4a, 0xd2, 0x1b, 0x34, 0xfa, 0x8f,
+	0xa6, 0x9a, 0x9e, 0x1e, 0x74, 0x2e, 0x3d, 0x5c, 0x2a, 0x7e, 0x51, 0x5f, 0x16, 0xe6, 0x25, 0xc5,
+	0x6d, 0xec, 0xa3, 0xc9, 0x9c, 0x85, 0xb0, 0xd0, 0xc6, 0xdb, 0xb0, 0x85, 0x22, 0xc9, 0x6d, 0x14,
+	0x63, 0xe4, 0x7e, 0xb7, 0x34, 0x76, 0xbf, 0xfb, 0x53, 0xd4, 0xc1, 0x2c, 0x96, 0xce, 0x16, 0x8b,
+	0xa5, 0x85, 0xab, 0xec, 0xf2, 0xe8, 0x55, 0xf6, 0x63, 0x50, 0xcc, 0x75, 0xfd, 0xe0, 0x82, 0x0b,
+	0xa9, 0x63, 0xc4, 0x1a, 0x61, 0x3b, 0x04, 0xd9, 0x3f, 0xa8, 0x5d, 0xee, 0xe5, 0x2b, 0xbc, 0xc5,
+	0x2e, 0x6d, 0xa8, 0x17, 0xc4, 0x5e, 0x09, 0x50, 0x01, 0xb3, 0xbf, 0x03, 0x76, 0xbc, 0x71, 0xbc,
+	0xd9, 0xc1, 0x9a, 0x6b, 0x8f, 0xfb, 0x17, 0xbc, 0xcf, 0x43, 0x89, 0x12, 0x7d, 0x36, 0x94, 0x5c,
+	0xe0, 0x2b, 0x02, 0x74, 0x32, 0xfa, 0x4a, 0xa9, 0xe1, 0x2c, 0x10, 0x7c, 0x9c, 0xa2, 0xf6, 0xbf,
+	0x5a, 0xea, 0xdc, 0xa9, 0x58, 0xfc, 0x5e, 0xe7, 0x8e, 0x96, 0x02, 0xad, 0xa6, 0xef, 0x16, 0x2f,
+	0xf9, 0x1b, 0xce, 0xa2, 0xc2, 0x4f, 0x33, 0xfe, 0xac, 0x41, 0xad, 0x93, 0x70, 0x3f, 0x38, 0xc3,
+	0xb8, 0x63, 0xa8, 0x4b, 0xc2, 0x26, 0xc4, 0x9e, 0x41, 0x9b, 0xf4, 0xdc, 0x28, 0x31, 0xbb, 0x26,
+	0xc3, 0xd1, 0x5f, 0xb6, 0x90, 0xc2, 0xa8, 0x36, 0x67, 0xdf, 0xb7, 0x9f, 0x41, 0x59, 0xd5, 0x45,
+	0xbf, 0x80, 0x05, 0xb5, 0x81, 0xf0, 0x3c, 0x52, 0xa5, 0xbf, 0xd1, 0x67, 0x43, 0xb8, 0x4f, 0xa7,
+	0x1e, 0xeb, 0x7f, 0x68, 0xb9, 0xed, 0xaf, 0xa0, 0xad, 0xae, 0x63, 0x31, 0xac, 0x7a, 0xc9, 0x87,
+	0x62, 0x6b, 0xa8, 0x0d, 0xfb, 0xcd, 0x17, 0xfd, 0x47, 0xf0, 0x60, 0xea, 0x38, 0x11, 0xb3, 0x3f,
+	0x84, 0x9a, 0x8e, 0xb4, 0xb3, 0x98, 0x60, 0xbc, 0x06, 0x09, 0x69, 0x30, 0xde, 0x15, 0xf6, 0x5f,
+	0xc2, 0xfd, 0x5f, 0xf1, 0x24, 0x38, 0x1f, 0x6a, 0xcf, 0x76, 0x92, 0x66, 0x27, 0x37, 0x2e, 0xe3,
+	0x86, 0xbb, 0xad, 0x42, 0xca, 0x33, 0x33, 0x9a, 0xf2, 0xa8, 0xa7, 0x22, 0xb3, 0xd9, 0x53, 0x91,
+	0xdf, 0x58, 0xd0, 0x9e, 0x36, 0xbf, 0x7a, 0x5e, 0x70, 0xe5, 0xf5, 0x82, 0xec, 0x79, 0x01, 0x35,
+	0x10, 0xa5, 0x5b, 0x40, 0x7d, 0x25, 0xa8, 0x1a, 0x18, 0x0c, 0x61, 0xb1, 0x53, 0x3d, 0xc0, 0xd2,
+	0x77, 0xea, 0x91, 0x14, 0xfb, 0xd8, 0x66, 0x5f, 0xc2, 0xbd, 0xac, 0xd3, 0x1d, 0x08, 0xee, 0xbb,
+	0x58, 0xf5, 0x41, 0xed, 0xa7, 0xc5, 0x54, 0x9c, 0xe5, 0x94, 0xf4, 0xb5, 0xe0, 0xfe, 0xab, 0x90,
+	0x0c, 0x83, 0x7d, 0x4a, 0xef, 0x00, 0x0e, 0x83, 0x30, 0x20, 0xfb, 0x4e, 0x47, 0xf3, 0x29, 0x2c,
+	0x61, 0x15, 0xc4, 0x9d, 0xa0, 0x46, 0x8b, 0xd8, 0x61, 0x78, 0x02, 0x5c, 0x68, 0x5e, 0x59, 0x6a,
+	0xe8, 0x52, 0x8f, 0xfd, 0x7f, 0x16, 0x54, 0xb3, 0x6f, 0xfe, 0x5e, 0xec, 0xce, 0x7b, 0x55, 0x03,
+	0x3f, 0x9b, 0x54, 0xfa, 0x52, 0xda, 0x31, 0x56, 0xe3, 0x22, 0x62, 0x72, 0x09, 0x69, 0x40, 0x96,
+	0x70, 0x3f, 0xab, 0xe3, 0x60, 0xc7, 0x69, 0x8e, 0xa3, 0x0f, 0xe8, 0x07, 0xa1, 0x4e, 0x9f, 0x51,
+	0x68, 0x94, 0x49, 0xaa, 0x13, 0xa8, 0xa5, 0xd4, 0xde, 0xa1, 0x77, 0x08, 0x26, 0x4f, 0x95, 0xd8,
+	0x62, 0x01, 0x50, 0x31, 0xb5, 0x28, 0xb6, 0x19, 0xa5, 0x03, 0xfd, 0x6c, 0x90, 0xed, 0x43, 0xa3,
+	0x50, 0x9c, 0x9e, 0x5a, 0xfd, 0x9d, 0x92, 0xd3, 0xdc, 0x2a, 0x6b, 0xda, 0xf8, 0x9f, 0x0a, 0x54,
+	0x55, 0xca, 0xb3, 0x79, 0xbc, 0xcf, 0xbe, 0xa5, 0x37, 0x32, 0xd9, 0xcb, 0x47, 0x76, 0x37, 0x7d,
+	0x01, 0x62, 0xbe, 0x8f, 0x6c, 0xaf, 0x4c, 0x40, 0x45, 0xcc, 0xbe, 0xa7, 0x97, 0x33, 0xc6, 0x6d,
+	0x54, 0x46, 0x57, 0x78, 0x13, 0xd9, 0x5e, 0x9d, 0x04, 0x8b, 0x58, 0x4f, 0x9e, 0xbd, 0x55, 0xcc,
+	0x27, 0x37, 0x5f, 0x34, 0xb6, 0x57, 0x26, 0xa0, 0xc4, 0xde, 0x4a, 0xfa, 0x70, 0x8f, 0x35, 0x53,
+	0x92, 0xf4, 0x19, 0x60, 0x7b, 0x69, 0x04, 0xa1, 0x6b, 0xfc, 0xc5, 0x91, 0xe7, 0x44, 0xec, 0x5e,
+	0x4a, 0x35, 0xf2, 0x28, 0xa9, 0xdd, 0x9a, 0xdc, 0x21, 0x62, 0xb6, 0x01, 0xd5, 0xec, 0xb5, 0x10,
+	0xcb, 0x66, 0xc9, 0x1e, 0x19, 0xb5, 0xd9, 0x28, 0x94, 0xf1, 0x29, 0x7f, 0xa6, 0x92, 0xf3, 0xa9,
+	0xf0, 0xce, 0xa6, 0xbd, 0x3a, 0x09, 0x56, 0x2b, 0x1f, 0x79, 0x69, 0xa0, 0x57, 0x3e, 0xfe, 0x0a,
+	0xa2, 0xdd, 0x9a, 0xdc, 0xa1, 0x56, 0x51, 0x78, 0xa8, 0xc1, 0x8c, 0xa2, 0xb0, 0xf1, 0xb2, 0xa4,
+	0xbd, 0x3a, 0x09, 0x16, 0x31, 0x7b, 0x01, 0xcd, 0x9c, 0x21, 0xea, 0x05, 0x1c, 0x1b, 0xe5, 0x53,
+	0xf6, 0x7e, 0xae, 0x7d, 0x7f, 0x4a, 0x0f, 0x1d, 0x3b, 0xb2, 0x23, 0x7f, 0x78, 0xc0, 0x14, 0xcf,
+	0x0a, 0x2f, 0x11, 0xa6, 0xae, 0xe2, 0x6b, 0x7a, 0xee, 0x99, 0x5e, 0x9e, 0xeb, 0x93, 0x37, 0xee,
+	0xd2, 0x6f, 0x58, 0x3e, 0xbe, 0x26, 0x1b, 0xbd, 0x7d, 0x67, 0xad, 0x02, 0xf9, 0x6d, 0x3e, 0xa4,
+	0x56, 0x90, 0x5e, 0x81, 0xeb, 0x15, 0x18, 0x37, 0xe2, 0x53, 0x07, 0xfe, 0x08, 0xf7, 0xa6, 0xb8,
+	0x39, 0xf6, 0xc8, 0x38, 0xf9, 0x49, 0xce, 0xb3, 0xbd, 0x76, 0x33, 0x81, 0x88, 0xd9, 0xaf, 0x61,
+	0x75, 0xb2, 0xd3, 0x61, 0xaa, 0x3c, 0x34, 0xd5, 0x23, 0xb6, 0x1f, 0xdd, 0xd8, 0x9f, 0xc9, 0x6f,
+	0x6e, 0xde, 0x72, 0xf9, 0x2d, 0xb8, 0x91, 0xf6, 0xea, 0x24, 0x58, 0xc4, 0x1b, 0x00, 0x95, 0x4d,
+	0xbf, 0x1f, 0x84, 0x9b, 0xc7, 0xfb, 0x67, 0x73, 0xf4, 0x24, 0xfb, 0x8b, 0xff, 0x1f, 0x00, 0x56,
+	0x84, 0x2d, 0xb5, 0x9f, 0x2d, 0x00, 0x00,
 }
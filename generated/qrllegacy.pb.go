@@ -890,6 +890,9 @@ type VEData struct {
 	Version         string `protobuf:"bytes,1,opt,name=version" json:"version,omitempty"`
 	GenesisPrevHash []byte `protobuf:"bytes,2,opt,name=genesis_prev_hash,json=genesisPrevHash,proto3" json:"genesis_prev_hash,omitempty"`
 	RateLimit       uint64 `protobuf:"varint,3,opt,name=rate_limit,json=rateLimit" json:"rate_limit,omitempty"`
+	Features        uint32 `protobuf:"varint,4,opt,name=features" json:"features,omitempty"`
+	HandshakePk     []byte `protobuf:"bytes,5,opt,name=handshake_pk,json=handshakePk,proto3" json:"handshake_pk,omitempty"`
+	ProtocolVersion uint32 `protobuf:"varint,6,opt,name=protocol_version,json=protocolVersion" json:"protocol_version,omitempty"`
 }
 
 func (m *VEData) Reset()                    { *m = VEData{} }
@@ -918,6 +921,27 @@ func (m *VEData) GetRateLimit() uint64 {
 	return 0
 }
 
+func (m *VEData) GetFeatures() uint32 {
+	if m != nil {
+		return m.Features
+	}
+	return 0
+}
+
+func (m *VEData) GetHandshakePk() []byte {
+	if m != nil {
+		return m.HandshakePk
+	}
+	return nil
+}
+
+func (m *VEData) GetProtocolVersion() uint32 {
+	if m != nil {
+		return m.ProtocolVersion
+	}
+	return 0
+}
+
 type PLData struct {
 	PeerIps    []string `protobuf:"bytes,1,rep,name=peer_ips,json=peerIps" json:"peer_ips,omitempty"`
 	PublicPort uint32   `protobuf:"varint,2,opt,name=public_port,json=publicPort" json:"public_port,omitempty"`
@@ -132,6 +132,7 @@ func (m *GetLastBlockHeaderResp) GetDepth() uint64 {
 
 type GetBlockToMineReq struct {
 	WalletAddress string `protobuf:"bytes,1,opt,name=wallet_address,json=walletAddress" json:"wallet_address,omitempty"`
+	WorkerId      string `protobuf:"bytes,2,opt,name=worker_id,json=workerId" json:"worker_id,omitempty"`
 }
 
 func (m *GetBlockToMineReq) Reset()                    { *m = GetBlockToMineReq{} }
@@ -146,11 +147,22 @@ func (m *GetBlockToMineReq) GetWalletAddress() string {
 	return ""
 }
 
+func (m *GetBlockToMineReq) GetWorkerId() string {
+	if m != nil {
+		return m.WorkerId
+	}
+	return ""
+}
+
 type GetBlockToMineResp struct {
 	BlocktemplateBlob string `protobuf:"bytes,1,opt,name=blocktemplate_blob,json=blocktemplateBlob" json:"blocktemplate_blob,omitempty"`
 	Difficulty        uint64 `protobuf:"varint,2,opt,name=difficulty" json:"difficulty,omitempty"`
 	Height            uint64 `protobuf:"varint,3,opt,name=height" json:"height,omitempty"`
 	ReservedOffset    uint32 `protobuf:"varint,4,opt,name=reserved_offset,json=reservedOffset" json:"reserved_offset,omitempty"`
+	BlockWeight       uint64 `protobuf:"varint,5,opt,name=block_weight,json=blockWeight" json:"block_weight,omitempty"`
+	BlockWeightLimit  uint64 `protobuf:"varint,6,opt,name=block_weight_limit,json=blockWeightLimit" json:"block_weight_limit,omitempty"`
+	ExtraNonceStart   uint64 `protobuf:"varint,7,opt,name=extra_nonce_start,json=extraNonceStart" json:"extra_nonce_start,omitempty"`
+	ExtraNonceEnd     uint64 `protobuf:"varint,8,opt,name=extra_nonce_end,json=extraNonceEnd" json:"extra_nonce_end,omitempty"`
 }
 
 func (m *GetBlockToMineResp) Reset()                    { *m = GetBlockToMineResp{} }
@@ -186,6 +198,34 @@ func (m *GetBlockToMineResp) GetReservedOffset() uint32 {
 	return 0
 }
 
+func (m *GetBlockToMineResp) GetBlockWeight() uint64 {
+	if m != nil {
+		return m.BlockWeight
+	}
+	return 0
+}
+
+func (m *GetBlockToMineResp) GetBlockWeightLimit() uint64 {
+	if m != nil {
+		return m.BlockWeightLimit
+	}
+	return 0
+}
+
+func (m *GetBlockToMineResp) GetExtraNonceStart() uint64 {
+	if m != nil {
+		return m.ExtraNonceStart
+	}
+	return 0
+}
+
+func (m *GetBlockToMineResp) GetExtraNonceEnd() uint64 {
+	if m != nil {
+		return m.ExtraNonceEnd
+	}
+	return 0
+}
+
 type SubmitMinedBlockReq struct {
 	Blob []byte `protobuf:"bytes,1,opt,name=blob,proto3" json:"blob,omitempty"`
 }
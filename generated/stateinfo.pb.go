@@ -101,33 +101,82 @@ func (m *ForkState) GetNewMainchainHashPath() [][]byte {
 	return nil
 }
 
+// Node-local facts about how a block or transaction was received, stored
+// apart from it so its serialization and hash are unchanged
+type ReceiptMetadata struct {
+	ReceivedAt       uint64 `protobuf:"varint,1,opt,name=received_at,json=receivedAt" json:"received_at,omitempty"`
+	Source           string `protobuf:"bytes,2,opt,name=source" json:"source,omitempty"`
+	SourcePeer       string `protobuf:"bytes,3,opt,name=source_peer,json=sourcePeer" json:"source_peer,omitempty"`
+	ValidationMicros uint64 `protobuf:"varint,4,opt,name=validation_micros,json=validationMicros" json:"validation_micros,omitempty"`
+}
+
+func (m *ReceiptMetadata) Reset()                    { *m = ReceiptMetadata{} }
+func (m *ReceiptMetadata) String() string            { return proto.CompactTextString(m) }
+func (*ReceiptMetadata) ProtoMessage()               {}
+func (*ReceiptMetadata) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{3} }
+
+func (m *ReceiptMetadata) GetReceivedAt() uint64 {
+	if m != nil {
+		return m.ReceivedAt
+	}
+	return 0
+}
+
+func (m *ReceiptMetadata) GetSource() string {
+	if m != nil {
+		return m.Source
+	}
+	return ""
+}
+
+func (m *ReceiptMetadata) GetSourcePeer() string {
+	if m != nil {
+		return m.SourcePeer
+	}
+	return ""
+}
+
+func (m *ReceiptMetadata) GetValidationMicros() uint64 {
+	if m != nil {
+		return m.ValidationMicros
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*TransactionMetadata)(nil), "qrl.TransactionMetadata")
 	proto.RegisterType((*LastTransactions)(nil), "qrl.LastTransactions")
 	proto.RegisterType((*ForkState)(nil), "qrl.ForkState")
+	proto.RegisterType((*ReceiptMetadata)(nil), "qrl.ReceiptMetadata")
 }
 
 func init() { proto.RegisterFile("stateinfo.proto", fileDescriptor1) }
 
 var fileDescriptor1 = []byte{
-	// 303 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x91, 0xd1, 0x4a, 0xc3, 0x30,
-	0x14, 0x86, 0xe9, 0x3a, 0x84, 0x9d, 0x0e, 0x1c, 0xd9, 0xc4, 0x22, 0x5e, 0xd4, 0x5d, 0xf5, 0x6a,
-	0x60, 0xc5, 0x0b, 0x5f, 0x40, 0x76, 0xe1, 0x64, 0x54, 0xef, 0xc3, 0xd9, 0x9a, 0x91, 0xb0, 0x36,
-	0xe9, 0x92, 0x23, 0xdb, 0x33, 0xf8, 0x94, 0x3e, 0x8a, 0x34, 0xb3, 0x5b, 0x51, 0xef, 0x92, 0xff,
-	0x3b, 0x1f, 0xf9, 0x93, 0xc0, 0xa5, 0x23, 0x24, 0xa1, 0xf4, 0xc6, 0xcc, 0x6a, 0x6b, 0xc8, 0xb0,
-	0x70, 0x67, 0xcb, 0x9b, 0xc1, 0xce, 0x96, 0xc7, 0xfd, 0xf4, 0x33, 0x80, 0xf1, 0xbb, 0x45, 0xed,
-	0x70, 0x4d, 0xca, 0xe8, 0x85, 0x20, 0x2c, 0x90, 0x90, 0x65, 0x10, 0xd1, 0x39, 0x8e, 0x83, 0x24,
-	0x48, 0xa3, 0x6c, 0x34, 0x6b, 0xc4, 0xce, 0x78, 0xde, 0x1d, 0x62, 0x77, 0x30, 0x5c, 0x95, 0x66,
-	0xbd, 0xe5, 0xfa, 0xa3, 0x5a, 0x09, 0x1b, 0xf7, 0x92, 0x20, 0xed, 0xe7, 0x91, 0xcf, 0x5e, 0x7d,
-	0xc4, 0x6e, 0x61, 0x40, 0xaa, 0x12, 0x8e, 0xb0, 0xaa, 0xe3, 0xd0, 0xf3, 0x73, 0x30, 0x5d, 0xc0,
-	0xe8, 0x05, 0x1d, 0x75, 0x0e, 0x70, 0xec, 0x09, 0x22, 0x3a, 0xf0, 0xea, 0xa7, 0x57, 0x1c, 0x24,
-	0x61, 0x1a, 0x65, 0xf1, 0xef, 0x22, 0x6d, 0xef, 0x1c, 0xe8, 0xd0, 0xae, 0xa7, 0x5f, 0x01, 0x0c,
-	0x9e, 0x8d, 0xdd, 0xbe, 0x35, 0x6f, 0xc0, 0xee, 0x61, 0xa2, 0xb4, 0x22, 0x85, 0x64, 0x2c, 0x97,
-	0x02, 0x0b, 0x61, 0x25, 0x3a, 0xe9, 0xaf, 0x36, 0xcc, 0xc7, 0x27, 0x36, 0x3f, 0x21, 0x96, 0xc1,
-	0xd5, 0xc6, 0xd8, 0x2d, 0xaf, 0x8d, 0xd2, 0xd4, 0x75, 0x7a, 0x47, 0xa7, 0x81, 0xcb, 0x86, 0x75,
-	0x9c, 0x47, 0xb8, 0x36, 0x65, 0xc1, 0x2b, 0x54, 0x7a, 0x2d, 0x51, 0x69, 0xde, 0xa4, 0xbc, 0x46,
-	0x92, 0x71, 0x98, 0x84, 0xe9, 0x30, 0x9f, 0x98, 0xb2, 0x58, 0xb4, 0x74, 0x8e, 0x4e, 0x2e, 0x91,
-	0xbc, 0xa6, 0xc5, 0xfe, 0x5f, 0xad, 0x7f, 0xd4, 0xb4, 0xd8, 0xff, 0xd1, 0x56, 0x17, 0xfe, 0x17,
-	0x1f, 0xbe, 0x03, 0x00, 0x00, 0xff, 0xff, 0x97, 0x38, 0x64, 0xf3, 0xe8, 0x01, 0x00, 0x00,
+	// 385 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x92, 0xcf, 0x8e, 0xd3, 0x30,
+	0x10, 0x87, 0x95, 0x4d, 0xb5, 0x52, 0x26, 0x95, 0xb6, 0x78, 0x17, 0x88, 0x10, 0x12, 0xa5, 0xa7,
+	0x4a, 0x48, 0x2b, 0x51, 0xc4, 0x81, 0x23, 0x17, 0xb4, 0x07, 0x82, 0x2a, 0xc3, 0xdd, 0x9a, 0x26,
+	0xb3, 0x8a, 0xd5, 0xc4, 0xce, 0xda, 0xb3, 0x7f, 0x9e, 0x81, 0x27, 0xe0, 0xf1, 0x78, 0x14, 0x64,
+	0xa7, 0x69, 0x23, 0xe0, 0x66, 0x7f, 0x3f, 0x7f, 0xc9, 0x8c, 0xc7, 0x70, 0xe1, 0x19, 0x99, 0xb4,
+	0xb9, 0xb5, 0xd7, 0xbd, 0xb3, 0x6c, 0x45, 0x7a, 0xe7, 0xda, 0x57, 0xd9, 0x9d, 0x6b, 0x87, 0xfd,
+	0xea, 0x67, 0x02, 0x97, 0x3f, 0x1c, 0x1a, 0x8f, 0x15, 0x6b, 0x6b, 0x4a, 0x62, 0xac, 0x91, 0x51,
+	0x6c, 0x20, 0xe7, 0x13, 0x2e, 0x92, 0x65, 0xb2, 0xce, 0x37, 0x8b, 0xeb, 0x20, 0x4e, 0x8e, 0xcb,
+	0xe9, 0x21, 0xf1, 0x16, 0xe6, 0xbb, 0xd6, 0x56, 0x7b, 0x65, 0xee, 0xbb, 0x1d, 0xb9, 0xe2, 0x6c,
+	0x99, 0xac, 0x67, 0x32, 0x8f, 0xec, 0x5b, 0x44, 0xe2, 0x35, 0x64, 0xac, 0x3b, 0xf2, 0x8c, 0x5d,
+	0x5f, 0xa4, 0x31, 0x3f, 0x81, 0x55, 0x09, 0x8b, 0xaf, 0xe8, 0x79, 0xf2, 0x03, 0x2f, 0x3e, 0x41,
+	0xce, 0x4f, 0xaa, 0x3b, 0xd4, 0x55, 0x24, 0xcb, 0x74, 0x9d, 0x6f, 0x8a, 0xbf, 0x0b, 0x19, 0xeb,
+	0x96, 0xc0, 0x4f, 0xe3, 0x7a, 0xf5, 0x3b, 0x81, 0xec, 0x8b, 0x75, 0xfb, 0xef, 0xe1, 0x0e, 0xc4,
+	0x7b, 0xb8, 0xd2, 0x46, 0xb3, 0x46, 0xb6, 0x4e, 0x35, 0x84, 0x35, 0xb9, 0x06, 0x7d, 0x13, 0x5b,
+	0x9b, 0xcb, 0xcb, 0x63, 0x76, 0x73, 0x8c, 0xc4, 0x06, 0x9e, 0xdf, 0x5a, 0xb7, 0x57, 0xbd, 0xd5,
+	0x86, 0xa7, 0xce, 0xd9, 0xe0, 0x84, 0x70, 0x1b, 0xb2, 0x89, 0xf3, 0x11, 0x5e, 0xda, 0xb6, 0x56,
+	0x1d, 0x6a, 0x53, 0x35, 0xa8, 0x8d, 0x0a, 0x54, 0xf5, 0xc8, 0x4d, 0x91, 0x2e, 0xd3, 0xf5, 0x5c,
+	0x5e, 0xd9, 0xb6, 0x2e, 0xc7, 0xf4, 0x06, 0x7d, 0xb3, 0x45, 0x8e, 0x9a, 0xa1, 0xc7, 0xff, 0x6a,
+	0xb3, 0x41, 0x33, 0xf4, 0xf8, 0x8f, 0xb6, 0xfa, 0x95, 0xc0, 0x85, 0xa4, 0x8a, 0x74, 0xcf, 0xc7,
+	0xd1, 0xbd, 0x81, 0xdc, 0x05, 0xf4, 0x40, 0xb5, 0x42, 0x8e, 0xfd, 0xcd, 0x24, 0x8c, 0xe8, 0x33,
+	0x8b, 0x17, 0x70, 0xee, 0xed, 0xbd, 0xab, 0x28, 0xf6, 0x91, 0xc9, 0xc3, 0x2e, 0x88, 0xc3, 0x4a,
+	0xf5, 0x44, 0x2e, 0x8e, 0x27, 0x93, 0x30, 0xa0, 0x2d, 0x91, 0x13, 0xef, 0xe0, 0xd9, 0x03, 0xb6,
+	0xba, 0xc6, 0x70, 0xe5, 0xaa, 0xd3, 0x95, 0xb3, 0xbe, 0x98, 0xc5, 0xef, 0x2f, 0x4e, 0x41, 0x19,
+	0xf9, 0xee, 0x3c, 0x3e, 0xb0, 0x0f, 0x7f, 0x06, 0x00, 0x18, 0x15, 0x6c, 0xc7, 0x83, 0x02, 0x00,
+	0x00,
 }
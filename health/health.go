@@ -0,0 +1,144 @@
+// Package health serves the liveness and readiness probes of the node for
+// orchestrators and load balancers, along with the Prometheus metrics. /healthz answers as long as the process
+// serves HTTP, /readyz only once the database is readable, enough peers are
+// connected and the chain is close enough to the median height announced by
+// the peers. Read-only replicas have no peers, only their database is
+// checked.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/cyyber/go-qrl/log"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Source reports the node state the readiness checks are computed from
+type Source struct {
+	// CheckDatabase returns an error if the database can't be read
+	CheckDatabase func() error
+	PeerCount     func() int
+	Height        func() uint64
+	// NetworkHeight returns the median height announced by the peers
+	NetworkHeight func() uint64
+	// ReadOnly skips the peer and sync checks
+	ReadOnly bool
+	// WriteMetrics writes the metrics served on /metrics in the Prometheus
+	// text format, /metrics isn't served when it's nil
+	WriteMetrics func(w io.Writer)
+}
+
+type Check struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail,omitempty"`
+}
+
+type Report struct {
+	Ready  bool     `json:"ready"`
+	Checks []*Check `json:"checks"`
+}
+
+type Server struct {
+	source     *Source
+	minPeers   int
+	maxSyncLag uint64
+
+	log    log.Logger
+	server *http.Server
+}
+
+func NewServer(host string, port uint16, minPeers int, maxSyncLag uint64, source *Source, log log.Logger) *Server {
+	s := &Server{
+		source:     source,
+		minPeers:   minPeers,
+		maxSyncLag: maxSyncLag,
+		log:        log,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok\n"))
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		report := s.Readiness()
+		w.Header().Set("Content-Type", "application/json")
+		if !report.Ready {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(report)
+	})
+
+	if source.WriteMetrics != nil {
+		mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+			source.WriteMetrics(w)
+		})
+	}
+
+	s.server = &http.Server{
+		Addr:    net.JoinHostPort(host, strconv.Itoa(int(port))),
+		Handler: mux,
+	}
+	return s
+}
+
+// Readiness runs the readiness checks
+func (s *Server) Readiness() *Report {
+	report := &Report{Ready: true}
+	add := func(name string, ok bool, detail string) {
+		report.Checks = append(report.Checks, &Check{Name: name, OK: ok, Detail: detail})
+		report.Ready = report.Ready && ok
+	}
+
+	if err := s.source.CheckDatabase(); err != nil {
+		add("database", false, err.Error())
+	} else {
+		add("database", true, "")
+	}
+
+	if s.source.ReadOnly {
+		return report
+	}
+
+	peers := s.source.PeerCount()
+	add("peers", peers >= s.minPeers, strconv.Itoa(peers)+" connected, "+strconv.Itoa(s.minPeers)+" required")
+
+	height := s.source.Height()
+	var lag uint64
+	if networkHeight := s.source.NetworkHeight(); networkHeight > height {
+		lag = networkHeight - height
+	}
+	add("sync", lag <= s.maxSyncLag, strconv.FormatUint(lag, 10)+" blocks behind, "+strconv.FormatUint(s.maxSyncLag, 10)+" allowed")
+
+	return report
+}
+
+func (s *Server) Start() error {
+	// An http.Server can't serve again once shut down
+	s.server = &http.Server{Addr: s.server.Addr, Handler: s.server.Handler}
+
+	listener, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return err
+	}
+
+	s.log.Info("Health server listening", "address", s.server.Addr)
+	go func() {
+		if err := s.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			s.log.Warn("Health server stopped", "error", err)
+		}
+	}()
+	return nil
+}
+
+func (s *Server) Stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return s.server.Shutdown(ctx)
+}
@@ -1,12 +1,15 @@
 package log
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"log"
 	"os"
-	"bytes"
 	"strconv"
-	"fmt"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -29,6 +32,53 @@ type RecordKeyNames struct {
 	Lvl  string
 }
 
+type Lvl int32
+
+const (
+	LvlCrit Lvl = iota
+	LvlError
+	LvlWarn
+	LvlInfo
+	LvlDebug
+	LvlTrace
+)
+
+var lvlNames = map[string]Lvl{
+	"crit":  LvlCrit,
+	"error": LvlError,
+	"warn":  LvlWarn,
+	"info":  LvlInfo,
+	"debug": LvlDebug,
+	"trace": LvlTrace,
+}
+
+var ErrUnknownLevel = errors.New("unknown log level")
+
+// Records above the level are discarded by every logger, everything is
+// logged until SetLevel is called
+var level = int32(LvlTrace)
+
+func SetLevel(lvl Lvl) {
+	atomic.StoreInt32(&level, int32(lvl))
+}
+
+func GetLevel() Lvl {
+	return Lvl(atomic.LoadInt32(&level))
+}
+
+// ParseLevel returns the level named crit, error, warn, info, debug or trace
+func ParseLevel(name string) (Lvl, error) {
+	lvl, ok := lvlNames[strings.ToLower(name)]
+	if !ok {
+		return LvlTrace, ErrUnknownLevel
+	}
+	return lvl, nil
+}
+
+func enabled(lvl Lvl) bool {
+	return lvl <= GetLevel()
+}
+
 type Logger interface {
 	Trace(msg string, ctx ...interface{})
 	Debug(msg string, ctx ...interface{})
@@ -102,31 +152,49 @@ func New() Logger {
 }
 
 func (l *logger) Trace(msg string, ctx ...interface{}) {
+	if !enabled(LvlTrace) {
+		return
+	}
 	record := &Record {Msg: msg, Ctx: normalize(ctx)}
 	l.trace.Println(msg, TerminalFormat(record))
 }
 
 func (l *logger) Debug(msg string, ctx ...interface{}) {
+	if !enabled(LvlDebug) {
+		return
+	}
 	record := &Record {Msg: msg, Ctx: normalize(ctx)}
 	l.debug.Println(msg, TerminalFormat(record))
 }
 
 func (l *logger) Info(msg string, ctx ...interface{}) {
+	if !enabled(LvlInfo) {
+		return
+	}
 	record := &Record {Msg: msg, Ctx: normalize(ctx)}
 	l.info.Println(msg, TerminalFormat(record))
 }
 
 func (l *logger) Warn(msg string, ctx ...interface{}) {
+	if !enabled(LvlWarn) {
+		return
+	}
 	record := &Record {Msg: msg, Ctx: normalize(ctx)}
 	l.warn.Println(msg, TerminalFormat(record))
 }
 
 func (l *logger) Error(msg string, ctx ...interface{}) {
+	if !enabled(LvlError) {
+		return
+	}
 	record := &Record {Msg: msg, Ctx: normalize(ctx)}
 	l.error.Println(msg, TerminalFormat(record))
 }
 
 func (l *logger) Crit(msg string, ctx ...interface{}) {
+	if !enabled(LvlCrit) {
+		return
+	}
 	record := &Record {Msg: msg, Ctx: normalize(ctx)}
 	l.crit.Println(msg, TerminalFormat(record))
 }
@@ -2,11 +2,14 @@ package main
 
 import (
 	"bufio"
+	"flag"
+	"github.com/cyyber/go-qrl/core"
+	"github.com/cyyber/go-qrl/log"
+	"github.com/cyyber/go-qrl/p2p"
 	"os"
+	"os/signal"
 	"strings"
-	"github.com/cyyber/go-qrl/p2p"
-	"github.com/cyyber/go-qrl/log"
-	"github.com/cyyber/go-qrl/core"
+	"syscall"
 )
 
 var (
@@ -24,11 +27,42 @@ func startServer() error {
 	return nil
 }
 
+var (
+	networkName = flag.String("network", core.NetworkMainnet, "network to join: mainnet, devnet or regtest")
+	regtest     = flag.Bool("regtest", false, "run a local developer network with instant blocks, same as -network regtest")
+)
+
 func initialize() {
+	if *regtest {
+		*networkName = core.NetworkRegtest
+	}
+	if err := core.SelectNetwork(*networkName); err != nil {
+		logger.Error("invalid network", "error", err)
+		os.Exit(1)
+	}
 	config = core.GetConfig()
+	if err := config.LoadFile(); err != nil {
+		logger.Error("failed to load configuration file", "error", err)
+	}
 	server = &p2p.Server{}
 }
 
+// reloadOnHangup reloads the operational settings on SIGHUP
+func reloadOnHangup() {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	go func() {
+		for range hangup {
+			report, err := config.Reload()
+			if err != nil {
+				logger.Error("failed to reload configuration", "error", err)
+				continue
+			}
+			logger.Info("Reloaded configuration", "applied", report.Applied, "restart", report.RequireRestart)
+		}
+	}()
+}
+
 func run() {
 	err := startServer()
 	if err != nil {
@@ -37,6 +71,7 @@ func run() {
 	}
 	defer server.Stop()
 
+	reloadOnHangup()
 	sendLoop()
 }
 
@@ -54,6 +89,7 @@ func sendLoop() {
 }
 
 func main() {
+	flag.Parse()
 	logger.Info("Starting")
 	initialize()
 	run()
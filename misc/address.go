@@ -0,0 +1,143 @@
+package misc
+
+import (
+	"crypto/sha256"
+	"errors"
+	"strings"
+)
+
+// A QRL address is the 3 bytes descriptor of the XMSS tree, followed by
+// SHA2-256(descriptor || public key) and a 4 bytes checksum, which is the
+// last 4 bytes of SHA2-256(descriptor || hash)
+const (
+	AddressDescriptorSize = 3
+	AddressHashSize       = 32
+	AddressChecksumSize   = 4
+	AddressSize           = AddressDescriptorSize + AddressHashSize + AddressChecksumSize
+
+	QaddressPrefix = "Q"
+)
+
+// Values allowed in the address descriptor
+const (
+	signatureTypeXMSS = 0
+
+	hashFunctionSHA2_256  = 0
+	hashFunctionSHAKE_128 = 1
+	hashFunctionSHAKE_256 = 2
+
+	addressFormatSHA256_2X = 0
+)
+
+var (
+	ErrInvalidAddressSize     = errors.New("invalid address size")
+	ErrInvalidSignatureType   = errors.New("invalid address signature type")
+	ErrInvalidHashFunction    = errors.New("invalid address hash function")
+	ErrInvalidAddressFormat   = errors.New("invalid address format")
+	ErrInvalidAddressChecksum = errors.New("invalid address checksum")
+	ErrInvalidQaddress        = errors.New("invalid Q address")
+)
+
+func addressChecksum(address []byte) []byte {
+	hash := sha256.Sum256(address[:AddressDescriptorSize+AddressHashSize])
+	return hash[AddressHashSize-AddressChecksumSize:]
+}
+
+// ValidateAddress checks the size, descriptor and checksum of address
+func ValidateAddress(address []byte) error {
+	if len(address) != AddressSize {
+		return ErrInvalidAddressSize
+	}
+
+	if address[0]>>4 != signatureTypeXMSS {
+		return ErrInvalidSignatureType
+	}
+
+	switch address[0] & 0x0F {
+	case hashFunctionSHA2_256, hashFunctionSHAKE_128, hashFunctionSHAKE_256:
+	default:
+		return ErrInvalidHashFunction
+	}
+
+	if address[1]>>4 != addressFormatSHA256_2X {
+		return ErrInvalidAddressFormat
+	}
+
+	checksum := addressChecksum(address)
+	for i := 0; i < AddressChecksumSize; i++ {
+		if address[AddressSize-AddressChecksumSize+i] != checksum[i] {
+			return ErrInvalidAddressChecksum
+		}
+	}
+
+	return nil
+}
+
+// burnPatterns are the hash parts of the canonical burn addresses, no
+// public key can be found hashing to them so their balances are unspendable
+var burnPatterns = [][]byte{
+	{0x00},
+	{0xff},
+	{0xde, 0xad},
+	{0xde, 0xad, 0xbe, 0xef},
+}
+
+// IsBurnAddress returns true if address is a valid address whose hash part
+// repeats one of the canonical burn patterns, e.g. all zeroes
+func IsBurnAddress(address []byte) bool {
+	if ValidateAddress(address) != nil {
+		return false
+	}
+	hash := address[AddressDescriptorSize : AddressDescriptorSize+AddressHashSize]
+	for _, pattern := range burnPatterns {
+		matched := true
+		for i := range hash {
+			if hash[i] != pattern[i%len(pattern)] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// BurnAddress returns the canonical burn address with descriptor, whose
+// hash part is all zeroes
+func BurnAddress(descriptor []byte) []byte {
+	address := make([]byte, AddressSize)
+	copy(address, descriptor[:AddressDescriptorSize])
+	copy(address[AddressDescriptorSize+AddressHashSize:], addressChecksum(address))
+	return address
+}
+
+// AddressTreeHeight returns the height of the XMSS tree of address, stored
+// halved in the descriptor
+func AddressTreeHeight(address []byte) uint {
+	return uint(address[1]&0x0F) * 2
+}
+
+// Qaddress returns the human readable form of address
+func Qaddress(address []byte) string {
+	return QaddressPrefix + Bin2HStr(address)
+}
+
+// ParseQaddress parses and validates a Q address
+func ParseQaddress(qaddress string) ([]byte, error) {
+	if !strings.HasPrefix(qaddress, QaddressPrefix) {
+		return nil, ErrInvalidQaddress
+	}
+
+	address, err := HStr2Bin(qaddress[len(QaddressPrefix):])
+	if err != nil {
+		return nil, ErrInvalidQaddress
+	}
+
+	if err := ValidateAddress(address); err != nil {
+		return nil, err
+	}
+
+	return address, nil
+}
@@ -0,0 +1,52 @@
+package misc
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is the source of the current time, in seconds since the epoch. NTP
+// is the Clock used by a running node; FakeClock makes time dependent code
+// deterministic in tests and simulations.
+type Clock interface {
+	Time() uint64
+}
+
+// FakeClock only moves when told to
+type FakeClock struct {
+	lock sync.Mutex
+
+	now uint64
+}
+
+func NewFakeClock(now uint64) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+func (c *FakeClock) Time() uint64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.now
+}
+
+func (c *FakeClock) Set(now uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.now = now
+}
+
+func (c *FakeClock) Advance(seconds uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.now += seconds
+}
+
+// SystemClock trusts the local time, for networks running without NTP
+type SystemClock struct{}
+
+func (SystemClock) Time() uint64 {
+	return uint64(time.Now().Unix())
+}
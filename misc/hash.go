@@ -0,0 +1,88 @@
+package misc
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"github.com/theQRL/qrllib/goqrllib"
+	"golang.org/x/crypto/sha3"
+	"sync"
+)
+
+// SHA2-256 and SHAKE are standard primitives, so they can be computed in
+// Go without a CGO round trip into qrllib. The Go implementations are
+// cross validated against qrllib the first time they are used, and qrllib
+// is used instead should they ever disagree.
+
+var (
+	nativeHashOnce    sync.Once
+	nativeHashEnabled bool
+)
+
+var crossValidationSizes = []int{0, 1, 32, 55, 56, 64, 135, 136, 168, 1000}
+
+func useNativeHash() bool {
+	nativeHashOnce.Do(func() {
+		nativeHashEnabled = CrossValidateHashes() == nil
+	})
+	return nativeHashEnabled
+}
+
+// CrossValidateHashes compares the Go and qrllib implementations on inputs
+// around the block sizes of each primitive
+func CrossValidateHashes() error {
+	for _, size := range crossValidationSizes {
+		data := make([]byte, size)
+		for i := range data {
+			data[i] = byte(i*7 + size)
+		}
+
+		native := sha256.Sum256(data)
+		if !bytes.Equal(native[:], UCharVectorToBytes(goqrllib.Sha2_256(BytesToUCharVector(data)))) {
+			return errors.New("sha2_256 mismatch between go and qrllib")
+		}
+
+		if !bytes.Equal(nativeShake128(64, data), UCharVectorToBytes(goqrllib.Shake128(64, BytesToUCharVector(data)))) {
+			return errors.New("shake128 mismatch between go and qrllib")
+		}
+
+		if !bytes.Equal(nativeShake256(64, data), UCharVectorToBytes(goqrllib.Shake256(64, BytesToUCharVector(data)))) {
+			return errors.New("shake256 mismatch between go and qrllib")
+		}
+	}
+	return nil
+}
+
+func nativeShake128(size int, data []byte) []byte {
+	hash := make([]byte, size)
+	sha3.ShakeSum128(hash, data)
+	return hash
+}
+
+func nativeShake256(size int, data []byte) []byte {
+	hash := make([]byte, size)
+	sha3.ShakeSum256(hash, data)
+	return hash
+}
+
+func Sha256(data []byte) []byte {
+	if useNativeHash() {
+		hash := sha256.Sum256(data)
+		return hash[:]
+	}
+	return UCharVectorToBytes(goqrllib.Sha2_256(BytesToUCharVector(data)))
+}
+
+func Shake128(size int, data []byte) []byte {
+	if useNativeHash() {
+		return nativeShake128(size, data)
+	}
+	return UCharVectorToBytes(goqrllib.Shake128(int64(size), BytesToUCharVector(data)))
+}
+
+func Shake256(size int, data []byte) []byte {
+	if useNativeHash() {
+		return nativeShake256(size, data)
+	}
+	return UCharVectorToBytes(goqrllib.Shake256(int64(size), BytesToUCharVector(data)))
+}
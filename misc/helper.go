@@ -92,11 +92,11 @@ func MerkleTXHash(hashes list.List) []byte {
 			if h.Len() == z + 1 {
 				nextLayer.PushBack(e.Value.([]byte))
 			} else {
-				tmp := UcharVector{}
-				tmp.AddBytes(e.Value.([]byte))
+				var tmp []byte
+				tmp = append(tmp, e.Value.([]byte)...)
 				e := e.Next()
-				tmp.AddBytes(e.Value.([]byte))
-				nextLayer.PushBack(UCharVectorToBytes(goqrllib.Sha2_256(tmp.GetData())))
+				tmp = append(tmp, e.Value.([]byte)...)
+				nextLayer.PushBack(Sha256(tmp))
 				e = e.Next()
 			}
 			z += 2
@@ -0,0 +1,20 @@
+package misc
+
+import (
+	"container/list"
+	"encoding/binary"
+	"testing"
+)
+
+func BenchmarkMerkleRoot(b *testing.B) {
+	var hashes list.List
+	for i := 0; i < 1000; i++ {
+		hash := make([]byte, 8)
+		binary.BigEndian.PutUint64(hash, uint64(i))
+		hashes.PushBack(Sha256(hash))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		MerkleTXHash(hashes)
+	}
+}
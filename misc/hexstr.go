@@ -0,0 +1,48 @@
+package misc
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var ErrInvalidHexString = errors.New("invalid hex string")
+
+// Bin2HStr returns the lowercase hex encoding of data. Every hash or
+// address shown in logs, JSON or CLI output goes through it.
+func Bin2HStr(data []byte) string {
+	return hex.EncodeToString(data)
+}
+
+func Bin2HStrList(data [][]byte) []string {
+	result := make([]string, len(data))
+	for i, item := range data {
+		result[i] = Bin2HStr(item)
+	}
+	return result
+}
+
+// HStr2Bin decodes a hex string, upper and lowercase digits are accepted
+func HStr2Bin(s string) ([]byte, error) {
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidHexString
+	}
+	return data, nil
+}
+
+// BytesToString converts a protobuf bytes field carrying text, such as a
+// token symbol or a message, into a string. Fields which are not printable
+// UTF-8 are returned hex encoded instead.
+func BytesToString(data []byte) string {
+	if !utf8.Valid(data) {
+		return Bin2HStr(data)
+	}
+	s := string(data)
+	if strings.IndexFunc(s, func(r rune) bool { return !unicode.IsPrint(r) && !unicode.IsSpace(r) }) != -1 {
+		return Bin2HStr(data)
+	}
+	return s
+}
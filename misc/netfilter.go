@@ -0,0 +1,109 @@
+package misc
+
+import (
+	"fmt"
+	"net"
+)
+
+// CIDRFilter decides whether a remote IP may connect. A denied range always
+// wins; when an allowlist is configured, only IPs inside it are accepted.
+type CIDRFilter struct {
+	allow []*net.IPNet
+	deny  []*net.IPNet
+}
+
+func parseCIDRs(cidrs []string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, cidr := range cidrs {
+		// Plain IPs are accepted as single host ranges
+		if ip := net.ParseIP(cidr); ip != nil {
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 8 * net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %s: %s", cidr, err)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+func NewCIDRFilter(allow []string, deny []string) (*CIDRFilter, error) {
+	allowNets, err := parseCIDRs(allow)
+	if err != nil {
+		return nil, err
+	}
+	denyNets, err := parseCIDRs(deny)
+	if err != nil {
+		return nil, err
+	}
+	return &CIDRFilter{
+		allow: allowNets,
+		deny:  denyNets,
+	}, nil
+}
+
+func (f *CIDRFilter) Allowed(ip net.IP) bool {
+	if f == nil {
+		return true
+	}
+	for _, ipNet := range f.deny {
+		if ipNet.Contains(ip) {
+			return false
+		}
+	}
+	if len(f.allow) == 0 {
+		return true
+	}
+	for _, ipNet := range f.allow {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoteIP extracts the IP of the remote end of a connection
+func RemoteIP(addr net.Addr) net.IP {
+	if tcpAddr, ok := addr.(*net.TCPAddr); ok {
+		return tcpAddr.IP
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return nil
+	}
+	return net.ParseIP(host)
+}
+
+// FilteredListener closes accepted connections rejected by allowed, so that
+// servers built on top of it (API listeners) never see them
+type FilteredListener struct {
+	net.Listener
+	allowed func(ip net.IP) bool
+}
+
+func NewFilteredListener(listener net.Listener, allowed func(ip net.IP) bool) *FilteredListener {
+	return &FilteredListener{
+		Listener: listener,
+		allowed:  allowed,
+	}
+}
+
+func (l *FilteredListener) Accept() (net.Conn, error) {
+	for {
+		c, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		if l.allowed(RemoteIP(c.RemoteAddr())) {
+			return c, nil
+		}
+		c.Close()
+	}
+}
@@ -0,0 +1,143 @@
+package misc
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Amounts are stored and signed as Shor, Quanta only exist as a decimal
+// representation for humans. Never convert through float64, above 2^53
+// Shor (~9 million Quanta) it silently loses precision.
+const (
+	ShorPerQuanta  = 1000000000
+	QuantaDecimals = 9
+
+	MaxShor = 1<<64 - 1
+)
+
+var (
+	ErrInvalidQuanta   = errors.New("invalid Quanta amount")
+	ErrQuantaPrecision = errors.New("Quanta amount has more than 9 decimals")
+	ErrShorOverflow    = errors.New("amount overflows uint64 Shor")
+)
+
+type Rounding int
+
+const (
+	// RoundStrict refuses amounts which aren't a whole number of Shor
+	RoundStrict Rounding = iota
+	RoundDown
+	RoundUp
+	RoundHalfUp
+)
+
+// ParseQuanta converts a decimal Quanta string such as "12.5" to Shor.
+// Only plain decimal notation is accepted: no sign, exponent, grouping or
+// surrounding spaces, and digits are required on both sides of the point.
+func ParseQuanta(quanta string) (uint64, error) {
+	return ParseQuantaRounded(quanta, RoundStrict)
+}
+
+// ParseQuantaRounded is ParseQuanta, decimals beyond the Shor being
+// rounded according to mode instead of rejected.
+func ParseQuantaRounded(quanta string, mode Rounding) (uint64, error) {
+	whole, fraction, hasPoint := quanta, "", false
+	if i := strings.IndexByte(quanta, '.'); i >= 0 {
+		whole, fraction, hasPoint = quanta[:i], quanta[i+1:], true
+	}
+	if !isDigits(whole) || (hasPoint && !isDigits(fraction)) {
+		return 0, ErrInvalidQuanta
+	}
+
+	var extra string
+	if len(fraction) > QuantaDecimals {
+		fraction, extra = fraction[:QuantaDecimals], fraction[QuantaDecimals:]
+	}
+	fraction += strings.Repeat("0", QuantaDecimals-len(fraction))
+
+	w, err := strconv.ParseUint(whole, 10, 64)
+	if err != nil {
+		return 0, ErrShorOverflow
+	}
+	shor, err := QuantaToShor(w)
+	if err != nil {
+		return 0, err
+	}
+	f, _ := strconv.ParseUint(fraction, 10, 64)
+	if shor, err = AddShor(shor, f); err != nil {
+		return 0, err
+	}
+
+	if strings.Trim(extra, "0") == "" {
+		return shor, nil
+	}
+	switch mode {
+	case RoundDown:
+		return shor, nil
+	case RoundUp:
+		return AddShor(shor, 1)
+	case RoundHalfUp:
+		if extra[0] >= '5' {
+			return AddShor(shor, 1)
+		}
+		return shor, nil
+	}
+	return 0, ErrQuantaPrecision
+}
+
+// FormatQuanta renders shor as decimal Quanta without trailing zeros,
+// e.g. 1500000000 is "1.5". ParseQuanta(FormatQuanta(x)) == x for any x.
+func FormatQuanta(shor uint64) string {
+	s := strconv.FormatUint(shor/ShorPerQuanta, 10)
+	if fraction := shor % ShorPerQuanta; fraction > 0 {
+		s += "." + strings.TrimRight(formatFraction(fraction), "0")
+	}
+	return s
+}
+
+// FormatQuantaFixed always renders the 9 decimals, useful for aligned
+// columns and exports.
+func FormatQuantaFixed(shor uint64) string {
+	return strconv.FormatUint(shor/ShorPerQuanta, 10) + "." + formatFraction(shor%ShorPerQuanta)
+}
+
+func QuantaToShor(quanta uint64) (uint64, error) {
+	if quanta > MaxShor/ShorPerQuanta {
+		return 0, ErrShorOverflow
+	}
+	return quanta * ShorPerQuanta, nil
+}
+
+// ShorToQuanta splits shor into whole Quanta and the remaining Shor
+func ShorToQuanta(shor uint64) (quanta uint64, remainder uint64) {
+	return shor / ShorPerQuanta, shor % ShorPerQuanta
+}
+
+// AddShor sums amounts, failing instead of wrapping around
+func AddShor(amounts ...uint64) (uint64, error) {
+	var total uint64
+	for _, amount := range amounts {
+		if total+amount < total {
+			return 0, ErrShorOverflow
+		}
+		total += amount
+	}
+	return total, nil
+}
+
+func formatFraction(fraction uint64) string {
+	return strconv.FormatUint(fraction+ShorPerQuanta, 10)[1:]
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
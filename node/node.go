@@ -0,0 +1,403 @@
+// Package node embeds a QRL node in another Go program. It wires the
+// storage, chain, transaction pool, event bus and P2P server the same way
+// the qrl binary does, and exposes typed query methods so the embedding
+// program doesn't need to go through the gRPC APIs.
+//
+//	config := core.GetConfig()
+//	if err := config.LoadFile(); err != nil {
+//		return err
+//	}
+//	n, err := node.New(config)
+//	if err != nil {
+//		return err
+//	}
+//	if err := n.Start(); err != nil {
+//		return err
+//	}
+//	defer n.Stop()
+//
+//	sub := n.Subscribe(events.TopicNewBlock)
+//	for event := range sub.Events() {
+//		...
+//	}
+//
+// The exported methods of Node are a stable API, new methods may be added
+// but existing signatures won't change.
+package node
+
+import (
+	"context"
+	"errors"
+	"github.com/cyyber/go-qrl/api"
+	"github.com/cyyber/go-qrl/core"
+	"github.com/cyyber/go-qrl/core/pool"
+	"github.com/cyyber/go-qrl/core/transactions"
+	"github.com/cyyber/go-qrl/deposits"
+	"github.com/cyyber/go-qrl/diagnostics"
+	"github.com/cyyber/go-qrl/events"
+	"github.com/cyyber/go-qrl/generated"
+	"github.com/cyyber/go-qrl/genesis"
+	"github.com/cyyber/go-qrl/health"
+	"github.com/cyyber/go-qrl/log"
+	"github.com/cyyber/go-qrl/misc"
+	"github.com/cyyber/go-qrl/p2p"
+	"github.com/cyyber/go-qrl/tracing"
+	"github.com/cyyber/go-qrl/webhooks"
+	"sync"
+)
+
+const eventBufferSize = 256
+
+var (
+	ErrNodeRunning    = errors.New("node is already running")
+	ErrNodeNotRunning = errors.New("node is not running")
+)
+
+type Node struct {
+	lock sync.Mutex
+
+	config *core.Config
+	log    log.Logger
+
+	state    *core.State
+	txPool   *pool.TransactionPool
+	eventBus *events.Bus
+	manager  *core.ChainManager
+	server   *p2p.Server
+
+	compactor    *core.Compactor
+	recompressor *core.BlockRecompressor
+	archiver     *core.BlockArchiver
+	pruner       *core.BlockPruner
+	replica      *core.Replica
+	debug        *diagnostics.Server
+	health       *health.Server
+	publicAPI    *api.Server
+	adminAPI     *api.AdminAPIServer
+	deposits     *deposits.Watcher
+	webhooks     *webhooks.Notifier
+
+	stopTracing func(context.Context) error
+
+	running     bool
+	stateClosed bool
+}
+
+// New opens the data directory and loads the chain, without connecting to
+// the network. Start must be called to join the network. The configuration
+// file, if any, must already have been applied with config.LoadFile.
+func New(config *core.Config) (*Node, error) {
+	logger := log.New()
+
+	genesisBlock, err := genesis.CreateGenesisBlock()
+	if err != nil {
+		return nil, err
+	}
+	core.AddDevAccounts(&genesisBlock.Block, config)
+	if err := config.OpenDataDir(genesisBlock.HeaderHash(), logger); err != nil {
+		return nil, err
+	}
+
+	var state *core.State
+	if config.User.ReadOnly {
+		state, err = core.CreateReadOnlyState(&logger, config)
+	} else {
+		state, err = core.CreateState(&logger, config)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := state.CheckGenesis(genesisBlock.HeaderHash()); err != nil {
+		return nil, err
+	}
+
+	txPool := pool.CreateTransactionPool(config)
+	eventBus := events.NewBus(eventBufferSize)
+	manager := core.CreateChainManager(logger, state, txPool, eventBus, config)
+
+	// Without NTP, such as on regtest, the local clock is trusted
+	if !config.User.NTP.Enabled {
+		txPool.SetClock(misc.SystemClock{})
+		manager.Chain().SetClock(misc.SystemClock{})
+	}
+
+	if err := manager.Load(&genesisBlock.Block); err != nil {
+		return nil, err
+	}
+
+	server := &p2p.Server{}
+	server.SetChainManager(manager)
+	manager.SetBlockRelay(server.BroadcastBlock)
+
+	n := &Node{
+		config:       config,
+		log:          logger,
+		state:        state,
+		txPool:       txPool,
+		eventBus:     eventBus,
+		manager:      manager,
+		server:       server,
+		compactor:    core.NewCompactor(state, config, logger),
+		recompressor: core.NewBlockRecompressor(manager.Chain(), logger),
+		archiver:     core.NewBlockArchiver(manager.Chain(), config, logger),
+		pruner:       core.NewBlockPruner(state, config, logger),
+		replica:      core.NewReplica(manager.Chain(), config, logger),
+		debug:        diagnostics.NewServer(config.User.Debug.Host, config.User.Debug.Port, logger),
+	}
+	healthConfig := config.User.Health
+	source := &health.Source{
+		CheckDatabase: func() error {
+			_, err := state.GetChainHeight()
+			return err
+		},
+		PeerCount:     n.PeerCount,
+		Height:        n.Height,
+		NetworkHeight: server.NetworkHeight,
+		ReadOnly:      config.User.ReadOnly,
+		WriteMetrics:  txPool.WritePrometheus,
+	}
+	n.health = health.NewServer(healthConfig.Host, healthConfig.Port, int(healthConfig.MinPeers), healthConfig.MaxSyncLag, source, logger)
+
+	publicAPI := api.NewPublicAPIServer(manager.Chain(), server, config, logger)
+	n.publicAPI = api.NewPublicServer(publicAPI, config.User.API.PublicAPI, logger)
+	n.adminAPI = api.NewAdminAPIServer(manager.Chain(), server, config, logger)
+	n.adminAPI.SetChainManager(manager)
+
+	if config.User.Deposits.Enabled {
+		n.deposits, err = deposits.NewWatcher(manager, eventBus, config, logger)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(config.User.Webhooks.Endpoints) > 0 {
+		n.webhooks, err = webhooks.NewNotifier(manager, eventBus, config, logger)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return n, nil
+}
+
+// Start connects the node to the network. Read-only replicas never connect,
+// they only follow the snapshots of their primary.
+func (n *Node) Start() error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if n.running {
+		return ErrNodeRunning
+	}
+
+	if n.stateClosed {
+		if err := n.state.Open(); err != nil {
+			return err
+		}
+		n.stateClosed = false
+	}
+
+	if tracingConfig := n.config.User.Tracing; tracingConfig.Enabled {
+		stopTracing, err := tracing.Init(tracingConfig.Exporter, tracingConfig.Endpoint, tracingConfig.SampleRatio, "go-qrl")
+		if err != nil {
+			return err
+		}
+		n.stopTracing = stopTracing
+	}
+
+	if n.config.User.Debug.Enabled {
+		if err := n.debug.Start(); err != nil {
+			return err
+		}
+		diagnostics.RegisterGauge("events.pending", func() int64 {
+			return int64(n.eventBus.Pending())
+		})
+		diagnostics.RegisterGauge("txpool.size", func() int64 {
+			return int64(len(n.txPool.Transactions()))
+		})
+	}
+
+	if n.config.User.Health.Enabled {
+		if err := n.health.Start(); err != nil {
+			return err
+		}
+	}
+
+	if n.config.User.API.PublicAPI.Enabled {
+		if err := n.publicAPI.Start(); err != nil {
+			return err
+		}
+	}
+
+	if n.webhooks != nil {
+		n.webhooks.Start()
+	}
+
+	if n.deposits != nil {
+		n.deposits.Start()
+	}
+
+	if n.config.User.ReadOnly {
+		n.replica.Start()
+	} else {
+		if err := n.server.Start(n.log, n.config); err != nil {
+			return err
+		}
+		n.compactor.Start()
+		n.recompressor.Start()
+		if err := n.archiver.Start(); err != nil {
+			return err
+		}
+		n.pruner.Start()
+	}
+
+	n.running = true
+	return nil
+}
+
+// Stop disconnects the node and closes the database, the query methods fail
+// until the node is started again
+func (n *Node) Stop() error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if !n.running {
+		return ErrNodeNotRunning
+	}
+
+	if n.config.User.ReadOnly {
+		n.replica.Stop()
+	} else {
+		n.compactor.Stop()
+		n.recompressor.Stop()
+		n.archiver.Stop()
+		n.pruner.Stop()
+		n.server.Stop()
+	}
+
+	if n.config.User.API.PublicAPI.Enabled {
+		n.publicAPI.Stop()
+	}
+
+	// A running index rebuild would write to the closed database
+	n.adminAPI.StopRebuildIndexes(context.Background())
+
+	if n.deposits != nil {
+		n.deposits.Stop()
+	}
+
+	if n.webhooks != nil {
+		n.webhooks.Stop()
+	}
+
+	if n.config.User.Health.Enabled {
+		n.health.Stop()
+	}
+
+	if n.config.User.Debug.Enabled {
+		diagnostics.UnregisterGauge("events.pending")
+		diagnostics.UnregisterGauge("txpool.size")
+		n.debug.Stop()
+	}
+
+	if n.stopTracing != nil {
+		n.stopTracing(context.Background())
+		n.stopTracing = nil
+	}
+
+	n.state.Close()
+	n.stateClosed = true
+
+	n.running = false
+	return nil
+}
+
+// Close stops the node if it is running and closes the database, it is the
+// counterpart of New for programs that never start the node
+func (n *Node) Close() error {
+	if err := n.Stop(); err != nil && err != ErrNodeNotRunning {
+		return err
+	}
+
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if !n.stateClosed {
+		n.state.Close()
+		n.stateClosed = true
+	}
+	return nil
+}
+
+// AdminAPI returns the operator calls of the node, such as GenerateBlocks
+func (n *Node) AdminAPI() *api.AdminAPIServer {
+	return n.adminAPI
+}
+
+func (n *Node) Config() *core.Config {
+	return n.config
+}
+
+// ReloadConfig applies the operational settings changed in the
+// configuration file
+func (n *Node) ReloadConfig() (*core.ReloadReport, error) {
+	return n.config.Reload()
+}
+
+func (n *Node) Height() uint64 {
+	return n.manager.Height()
+}
+
+func (n *Node) Tip() *core.Block {
+	return n.manager.Tip()
+}
+
+func (n *Node) GetBlock(headerHash []byte) (*core.Block, error) {
+	return n.manager.GetBlock(headerHash)
+}
+
+func (n *Node) GetBlockByNumber(blockNumber uint64) (*core.Block, error) {
+	return n.manager.GetBlockByNumber(blockNumber)
+}
+
+func (n *Node) GetAddressState(address []byte) (*core.AddressState, error) {
+	return n.manager.Chain().GetAddressState(address)
+}
+
+func (n *Node) GetTransaction(txHash []byte) (*generated.TransactionMetadata, error) {
+	return n.state.GetTxMetadata(txHash)
+}
+
+func (n *Node) PendingTransactions() []*pool.TransactionInfo {
+	return n.manager.Chain().PendingTransactions()
+}
+
+// SubmitTransaction validates tx against the current tip and adds it to the
+// transaction pool, it is broadcast to the peers from there
+func (n *Node) SubmitTransaction(tx *generated.Transaction) error {
+	t := transactions.ProtoToTransaction(tx)
+	if t == nil {
+		return errors.New("unsupported transaction type")
+	}
+	return n.manager.Chain().SubmitTransaction(t)
+}
+
+// AddBlock processes a block obtained outside of the P2P network
+func (n *Node) AddBlock(block *core.Block) bool {
+	return n.manager.AddBlock(block, core.BlockFromAPI)
+}
+
+// GenerateBlocks instantly mines count blocks paying minerAddress, on
+// regtest only
+func (n *Node) GenerateBlocks(count int, minerAddress []byte) ([]*core.Block, error) {
+	return n.manager.GenerateBlocks(count, minerAddress)
+}
+
+// Subscribe returns a subscription to the events published on topics. The
+// caller must Unsubscribe once done.
+func (n *Node) Subscribe(topics ...events.Topic) *events.Subscription {
+	return n.eventBus.Subscribe(topics...)
+}
+
+func (n *Node) PeerCount() int {
+	return n.server.PeerCount()
+}
@@ -0,0 +1,185 @@
+package p2p
+
+import (
+	"errors"
+	"github.com/cyyber/go-qrl/generated"
+	"github.com/cyyber/go-qrl/misc"
+	"github.com/golang/protobuf/proto"
+	"io/ioutil"
+	"net"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Anchors are outbound peers which stayed connected and well-behaved for a
+// long time during the previous session. They are reconnected first on
+// restart, before any slot is filled from the general peer list, which makes
+// it harder for an attacker to eclipse the node across restarts. They're
+// kept in the data directory of the network, with the ban list.
+
+func (srv *Server) anchorsFile() string {
+	return path.Join(srv.config.DataDir(), srv.config.Dev.AnchorsFilename)
+}
+
+// selectAnchors picks the longest lived outbound peers that have been
+// connected for at least MinAnchorUptime seconds
+func (srv *Server) selectAnchors(peers map[string]*Peer) []*Peer {
+	minUptime := time.Duration(srv.config.User.Node.MinAnchorUptime) * time.Second
+
+	var candidates []*Peer
+	for _, p := range peers {
+		if p.inbound {
+			continue
+		}
+		if p.Uptime() < minUptime {
+			continue
+		}
+		candidates = append(candidates, p)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Uptime() > candidates[j].Uptime()
+	})
+
+	if len(candidates) > int(srv.config.User.Node.MaxAnchorPeers) {
+		candidates = candidates[:srv.config.User.Node.MaxAnchorPeers]
+	}
+
+	return candidates
+}
+
+func (srv *Server) saveAnchors(peers map[string]*Peer) error {
+	anchors := &generated.Peers{}
+	for _, p := range srv.selectAnchors(peers) {
+		host, port, err := net.SplitHostPort(p.conn.RemoteAddr().String())
+		if err != nil {
+			continue
+		}
+		portNum, err := strconv.ParseUint(port, 10, 32)
+		if err != nil {
+			continue
+		}
+		anchors.PeerInfoList = append(anchors.PeerInfoList, &generated.PeerInfo{
+			PeerIp: []byte(host),
+			Port:   uint32(portNum),
+		})
+	}
+
+	value, err := proto.Marshal(anchors)
+	if err != nil {
+		return err
+	}
+
+	srv.log.Debug("Saving anchor peers", "count", len(anchors.PeerInfoList))
+	return ioutil.WriteFile(srv.anchorsFile(), value, 0600)
+}
+
+// loadAnchors reads and removes the anchors file. Anchors are only used for
+// the first connection attempt after a restart, so a peer that turns bad
+// cannot stick around as an anchor forever.
+func (srv *Server) loadAnchors() ([]string, error) {
+	value, err := ioutil.ReadFile(srv.anchorsFile())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	os.Remove(srv.anchorsFile())
+
+	anchors := &generated.Peers{}
+	if err := proto.Unmarshal(value, anchors); err != nil {
+		return nil, err
+	}
+
+	var addresses []string
+	for _, peerInfo := range anchors.PeerInfoList {
+		addresses = append(addresses, net.JoinHostPort(string(peerInfo.PeerIp), strconv.Itoa(int(peerInfo.Port))))
+	}
+
+	return addresses, nil
+}
+
+func (srv *Server) peerAddress(ip string) string {
+	if _, _, err := net.SplitHostPort(ip); err == nil {
+		return ip
+	}
+	return net.JoinHostPort(ip, strconv.Itoa(int(srv.config.User.Node.PublicPort)))
+}
+
+func (srv *Server) connectPeer(address string) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if !srv.isAllowed(net.ParseIP(host)) {
+		return errors.New("peer address rejected by connection filter")
+	}
+
+	c, err := net.DialTimeout("tcp", address, time.Duration(srv.config.User.Node.PeerWriteTimeout)*time.Second)
+	if err != nil {
+		return err
+	}
+
+	group := srv.netGroup(misc.RemoteIP(c.RemoteAddr()))
+	if !srv.groups.reserve(group, int(srv.config.Settings().Node.MaxOutboundPerNetGroup)) {
+		c.Close()
+		return errNetGroupFull
+	}
+
+	select {
+	case srv.addpeer <- &conn{c, false, group}:
+		return nil
+	case <-srv.exit:
+		srv.groups.release(group, false)
+		c.Close()
+		return errors.New("server is quitting")
+	}
+}
+
+// bootstrapConnections dials the anchors from the previous session first,
+// then fills the remaining outbound slots from the configured peer list
+func (srv *Server) bootstrapConnections() {
+	defer srv.loopWG.Done()
+
+	dialed := make(map[string]bool)
+	connected := 0
+	maxOutbound := int(srv.config.Settings().Node.MaxOutboundPeers)
+
+	anchors, err := srv.loadAnchors()
+	if err != nil {
+		srv.log.Warn("Failed to load anchor peers", "error", err)
+	}
+
+	for _, address := range anchors {
+		if connected >= maxOutbound {
+			return
+		}
+		dialed[address] = true
+		if err := srv.connectPeer(address); err != nil {
+			srv.log.Debug("Failed to connect anchor peer", "peer", address, "error", err)
+			continue
+		}
+		srv.log.Info("Connected to anchor peer", "peer", address)
+		connected++
+	}
+
+	for _, ip := range srv.config.User.Node.PeerList {
+		if connected >= maxOutbound {
+			return
+		}
+		address := srv.peerAddress(ip)
+		if dialed[address] {
+			continue
+		}
+		dialed[address] = true
+		if err := srv.connectPeer(address); err != nil {
+			srv.log.Debug("Failed to connect peer", "peer", address, "error", err)
+			continue
+		}
+		connected++
+	}
+}
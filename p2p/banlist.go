@@ -0,0 +1,128 @@
+package p2p
+
+import (
+	"github.com/cyyber/go-qrl/generated"
+	"github.com/golang/protobuf/proto"
+	"io/ioutil"
+	"net"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// BanList holds banned peer IPs with their expiry, persisted in
+// BannedPeersFilename so bans survive restarts
+type BanList struct {
+	lock sync.Mutex
+
+	filename string
+	bans     map[string]uint32 // ip -> expiry timestamp
+}
+
+func NewBanList(filename string) *BanList {
+	return &BanList{
+		filename: filename,
+		bans:     make(map[string]uint32),
+	}
+}
+
+func (b *BanList) Load() error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	value, err := ioutil.ReadFile(b.filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	peers := &generated.Peers{}
+	if err := proto.Unmarshal(value, peers); err != nil {
+		return err
+	}
+
+	now := uint32(time.Now().Unix())
+	for _, peerInfo := range peers.PeerInfoList {
+		if peerInfo.BannedTimestamp > now {
+			b.bans[string(peerInfo.PeerIp)] = peerInfo.BannedTimestamp
+		}
+	}
+	return nil
+}
+
+func (b *BanList) save() error {
+	value, err := proto.Marshal(&generated.Peers{PeerInfoList: b.list()})
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(b.filename, value, 0600)
+}
+
+// expire removes the bans which expired, must be called with the lock held
+func (b *BanList) expire() bool {
+	now := uint32(time.Now().Unix())
+	expired := false
+	for ip, expiry := range b.bans {
+		if expiry <= now {
+			delete(b.bans, ip)
+			expired = true
+		}
+	}
+	return expired
+}
+
+func (b *BanList) Ban(ip net.IP, duration time.Duration) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.expire()
+	b.bans[ip.String()] = uint32(time.Now().Add(duration).Unix())
+	return b.save()
+}
+
+func (b *BanList) Unban(ip net.IP) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if _, ok := b.bans[ip.String()]; !ok {
+		return nil
+	}
+	delete(b.bans, ip.String())
+	return b.save()
+}
+
+func (b *BanList) IsBanned(ip net.IP) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	expiry, ok := b.bans[ip.String()]
+	return ok && expiry > uint32(time.Now().Unix())
+}
+
+func (b *BanList) list() []*generated.PeerInfo {
+	var peerInfos []*generated.PeerInfo
+	for ip, expiry := range b.bans {
+		peerInfos = append(peerInfos, &generated.PeerInfo{
+			PeerIp:          []byte(ip),
+			BannedTimestamp: expiry,
+		})
+	}
+	sort.Slice(peerInfos, func(i, j int) bool {
+		return string(peerInfos[i].PeerIp) < string(peerInfos[j].PeerIp)
+	})
+	return peerInfos
+}
+
+// List returns the active bans, ordered by IP
+func (b *BanList) List() []*generated.PeerInfo {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.expire() {
+		b.save()
+	}
+	return b.list()
+}
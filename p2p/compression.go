@@ -0,0 +1,182 @@
+package p2p
+
+import (
+	"errors"
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Once both sides advertised a compression feature in their VE messages,
+// every frame payload starts with a codec byte, followed by the message
+// compressed with that codec, or as is for codecNone. Small messages aren't
+// worth compressing and are sent with codecNone. Compression is applied
+// before encryption, the frame size limits apply to the compressed payload
+// and the message size limits to the decompressed one.
+
+const (
+	CompressionOff    = "off"
+	CompressionSnappy = "snappy"
+	CompressionZstd   = "zstd"
+)
+
+const (
+	codecNone   byte = 0
+	codecSnappy byte = 1
+	codecZstd   byte = 2
+)
+
+const minCompressSize = 256
+
+var (
+	errUnknownCodec     = errors.New("unknown compression codec")
+	errDecompressedSize = errors.New("decompressed message too large")
+)
+
+var (
+	zstdOnce    sync.Once
+	zstdEncoder *zstd.Encoder
+	zstdDecoder *zstd.Decoder
+)
+
+func initZstd(maxSize uint64) {
+	zstdOnce.Do(func() {
+		zstdEncoder, _ = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedFastest))
+		zstdDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderMaxMemory(maxSize))
+	})
+}
+
+// compressionFeatures returns the features advertised for the configured
+// compression, zstd peers fall back to snappy with peers lacking zstd
+func compressionFeatures(compression string) uint32 {
+	switch compression {
+	case CompressionSnappy:
+		return FeatureSnappy
+	case CompressionZstd:
+		return FeatureZstd | FeatureSnappy
+	}
+	return 0
+}
+
+// CompressionStats sums the bytes saved by compression over all peers and
+// the time spent compressing and decompressing
+type CompressionStats struct {
+	RawBytesOut        uint64
+	CompressedBytesOut uint64
+	RawBytesIn         uint64
+	CompressedBytesIn  uint64
+	CompressTime       time.Duration
+	DecompressTime     time.Duration
+}
+
+type compressionStats struct {
+	rawOut        uint64
+	compressedOut uint64
+	rawIn         uint64
+	compressedIn  uint64
+	compressNs    int64
+	decompressNs  int64
+}
+
+func (s *compressionStats) snapshot() CompressionStats {
+	return CompressionStats{
+		RawBytesOut:        atomic.LoadUint64(&s.rawOut),
+		CompressedBytesOut: atomic.LoadUint64(&s.compressedOut),
+		RawBytesIn:         atomic.LoadUint64(&s.rawIn),
+		CompressedBytesIn:  atomic.LoadUint64(&s.compressedIn),
+		CompressTime:       time.Duration(atomic.LoadInt64(&s.compressNs)),
+		DecompressTime:     time.Duration(atomic.LoadInt64(&s.decompressNs)),
+	}
+}
+
+// negotiateCompression selects the codec of the connection from the shared
+// capabilities, zstd first. It switches the codec under the write lock
+// before releaseTransport, the other messages are held back until then.
+func (p *Peer) negotiateCompression() {
+	codec := codecNone
+	switch {
+	case p.Supports(FeatureZstd):
+		initZstd(p.config.Dev.MaxReceivableBytes)
+		codec = codecZstd
+	case p.Supports(FeatureSnappy):
+		codec = codecSnappy
+	}
+
+	p.writeLock.Lock()
+	p.codec = codec
+	p.writeLock.Unlock()
+	if codec != codecNone {
+		p.log.Debug("Compression enabled", "codec", codec)
+	}
+}
+
+// compress prefixes data with its codec, the write lock must be held
+func (p *Peer) compress(data []byte) []byte {
+	codec := p.codec
+	if len(data) < minCompressSize {
+		codec = codecNone
+	}
+
+	start := time.Now()
+	var compressed []byte
+	switch codec {
+	case codecSnappy:
+		compressed = snappy.Encode(nil, data)
+	case codecZstd:
+		compressed = zstdEncoder.EncodeAll(data, nil)
+	}
+	if codec == codecNone || len(compressed) >= len(data) {
+		return append([]byte{codecNone}, data...)
+	}
+
+	atomic.AddInt64(&p.compression.compressNs, int64(time.Since(start)))
+	atomic.AddUint64(&p.compression.rawOut, uint64(len(data)))
+	atomic.AddUint64(&p.compression.compressedOut, uint64(len(compressed)))
+	return append([]byte{codec}, compressed...)
+}
+
+func (p *Peer) decompress(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, errUnknownCodec
+	}
+	codec, payload := data[0], data[1:]
+	maxSize := p.config.Dev.MaxReceivableBytes
+
+	start := time.Now()
+	var decompressed []byte
+	switch codec {
+	case codecNone:
+		return payload, nil
+	case codecSnappy:
+		size, err := snappy.DecodedLen(payload)
+		if err != nil {
+			return nil, err
+		}
+		if uint64(size) > maxSize {
+			return nil, errDecompressedSize
+		}
+		if decompressed, err = snappy.Decode(nil, payload); err != nil {
+			return nil, err
+		}
+	case codecZstd:
+		if p.codec != codecZstd {
+			return nil, errUnknownCodec
+		}
+		var err error
+		if decompressed, err = zstdDecoder.DecodeAll(payload, nil); err != nil {
+			return nil, err
+		}
+		if uint64(len(decompressed)) > maxSize {
+			return nil, errDecompressedSize
+		}
+	default:
+		return nil, errUnknownCodec
+	}
+
+	atomic.AddInt64(&p.compression.decompressNs, int64(time.Since(start)))
+	atomic.AddUint64(&p.compression.rawIn, uint64(len(decompressed)))
+	atomic.AddUint64(&p.compression.compressedIn, uint64(len(payload)))
+	return decompressed, nil
+}
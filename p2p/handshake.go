@@ -0,0 +1,161 @@
+package p2p
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+	"io"
+)
+
+const (
+	// FeatureEncryptedTransport is advertised in VEData.Features when the
+	// peer is able to switch to the encrypted transport after the VE exchange
+	FeatureEncryptedTransport uint32 = 1 << 0
+)
+
+// The handshake follows the Noise NN pattern: both keys are ephemeral and
+// exchanged in the VE messages, nothing binds them to the identity of the
+// node. It's unauthenticated, it only keeps passive observers from reading
+// the traffic. An active man-in-the-middle can run a handshake with each side
+// and read and alter everything, so the transport must not be relied on to
+// identify a peer or protect what it sends.
+var handshakeProtocolName = []byte("QRL_Noise_NN_25519_ChaChaPoly_SHA256")
+
+var (
+	errInvalidHandshakeKey = errors.New("invalid handshake public key")
+	errDecryptionFailed    = errors.New("failed to decrypt message")
+	errNonceExhausted      = errors.New("transport nonce exhausted")
+)
+
+// handshake holds the ephemeral key pair generated for a single connection.
+// Keys are never reused across connections.
+type handshake struct {
+	privateKey [32]byte
+	publicKey  [32]byte
+}
+
+func newHandshake() (*handshake, error) {
+	h := &handshake{}
+	if _, err := io.ReadFull(rand.Reader, h.privateKey[:]); err != nil {
+		return nil, err
+	}
+	curve25519.ScalarBaseMult(&h.publicKey, &h.privateKey)
+	return h, nil
+}
+
+func (h *handshake) PublicKey() []byte {
+	return h.publicKey[:]
+}
+
+// deriveSession performs the DH with the remote ephemeral key and derives
+// one key per direction. The side with the lexicographically lower public
+// key acts as initiator so both ends agree on the key assignment without
+// any extra round trip.
+func (h *handshake) deriveSession(remotePK []byte) (*secureSession, error) {
+	if len(remotePK) != 32 || bytes.Equal(remotePK, h.publicKey[:]) {
+		return nil, errInvalidHandshakeKey
+	}
+
+	var remote, shared [32]byte
+	copy(remote[:], remotePK)
+	curve25519.ScalarMult(&shared, &h.privateKey, &remote)
+
+	var zero [32]byte
+	if bytes.Equal(shared[:], zero[:]) {
+		return nil, errInvalidHandshakeKey
+	}
+
+	initiator := bytes.Compare(h.publicKey[:], remotePK) < 0
+
+	// Transcript hash binds both ephemeral keys into the derived keys
+	transcript := sha256.New()
+	transcript.Write(handshakeProtocolName)
+	if initiator {
+		transcript.Write(h.publicKey[:])
+		transcript.Write(remotePK)
+	} else {
+		transcript.Write(remotePK)
+		transcript.Write(h.publicKey[:])
+	}
+
+	kdf := hkdf.New(sha256.New, shared[:], transcript.Sum(nil), handshakeProtocolName)
+	initiatorKey := make([]byte, chacha20poly1305.KeySize)
+	responderKey := make([]byte, chacha20poly1305.KeySize)
+	if _, err := io.ReadFull(kdf, initiatorKey); err != nil {
+		return nil, err
+	}
+	if _, err := io.ReadFull(kdf, responderKey); err != nil {
+		return nil, err
+	}
+
+	sendKey, recvKey := initiatorKey, responderKey
+	if !initiator {
+		sendKey, recvKey = responderKey, initiatorKey
+	}
+
+	return newSecureSession(sendKey, recvKey)
+}
+
+// secureSession encrypts and authenticates every frame payload once the
+// handshake is complete. Nonces are implicit counters, so a dropped,
+// replayed or reordered frame fails authentication.
+type secureSession struct {
+	send cipherState
+	recv cipherState
+}
+
+type cipherState struct {
+	aead  cipher.AEAD
+	nonce uint64
+}
+
+func newSecureSession(sendKey []byte, recvKey []byte) (*secureSession, error) {
+	sendAEAD, err := chacha20poly1305.New(sendKey)
+	if err != nil {
+		return nil, err
+	}
+	recvAEAD, err := chacha20poly1305.New(recvKey)
+	if err != nil {
+		return nil, err
+	}
+	return &secureSession{
+		send: cipherState{aead: sendAEAD},
+		recv: cipherState{aead: recvAEAD},
+	}, nil
+}
+
+func (c *cipherState) nextNonce() ([]byte, error) {
+	if c.nonce == ^uint64(0) {
+		return nil, errNonceExhausted
+	}
+	nonce := make([]byte, c.aead.NonceSize())
+	binary.LittleEndian.PutUint64(nonce[4:], c.nonce)
+	c.nonce++
+	return nonce, nil
+}
+
+func (s *secureSession) Encrypt(plaintext []byte) ([]byte, error) {
+	nonce, err := s.send.nextNonce()
+	if err != nil {
+		return nil, err
+	}
+	return s.send.aead.Seal(nil, nonce, plaintext, nil), nil
+}
+
+func (s *secureSession) Decrypt(ciphertext []byte) ([]byte, error) {
+	nonce, err := s.recv.nextNonce()
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := s.recv.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, errDecryptionFailed
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,550 @@
+package p2p
+
+import (
+	"bytes"
+	"github.com/cyyber/go-qrl/core"
+	"github.com/cyyber/go-qrl/diagnostics"
+	"github.com/cyyber/go-qrl/generated"
+	"github.com/cyyber/go-qrl/misc"
+	"github.com/willf/bloom"
+	"net"
+	"sync"
+	"time"
+)
+
+// The Python node expects its peers to follow a few rules of the legacy
+// protocol on top of the LegacyMessage framing:
+//   - every message but VE and P2P_ACK is acknowledged by a P2P_ACK holding
+//     the processed frame size, and a node stops sending once MaxBytesOut
+//     bytes are left unacknowledged
+//   - a peer doesn't send more messages per minute than the rate limit
+//     announced in the VE message
+//   - the VE message carries the genesis prev headerhash, peers on another
+//     network are dropped
+//   - new blocks are announced with MR, the peers missing them ask for the
+//     full block with SFM, a BK that wasn't requested is ignored, as is a
+//     PB since this node never sends FB
+// Acknowledgements are only waited for once the peer sent one, so peers
+// which never acknowledge can't stall the connection.
+
+const (
+	syncStateSynced = "Synced"
+
+	// Peers whose tip is more recent than this are reported synced
+	syncedTipAge = 10 * time.Minute
+
+	rateLimitWindow = time.Minute
+
+	// Blocks received from a peer waiting to be added, further blocks are
+	// dropped until the worker catches up
+	blockQueueSize = 64
+
+	// Blocks we announced, kept to answer the SFM requests
+	maxAnnouncedBlocks = 16
+
+	// Blocks requested from a peer with SFM and not received yet
+	maxRequestedBlocks = 16
+
+	// Messages queued before the transport is settled
+	maxHeldBack = 64
+)
+
+// Header hashes sent in reply to a single HEADERHASHES request
+var maxLegacyHeaderHashes = uint64(maxHeaderHashesSize / 64)
+
+// seenFilter is the bloom filter of the blocks and transactions already
+// seen, shared by the peers
+type seenFilter struct {
+	lock   sync.Mutex
+	filter *bloom.BloomFilter
+}
+
+func newSeenFilter(m uint, k uint) *seenFilter {
+	return &seenFilter{filter: bloom.New(m, k)}
+}
+
+func (f *seenFilter) Test(data []byte) bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return f.filter.Test(data)
+}
+
+func (f *seenFilter) Add(data []byte) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.filter.Add(data)
+}
+
+// TestAndAdd adds data and tells whether it was seen before
+func (f *seenFilter) TestAndAdd(data []byte) bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return f.filter.TestAndAdd(data)
+}
+
+// announcedBlocks keeps the last blocks we announced with MR. A block relayed
+// cut-through is announced before it is stored, so the SFM requests of the
+// peers are answered from here first.
+type announcedBlocks struct {
+	lock   sync.Mutex
+	blocks []*core.Block
+}
+
+func (a *announcedBlocks) add(block *core.Block) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.blocks = append(a.blocks, block)
+	if len(a.blocks) > maxAnnouncedBlocks {
+		a.blocks = a.blocks[1:]
+	}
+}
+
+func (a *announcedBlocks) get(headerHash []byte) *core.Block {
+	if a == nil {
+		return nil
+	}
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	for _, block := range a.blocks {
+		if bytes.Equal(block.HeaderHash(), headerHash) {
+			return block
+		}
+	}
+	return nil
+}
+
+// requestedBlocks keeps the header hashes of the blocks requested from the
+// peer with SFM
+type requestedBlocks struct {
+	lock   sync.Mutex
+	hashes [][]byte
+}
+
+func (r *requestedBlocks) add(headerHash []byte) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.hashes = append(r.hashes, headerHash)
+	if len(r.hashes) > maxRequestedBlocks {
+		r.hashes = r.hashes[1:]
+	}
+}
+
+// take forgets headerHash and returns whether it was requested
+func (r *requestedBlocks) take(headerHash []byte) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for i, hash := range r.hashes {
+		if bytes.Equal(hash, headerHash) {
+			r.hashes = append(r.hashes[:i], r.hashes[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// blockAnnouncement returns the MR message announcing block
+func blockAnnouncement(block *core.Block) *generated.LegacyMessage {
+	return &generated.LegacyMessage{
+		FuncName: generated.LegacyMessage_MR,
+		Data: &generated.LegacyMessage_MrData{MrData: &generated.MRData{
+			Hash:           block.HeaderHash(),
+			Type:           generated.LegacyMessage_BK,
+			BlockNumber:    block.BlockNumber(),
+			PrevHeaderhash: block.PrevHeaderHash(),
+		}},
+	}
+}
+
+// flowControl tracks the bytes sent and not yet acknowledged by the peer
+type flowControl struct {
+	lock sync.Mutex
+
+	unacked uint64
+	ackSeen bool
+	acked   chan struct{}
+}
+
+func newFlowControl() *flowControl {
+	return &flowControl{acked: make(chan struct{}, 1)}
+}
+
+// tryReserve accounts for size more bytes if they can be sent without
+// exceeding limit, otherwise the caller waits on acked and retries
+func (f *flowControl) tryReserve(size uint64, limit uint64) bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if !f.ackSeen || f.unacked == 0 || f.unacked+size <= limit {
+		f.unacked += size
+		return true
+	}
+	return false
+}
+
+func (f *flowControl) ack(size uint64) {
+	f.lock.Lock()
+	f.ackSeen = true
+	if size > f.unacked {
+		f.unacked = 0
+	} else {
+		f.unacked -= size
+	}
+	f.lock.Unlock()
+
+	select {
+	case f.acked <- struct{}{}:
+	default:
+	}
+}
+
+// messageRate counts the messages of the current rate limit window
+type messageRate struct {
+	lock sync.Mutex
+
+	windowStart time.Time
+	count       uint64
+}
+
+// add records a message and returns the number of messages of the window
+func (m *messageRate) add() uint64 {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	now := time.Now()
+	if now.Sub(m.windowStart) >= rateLimitWindow {
+		m.windowStart = now
+		m.count = 0
+	}
+	m.count++
+	return m.count
+}
+
+// wait returns how long to wait before sending another message without
+// exceeding limit messages per window. A limit of 0 disables pacing.
+func (m *messageRate) wait(limit uint64) time.Duration {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if limit == 0 || m.count < limit {
+		return 0
+	}
+	remaining := rateLimitWindow - time.Since(m.windowStart)
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// isFlowControlled tells whether a message counts against the peer's
+// unacknowledged bytes and rate limit
+func isFlowControlled(funcName generated.LegacyMessage_FuncName) bool {
+	return funcName != generated.LegacyMessage_VE && funcName != generated.LegacyMessage_P2P_ACK
+}
+
+// waitForRate applies the peer's rate limit before a message is written
+func (p *Peer) waitForRate() error {
+	p.stateLock.Lock()
+	rateLimit := p.remoteRateLimit
+	p.stateLock.Unlock()
+
+	if delay := p.outRate.wait(rateLimit); delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-p.closed:
+			return errProtocolReturned
+		}
+	}
+	p.outRate.add()
+	return nil
+}
+
+// requestVersion asks the peer for its VE message, the Python node only
+// sends it in reply to a VE message without version
+func (p *Peer) requestVersion() error {
+	out := Msg{}
+	out.msg = &generated.LegacyMessage{
+		FuncName: generated.LegacyMessage_VE,
+	}
+	return p.WriteMsg(out)
+}
+
+func (p *Peer) sendAck(size uint32) error {
+	out := Msg{}
+	out.msg = &generated.LegacyMessage{
+		FuncName: generated.LegacyMessage_P2P_ACK,
+		Data: &generated.LegacyMessage_P2PAckData{
+			P2PAckData: &generated.P2PAcknowledgement{BytesProcessed: size},
+		},
+	}
+	return p.WriteMsg(out)
+}
+
+// reply sends msg without blocking the read loop, which must keep reading
+// the acknowledgements the write may be waiting for
+func (p *Peer) reply(msg *generated.LegacyMessage) {
+	p.wg.Add(1)
+	diagnostics.Go("p2p", func() {
+		defer p.wg.Done()
+		if err := p.WriteMsg(Msg{msg: msg}); err != nil {
+			p.log.Debug("Failed to reply to peer", "type", msg.FuncName, "error", err)
+		}
+	})
+}
+
+func (p *Peer) checkGenesis(veData *generated.VEData) error {
+	if !bytes.Equal(veData.GenesisPrevHash, p.config.Dev.Genesis.GenesisPrevHeadehash) {
+		p.log.Debug("Peer is on another network", "genesis", string(veData.GenesisPrevHash))
+		return DiscUselessPeer
+	}
+	return nil
+}
+
+func (p *Peer) handleAck(ackData *generated.P2PAcknowledgement) {
+	if ackData == nil {
+		return
+	}
+	p.flow.ack(uint64(ackData.BytesProcessed))
+}
+
+func (p *Peer) chainState() *generated.NodeChainState {
+	tip := p.chain.Tip()
+	chainState := &generated.NodeChainState{
+		BlockNumber: tip.BlockNumber(),
+		HeaderHash:  tip.HeaderHash(),
+		Timestamp:   uint64(time.Now().Unix()),
+		StateDigest: p.chain.Chain().StateDigest(tip.BlockNumber(), tip.HeaderHash()),
+	}
+	// Encoded as the 32 bytes big endian uint256 of the Python node
+	if difficulty, err := p.chain.CumulativeDifficulty(tip.HeaderHash()); err == nil && len(difficulty.Bytes()) <= 32 {
+		chainState.CumulativeDifficulty = make([]byte, 32)
+		value := difficulty.Bytes()
+		copy(chainState.CumulativeDifficulty[32-len(value):], value)
+	}
+	return chainState
+}
+
+func (p *Peer) sendChainState() {
+	if p.chain == nil {
+		return
+	}
+	p.reply(&generated.LegacyMessage{
+		FuncName: generated.LegacyMessage_CHAINSTATE,
+		Data:     &generated.LegacyMessage_ChainStateData{ChainStateData: p.chainState()},
+	})
+}
+
+func (p *Peer) handleChainState(chainState *generated.NodeChainState) {
+	if chainState == nil {
+		return
+	}
+	p.stateLock.Lock()
+	p.remoteChainState = chainState
+	p.stateLock.Unlock()
+
+	if p.chain != nil {
+		// Peers are told apart by host, a peer reconnecting from another
+		// port doesn't count twice
+		host, _, err := net.SplitHostPort(p.conn.RemoteAddr().String())
+		if err != nil {
+			host = p.conn.RemoteAddr().String()
+		}
+		p.chain.Chain().CheckStateDigest(host, chainState.BlockNumber, chainState.HeaderHash, chainState.StateDigest)
+	}
+}
+
+// RemoteChainState returns the last chain state announced by the peer
+func (p *Peer) RemoteChainState() *generated.NodeChainState {
+	p.stateLock.Lock()
+	defer p.stateLock.Unlock()
+
+	return p.remoteChainState
+}
+
+func (p *Peer) isSynced() bool {
+	tip := p.chain.Tip()
+	return time.Since(time.Unix(int64(tip.Timestamp()), 0)) < syncedTipAge
+}
+
+// handleSync answers the sync state requests of the Python node, which
+// only fetches blocks from peers reporting themselves synced
+func (p *Peer) handleSync(syncData *generated.SYNCData) {
+	if syncData == nil || p.chain == nil {
+		return
+	}
+	if syncData.State != "" {
+		p.stateLock.Lock()
+		p.remoteSynced = syncData.State == syncStateSynced
+		p.stateLock.Unlock()
+		return
+	}
+	if !p.isSynced() {
+		return
+	}
+	p.reply(&generated.LegacyMessage{
+		FuncName: generated.LegacyMessage_SYNC,
+		Data:     &generated.LegacyMessage_SyncData{SyncData: &generated.SYNCData{State: syncStateSynced}},
+	})
+}
+
+// handleFetchBlock replies to FB with the mainchain block at the requested
+// height
+func (p *Peer) handleFetchBlock(fbData *generated.FBData) {
+	if fbData == nil || p.chain == nil {
+		return
+	}
+	block, err := p.chain.GetBlockByNumber(fbData.Index)
+	if err != nil {
+		p.log.Debug("Peer requested unknown block", "number", fbData.Index)
+		return
+	}
+	if block.IsPruned() {
+		p.refusePrunedBlock(block)
+		return
+	}
+	p.reply(&generated.LegacyMessage{
+		FuncName: generated.LegacyMessage_PB,
+		Data:     &generated.LegacyMessage_PbData{PbData: &generated.PBData{Block: block.PBData()}},
+	})
+}
+
+// handleHeaderHashes replies to a request, a NodeHeaderHash without
+// headerhashes, with the mainchain headerhashes from the requested height
+func (p *Peer) handleHeaderHashes(nodeHeaderHash *generated.NodeHeaderHash) {
+	if nodeHeaderHash == nil || len(nodeHeaderHash.Headerhashes) != 0 || p.chain == nil {
+		return
+	}
+	height := p.chain.Height()
+	if nodeHeaderHash.BlockNumber > height {
+		return
+	}
+	reply := &generated.NodeHeaderHash{BlockNumber: nodeHeaderHash.BlockNumber}
+	for blockNumber := nodeHeaderHash.BlockNumber; blockNumber <= height && uint64(len(reply.Headerhashes)) < maxLegacyHeaderHashes; blockNumber++ {
+		block, err := p.chain.GetBlockByNumber(blockNumber)
+		if err != nil {
+			break
+		}
+		reply.Headerhashes = append(reply.Headerhashes, block.HeaderHash())
+	}
+	p.reply(&generated.LegacyMessage{
+		FuncName: generated.LegacyMessage_HEADERHASHES,
+		Data:     &generated.LegacyMessage_NodeHeaderHash{NodeHeaderHash: reply},
+	})
+}
+
+// handleMessageReceived requests the blocks announced by the peer through
+// MR that we don't have yet
+func (p *Peer) handleMessageReceived(mrData *generated.MRData) {
+	if mrData == nil || p.chain == nil || p.filter.Test(mrData.Hash) {
+		return
+	}
+	if mrData.Type != generated.LegacyMessage_BK {
+		return
+	}
+
+	height := p.chain.Height()
+	if mrData.BlockNumber > height+uint64(p.config.Dev.MaxMarginBlocKNumber) {
+		p.log.Debug("Skipping block beyond lead limit", "number", mrData.BlockNumber)
+		return
+	}
+	if mrData.BlockNumber+uint64(p.config.Dev.MinMarginBlockNumber) < height {
+		p.log.Debug("Skipping block beyond the limit", "number", mrData.BlockNumber)
+		return
+	}
+	if _, err := p.chain.GetBlock(mrData.PrevHeaderhash); err != nil {
+		p.log.Debug("Missing parent block", "block", misc.Bin2HStr(mrData.Hash),
+			"parent", misc.Bin2HStr(mrData.PrevHeaderhash))
+		return
+	}
+
+	p.filter.Add(mrData.Hash)
+	p.requested.add(mrData.Hash)
+	p.reply(&generated.LegacyMessage{
+		FuncName: generated.LegacyMessage_SFM,
+		Data:     &generated.LegacyMessage_MrData{MrData: mrData},
+	})
+}
+
+// handleSendFullMessage replies to SFM with the block announced by our MR
+func (p *Peer) handleSendFullMessage(mrData *generated.MRData) {
+	if mrData == nil || p.chain == nil || mrData.Type != generated.LegacyMessage_BK {
+		return
+	}
+	block := p.announced.get(mrData.Hash)
+	if block == nil {
+		var err error
+		block, err = p.chain.GetBlock(mrData.Hash)
+		if err != nil {
+			return
+		}
+		if block.IsPruned() {
+			p.refusePrunedBlock(block)
+			return
+		}
+	}
+	p.reply(&generated.LegacyMessage{
+		FuncName: generated.LegacyMessage_BK,
+		Data:     &generated.LegacyMessage_Block{Block: block.PBData()},
+	})
+}
+
+// refusePrunedBlock disconnects a peer requesting a block whose body was
+// pruned by this relay-only node. The legacy protocol can't answer a
+// request negatively, the peer would wait until its request times out,
+// disconnected it requests the block from another peer.
+func (p *Peer) refusePrunedBlock(block *core.Block) {
+	p.log.Debug("Peer requested a pruned block", "number", block.BlockNumber())
+	p.Disconnect(DiscBlockPruned)
+}
+
+// receivedBlock is a block waiting to be added by the block worker of the
+// peer, and announced to the other peers once added
+type receivedBlock struct {
+	block *core.Block
+}
+
+// handleBlock queues the block requested with SFM for the block worker,
+// validation mustn't hold up the read loop
+func (p *Peer) handleBlock(pbBlock *generated.Block) {
+	if pbBlock == nil || p.chain == nil {
+		return
+	}
+	block, err := core.BlockFromPBData(pbBlock, p.config)
+	if err != nil {
+		p.log.Debug("Peer sent invalid block", "error", err)
+		return
+	}
+	if !p.requested.take(block.HeaderHash()) {
+		p.log.Debug("Dropping block which wasn't requested", "number", block.BlockNumber())
+		return
+	}
+	select {
+	case p.blocks <- &receivedBlock{block: block}:
+		p.filter.Add(block.HeaderHash())
+	default:
+		p.log.Debug("Dropping block, too many blocks waiting to be added", "number", block.BlockNumber())
+	}
+}
+
+// blockLoop adds the blocks received from the peer, and announces the new
+// ones to the other peers
+func (p *Peer) blockLoop() {
+	defer p.wg.Done()
+	for {
+		select {
+		case received := <-p.blocks:
+			added := p.chain.AddBlockFromPeer(received.block, core.BlockFromSync, p.conn.RemoteAddr().String())
+			if added && p.relay != nil {
+				p.relay(blockAnnouncement(received.block), p)
+			}
+		case <-p.closed:
+			return
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package p2p
+
+import (
+	"github.com/cyyber/go-qrl/core"
+	"github.com/cyyber/go-qrl/generated"
+	"sync"
+	"time"
+)
+
+const (
+	frameHeaderSize = 4
+
+	// Size limits for messages that never carry a block or transaction.
+	// Messages carrying blocks are bounded by Dev.MaxReceivableBytes.
+	maxControlMessageSize     = 1024
+	maxPeerListMessageSize    = 64 * 1024
+	maxTransactionMessageSize = 256 * 1024
+	maxHeaderHashesSize       = 1024 * 1024
+)
+
+// MaxMessageSize returns the maximum encoded size allowed for a message type
+func MaxMessageSize(funcName generated.LegacyMessage_FuncName, config *core.Config) uint64 {
+	switch funcName {
+	case generated.LegacyMessage_VE,
+		generated.LegacyMessage_PONG,
+		generated.LegacyMessage_MR,
+		generated.LegacyMessage_SFM,
+		generated.LegacyMessage_FB,
+		generated.LegacyMessage_BH,
+		generated.LegacyMessage_SYNC,
+		generated.LegacyMessage_CHAINSTATE,
+		generated.LegacyMessage_P2P_ACK:
+		return maxControlMessageSize
+	case generated.LegacyMessage_PL:
+		return maxPeerListMessageSize
+	case generated.LegacyMessage_TX,
+		generated.LegacyMessage_LT,
+		generated.LegacyMessage_EPH,
+		generated.LegacyMessage_MT,
+		generated.LegacyMessage_TK,
+		generated.LegacyMessage_TT,
+		generated.LegacyMessage_SL:
+		return maxTransactionMessageSize
+	case generated.LegacyMessage_HEADERHASHES:
+		return maxHeaderHashesSize
+	}
+	return config.Dev.MaxReceivableBytes
+}
+
+// bandwidthMeter tracks the bytes exchanged with a peer over one second
+// windows, so that peers exceeding the configured rate can be throttled
+type bandwidthMeter struct {
+	lock sync.Mutex
+
+	windowStart time.Time
+	windowBytes uint64
+
+	totalBytes uint64
+}
+
+// consume records n bytes and returns how long the caller should wait
+// before processing more data, in order to stay under limit bytes/sec.
+// A limit of 0 disables throttling.
+func (b *bandwidthMeter) consume(n uint64, limit uint64) time.Duration {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := time.Now()
+	if now.Sub(b.windowStart) >= time.Second {
+		b.windowStart = now
+		b.windowBytes = 0
+	}
+
+	b.windowBytes += n
+	b.totalBytes += n
+
+	if limit == 0 || b.windowBytes <= limit {
+		return 0
+	}
+
+	// Time needed for the excess to drain at the allowed rate
+	excess := b.windowBytes - limit
+	return time.Duration(excess * uint64(time.Second) / limit)
+}
+
+func (b *bandwidthMeter) Total() uint64 {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.totalBytes
+}
+
+// DropStats counts peers disconnected per reason
+type DropStats struct {
+	lock sync.Mutex
+
+	counts map[DiscReason]uint64
+}
+
+func (d *DropStats) Add(reason DiscReason) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.counts == nil {
+		d.counts = make(map[DiscReason]uint64)
+	}
+	d.counts[reason]++
+}
+
+func (d *DropStats) Counts() map[string]uint64 {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	result := make(map[string]uint64, len(d.counts))
+	for reason, count := range d.counts {
+		result[reason.String()] = count
+	}
+	return result
+}
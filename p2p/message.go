@@ -1,11 +1,28 @@
 package p2p
 
 import (
+	"github.com/cyyber/go-qrl/core"
 	"github.com/cyyber/go-qrl/generated"
+	"github.com/golang/protobuf/proto"
 	"time"
 )
 
 type Msg struct {
 	msg			*generated.LegacyMessage
 	ReceivedAt	time.Time
+	// Size of the received frame, header included
+	size uint32
+}
+
+// decodeMessage decodes the payload of a frame. On DiscOversizedMessage
+// the decoded message is returned as well, for logging.
+func decodeMessage(buf []byte, config *core.Config) (*generated.LegacyMessage, error) {
+	message := &generated.LegacyMessage{}
+	if err := proto.Unmarshal(buf, message); err != nil {
+		return nil, newPeerError(errInvalidMsg, "%s", err.Error())
+	}
+	if uint64(len(buf)) > MaxMessageSize(message.FuncName, config) {
+		return message, DiscOversizedMessage
+	}
+	return message, nil
 }
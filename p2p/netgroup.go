@@ -0,0 +1,247 @@
+package p2p
+
+import (
+	"errors"
+	"fmt"
+	"github.com/oschwald/maxminddb-golang"
+	"net"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Outbound peers are spread over network groups, so an attacker controlling
+// a single network can't fill every outbound slot and eclipse the node. A
+// group is the autonomous system of the peer when an ASN database is
+// configured, its /16 for IPv4 or /32 for IPv6 otherwise. Local and private
+// addresses are their own group, they aren't a sign of an attack.
+
+var errNetGroupFull = errors.New("outbound peers limit reached for network group")
+
+// asnLookup resolves the autonomous system numbers of IPs from a MaxMind
+// ASN database
+type asnLookup struct {
+	db *maxminddb.Reader
+}
+
+func openASNLookup(filename string) (*asnLookup, error) {
+	db, err := maxminddb.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &asnLookup{db: db}, nil
+}
+
+func (a *asnLookup) asn(ip net.IP) (uint, bool) {
+	var record struct {
+		ASN uint `maxminddb:"autonomous_system_number"`
+	}
+	if err := a.db.Lookup(ip, &record); err != nil || record.ASN == 0 {
+		return 0, false
+	}
+	return record.ASN, true
+}
+
+func (a *asnLookup) close() {
+	a.db.Close()
+}
+
+func isLocalIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() {
+		return true
+	}
+	for _, cidr := range []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "fc00::/7"} {
+		_, network, _ := net.ParseCIDR(cidr)
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// netGroup returns the network group of ip
+func (srv *Server) netGroup(ip net.IP) string {
+	if ip == nil {
+		return "unknown"
+	}
+	if isLocalIP(ip) {
+		return "local:" + ip.String()
+	}
+	if srv.asn != nil {
+		if asn, ok := srv.asn.asn(ip); ok {
+			return fmt.Sprintf("AS%d", asn)
+		}
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return fmt.Sprintf("%d.%d.0.0/16", ip4[0], ip4[1])
+	}
+	return ip.Mask(net.CIDRMask(32, 128)).String() + "/32"
+}
+
+// netGroups counts the connected peers per network group
+type netGroups struct {
+	lock sync.Mutex
+
+	outbound map[string]int
+	inbound  map[string]int
+}
+
+func newNetGroups() *netGroups {
+	return &netGroups{
+		outbound: make(map[string]int),
+		inbound:  make(map[string]int),
+	}
+}
+
+// reserve takes an outbound slot of group, unless limit peers of the group
+// are already connected. A limit of 0 disables the check.
+func (g *netGroups) reserve(group string, limit int) bool {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if limit > 0 && g.outbound[group] >= limit {
+		return false
+	}
+	g.outbound[group]++
+	return true
+}
+
+func (g *netGroups) addInbound(group string) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.inbound[group]++
+}
+
+func (g *netGroups) release(group string, inbound bool) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	counts := g.outbound
+	if inbound {
+		counts = g.inbound
+	}
+	counts[group]--
+	if counts[group] <= 0 {
+		delete(counts, group)
+	}
+}
+
+type NetGroupCount struct {
+	Group    string
+	Outbound int
+	Inbound  int
+}
+
+// PeerDiversity describes how the connected peers are spread over network
+// groups, a low number of outbound groups means the node is easy to eclipse
+type PeerDiversity struct {
+	OutboundGroups int
+	InboundGroups  int
+	// Outbound peers of the most represented group
+	LargestOutboundGroup int
+	// Outbound peers allowed per group, 0 when unlimited
+	MaxOutboundPerGroup int
+	UsingASN            bool
+	Groups              []*NetGroupCount
+}
+
+func (g *netGroups) diversity() *PeerDiversity {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	d := &PeerDiversity{
+		OutboundGroups: len(g.outbound),
+		InboundGroups:  len(g.inbound),
+	}
+	groups := make(map[string]*NetGroupCount)
+	get := func(group string) *NetGroupCount {
+		if _, ok := groups[group]; !ok {
+			groups[group] = &NetGroupCount{Group: group}
+			d.Groups = append(d.Groups, groups[group])
+		}
+		return groups[group]
+	}
+	for group, count := range g.outbound {
+		get(group).Outbound = count
+		if count > d.LargestOutboundGroup {
+			d.LargestOutboundGroup = count
+		}
+	}
+	for group, count := range g.inbound {
+		get(group).Inbound = count
+	}
+	sort.Slice(d.Groups, func(i, j int) bool {
+		return d.Groups[i].Outbound+d.Groups[i].Inbound > d.Groups[j].Outbound+d.Groups[j].Inbound
+	})
+	return d
+}
+
+// PeerDiversity returns the spread of the connected peers over network
+// groups
+func (srv *Server) PeerDiversity() *PeerDiversity {
+	d := srv.groups.diversity()
+	d.MaxOutboundPerGroup = int(srv.config.Settings().Node.MaxOutboundPerNetGroup)
+	d.UsingASN = srv.asn != nil
+	return d
+}
+
+// PeerSummary describes a connected peer
+type PeerSummary struct {
+	Address         string
+	Inbound         bool
+	NetGroup        string
+	ClientVersion   string
+	ProtocolVersion uint32
+	Encrypted       bool
+	Uptime          time.Duration
+	BytesReceived   uint64
+	BytesSent       uint64
+	// Height of the last chain state announced by the peer
+	BlockNumber uint64
+}
+
+func (p *Peer) summary() *PeerSummary {
+	return &PeerSummary{
+		Address:         p.conn.RemoteAddr().String(),
+		Inbound:         p.inbound,
+		NetGroup:        p.netGroup,
+		ClientVersion:   p.ClientVersion(),
+		ProtocolVersion: p.ProtocolVersion(),
+		Encrypted:       p.IsEncrypted(),
+		Uptime:          p.Uptime(),
+		BytesReceived:   p.BytesReceived(),
+		BytesSent:       p.BytesSent(),
+		BlockNumber:     p.RemoteChainState().GetBlockNumber(),
+	}
+}
+
+// Peers lists the connected peers
+func (srv *Server) Peers() []*PeerSummary {
+	// Read-only replicas never start the server
+	if srv.listpeers == nil {
+		return nil
+	}
+	result := make(chan []*PeerSummary, 1)
+	select {
+	case srv.listpeers <- result:
+	case <-srv.exit:
+		return nil
+	}
+	return <-result
+}
+
+// NetworkHeight returns the median of the heights announced by the connected
+// peers, so a single peer announcing a height it doesn't have can't move it
+func (srv *Server) NetworkHeight() uint64 {
+	peers := srv.Peers()
+	if len(peers) == 0 {
+		return 0
+	}
+	heights := make([]uint64, len(peers))
+	for i, summary := range peers {
+		heights[i] = summary.BlockNumber
+	}
+	sort.Slice(heights, func(i, j int) bool { return heights[i] < heights[j] })
+	return heights[len(heights)/2]
+}
@@ -1,16 +1,20 @@
 package p2p
 
 import (
-	"net"
-	"github.com/cyyber/go-qrl/log"
-	"sync"
-	"io"
+	"encoding/binary"
+	"github.com/cyyber/go-qrl/core"
+	"github.com/cyyber/go-qrl/core/pool"
+	"github.com/cyyber/go-qrl/core/transactions"
+	"github.com/cyyber/go-qrl/diagnostics"
 	"github.com/cyyber/go-qrl/generated"
+	"github.com/cyyber/go-qrl/log"
+	"github.com/cyyber/go-qrl/misc"
 	"github.com/golang/protobuf/proto"
+	"golang.org/x/crypto/chacha20poly1305"
+	"io"
+	"net"
+	"sync"
 	"time"
-	"encoding/binary"
-	"github.com/willf/bloom"
-	"github.com/cyyber/go-qrl/core"
 )
 
 type Peer struct {
@@ -21,53 +25,340 @@ type Peer struct {
 	closed chan struct{}
 	disc   chan DiscReason
 	log    log.Logger
-	filter *bloom.BloomFilter
+	filter *seenFilter
 	config *core.Config
+	chain  *core.ChainManager
+	// Sends a message to the other peers, set by the server
+	relay     func(msg *generated.LegacyMessage, from *Peer)
+	announced *announcedBlocks
+	requested requestedBlocks
+	blocks    chan *receivedBlock
+
+	versionSent bool
+	handshake   *handshake
+	session     *secureSession
+
+	protocolVersion uint32
+	clientVersion   string
+	capabilities    uint32
+	// Messages per minute, guarded by stateLock
+	remoteRateLimit uint64
+
+	writeLock sync.Mutex
+	flow      *flowControl
+	// Until the transport is settled only VE messages are written, the
+	// others are held back, guarded by writeLock
+	transportReady bool
+	heldBack       []*generated.LegacyMessage
+
+	// Codec of the connection, codecNone until negotiated
+	codec       byte
+	compression *compressionStats
+
+	inRate  messageRate
+	outRate messageRate
+
+	stateLock        sync.Mutex
+	remoteChainState *generated.NodeChainState
+	remoteSynced     bool
+
+	inMeter  bandwidthMeter
+	outMeter bandwidthMeter
+
+	// Network group of the remote address, for outbound diversity
+	netGroup string
+
+	dropReason  DiscReason
+	connectedAt time.Time
 }
 
-func newPeer(conn *net.Conn, inbound bool, log *log.Logger, filter *bloom.BloomFilter, config *core.Config) *Peer {
-	p := &Peer {
-		conn: *conn,
-		inbound: inbound,
-		log: *log,
-		filter: filter,
-		config: config,
+func newPeer(conn *net.Conn, inbound bool, log *log.Logger, filter *seenFilter, config *core.Config, chain *core.ChainManager, compression *compressionStats) *Peer {
+	p := &Peer{
+		conn:        *conn,
+		inbound:     inbound,
+		log:         *log,
+		filter:      filter,
+		config:      config,
+		chain:       chain,
+		flow:        newFlowControl(),
+		compression: compression,
+		blocks:      make(chan *receivedBlock, blockQueueSize),
+		closed:      make(chan struct{}),
+		disc:        make(chan DiscReason),
+		connectedAt: time.Now(),
+	}
+
+	if config.User.Node.EnableEncryptedTransport {
+		h, err := newHandshake()
+		if err != nil {
+			p.log.Warn("Failed to generate handshake key, falling back to plaintext", "error", err)
+		} else {
+			p.handshake = h
+		}
 	}
 	return p
 }
 
+func (p *Peer) Uptime() time.Duration {
+	return time.Since(p.connectedAt)
+}
+
+func (p *Peer) BytesReceived() uint64 {
+	return p.inMeter.Total()
+}
+
+func (p *Peer) BytesSent() uint64 {
+	return p.outMeter.Total()
+}
+
+func (p *Peer) IsEncrypted() bool {
+	p.writeLock.Lock()
+	defer p.writeLock.Unlock()
+
+	return p.session != nil
+}
+
+func (p *Peer) features() uint32 {
+	var features uint32
+	if p.handshake != nil {
+		features |= FeatureEncryptedTransport
+	}
+	features |= compressionFeatures(p.config.User.Node.Compression)
+	return features
+}
+
+func (p *Peer) SendVersion() error {
+	out := Msg{}
+	veData := generated.VEData{
+		Version:         clientVersionString(core.Version),
+		GenesisPrevHash: p.config.Dev.Genesis.GenesisPrevHeadehash,
+		RateLimit:       uint64(p.config.Settings().Node.PeerRateLimit),
+		Features:        p.features(),
+		ProtocolVersion: ProtocolVersion,
+	}
+	if p.handshake != nil {
+		veData.HandshakePk = p.handshake.PublicKey()
+	}
+	out.msg = &generated.LegacyMessage{
+		FuncName: generated.LegacyMessage_VE,
+		Data: &generated.LegacyMessage_VeData{
+			VeData: &veData,
+		},
+	}
+	err := p.WriteMsg(out)
+	if err == nil {
+		p.versionSent = true
+	}
+	return err
+}
+
+// establishSession switches the connection to the encrypted transport once
+// both sides have exchanged VE messages advertising the feature. Legacy
+// peers never set the feature bit and stay on plaintext. The session is
+// unauthenticated, see handshakeProtocolName.
+func (p *Peer) establishSession(veData *generated.VEData) error {
+	if p.IsEncrypted() || p.handshake == nil {
+		return nil
+	}
+	if !p.Supports(FeatureEncryptedTransport) || len(veData.HandshakePk) == 0 {
+		p.log.Debug("Peer does not support encrypted transport, using plaintext")
+		return nil
+	}
+
+	session, err := p.handshake.deriveSession(veData.HandshakePk)
+	if err != nil {
+		return newPeerError(errInvalidHandshake, "%s", err.Error())
+	}
+
+	p.writeLock.Lock()
+	p.session = session
+	p.writeLock.Unlock()
+	// Ephemeral private key is no longer needed once the session keys are derived
+	p.handshake = nil
+	p.log.Debug("Encrypted transport established")
+	return nil
+}
+
+// releaseTransport marks the transport settled once our VE is sent and the
+// session and codec are switched, and writes the messages held back. Our
+// VE precedes them on the wire, the peer has switched when it reads them.
+func (p *Peer) releaseTransport() {
+	p.writeLock.Lock()
+	p.transportReady = true
+	heldBack := p.heldBack
+	p.heldBack = nil
+	p.writeLock.Unlock()
+
+	for _, msg := range heldBack {
+		if err := p.WriteMsg(Msg{msg: msg}); err != nil {
+			p.log.Debug("Failed to send held back message", "type", msg.FuncName, "error", err)
+			return
+		}
+	}
+}
+
+// holdBack queues msg until the transport is settled, it returns false
+// once it is. A frame written before would reach the peer in plaintext or
+// uncompressed after it switched. Beyond maxHeldBack messages are dropped.
+func (p *Peer) holdBack(msg *generated.LegacyMessage) bool {
+	p.writeLock.Lock()
+	defer p.writeLock.Unlock()
+
+	if p.transportReady {
+		return false
+	}
+	if len(p.heldBack) < maxHeldBack {
+		p.heldBack = append(p.heldBack, msg)
+	} else {
+		p.log.Debug("Dropping message sent before the handshake", "type", msg.FuncName)
+	}
+	return true
+}
+
 func (p *Peer) WriteMsg(msg Msg) error {
+	if msg.msg.FuncName != generated.LegacyMessage_VE && p.holdBack(msg.msg) {
+		return nil
+	}
+
 	data, err := proto.Marshal(msg.msg)
 	if err != nil {
 		p.log.Error("Error Parsing Data")
 		return err
 	}
 
-	bs := make([]byte, 4)
+	flowControlled := isFlowControlled(msg.msg.FuncName)
+	if flowControlled {
+		if err := p.waitForRate(); err != nil {
+			return err
+		}
+	}
+
+	deadline := time.NewTimer(time.Duration(p.config.User.Node.PeerWriteTimeout) * time.Second)
+	defer deadline.Stop()
+
+	// Frames must be compressed, reserved, encrypted and written in the same
+	// order under the write lock, the flow control accounts for the frame as
+	// it goes on the wire, which is what the peer acknowledges. The lock is
+	// released while waiting for an ack, the read loop writes its own acks.
+	compressed := false
+	for {
+		p.writeLock.Lock()
+		if !compressed {
+			if p.codec != codecNone {
+				data = p.compress(data)
+			}
+			compressed = true
+		}
+		size := uint64(frameHeaderSize + len(data))
+		if p.session != nil {
+			size += chacha20poly1305.Overhead
+		}
+		if !flowControlled || p.flow.tryReserve(size, p.config.Dev.MaxBytesOut) {
+			err := p.writeFrame(data)
+			p.writeLock.Unlock()
+			return err
+		}
+		p.writeLock.Unlock()
+
+		select {
+		case <-p.flow.acked:
+		case <-p.closed:
+			return errProtocolReturned
+		case <-deadline.C:
+			return DiscReadTimeout
+		}
+	}
+}
+
+// writeFrame encrypts data and writes it as a frame, the write lock must be
+// held
+func (p *Peer) writeFrame(data []byte) error {
+	var err error
+	if p.session != nil {
+		data, err = p.session.Encrypt(data)
+		if err != nil {
+			p.log.Error("Error encrypting message", "error", err)
+			return err
+		}
+	}
+
+	bs := make([]byte, frameHeaderSize)
 	binary.BigEndian.PutUint32(bs, uint32(len(data)))
 	out := append(bs, data...)
 
+	if delay := p.outMeter.consume(uint64(len(out)), p.config.Settings().Node.PeerMaxBytesPerSecond); delay > 0 {
+		time.Sleep(delay)
+	}
+
+	p.conn.SetWriteDeadline(time.Now().Add(time.Duration(p.config.User.Node.PeerWriteTimeout) * time.Second))
 	_, err = p.conn.Write(out)
 	if err != nil {
 		p.log.Error("Error while writing message on socket", "error", err)
+		return err
 	}
 	return nil
 }
 
 func (p *Peer) ReadMsg() (msg Msg, err error){
-	buf := make([]byte, 4)
+	nodeConfig := p.config.Settings().Node
+
+	// Idle peers are allowed to stay silent until the idle timeout,
+	// but once a frame has started it must be fully received within
+	// PeerReadTimeout, so that peers trickling bytes get dropped.
+	p.conn.SetReadDeadline(time.Now().Add(time.Duration(nodeConfig.PeerIdleTimeout) * time.Second))
+	buf := make([]byte, frameHeaderSize)
 	if _, err := io.ReadFull(p.conn, buf); err != nil {
-		return msg, err
+		return msg, timeoutToDiscReason(err)
 	}
 	size := convertBytesToLong(buf)
+	if uint64(size) > p.config.Dev.MaxReceivableBytes {
+		p.log.Warn("Peer sent oversized frame", "size", size)
+		return msg, DiscOversizedMessage
+	}
+
+	p.conn.SetReadDeadline(time.Now().Add(time.Duration(nodeConfig.PeerReadTimeout) * time.Second))
 	buf = make([]byte, size)
 	if _, err := io.ReadFull(p.conn, buf); err != nil {
+		return msg, timeoutToDiscReason(err)
+	}
+
+	if delay := p.inMeter.consume(uint64(size+frameHeaderSize), nodeConfig.PeerMaxBytesPerSecond); delay > 0 {
+		// Peers flooding far beyond the allowed rate are dropped instead of throttled
+		if delay > time.Duration(nodeConfig.PeerReadTimeout)*time.Second {
+			return msg, DiscBandwidthExceeded
+		}
+		time.Sleep(delay)
+	}
+
+	if p.session != nil {
+		buf, err = p.session.Decrypt(buf)
+		if err != nil {
+			return msg, newPeerError(errInvalidMsg, "%s", err.Error())
+		}
+	}
+	if p.codec != codecNone {
+		buf, err = p.decompress(buf)
+		if err != nil {
+			return msg, newPeerError(errInvalidMsg, "%s", err.Error())
+		}
+	}
+	message, err := decodeMessage(buf, p.config)
+	if err != nil {
+		if err == DiscOversizedMessage {
+			p.log.Warn("Peer sent oversized message", "type", message.FuncName, "size", len(buf))
+		}
 		return msg, err
 	}
-	message := &generated.LegacyMessage{}
-	err = proto.Unmarshal(buf, message)
 	msg.msg = message
-	return msg, err
+	msg.size = size + frameHeaderSize
+	return msg, nil
+}
+
+func timeoutToDiscReason(err error) error {
+	if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+		return DiscReadTimeout
+	}
+	return err
 }
 
 func (p *Peer) readLoop(errc chan<- error) {
@@ -81,16 +372,45 @@ func (p *Peer) readLoop(errc chan<- error) {
 		}
 		msg.ReceivedAt = time.Now()
 		p.log.Debug("Received msg")
+		if isFlowControlled(msg.msg.FuncName) {
+			if limit := uint64(p.config.Settings().Node.PeerRateLimit); limit > 0 && p.inRate.add() > 2*limit {
+				errc <- DiscBandwidthExceeded
+				return
+			}
+		}
 		if err = p.handle(msg); err != nil {
 			errc <- err
 			return
 		}
+		if isFlowControlled(msg.msg.FuncName) {
+			if err = p.sendAck(msg.size); err != nil {
+				errc <- err
+				return
+			}
+		}
 	}
 }
 
 func (p *Peer) pingLoop() {
 	defer p.wg.Done()
 
+	// The chain state is announced again periodically, for the peer to
+	// compare our state digest with its own, unless the period is 0
+	period := p.config.User.ChainStateBroadcastPeriod
+	if period == 0 {
+		<-p.closed
+		return
+	}
+	ticker := time.NewTicker(time.Duration(period) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.sendChainState()
+		case <-p.closed:
+			return
+		}
+	}
 }
 
 func (p* Peer) handle(msg Msg) error {
@@ -98,74 +418,121 @@ func (p* Peer) handle(msg Msg) error {
 	case generated.LegacyMessage_VE:
 		p.log.Debug("Received VE MSG")
 		if msg.msg.GetVeData() == nil {
-			out := Msg{}
-			veData := generated.VEData{
-				Version: "",
-				GenesisPrevHash: []byte("0"),
-				RateLimit: 100,
-			}
-			out.msg = &generated.LegacyMessage {
-				FuncName: generated.LegacyMessage_VE,
-				Data: &generated.LegacyMessage_VeData{
-					VeData: &veData,
-				},
-			}
-			err := p.WriteMsg(out)
-			return err
+			return p.SendVersion()
 		}
 		veData := msg.msg.GetVeData()
 		p.log.Info("", "version:", veData.Version,
-			"GenesisPrevHash:", veData.GenesisPrevHash, "RateLimit:", veData.RateLimit)
+			"GenesisPrevHash:", veData.GenesisPrevHash, "RateLimit:", veData.RateLimit,
+			"Features:", FeatureString(veData.Features), "Protocol:", veData.ProtocolVersion)
+		if err := p.checkGenesis(veData); err != nil {
+			return err
+		}
+		p.stateLock.Lock()
+		p.remoteRateLimit = veData.RateLimit
+		p.stateLock.Unlock()
+		if err := p.negotiate(veData); err != nil {
+			return err
+		}
+
+		// Our VE must go out in plaintext before switching the transport
+		if !p.versionSent {
+			if err := p.SendVersion(); err != nil {
+				return err
+			}
+		}
+		if err := p.establishSession(veData); err != nil {
+			return err
+		}
+		p.negotiateCompression()
+		p.releaseTransport()
+		p.sendChainState()
 
 	case generated.LegacyMessage_PL:
 		p.log.Debug("Received PL MSG")
 	case generated.LegacyMessage_PONG:
 		p.log.Debug("Received PONG MSG")
 	case generated.LegacyMessage_MR:
-		mrData := msg.msg.GetMrData()
-		if p.filter.Test(mrData.Hash) {
-			return nil
-		}
-
-		switch mrData.Type {
-		case generated.LegacyMessage_BK:
-			if mrData.BlockNumber > chainHeight + p.config.Dev.MaxMarginBlocKNumber {
-				p.log.Debug("Skipping block #%s as beyond lead limit", "Block #", mrData.BlockNumber)
-				return nil
-			}
-			if mrData.BlockNumber < chainHeight - p.config.Dev.MinMarginBlockNumber {
-				p.log.Debug("'Skipping block #%s as beyond the limit", "Block #", mrData.BlockNumber)
-				return nil
-			}
-			if !IsBlockExist(mrData.PrevHeaderhash) {
-				p.log.Debug("Missing Parent Block", "Block:", mrData.Hash,
-					"Parent Block ", mrData.PrevHeaderhash)
-				return nil
-			}
-			// Request for full message
-			// Check if its already being feeded by any other peer
-		case generated.LegacyMessage_TX:
-			// Check Transaction pool Size,
-			// if full then ignore
-		default:
-			//connection lost
-		}
+		p.handleMessageReceived(msg.msg.GetMrData())
 	case generated.LegacyMessage_SFM:
+		p.handleSendFullMessage(msg.msg.GetMrData())
 	case generated.LegacyMessage_BK:
+		p.handleBlock(msg.msg.GetBlock())
 	case generated.LegacyMessage_FB:
+		p.handleFetchBlock(msg.msg.GetFbData())
 	case generated.LegacyMessage_PB:
+		p.log.Debug("Dropping PB, no block was fetched with FB")
 	case generated.LegacyMessage_BH:
 	case generated.LegacyMessage_TX:
+		return p.handleTransaction(msg.msg)
 	case generated.LegacyMessage_LT:
+		return p.handleTransaction(msg.msg)
 	case generated.LegacyMessage_EPH:
 	case generated.LegacyMessage_MT:
+		return p.handleTransaction(msg.msg)
 	case generated.LegacyMessage_TK:
+		return p.handleTransaction(msg.msg)
 	case generated.LegacyMessage_TT:
+		return p.handleTransaction(msg.msg)
 	case generated.LegacyMessage_SL:
+		return p.handleTransaction(msg.msg)
 	case generated.LegacyMessage_SYNC:
+		p.handleSync(msg.msg.GetSyncData())
 	case generated.LegacyMessage_CHAINSTATE:
+		p.handleChainState(msg.msg.GetChainStateData())
 	case generated.LegacyMessage_HEADERHASHES:
+		p.handleHeaderHashes(msg.msg.GetNodeHeaderHash())
 	case generated.LegacyMessage_P2P_ACK:
+		p.handleAck(msg.msg.GetP2PAckData())
+	}
+	return nil
+}
+
+// legacyTransaction returns the transaction carried by a TX, LT, MT, TK,
+// TT or SL message
+func legacyTransaction(msg *generated.LegacyMessage) *generated.Transaction {
+	switch msg.FuncName {
+	case generated.LegacyMessage_TX:
+		return msg.GetTxData()
+	case generated.LegacyMessage_LT:
+		return msg.GetLtData()
+	case generated.LegacyMessage_MT:
+		return msg.GetMtData()
+	case generated.LegacyMessage_TK:
+		return msg.GetTkData()
+	case generated.LegacyMessage_TT:
+		return msg.GetTtData()
+	case generated.LegacyMessage_SL:
+		return msg.GetSlData()
+	}
+	return nil
+}
+
+// handleTransaction adds the transaction to the pool and relays it to the
+// other peers. Transactions below the relay fee policy are dropped before
+// they reach the pool.
+func (p *Peer) handleTransaction(msg *generated.LegacyMessage) error {
+	protoTx := legacyTransaction(msg)
+	if protoTx == nil || p.chain == nil || p.config.User.SeedMode {
+		return nil
+	}
+	tx := transactions.ProtoToTransaction(protoTx)
+	if tx == nil {
+		return nil
+	}
+	if p.filter.TestAndAdd(tx.Txhash()) {
+		return nil
+	}
+
+	if err := pool.CheckRelayFee(tx, p.config); err != nil {
+		p.log.Debug("Not relaying transaction", "txhash", misc.Bin2HStr(tx.Txhash()), "reason", err)
+		return nil
+	}
+	if err := p.chain.Chain().SubmitTransactionFromPeer(tx, p.conn.RemoteAddr().String()); err != nil {
+		p.log.Debug("Transaction not added to the pool", "txhash", misc.Bin2HStr(tx.Txhash()), "reason", err)
+		return nil
+	}
+	if p.relay != nil {
+		p.relay(msg, p)
 	}
 	return nil
 }
@@ -177,9 +544,14 @@ func (p *Peer) run() (remoteRequested bool, err error) {
 		readErr	 = make(chan error, 1)
 		reason 	 DiscReason
 	)
-	p.wg.Add(2)
-	go p.readLoop(readErr)
-	go p.pingLoop()
+	p.wg.Add(3)
+	diagnostics.Go("p2p", func() { p.readLoop(readErr) })
+	diagnostics.Go("p2p", p.pingLoop)
+	diagnostics.Go("p2p", p.blockLoop)
+
+	if err := p.requestVersion(); err != nil {
+		p.log.Debug("Failed to request version", "error", err)
+	}
 
 loop:
 	for {
@@ -190,14 +562,18 @@ loop:
 			}
 			writeStart <- struct{}{}
 		case err = <-readErr:
-			if r, ok := err.(DiscReason); ok {
-				break loop
-				reason = r
-			} else {
-				reason = DiscNetworkError
+			reason = discReasonForError(err)
+			if _, ok := err.(DiscReason); !ok {
+				if _, ok := err.(*peerError); !ok {
+					reason = DiscNetworkError
+				}
 			}
+			break loop
+		case reason = <-p.disc:
+			break loop
 		}
 	}
+	p.dropReason = reason
 	close(p.closed)
 	p.close(reason)
 	p.wg.Wait()
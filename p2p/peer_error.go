@@ -8,11 +8,13 @@ import (
 const (
 	errInvalidMsgCode = iota
 	errInvalidMsg
+	errInvalidHandshake
 )
 
 var errorToString = map[int]string{
 	errInvalidMsgCode: "invalid message code",
 	errInvalidMsg:     "invalid message",
+	errInvalidHandshake: "invalid handshake",
 }
 
 type peerError struct {
@@ -53,6 +55,10 @@ const (
 	DiscUnexpectedIdentity
 	DiscSelf
 	DiscReadTimeout
+	DiscOversizedMessage
+	DiscBandwidthExceeded
+	DiscBanned
+	DiscBlockPruned
 	DiscSubprotocolError = 0x10
 )
 
@@ -69,6 +75,10 @@ var discReasonToString = [...]string{
 	DiscUnexpectedIdentity:  "unexpected identity",
 	DiscSelf:                "connected to self",
 	DiscReadTimeout:         "read timeout",
+	DiscOversizedMessage:    "oversized message",
+	DiscBandwidthExceeded:   "bandwidth exceeded",
+	DiscBanned:              "banned",
+	DiscBlockPruned:         "requested block pruned",
 	DiscSubprotocolError:    "subprotocol error",
 }
 
@@ -93,7 +103,7 @@ func discReasonForError(err error) DiscReason {
 	peerError, ok := err.(*peerError)
 	if ok {
 		switch peerError.code {
-		case errInvalidMsgCode, errInvalidMsg:
+		case errInvalidMsgCode, errInvalidMsg, errInvalidHandshake:
 			return DiscProtocolError
 		default:
 			return DiscSubprotocolError
@@ -0,0 +1,105 @@
+package p2p
+
+import (
+	"fmt"
+	"github.com/cyyber/go-qrl/generated"
+	"strings"
+)
+
+// The peer protocol is versioned by VEData.ProtocolVersion, optional
+// features are negotiated through the VEData.Features capability bitmask.
+// A feature is only used on a connection once both sides advertise it, so
+// new features can be rolled out without breaking older peers. Unknown bits
+// are ignored.
+//
+// Python nodes and go-qrl nodes predating versioning leave ProtocolVersion
+// unset, they are treated as legacyProtocolVersion.
+
+const (
+	ProtocolVersion    uint32 = 2
+	MinProtocolVersion uint32 = 1
+
+	legacyProtocolVersion uint32 = 1
+)
+
+// Capability bits besides FeatureEncryptedTransport. They are reserved so
+// every implementation agrees on them, a node only advertises the ones it
+// implements.
+const (
+	FeatureCompactBlocks uint32 = 1 << 1
+	FeatureBloomFilters  uint32 = 1 << 2
+	FeatureHeadersFirst  uint32 = 1 << 3
+	FeatureSnappy        uint32 = 1 << 4
+	FeatureZstd          uint32 = 1 << 5
+)
+
+var featureNames = []struct {
+	feature uint32
+	name    string
+}{
+	{FeatureEncryptedTransport, "encrypted-transport"},
+	{FeatureCompactBlocks, "compact-blocks"},
+	{FeatureBloomFilters, "bloom-filters"},
+	{FeatureHeadersFirst, "headers-first"},
+	{FeatureSnappy, "snappy"},
+	{FeatureZstd, "zstd"},
+}
+
+// FeatureString lists the names of the known features set in features
+func FeatureString(features uint32) string {
+	var names []string
+	for _, f := range featureNames {
+		if features&f.feature != 0 {
+			names = append(names, f.name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ",")
+}
+
+func remoteProtocolVersion(veData *generated.VEData) uint32 {
+	if veData.ProtocolVersion == 0 {
+		return legacyProtocolVersion
+	}
+	return veData.ProtocolVersion
+}
+
+// negotiate records the protocol version and the capabilities shared with
+// the peer, it fails for peers too old to talk to
+func (p *Peer) negotiate(veData *generated.VEData) error {
+	version := remoteProtocolVersion(veData)
+	if version < MinProtocolVersion {
+		return DiscIncompatibleVersion
+	}
+
+	p.protocolVersion = version
+	p.clientVersion = veData.Version
+	p.capabilities = p.features() & veData.Features
+	p.log.Debug("Negotiated peer protocol",
+		"protocol", p.protocolVersion,
+		"client", p.clientVersion,
+		"capabilities", FeatureString(p.capabilities))
+	return nil
+}
+
+// ProtocolVersion returns the protocol version announced by the peer, 0
+// until its VE message was received
+func (p *Peer) ProtocolVersion() uint32 {
+	return p.protocolVersion
+}
+
+// ClientVersion returns the free form client version announced by the peer
+func (p *Peer) ClientVersion() string {
+	return p.clientVersion
+}
+
+// Supports tells whether both sides advertised feature
+func (p *Peer) Supports(feature uint32) bool {
+	return p.capabilities&feature != 0
+}
+
+func clientVersionString(buildVersion string) string {
+	return fmt.Sprintf("go-qrl/%s", buildVersion)
+}
@@ -1,22 +1,29 @@
 package p2p
 
 import (
-	"net"
-	"sync"
 	"errors"
-	"github.com/cyyber/go-qrl/log"
-	"github.com/cyyber/go-qrl/core"
 	"fmt"
-	"github.com/willf/bloom"
+	"github.com/cyyber/go-qrl/core"
+	"github.com/cyyber/go-qrl/diagnostics"
+	"github.com/cyyber/go-qrl/generated"
+	"github.com/cyyber/go-qrl/log"
+	"github.com/cyyber/go-qrl/misc"
+	"net"
+	"path"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type conn struct {
-	fd		net.Conn
-	inbound	bool
+	fd      net.Conn
+	inbound bool
+	group   string
 }
 
 type Server struct {
 	config *core.Config
+	chain  *core.ChainManager
 
 	listener net.Listener
 	lock     sync.Mutex
@@ -28,8 +35,31 @@ type Server struct {
 	exit    chan struct{}
 	addpeer chan *conn
 	delpeer chan peerDrop
+	kickip  chan string
+	relay   chan *relayMsg
+
+	listpeers chan chan []*PeerSummary
+
+	filter    *seenFilter
+	announced announcedBlocks
 
-	filter *bloom.BloomFilter
+	connFilter *misc.CIDRFilter
+	banList    *BanList
+
+	dropStats   DropStats
+	compression compressionStats
+
+	groups *netGroups
+	asn    *asnLookup
+
+	peerCount int32
+}
+
+// relayMsg is sent to every peer except from, the peer it was received
+// from if any
+type relayMsg struct {
+	msg  *generated.LegacyMessage
+	from *Peer
 }
 
 type peerDrop struct {
@@ -38,6 +68,13 @@ type peerDrop struct {
 	requested bool // true if signaled by the peer
 }
 
+// SetChainManager gives the peers access to the chain, to serve blocks and
+// chain state and to add the blocks they receive. It must be called before
+// Start.
+func (srv *Server) SetChainManager(chain *core.ChainManager) {
+	srv.chain = chain
+}
+
 func (srv *Server) Start(log log.Logger, config *core.Config) (err error) {
 	srv.lock.Lock()
 	defer srv.lock.Unlock()
@@ -49,19 +86,40 @@ func (srv *Server) Start(log log.Logger, config *core.Config) (err error) {
 	srv.exit = make(chan struct{})
 	srv.addpeer = make(chan *conn)
 	srv.delpeer = make(chan peerDrop)
+	srv.kickip = make(chan string)
+	srv.relay = make(chan *relayMsg)
+	srv.listpeers = make(chan chan []*PeerSummary)
 	srv.log = log
+	srv.groups = newNetGroups()
+
+	if filename := config.User.Node.ASNDatabase; filename != "" {
+		if srv.asn, err = openASNLookup(filename); err != nil {
+			srv.log.Warn("Failed to open ASN database, grouping peers by IP prefix", "file", filename, "error", err)
+		}
+	}
+
+	srv.filter = newSeenFilter(200000, 5)
+
+	srv.connFilter, err = misc.NewCIDRFilter(config.User.Node.AllowedCIDRs, config.User.Node.DeniedCIDRs)
+	if err != nil {
+		return err
+	}
+	srv.banList = NewBanList(path.Join(config.DataDir(), config.Dev.BannedPeersFilename))
+	if err := srv.banList.Load(); err != nil {
+		srv.log.Warn("Failed to load banned peers", "error", err)
+	}
 
-	srv.filter = bloom.New(200000, 5)
 	if err := srv.startListening(); err != nil {
 		return err
 	}
 	srv.running = true
-	go srv.run()
+	srv.loopWG.Add(2)
+	diagnostics.Go("p2p", srv.run)
+	diagnostics.Go("p2p", srv.bootstrapConnections)
 	return nil
 }
 
 func (srv *Server) listenLoop(listener net.Listener) {
-	srv.loopWG.Add(1)
 	defer srv.loopWG.Done()
 	for {
 		c, err := listener.Accept()
@@ -70,8 +128,13 @@ func (srv *Server) listenLoop(listener net.Listener) {
 			srv.log.Error("Read ERROR", "Reason", err)
 			return
 		}
+		if ip := misc.RemoteIP(c.RemoteAddr()); !srv.isAllowed(ip) {
+			srv.log.Debug("Rejected peer", "ip", ip)
+			c.Close()
+			continue
+		}
 		srv.log.Debug("called addpeer")
-		srv.addpeer <- &conn{c, true}
+		srv.addpeer <- &conn{c, true, srv.netGroup(misc.RemoteIP(c.RemoteAddr()))}
 	}
 }
 
@@ -87,6 +150,9 @@ func (srv *Server) Stop() {
 	}
 	close(srv.exit)
 	srv.loopWG.Wait()
+	if srv.asn != nil {
+		srv.asn.close()
+	}
 }
 
 func (srv *Server) startListening() error {
@@ -96,7 +162,8 @@ func (srv *Server) startListening() error {
 		return err
 	}
 	srv.listener = listener
-	go srv.listenLoop(listener)
+	srv.loopWG.Add(1)
+	diagnostics.Go("p2p", func() { srv.listenLoop(listener) })
 	return nil
 }
 
@@ -106,7 +173,6 @@ func (srv *Server) run() {
 		inboundCount = 0
 	)
 
-	srv.loopWG.Add(1)
 	defer srv.loopWG.Done()
 
 running:
@@ -116,21 +182,67 @@ running:
 			srv.log.Debug("Quitting!!!")
 			break running
 		case c := <-srv.addpeer:
+			// Only seed nodes cap their inbound peers, MaxPeersLimit
+			// isn't enforced on the other nodes
+			if c.inbound && srv.config.User.SeedMode && len(peers) >= srv.config.MaxPeers() {
+				srv.log.Debug("Rejected peer, peer limit reached", "addr", c.fd.RemoteAddr())
+				c.fd.Close()
+				continue
+			}
 			srv.log.Debug("Adding peer", "addr", c.fd.RemoteAddr())
-			p := newPeer(&c.fd, c.inbound, &srv.log, srv.filter, srv.config)
-			go srv.runPeer(p)
+			p := newPeer(&c.fd, c.inbound, &srv.log, srv.filter, srv.config, srv.chain, &srv.compression)
+			p.netGroup = c.group
+			p.relay = srv.relayFrom
+			p.announced = &srv.announced
+			diagnostics.Go("p2p", func() { srv.runPeer(p) })
 			peers[c.fd.RemoteAddr().String()] = p
+			atomic.StoreInt32(&srv.peerCount, int32(len(peers)))
 			if p.inbound {
 				inboundCount++
+				srv.groups.addInbound(p.netGroup)
 			}
 		case pd := <-srv.delpeer:
-			pd.log.Debug("Removing Peer", "err", pd.err)
+			pd.log.Debug("Removing Peer", "err", pd.err, "reason", pd.dropReason)
+			srv.dropStats.Add(pd.dropReason)
+			if shouldBan(pd.dropReason) {
+				srv.banMisbehavingPeer(pd.Peer)
+			}
 			delete(peers, pd.conn.RemoteAddr().String())
+			atomic.StoreInt32(&srv.peerCount, int32(len(peers)))
 			if pd.inbound {
 				inboundCount--
 			}
+			srv.groups.release(pd.netGroup, pd.inbound)
+		case r := <-srv.relay:
+			for _, p := range peers {
+				if p == r.from {
+					continue
+				}
+				p := p
+				diagnostics.Go("p2p", func() {
+					if err := p.WriteMsg(Msg{msg: r.msg}); err != nil {
+						p.log.Debug("Failed to relay message", "type", r.msg.FuncName, "error", err)
+					}
+				})
+			}
+		case result := <-srv.listpeers:
+			summaries := make([]*PeerSummary, 0, len(peers))
+			for _, p := range peers {
+				summaries = append(summaries, p.summary())
+			}
+			result <- summaries
+		case ip := <-srv.kickip:
+			for _, p := range peers {
+				if misc.RemoteIP(p.conn.RemoteAddr()).String() == ip {
+					p.Disconnect(DiscBanned)
+				}
+			}
 		}
 	}
+	if err := srv.saveAnchors(peers); err != nil {
+		srv.log.Warn("Failed to save anchor peers", "error", err)
+	}
+
 	for _, p := range peers {
 		p.Disconnect(DiscQuitting)
 	}
@@ -141,8 +253,94 @@ running:
 	}
 }
 
+// BroadcastBlock announces block to every connected peer, they request it
+// with SFM
+func (srv *Server) BroadcastBlock(block *core.Block) {
+	srv.lock.Lock()
+	running := srv.running
+	srv.lock.Unlock()
+	if !running {
+		return
+	}
+
+	// Peers announcing it back must not make us request it again
+	srv.filter.Add(block.HeaderHash())
+	srv.announced.add(block)
+	srv.relayFrom(blockAnnouncement(block), nil)
+}
+
+// relayFrom sends msg to every connected peer but from
+func (srv *Server) relayFrom(msg *generated.LegacyMessage, from *Peer) {
+	select {
+	case srv.relay <- &relayMsg{msg: msg, from: from}:
+	case <-srv.exit:
+	}
+}
+
+func (srv *Server) PeerCount() int {
+	return int(atomic.LoadInt32(&srv.peerCount))
+}
+
+// DroppedPeers returns the number of peers disconnected, grouped by reason
+func (srv *Server) DroppedPeers() map[string]uint64 {
+	return srv.dropStats.Counts()
+}
+
+// CompressionStats returns the bytes saved by compressing messages and the
+// time spent on it, over all peers
+func (srv *Server) CompressionStats() CompressionStats {
+	return srv.compression.snapshot()
+}
+
 func (srv *Server) runPeer(p *Peer) {
 	remoteRequested, err := p.run()
 
 	srv.delpeer <- peerDrop{p, err, remoteRequested}
+}
+func (srv *Server) isAllowed(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	return srv.connFilter.Allowed(ip) && !srv.banList.IsBanned(ip)
+}
+
+// shouldBan returns true for the drop reasons caused by a misbehaving peer
+func shouldBan(reason DiscReason) bool {
+	switch reason {
+	case DiscProtocolError, DiscOversizedMessage, DiscInvalidIdentity:
+		return true
+	}
+	return false
+}
+
+func (srv *Server) banMisbehavingPeer(p *Peer) {
+	ip := misc.RemoteIP(p.conn.RemoteAddr())
+	if ip == nil {
+		return
+	}
+	duration := time.Duration(srv.config.Settings().Node.BanMinutes) * time.Minute
+	srv.log.Info("Banning peer", "ip", ip, "reason", p.dropReason, "duration", duration)
+	if err := srv.banList.Ban(ip, duration); err != nil {
+		srv.log.Warn("Failed to save banned peers", "error", err)
+	}
+}
+
+// BanPeer bans ip for duration and disconnects it if connected
+func (srv *Server) BanPeer(ip net.IP, duration time.Duration) error {
+	if err := srv.banList.Ban(ip, duration); err != nil {
+		return err
+	}
+	select {
+	case srv.kickip <- ip.String():
+	case <-srv.exit:
+	}
+	return nil
+}
+
+func (srv *Server) UnbanPeer(ip net.IP) error {
+	return srv.banList.Unban(ip)
+}
+
+func (srv *Server) BannedPeers() []*generated.PeerInfo {
+	return srv.banList.List()
 }
\ No newline at end of file
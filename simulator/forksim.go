@@ -0,0 +1,177 @@
+package simulator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/cyyber/go-qrl/core"
+	"github.com/cyyber/go-qrl/misc"
+	"math/big"
+)
+
+// A fork scenario builds competing branches on top of a common chain, each
+// on its own isolated node, then delivers every block to a judge node and
+// checks which tip it settled on. Branches differ by length and by block
+// spacing, which changes their difficulty unless the node configuration
+// fixes it, and may end with an invalid block. Scenarios run the same way
+// from gqrl devtool fork-sim and from tests.
+
+var ErrUnknownBranch = errors.New("unknown branch")
+
+type Branch struct {
+	Name string
+	// Blocks mined on top of the common chain
+	Length int
+	// Seconds between the blocks of the branch
+	BlockInterval uint64
+	// Position in the branch, from 1, of a block sealed with an invalid
+	// timestamp, the branch ending with it, 0 when the branch is valid
+	InvalidAt int
+}
+
+type ForkScenario struct {
+	// Blocks mined before the branches fork
+	CommonLength   int
+	CommonInterval uint64
+
+	Branches []*Branch
+	// Branch expected to hold the tip of the judge once every block was
+	// delivered, empty for the common chain
+	ExpectedTip string
+
+	MinerAddress []byte
+}
+
+type ForkResult struct {
+	// Tip of every branch, the last valid block, keyed by name
+	BranchTips map[string][]byte
+	// Cumulative difficulty of the tip of every branch for the judge
+	BranchDifficulties map[string]*big.Int
+	Tip                []byte
+	// Branch holding the tip of the judge, empty for the common chain
+	TipBranch string
+	Height    uint64
+	// TipBranch is the expected branch
+	OK bool
+}
+
+func (r *ForkResult) String() string {
+	tipBranch := r.TipBranch
+	if tipBranch == "" {
+		tipBranch = "common chain"
+	}
+	status := "OK"
+	if !r.OK {
+		status = "UNEXPECTED"
+	}
+	return fmt.Sprintf("%s: tip #%d %s on %s", status, r.Height, misc.Bin2HStr(r.Tip), tipBranch)
+}
+
+// RunForkScenario plays scenario on a new network built from config, node 0
+// judging the branches built by the other nodes
+func RunForkScenario(scenario *ForkScenario, config *Config) (*ForkResult, error) {
+	if scenario.ExpectedTip != "" {
+		found := false
+		for _, branch := range scenario.Branches {
+			found = found || branch.Name == scenario.ExpectedTip
+		}
+		if !found {
+			return nil, fmt.Errorf("%s: %s", ErrUnknownBranch, scenario.ExpectedTip)
+		}
+	}
+
+	config.Nodes = len(scenario.Branches) + 1
+	network, err := NewNetwork(config)
+	if err != nil {
+		return nil, err
+	}
+	judge := network.Node(0)
+
+	// The common chain reaches every node
+	for i := 0; i < scenario.CommonLength; i++ {
+		network.setTime(network.now + scenario.CommonInterval*1000)
+		if _, err := judge.MineBlock(scenario.MinerAddress); err != nil {
+			return nil, err
+		}
+		network.RunUntilIdle()
+	}
+	forkTime := network.now
+	commonTip := judge.Tip().HeaderHash()
+
+	// Builders don't relay the blocks they seal, the judge receives them
+	// once all branches are built
+	result := &ForkResult{BranchTips: make(map[string][]byte)}
+	branchBlocks := make(map[string]map[string]bool)
+	var blocks [][]*core.Block
+	latest := forkTime
+	for i, branch := range scenario.Branches {
+		builder := network.Node(i + 1)
+		network.setTime(forkTime)
+		branchBlocks[branch.Name] = make(map[string]bool)
+		result.BranchTips[branch.Name] = commonTip
+
+		var built []*core.Block
+		for j := 0; j < branch.Length; j++ {
+			network.setTime(network.now + branch.BlockInterval*1000)
+			timestamp := network.clock.Time()
+			invalid := j+1 == branch.InvalidAt
+			if invalid {
+				timestamp = uint64(builder.Tip().Timestamp())
+			}
+			block, err := builder.sealBlock(scenario.MinerAddress, timestamp)
+			if err != nil {
+				return nil, fmt.Errorf("branch %s: %s", branch.Name, err)
+			}
+			built = append(built, block)
+			branchBlocks[branch.Name][string(block.HeaderHash())] = true
+			if invalid {
+				break
+			}
+			if !builder.manager.AddBlock(block, core.BlockFromMiner) {
+				return nil, fmt.Errorf("branch %s: block #%d rejected by its builder", branch.Name, block.BlockNumber())
+			}
+			result.BranchTips[branch.Name] = block.HeaderHash()
+		}
+		blocks = append(blocks, built)
+		if network.now > latest {
+			latest = network.now
+		}
+	}
+
+	network.setTime(latest)
+	var builders []int
+	for i, built := range blocks {
+		builders = append(builders, i+1)
+		for _, block := range built {
+			data, err := block.Serialize()
+			if err != nil {
+				return nil, err
+			}
+			network.send(msgBlock, i+1, judge.id, data)
+		}
+	}
+	// The judge relaying the blocks it accepts would change the builders
+	// only
+	network.Partition([]int{judge.id}, builders)
+	network.RunUntilIdle()
+
+	result.Tip = judge.Tip().HeaderHash()
+	result.Height = judge.Height()
+	result.BranchDifficulties = make(map[string]*big.Int)
+	for name, tip := range result.BranchTips {
+		if difficulty, err := judge.manager.CumulativeDifficulty(tip); err == nil {
+			result.BranchDifficulties[name] = difficulty
+		}
+	}
+	for name, headerHashes := range branchBlocks {
+		if headerHashes[string(result.Tip)] {
+			result.TipBranch = name
+		}
+	}
+	if scenario.ExpectedTip != "" {
+		result.OK = bytes.Equal(result.Tip, result.BranchTips[scenario.ExpectedTip])
+	} else {
+		result.OK = bytes.Equal(result.Tip, commonTip)
+	}
+	return result, nil
+}
@@ -0,0 +1,233 @@
+// Package simulator runs several in-process nodes over a virtual network.
+// Nodes use in-memory storage and a shared FakeClock, and messages are
+// delivered by a deterministic scheduler: for a given Config and sequence
+// of calls, a simulation always produces the same chains. This allows
+// testing sync, fork resolution and transaction propagation without
+// sockets or wall clock time.
+package simulator
+
+import (
+	"container/heap"
+	"github.com/cyyber/go-qrl/core"
+	"github.com/cyyber/go-qrl/misc"
+	"math/rand"
+)
+
+type Config struct {
+	Nodes int
+	Seed  int64
+
+	// Virtual network properties, in milliseconds
+	Latency uint64
+	Jitter  uint64
+	// Probability in [0, 1) for a message to be lost
+	PacketLoss float64
+
+	// Timestamp of the virtual clock when the simulation starts
+	StartTime uint64
+
+	NodeConfig *core.Config
+}
+
+type messageKind int
+
+const (
+	msgBlock messageKind = iota
+	msgTransaction
+	msgBlockRequest
+)
+
+type message struct {
+	kind messageKind
+	from int
+	to   int
+	data []byte
+
+	deliverAt uint64
+	seq       uint64
+}
+
+type messageQueue []*message
+
+func (q messageQueue) Len() int { return len(q) }
+func (q messageQueue) Less(i, j int) bool {
+	if q[i].deliverAt != q[j].deliverAt {
+		return q[i].deliverAt < q[j].deliverAt
+	}
+	return q[i].seq < q[j].seq
+}
+func (q messageQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *messageQueue) Push(x interface{}) { *q = append(*q, x.(*message)) }
+func (q *messageQueue) Pop() interface{} {
+	old := *q
+	m := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return m
+}
+
+// Network connects every node to every other node, unless partitioned
+type Network struct {
+	config *Config
+	clock  *misc.FakeClock
+	rand   *rand.Rand
+
+	nodes []*Node
+
+	now   uint64 // virtual time in milliseconds
+	seq   uint64
+	queue messageQueue
+
+	// Links cut by a partition, keyed by the ordered pair of node ids
+	cut map[[2]int]bool
+
+	Stats NetworkStats
+}
+
+type NetworkStats struct {
+	Sent      uint64
+	Delivered uint64
+	Lost      uint64
+	Dropped   uint64 // sent across a partition
+}
+
+func NewNetwork(config *Config) (*Network, error) {
+	n := &Network{
+		config: config,
+		clock:  misc.NewFakeClock(config.StartTime),
+		rand:   rand.New(rand.NewSource(config.Seed)),
+		now:    config.StartTime * 1000,
+		cut:    make(map[[2]int]bool),
+	}
+
+	for i := 0; i < config.Nodes; i++ {
+		node, err := newNode(i, n)
+		if err != nil {
+			return nil, err
+		}
+		n.nodes = append(n.nodes, node)
+	}
+
+	return n, nil
+}
+
+func (n *Network) Nodes() []*Node {
+	return n.nodes
+}
+
+func (n *Network) Node(id int) *Node {
+	return n.nodes[id]
+}
+
+func (n *Network) Clock() *misc.FakeClock {
+	return n.clock
+}
+
+// Now returns the virtual time in milliseconds
+func (n *Network) Now() uint64 {
+	return n.now
+}
+
+func link(a int, b int) [2]int {
+	if a > b {
+		a, b = b, a
+	}
+	return [2]int{a, b}
+}
+
+// Partition cuts every link between the nodes of group a and group b
+func (n *Network) Partition(a []int, b []int) {
+	for _, i := range a {
+		for _, j := range b {
+			n.cut[link(i, j)] = true
+		}
+	}
+}
+
+// Heal restores every link
+func (n *Network) Heal() {
+	n.cut = make(map[[2]int]bool)
+}
+
+func (n *Network) send(kind messageKind, from int, to int, data []byte) {
+	n.Stats.Sent++
+	if n.cut[link(from, to)] {
+		n.Stats.Dropped++
+		return
+	}
+	if n.config.PacketLoss > 0 && n.rand.Float64() < n.config.PacketLoss {
+		n.Stats.Lost++
+		return
+	}
+
+	delay := n.config.Latency
+	if n.config.Jitter > 0 {
+		delay += uint64(n.rand.Int63n(int64(n.config.Jitter) + 1))
+	}
+
+	n.seq++
+	heap.Push(&n.queue, &message{
+		kind:      kind,
+		from:      from,
+		to:        to,
+		data:      data,
+		deliverAt: n.now + delay,
+		seq:       n.seq,
+	})
+}
+
+func (n *Network) broadcast(kind messageKind, from int, data []byte) {
+	for _, node := range n.nodes {
+		if node.id != from {
+			n.send(kind, from, node.id, data)
+		}
+	}
+}
+
+func (n *Network) setTime(now uint64) {
+	n.now = now
+	n.clock.Set(now / 1000)
+}
+
+// Step delivers the next message, returns false when none is in flight
+func (n *Network) Step() bool {
+	if n.queue.Len() == 0 {
+		return false
+	}
+	m := heap.Pop(&n.queue).(*message)
+	if m.deliverAt > n.now {
+		n.setTime(m.deliverAt)
+	}
+	n.Stats.Delivered++
+	n.nodes[m.to].receive(m)
+	return true
+}
+
+// Advance delivers the messages due within the next ms milliseconds and
+// moves the clock forward
+func (n *Network) Advance(ms uint64) {
+	deadline := n.now + ms
+	for n.queue.Len() > 0 && n.queue[0].deliverAt <= deadline {
+		n.Step()
+	}
+	n.setTime(deadline)
+}
+
+// RunUntilIdle delivers messages until none is in flight
+func (n *Network) RunUntilIdle() {
+	for n.Step() {
+	}
+}
+
+// Converged returns true when every node has the same tip
+func (n *Network) Converged() bool {
+	if len(n.nodes) == 0 {
+		return true
+	}
+	tip := misc.Bin2HStr(n.nodes[0].Tip().HeaderHash())
+	for _, node := range n.nodes[1:] {
+		if misc.Bin2HStr(node.Tip().HeaderHash()) != tip {
+			return false
+		}
+	}
+	return true
+}
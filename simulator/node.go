@@ -0,0 +1,257 @@
+package simulator
+
+import (
+	"errors"
+	"github.com/cyyber/go-qrl/core"
+	"github.com/cyyber/go-qrl/core/pool"
+	"github.com/cyyber/go-qrl/core/transactions"
+	"github.com/cyyber/go-qrl/events"
+	"github.com/cyyber/go-qrl/generated"
+	"github.com/cyyber/go-qrl/genesis"
+	"github.com/cyyber/go-qrl/log"
+	"github.com/cyyber/go-qrl/misc"
+	"github.com/cyyber/go-qrl/pow"
+	"github.com/golang/protobuf/proto"
+)
+
+const maxMiningAttempts = 1 << 20
+
+var ErrNoNonceFound = errors.New("no nonce found")
+
+// Node is a simulated node, with the same Chain, ChainManager and
+// TransactionPool as a real node
+type Node struct {
+	id      int
+	network *Network
+	log     log.Logger
+
+	txPool   *pool.TransactionPool
+	eventBus *events.Bus
+	manager  *core.ChainManager
+
+	seenBlocks map[string]bool
+	seenTxs    map[string]bool
+
+	// Blocks waiting for their parent, keyed by parent headerhash
+	orphans map[string][]*orphanBlock
+}
+
+type orphanBlock struct {
+	block *core.Block
+	from  int
+}
+
+func newNode(id int, network *Network) (*Node, error) {
+	logger := log.New()
+	config := network.config.NodeConfig
+
+	state, err := core.CreateMemState(&logger, config)
+	if err != nil {
+		return nil, err
+	}
+
+	txPool := pool.CreateTransactionPool(config)
+	txPool.SetClock(network.clock)
+	eventBus := events.NewBus(256)
+	manager := core.CreateChainManager(logger, state, txPool, eventBus, config)
+	manager.Chain().SetClock(network.clock)
+
+	genesisBlock, err := genesis.CreateGenesisBlock()
+	if err != nil {
+		return nil, err
+	}
+	core.AddDevAccounts(&genesisBlock.Block, config)
+	if err := manager.Load(&genesisBlock.Block); err != nil {
+		return nil, err
+	}
+
+	return &Node{
+		id:         id,
+		network:    network,
+		log:        logger,
+		txPool:     txPool,
+		eventBus:   eventBus,
+		manager:    manager,
+		seenBlocks: make(map[string]bool),
+		seenTxs:    make(map[string]bool),
+		orphans:    make(map[string][]*orphanBlock),
+	}, nil
+}
+
+func (n *Node) ID() int {
+	return n.id
+}
+
+func (n *Node) Chain() *core.Chain {
+	return n.manager.Chain()
+}
+
+func (n *Node) Tip() *core.Block {
+	return n.manager.Tip()
+}
+
+func (n *Node) Height() uint64 {
+	return n.manager.Height()
+}
+
+func (n *Node) Subscribe(topics ...events.Topic) *events.Subscription {
+	return n.eventBus.Subscribe(topics...)
+}
+
+// MineBlock seals a block on top of the local tip and broadcasts it
+func (n *Node) MineBlock(minerAddress []byte) (*core.Block, error) {
+	block, err := n.sealBlock(minerAddress, n.network.clock.Time())
+	if err != nil {
+		return nil, err
+	}
+
+	if !n.manager.AddBlock(block, core.BlockFromMiner) {
+		return nil, errors.New("mined block rejected by the local chain")
+	}
+	n.relayBlock(block, -1)
+
+	return block, nil
+}
+
+// sealBlock mines a block with timestamp on top of the local tip, without
+// adding it to the chain
+func (n *Node) sealBlock(minerAddress []byte, timestamp uint64) (*core.Block, error) {
+	template, err := n.manager.Chain().CreateBlockTemplate(minerAddress, timestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	dt := pow.DifficultyTracker{}
+	target := dt.GetTarget(misc.BytesToUCharVector(template.Difficulty))
+	validator := pow.GetPowValidator()
+
+	block := template.Block
+	found := false
+	for nonce := uint32(0); nonce < maxMiningAttempts; nonce++ {
+		block.SetNonces(nonce, 0)
+		if validator.VerifyInput(block.MiningBlob(), target) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, ErrNoNonceFound
+	}
+	return block, nil
+}
+
+// SubmitTransaction adds tx to the local pool and broadcasts it
+func (n *Node) SubmitTransaction(protoTX *generated.Transaction) error {
+	tx := transactions.ProtoToTransaction(protoTX)
+	if tx == nil {
+		return errors.New("unsupported transaction type")
+	}
+	if err := n.manager.Chain().SubmitTransaction(tx); err != nil {
+		return err
+	}
+	n.relayTransaction(protoTX, -1)
+	return nil
+}
+
+func (n *Node) HasTransaction(txHash []byte) bool {
+	for _, tx := range n.txPool.Transactions() {
+		if misc.Bin2HStr(tx.Txhash()) == misc.Bin2HStr(txHash) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *Node) relayBlock(block *core.Block, from int) {
+	n.seenBlocks[string(block.HeaderHash())] = true
+	data, err := block.Serialize()
+	if err != nil {
+		n.log.Warn("Failed to serialize block", "error", err)
+		return
+	}
+	for _, peer := range n.network.nodes {
+		if peer.id != n.id && peer.id != from {
+			n.network.send(msgBlock, n.id, peer.id, data)
+		}
+	}
+}
+
+func (n *Node) relayTransaction(protoTX *generated.Transaction, from int) {
+	n.seenTxs[string(protoTX.TransactionHash)] = true
+	data, err := proto.Marshal(protoTX)
+	if err != nil {
+		n.log.Warn("Failed to serialize transaction", "error", err)
+		return
+	}
+	for _, peer := range n.network.nodes {
+		if peer.id != n.id && peer.id != from {
+			n.network.send(msgTransaction, n.id, peer.id, data)
+		}
+	}
+}
+
+func (n *Node) receive(m *message) {
+	switch m.kind {
+	case msgBlock:
+		block, err := core.DeSerializeBlock(m.data)
+		if err != nil {
+			return
+		}
+		n.receiveBlock(block, m.from)
+	case msgTransaction:
+		protoTX := &generated.Transaction{}
+		if err := proto.Unmarshal(m.data, protoTX); err != nil {
+			return
+		}
+		if n.seenTxs[string(protoTX.TransactionHash)] {
+			return
+		}
+		tx := transactions.ProtoToTransaction(protoTX)
+		if tx == nil {
+			return
+		}
+		if err := n.manager.Chain().SubmitTransaction(tx); err != nil {
+			n.seenTxs[string(protoTX.TransactionHash)] = true
+			return
+		}
+		n.relayTransaction(protoTX, m.from)
+	case msgBlockRequest:
+		block, err := n.manager.GetBlock(m.data)
+		if err != nil {
+			return
+		}
+		data, err := block.Serialize()
+		if err != nil {
+			return
+		}
+		n.network.send(msgBlock, n.id, m.from, data)
+	}
+}
+
+// receiveBlock adds a block received from a peer. Blocks whose parent is
+// unknown are kept as orphans and the parent is requested from the peer,
+// which walks back to the fork point one block at a time.
+func (n *Node) receiveBlock(block *core.Block, from int) {
+	if n.seenBlocks[string(block.HeaderHash())] {
+		return
+	}
+
+	if _, err := n.manager.GetBlock(block.PrevHeaderHash()); err != nil {
+		parent := string(block.PrevHeaderHash())
+		n.orphans[parent] = append(n.orphans[parent], &orphanBlock{block, from})
+		n.network.send(msgBlockRequest, n.id, from, block.PrevHeaderHash())
+		return
+	}
+
+	n.seenBlocks[string(block.HeaderHash())] = true
+	if !n.manager.AddBlock(block, core.BlockFromSync) {
+		return
+	}
+	n.relayBlock(block, from)
+
+	children := n.orphans[string(block.HeaderHash())]
+	delete(n.orphans, string(block.HeaderHash()))
+	for _, child := range children {
+		n.receiveBlock(child.block, child.from)
+	}
+}
@@ -0,0 +1,70 @@
+// Package tracing instruments the block and transaction lifecycle with
+// OpenTelemetry spans. Until Init is called the global noop provider is
+// used, so the spans cost next to nothing when tracing is disabled.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+	"os"
+)
+
+const instrumentationName = "github.com/cyyber/go-qrl"
+
+const (
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+func newExporter(ctx context.Context, exporter string, endpoint string) (sdktrace.SpanExporter, error) {
+	switch exporter {
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterOTLP:
+		return otlptracegrpc.New(ctx,
+			otlptracegrpc.WithEndpoint(endpoint),
+			otlptracegrpc.WithInsecure())
+	}
+	return nil, fmt.Errorf("unknown tracing exporter %s", exporter)
+}
+
+// Init installs a tracer provider exporting a sampleRatio share of the
+// traces to exporter. The returned function flushes and stops it.
+func Init(exporter string, endpoint string, sampleRatio float64, serviceName string) (func(context.Context) error, error) {
+	ctx := context.Background()
+
+	spanExporter, err := newExporter(ctx, exporter, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL,
+			semconv.ServiceNameKey.String(serviceName))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records err on span, if any, and ends it
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+}
@@ -0,0 +1,187 @@
+package wallet
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/cyyber/go-qrl/misc"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+)
+
+var (
+	ErrContactExists   = errors.New("contact already exists for this address")
+	ErrContactNotFound = errors.New("contact not found")
+	ErrEmptyLabel      = errors.New("contact label cannot be empty")
+)
+
+type Contact struct {
+	Label    string `json:"label"`
+	Qaddress string `json:"address"`
+}
+
+// ContactBook maps Q addresses to labels. It is kept encrypted on disk with
+// the passphrase it was opened with.
+type ContactBook struct {
+	lock sync.RWMutex
+
+	filename   string
+	passphrase []byte
+	contacts   map[string]*Contact
+}
+
+// OpenContactBook loads the contact book stored in filename, an empty book
+// is returned when the file doesn't exist yet
+func OpenContactBook(filename string, passphrase []byte) (*ContactBook, error) {
+	c := &ContactBook{
+		filename:   filename,
+		passphrase: append([]byte(nil), passphrase...),
+		contacts:   make(map[string]*Contact),
+	}
+
+	data, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := Decrypt(data, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	var contacts []*Contact
+	if err := json.Unmarshal(plaintext, &contacts); err != nil {
+		return nil, err
+	}
+	for _, contact := range contacts {
+		c.contacts[contact.Qaddress] = contact
+	}
+
+	return c, nil
+}
+
+func (c *ContactBook) save() error {
+	plaintext, err := json.Marshal(c.list())
+	if err != nil {
+		return err
+	}
+
+	data, err := Encrypt(plaintext, c.passphrase)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(c.filename, data)
+}
+
+func (c *ContactBook) list() []*Contact {
+	contacts := make([]*Contact, 0, len(c.contacts))
+	for _, contact := range c.contacts {
+		contacts = append(contacts, &Contact{Label: contact.Label, Qaddress: contact.Qaddress})
+	}
+	sort.Slice(contacts, func(i, j int) bool {
+		return contacts[i].Label < contacts[j].Label
+	})
+	return contacts
+}
+
+func (c *ContactBook) Add(label string, qaddress string) error {
+	if label == "" {
+		return ErrEmptyLabel
+	}
+	if _, err := misc.ParseQaddress(qaddress); err != nil {
+		return err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if _, ok := c.contacts[qaddress]; ok {
+		return ErrContactExists
+	}
+	c.contacts[qaddress] = &Contact{Label: label, Qaddress: qaddress}
+
+	if err := c.save(); err != nil {
+		delete(c.contacts, qaddress)
+		return err
+	}
+	return nil
+}
+
+func (c *ContactBook) Update(label string, qaddress string) error {
+	if label == "" {
+		return ErrEmptyLabel
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	contact, ok := c.contacts[qaddress]
+	if !ok {
+		return ErrContactNotFound
+	}
+	oldLabel := contact.Label
+	contact.Label = label
+
+	if err := c.save(); err != nil {
+		contact.Label = oldLabel
+		return err
+	}
+	return nil
+}
+
+func (c *ContactBook) Remove(qaddress string) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	contact, ok := c.contacts[qaddress]
+	if !ok {
+		return ErrContactNotFound
+	}
+	delete(c.contacts, qaddress)
+
+	if err := c.save(); err != nil {
+		c.contacts[qaddress] = contact
+		return err
+	}
+	return nil
+}
+
+func (c *ContactBook) Get(qaddress string) (*Contact, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	contact, ok := c.contacts[qaddress]
+	if !ok {
+		return nil, ErrContactNotFound
+	}
+	return &Contact{Label: contact.Label, Qaddress: contact.Qaddress}, nil
+}
+
+// List returns the contacts sorted by label
+func (c *ContactBook) List() []*Contact {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return c.list()
+}
+
+// Label returns the label of address, or an empty string for unknown
+// addresses. It is safe to call on a nil ContactBook.
+func (c *ContactBook) Label(address []byte) string {
+	if c == nil || address == nil {
+		return ""
+	}
+
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if contact, ok := c.contacts[misc.Qaddress(address)]; ok {
+		return contact.Label
+	}
+	return ""
+}
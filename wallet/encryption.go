@@ -0,0 +1,89 @@
+package wallet
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"golang.org/x/crypto/argon2"
+)
+
+// Encrypted data is laid out as salt || nonce || AES-256-GCM ciphertext, the
+// key being derived from the passphrase with Argon2id
+const (
+	saltSize = 16
+	keySize  = 32
+
+	argon2Time    = 3
+	argon2Memory  = 64 * 1024
+	argon2Threads = 4
+)
+
+var ErrWrongPassphrase = errors.New("wrong passphrase or corrupted data")
+
+func deriveKey(passphrase []byte, salt []byte) []byte {
+	return argon2.IDKey(passphrase, salt, argon2Time, argon2Memory, argon2Threads, keySize)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func Encrypt(plaintext []byte, passphrase []byte) ([]byte, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	key := deriveKey(passphrase, salt)
+	defer Wipe(key)
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	data := append(salt, nonce...)
+	return gcm.Seal(data, nonce, plaintext, nil), nil
+}
+
+func Decrypt(data []byte, passphrase []byte) ([]byte, error) {
+	if len(data) < saltSize {
+		return nil, ErrWrongPassphrase
+	}
+
+	key := deriveKey(passphrase, data[:saltSize])
+	defer Wipe(key)
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data = data[saltSize:]
+	if len(data) < gcm.NonceSize() {
+		return nil, ErrWrongPassphrase
+	}
+
+	plaintext, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return plaintext, nil
+}
+
+// Wipe overwrites key material before it is released
+func Wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,316 @@
+package wallet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/cyyber/go-qrl/core/transactions"
+	"github.com/cyyber/go-qrl/misc"
+	"github.com/karalabe/hid"
+	"github.com/theQRL/qrllib/goqrllib"
+	"sync"
+)
+
+// APDU instructions of the QRL Ledger app
+const (
+	ledgerCLA = 0x77
+
+	ledgerInsVersion     = 0x00
+	ledgerInsGetState    = 0x01
+	ledgerInsPublicKey   = 0x03
+	ledgerInsSign        = 0x04
+	ledgerInsSignNext    = 0x05
+	ledgerInsSetIndex    = 0x06
+	ledgerInsViewAddress = 0x07
+
+	ledgerStatusOK = 0x9000
+
+	// The app holds a single XMSS tree of height 8 using SHAKE-128
+	ledgerTreeHeight    = 8
+	ledgerPKSize        = 67
+	ledgerSignatureSize = 4 + 32 + 67*32 + ledgerTreeHeight*32
+
+	// Max destinations of a transfer accepted by the app
+	ledgerMaxDestinations = 3
+
+	ledgerVendorID = 0x2c97
+
+	ledgerHIDChannel    = 0x0101
+	ledgerHIDTag        = 0x05
+	ledgerHIDPacketSize = 64
+)
+
+var (
+	ErrLedgerNotFound       = errors.New("no Ledger device found")
+	ErrLedgerNotReady       = errors.New("Ledger QRL app is not initialized")
+	ErrLedgerUnsupportedTx  = errors.New("transaction type not supported by the Ledger app")
+	ErrLedgerTooManyOutputs = errors.New("too many destinations for the Ledger app")
+	ErrLedgerWrongAddress   = errors.New("address doesn't belong to the Ledger device")
+)
+
+// LedgerTransport exchanges APDUs with the device
+type LedgerTransport interface {
+	Exchange(apdu []byte) ([]byte, error)
+	Close() error
+}
+
+type hidTransport struct {
+	device hid.Device
+}
+
+// OpenLedgerHID opens the first Ledger device connected over USB
+func OpenLedgerHID() (LedgerTransport, error) {
+	for _, info := range hid.Enumerate(ledgerVendorID, 0) {
+		device, err := info.Open()
+		if err != nil {
+			return nil, err
+		}
+		return &hidTransport{device: device}, nil
+	}
+	return nil, ErrLedgerNotFound
+}
+
+// Exchange wraps apdu in the Ledger HID framing: every packet starts with
+// the channel, the tag and a sequence number, the first one also carries
+// the total length of the APDU
+func (t *hidTransport) Exchange(apdu []byte) ([]byte, error) {
+	data := make([]byte, 2+len(apdu))
+	binary.BigEndian.PutUint16(data, uint16(len(apdu)))
+	copy(data[2:], apdu)
+
+	for seq := 0; len(data) > 0; seq++ {
+		packet := make([]byte, ledgerHIDPacketSize)
+		binary.BigEndian.PutUint16(packet, ledgerHIDChannel)
+		packet[2] = ledgerHIDTag
+		binary.BigEndian.PutUint16(packet[3:], uint16(seq))
+		n := copy(packet[5:], data)
+		data = data[n:]
+		if _, err := t.device.Write(packet); err != nil {
+			return nil, err
+		}
+	}
+
+	var response []byte
+	length := -1
+	for seq := 0; length < 0 || len(response) < length; seq++ {
+		packet := make([]byte, ledgerHIDPacketSize)
+		if _, err := t.device.Read(packet); err != nil {
+			return nil, err
+		}
+		if binary.BigEndian.Uint16(packet) != ledgerHIDChannel || packet[2] != ledgerHIDTag ||
+			int(binary.BigEndian.Uint16(packet[3:])) != seq {
+			return nil, errors.New("invalid Ledger HID packet")
+		}
+		payload := packet[5:]
+		if seq == 0 {
+			length = int(binary.BigEndian.Uint16(payload))
+			payload = payload[2:]
+		}
+		response = append(response, payload...)
+	}
+	response = response[:length]
+
+	if len(response) < 2 {
+		return nil, errors.New("Ledger response too short")
+	}
+	status := binary.BigEndian.Uint16(response[len(response)-2:])
+	if status != ledgerStatusOK {
+		return nil, fmt.Errorf("Ledger returned status 0x%04x", status)
+	}
+	return response[:len(response)-2], nil
+}
+
+func (t *hidTransport) Close() error {
+	return t.device.Close()
+}
+
+// LedgerSigner signs with the XMSS tree held by the QRL Ledger app. The
+// device tracks its own OTS index, it never reuses a key.
+type LedgerSigner struct {
+	lock sync.Mutex
+
+	transport LedgerTransport
+	pk        []byte
+	qaddress  string
+}
+
+func NewLedgerSigner(transport LedgerTransport) (*LedgerSigner, error) {
+	l := &LedgerSigner{transport: transport}
+
+	state, _, err := l.State()
+	if err != nil {
+		return nil, err
+	}
+	if state == 0 {
+		return nil, ErrLedgerNotReady
+	}
+
+	pk, err := l.exchange(ledgerInsPublicKey, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(pk) != ledgerPKSize {
+		return nil, errors.New("invalid public key returned by Ledger")
+	}
+	l.pk = pk
+	l.qaddress = misc.Qaddress(misc.UCharVectorToBytes(goqrllib.QRLHelperGetAddress(misc.BytesToUCharVector(pk))))
+
+	return l, nil
+}
+
+func (l *LedgerSigner) exchange(ins byte, p1 byte, data []byte) ([]byte, error) {
+	apdu := []byte{ledgerCLA, ins, p1, 0, byte(len(data))}
+	return l.transport.Exchange(append(apdu, data...))
+}
+
+// Version returns the major, minor and patch version of the Ledger app
+func (l *LedgerSigner) Version() (string, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	response, err := l.exchange(ledgerInsVersion, 0, nil)
+	if err != nil {
+		return "", err
+	}
+	if len(response) < 4 {
+		return "", errors.New("invalid version returned by Ledger")
+	}
+	return fmt.Sprintf("%d.%d.%d", response[1], response[2], response[3]), nil
+}
+
+// State returns the app state and the next OTS index of the device
+func (l *LedgerSigner) State() (byte, uint64, error) {
+	response, err := l.exchange(ledgerInsGetState, 0, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(response) < 3 {
+		return 0, 0, errors.New("invalid state returned by Ledger")
+	}
+	return response[0], uint64(binary.LittleEndian.Uint16(response[1:3])), nil
+}
+
+// ShowAddress displays the address on the device, for the user to compare
+func (l *LedgerSigner) ShowAddress() error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	_, err := l.exchange(ledgerInsViewAddress, 0, nil)
+	return err
+}
+
+func (l *LedgerSigner) Close() error {
+	return l.transport.Close()
+}
+
+func (l *LedgerSigner) Addresses() ([]string, error) {
+	return []string{l.qaddress}, nil
+}
+
+func (l *LedgerSigner) PK(qaddress string) ([]byte, error) {
+	if qaddress != l.qaddress {
+		return nil, ErrLedgerWrongAddress
+	}
+	return l.pk, nil
+}
+
+// SignNext sends the transfer to the device for the user to review, the
+// device signing with its own OTS index
+func (l *LedgerSigner) SignNext(qaddress string, chainOTSIndex uint64, tx transactions.TransactionInterface) ([]byte, uint64, error) {
+	if qaddress != l.qaddress {
+		return nil, 0, ErrLedgerWrongAddress
+	}
+
+	blob, err := LedgerTransferBlob(tx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	otsIndex, err := l.nextOTSIndex(chainOTSIndex)
+	if err != nil {
+		return nil, 0, err
+	}
+	signature, err := l.sign(otsIndex, blob)
+	if err != nil {
+		return nil, 0, err
+	}
+	return signature, otsIndex, nil
+}
+
+// nextOTSIndex returns the index of the device. When the chain shows keys
+// above it were used, e.g. after restoring the seed on another device, the
+// device index is moved forward first.
+func (l *LedgerSigner) nextOTSIndex(chainOTSIndex uint64) (uint64, error) {
+	_, index, err := l.State()
+	if err != nil {
+		return 0, err
+	}
+	if index >= chainOTSIndex {
+		return index, nil
+	}
+
+	data := make([]byte, 2)
+	binary.LittleEndian.PutUint16(data, uint16(chainOTSIndex))
+	if _, err := l.exchange(ledgerInsSetIndex, 0, data); err != nil {
+		return 0, err
+	}
+	return chainOTSIndex, nil
+}
+
+// LedgerTransferBlob encodes a transfer in the format signed by the app:
+// type, number of destinations, then the source address with the fee and
+// each destination address with its amount, amounts being big endian
+func LedgerTransferBlob(tx transactions.TransactionInterface) ([]byte, error) {
+	transfer := tx.PBData().GetTransfer()
+	if transfer == nil {
+		return nil, ErrLedgerUnsupportedTx
+	}
+	if len(transfer.AddrsTo) > ledgerMaxDestinations {
+		return nil, ErrLedgerTooManyOutputs
+	}
+
+	blob := new(bytes.Buffer)
+	blob.WriteByte(0)
+	blob.WriteByte(byte(len(transfer.AddrsTo)))
+	blob.Write(tx.AddrFrom())
+	binary.Write(blob, binary.BigEndian, tx.Fee())
+	for i, addrTo := range transfer.AddrsTo {
+		blob.Write(addrTo)
+		binary.Write(blob, binary.BigEndian, transfer.Amounts[i])
+	}
+	return blob.Bytes(), nil
+}
+
+// sign has the device sign blob. otsIndex is checked against the index of
+// the device so the caller never relays a transaction signed with an
+// unexpected key.
+func (l *LedgerSigner) sign(otsIndex uint64, blob []byte) ([]byte, error) {
+	_, index, err := l.State()
+	if err != nil {
+		return nil, err
+	}
+	if index != otsIndex {
+		return nil, fmt.Errorf("Ledger OTS index is %d, expected %d", index, otsIndex)
+	}
+
+	signature, err := l.exchange(ledgerInsSign, 0, blob)
+	if err != nil {
+		return nil, err
+	}
+	for len(signature) < ledgerSignatureSize {
+		chunk, err := l.exchange(ledgerInsSignNext, 0, nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(chunk) == 0 {
+			return nil, errors.New("incomplete signature returned by Ledger")
+		}
+		signature = append(signature, chunk...)
+	}
+
+	return signature[:ledgerSignatureSize], nil
+}
@@ -0,0 +1,114 @@
+package wallet
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+var (
+	ErrPythonWallet          = errors.New("wallet file uses the Python node format, it must be migrated")
+	ErrEncryptedPythonWallet = errors.New("encrypted Python wallets must be decrypted with the Python walletd before migration")
+)
+
+// The Python node stores the wallet as a list of addresses with their
+// hexseed (version 0), or as an object holding that list (version 1)
+type pythonAddress struct {
+	Qaddress string `json:"address"`
+	HexSeed  string `json:"hexseed"`
+	Mnemonic string `json:"mnemonic"`
+	// Next OTS index, only in version 1 files
+	Index uint64 `json:"index"`
+}
+
+type pythonWallet struct {
+	Addresses []*pythonAddress `json:"addresses"`
+	Encrypted bool             `json:"encrypted"`
+	Version   int              `json:"version"`
+}
+
+func parsePythonWallet(data []byte) (*pythonWallet, error) {
+	var addresses []*pythonAddress
+	if err := json.Unmarshal(data, &addresses); err == nil {
+		return &pythonWallet{Addresses: addresses}, nil
+	}
+
+	w := &pythonWallet{}
+	if err := json.Unmarshal(data, w); err != nil {
+		return nil, err
+	}
+	if w.Addresses == nil {
+		return nil, errors.New("not a Python wallet file")
+	}
+	return w, nil
+}
+
+func isPythonWallet(data []byte) bool {
+	_, err := parsePythonWallet(data)
+	return err == nil
+}
+
+// MigratePythonWallet creates filename, encrypted with passphrase, holding
+// the addresses of the Python wallet pythonFilename along with their OTS
+// index. The Python wallet is left untouched.
+func MigratePythonWallet(pythonFilename string, filename string, passphrase []byte, idleTimeout time.Duration) (*Wallet, error) {
+	data, err := ioutil.ReadFile(pythonFilename)
+	if err != nil {
+		return nil, err
+	}
+	pw, err := parsePythonWallet(data)
+	if err != nil {
+		return nil, err
+	}
+	if pw.Encrypted {
+		return nil, ErrEncryptedPythonWallet
+	}
+
+	if _, err := os.Stat(filename); err == nil {
+		return nil, ErrWalletExists
+	}
+
+	// The wallet is built in a temporary file renamed once every address
+	// is imported, an interrupted migration never leaves a partial wallet
+	tmpFilename := filename + ".migrating"
+	os.Remove(tmpFilename)
+	defer os.Remove(tmpFilename)
+
+	w, err := CreateWallet(tmpFilename, passphrase, idleTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.Unlock(passphrase); err != nil {
+		return nil, err
+	}
+	defer w.Lock()
+
+	for _, address := range pw.Addresses {
+		var qaddress string
+		if address.HexSeed != "" {
+			qaddress, err = w.ImportHexSeed(address.HexSeed)
+		} else {
+			qaddress, err = w.ImportMnemonic(address.Mnemonic)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if address.Qaddress != "" && address.Qaddress != qaddress {
+			return nil, errors.New("seed of " + address.Qaddress + " derives another address")
+		}
+		if err := w.SetOTSIndex(qaddress, address.Index); err != nil {
+			return nil, err
+		}
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if err := os.Rename(tmpFilename, filename); err != nil {
+		return nil, err
+	}
+	w.filename = filename
+
+	return w, nil
+}
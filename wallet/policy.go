@@ -0,0 +1,152 @@
+package wallet
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/cyyber/go-qrl/misc"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+var (
+	ErrPolicyTxLimit           = errors.New("amount exceeds the per transaction limit")
+	ErrPolicyDailyLimit        = errors.New("amount exceeds the daily limit")
+	ErrPolicyDestinationDenied = errors.New("destination not allowed by policy")
+)
+
+type spending struct {
+	Timestamp int64  `json:"timestamp"`
+	Amount    uint64 `json:"amount"`
+}
+
+// Policy limits what an automated signer may spend. The spendings of the
+// last 24 hours are persisted so restarting the service doesn't reset the
+// daily limit.
+type Policy struct {
+	lock sync.Mutex
+
+	// 0 means no limit
+	MaxAmountPerTx  uint64
+	MaxAmountPerDay uint64
+	// Empty means any destination
+	AllowedDestinations map[string]bool
+
+	filename  string
+	spendings []*spending
+}
+
+func LoadPolicy(filename string, maxAmountPerTx uint64, maxAmountPerDay uint64, allowedDestinations []string) (*Policy, error) {
+	p := &Policy{
+		MaxAmountPerTx:      maxAmountPerTx,
+		MaxAmountPerDay:     maxAmountPerDay,
+		AllowedDestinations: make(map[string]bool),
+		filename:            filename,
+	}
+	for _, qaddress := range allowedDestinations {
+		p.AllowedDestinations[qaddress] = true
+	}
+
+	data, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return p, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &p.spendings); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *Policy) spentSince(since int64) uint64 {
+	var spent uint64
+	for _, s := range p.spendings {
+		if s.Timestamp > since {
+			spent += s.Amount
+		}
+	}
+	return spent
+}
+
+// Check returns an error if sending payouts, plus fee, breaks the policy
+func (p *Policy) Check(payouts []*Payout, fee uint64) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.check(payouts, fee)
+}
+
+func (p *Policy) check(payouts []*Payout, fee uint64) error {
+	for _, payout := range payouts {
+		if len(p.AllowedDestinations) > 0 && !p.AllowedDestinations[payout.Qaddress] {
+			return ErrPolicyDestinationDenied
+		}
+	}
+
+	// A wrapped around sum would pass any limit
+	amount, err := payoutsCost(payouts, fee)
+	if err != nil || (p.MaxAmountPerTx > 0 && amount > p.MaxAmountPerTx) {
+		return ErrPolicyTxLimit
+	}
+	if p.MaxAmountPerDay > 0 {
+		dayAgo := time.Now().Add(-24 * time.Hour).Unix()
+		spent, err := misc.AddShor(p.spentSince(dayAgo), amount)
+		if err != nil || spent > p.MaxAmountPerDay {
+			return ErrPolicyDailyLimit
+		}
+	}
+	return nil
+}
+
+func payoutsCost(payouts []*Payout, fee uint64) (uint64, error) {
+	amounts := []uint64{fee}
+	for _, payout := range payouts {
+		amounts = append(amounts, payout.Amount)
+	}
+	return misc.AddShor(amounts...)
+}
+
+// Reserve checks payouts against the policy and records them as spent. The
+// amount is recorded before the transaction is relayed, a failed relay is
+// counted against the daily limit rather than risking to exceed it.
+func (p *Policy) Reserve(payouts []*Payout, fee uint64) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if err := p.check(payouts, fee); err != nil {
+		return err
+	}
+
+	amount, _ := payoutsCost(payouts, fee)
+
+	now := time.Now()
+	dayAgo := now.Add(-24 * time.Hour).Unix()
+	var kept []*spending
+	for _, s := range p.spendings {
+		if s.Timestamp > dayAgo {
+			kept = append(kept, s)
+		}
+	}
+	kept = append(kept, &spending{Timestamp: now.Unix(), Amount: amount})
+
+	data, err := json.Marshal(kept)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(p.filename, data); err != nil {
+		return err
+	}
+	p.spendings = kept
+	return nil
+}
+
+// SpentToday returns the amount spent over the last 24 hours
+func (p *Policy) SpentToday() uint64 {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.spentSince(time.Now().Add(-24 * time.Hour).Unix())
+}
@@ -0,0 +1,134 @@
+package wallet
+
+import (
+	"errors"
+	"github.com/cyyber/go-qrl/crypto"
+	"github.com/cyyber/go-qrl/misc"
+	"github.com/theQRL/qrllib/goqrllib"
+	"strings"
+)
+
+// An extended seed is the 3 bytes address descriptor followed by the 48
+// bytes seed. Its mnemonic encodes 12 bits per word.
+const (
+	ExtendedSeedSize  = 51
+	MnemonicWordCount = ExtendedSeedSize * 8 / 12
+)
+
+var (
+	ErrInvalidExtendedSeed = errors.New("invalid extended seed")
+	ErrInvalidMnemonic     = errors.New("invalid mnemonic")
+)
+
+// MnemonicToExtendedSeed converts a mnemonic to the extended seed it encodes
+func MnemonicToExtendedSeed(mnemonic string) (extendedSeed []byte, err error) {
+	words := strings.Fields(mnemonic)
+	if len(words) != MnemonicWordCount {
+		return nil, ErrInvalidMnemonic
+	}
+
+	// qrllib raises an exception on unknown words
+	defer func() {
+		if recover() != nil {
+			extendedSeed, err = nil, ErrInvalidMnemonic
+		}
+	}()
+	extendedSeed = misc.UCharVectorToBytes(goqrllib.Mnemonic2bin(strings.Join(words, " ")))
+	if len(extendedSeed) != ExtendedSeedSize {
+		return nil, ErrInvalidMnemonic
+	}
+	return extendedSeed, nil
+}
+
+func ExtendedSeedToMnemonic(extendedSeed []byte) (string, error) {
+	if len(extendedSeed) != ExtendedSeedSize {
+		return "", ErrInvalidExtendedSeed
+	}
+	return goqrllib.Bin2mnemonic(misc.BytesToUCharVector(extendedSeed)), nil
+}
+
+func HexSeedToExtendedSeed(hexSeed string) ([]byte, error) {
+	extendedSeed, err := misc.HStr2Bin(strings.TrimSpace(hexSeed))
+	if err != nil || len(extendedSeed) != ExtendedSeedSize {
+		return nil, ErrInvalidExtendedSeed
+	}
+	return extendedSeed, nil
+}
+
+// ImportExtendedSeed adds the address derived from extendedSeed to the
+// wallet, which must be unlocked
+func (w *Wallet) ImportExtendedSeed(extendedSeed []byte) (string, error) {
+	if len(extendedSeed) != ExtendedSeedSize {
+		return "", ErrInvalidExtendedSeed
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.seeds == nil {
+		return "", ErrWalletLocked
+	}
+
+	xmss := (&crypto.XMSS{}).FromExtendedSeed(misc.BytesToUCharVector(extendedSeed))
+	defer xmss.Close()
+
+	qaddress := xmss.QAddress()
+	if err := w.addAccount(qaddress, misc.UCharVectorToBytes(xmss.PK()), extendedSeed); err != nil {
+		return "", err
+	}
+	return qaddress, nil
+}
+
+func (w *Wallet) ImportMnemonic(mnemonic string) (string, error) {
+	extendedSeed, err := MnemonicToExtendedSeed(mnemonic)
+	if err != nil {
+		return "", err
+	}
+	defer Wipe(extendedSeed)
+
+	return w.ImportExtendedSeed(extendedSeed)
+}
+
+func (w *Wallet) ImportHexSeed(hexSeed string) (string, error) {
+	extendedSeed, err := HexSeedToExtendedSeed(hexSeed)
+	if err != nil {
+		return "", err
+	}
+	defer Wipe(extendedSeed)
+
+	return w.ImportExtendedSeed(extendedSeed)
+}
+
+func (w *Wallet) extendedSeed(qaddress string) ([]byte, error) {
+	if w.seeds == nil {
+		return nil, ErrWalletLocked
+	}
+	seed, ok := w.seeds[qaddress]
+	if !ok {
+		return nil, ErrAccountNotFound
+	}
+	w.touch()
+	return seed, nil
+}
+
+func (w *Wallet) ExportMnemonic(qaddress string) (string, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	seed, err := w.extendedSeed(qaddress)
+	if err != nil {
+		return "", err
+	}
+	return ExtendedSeedToMnemonic(seed)
+}
+
+func (w *Wallet) ExportHexSeed(qaddress string) (string, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	seed, err := w.extendedSeed(qaddress)
+	if err != nil {
+		return "", err
+	}
+	return misc.Bin2HStr(seed), nil
+}
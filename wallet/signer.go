@@ -0,0 +1,86 @@
+package wallet
+
+import (
+	"github.com/cyyber/go-qrl/core/transactions"
+	"github.com/cyyber/go-qrl/misc"
+)
+
+// Signer is implemented by the backends holding XMSS keys, the local
+// encrypted wallet and hardware wallets
+type Signer interface {
+	Addresses() ([]string, error)
+
+	PK(qaddress string) ([]byte, error)
+
+	// SignNext signs tx with the next OTS key unused both by the signer and
+	// on chain, chainOTSIndex being the lowest index not yet used on chain.
+	// The key is reserved and used under one lock, so concurrent calls
+	// never sign with the same key. It returns the signature and the index
+	// of the key used.
+	SignNext(qaddress string, chainOTSIndex uint64, tx transactions.TransactionInterface) ([]byte, uint64, error)
+}
+
+func (w *Wallet) account(qaddress string) *Account {
+	for _, account := range w.file.Accounts {
+		if account.Qaddress == qaddress {
+			return account
+		}
+	}
+	return nil
+}
+
+func (w *Wallet) PK(qaddress string) ([]byte, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	account := w.account(qaddress)
+	if account == nil {
+		return nil, ErrAccountNotFound
+	}
+	return account.PK, nil
+}
+
+// SignNext signs with the highest of the chain index and the index tracked
+// by the wallet, as transactions signed by the wallet might not be on chain
+func (w *Wallet) SignNext(qaddress string, chainOTSIndex uint64, tx transactions.TransactionInterface) ([]byte, uint64, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	account := w.account(qaddress)
+	if account == nil {
+		return nil, 0, ErrAccountNotFound
+	}
+	otsIndex := chainOTSIndex
+	if account.OTSIndex > otsIndex {
+		otsIndex = account.OTSIndex
+	}
+
+	signature, _, err := w.sign(qaddress, otsIndex, misc.UCharVectorToBytes(tx.GetHashableBytes()))
+	if err != nil {
+		return nil, 0, err
+	}
+	return signature, otsIndex, nil
+}
+
+// SetOTSIndex moves the OTS index tracked for qaddress forward, used when
+// recovering an address whose keys were used elsewhere
+func (w *Wallet) SetOTSIndex(qaddress string, otsIndex uint64) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	account := w.account(qaddress)
+	if account == nil {
+		return ErrAccountNotFound
+	}
+	if otsIndex <= account.OTSIndex {
+		return nil
+	}
+
+	previous := account.OTSIndex
+	account.OTSIndex = otsIndex
+	if err := w.save(); err != nil {
+		account.OTSIndex = previous
+		return err
+	}
+	return nil
+}
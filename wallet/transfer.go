@@ -0,0 +1,114 @@
+package wallet
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/cyyber/go-qrl/core/transactions"
+	"github.com/cyyber/go-qrl/misc"
+	"io"
+	"math"
+	"strings"
+)
+
+var (
+	ErrNoPayouts      = errors.New("no payouts")
+	ErrAmountOverflow = errors.New("total amount overflows")
+	ErrTooManyOutputs = errors.New("too many outputs for a single transaction")
+
+	ErrInsufficientBalance = errors.New("insufficient balance")
+)
+
+// Payout is one output of a multi-output transfer, Amount is in Shor
+type Payout struct {
+	Qaddress string
+	Amount   uint64
+}
+
+// ValidatePayouts checks every output and returns the total amount and the
+// total cost of the transfers, fee being paid once per transaction of up to
+// multiOutputLimit outputs
+func ValidatePayouts(payouts []*Payout, fee uint64, multiOutputLimit int) (uint64, uint64, error) {
+	if len(payouts) == 0 {
+		return 0, 0, ErrNoPayouts
+	}
+
+	var total uint64
+	for i, payout := range payouts {
+		if _, err := misc.ParseQaddress(payout.Qaddress); err != nil {
+			return 0, 0, fmt.Errorf("output %d: %s", i, err)
+		}
+		if payout.Amount == 0 {
+			return 0, 0, fmt.Errorf("output %d: amount must be greater than 0", i)
+		}
+		var err error
+		if total, err = misc.AddShor(total, payout.Amount); err != nil {
+			return 0, 0, ErrAmountOverflow
+		}
+	}
+
+	batches := uint64(len(SplitPayouts(payouts, multiOutputLimit)))
+	if fee != 0 && batches > (math.MaxUint64-total)/fee {
+		return 0, 0, ErrAmountOverflow
+	}
+	return total, total + batches*fee, nil
+}
+
+// SplitPayouts groups payouts in batches of at most limit outputs, one
+// transaction being needed per batch
+func SplitPayouts(payouts []*Payout, limit int) [][]*Payout {
+	var batches [][]*Payout
+	for len(payouts) > limit {
+		batches = append(batches, payouts[:limit])
+		payouts = payouts[limit:]
+	}
+	if len(payouts) > 0 {
+		batches = append(batches, payouts)
+	}
+	return batches
+}
+
+// CreateTransfer builds an unsigned transfer paying payouts
+func CreateTransfer(payouts []*Payout, fee uint64, pk []byte, masterAddr []byte) (*transactions.TransferTransaction, error) {
+	var addrsTo [][]byte
+	var amounts []uint64
+	for _, payout := range payouts {
+		address, err := misc.ParseQaddress(payout.Qaddress)
+		if err != nil {
+			return nil, err
+		}
+		addrsTo = append(addrsTo, address)
+		amounts = append(amounts, payout.Amount)
+	}
+	return transactions.Create(addrsTo, amounts, fee, pk, masterAddr), nil
+}
+
+// ParsePayoutsCSV reads address,amount lines, amounts being decimal Quanta
+// with at most 9 decimals. Empty lines and lines starting with # are skipped.
+func ParsePayoutsCSV(r io.Reader) ([]*Payout, error) {
+	var payouts []*Payout
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Split(text, ",")
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: expected address,amount", line)
+		}
+		amount, err := misc.ParseQuanta(strings.TrimSpace(fields[1]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s: %q", line, err, fields[1])
+		}
+		payouts = append(payouts, &Payout{
+			Qaddress: strings.TrimSpace(fields[0]),
+			Amount:   amount,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return payouts, nil
+}
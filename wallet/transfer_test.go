@@ -0,0 +1,52 @@
+package wallet
+
+import (
+	"bytes"
+	"github.com/cyyber/go-qrl/core/transactions"
+	"github.com/cyyber/go-qrl/crypto"
+	"github.com/cyyber/go-qrl/misc"
+	"testing"
+)
+
+func TestCreateTransferMultiOutput(t *testing.T) {
+	xmss := (&crypto.XMSS{}).FromHeight(4, "shake128")
+	defer xmss.Close()
+
+	var payouts []*Payout
+	for i := 0; i < 3; i++ {
+		receiver := (&crypto.XMSS{}).FromHeight(4, "shake128")
+		payouts = append(payouts, &Payout{Qaddress: receiver.QAddress(), Amount: uint64(i+1) * 1000})
+		receiver.Close()
+	}
+
+	tx, err := CreateTransfer(payouts, 10, misc.UCharVectorToBytes(xmss.PK()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tx.AddrsTo()) != len(payouts) || len(tx.Amounts()) != len(payouts) {
+		t.Fatalf("%d outputs, expected %d", len(tx.AddrsTo()), len(payouts))
+	}
+	for i, payout := range payouts {
+		address, err := misc.ParseQaddress(payout.Qaddress)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(tx.AddrsTo()[i], address) || tx.Amounts()[i] != payout.Amount {
+			t.Fatalf("output %d doesn't match its payout", i)
+		}
+	}
+	if tx.Fee() != 10 {
+		t.Fatalf("fee %d, expected 10", tx.Fee())
+	}
+
+	tx.PBData().Nonce = 1
+	tx.Sign(*xmss, tx.GetHashableBytes())
+	tx.UpdateTxhash(tx.GetHashableBytes())
+
+	if !tx.ValidateXMSS(tx.GetHashableBytes()) {
+		t.Fatal("invalid signature")
+	}
+	if !bytes.Equal(tx.Txhash(), transactions.ExpectedTxhash(tx)) {
+		t.Fatal("txhash doesn't match the transaction")
+	}
+}
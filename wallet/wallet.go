@@ -0,0 +1,369 @@
+package wallet
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/cyyber/go-qrl/crypto"
+	"github.com/cyyber/go-qrl/misc"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+var (
+	ErrWalletLocked    = errors.New("wallet is locked")
+	ErrWalletExists    = errors.New("wallet file already exists")
+	ErrAccountNotFound = errors.New("address not found in wallet")
+	ErrAccountExists   = errors.New("address already exists in wallet")
+	ErrUnknownVersion  = errors.New("wallet file version not supported")
+	ErrOTSIndexUsed    = errors.New("OTS index already used by the wallet")
+)
+
+// Version 0 files, written before accounts had metadata, are upgraded when
+// opened
+const walletVersion = 1
+
+// Known plaintext encrypted along with the seeds, so the passphrase can be
+// checked even when the wallet has no account
+var passphraseCheck = []byte("QRL wallet")
+
+type Account struct {
+	Qaddress     string `json:"address"`
+	PK           []byte `json:"pk"`
+	Height       uint8  `json:"height"`
+	HashFunction string `json:"hash_function"`
+	// Unix time the account was added to the wallet
+	CreatedAt int64 `json:"created_at"`
+	// Lowest OTS index never used by this wallet
+	OTSIndex uint64 `json:"ots_index"`
+	// Extended seed encrypted with the wallet passphrase
+	EncryptedSeed []byte `json:"encrypted_seed"`
+}
+
+// AccountInfo is the public part of an Account
+type AccountInfo struct {
+	Qaddress     string
+	Height       uint8
+	HashFunction string
+	CreatedAt    int64
+	OTSIndex     uint64
+}
+
+type walletFile struct {
+	Version  int        `json:"version"`
+	Check    []byte     `json:"check"`
+	Accounts []*Account `json:"accounts"`
+}
+
+var descriptorHashFunctions = map[byte]string{
+	0: "sha2_256",
+	1: "shake128",
+	2: "shake256",
+}
+
+// setDescriptorInfo fills the tree height and hash function of account from
+// the descriptor heading its public key
+func (account *Account) setDescriptorInfo() {
+	if len(account.PK) < misc.AddressDescriptorSize {
+		return
+	}
+	account.HashFunction = descriptorHashFunctions[account.PK[0]&0x0F]
+	account.Height = (account.PK[1] & 0x0F) * 2
+}
+
+// Wallet keeps XMSS seeds encrypted at rest. Signing requires the wallet to
+// be unlocked, it locks itself again after idleTimeout without use and the
+// decrypted key material is wiped from memory when locking.
+type Wallet struct {
+	lock sync.Mutex
+
+	filename    string
+	file        *walletFile
+	idleTimeout time.Duration
+
+	// Only set while unlocked
+	passphrase []byte
+	seeds      map[string][]byte
+	lastUsed   time.Time
+	lockTimer  *time.Timer
+}
+
+// CreateWallet creates an empty wallet encrypted with passphrase
+func CreateWallet(filename string, passphrase []byte, idleTimeout time.Duration) (*Wallet, error) {
+	if _, err := os.Stat(filename); err == nil {
+		return nil, ErrWalletExists
+	}
+
+	check, err := Encrypt(passphraseCheck, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Wallet{
+		filename:    filename,
+		file:        &walletFile{Version: walletVersion, Check: check},
+		idleTimeout: idleTimeout,
+	}
+	if err := w.save(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// OpenWallet loads a wallet file, the wallet starts locked
+func OpenWallet(filename string, idleTimeout time.Duration) (*Wallet, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if isPythonWallet(data) {
+		return nil, ErrPythonWallet
+	}
+	file := &walletFile{}
+	if err := json.Unmarshal(data, file); err != nil {
+		return nil, err
+	}
+	if file.Check == nil {
+		return nil, errors.New("invalid wallet file")
+	}
+	if file.Version > walletVersion {
+		return nil, ErrUnknownVersion
+	}
+
+	w := &Wallet{
+		filename:    filename,
+		file:        file,
+		idleTimeout: idleTimeout,
+	}
+
+	if file.Version < walletVersion {
+		for _, account := range file.Accounts {
+			account.setDescriptorInfo()
+		}
+		file.Version = walletVersion
+		if err := w.save(); err != nil {
+			return nil, err
+		}
+	}
+
+	return w, nil
+}
+
+func (w *Wallet) save() error {
+	data, err := json.MarshalIndent(w.file, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(w.filename, data)
+}
+
+func (w *Wallet) Addresses() ([]string, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	var qaddresses []string
+	for _, account := range w.file.Accounts {
+		qaddresses = append(qaddresses, account.Qaddress)
+	}
+	return qaddresses, nil
+}
+
+// Accounts returns the metadata of the accounts, in the order they were added
+func (w *Wallet) Accounts() []*AccountInfo {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	var accounts []*AccountInfo
+	for _, account := range w.file.Accounts {
+		accounts = append(accounts, &AccountInfo{
+			Qaddress:     account.Qaddress,
+			Height:       account.Height,
+			HashFunction: account.HashFunction,
+			CreatedAt:    account.CreatedAt,
+			OTSIndex:     account.OTSIndex,
+		})
+	}
+	return accounts
+}
+
+func (w *Wallet) IsLocked() bool {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	return w.seeds == nil
+}
+
+// Unlock decrypts the seeds of every account with passphrase
+func (w *Wallet) Unlock(passphrase []byte) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.seeds != nil {
+		w.touch()
+		return nil
+	}
+
+	if _, err := Decrypt(w.file.Check, passphrase); err != nil {
+		return err
+	}
+
+	seeds := make(map[string][]byte, len(w.file.Accounts))
+	for _, account := range w.file.Accounts {
+		seed, err := Decrypt(account.EncryptedSeed, passphrase)
+		if err != nil {
+			for _, seed := range seeds {
+				Wipe(seed)
+			}
+			return err
+		}
+		seeds[account.Qaddress] = seed
+	}
+
+	w.seeds = seeds
+	w.passphrase = append([]byte(nil), passphrase...)
+	if w.idleTimeout > 0 {
+		w.lockTimer = time.AfterFunc(w.idleTimeout, w.lockIfIdle)
+	}
+	w.touch()
+
+	return nil
+}
+
+func (w *Wallet) Lock() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.wipe()
+}
+
+func (w *Wallet) wipe() {
+	if w.lockTimer != nil {
+		w.lockTimer.Stop()
+		w.lockTimer = nil
+	}
+	for _, seed := range w.seeds {
+		Wipe(seed)
+	}
+	w.seeds = nil
+	Wipe(w.passphrase)
+	w.passphrase = nil
+}
+
+func (w *Wallet) lockIfIdle() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.seeds == nil {
+		return
+	}
+	if idle := time.Since(w.lastUsed); idle < w.idleTimeout {
+		w.lockTimer.Reset(w.idleTimeout - idle)
+		return
+	}
+	w.wipe()
+}
+
+func (w *Wallet) touch() {
+	w.lastUsed = time.Now()
+}
+
+// addAccount encrypts and stores extendedSeed, the wallet must be unlocked
+func (w *Wallet) addAccount(qaddress string, pk []byte, extendedSeed []byte) error {
+	if w.seeds == nil {
+		return ErrWalletLocked
+	}
+	if _, ok := w.seeds[qaddress]; ok {
+		return ErrAccountExists
+	}
+
+	encryptedSeed, err := Encrypt(extendedSeed, w.passphrase)
+	if err != nil {
+		return err
+	}
+
+	account := &Account{
+		Qaddress:      qaddress,
+		PK:            pk,
+		CreatedAt:     time.Now().Unix(),
+		EncryptedSeed: encryptedSeed,
+	}
+	account.setDescriptorInfo()
+
+	accounts := w.file.Accounts
+	w.file.Accounts = append(w.file.Accounts, account)
+	if err := w.save(); err != nil {
+		w.file.Accounts = accounts
+		return err
+	}
+
+	w.seeds[qaddress] = append([]byte(nil), extendedSeed...)
+	w.touch()
+	return nil
+}
+
+// AddNewAddress generates a new XMSS tree and stores its seed
+func (w *Wallet) AddNewAddress(treeHeight uint, hashFunction string) (string, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.seeds == nil {
+		return "", ErrWalletLocked
+	}
+
+	xmss := (&crypto.XMSS{}).FromHeight(treeHeight, hashFunction)
+	defer xmss.Close()
+
+	extendedSeed := misc.UCharVectorToBytes(xmss.ExtendedSeed())
+	defer Wipe(extendedSeed)
+
+	qaddress := xmss.QAddress()
+	if err := w.addAccount(qaddress, misc.UCharVectorToBytes(xmss.PK()), extendedSeed); err != nil {
+		return "", err
+	}
+	return qaddress, nil
+}
+
+// Sign signs message with the OTS key otsIndex of qaddress and returns the
+// signature along with the XMSS public key. Keys below the index tracked by
+// the wallet are refused, they may already have signed something else.
+func (w *Wallet) Sign(qaddress string, otsIndex uint, message []byte) ([]byte, []byte, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	return w.sign(qaddress, uint64(otsIndex), message)
+}
+
+func (w *Wallet) sign(qaddress string, otsIndex uint64, message []byte) ([]byte, []byte, error) {
+	if w.seeds == nil {
+		return nil, nil, ErrWalletLocked
+	}
+	seed, ok := w.seeds[qaddress]
+	if !ok {
+		return nil, nil, ErrAccountNotFound
+	}
+	w.touch()
+
+	account := w.account(qaddress)
+	if otsIndex < account.OTSIndex {
+		return nil, nil, ErrOTSIndexUsed
+	}
+
+	// The index is recorded before signing, a crash can waste an OTS key
+	// but never lead to its reuse
+	previous := account.OTSIndex
+	account.OTSIndex = otsIndex + 1
+	if err := w.save(); err != nil {
+		account.OTSIndex = previous
+		return nil, nil, err
+	}
+
+	xmss := (&crypto.XMSS{}).FromExtendedSeed(misc.BytesToUCharVector(seed))
+	defer xmss.Close()
+
+	xmss.SetOTSIndex(uint(otsIndex))
+	signature := xmss.Sign(misc.BytesToUCharVector(message))
+
+	return signature, misc.UCharVectorToBytes(xmss.PK()), nil
+}
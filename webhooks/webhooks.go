@@ -0,0 +1,429 @@
+package webhooks
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/cyyber/go-qrl/core"
+	"github.com/cyyber/go-qrl/core/transactions"
+	"github.com/cyyber/go-qrl/deposits"
+	"github.com/cyyber/go-qrl/diagnostics"
+	"github.com/cyyber/go-qrl/events"
+	"github.com/cyyber/go-qrl/log"
+	"github.com/cyyber/go-qrl/misc"
+	"net/http"
+	"os"
+	"path"
+	"sync"
+	"time"
+)
+
+const (
+	// Pseudo topic, delivered for each transaction of a new block which
+	// involves one of the watched addresses of the endpoint
+	TopicAddressTransaction events.Topic = "address_transaction"
+
+	SignatureHeader = "X-QRL-Signature"
+	EventHeader     = "X-QRL-Event"
+	DeliveryHeader  = "X-QRL-Delivery"
+
+	endpointQueueSize = 1024
+)
+
+// Payload is the JSON body POSTed to the webhook endpoints. ID is unique per
+// delivery and kept across retries, so receivers can drop duplicates.
+type Payload struct {
+	ID        string       `json:"id"`
+	Topic     events.Topic `json:"topic"`
+	Timestamp int64        `json:"timestamp"`
+	Data      interface{}  `json:"data"`
+}
+
+type NewBlockData struct {
+	HeaderHash  string `json:"header_hash"`
+	BlockNumber uint64 `json:"block_number"`
+	Timestamp   uint64 `json:"timestamp"`
+}
+
+type ReorgData struct {
+	OldTip          string   `json:"old_tip"`
+	OldHeight       uint64   `json:"old_height"`
+	NewTip          string   `json:"new_tip"`
+	NewHeight       uint64   `json:"new_height"`
+	ForkPoint       string   `json:"fork_point"`
+	Depth           uint64   `json:"depth"`
+	DroppedTxHashes []string `json:"dropped_tx_hashes"`
+}
+
+type AddressTransactionData struct {
+	Address     string `json:"address"`
+	TxHash      string `json:"tx_hash"`
+	HeaderHash  string `json:"header_hash"`
+	BlockNumber uint64 `json:"block_number"`
+}
+
+type DepositData struct {
+	ID            string `json:"id"`
+	Status        string `json:"status"`
+	Address       string `json:"address"`
+	TxHash        string `json:"tx_hash"`
+	OutputIndex   int    `json:"output_index"`
+	Amount        uint64 `json:"amount"`
+	TokenTxHash   string `json:"token_tx_hash,omitempty"`
+	BlockNumber   uint64 `json:"block_number"`
+	HeaderHash    string `json:"header_hash"`
+	Confirmations uint64 `json:"confirmations"`
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of body, sent in SignatureHeader
+// as "sha256=<signature>"
+func Sign(secret []byte, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature is the check a receiver is expected to perform
+func VerifySignature(secret []byte, body []byte, header string) bool {
+	expected := "sha256=" + Sign(secret, body)
+	return hmac.Equal([]byte(expected), []byte(header))
+}
+
+type endpoint struct {
+	config    *core.WebhookEndpoint
+	topics    map[events.Topic]bool
+	addresses map[string]bool
+	queue     chan *Payload
+}
+
+// Notifier POSTs the events published on the event bus to the configured
+// webhook endpoints. Each endpoint has its own queue and worker, so a slow
+// or unreachable endpoint doesn't delay the others. Deliveries that still
+// fail after MaxRetries are appended to the dead-letter file.
+type Notifier struct {
+	log      log.Logger
+	config   *core.WebhooksConfig
+	manager  *core.ChainManager
+	eventBus *events.Bus
+	client   *http.Client
+
+	endpoints []*endpoint
+
+	deadLetterLock sync.Mutex
+	deadLetterFile string
+
+	subscription *events.Subscription
+	quit         chan struct{}
+	wg           sync.WaitGroup
+}
+
+func NewNotifier(manager *core.ChainManager, eventBus *events.Bus, config *core.Config, log log.Logger) (*Notifier, error) {
+	webhooksConfig := config.User.Webhooks
+	n := &Notifier{
+		log:            log,
+		config:         webhooksConfig,
+		manager:        manager,
+		eventBus:       eventBus,
+		client:         &http.Client{Timeout: time.Duration(webhooksConfig.Timeout) * time.Second},
+		deadLetterFile: path.Join(config.User.QrlDir, webhooksConfig.DeadLetterFilename),
+	}
+
+	for _, endpointConfig := range webhooksConfig.Endpoints {
+		e := &endpoint{
+			config:    endpointConfig,
+			topics:    make(map[events.Topic]bool),
+			addresses: make(map[string]bool),
+			queue:     make(chan *Payload, endpointQueueSize),
+		}
+		for _, topic := range endpointConfig.Topics {
+			e.topics[events.Topic(topic)] = true
+		}
+		for _, qaddress := range endpointConfig.WatchedAddresses {
+			address, err := misc.ParseQaddress(qaddress)
+			if err != nil {
+				return nil, fmt.Errorf("webhook %s: invalid watched address %s: %s", endpointConfig.URL, qaddress, err)
+			}
+			e.addresses[string(address)] = true
+		}
+		n.endpoints = append(n.endpoints, e)
+	}
+
+	return n, nil
+}
+
+func (n *Notifier) Start() {
+	n.quit = make(chan struct{})
+	n.subscription = n.eventBus.Subscribe(events.TopicNewBlock, events.TopicReorg, events.TopicDeposit)
+
+	for _, e := range n.endpoints {
+		e := e
+		n.wg.Add(1)
+		diagnostics.Go("webhooks", func() { n.worker(e) })
+		diagnostics.RegisterGauge("webhooks.queue."+e.config.URL, func() int64 {
+			return int64(len(e.queue))
+		})
+	}
+
+	n.wg.Add(1)
+	diagnostics.Go("webhooks", n.run)
+}
+
+func (n *Notifier) Stop() {
+	for _, e := range n.endpoints {
+		diagnostics.UnregisterGauge("webhooks.queue." + e.config.URL)
+	}
+	close(n.quit)
+	n.subscription.Unsubscribe()
+	n.wg.Wait()
+}
+
+func (n *Notifier) run() {
+	defer n.wg.Done()
+
+	for {
+		select {
+		case <-n.quit:
+			return
+		case event, ok := <-n.subscription.Events():
+			if !ok {
+				return
+			}
+			n.dispatch(event)
+		}
+	}
+}
+
+func newDeliveryID() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+func (n *Notifier) enqueue(e *endpoint, topic events.Topic, timestamp time.Time, data interface{}) {
+	payload := &Payload{
+		ID:        newDeliveryID(),
+		Topic:     topic,
+		Timestamp: timestamp.Unix(),
+		Data:      data,
+	}
+
+	select {
+	case e.queue <- payload:
+	default:
+		n.log.Warn("Webhook queue full", "url", e.config.URL, "topic", topic)
+		n.deadLetter(e, payload, "queue full")
+	}
+}
+
+func (n *Notifier) dispatch(event *events.Event) {
+	switch data := event.Data.(type) {
+	case *events.NewBlockEvent:
+		blockData := &NewBlockData{
+			HeaderHash:  misc.Bin2HStr(data.HeaderHash),
+			BlockNumber: data.BlockNumber,
+			Timestamp:   data.Timestamp,
+		}
+		for _, e := range n.endpoints {
+			if e.topics[events.TopicNewBlock] {
+				n.enqueue(e, events.TopicNewBlock, event.Timestamp, blockData)
+			}
+		}
+		n.dispatchAddressTransactions(event, data)
+	case *events.ReorgEvent:
+		reorgData := &ReorgData{
+			OldTip:          misc.Bin2HStr(data.OldTip),
+			OldHeight:       data.OldHeight,
+			NewTip:          misc.Bin2HStr(data.NewTip),
+			NewHeight:       data.NewHeight,
+			ForkPoint:       misc.Bin2HStr(data.ForkPoint),
+			Depth:           data.Depth,
+			DroppedTxHashes: misc.Bin2HStrList(data.DroppedTxHashes),
+		}
+		for _, e := range n.endpoints {
+			if e.topics[events.TopicReorg] {
+				n.enqueue(e, events.TopicReorg, event.Timestamp, reorgData)
+			}
+		}
+	case *deposits.DepositEvent:
+		deposit := data.Deposit
+		depositData := &DepositData{
+			ID:            deposit.ID,
+			Status:        string(data.Status),
+			Address:       misc.Qaddress(deposit.Address),
+			TxHash:        misc.Bin2HStr(deposit.TxHash),
+			OutputIndex:   deposit.OutputIndex,
+			Amount:        deposit.Amount,
+			BlockNumber:   deposit.BlockNumber,
+			HeaderHash:    misc.Bin2HStr(deposit.HeaderHash),
+			Confirmations: data.Confirmations,
+		}
+		if deposit.TokenTxHash != nil {
+			depositData.TokenTxHash = misc.Bin2HStr(deposit.TokenTxHash)
+		}
+		for _, e := range n.endpoints {
+			if e.topics[events.TopicDeposit] {
+				n.enqueue(e, events.TopicDeposit, event.Timestamp, depositData)
+			}
+		}
+	}
+}
+
+func (n *Notifier) dispatchAddressTransactions(event *events.Event, newBlock *events.NewBlockEvent) {
+	var interested []*endpoint
+	for _, e := range n.endpoints {
+		if e.topics[TopicAddressTransaction] && len(e.addresses) > 0 {
+			interested = append(interested, e)
+		}
+	}
+	if len(interested) == 0 {
+		return
+	}
+
+	block, err := n.manager.GetBlock(newBlock.HeaderHash)
+	if err != nil {
+		n.log.Warn("Webhook notifier failed to load block", "headerhash", misc.Bin2HStr(newBlock.HeaderHash), "error", err)
+		return
+	}
+
+	for _, protoTX := range block.Transactions() {
+		tx := transactions.ProtoToTransaction(protoTX)
+		if tx == nil {
+			continue
+		}
+		affected := make(map[string]core.AddressState)
+		tx.SetAffectedAddress(affected)
+
+		for _, e := range interested {
+			for address := range affected {
+				if !e.addresses[address] {
+					continue
+				}
+				n.enqueue(e, TopicAddressTransaction, event.Timestamp, &AddressTransactionData{
+					Address:     misc.Qaddress([]byte(address)),
+					TxHash:      misc.Bin2HStr(protoTX.TransactionHash),
+					HeaderHash:  misc.Bin2HStr(newBlock.HeaderHash),
+					BlockNumber: newBlock.BlockNumber,
+				})
+			}
+		}
+	}
+}
+
+func (n *Notifier) worker(e *endpoint) {
+	defer n.wg.Done()
+
+	for {
+		select {
+		case <-n.quit:
+			return
+		case payload := <-e.queue:
+			n.deliver(e, payload)
+		}
+	}
+}
+
+// deliver POSTs payload to the endpoint, retrying with exponential backoff
+func (n *Notifier) deliver(e *endpoint, payload *Payload) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		n.log.Warn("Failed to encode webhook payload", "topic", payload.Topic, "error", err)
+		return
+	}
+
+	backoff := time.Duration(n.config.InitialBackoff) * time.Millisecond
+	maxBackoff := time.Duration(n.config.MaxBackoff) * time.Millisecond
+
+	for attempt := uint64(0); ; attempt++ {
+		err = n.post(e, payload, body)
+		if err == nil {
+			return
+		}
+		if attempt >= n.config.MaxRetries {
+			break
+		}
+
+		n.log.Debug("Webhook delivery failed, retrying",
+			"url", e.config.URL,
+			"id", payload.ID,
+			"attempt", attempt+1,
+			"backoff", backoff,
+			"error", err)
+
+		select {
+		case <-n.quit:
+			n.deadLetter(e, payload, "shutdown")
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+
+	n.log.Warn("Webhook delivery failed", "url", e.config.URL, "id", payload.ID, "error", err)
+	n.deadLetter(e, payload, err.Error())
+}
+
+func (n *Notifier) post(e *endpoint, payload *Payload, body []byte) error {
+	req, err := http.NewRequest("POST", e.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(payload.Topic))
+	req.Header.Set(DeliveryHeader, payload.ID)
+	if e.config.Secret != "" {
+		req.Header.Set(SignatureHeader, "sha256="+Sign([]byte(e.config.Secret), body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+type deadLetterEntry struct {
+	URL      string   `json:"url"`
+	Reason   string   `json:"reason"`
+	FailedAt int64    `json:"failed_at"`
+	Payload  *Payload `json:"payload"`
+}
+
+// deadLetter appends a failed delivery to the dead-letter file, one JSON
+// object per line, so it can be inspected or replayed later
+func (n *Notifier) deadLetter(e *endpoint, payload *Payload, reason string) {
+	n.deadLetterLock.Lock()
+	defer n.deadLetterLock.Unlock()
+
+	line, err := json.Marshal(&deadLetterEntry{
+		URL:      e.config.URL,
+		Reason:   reason,
+		FailedAt: time.Now().Unix(),
+		Payload:  payload,
+	})
+	if err != nil {
+		n.log.Warn("Failed to encode dead-letter entry", "id", payload.ID, "error", err)
+		return
+	}
+
+	f, err := os.OpenFile(n.deadLetterFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		n.log.Warn("Failed to open dead-letter file", "file", n.deadLetterFile, "error", err)
+		return
+	}
+	defer f.Close()
+
+	f.Write(append(line, '\n'))
+}